@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"go-llama/internal/config"
+	"go-llama/internal/memory"
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: go run ./cmd/import_memories [flags] -file memories.jsonl")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Each line is a JSON object:")
+	fmt.Fprintln(out, `  {"content": "...", "created_at": "2023-04-01T12:00:00Z", "user_id": "42",`)
+	fmt.Fprintln(out, `   "is_collective": false, "tags": ["golang"], "importance": 0.6}`)
+	fmt.Fprintln(out, "user_id is required unless is_collective is true; importance defaults to 0.5.")
+	fmt.Fprintln(out, "Tiers are assigned from created_at using the compression tier rules.")
+	fmt.Fprintln(out, "Progress is checkpointed after each batch; rerunning resumes after the last completed batch.")
+	fmt.Fprintln(out, "Exits 1 if any record fails.")
+	fmt.Fprintln(out, "")
+	flag.PrintDefaults()
+}
+
+func main() {
+	file := flag.String("file", "", "JSONL file to import; - reads stdin")
+	dryRun := flag.Bool("dry-run", false, "Validate records and report tiers without embedding or writing")
+	checkpoint := flag.String("checkpoint", "", "Checkpoint file (default <file>.checkpoint; none for stdin)")
+	errorsOut := flag.String("errors", "", "Write failed records as JSON lines to this file")
+	batchSize := flag.Int("batch", 32, "Records embedded per request")
+	perMinute := flag.Int("rate", 600, "Maximum records embedded per minute")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig("config.json")
+	if err != nil {
+		log.Fatalf("Failed to load config.json: %v", err)
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", *file, err)
+		}
+		defer f.Close()
+		in = f
+		if *checkpoint == "" {
+			*checkpoint = *file + ".checkpoint"
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var storage *memory.Storage
+	var embedder *memory.Embedder
+	if !*dryRun {
+		storage, err = memory.NewStorage(cfg.GrowerAI.Qdrant.URL, cfg.GrowerAI.Qdrant.Collection, cfg.GrowerAI.Qdrant.APIKey)
+		if err != nil {
+			log.Fatalf("Failed to connect to Qdrant: %v", err)
+		}
+		if err := storage.WaitForCollection(ctx, 30*time.Second); err != nil {
+			log.Fatalf("Qdrant collection not ready: %v", err)
+		}
+		embedder = memory.NewEmbedder(config.GetEmbeddingsURL(cfg.GrowerAI.EmbeddingModel.URL))
+	}
+
+	importer := memory.NewImporter(storage, embedder, memory.ImportConfig{
+		BatchSize:      *batchSize,
+		PerMinute:      *perMinute,
+		DedupThreshold: cfg.GrowerAI.Dialogue.DedupThreshold,
+		TierRules: memory.TierRules{
+			RecentToMediumDays: cfg.GrowerAI.Compression.TierRules.RecentToMediumDays,
+			MediumToLongDays:   cfg.GrowerAI.Compression.TierRules.MediumToLongDays,
+			LongToAncientDays:  cfg.GrowerAI.Compression.TierRules.LongToAncientDays,
+		},
+		DryRun:         *dryRun,
+		CheckpointPath: *checkpoint,
+	})
+
+	report, importErr := importer.Import(ctx, in)
+	if report != nil {
+		if *errorsOut != "" && len(report.Errors) > 0 {
+			if err := writeErrors(*errorsOut, report.Errors); err != nil {
+				log.Printf("WARNING: %v", err)
+			}
+		}
+		// The summary line leaves out the per-record errors; -errors has them
+		report.Errors = nil
+		summary, _ := json.Marshal(report)
+		fmt.Println(string(summary))
+	}
+	if importErr != nil {
+		log.Fatalf("Import stopped: %v", importErr)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeErrors writes one JSON line per failed record
+func writeErrors(path string, importErrors []memory.ImportError) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create error report: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, e := range importErrors {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write error report: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go-llama/internal/config"
+	"go-llama/internal/dialogue"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: go run ./cmd/replay [flags]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Re-runs the parsing and decisions of a recorded dialogue cycle against the current")
+	fmt.Fprintln(out, "code and configuration, and prints where they differ from what was recorded.")
+	fmt.Fprintln(out, "No model or tool is called: the recorded replies are parsed again, and proposed")
+	fmt.Fprintln(out, "goals are checked for duplicates against the goals the cycle started with, on")
+	fmt.Fprintln(out, "their text alone.")
+	fmt.Fprintln(out, "Cycles are recorded when growerai.dialogue.transcripts.enabled is set.")
+	fmt.Fprintln(out, "Exits 1 if any decision changed.")
+	fmt.Fprintln(out, "")
+	flag.PrintDefaults()
+}
+
+func main() {
+	configPath := flag.String("config", "config.json", "Config file")
+	cycleID := flag.Int("cycle", 0, "Cycle to replay (default the latest recorded)")
+	verbose := flag.Bool("v", false, "Also list unchanged decisions")
+	flag.Usage = usage
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", *configPath, err)
+	}
+	db, err := gorm.Open(postgres.Open(cfg.Postgres.DSN), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Error),
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to postgres: %v", err)
+	}
+
+	ctx := context.Background()
+	transcript, err := dialogue.LoadTranscript(ctx, db, *cycleID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	d := cfg.GrowerAI.Dialogue
+	results, err := dialogue.ReplayTranscript(ctx, transcript, dialogue.ReplayConfig{
+		GoalDedup: dialogue.GoalDedupConfig{
+			StringWeight:    d.GoalDedup.StringWeight,
+			KeywordWeight:   d.GoalDedup.KeywordWeight,
+			EmbeddingWeight: d.GoalDedup.EmbeddingWeight,
+			Threshold:       d.GoalDedup.Threshold,
+		},
+		GoalProposals: dialogue.GoalProposalConfig{
+			DefaultPriority:     d.GoalProposals.DefaultPriority,
+			MinDescriptionChars: d.GoalProposals.MinDescriptionChars,
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	changed := 0
+	for _, r := range results {
+		if len(r.Changes) == 0 {
+			if *verbose {
+				fmt.Printf("#%d %s: unchanged\n", r.Seq, r.Step)
+			}
+			continue
+		}
+		changed++
+		fmt.Printf("#%d %s:\n", r.Seq, r.Step)
+		for _, change := range r.Changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	fmt.Printf("Cycle #%d: %d of %d decisions changed (recorded %s)\n",
+		transcript.CycleID, changed, len(results), transcript.CreatedAt.Format("2006-01-02 15:04"))
+	if changed > 0 {
+		os.Exit(1)
+	}
+}
@@ -11,10 +11,13 @@ import (
 	"go-llama/internal/config"
 	"go-llama/internal/db"
 	"go-llama/internal/dialogue"
+	"go-llama/internal/health"
 	"go-llama/internal/llm"
 	"go-llama/internal/memory"
 	"go-llama/internal/tools"
 	redisdb "go-llama/internal/redis"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -28,6 +31,14 @@ func main() {
     // This updates model names and context limits without restart
     cfg.StartModelRefresher(5 * time.Minute)
 
+    // Watch config.json so live settings apply without a restart; components register
+    // reload hooks as they are created below
+    configWatcher, err := config.NewWatcher("config.json", time.Duration(cfg.ConfigReload.PollSeconds)*time.Second)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+        os.Exit(1)
+    }
+
 	if err := db.Init(cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "DB init error: %v\n", err)
 		os.Exit(1)
@@ -35,14 +46,40 @@ func main() {
 
 	rdb := redisdb.NewClient(cfg)
 
+    // Dependency checks behind /readyz; components register theirs as they are created below
+    healthChecker := health.NewChecker(
+        time.Duration(cfg.Health.TimeoutSeconds)*time.Second,
+        time.Duration(cfg.Health.CacheSeconds)*time.Second,
+    )
+    healthChecker.Register(health.Check{Name: health.DependencyPostgres, Required: true, Probe: db.Ping})
+    healthChecker.Register(health.Check{Name: health.DependencyRedis, Required: true, Probe: func(ctx context.Context) error {
+        return redisdb.Ping(ctx, rdb)
+    }})
+
     // Declare llmManager outside the block so it's accessible later
     var llmManager *llm.Manager
     var appEngine *dialogue.Engine // Milestone 5: Expose engine to router
+    var decayWorker *memory.DecayWorker // Exposed to router for admin compression endpoints
+    var retagWorker *memory.RetagWorker // Exposed to router for admin retag endpoints
+    var conceptTagger *memory.Tagger    // Shared with the dialogue engine for research syntheses
+    var domainPolicy *tools.DomainPolicy // Exposed to router for reloads; nil when web parsing is off
+    var llmBudget *llm.BudgetTracker // Hosted token budget; nil when disabled
 
 	// Check if GrowerAI is enabled globally
 	if cfg.GrowerAI.Enabled {
 		log.Printf("[Main] GrowerAI enabled - initializing components...")
 
+		reasoningURL := config.GetChatURL(cfg.GrowerAI.ReasoningModel.URL)
+		if provider := modelProvider(cfg.GrowerAI.ReasoningModel); provider != nil {
+			healthChecker.Register(health.Check{Name: health.DependencyReasoningModel, Required: true, Probe: provider.Ping})
+		} else {
+			healthChecker.Register(health.Check{Name: health.DependencyReasoningModel, Required: true, Probe: func(ctx context.Context) error {
+				return llm.PingModel(ctx, reasoningURL)
+			}})
+		}
+		healthChecker.SetModels(cfg.ResolvedModels)
+		configWatcher.Register(modelsReloadHook())
+
 		// Initialize LLM Queue Manager (if enabled)
 		if cfg.GrowerAI.LLMQueue.Enabled {
 			log.Printf("[Main] Initializing LLM queue manager...")
@@ -53,16 +90,37 @@ func main() {
 				BackgroundQueueSize:      cfg.GrowerAI.LLMQueue.BackgroundQueueSize,
 				CriticalTimeout:          time.Duration(cfg.GrowerAI.LLMQueue.CriticalTimeoutSeconds) * time.Second,
 				BackgroundTimeout:        time.Duration(cfg.GrowerAI.LLMQueue.BackgroundTimeoutSeconds) * time.Second,
+				PreemptBackground:        cfg.GrowerAI.LLMQueue.PreemptBackground,
+				PreemptWindow:            time.Duration(cfg.GrowerAI.LLMQueue.PreemptWindowSeconds) * time.Second,
+				StreamRepeatWindow:       cfg.GrowerAI.LLMQueue.StreamRepeatWindow,
+				StreamRepeatLimit:        cfg.GrowerAI.LLMQueue.StreamRepeatLimit,
 			}
 			
 			// Circuit breaker will be created later, pass nil for now
 			llmManager = llm.NewManager(llmConfig, nil)
 			defer llmManager.Stop()
+			registerModelProviders(cfg, llmManager)
+			if cfg.GrowerAI.Budget.Enabled {
+				llmBudget = llm.NewBudgetTracker(db.DB, budgetConfig(cfg))
+				if err := llmBudget.Load(context.Background()); err != nil {
+					log.Printf("[Main] WARNING: Failed to load LLM token usage, budget starts from zero: %v", err)
+				}
+				llmManager.SetBudget(llmBudget)
+				configWatcher.Register(budgetReloadHook(llmBudget))
+				log.Printf("[Main] ✓ Hosted LLM token budget enabled (daily: %d/%d, monthly: %d/%d soft/hard)",
+					cfg.GrowerAI.Budget.DailySoftTokens, cfg.GrowerAI.Budget.DailyHardTokens,
+					cfg.GrowerAI.Budget.MonthlySoftTokens, cfg.GrowerAI.Budget.MonthlyHardTokens)
+			}
 			
 			log.Printf("[Main] ✓ LLM queue manager initialized (concurrent: %d, critical queue: %d, background queue: %d)",
 				llmConfig.MaxConcurrent, llmConfig.CriticalQueueSize, llmConfig.BackgroundQueueSize)
 		} else {
 			log.Printf("[Main] LLM queue disabled in config")
+			for _, model := range []config.ModelConfig{cfg.GrowerAI.ReasoningModel, cfg.GrowerAI.SimpleModel, cfg.GrowerAI.CompressionModel} {
+				if model.Hosted() {
+					log.Fatalf("[Main] Model %s uses the %s provider, which requires growerai.llm_queue.enabled", model.Name, model.Provider)
+				}
+			}
 		}
 
 		// Initialize GrowerAI principles (10 Commandments)
@@ -102,6 +160,19 @@ func main() {
 				log.Fatalf("[Main] Failed to initialize memory collection: %v", err)
 			}
 			log.Printf("[Main] ✓ Memory collection ready")
+			healthChecker.Register(health.Check{Name: health.DependencyQdrant, Required: true, Probe: storage.Ping})
+			healthEmbedder := memory.NewEmbedder(config.GetEmbeddingsURL(cfg.GrowerAI.EmbeddingModel.URL))
+			healthChecker.Register(health.Check{Name: health.DependencyEmbeddings, Required: true, Probe: healthEmbedder.Ping})
+
+			// Record retrievals in the background for every Storage instance
+			accessTracker := memory.NewAccessTracker(storage, memory.AccessTrackerConfig{
+				Mode:          cfg.GrowerAI.Retrieval.AccessTracking.Mode,
+				FlushInterval: time.Duration(cfg.GrowerAI.Retrieval.AccessTracking.FlushSeconds) * time.Second,
+			})
+			memory.InstallAccessTracker(accessTracker)
+			go accessTracker.Start()
+			log.Printf("[Main] ✓ Memory access tracking enabled (mode: %s, flush: %ds)",
+				cfg.GrowerAI.Retrieval.AccessTracking.Mode, cfg.GrowerAI.Retrieval.AccessTracking.FlushSeconds)
 		}
 
 		// Start GrowerAI compression worker if enabled
@@ -132,8 +203,8 @@ func main() {
 				}
 
                 compressor := memory.NewCompressor(
-                    config.GetChatURL(cfg.GrowerAI.ReasoningModel.URL),
-                    cfg.GrowerAI.ReasoningModel.Name,
+                    config.GetChatURL(cfg.GrowerAI.CompressionModel.URL),
+                    cfg.GrowerAI.CompressionModel.Name,
                     embedder,
 					linker,
 					compressorLLMClient,
@@ -168,6 +239,20 @@ func main() {
 				defer taggerQueue.Stop()
 				log.Printf("[Main] ✓ Async tagger queue initialized (workers: 3, queue: 1000)")
 
+				conceptTagger = tagger
+				retagWorker = memory.NewRetagWorker(storage, tagger, db.DB, memory.RetagConfig{
+					BatchSize: cfg.GrowerAI.Tagging.Retag.BatchSize,
+					PerMinute: cfg.GrowerAI.Tagging.Retag.PerMinute,
+				})
+				if cfg.GrowerAI.Tagging.Retag.OnStartup {
+					if err := retagWorker.Start(context.Background()); err != nil {
+						log.Printf("[Main] WARNING: Failed to start retag run: %v", err)
+					} else {
+						log.Printf("[Main] ✓ Concept tag retag started for tagger %s (rate: %d/min)",
+							tagger.Version(), cfg.GrowerAI.Tagging.Retag.PerMinute)
+					}
+				}
+
 				tierRules := memory.TierRules{
 					RecentToMediumDays: cfg.GrowerAI.Compression.TierRules.RecentToMediumDays,
 					MediumToLongDays:   cfg.GrowerAI.Compression.TierRules.MediumToLongDays,
@@ -181,7 +266,8 @@ func main() {
 				}
 
 				storageLimits := memory.StorageLimits{
-					MaxTotalMemories: cfg.GrowerAI.StorageLimits.MaxTotalMemories,
+					MaxTotalMemories:      cfg.GrowerAI.StorageLimits.MaxTotalMemories,
+					MaxCollectiveMemories: cfg.GrowerAI.StorageLimits.MaxCollectiveMemories,
 					TierAllocation: memory.TierAllocation{
 						Recent:  cfg.GrowerAI.StorageLimits.TierAllocation.Recent,
 						Medium:  cfg.GrowerAI.StorageLimits.TierAllocation.Medium,
@@ -206,24 +292,25 @@ func main() {
 						llm.PriorityBackground,
 						time.Duration(cfg.GrowerAI.LLMQueue.BackgroundTimeoutSeconds)*time.Second,
 					)
-					log.Printf("[Main] ✓ DecayWorker using LLM queue for principles (priority: background)")
+					log.Printf("[Main] ✓ Principle evolution using LLM queue (priority: background)")
 				}
 
-                worker := memory.NewDecayWorker(
+                workerDeps := memory.NewWorkerDeps(
                     storage,
                     compressor,
                     embedder,
-                    taggerQueue, // Use tagger queue instead of tagger
-                    linker,
                     db.DB,
                     config.GetChatURL(cfg.GrowerAI.ReasoningModel.URL),
                     cfg.GrowerAI.ReasoningModel.Name,
-					config.GetChatURL(cfg.GrowerAI.SimpleModel.URL),
+                    config.GetChatURL(cfg.GrowerAI.SimpleModel.URL),
                     cfg.GrowerAI.SimpleModel.Name,
-                    decayWorkerLLMClient, // NEW: Pass LLM client for principle generation
+                    decayWorkerLLMClient,
+                )
+                worker := memory.NewDecayWorker(
+                    workerDeps,
+                    taggerQueue, // Use tagger queue instead of tagger
+                    linker,
                     cfg.GrowerAI.Compression.ScheduleHours,
-                    cfg.GrowerAI.Principles.MinRatingThreshold,
-                    cfg.GrowerAI.Principles.ExtractionLimit,
                     tierRules,
                     mergeWindows,
                     cfg.GrowerAI.Compression.ImportanceMod,
@@ -240,10 +327,25 @@ func main() {
 				go linkWorker.Start()
 
                 go worker.Start()
+                decayWorker = worker
+                healthChecker.RegisterWorker(worker.Status)
+
+                // Principle evolution has its own schedule, so a long compression pass
+                // cannot delay it and a panic in one does not stop the other
+                principleWorker := memory.NewPrincipleWorker(
+                    workerDeps,
+                    cfg.GrowerAI.Principles.EvolutionScheduleHours,
+                    cfg.GrowerAI.Principles.MinRatingThreshold,
+                    cfg.GrowerAI.Principles.ExtractionLimit,
+                    time.Duration(cfg.GrowerAI.Principles.DialogueCooldownHours*float64(time.Hour)),
+                )
+                go principleWorker.Start()
+                healthChecker.RegisterWorker(principleWorker.Status)
 
                 log.Printf("[Main] ✓ GrowerAI compression worker started (schedule: every %d hours)",
                     cfg.GrowerAI.Compression.ScheduleHours)
-                log.Printf("[Main] ✓ Principle evolution integrated into compression cycle")
+                log.Printf("[Main] ✓ Principle evolution worker started (schedule: every %d hours, dialogue cooldown: %.0fh)",
+                    cfg.GrowerAI.Principles.EvolutionScheduleHours, cfg.GrowerAI.Principles.DialogueCooldownHours)
                 log.Printf("[Main] ✓ Memory linking enabled (similarity: %.2f, max links: %d)",
 					cfg.GrowerAI.Linking.SimilarityThreshold, cfg.GrowerAI.Linking.MaxLinksPerMemory)
 				log.Printf("[Main] ✓ Cluster compression enabled (merge windows: %d/%d/%d days)",
@@ -260,23 +362,66 @@ func main() {
 		// Initialize GrowerAI tool registry
 		log.Printf("[Main] Initializing GrowerAI tool registry...")
 		toolRegistry := tools.NewRegistry()
+		toolHealth := tools.NewHealthTracker(0, 0, 0)
+		toolRegistry.Use(toolHealth.Middleware())
+		toolRegistry.Use(tools.LimitOutput(tools.OutputLimits{
+			MaxBytes:     cfg.GrowerAI.Tools.OutputLimits.MaxBytes,
+			PerTool:      cfg.GrowerAI.Tools.OutputLimits.PerTool,
+			HardMaxBytes: cfg.GrowerAI.Tools.OutputLimits.HardMaxBytes,
+		}))
 		toolConfigs := make(map[string]tools.ToolConfig)
 
+		// Tool caches persist in Redis when reachable, otherwise in memory only
+		var toolCacheRedis *redis.Client
+		redisChecked := false
+		cacheRedisClient := func() *redis.Client {
+			if !redisChecked {
+				redisChecked = true
+				pingCtx, pingCancel := context.WithTimeout(context.Background(), 2*time.Second)
+				if err := rdb.Ping(pingCtx).Err(); err == nil {
+					toolCacheRedis = rdb
+				} else {
+					log.Printf("[Main] Redis unavailable for tool caches, using in-memory caches: %v", err)
+				}
+				pingCancel()
+			}
+			return toolCacheRedis
+		}
+
 		if cfg.GrowerAI.Tools.SearXNG.Enabled {
-			searxngConfig := tools.ToolConfig{
-				Enabled:               cfg.GrowerAI.Tools.SearXNG.Enabled,
-				TimeoutInteractive:    time.Duration(cfg.GrowerAI.Tools.SearXNG.TimeoutInteractive) * time.Second,
-				TimeoutIdle:           time.Duration(cfg.GrowerAI.Tools.SearXNG.TimeoutIdle) * time.Second,
-				MaxResultsInteractive: cfg.GrowerAI.Tools.SearXNG.MaxResultsInteractive,
-				MaxResultsIdle:        cfg.GrowerAI.Tools.SearXNG.MaxResultsIdle,
+			searxngConfig := searchToolConfig(cfg)
+
+			instances := []tools.SearXNGInstance{}
+			for _, inst := range cfg.GrowerAI.Tools.SearXNG.Instances {
+				instances = append(instances, tools.SearXNGInstance{URL: inst.URL, Weight: inst.Weight})
+			}
+			if len(instances) == 0 {
+				instances = append(instances, tools.SearXNGInstance{URL: cfg.GrowerAI.Tools.SearXNG.URL, Weight: 1})
+			}
+			poolConfig := tools.SearXNGPoolConfig{
+				QueryTimeout:    time.Duration(cfg.GrowerAI.Tools.SearXNG.QueryTimeout) * time.Second,
+				UnhealthyAfter:  cfg.GrowerAI.Tools.SearXNG.UnhealthyAfter,
+				ReprobeInterval: time.Duration(cfg.GrowerAI.Tools.SearXNG.ReprobeInterval) * time.Second,
 			}
 
-			searxngTool := tools.NewSearXNGTool(cfg.GrowerAI.Tools.SearXNG.URL, searxngConfig)
+			searxngTool := tools.NewSearXNGToolWithInstances(instances, poolConfig, searxngConfig)
+			if cfg.GrowerAI.Tools.SearXNG.Cache.Enabled {
+				cacheConfig := tools.SearchCacheConfig{
+					TTL:        time.Duration(cfg.GrowerAI.Tools.SearXNG.Cache.TTLHours) * time.Hour,
+					MaxEntries: cfg.GrowerAI.Tools.SearXNG.Cache.MaxEntries,
+				}
+				cacheRedis := cacheRedisClient()
+				searxngTool.SetCache(tools.NewSearchCache(cacheConfig, cacheRedis))
+				log.Printf("[Main] ✓ Search cache enabled (ttl: %s, max entries: %d, redis: %v)",
+					cacheConfig.TTL, cacheConfig.MaxEntries, cacheRedis != nil)
+			}
 			if err := toolRegistry.Register(searxngTool); err != nil {
 				log.Printf("[Main] WARNING: Failed to register SearXNG tool: %v", err)
 			} else {
 				toolConfigs[tools.ToolNameSearch] = searxngConfig
-				log.Printf("[Main] ✓ SearXNG tool registered (url: %s)", cfg.GrowerAI.Tools.SearXNG.URL)
+				// Not required: cycles can still reason and parse known pages without search
+				healthChecker.Register(health.Check{Name: health.DependencySearXNG, Probe: searxngTool.Ping})
+				log.Printf("[Main] ✓ SearXNG tool registered (%d instance(s), first: %s)", len(instances), instances[0].URL)
 			}
 		}
 
@@ -308,10 +453,51 @@ func main() {
             dynamicLimit := int(float64(cfg.GrowerAI.ReasoningModel.ContextSize) * 0.66)
             
             unifiedTool := tools.NewWebParserUnifiedTool(userAgent, llmURL, llmModel, maxPageSizeMB, webParseConfig, webParserLLMClient, dynamicLimit)
+            unifiedTool.SetExtractionMode(cfg.GrowerAI.Tools.WebParse.ExtractionMode)
+            unifiedTool.SetChunking(cfg.GrowerAI.Tools.WebParse.ChunkTokens, cfg.GrowerAI.Tools.WebParse.ChunkOverlapTokens)
+            unifiedTool.SetLanguage(tools.LanguageConfig{
+                Target:         cfg.GrowerAI.Tools.WebParse.Language.Target,
+                Policy:         cfg.GrowerAI.Tools.WebParse.Language.Policy,
+                TranslateURL:   cfg.GrowerAI.SimpleModel.URL,
+                TranslateModel: cfg.GrowerAI.SimpleModel.Name,
+            })
+            if cfg.GrowerAI.Tools.WebParse.HTTPCache.Enabled {
+                httpCacheConfig := tools.HTTPCacheConfig{
+                    MaxBytes:    int64(cfg.GrowerAI.Tools.WebParse.HTTPCache.MaxSizeMB) * 1024 * 1024,
+                    ReuseWindow: time.Duration(cfg.GrowerAI.Tools.WebParse.HTTPCache.ReuseMinutes) * time.Minute,
+                    TTL:         time.Duration(cfg.GrowerAI.Tools.WebParse.HTTPCache.TTLHours) * time.Hour,
+                }
+                unifiedTool.SetHTTPCache(tools.NewHTTPCache(httpCacheConfig, cacheRedisClient()))
+                log.Printf("[Main] ✓ Web parser HTTP cache enabled (budget: %dMB, reuse: %s)",
+                    cfg.GrowerAI.Tools.WebParse.HTTPCache.MaxSizeMB, httpCacheConfig.ReuseWindow)
+            }
+            // Always installed: even in "off" mode private/LAN targets must be refused
+            policy, err := tools.NewDomainPolicy(domainPolicyConfig(cfg))
+            if err != nil {
+                log.Printf("[Main] WARNING: Invalid domain policy (%v), falling back to mode \"off\"", err)
+                policy, _ = tools.NewDomainPolicy(tools.DomainPolicyConfig{})
+            }
+            unifiedTool.SetDomainPolicy(policy)
+            domainPolicy = policy
+            configWatcher.Register(domainPolicyReloadHook(policy))
+            if llmClient, ok := webParserLLMClient.(tools.SummarizerLLM); ok {
+                summarizer := tools.NewSummarizer(llmClient, tools.SummarizerConfig{
+                    LLMURL:        llmURL,
+                    Model:         llmModel,
+                    FetchTimeout:  webParseConfig.TimeoutIdle,
+                    UserAgent:     userAgent,
+                    MaxPageSizeMB: maxPageSizeMB,
+                    Sampling:      cfg.GrowerAI.Sampling[config.SamplingSummarize],
+                })
+                summarizer.SetDomainPolicy(policy)
+                api.SetDefaultSummarizer(summarizer)
+            }
+            log.Printf("[Main] ✓ Web parser domain policy: %s (%d patterns, private targets refused)",
+                policy.Config().Mode, len(policy.Config().Patterns))
             if err := toolRegistry.Register(unifiedTool); err != nil {
                 log.Printf("[Main] WARNING: Failed to register web_parse_unified tool: %v", err)
             } else {
-                log.Printf("[Main] ✓ Unified Web parser registered (max page: %dMB)", maxPageSizeMB)
+                log.Printf("[Main] ✓ Unified Web parser registered (max page: %dMB, extraction: %s)", maxPageSizeMB, cfg.GrowerAI.Tools.WebParse.ExtractionMode)
             }
         }
 
@@ -321,6 +507,9 @@ func main() {
 
 		contextualRegistry := tools.NewContextualRegistry(toolRegistry, toolConfigs)
 		log.Printf("[Main] ✓ Tool registry initialized with %d tools", len(toolRegistry.List()))
+		if _, ok := toolConfigs[tools.ToolNameSearch]; ok {
+			configWatcher.Register(searchReloadHook(contextualRegistry))
+		}
 
 		// Start GrowerAI dialogue worker if enabled
 		if cfg.GrowerAI.Dialogue.Enabled {
@@ -331,6 +520,17 @@ func main() {
 			} else {
             embedder := memory.NewEmbedder(config.GetEmbeddingsURL(cfg.GrowerAI.EmbeddingModel.URL))
 				stateManager := dialogue.NewStateManager(db.DB)
+				stateManager.SetCompletedGoalRetention(
+					cfg.GrowerAI.Dialogue.CompletedGoals.Retain,
+					cfg.GrowerAI.Dialogue.CompletedGoals.ArchiveLookup,
+				)
+				stateManager.SetStateVersions(cfg.GrowerAI.Dialogue.StateVersions)
+				if archived, err := stateManager.ArchiveOversizedState(context.Background()); err != nil {
+					log.Printf("[Main] WARNING: Failed to archive completed goals: %v", err)
+				} else if archived > 0 {
+					log.Printf("[Main] ✓ Archived %d completed goals beyond retention (%d)",
+						archived, cfg.GrowerAI.Dialogue.CompletedGoals.Retain)
+				}
 
 				// Initialize circuit breaker for LLM resilience
 				llmCircuitBreaker := tools.NewCircuitBreaker(
@@ -342,12 +542,15 @@ func main() {
 				// Create LLM client for dialogue (background priority)
 				var llmClient interface{}
 				if llmManager != nil {
-					llmClient = llm.NewClient(
+					dialogueLLMClient := llm.NewClient(
 						llmManager,
 						llm.PriorityBackground,
 						time.Duration(cfg.GrowerAI.LLMQueue.BackgroundTimeoutSeconds)*time.Second,
 					)
-					log.Printf("[Main] ✓ Dialogue using LLM queue (priority: background, timeout: %ds)",
+					// The engine resubmits preempted calls, so user chat may take its slots
+					dialogueLLMClient.SetPreemptible(true)
+					llmClient = dialogueLLMClient
+					log.Printf("[Main] ✓ Dialogue using LLM queue (priority: background, preemptible, timeout: %ds)",
 						cfg.GrowerAI.LLMQueue.BackgroundTimeoutSeconds)
 				} else {
 					log.Printf("[Main] Dialogue using legacy direct HTTP calls")
@@ -378,6 +581,107 @@ func main() {
 					cfg.GrowerAI.Dialogue.DynamicActionPlanning,
 					llmCircuitBreaker, // Add circuit breaker parameter
 				)
+				engine.SetDedupThreshold(cfg.GrowerAI.Dialogue.DedupThreshold)
+				engine.SetAdaptiveBase(
+					cfg.GrowerAI.Dialogue.Adaptive.SearchThreshold,
+					cfg.GrowerAI.Dialogue.Adaptive.GoalSimilarity,
+					cfg.GrowerAI.Dialogue.Adaptive.ToolTimeoutSeconds,
+				)
+				if err := engine.SetAdaptiveLimits(adaptiveLimits(cfg)); err != nil {
+					log.Printf("[Main] WARNING: Invalid dialogue.adaptive bounds or pins, using defaults: %v", err)
+				}
+				if err := engine.SetGoalDedup(goalDedupConfig(cfg)); err != nil {
+					log.Printf("[Main] WARNING: Invalid dialogue.goal_dedup, using default weights: %v", err)
+				}
+				engine.SetNoveltyThreshold(cfg.GrowerAI.Dialogue.NoveltyThreshold)
+				engine.SetReflectionDiversity(cfg.GrowerAI.Dialogue.ReflectionDiversityThreshold)
+				if err := engine.SetGoalProposalConfig(goalProposalConfig(cfg)); err != nil {
+					log.Printf("[Main] WARNING: Invalid dialogue.goal_proposals, using defaults: %v", err)
+				}
+				engine.SetMaxReplans(cfg.GrowerAI.Dialogue.MaxReplansPerGoal)
+				engine.SetSimpleContextSize(cfg.GrowerAI.SimpleModel.ContextSize)
+				if seed := cfg.GrowerAI.Dialogue.RandomSeed; seed != 0 {
+					engine.SetCycleSeed(seed)
+					log.Printf("[Main] ⚠ Dialogue cycles use fixed random seed %d", seed)
+				}
+				if err := engine.SetSynthesisGate(synthesisGateConfig(cfg)); err != nil {
+					log.Printf("[Main] WARNING: Invalid dialogue.synthesis_gate, using defaults: %v", err)
+				}
+				if conceptTagger != nil {
+					engine.SetConceptTagger(conceptTagger)
+				}
+				if tracing := cfg.GrowerAI.Tools.Tracing; tracing.OTLPEndpoint != "" {
+					engine.SetTraceExporter(tools.NewOTLPExporter(tracing.OTLPEndpoint, tracing.ServiceName, 0))
+					log.Printf("[Main] ✓ Exporting action traces to %s", tracing.OTLPEndpoint)
+				}
+				engine.SetPrincipleTrialConfig(cfg.GrowerAI.Dialogue.PrincipleTrials, cfg.GrowerAI.Dialogue.PrincipleTrialMargin)
+				engine.SetInjectionDetection(cfg.GrowerAI.Dialogue.InjectionDetection.Enabled, cfg.GrowerAI.Dialogue.InjectionDetection.ConfidencePenalty)
+				engine.SetActionTimeBudget(
+					time.Duration(cfg.GrowerAI.Dialogue.ActionTimeMarginSeconds)*time.Second,
+					time.Duration(cfg.GrowerAI.Dialogue.MinActionTimeSeconds)*time.Second,
+				)
+				if err := engine.SetModelRouting(cfg.GrowerAI.Dialogue.ModelRouting); err != nil {
+					log.Printf("[Main] WARNING: Invalid dialogue.model_routing, using default routing: %v", err)
+				}
+				if err := engine.SetSampling(dialogueSampling(cfg)); err != nil {
+					log.Printf("[Main] WARNING: Invalid growerai.sampling, using default sampling: %v", err)
+				}
+				engine.SetDeadlineEscalation(
+					time.Duration(cfg.GrowerAI.Dialogue.DeadlineEscalation.WindowHours*float64(time.Hour)),
+					cfg.GrowerAI.Dialogue.DeadlineEscalation.MaxBoost,
+					cfg.GrowerAI.Dialogue.DeadlineEscalation.CurveExponent,
+				)
+				if domainPolicy != nil {
+					engine.SetDomainPolicy(domainPolicy)
+				}
+				engine.SetToolHealth(toolHealth)
+				engine.SetTargetLanguage(cfg.GrowerAI.Tools.WebParse.Language.Target)
+				engine.SetResultStoreThreshold(cfg.GrowerAI.Dialogue.ResultStoreThresholdBytes)
+				engine.SetInterestHalfLife(time.Duration(cfg.GrowerAI.Dialogue.Interests.HalfLifeDays * float64(24*time.Hour)))
+				engine.SetMemoryReuse(memoryReuseConfig(cfg))
+				engine.SetSearchLedger(searchLedgerConfig(cfg))
+				engine.SetTranscripts(transcriptConfig(cfg))
+				engine.SetFocusConfig(focusConfig(cfg))
+				engine.SetStreaming(streamingConfig(cfg))
+				if err := loadSheddingConfig(cfg).Validate(); err != nil {
+					log.Printf("[Main] WARNING: Invalid dialogue.load_shedding, not shedding load: %v", err)
+				} else {
+					engine.SetLoadShedding(loadSheddingConfig(cfg))
+				}
+				if err := engine.SetBackpressure(backpressureConfig(cfg)); err != nil {
+					log.Printf("[Main] WARNING: Invalid dialogue.backpressure, not limiting goal proposals: %v", err)
+				}
+				engine.SetUserQuestionTimeout(time.Duration(cfg.GrowerAI.Dialogue.UserQuestions.TimeoutHours * float64(time.Hour)))
+				if client, ok := llmClient.(*llm.Client); ok {
+					engine.SetLLMLoadProbe(func() dialogue.LLMLoad {
+						stats := client.QueueStats()
+						return dialogue.LLMLoad{QueueDepth: stats.Depth, RecentWait: stats.RecentWait}
+					})
+				}
+				prompts, err := dialogue.LoadPromptRegistry(cfg.GrowerAI.Dialogue.PromptsDir)
+				if err != nil {
+					log.Fatalf("[Main] Invalid prompt templates in %s: %v", cfg.GrowerAI.Dialogue.PromptsDir, err)
+				}
+				engine.SetPromptRegistry(prompts)
+				if cfg.GrowerAI.Dialogue.CycleLock.Redis {
+					engine.SetCycleLease(
+						redisdb.NewLease(rdb, "growerai:dialogue:cycle_lock"),
+						time.Duration(cfg.GrowerAI.Dialogue.CycleLock.LeaseSeconds)*time.Second,
+					)
+					log.Printf("[Main] ✓ Dialogue cycle lease shared through Redis (ttl: %ds)", cfg.GrowerAI.Dialogue.CycleLock.LeaseSeconds)
+				}
+				if llmBudget != nil {
+					engine.SetTokenBudget(llmBudget)
+					llmBudget.OnAlert(func(alert llm.BudgetAlert) {
+						engine.PublishBudgetAlert(alert.Level, alert.Period, alert.Used, alert.Limit)
+					})
+				}
+				engine.SetReadiness(healthChecker, []string{
+					health.DependencyPostgres,
+					health.DependencyQdrant,
+					health.DependencyEmbeddings,
+					health.DependencyReasoningModel,
+				})
 
 				worker := dialogue.NewWorker(
 					engine,
@@ -385,9 +689,13 @@ func main() {
 					cfg.GrowerAI.Dialogue.JitterWindowMinutes,
 				)
 
+                configWatcher.Register(dialogueReloadHook(engine, worker))
                 go worker.Start()
                 appEngine = engine // Capture engine for router
 
+                digestWorker := dialogue.NewDigestWorker(engine, cfg.GrowerAI.Dialogue.DigestFrequency)
+                go digestWorker.Start()
+
                 log.Printf("[Main] ✓ GrowerAI dialogue worker started (interval: %d±%d minutes)",
                     cfg.GrowerAI.Dialogue.BaseIntervalMinutes,
                     cfg.GrowerAI.Dialogue.JitterWindowMinutes)
@@ -413,7 +721,10 @@ func main() {
     }
 
     // Initialize Router (Milestone 5: Pass appEngine)
-    r := api.SetupRouter(cfg, rdb, llmManager, criticalLLMClient, appEngine)
+    configWatcher.Start()
+    defer configWatcher.Stop()
+
+    r := api.SetupRouter(cfg, rdb, llmManager, criticalLLMClient, appEngine, decayWorker, retagWorker, domainPolicy, configWatcher, healthChecker)
     
     addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
     fmt.Printf("Starting server on %s%s\n", addr, cfg.Server.Subpath)
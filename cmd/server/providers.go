@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+
+	"go-llama/internal/config"
+	"go-llama/internal/llm"
+)
+
+// modelProvider creates the hosted provider serving a model role, or returns nil when
+// the role uses a local OpenAI-compatible server
+func modelProvider(model config.ModelConfig) llm.Provider {
+	if !model.Hosted() {
+		return nil
+	}
+	provider, err := llm.NewProvider(llm.ProviderConfig{
+		Name:            model.Provider,
+		URL:             model.URL,
+		APIKey:          model.Key(),
+		Organization:    model.Organization,
+		MaxOutputTokens: model.MaxOutputTokens,
+	}, nil)
+	if err != nil {
+		log.Fatalf("[Main] Failed to create %s provider for %s: %v", model.Provider, model.Name, err)
+	}
+	return provider
+}
+
+// registerModelProviders routes the queue clients' calls for hosted model roles to
+// their providers
+func registerModelProviders(cfg *config.Config, manager *llm.Manager) {
+	for role, model := range map[string]config.ModelConfig{
+		"reasoning":   cfg.GrowerAI.ReasoningModel,
+		"simple":      cfg.GrowerAI.SimpleModel,
+		"compression": cfg.GrowerAI.CompressionModel,
+	} {
+		if provider := modelProvider(model); provider != nil {
+			manager.SetProvider(config.GetChatURL(model.URL), provider)
+			log.Printf("[Main] ✓ %s model %s served by %s (%s)", role, model.Name, provider.Name(), model.URL)
+		}
+	}
+}
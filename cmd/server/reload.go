@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"go-llama/internal/config"
+	"go-llama/internal/dialogue"
+	"go-llama/internal/llm"
+	"go-llama/internal/tools"
+)
+
+// Config reload hooks: each one checks its part of a new config.json and applies it live.
+// The watcher only applies a file once every hook has accepted it.
+
+// searchToolConfig builds the search tool's timeouts and limits from config
+func searchToolConfig(cfg *config.Config) tools.ToolConfig {
+	return tools.ToolConfig{
+		Enabled:               cfg.GrowerAI.Tools.SearXNG.Enabled,
+		TimeoutInteractive:    time.Duration(cfg.GrowerAI.Tools.SearXNG.TimeoutInteractive) * time.Second,
+		TimeoutIdle:           time.Duration(cfg.GrowerAI.Tools.SearXNG.TimeoutIdle) * time.Second,
+		MaxResultsInteractive: cfg.GrowerAI.Tools.SearXNG.MaxResultsInteractive,
+		MaxResultsIdle:        cfg.GrowerAI.Tools.SearXNG.MaxResultsIdle,
+	}
+}
+
+// domainPolicyConfig reads the web parser's domain policy from config
+func domainPolicyConfig(cfg *config.Config) tools.DomainPolicyConfig {
+	return tools.DomainPolicyConfig{
+		Mode:     cfg.GrowerAI.Tools.WebParse.DomainPolicy.Mode,
+		Patterns: cfg.GrowerAI.Tools.WebParse.DomainPolicy.Patterns,
+	}
+}
+
+// dialogueSampling reads the sampling overrides for dialogue call types; the chat-side
+// summarize category goes to the summarizer instead
+func dialogueSampling(cfg *config.Config) map[string]dialogue.SamplingParams {
+	sampling := make(map[string]dialogue.SamplingParams, len(cfg.GrowerAI.Sampling))
+	for name, s := range cfg.GrowerAI.Sampling {
+		if name == config.SamplingSummarize {
+			continue
+		}
+		sampling[name] = dialogue.SamplingParams{
+			Temperature:   s.Temperature,
+			TopP:          s.TopP,
+			MaxTokens:     s.MaxTokens,
+			RepeatPenalty: s.RepeatPenalty,
+		}
+	}
+	return sampling
+}
+
+// goalDedupConfig reads the goal duplicate scoring weights from config
+func goalDedupConfig(cfg *config.Config) dialogue.GoalDedupConfig {
+	g := cfg.GrowerAI.Dialogue.GoalDedup
+	return dialogue.GoalDedupConfig{
+		StringWeight:    g.StringWeight,
+		KeywordWeight:   g.KeywordWeight,
+		EmbeddingWeight: g.EmbeddingWeight,
+		Threshold:       g.Threshold,
+	}
+}
+
+// adaptiveLimits reads the bounds and pins for the adaptive thresholds
+func adaptiveLimits(cfg *config.Config) dialogue.AdaptiveLimits {
+	a := cfg.GrowerAI.Dialogue.Adaptive
+	return dialogue.AdaptiveLimits{
+		SearchMin:            a.Bounds.SearchMin,
+		SearchMax:            a.Bounds.SearchMax,
+		CollectiveMax:        a.Bounds.CollectiveMax,
+		GoalSimilarityMin:    a.Bounds.GoalSimilarityMin,
+		GoalSimilarityMax:    a.Bounds.GoalSimilarityMax,
+		PinnedSearch:         a.Pins.SearchThreshold,
+		PinnedCollective:     a.Pins.CollectiveThreshold,
+		PinnedGoalSimilarity: a.Pins.GoalSimilarity,
+	}
+}
+
+// goalProposalConfig reads the checks applied to goals the model proposes
+func goalProposalConfig(cfg *config.Config) dialogue.GoalProposalConfig {
+	g := cfg.GrowerAI.Dialogue.GoalProposals
+	return dialogue.GoalProposalConfig{
+		DefaultPriority:     g.DefaultPriority,
+		MinDescriptionChars: g.MinDescriptionChars,
+		FeedbackWindow:      g.FeedbackWindowCycles,
+		MinAcceptanceRate:   g.MinAcceptanceRate,
+	}
+}
+
+// synthesisGateConfig reads the quality review research syntheses get before storing
+func synthesisGateConfig(cfg *config.Config) dialogue.SynthesisGateConfig {
+	g := cfg.GrowerAI.Dialogue.SynthesisGate
+	return dialogue.SynthesisGateConfig{
+		Enabled:  g.Enabled,
+		MinScore: g.MinScore,
+		OnFail:   g.OnFail,
+	}
+}
+
+// memoryReuseConfig reads when parse actions may be answered from memory
+func memoryReuseConfig(cfg *config.Config) dialogue.MemoryReuseConfig {
+	r := cfg.GrowerAI.Dialogue.MemoryReuse
+	return dialogue.MemoryReuseConfig{
+		Enabled:       r.Enabled,
+		MinSimilarity: r.MinSimilarity,
+		MaxAge:        time.Duration(r.MaxAgeDays * float64(24*time.Hour)),
+	}
+}
+
+// searchLedgerConfig reads when a search may reuse a recent identical search
+func searchLedgerConfig(cfg *config.Config) dialogue.SearchLedgerConfig {
+	l := cfg.GrowerAI.Dialogue.SearchLedger
+	return dialogue.SearchLedgerConfig{
+		Enabled:         l.Enabled,
+		Window:          time.Duration(l.WindowHours * float64(time.Hour)),
+		MinOverlap:      l.MinOverlap,
+		MinParseQuality: l.MinParseQuality,
+	}
+}
+
+// transcriptConfig reads whether cycles are recorded for replay
+func transcriptConfig(cfg *config.Config) dialogue.TranscriptConfig {
+	t := cfg.GrowerAI.Dialogue.Transcripts
+	return dialogue.TranscriptConfig{
+		Enabled:    t.Enabled,
+		KeepCycles: t.KeepCycles,
+	}
+}
+
+// focusConfig reads how self-assessed focus areas steer later cycles
+func focusConfig(cfg *config.Config) dialogue.FocusConfig {
+	f := cfg.GrowerAI.Dialogue.FocusAreas
+	return dialogue.FocusConfig{
+		ExpiryCycles:  f.ExpiryCycles,
+		PriorityBonus: f.PriorityBonus,
+		MinSimilarity: f.MinSimilarity,
+	}
+}
+
+// streamingConfig reads which dialogue calls are streamed
+func streamingConfig(cfg *config.Config) dialogue.StreamingConfig {
+	s := cfg.GrowerAI.Dialogue.Streaming
+	return dialogue.StreamingConfig{
+		Enabled:           s.Enabled,
+		MinSynthesisChars: s.MinSynthesisChars,
+	}
+}
+
+// loadSheddingConfig reads when an overloaded reasoning model sheds a cycle's reflection
+func loadSheddingConfig(cfg *config.Config) dialogue.LoadSheddingConfig {
+	l := cfg.GrowerAI.Dialogue.LoadShedding
+	return dialogue.LoadSheddingConfig{
+		Enabled:       l.Enabled,
+		MaxQueueDepth: l.MaxQueueDepth,
+		MaxWait:       time.Duration(l.MaxWaitSeconds * float64(time.Second)),
+	}
+}
+
+// backpressureConfig reads when the action backlog stops new goal proposals
+func backpressureConfig(cfg *config.Config) dialogue.BackpressureConfig {
+	d := cfg.GrowerAI.Dialogue
+	return dialogue.BackpressureConfig{
+		Enabled:          d.Backpressure.Enabled,
+		CycleInterval:    time.Duration(d.BaseIntervalMinutes) * time.Minute,
+		ActionsPerCycle:  d.Backpressure.ActionsPerCycle,
+		BacklogMultiple:  d.Backpressure.BacklogMultiple,
+		ShortestJobFirst: d.Backpressure.ShortestJobFirst,
+	}
+}
+
+// dialogueSettings collects the engine options a reload can change
+func dialogueSettings(cfg *config.Config) dialogue.Settings {
+	d := cfg.GrowerAI.Dialogue
+	return dialogue.Settings{
+		MaxTokensPerCycle:         d.MaxTokensPerCycle,
+		MaxDurationMinutes:        d.MaxDurationMinutes,
+		MaxThoughtsPerCycle:       d.MaxThoughtsPerCycle,
+		ActionRequirementInterval: d.ActionRequirementInterval,
+		NoveltyWindowHours:        d.NoveltyWindowHours,
+		NoveltyThreshold:          d.NoveltyThreshold,
+		MaxReplansPerGoal:         d.MaxReplansPerGoal,
+		ReflectionDiversity:       d.ReflectionDiversityThreshold,
+		ReasoningDepth:            d.ReasoningDepth,
+		EnableSelfAssessment:      d.EnableSelfAssessment,
+		EnableMetaLearning:        d.EnableMetaLearning,
+		EnableStrategyTracking:    d.EnableStrategyTracking,
+		StoreInsights:             d.StoreInsights,
+		DynamicActionPlanning:     d.DynamicActionPlanning,
+		DedupThreshold:            d.DedupThreshold,
+		PrincipleTrials:           d.PrincipleTrials,
+		PrincipleTrialMargin:      d.PrincipleTrialMargin,
+		InjectionDetection:        d.InjectionDetection.Enabled,
+		InjectionPenalty:          d.InjectionDetection.ConfidencePenalty,
+		ActionTimeMargin:          time.Duration(d.ActionTimeMarginSeconds) * time.Second,
+		MinActionTime:             time.Duration(d.MinActionTimeSeconds) * time.Second,
+		ModelRouting:              d.ModelRouting,
+		Sampling:                  dialogueSampling(cfg),
+		DeadlineWindow:            time.Duration(d.DeadlineEscalation.WindowHours * float64(time.Hour)),
+		DeadlineMaxBoost:          d.DeadlineEscalation.MaxBoost,
+		DeadlineCurveExponent:     d.DeadlineEscalation.CurveExponent,
+		GoalDedup:                 goalDedupConfig(cfg),
+		GoalProposals:             goalProposalConfig(cfg),
+		AdaptiveSearchThreshold:   d.Adaptive.SearchThreshold,
+		AdaptiveGoalSimilarity:    d.Adaptive.GoalSimilarity,
+		AdaptiveToolTimeout:       d.Adaptive.ToolTimeoutSeconds,
+		AdaptiveLimits:            adaptiveLimits(cfg),
+		InterestHalfLife:          time.Duration(d.Interests.HalfLifeDays * float64(24*time.Hour)),
+		MemoryReuse:               memoryReuseConfig(cfg),
+		SearchLedger:              searchLedgerConfig(cfg),
+		Transcripts:               transcriptConfig(cfg),
+		FocusAreas:                focusConfig(cfg),
+		Streaming:                 streamingConfig(cfg),
+		SynthesisGate:             synthesisGateConfig(cfg),
+		LoadShedding:              loadSheddingConfig(cfg),
+		Backpressure:              backpressureConfig(cfg),
+		UserQuestionTimeout:       time.Duration(d.UserQuestions.TimeoutHours * float64(time.Hour)),
+	}
+}
+
+// searchReloadHook updates the search tool's timeouts, limits and enabled flag
+func searchReloadHook(registry *tools.ContextualRegistry) config.ReloadHook {
+	return config.ReloadHook{
+		Name: "tools.search",
+		Check: func(next *config.Config) error {
+			s := next.GrowerAI.Tools.SearXNG
+			if s.TimeoutInteractive <= 0 || s.TimeoutIdle <= 0 {
+				return fmt.Errorf("searxng timeouts must be positive")
+			}
+			return nil
+		},
+		Apply: func(next *config.Config) {
+			registry.UpdateConfig(tools.ToolNameSearch, searchToolConfig(next))
+		},
+	}
+}
+
+// domainPolicyReloadHook swaps the web parser's domain allowlist or blocklist
+func domainPolicyReloadHook(policy *tools.DomainPolicy) config.ReloadHook {
+	return config.ReloadHook{
+		Name: "tools.domain_policy",
+		Check: func(next *config.Config) error {
+			_, err := tools.NewDomainPolicy(domainPolicyConfig(next))
+			return err
+		},
+		Apply: func(next *config.Config) {
+			// Same config NewDomainPolicy accepted in Check
+			policy.Update(domainPolicyConfig(next))
+		},
+	}
+}
+
+// budgetConfig reads the hosted token limits from config
+func budgetConfig(cfg *config.Config) llm.BudgetConfig {
+	return llm.BudgetConfig{
+		DailySoftTokens:   cfg.GrowerAI.Budget.DailySoftTokens,
+		DailyHardTokens:   cfg.GrowerAI.Budget.DailyHardTokens,
+		MonthlySoftTokens: cfg.GrowerAI.Budget.MonthlySoftTokens,
+		MonthlyHardTokens: cfg.GrowerAI.Budget.MonthlyHardTokens,
+	}
+}
+
+// budgetReloadHook applies new token limits; parseConfig has already validated them
+func budgetReloadHook(budget *llm.BudgetTracker) config.ReloadHook {
+	return config.ReloadHook{
+		Name: "budget",
+		Apply: func(next *config.Config) {
+			budget.SetLimits(budgetConfig(next))
+		},
+	}
+}
+
+// modelsReloadHook rejects a reload whose model names their servers do not serve. The
+// roles themselves only change on restart, so there is nothing to apply.
+func modelsReloadHook() config.ReloadHook {
+	return config.ReloadHook{
+		Name:  "models",
+		Check: config.VerifyModels,
+		Apply: func(next *config.Config) {},
+	}
+}
+
+// dialogueReloadHook applies engine settings between cycles and the worker's schedule
+func dialogueReloadHook(engine *dialogue.Engine, worker *dialogue.Worker) config.ReloadHook {
+	return config.ReloadHook{
+		Name: "dialogue",
+		Check: func(next *config.Config) error {
+			d := next.GrowerAI.Dialogue
+			if d.BaseIntervalMinutes <= 0 || d.JitterWindowMinutes < 0 {
+				return fmt.Errorf("base_interval_minutes must be positive and jitter_window_minutes not negative")
+			}
+			return engine.CheckSettings(dialogueSettings(next))
+		},
+		Apply: func(next *config.Config) {
+			engine.ApplySettings(dialogueSettings(next))
+			worker.SetInterval(next.GrowerAI.Dialogue.BaseIntervalMinutes, next.GrowerAI.Dialogue.JitterWindowMinutes)
+		},
+	}
+}
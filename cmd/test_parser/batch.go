@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-llama/internal/utils"
+)
+
+// previewChars is how much parser output each JSON line carries
+const previewChars = 500
+
+// parseTarget is one URL to parse, with the goal passed to goal-directed parsers
+type parseTarget struct {
+	URL  string
+	Goal string
+}
+
+// parseResult is the JSON line emitted for one URL
+type parseResult struct {
+	Type         string                 `json:"type"` // Always "result"
+	URL          string                 `json:"url"`
+	Goal         string                 `json:"goal,omitempty"`
+	Tool         string                 `json:"tool"`
+	Success      bool                   `json:"success"`
+	Error        string                 `json:"error,omitempty"`
+	DurationMS   int64                  `json:"duration_ms"`
+	Tokens       int                    `json:"tokens"`
+	OutputLength int                    `json:"output_length"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Preview      string                 `json:"preview"`
+
+	output string // Full output, printed in single-URL mode
+}
+
+// batchSummary is the final JSON line of a batch run
+type batchSummary struct {
+	Type       string   `json:"type"` // Always "summary"
+	Tool       string   `json:"tool"`
+	Total      int      `json:"total"`
+	Succeeded  int      `json:"succeeded"`
+	Failed     int      `json:"failed"`
+	DurationMS int64    `json:"duration_ms"`
+	FailedURLs []string `json:"failed_urls,omitempty"`
+}
+
+// readTargets reads one URL per line with an optional tab-separated goal; "-" is stdin
+func readTargets(path string) ([]parseTarget, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return parseTargets(r)
+}
+
+// parseTargets skips blank lines and # comments
+func parseTargets(r io.Reader) ([]parseTarget, error) {
+	var targets []parseTarget
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		url, goal, _ := strings.Cut(line, "\t")
+		targets = append(targets, parseTarget{URL: strings.TrimSpace(url), Goal: strings.TrimSpace(goal)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no URLs found")
+	}
+	return targets, nil
+}
+
+// runOne parses a single target under its own timeout
+func runOne(target parseTarget, parse parseFunc, toolName string, timeout time.Duration) *parseResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result := &parseResult{Type: "result", URL: target.URL, Goal: target.Goal, Tool: toolName}
+	start := time.Now()
+	toolResult, err := parse(ctx, target.URL, target.Goal)
+	result.DurationMS = time.Since(start).Milliseconds()
+
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+		if toolResult != nil && toolResult.Error != "" {
+			result.Error = toolResult.Error
+		}
+	case toolResult == nil:
+		result.Error = "parser returned no result"
+	case !toolResult.Success:
+		result.Error = toolResult.Error
+	default:
+		result.Success = true
+	}
+	if toolResult == nil {
+		return result
+	}
+
+	result.Metadata = toolResult.Metadata
+	result.Tokens = toolResult.TokensUsed
+	if result.Tokens == 0 {
+		if final, ok := toolResult.Metadata["final_tokens"].(int); ok {
+			result.Tokens = final
+		}
+	}
+	result.output = toolResult.Output
+	result.OutputLength = len(toolResult.Output)
+	result.Preview = utils.Prefix(toolResult.Output, previewChars)
+	return result
+}
+
+// runBatch parses targets with up to parallel workers, writing a JSON line per URL as it
+// finishes and a summary line at the end
+func runBatch(targets []parseTarget, parse parseFunc, toolName string, parallel int, timeout time.Duration, w io.Writer) batchSummary {
+	start := time.Now()
+	summary := batchSummary{Type: "summary", Tool: toolName, Total: len(targets)}
+	enc := json.NewEncoder(w)
+
+	var mu sync.Mutex
+	jobs := make(chan parseTarget)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				result := runOne(target, parse, toolName, timeout)
+
+				mu.Lock()
+				enc.Encode(result)
+				if result.Success {
+					summary.Succeeded++
+				} else {
+					summary.Failed++
+					summary.FailedURLs = append(summary.FailedURLs, result.URL)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, target := range targets {
+		jobs <- target
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary.DurationMS = time.Since(start).Milliseconds()
+	enc.Encode(summary)
+	return summary
+}
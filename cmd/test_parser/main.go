@@ -1,135 +1,146 @@
 package main
 
 import (
-    "bytes"
-    "context"
-    "encoding/json"
-    "fmt"
-    "io"
-    "log"
-    "net/http"
-    "os"
-    "time"
-
-    "go-llama/internal/config"
-    "go-llama/internal/tools"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"go-llama/internal/config"
+	"go-llama/internal/httpclient"
 )
 
 // directLLMClient is a minimal HTTP client to bypass the Queue Manager for testing
 type directLLMClient struct{}
 
 func (d *directLLMClient) Call(ctx context.Context, llmURL string, payload map[string]interface{}) ([]byte, error) {
-    jsonPayload, err := json.Marshal(payload)
-    if err != nil {
-        return nil, err
-    }
-
-    req, err := http.NewRequestWithContext(ctx, "POST", llmURL, bytes.NewBuffer(jsonPayload))
-    if err != nil {
-        return nil, err
-    }
-    req.Header.Set("Content-Type", "application/json")
-
-    client := &http.Client{Timeout: 120 * time.Second}
-    resp, err := client.Do(req)
-    if err != nil {
-        return nil, err
-    }
-    defer resp.Body.Close()
-
-    if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("LLM returned status %d", resp.StatusCode)
-    }
-
-    return io.ReadAll(resp.Body)
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", llmURL, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := httpclient.New(120 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LLM returned status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: go run ./cmd/test_parser [flags] <URL> [GOAL]")
+	fmt.Fprintln(out, "       go run ./cmd/test_parser [flags] -file urls.txt")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Batch files hold one URL per line with an optional tab-separated goal.")
+	fmt.Fprintln(out, "Blank lines and lines starting with # are ignored; -file - reads stdin.")
+	fmt.Fprintln(out, "Batch mode prints one JSON line per URL and a summary line, and exits 1 if any URL fails.")
+	fmt.Fprintln(out, "")
+	flag.PrintDefaults()
 }
 
 func main() {
-    // 1. Check Args
-    args := os.Args
-    if len(args) < 2 {
-        fmt.Println("Usage: go run cmd/test_parser/main.go <URL> [GOAL]")
-        fmt.Println("Example: go run cmd/test_parser/main.go https://example.com")
-        fmt.Println("Example (Selective): go run cmd/test_parser/main.go https://example.com \"find information about pricing\"")
-        os.Exit(1)
-    }
-
-    targetURL := args[1]
-    goal := ""
-    if len(args) >= 3 {
-        goal = args[2]
-    }
-
-    // 2. Load Config
-    cfg, err := config.LoadConfig("config.json")
-    if err != nil {
-        log.Fatalf("Failed to load config.json: %v", err)
-    }
-
-    // 3. Setup Tool
-    // Use a generous timeout for testing
-    toolConfig := tools.ToolConfig{
-        Enabled:     true,
-        TimeoutIdle: 120, // 2 minutes
-    }
-
-    llmClient := &directLLMClient{}
-    
-    // Use the Reasoning Model URL and Name from config
-    llmURL := config.GetChatURL(cfg.GrowerAI.ReasoningModel.URL)
-    llmModel := cfg.GrowerAI.ReasoningModel.Name
-
-    tool := tools.NewWebParserUnifiedTool(
-        cfg.GrowerAI.Tools.WebParse.UserAgent,
-        llmURL,
-        llmModel,
-        cfg.GrowerAI.Tools.WebParse.MaxPageSizeMB,
-        toolConfig,
-        llmClient, // Inject our direct client
-    )
-
-    fmt.Printf("Testing Web Parser...\n")
-    fmt.Printf("URL: %s\n", targetURL)
-    if goal != "" {
-        fmt.Printf("GOAL: %s\n", goal)
-    } else {
-        fmt.Printf("GOAL: (None - Full Parse Mode)\n")
-    }
-    fmt.Println("---")
-
-    // 4. Execute
-    ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
-    defer cancel()
-
-    params := map[string]interface{}{
-        "url":  targetURL,
-        "goal": goal, // Can be empty string
-    }
-
-    result, err := tool.Execute(ctx, params)
-
-    // 5. Output
-    if err != nil {
-        log.Fatalf("Tool Execution Failed: %v", err)
-    }
-
-    fmt.Printf("\n\n=== RESULT ===\n")
-    fmt.Printf("Success: %v\n", result.Success)
-    if !result.Success {
-        fmt.Printf("Error: %s\n", result.Error)
-        os.Exit(1)
-    }
-
-    fmt.Printf("Duration: %v\n", result.Duration)
-    fmt.Printf("Tokens Used: %d\n", result.TokensUsed)
-    
-    if result.Metadata != nil {
-        fmt.Printf("\n--- METADATA ---\n")
-        for k, v := range result.Metadata {
-            fmt.Printf("%s: %v\n", k, v)
-        }
-    }
-
-    fmt.Printf("\n--- OUTPUT ---\n")
-    fmt.Println(result.Output)
+	file := flag.String("file", "", "File of URLs to parse in batch mode")
+	toolName := flag.String("tool", toolUnified, "Parser to run: unified, metadata, general, contextual or chunked")
+	parallel := flag.Int("parallel", 1, "Number of URLs to parse concurrently in batch mode")
+	timeout := flag.Duration("timeout", 180*time.Second, "Timeout per URL")
+	jsonOut := flag.Bool("json", false, "Print the single-URL result as a JSON line")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *file == "" && flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *parallel < 1 {
+		*parallel = 1
+	}
+
+	// Load Config
+	cfg, err := config.LoadConfig("config.json")
+	if err != nil {
+		log.Fatalf("Failed to load config.json: %v", err)
+	}
+
+	parse, err := newParser(*toolName, cfg, *timeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *file != "" {
+		targets, err := readTargets(*file)
+		if err != nil {
+			log.Fatalf("Failed to read URL file: %v", err)
+		}
+		summary := runBatch(targets, parse, *toolName, *parallel, *timeout, os.Stdout)
+		if summary.Failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	target := parseTarget{URL: flag.Arg(0)}
+	if flag.NArg() >= 2 {
+		target.Goal = flag.Arg(1)
+	}
+	result := runOne(target, parse, *toolName, *timeout)
+	if *jsonOut {
+		json.NewEncoder(os.Stdout).Encode(result)
+	} else {
+		printResult(target, result)
+	}
+	if !result.Success {
+		os.Exit(1)
+	}
+}
+
+// printResult prints a single-URL result for reading by hand
+func printResult(target parseTarget, result *parseResult) {
+	fmt.Printf("Testing Web Parser (%s)...\n", result.Tool)
+	fmt.Printf("URL: %s\n", target.URL)
+	if target.Goal != "" {
+		fmt.Printf("GOAL: %s\n", target.Goal)
+	} else {
+		fmt.Printf("GOAL: (None - Full Parse Mode)\n")
+	}
+	fmt.Println("---")
+
+	fmt.Printf("\n\n=== RESULT ===\n")
+	fmt.Printf("Success: %v\n", result.Success)
+	if !result.Success {
+		fmt.Printf("Error: %s\n", result.Error)
+		return
+	}
+
+	fmt.Printf("Duration: %v\n", time.Duration(result.DurationMS)*time.Millisecond)
+	fmt.Printf("Tokens: %d\n", result.Tokens)
+
+	if result.Metadata != nil {
+		fmt.Printf("\n--- METADATA ---\n")
+		for k, v := range result.Metadata {
+			fmt.Printf("%s: %v\n", k, v)
+		}
+	}
+
+	fmt.Printf("\n--- OUTPUT ---\n")
+	fmt.Println(result.output)
 }
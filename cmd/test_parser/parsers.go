@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-llama/internal/config"
+	"go-llama/internal/tools"
+)
+
+// Parsers selectable with -tool
+const (
+	toolUnified    = "unified"    // Production web_parse_unified tool in its configured mode
+	toolMetadata   = "metadata"   // Page title, headings and size only
+	toolGeneral    = "general"    // Full clean text from the basic HTML parser
+	toolContextual = "contextual" // Unified tool forced into goal-directed selective mode
+	toolChunked    = "chunked"    // First 500-token chunk from the basic HTML parser
+)
+
+// chunkSizeChars matches the ~500 token chunks used by the web parser
+const chunkSizeChars = 2000
+
+// parseFunc runs one parser against a URL with an optional goal
+type parseFunc func(ctx context.Context, url, goal string) (*tools.ToolResult, error)
+
+// newParser builds the parser named by -tool from the server config
+func newParser(name string, cfg *config.Config, timeout time.Duration) (parseFunc, error) {
+	webParse := cfg.GrowerAI.Tools.WebParse
+
+	switch name {
+	case toolUnified, toolContextual:
+		// Use the Reasoning Model URL and Name from config
+		toolConfig := tools.ToolConfig{Enabled: true, TimeoutInteractive: timeout, TimeoutIdle: timeout}
+		maxContentTokens := int(float64(cfg.GrowerAI.ReasoningModel.ContextSize) * 0.66)
+		tool := tools.NewWebParserUnifiedTool(
+			webParse.UserAgent,
+			cfg.GrowerAI.ReasoningModel.URL,
+			cfg.GrowerAI.ReasoningModel.Name,
+			webParse.MaxPageSizeMB,
+			toolConfig,
+			&directLLMClient{}, // Inject our direct client
+			maxContentTokens,
+		)
+		tool.SetExtractionMode(webParse.ExtractionMode)
+
+		return func(ctx context.Context, url, goal string) (*tools.ToolResult, error) {
+			params := map[string]interface{}{"url": url, "goal": goal}
+			if name == toolContextual {
+				if goal == "" {
+					return nil, fmt.Errorf("contextual parser needs a goal")
+				}
+				params["extraction_mode"] = tools.ExtractionModeSelective
+			}
+			return tool.Execute(ctx, params)
+		}, nil
+
+	case toolMetadata, toolGeneral, toolChunked:
+		client := tools.NewWebParserClient(timeout, webParse.UserAgent, webParse.MaxPageSizeMB)
+		return func(ctx context.Context, url, goal string) (*tools.ToolResult, error) {
+			start := time.Now()
+			content, err := client.FetchAndParse(ctx, url)
+			if err != nil {
+				return nil, err
+			}
+			meta := client.ExtractMetadata(content)
+			metadata := map[string]interface{}{
+				"title":        meta.Title,
+				"total_tokens": meta.TotalTokens,
+				"total_chunks": meta.TotalChunks,
+				"headings":     len(meta.Headings),
+				"word_count":   content.WordCount,
+			}
+
+			var output string
+			switch name {
+			case toolMetadata:
+				output = fmt.Sprintf("Title: %s\nTokens: %d\nChunks: %d\nHeadings:\n- %s\n\nSummary: %s",
+					meta.Title, meta.TotalTokens, meta.TotalChunks, strings.Join(meta.Headings, "\n- "), meta.BriefSummary)
+			case toolGeneral:
+				output = content.CleanText
+			case toolChunked:
+				chunks := client.ChunkContent(content, chunkSizeChars)
+				metadata["chunks"] = len(chunks)
+				if len(chunks) > 0 {
+					metadata["chunk_heading"] = chunks[0].Heading
+					output = chunks[0].Text
+				}
+			}
+
+			return &tools.ToolResult{
+				Success:    true,
+				Output:     output,
+				Duration:   time.Since(start),
+				TokensUsed: client.EstimateTokens(output),
+				Metadata:   metadata,
+			}, nil
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown -tool %q: use %s, %s, %s, %s or %s",
+		name, toolUnified, toolMetadata, toolGeneral, toolContextual, toolChunked)
+}
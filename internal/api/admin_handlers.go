@@ -0,0 +1,369 @@
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "go-llama/internal/config"
+    "go-llama/internal/db"
+    "go-llama/internal/llm"
+    "go-llama/internal/memory"
+    "go-llama/internal/tools"
+)
+
+// --- Admin: compression worker ---
+
+// CompressionRunHandler runs one compression pass synchronously and returns its report
+// POST /admin/compression/run
+func CompressionRunHandler(worker *memory.DecayWorker) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if worker == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Compression worker not enabled"})
+            return
+        }
+
+        report, err := worker.RunOnce(c.Request.Context())
+        if errors.Is(err, memory.ErrCompressionInProgress) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, report)
+    }
+}
+
+// CompressionLastReportHandler returns the report from the most recent pass
+// GET /admin/compression/last-report
+func CompressionLastReportHandler(worker *memory.DecayWorker) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if worker == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Compression worker not enabled"})
+            return
+        }
+
+        report := worker.LastReport()
+        if report == nil {
+            c.JSON(http.StatusNotFound, gin.H{"error": "No compression pass has completed yet"})
+            return
+        }
+
+        c.JSON(http.StatusOK, report)
+    }
+}
+
+// CompressionPreviewHandler reports what the next compression pass would compress under
+// the live space-based limits, and what the deprecated age-based tier rules would move
+// GET /admin/compression/preview?recent_days=...&medium_days=...&long_days=...
+// The day parameters only change the age-rule simulation; omitted ones fall back to the
+// worker's configured rules.
+func CompressionPreviewHandler(worker *memory.DecayWorker) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if worker == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Compression worker not enabled"})
+            return
+        }
+
+        rules := worker.TierRules()
+        params := []struct {
+            name   string
+            target *int
+        }{
+            {"recent_days", &rules.RecentToMediumDays},
+            {"medium_days", &rules.MediumToLongDays},
+            {"long_days", &rules.LongToAncientDays},
+        }
+        for _, p := range params {
+            raw := c.Query(p.name)
+            if raw == "" {
+                continue
+            }
+            days, err := strconv.Atoi(raw)
+            if err != nil || days < 0 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + p.name + ": must be a non-negative integer"})
+                return
+            }
+            *p.target = days
+        }
+
+        preview, err := worker.Preview(c.Request.Context(), rules)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, preview)
+    }
+}
+
+// EvictionPreviewHandler reports which collective memories the cap would evict
+// GET /admin/compression/eviction-preview?cap=...
+// An omitted cap falls back to the worker's configured ceiling.
+func EvictionPreviewHandler(worker *memory.DecayWorker) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if worker == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Compression worker not enabled"})
+            return
+        }
+
+        ceiling := worker.CollectiveCap()
+        if raw := c.Query("cap"); raw != "" {
+            parsed, err := strconv.Atoi(raw)
+            if err != nil || parsed < 0 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cap: must be a non-negative integer"})
+                return
+            }
+            ceiling = parsed
+        }
+
+        preview, err := worker.PreviewEviction(c.Request.Context(), ceiling)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, preview)
+    }
+}
+
+// MemoryStatsHandler returns tier, concept tag, trust and source kind aggregates for
+// dashboards. Stats are cached for a minute, so polling is cheap.
+// GET /admin/memory/stats
+func MemoryStatsHandler(worker *memory.DecayWorker) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if worker == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Compression worker not enabled"})
+            return
+        }
+
+        stats, err := worker.MemoryStats(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, stats)
+    }
+}
+
+// --- Admin: concept tag backfill ---
+
+// RetagRunHandler starts (or resumes) a retag run in the background. Runs can take
+// hours, so progress is read from the status endpoint.
+// POST /admin/retag/run
+func RetagRunHandler(worker *memory.RetagWorker) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if worker == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Retag worker not enabled (requires compression)"})
+            return
+        }
+
+        // Detached from the request: the run outlives it
+        err := worker.Start(context.Background())
+        if errors.Is(err, memory.ErrRetagInProgress) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusAccepted, worker.Status(c.Request.Context()))
+    }
+}
+
+// RetagStatusHandler reports the current pass's checkpoint and the last run's report
+// GET /admin/retag/status
+func RetagStatusHandler(worker *memory.RetagWorker) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if worker == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Retag worker not enabled (requires compression)"})
+            return
+        }
+        c.JSON(http.StatusOK, worker.Status(c.Request.Context()))
+    }
+}
+
+// --- Admin: web fetch domain policy ---
+
+// DomainPolicyHandler returns the active domain policy
+// GET /admin/domain-policy
+func DomainPolicyHandler(policy *tools.DomainPolicy) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if policy == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Web parser not enabled"})
+            return
+        }
+        c.JSON(http.StatusOK, policy.Config())
+    }
+}
+
+// DomainPolicyReloadHandler re-reads the domain policy from the config file and applies
+// it without a restart; the previous policy stays active if the file is invalid
+// POST /admin/domain-policy/reload
+func DomainPolicyReloadHandler(policy *tools.DomainPolicy, configPath string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if policy == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Web parser not enabled"})
+            return
+        }
+
+        cfg, err := config.ReadConfig(configPath)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        domainPolicy := cfg.GrowerAI.Tools.WebParse.DomainPolicy
+        if err := policy.Update(tools.DomainPolicyConfig{Mode: domainPolicy.Mode, Patterns: domainPolicy.Patterns}); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, policy.Config())
+    }
+}
+
+// --- Admin: config reload ---
+
+// ConfigStatusHandler returns the hash of the applied config file and the outcome of the
+// last reload, including settings that changed but need a restart
+// GET /admin/config/status
+func ConfigStatusHandler(watcher *config.Watcher) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if watcher == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Config watcher not running"})
+            return
+        }
+        c.JSON(http.StatusOK, watcher.Status())
+    }
+}
+
+// ConfigReloadHandler reloads the config file now instead of waiting for the next poll;
+// an invalid file is rejected as a whole and the running config stays active
+// POST /admin/config/reload
+func ConfigReloadHandler(watcher *config.Watcher) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if watcher == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Config watcher not running"})
+            return
+        }
+        status, err := watcher.Reload()
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "status": status})
+            return
+        }
+        c.JSON(http.StatusOK, status)
+    }
+}
+
+// --- Admin: principle history ---
+
+// PrincipleHistoryHandler lists principle changes, newest first
+// GET /admin/principles/history?slot=...&limit=...
+func PrincipleHistoryHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        slot := -1
+        if raw := c.Query("slot"); raw != "" {
+            parsed, err := strconv.Atoi(raw)
+            if err != nil || parsed < 0 || parsed > 10 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slot: must be 0-10"})
+                return
+            }
+            slot = parsed
+        }
+
+        limit := 100
+        if raw := c.Query("limit"); raw != "" {
+            parsed, err := strconv.Atoi(raw)
+            if err != nil || parsed < 1 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit: must be a positive integer"})
+                return
+            }
+            limit = parsed
+        }
+
+        entries, err := memory.ListPrincipleHistory(db.DB, slot, limit)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, gin.H{"history": entries})
+    }
+}
+
+// PrincipleRollbackHandler restores a principle slot to an earlier version
+// POST /admin/principles/:slot/rollback  {"version": N}
+func PrincipleRollbackHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        slot, err := strconv.Atoi(c.Param("slot"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slot"})
+            return
+        }
+
+        var req struct {
+            Version *int `json:"version"`
+        }
+        if err := c.ShouldBindJSON(&req); err != nil || req.Version == nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must include a version"})
+            return
+        }
+
+        entry, err := memory.RollbackPrinciple(db.DB, slot, *req.Version)
+        switch {
+        case errors.Is(err, memory.ErrProtectedPrincipleSlot):
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        case errors.Is(err, memory.ErrPrincipleVersionNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+            return
+        case err != nil:
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        c.JSON(http.StatusOK, entry)
+    }
+}
+
+// --- Admin: LLM queue ---
+
+// LLMQueueMetricsHandler reports queue depth, throughput and wait time per priority lane
+// GET /admin/llm-queue
+func LLMQueueMetricsHandler(llmManager interface{}) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        mgr, ok := llmManager.(*llm.Manager)
+        if !ok || mgr == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "LLM queue not enabled"})
+            return
+        }
+        c.JSON(http.StatusOK, mgr.QueueMetrics())
+    }
+}
+
+// LLMBudgetHandler reports the hosted token budget level, usage against the daily and
+// monthly limits, and today's tokens per provider and model
+// GET /admin/llm-budget
+func LLMBudgetHandler(llmManager interface{}) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        mgr, ok := llmManager.(*llm.Manager)
+        if !ok || mgr == nil || mgr.Budget() == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "LLM budget not enabled"})
+            return
+        }
+        status, err := mgr.Budget().Status(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load LLM usage"})
+            return
+        }
+        c.JSON(http.StatusOK, status)
+    }
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-llama/internal/memory"
+	"go-llama/internal/testinfra"
+)
+
+// blockingCaller holds compression LLM calls until released, keeping a pass running
+type blockingCaller struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (b *blockingCaller) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	b.once.Do(func() { close(b.started) })
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"content": "Bees navigate by the sun."}}},
+		"usage":   map[string]int{"total_tokens": 42},
+	})
+}
+
+// compressionWorker builds a DecayWorker over a fake Qdrant holding enough recent
+// memories to trigger compression
+func compressionWorker(t *testing.T, caller *blockingCaller) *memory.DecayWorker {
+	t.Helper()
+	ctx := context.Background()
+	qdrant, err := testinfra.NewFakeQdrant()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(qdrant.Close)
+	llm := testinfra.NewFakeLLM()
+	t.Cleanup(llm.Close)
+
+	client, err := qdrant.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	storage, err := memory.NewStorageFromClient(client, "compression_admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Migrations already ran, so the pass goes straight to compression
+	db, err := testinfra.OpenDB(nil,
+		`CREATE TABLE growerai_dialogue_state (id integer PRIMARY KEY, migration_memory_id_complete boolean,
+			migration_is_collective_complete boolean, migration_source_kind_complete boolean)`,
+		`INSERT INTO growerai_dialogue_state VALUES (1, true, true, true)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		content := fmt.Sprintf("Bees navigate using the sun, observation %d", i)
+		mem := &memory.Memory{
+			Content:      content,
+			Tier:         memory.TierRecent,
+			IsCollective: true,
+			OutcomeTag:   string(memory.OutcomeGood),
+			ConceptTags:  []string{"bees"},
+			CreatedAt:    time.Now().UTC().AddDate(0, 0, -30+i),
+			Embedding:    testinfra.Embed(content),
+		}
+		if err := storage.Store(ctx, mem); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	embedder := memory.NewEmbedder(llm.EmbeddingsURL())
+	linker := memory.NewLinker(storage, 0.8, 5)
+	compressor := memory.NewCompressor("http://compressor", "fake", embedder, linker, caller)
+	deps := memory.NewWorkerDeps(storage, compressor, embedder, db, "", "", "", "", nil)
+	// A recent tier of 3 compresses from 2 memories
+	limits := memory.StorageLimits{
+		MaxTotalMemories:   10,
+		TierAllocation:     memory.TierAllocation{Recent: 0.3, Medium: 0.3, Long: 0.2, Ancient: 0.2},
+		CompressionTrigger: 0.7,
+	}
+	return memory.NewDecayWorker(deps, nil, linker, 24, memory.TierRules{}, memory.MergeWindows{}, 0, 0,
+		limits, memory.CompressionWeights{Age: 1})
+}
+
+func TestCompressionAdminHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	caller := &blockingCaller{started: make(chan struct{}), release: make(chan struct{})}
+	worker := compressionWorker(t, caller)
+	r := gin.New()
+	r.POST("/admin/compression/run", CompressionRunHandler(worker))
+	r.GET("/admin/compression/last-report", CompressionLastReportHandler(worker))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	if w := serve("GET", "/admin/compression/last-report"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before any pass, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The first run blocks in the compressor; a second one must not run beside it
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- serve("POST", "/admin/compression/run") }()
+	select {
+	case <-caller.started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the pass to reach the compressor")
+	}
+	if w := serve("POST", "/admin/compression/run"); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 while a pass runs, got %d: %s", w.Code, w.Body.String())
+	}
+	close(caller.release)
+
+	w := <-first
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from the run, got %d: %s", w.Code, w.Body.String())
+	}
+	var report memory.CompressionReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Trigger != memory.CompressionTriggerManual || len(report.Transitions) != 3 {
+		t.Fatalf("expected a manual report over three transitions, got %+v", report)
+	}
+	recent := report.Transitions[0]
+	if recent.From != memory.TierRecent || recent.Count != 4 || recent.Limit != 3 || recent.Candidates == 0 {
+		t.Errorf("expected recent memories selected for compression, got %+v", recent)
+	}
+	if report.CompressorTokens == 0 || report.StatsBefore == nil || report.StatsBefore.Total != 4 {
+		t.Errorf("expected compressor tokens and stats recorded, got %+v", report)
+	}
+
+	w = serve("GET", "/admin/compression/last-report")
+	var last memory.CompressionReport
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &last) != nil {
+		t.Fatalf("expected the last report, got %d: %s", w.Code, w.Body.String())
+	}
+	if !last.StartedAt.Equal(report.StartedAt) || last.Trigger != memory.CompressionTriggerManual {
+		t.Errorf("expected the run's report, got %+v", last)
+	}
+}
+
+func TestCompressionAdminHandlersWithoutWorker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/admin/compression/run", CompressionRunHandler(nil))
+	r.GET("/admin/compression/last-report", CompressionLastReportHandler(nil))
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/admin/compression/run", nil),
+		httptest.NewRequest("GET", "/admin/compression/last-report", nil),
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: expected 503 without a worker, got %d", req.Method, req.URL.Path, w.Code)
+		}
+	}
+}
@@ -19,11 +19,42 @@ import (
     "go-llama/internal/config"
     "go-llama/internal/db"
     "go-llama/internal/dialogue"
+    "go-llama/internal/goal"
+    "go-llama/internal/httpclient"
+    "go-llama/internal/llm"
     "go-llama/internal/memory"
+    "go-llama/internal/user"
+    "go-llama/internal/utils"
     "gorm.io/gorm"
 )
 
-func HandleGrowerAIMessage(c *gin.Context, cfg *config.Config, chatInst *chat.Chat, content string, userID uint) {
+// retrievalSourceKinds returns the memory sources a GrowerAI message retrieves from:
+// the request's list when given (empty = all sources), else the config default
+func retrievalSourceKinds(cfg *config.Config, requested []string) ([]string, error) {
+	if requested == nil {
+		return cfg.GrowerAI.Retrieval.SourceKinds, nil
+	}
+	if err := memory.ValidateSourceKinds(requested); err != nil {
+		return nil, err
+	}
+	return requested, nil
+}
+
+// statusContext returns a summary of the dialogue state to ground the reply when the
+// message asks what the system has been working on, or "" otherwise
+func statusContext(ctx context.Context, cfg *config.Config, engine *dialogue.Engine, userID uint, content string) string {
+	if engine == nil || !cfg.GrowerAI.Dialogue.StatusIntent.Enabled || !dialogue.DetectStatusQuestion(content) {
+		return ""
+	}
+	summary := engine.ExportStatusSummaryForUser(ctx, fmt.Sprintf("%d", userID), cfg.GrowerAI.Dialogue.StatusIntent.MaxTokens)
+	if summary == "" {
+		summary = "YOUR CURRENT ACTIVITY: no goals or recent research on record. Say so rather than inventing any."
+	}
+	log.Printf("[GrowerAI] Status question detected, added %d chars of dialogue state", len(summary))
+	return summary
+}
+
+func HandleGrowerAIMessage(c *gin.Context, cfg *config.Config, chatInst *chat.Chat, content string, userID uint, sourceKinds []string, engine *dialogue.Engine) {
 	log.Printf("[GrowerAI] Processing message from user %d in chat %d", userID, chatInst.ID)
 	
 	// Save user's message first
@@ -63,6 +94,9 @@ func HandleGrowerAIMessage(c *gin.Context, cfg *config.Config, chatInst *chat.Ch
 		return
 	}
 
+	// "Stop researching X" keeps X out of research goals for a while
+	detectTopicSuppression(ctx, cfg, embedder, storage, userID, content)
+
 	// STEP 1: Generate embedding for user's message
 	log.Printf("[GrowerAI] Generating embedding for query: %s", truncate(content, 50))
 	queryEmbedding, err := embedder.Embed(ctx, content)
@@ -82,6 +116,7 @@ func HandleGrowerAIMessage(c *gin.Context, cfg *config.Config, chatInst *chat.Ch
 		IncludeCollective: true,
 		Limit:             5,
 		MinScore:          0.5,
+		SourceKinds:       sourceKinds,
 	}
 
 	log.Printf("[GrowerAI] Searching memory (user=%s, min_score=0.5)...", userIDStr)
@@ -128,6 +163,10 @@ func HandleGrowerAIMessage(c *gin.Context, cfg *config.Config, chatInst *chat.Ch
         log.Printf("[GrowerAI]   No relevant memories found")
     }
 
+    if status := statusContext(ctx, cfg, engine, userID, content); status != "" {
+        contextBuilder.WriteString(status + "\n\n")
+    }
+
     contextBuilder.WriteString(fmt.Sprintf("User's current message: %s\n\n", content))
     contextBuilder.WriteString("Respond naturally, incorporating relevant context from memories if available.")
 
@@ -196,6 +235,7 @@ func HandleGrowerAIMessage(c *gin.Context, cfg *config.Config, chatInst *chat.Ch
 				Tier:            memory.TierRecent,
 				UserID:          &userIDStr,
 				IsCollective:    false,
+				SourceKind:      memory.SourceUserConversation,
 				CreatedAt:       time.Now(),
 				LastAccessedAt:  time.Now(),
 				AccessCount:     0,
@@ -514,7 +554,7 @@ func ListMessagesHandler() gin.HandlerFunc {
 }
 
 // Send a message in a chat (calls LLM, supports optional web search)
-func SendMessageHandler(cfg *config.Config) gin.HandlerFunc {
+func SendMessageHandler(cfg *config.Config, engine *dialogue.Engine) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, ok := getUserIDFromContext(c)
 		if !ok {
@@ -536,8 +576,9 @@ func SendMessageHandler(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		var req struct {
-			Content   string `json:"content"`
-			WebSearch bool   `json:"web_search"`
+			Content     string   `json:"content"`
+			WebSearch   bool     `json:"web_search"`
+			SourceKinds []string `json:"source_kinds"` // Memory sources for GrowerAI retrieval; omitted = config default
 		}
 		if err := c.ShouldBindJSON(&req); err != nil || req.Content == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "missing content"})
@@ -548,7 +589,12 @@ if chatInst.UseGrowerAI {
     // Route to GrowerAI memory system instead of standard LLM
     // This is where you'll integrate the memory evaluation loop
     // For now, we can add a placeholder or call a separate handler
-    HandleGrowerAIMessage(c, cfg, &chatInst, req.Content, userID)
+    sourceKinds, err := retrievalSourceKinds(cfg, req.SourceKinds)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    HandleGrowerAIMessage(c, cfg, &chatInst, req.Content, userID, sourceKinds, engine)
     return
 }
 
@@ -643,7 +689,7 @@ if chatInst.UseGrowerAI {
 			}
 
 			searchQuery := cleanForSearch(req.Content)
-			httpResp, err := http.Get(fmt.Sprintf("%s?q=%s&format=json", searxngURL, url.QueryEscape(searchQuery)))
+			httpResp, err := httpclient.New(0).Get(fmt.Sprintf("%s?q=%s&format=json", searxngURL, url.QueryEscape(searchQuery)))
 			if err == nil && httpResp.StatusCode == 200 {
 				defer httpResp.Body.Close()
 
@@ -835,7 +881,7 @@ var CallLLM = func(url string, payload map[string]interface{}) (LLMResponse, err
 	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 
-	client := http.Client{Timeout: 120 * time.Second}
+	client := httpclient.New(120 * time.Second)
 	res, err := client.Do(req)
 	if err != nil {
 		return LLMResponse{}, err
@@ -926,16 +972,16 @@ func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
 func truncate(s string, maxLen int) string {
-    if len(s) <= maxLen {
-        return s
-    }
-    return s[:maxLen] + "..."
+    return utils.Truncate(s, maxLen)
 }
 
 // --- Milestone 5: Goal Interaction Handlers ---
 
+// thresholdHistoryCycles is how many cycles' adaptive thresholds the goal status shows
+const thresholdHistoryCycles = 20
+
 // GoalStatusHandler handles "What are your current goals?"
-func GoalStatusHandler(engine *dialogue.Engine) gin.HandlerFunc {
+func GoalStatusHandler(cfg *config.Config, engine *dialogue.Engine) gin.HandlerFunc {
     return func(c *gin.Context) {
         orch := engine.GetOrchestrator()
         if orch == nil {
@@ -955,14 +1001,54 @@ func GoalStatusHandler(engine *dialogue.Engine) gin.HandlerFunc {
             return
         }
 
+        overdue, err := orch.GetOverdueGoals(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch overdue goals"})
+            return
+        }
+
+        focusAreas, err := engine.FocusAreas(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch focus areas"})
+            return
+        }
+
+        thresholdHistory, err := engine.AdaptiveThresholdHistory(c.Request.Context(), thresholdHistoryCycles)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch threshold history"})
+            return
+        }
+
+        recentProposals, allProposals, err := engine.GoalProposalAcceptance(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goal proposal stats"})
+            return
+        }
+
         c.JSON(http.StatusOK, gin.H{
             "active_goal": active,
             "queued_count": len(queued),
-            "queued_goals": queued,
+            "queued_goals": queued, // Selection order, by effective priority and effort
+            "overdue_count": len(overdue),
+            "overdue_goals": overdue,
+            "focus_areas": focusAreas, // From the last self-assessment, until they expire
+            "adaptive_thresholds": engine.AdaptiveThresholds(), // Used by the next cycle
+            "threshold_history": thresholdHistory, // Newest first
+            "goal_proposals": gin.H{"recent": recentProposals, "cumulative": allProposals},
+            "summary": engine.ExportStatusSummaryForUser(c.Request.Context(), summaryUserID(c), cfg.GrowerAI.Dialogue.StatusIntent.MaxTokens),
         })
     }
 }
 
+// summaryUserID returns the requesting user's ID for a status summary, or "" so only
+// goals derived from no one's personal memories are shown
+func summaryUserID(c *gin.Context) string {
+    if userID, ok := getUserIDFromContext(c); ok {
+        return fmt.Sprintf("%d", userID)
+    }
+    return ""
+}
+
 // GoalDetailHandler handles "Tell me more about [goal]"
 func GoalDetailHandler(engine *dialogue.Engine) gin.HandlerFunc {
     return func(c *gin.Context) {
@@ -1042,3 +1128,471 @@ func GoalPrioritizeHandler(engine *dialogue.Engine) gin.HandlerFunc {
         c.JSON(http.StatusOK, gin.H{"status": "prioritized", "id": goalID, "boost": req.Boost})
     }
 }
+
+// DialogueGoalGraphHandler returns the dialogue state's goal support graph for visualization
+func DialogueGoalGraphHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if db.DB == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database not initialized"})
+            return
+        }
+
+        state, err := dialogue.NewStateManager(db.DB).LoadState(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load dialogue state"})
+            return
+        }
+
+        c.JSON(http.StatusOK, dialogue.NewGoalGraph(state).View())
+    }
+}
+
+// DialogueHistorySearchHandler searches persisted thoughts and actions
+// GET /dialogue/history/search?q=...&type=thought|action&from=...&to=...&limit=...&offset=...
+// from/to accept RFC 3339 timestamps or YYYY-MM-DD dates (to is inclusive of that day).
+func DialogueHistorySearchHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if db.DB == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database not initialized"})
+            return
+        }
+
+        query := dialogue.HistoryQuery{
+            Text: c.Query("q"),
+            Type: c.Query("type"),
+        }
+        for _, p := range []struct {
+            name   string
+            target *int
+        }{{"limit", &query.Limit}, {"offset", &query.Offset}} {
+            if raw := c.Query(p.name); raw != "" {
+                value, err := strconv.Atoi(raw)
+                if err != nil {
+                    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + p.name + ": must be an integer"})
+                    return
+                }
+                *p.target = value
+            }
+        }
+        for _, p := range []struct {
+            name     string
+            target   *time.Time
+            endOfDay bool
+        }{{"from", &query.From, false}, {"to", &query.To, true}} {
+            if raw := c.Query(p.name); raw != "" {
+                value, err := parseHistoryTime(raw, p.endOfDay)
+                if err != nil {
+                    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + p.name + ": use RFC 3339 or YYYY-MM-DD"})
+                    return
+                }
+                *p.target = value
+            }
+        }
+        if err := query.Normalize(); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        page, err := dialogue.NewStateManager(db.DB).SearchHistory(c.Request.Context(), query)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search history"})
+            return
+        }
+
+        c.JSON(http.StatusOK, page)
+    }
+}
+
+// parseHistoryTime accepts RFC 3339 or a bare date; a bare "to" date covers the whole day
+func parseHistoryTime(raw string, endOfDay bool) (time.Time, error) {
+    if t, err := time.Parse(time.RFC3339, raw); err == nil {
+        return t, nil
+    }
+    t, err := time.Parse("2006-01-02", raw)
+    if err != nil {
+        return time.Time{}, err
+    }
+    if endOfDay {
+        t = t.Add(24*time.Hour - time.Nanosecond)
+    }
+    return t, nil
+}
+
+// DialogueGoalTimelineHandler returns a goal's actions, thoughts, assessments, plan and
+// status changes in chronological order
+// GET /dialogue/goals/:id/timeline?limit=...&offset=...
+func DialogueGoalTimelineHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if db.DB == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database not initialized"})
+            return
+        }
+
+        query := dialogue.TimelineQuery{GoalID: c.Param("id")}
+        for _, p := range []struct {
+            name   string
+            target *int
+        }{{"limit", &query.Limit}, {"offset", &query.Offset}} {
+            if raw := c.Query(p.name); raw != "" {
+                value, err := strconv.Atoi(raw)
+                if err != nil {
+                    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + p.name + ": must be an integer"})
+                    return
+                }
+                *p.target = value
+            }
+        }
+        // Results are served next to the timeline: .../dialogue/actions/:id/result
+        base := strings.TrimSuffix(c.FullPath(), "goals/:id/timeline")
+        query.ResultURL = func(actionID string) string {
+            return base + "actions/" + url.PathEscape(actionID) + "/result"
+        }
+
+        page, err := dialogue.NewStateManager(db.DB).GoalTimeline(c.Request.Context(), query)
+        if errors.Is(err, dialogue.ErrGoalNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+            return
+        }
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load goal timeline"})
+            return
+        }
+
+        c.JSON(http.StatusOK, page)
+    }
+}
+
+// DialogueActionResultHandler returns the full output of an action whose result was too
+// large to keep in goal state
+// GET /dialogue/actions/:id/result
+func DialogueActionResultHandler() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if db.DB == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database not initialized"})
+            return
+        }
+
+        actionID := c.Param("id")
+        content, err := dialogue.NewStateManager(db.DB).LoadActionResult(c.Request.Context(), actionID)
+        if errors.Is(err, dialogue.ErrActionResultNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Action result not found"})
+            return
+        }
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load action result"})
+            return
+        }
+
+        c.JSON(http.StatusOK, gin.H{"action_id": actionID, "content": content, "size": len(content)})
+    }
+}
+
+// DialogueEventsHandler streams dialogue engine events as Server-Sent Events. Each
+// client gets a bounded buffer; if it falls behind, events are dropped and the running
+// drop count is reported in an "events_dropped" event rather than stalling the engine.
+func DialogueEventsHandler(engine *dialogue.Engine) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if engine == nil || engine.Events() == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Dialogue engine not initialized"})
+            return
+        }
+
+        sub := engine.Events().Subscribe(dialogue.DefaultEventBuffer)
+        defer sub.Unsubscribe()
+
+        c.Header("Content-Type", "text/event-stream")
+        c.Header("Cache-Control", "no-cache")
+        c.Header("Connection", "keep-alive")
+        c.Header("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+
+        heartbeat := time.NewTicker(15 * time.Second)
+        defer heartbeat.Stop()
+
+        var reportedDrops int64
+        c.Stream(func(w io.Writer) bool {
+            select {
+            case <-c.Request.Context().Done():
+                return false
+            case event, ok := <-sub.Events():
+                if !ok {
+                    return false
+                }
+                if dropped := sub.Dropped(); dropped > reportedDrops {
+                    reportedDrops = dropped
+                    c.SSEvent("events_dropped", gin.H{"type": "events_dropped", "dropped": dropped})
+                }
+                c.SSEvent(string(event.Type), event)
+                return true
+            case <-heartbeat.C:
+                c.SSEvent("heartbeat", gin.H{"type": "heartbeat", "timestamp": time.Now()})
+                return true
+            }
+        })
+    }
+}
+
+// DialogueModelRoutingHandler reports the per-call-type model routing policy and call counts
+func DialogueModelRoutingHandler(engine *dialogue.Engine) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if engine == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Dialogue engine not initialized"})
+            return
+        }
+        c.JSON(http.StatusOK, engine.ModelRoutingStats())
+    }
+}
+
+// maxResearchNowTimeout caps the time a caller can ask a quick answer to take
+const maxResearchNowTimeout = 5 * time.Minute
+
+// ResearchNowHandler answers a question now within a token and time budget, returning
+// the answer with its sources, or partial findings if the budget ran out
+// POST /dialogue/research-now {"question": "...", "max_tokens": 4000, "timeout_seconds": 60, "max_pages": 2}
+func ResearchNowHandler(engine *dialogue.Engine) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if engine == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Dialogue engine not initialized"})
+            return
+        }
+
+        var req struct {
+            Question       string `json:"question"`
+            MaxTokens      int    `json:"max_tokens"`
+            TimeoutSeconds int    `json:"timeout_seconds"`
+            MaxPages       int    `json:"max_pages"`
+        }
+        if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Question) == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "question is required"})
+            return
+        }
+        if req.MaxTokens < 0 || req.TimeoutSeconds < 0 || req.MaxPages < 0 || req.MaxPages > dialogue.MaxResearchNowPages {
+            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("budget fields must be non-negative and max_pages at most %d", dialogue.MaxResearchNowPages)})
+            return
+        }
+        timeout := time.Duration(req.TimeoutSeconds) * time.Second
+        if timeout > maxResearchNowTimeout {
+            timeout = maxResearchNowTimeout
+        }
+
+        answer, err := engine.ResearchNow(c.Request.Context(), req.Question, dialogue.ResearchBudget{
+            MaxTokens: req.MaxTokens,
+            Timeout:   timeout,
+            MaxPages:  req.MaxPages,
+        })
+        if err != nil {
+            c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Research failed: %v", err)})
+            return
+        }
+        c.JSON(http.StatusOK, answer)
+    }
+}
+
+// DialogueMetricsHandler returns recent cycle metrics and how often each stop reason
+// ended them, to show which cycle budget is binding, the acceptance rate of goal
+// proposals over them and over all cycles, plus cycles skipped for
+// unavailable dependencies, the shared HTTP transport's counters and the hosted token
+// budget when one is set
+// GET /dialogue/metrics?cycles=50
+func DialogueMetricsHandler(engine *dialogue.Engine, llmManager interface{}) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if db.DB == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database not initialized"})
+            return
+        }
+
+        n := 50
+        if raw := c.Query("cycles"); raw != "" {
+            parsed, err := strconv.Atoi(raw)
+            if err != nil || parsed < 1 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "cycles must be a positive integer"})
+                return
+            }
+            n = parsed
+        }
+        if n > 500 {
+            n = 500
+        }
+
+        sm := dialogue.NewStateManager(db.DB)
+        cycles, err := sm.RecentMetrics(c.Request.Context(), n)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cycle metrics"})
+            return
+        }
+        proposalTotals, err := sm.GoalProposalTotals(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load goal proposal stats"})
+            return
+        }
+
+        response := gin.H{
+            "window":       len(cycles),
+            "stop_reasons": dialogue.StopReasonHistogram(cycles),
+            "goal_proposals": gin.H{
+                "window":     dialogue.SummarizeGoalProposals(cycles),
+                "cumulative": proposalTotals,
+            },
+            "cycles":       cycles,
+        }
+        if engine != nil {
+            response["skipped_cycles"] = engine.SkippedCycles()
+        }
+        response["http_client"] = httpclient.Default().Stats()
+        if mgr, ok := llmManager.(*llm.Manager); ok && mgr != nil && mgr.Budget() != nil {
+            if budget, err := mgr.Budget().Status(c.Request.Context()); err == nil {
+                response["budget"] = budget
+            }
+        }
+        c.JSON(http.StatusOK, response)
+    }
+}
+
+// MemoryProvenanceHandler traces a memory back to the cycle, goal and actions that
+// produced it. Admins can explain any memory; other users their own and collective ones.
+func MemoryProvenanceHandler(engine *dialogue.Engine) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if engine == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Dialogue engine not initialized"})
+            return
+        }
+
+        viewerID := ""
+        if role, _ := c.Get("role"); role != string(user.RoleAdmin) {
+            userID, ok := getUserIDFromContext(c)
+            if !ok {
+                c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+                return
+            }
+            viewerID = fmt.Sprintf("%d", userID)
+        }
+
+        prov, err := engine.ExplainMemory(c.Request.Context(), c.Param("id"), viewerID)
+        if errors.Is(err, memory.ErrNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Memory not found"})
+            return
+        }
+        if errors.Is(err, memory.ErrStorageUnavailable) {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Memory storage unavailable"})
+            return
+        }
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trace memory provenance"})
+            return
+        }
+
+        c.JSON(http.StatusOK, prov)
+    }
+}
+
+// GoalDeadlineHandler handles "Finish [goal] by [date]". An empty deadline clears it.
+func GoalDeadlineHandler(engine *dialogue.Engine) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        goalID := c.Param("id")
+        if goalID == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Goal ID required"})
+            return
+        }
+
+        var req struct {
+            Deadline string `json:"deadline"`
+        }
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+            return
+        }
+        deadline, err := goal.ParseDeadline(req.Deadline)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        orch := engine.GetOrchestrator()
+        if orch == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Goal system not initialized"})
+            return
+        }
+
+        if err := orch.SetGoalDeadline(c.Request.Context(), goalID, deadline); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to set deadline: %v", err)})
+            return
+        }
+
+        c.JSON(http.StatusOK, gin.H{"status": "deadline_set", "id": goalID, "deadline": deadline})
+    }
+}
+
+// GoalOverdueHandler answers an overdue goal's report: continue (optionally with a new
+// deadline) or stop
+func GoalOverdueHandler(engine *dialogue.Engine) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        goalID := c.Param("id")
+        if goalID == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Goal ID required"})
+            return
+        }
+
+        var req struct {
+            Continue *bool  `json:"continue"`
+            Deadline string `json:"deadline"`
+        }
+        if err := c.ShouldBindJSON(&req); err != nil || req.Continue == nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "\"continue\" (true or false) is required"})
+            return
+        }
+        deadline, err := goal.ParseDeadline(req.Deadline)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        orch := engine.GetOrchestrator()
+        if orch == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Goal system not initialized"})
+            return
+        }
+
+        if err := orch.ResolveOverdueGoal(c.Request.Context(), goalID, *req.Continue, deadline); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to resolve overdue goal: %v", err)})
+            return
+        }
+
+        status := "archived"
+        if *req.Continue {
+            status = "queued"
+        }
+        c.JSON(http.StatusOK, gin.H{"status": status, "id": goalID, "deadline": deadline})
+    }
+}
+
+// GoalAnswerHandler takes the user's answer to the question a dialogue goal is paused
+// on. The next cycle adds it to the goal's notes and resumes the goal.
+// POST /dialogue/goals/:id/answer {"answer": "..."}
+func GoalAnswerHandler(engine *dialogue.Engine) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if engine == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Dialogue engine not initialized"})
+            return
+        }
+
+        var req struct {
+            Answer string `json:"answer"`
+        }
+        if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Answer) == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "answer is required"})
+            return
+        }
+
+        goalID := c.Param("id")
+        err := engine.AnswerUserQuestion(c.Request.Context(), goalID, req.Answer)
+        switch {
+        case errors.Is(err, dialogue.ErrGoalNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+        case errors.Is(err, dialogue.ErrNoPendingQuestion):
+            c.JSON(http.StatusConflict, gin.H{"error": "Goal is not waiting for an answer"})
+        case err != nil:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to record answer: %v", err)})
+        default:
+            c.JSON(http.StatusAccepted, gin.H{"status": "answered", "id": goalID, "resumes": "next cycle"})
+        }
+    }
+}
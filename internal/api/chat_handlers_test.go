@@ -218,7 +218,7 @@ func TestSendMessageHandler_Unauthorized(t *testing.T) {
 	}
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.POST("/chats/:id/send", SendMessageHandler(cfg))
+	r.POST("/chats/:id/send", SendMessageHandler(cfg, nil))
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", "/chats/1/send", bytes.NewReader([]byte(`{"content":"hello"}`)))
 	req.Header.Set("Content-Type", "application/json")
@@ -241,7 +241,7 @@ func TestSendMessageHandler_ChatNotFound(t *testing.T) {
 		c.Set("userId", u.ID)
 		c.Next()
 	})
-	r.POST("/chats/:id/send", SendMessageHandler(cfg))
+	r.POST("/chats/:id/send", SendMessageHandler(cfg, nil))
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", "/chats/999/send", bytes.NewReader([]byte(`{"content":"hello"}`)))
 	req.Header.Set("Content-Type", "application/json")
@@ -267,7 +267,7 @@ func TestSendMessageHandler_MissingContent(t *testing.T) {
 		c.Set("userId", u.ID)
 		c.Next()
 	})
-	r.POST("/chats/:id/send", SendMessageHandler(cfg))
+	r.POST("/chats/:id/send", SendMessageHandler(cfg, nil))
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", "/chats/"+fmt.Sprintf("%d", c.ID)+"/send", bytes.NewReader([]byte(`{"content":""}`)))
 	req.Header.Set("Content-Type", "application/json")
@@ -300,7 +300,7 @@ func TestSendMessageHandler_LLMFailure(t *testing.T) {
 		c.Set("userId", u.ID)
 		c.Next()
 	})
-	r.POST("/chats/:id/send", SendMessageHandler(cfg))
+	r.POST("/chats/:id/send", SendMessageHandler(cfg, nil))
 	payload := map[string]interface{}{"content": "hello"}
 	b, _ := json.Marshal(payload)
 	w := httptest.NewRecorder()
@@ -3,16 +3,35 @@ package api
 import (
 	"net/http"
 	"go-llama/internal/config"
+	"go-llama/internal/health"
 	"github.com/gin-gonic/gin"
 )
 
-// GET /health
+// GET /health, GET /healthz
+// Liveness only: answers while the process is up, whatever its dependencies' state
 func healthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
 	})
 }
 
+// GET /readyz
+// Checks every downstream dependency and answers 503 while a required one is down
+func readyHandler(checker *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.JSON(http.StatusOK, health.Snapshot{Ready: true, Dependencies: []health.DependencyStatus{}})
+			return
+		}
+		snap := checker.Check(c.Request.Context())
+		status := http.StatusOK
+		if !snap.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, snap)
+	}
+}
+
 // GET /config
 func configHandler(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
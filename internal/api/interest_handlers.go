@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-llama/internal/chat"
+	"go-llama/internal/config"
+	"go-llama/internal/db"
+	"go-llama/internal/dialogue"
+	"go-llama/internal/memory"
+)
+
+// Message ratings
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// suppressionCooldown is how long a turned-down topic stays out of goals
+func suppressionCooldown(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.GrowerAI.Dialogue.Interests.SuppressionCooldownDays * float64(24*time.Hour))
+}
+
+// recordTopicSuppression keeps a topic out of interest-driven goals for the cooldown and
+// stores the request as a personal memory tagged dialogue.InterestSuppressionTag. The
+// memory is skipped when storage is nil.
+func recordTopicSuppression(ctx context.Context, cfg *config.Config, embedder *memory.Embedder, storage *memory.Storage, userID uint, topic, reason string) (*dialogue.TopicSuppression, error) {
+	suppression, err := dialogue.SuppressTopic(db.DB.WithContext(ctx), userID, topic, reason, suppressionCooldown(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if storage == nil || embedder == nil {
+		return suppression, nil
+	}
+
+	content := fmt.Sprintf("User asked me to stop researching %s", suppression.Topic)
+	embedding, err := embedder.Embed(ctx, content)
+	if err != nil {
+		log.Printf("[Interests] WARNING: Failed to embed suppression of %q: %v", suppression.Topic, err)
+		return suppression, nil
+	}
+	userIDStr := fmt.Sprintf("%d", userID)
+	mem := &memory.Memory{
+		Content:         content,
+		Tier:            memory.TierRecent,
+		UserID:          &userIDStr,
+		IsCollective:    false,
+		SourceKind:      memory.SourceUserConversation,
+		CreatedAt:       time.Now(),
+		LastAccessedAt:  time.Now(),
+		ImportanceScore: 0.8,
+		Embedding:       embedding,
+		ConceptTags:     []string{dialogue.InterestSuppressionTag, suppression.Topic},
+		Metadata: map[string]interface{}{
+			"suppressed_topic":   suppression.Topic,
+			"suppression_reason": reason,
+			"suppressed_until":   suppression.ExpiresAt.Format(time.RFC3339),
+		},
+	}
+	if err := storage.Store(ctx, mem); err != nil {
+		log.Printf("[Interests] WARNING: Failed to store suppression of %q: %v", suppression.Topic, err)
+	}
+	return suppression, nil
+}
+
+// detectTopicSuppression records a suppression when a chat message asks to stop
+// researching a topic
+func detectTopicSuppression(ctx context.Context, cfg *config.Config, embedder *memory.Embedder, storage *memory.Storage, userID uint, content string) {
+	topic, ok := dialogue.DetectTopicSuppression(content)
+	if !ok {
+		return
+	}
+	if _, err := recordTopicSuppression(ctx, cfg, embedder, storage, userID, topic, dialogue.SuppressionChatRequest); err != nil {
+		log.Printf("[Interests] WARNING: %v", err)
+	}
+}
+
+// detectCorrection revises the memories behind the previous bot reply when the user's
+// message says it was wrong
+func detectCorrection(ctx context.Context, storage *memory.Storage, chatID uint, content string) {
+	if !dialogue.DetectCorrection(content) {
+		return
+	}
+	var lastReply chat.Message
+	if err := db.DB.Where("chat_id = ? AND sender = ?", chatID, "bot").Order("created_at DESC").First(&lastReply).Error; err != nil {
+		return
+	}
+	applyReplyFeedback(ctx, storage, &lastReply, memory.SignalCorrection)
+}
+
+// feedbackMemoryIDs returns the IDs of the injected memories that feedback on a reply
+// can revise, leaving out the user's own conversation history
+func feedbackMemoryIDs(resultSets ...[]memory.RetrievalResult) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, results := range resultSets {
+		for i := range results {
+			mem := &results[i].Memory
+			if !memory.FeedbackEligible(mem) || seen[mem.ID] {
+				continue
+			}
+			seen[mem.ID] = true
+			ids = append(ids, mem.ID)
+		}
+	}
+	return ids
+}
+
+// applyReplyFeedback revises the outcome and trust of the memories a bot reply used
+func applyReplyFeedback(ctx context.Context, storage *memory.Storage, msg *chat.Message, signal memory.OutcomeSignal) {
+	ids := msg.UsedMemoryIDs()
+	if storage == nil || len(ids) == 0 {
+		return
+	}
+	updated, err := storage.ApplyOutcomeFeedback(ctx, ids, signal)
+	if err != nil {
+		log.Printf("[Interests] WARNING: Failed to apply %s to memories of message %d: %v", signal, msg.ID, err)
+		return
+	}
+	log.Printf("[Interests] Applied %s to %d/%d memories used by message %d", signal, updated, len(ids), msg.ID)
+}
+
+// RateMessageHandler records a thumbs up or down on a bot message. The rating revises
+// the outcome of the memories the reply was generated from, and rating a message down
+// suppresses the topic it mentions researching, or the topic given in the request.
+// POST /chats/:id/messages/:messageId/rating
+func RateMessageHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := getUserIDFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		chatID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat id"})
+			return
+		}
+		messageID, err := strconv.ParseUint(c.Param("messageId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message id"})
+			return
+		}
+
+		var req struct {
+			Rating string `json:"rating"` // "up" or "down"
+			Topic  string `json:"topic"`  // Optional; the topic to suppress on a thumbs-down
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || (req.Rating != RatingUp && req.Rating != RatingDown) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rating must be \"up\" or \"down\""})
+			return
+		}
+
+		var chatInst chat.Chat
+		if err := db.DB.Where("id = ? AND user_id = ?", chatID, userID).First(&chatInst).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+			return
+		}
+		var msg chat.Message
+		if err := db.DB.Where("id = ? AND chat_id = ?", messageID, chatInst.ID).First(&msg).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
+			return
+		}
+
+		previous := msg.Rating
+		msg.Rating = req.Rating
+		if err := db.DB.Save(&msg).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save rating"})
+			return
+		}
+
+		topic := ""
+		if req.Rating == RatingDown {
+			topic = req.Topic
+			if topic == "" {
+				topic, _ = dialogue.DetectResearchMention(msg.Content)
+			}
+		}
+		// Re-sending the same rating does not revise the memories again
+		revise := previous != req.Rating && len(msg.UsedMemoryIDs()) > 0
+
+		response := gin.H{"id": msg.ID, "rating": msg.Rating}
+		if topic != "" || revise {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+			defer cancel()
+
+			storage, err := memory.NewStorage(cfg.GrowerAI.Qdrant.URL, cfg.GrowerAI.Qdrant.Collection, cfg.GrowerAI.Qdrant.APIKey)
+			if err != nil {
+				log.Printf("[Interests] WARNING: Memory storage unavailable, recording rating only: %v", err)
+				storage = nil
+			}
+			if revise {
+				signal := memory.SignalThumbsUp
+				if req.Rating == RatingDown {
+					signal = memory.SignalThumbsDown
+				}
+				applyReplyFeedback(ctx, storage, &msg, signal)
+			}
+			if topic != "" {
+				embedder := memory.NewEmbedder(config.GetEmbeddingsURL(cfg.GrowerAI.EmbeddingModel.URL))
+				suppression, err := recordTopicSuppression(ctx, cfg, embedder, storage, userID, topic, dialogue.SuppressionThumbsDown)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to suppress topic"})
+					return
+				}
+				response["suppression"] = suppression
+			}
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
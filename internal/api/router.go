@@ -6,10 +6,15 @@ import (
     "go-llama/internal/auth"
     "go-llama/internal/db"
     "go-llama/internal/dialogue"
+    "go-llama/internal/health"
+    "go-llama/internal/memory"
+    redisdb "go-llama/internal/redis"
+    "go-llama/internal/tools"
     "go-llama/internal/user"
     "github.com/redis/go-redis/v9"
     "net/http"
     "path"
+    "strings"
 )
 
 func usersExist() bool {
@@ -21,7 +26,7 @@ func usersExist() bool {
 	return count > 0
 }
 
-func SetupRouter(cfg *config.Config, rdb *redis.Client, llmManager interface{}, criticalLLMClient interface{}, engine *dialogue.Engine) *gin.Engine {
+func SetupRouter(cfg *config.Config, rdb *redis.Client, llmManager interface{}, criticalLLMClient interface{}, engine *dialogue.Engine, decayWorker *memory.DecayWorker, retagWorker *memory.RetagWorker, domainPolicy *tools.DomainPolicy, configWatcher *config.Watcher, healthChecker *health.Checker) *gin.Engine {
 	r := gin.Default()
 	subpath := cfg.Server.Subpath // e.g. "/go-llama" or any custom path, always starts with '/'
 
@@ -59,10 +64,32 @@ func SetupRouter(cfg *config.Config, rdb *redis.Client, llmManager interface{},
 		c.Redirect(http.StatusMovedPermanently, subpath)
 	})
 
+	// Admin and mutating dialogue routes: admin token or session, rate limited, audited
+	var counter auth.RateCounter
+	if rdb != nil {
+		counter = redisdb.NewRateCounter(rdb)
+	}
+	guard := auth.NewGuard(cfg, counter, db.DB)
+
+	// Unknown admin paths answer like known ones to unauthenticated callers, so the
+	// response does not reveal which admin endpoints exist
+	adminPrefix := subpath + "/admin"
+	r.NoRoute(func(c *gin.Context) {
+		if c.Request.URL.Path == adminPrefix || strings.HasPrefix(c.Request.URL.Path, adminPrefix+"/") {
+			guard.Protect(true)(c)
+			if c.IsAborted() {
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "Not found"}})
+	})
+
 	// API routes
 	group := r.Group(subpath)
 	{
 		group.GET("/health", healthHandler)
+		group.GET("/healthz", healthHandler)
+		group.GET("/readyz", readyHandler(healthChecker))
 		group.GET("/config", configHandler(cfg))
 
 		// Setup: only if no users
@@ -95,10 +122,11 @@ func SetupRouter(cfg *config.Config, rdb *redis.Client, llmManager interface{},
 		group.GET("/chats", auth.AuthMiddleware(cfg, rdb, false), ListChatsHandler())
 		group.GET("/chats/:id", auth.AuthMiddleware(cfg, rdb, false), GetChatHandler())
 		group.GET("/chats/:id/messages", auth.AuthMiddleware(cfg, rdb, false), ListMessagesHandler())
-		group.POST("/chats/:id/messages", auth.AuthMiddleware(cfg, rdb, false), SendMessageHandler(cfg))
+		group.POST("/chats/:id/messages", auth.AuthMiddleware(cfg, rdb, false), SendMessageHandler(cfg, engine))
+		group.POST("/chats/:id/messages/:messageId/rating", auth.AuthMiddleware(cfg, rdb, false), RateMessageHandler(cfg))
 
         // --- Streaming WebSocket endpoint ---
-        group.GET("/ws/chat", WSChatHandler(cfg, llmManager, criticalLLMClient))
+        group.GET("/ws/chat", WSChatHandler(cfg, llmManager, criticalLLMClient, engine))
 
 		// --- SearxNG-augmented LLM endpoint ---
 		group.POST("/search", auth.AuthMiddleware(cfg, rdb, false), SearxNGSearchHandler(cfg))
@@ -113,10 +141,44 @@ func SetupRouter(cfg *config.Config, rdb *redis.Client, llmManager interface{},
         // --- Milestone 5: Goal API ---
         goalGroup := r.Group(subpath + "/api/goals")
         {
-            goalGroup.GET("", auth.AuthMiddleware(cfg, rdb, false), GoalStatusHandler(engine))
+            goalGroup.GET("", auth.AuthMiddleware(cfg, rdb, false), GoalStatusHandler(cfg, engine))
             goalGroup.GET("/:id", auth.AuthMiddleware(cfg, rdb, false), GoalDetailHandler(engine))
-            goalGroup.POST("/:id/stop", auth.AuthMiddleware(cfg, rdb, false), GoalStopHandler(engine))
-            goalGroup.POST("/:id/prioritize", auth.AuthMiddleware(cfg, rdb, false), GoalPrioritizeHandler(engine))
+            goalGroup.POST("/:id/stop", guard.Protect(false), GoalStopHandler(engine))
+            goalGroup.POST("/:id/prioritize", guard.Protect(false), GoalPrioritizeHandler(engine))
+            goalGroup.POST("/:id/deadline", guard.Protect(false), GoalDeadlineHandler(engine))
+            goalGroup.POST("/:id/overdue", guard.Protect(false), GoalOverdueHandler(engine))
+        }
+
+        // --- Dialogue state ---
+        group.GET("/api/dialogue/state/goal-graph", auth.AuthMiddleware(cfg, rdb, false), DialogueGoalGraphHandler())
+        group.GET("/dialogue/events", auth.AuthMiddleware(cfg, rdb, false), DialogueEventsHandler(engine))
+        group.GET("/dialogue/history/search", auth.AuthMiddleware(cfg, rdb, false), DialogueHistorySearchHandler())
+        group.GET("/dialogue/model-routing", auth.AuthMiddleware(cfg, rdb, false), DialogueModelRoutingHandler(engine))
+        group.GET("/dialogue/metrics", auth.AuthMiddleware(cfg, rdb, false), DialogueMetricsHandler(engine, llmManager))
+        group.POST("/dialogue/research-now", guard.Protect(false), ResearchNowHandler(engine))
+        group.POST("/dialogue/goals/:id/answer", guard.Protect(false), GoalAnswerHandler(engine))
+        group.GET("/dialogue/goals/:id/timeline", auth.AuthMiddleware(cfg, rdb, false), DialogueGoalTimelineHandler())
+        group.GET("/dialogue/actions/:id/result", auth.AuthMiddleware(cfg, rdb, false), DialogueActionResultHandler())
+        group.GET("/memories/:id/provenance", auth.AuthMiddleware(cfg, rdb, false), MemoryProvenanceHandler(engine))
+
+        // --- Admin: GrowerAI maintenance ---
+        adminGroup := group.Group("/admin", guard.Protect(true))
+        {
+            adminGroup.POST("/compression/run", CompressionRunHandler(decayWorker))
+            adminGroup.GET("/compression/last-report", CompressionLastReportHandler(decayWorker))
+            adminGroup.GET("/compression/preview", CompressionPreviewHandler(decayWorker))
+            adminGroup.GET("/compression/eviction-preview", EvictionPreviewHandler(decayWorker))
+            adminGroup.GET("/memory/stats", MemoryStatsHandler(decayWorker))
+            adminGroup.POST("/retag/run", RetagRunHandler(retagWorker))
+            adminGroup.GET("/retag/status", RetagStatusHandler(retagWorker))
+            adminGroup.GET("/principles/history", PrincipleHistoryHandler())
+            adminGroup.POST("/principles/:slot/rollback", PrincipleRollbackHandler())
+            adminGroup.GET("/domain-policy", DomainPolicyHandler(domainPolicy))
+            adminGroup.POST("/domain-policy/reload", DomainPolicyReloadHandler(domainPolicy, "config.json"))
+            adminGroup.GET("/config/status", ConfigStatusHandler(configWatcher))
+            adminGroup.POST("/config/reload", ConfigReloadHandler(configWatcher))
+            adminGroup.GET("/llm-queue", LLMQueueMetricsHandler(llmManager))
+            adminGroup.GET("/llm-budget", LLMBudgetHandler(llmManager))
         }
     }
     return r
@@ -10,33 +10,36 @@ import (
 
 func TestSetupRouter_BasicRoutes(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	t.Chdir("../..") // The router loads the frontend relative to the repo root
 
 	cfg := &config.Config{}
-	r := SetupRouter(cfg, nil)
+	cfg.Server.Subpath = "/go-llama" // An empty subpath would register "/" twice
+	r := SetupRouter(cfg, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	// Health route should exist and return 200
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest("GET", "/health", nil)
+	req := httptest.NewRequest("GET", "/go-llama/health", nil)
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusOK {
-		t.Errorf("GET /health should return 200, got %d", w.Code)
+		t.Errorf("GET /go-llama/health should return 200, got %d", w.Code)
 	}
 
 	// Config route should exist and return 200
 	w2 := httptest.NewRecorder()
-	req2 := httptest.NewRequest("GET", "/config", nil)
+	req2 := httptest.NewRequest("GET", "/go-llama/config", nil)
 	r.ServeHTTP(w2, req2)
 	if w2.Code != http.StatusOK {
-		t.Errorf("GET /config should return 200, got %d", w2.Code)
+		t.Errorf("GET /go-llama/config should return 200, got %d", w2.Code)
 	}
 }
 
 func TestSetupRouter_Subpath(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	t.Chdir("../..") // The router loads the frontend relative to the repo root
 
 	cfg := &config.Config{}
 	cfg.Server.Subpath = "/api"
-	r := SetupRouter(cfg, nil)
+	r := SetupRouter(cfg, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	// Should correctly prefix routes with subpath
 	w := httptest.NewRecorder()
@@ -12,6 +12,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go-llama/internal/config"
+	"go-llama/internal/httpclient"
 )
 
 type SearxNGPromptRequest struct {
@@ -50,7 +51,7 @@ func SearxNGSearchHandler(cfg *config.Config) gin.HandlerFunc {
 		q.Set("format", "json")
 		u.RawQuery = q.Encode()
 
-		resp, err := http.Get(u.String())
+		resp, err := httpclient.New(0).Get(u.String())
 		if err != nil {
 			log.Println("SearxNG error:", err)
 			c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "SearxNG unavailable"}})
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go-llama/internal/tools"
+)
+
+var (
+	summarizerMu      sync.RWMutex
+	defaultSummarizer *tools.Summarizer
+)
+
+// SetDefaultSummarizer installs the summarizer used by EnrichAndSummarize
+func SetDefaultSummarizer(s *tools.Summarizer) {
+	summarizerMu.Lock()
+	defer summarizerMu.Unlock()
+	defaultSummarizer = s
+}
+
+// EnrichAndSummarize fetches url and returns a condensed summary, or fallback when the
+// page cannot be summarized. New code should call tools.Summarizer directly.
+func EnrichAndSummarize(url, fallback string) string {
+	summarizerMu.RLock()
+	s := defaultSummarizer
+	summarizerMu.RUnlock()
+	if s == nil {
+		log.Printf("[Summarizer] No summarizer configured, using fallback for %s", url)
+		return fallback
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	summary, err := s.Summarize(ctx, url, tools.SummarizeOptions{})
+	if err != nil {
+		log.Printf("[Summarizer] Failed to summarize %s, using fallback: %v", url, err)
+		return fallback
+	}
+	return summary.Text
+}
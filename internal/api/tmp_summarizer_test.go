@@ -13,7 +13,7 @@ func TestSummarizer(t *testing.T) {
 	if url == "" {
 		url = "https://simple.wikipedia.org/wiki/List_of_prime_ministers_of_the_United_Kingdom"
 	}
-	s := enrichAndSummarize(url, "(fallback snippet)")
+	s := EnrichAndSummarize(url, "(fallback snippet)")
 	fmt.Println("\n=== Condensed Summary ===")
 	fmt.Println(s)
 }
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"log"
 	"net/http"
 
 	"go-llama/internal/user"
 	"go-llama/internal/db"
+	"go-llama/internal/dialogue"
 
 	"github.com/gin-gonic/gin"
 )
@@ -83,17 +85,26 @@ func GetMeHandler() gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "User not found"}})
 			return
 		}
+		// Topics the user turned down, kept out of research goals until they expire
+		suppressions, err := dialogue.ActiveTopicSuppressions(db.DB, u.ID)
+		if err != nil {
+			log.Printf("[Users] WARNING: %v", err)
+			suppressions = []dialogue.TopicSuppression{}
+		}
 		c.JSON(http.StatusOK, gin.H{
-			"id":        u.ID,
-			"username":  u.Username,
-			"role":      u.Role,
-			"createdAt": u.CreatedAt,
+			"id":                u.ID,
+			"username":          u.Username,
+			"role":              u.Role,
+			"createdAt":         u.CreatedAt,
+			"topicSuppressions": suppressions,
 		})
 	}
 }
 
 type UpdateMeRequest struct {
-	Password string `json:"password,omitempty"`
+	Password               string `json:"password,omitempty"`
+	AllowDialogueAccess    *bool  `json:"allowDialogueAccess,omitempty"`
+	AllowCollectiveSharing *bool  `json:"allowCollectiveSharing,omitempty"`
 }
 
 // PUT /users/me
@@ -118,6 +129,12 @@ func UpdateMeHandler() gin.HandlerFunc {
 			}
 			u.PasswordHash = pwHash
 		}
+		if req.AllowDialogueAccess != nil {
+			u.AllowDialogueAccess = *req.AllowDialogueAccess
+		}
+		if req.AllowCollectiveSharing != nil {
+			u.AllowCollectiveSharing = *req.AllowCollectiveSharing
+		}
 		if err := db.DB.Save(&u).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "Update error"}})
 			return
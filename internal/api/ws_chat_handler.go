@@ -16,13 +16,15 @@ import (
 	"go-llama/internal/chat"
 	"go-llama/internal/config"
 	"go-llama/internal/db"
+	"go-llama/internal/dialogue"
 )
 
 // WebSocket message format
 type WSChatPrompt struct {
-	ChatID    int    `json:"chatId"`
-	Prompt    string `json:"prompt"`
-	WebSearch bool   `json:"web_search"`
+	ChatID      int      `json:"chatId"`
+	Prompt      string   `json:"prompt"`
+	WebSearch   bool     `json:"web_search"`
+	SourceKinds []string `json:"source_kinds"` // Memory sources for GrowerAI retrieval; omitted = config default
 }
 
 // WebSocket streaming token format
@@ -56,7 +58,7 @@ func (s *safeWSConn) Close() error {
 }
 
 // WSChatHandler is the main WebSocket entry point - routes to standard LLM or GrowerAI
-func WSChatHandler(cfg *config.Config, llmManager interface{}, criticalLLMClient interface{}) gin.HandlerFunc {
+func WSChatHandler(cfg *config.Config, llmManager interface{}, criticalLLMClient interface{}, engine *dialogue.Engine) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Authenticate
 		token := c.GetHeader("Authorization")
@@ -112,7 +114,12 @@ func WSChatHandler(cfg *config.Config, llmManager interface{}, criticalLLMClient
 
         // Route to appropriate handler
         if chatInst.UseGrowerAI {
-            handleGrowerAIWebSocket(conn, cfg, &chatInst, req.Prompt, userID, llmManager)
+            sourceKinds, err := retrievalSourceKinds(cfg, req.SourceKinds)
+            if err != nil {
+                conn.WriteJSON(map[string]string{"error": err.Error()})
+                return
+            }
+            handleGrowerAIWebSocket(conn, cfg, &chatInst, req.Prompt, userID, llmManager, sourceKinds, engine)
         } else {
             handleStandardLLMWebSocket(conn, cfg, &chatInst, req, userID, criticalLLMClient)
         }
@@ -37,7 +37,7 @@ func TestWSChatHandler_MissingPrompt(t *testing.T) {
 		cxt.Set("userId", u.ID)
 		cxt.Next()
 	})
-	r.GET("/ws/chat", WSChatHandler(cfg))
+	r.GET("/ws/chat", WSChatHandler(cfg, nil, nil, nil))
 
 	s := httptest.NewServer(r)
 	defer s.Close()
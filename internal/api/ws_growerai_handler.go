@@ -18,15 +18,17 @@ import (
 	"go-llama/internal/config"
 	"go-llama/internal/db"
 	"go-llama/internal/dialogue"
+	"go-llama/internal/httpclient"
 	"go-llama/internal/memory"
 	"go-llama/internal/llm"
+	"go-llama/internal/user"
 
 	"github.com/gorilla/websocket"
 	"gorm.io/gorm"
 )
 
 // handleGrowerAIWebSocket processes GrowerAI messages via WebSocket with streaming
-func handleGrowerAIWebSocket(conn *safeWSConn, cfg *config.Config, chatInst *chat.Chat, content string, userID uint, llmManager interface{}) {
+func handleGrowerAIWebSocket(conn *safeWSConn, cfg *config.Config, chatInst *chat.Chat, content string, userID uint, llmManager interface{}, sourceKinds []string, engine *dialogue.Engine) {
 	// Check if GrowerAI is globally enabled
 	if !cfg.GrowerAI.Enabled {
 		log.Printf("[GrowerAI-WS] GrowerAI disabled in config")
@@ -60,6 +62,11 @@ func handleGrowerAIWebSocket(conn *safeWSConn, cfg *config.Config, chatInst *cha
 		return
 	}
 
+	// "Stop researching X" keeps X out of research goals for a while
+	detectTopicSuppression(ctx, cfg, embedder, storage, userID, content)
+	// "That's wrong" marks the memories behind the previous reply as bad outcomes
+	detectCorrection(ctx, storage, chatInst.ID, content)
+
 	// Initialize linker for co-occurrence tracking
 	linker := memory.NewLinker(
 		storage,
@@ -96,6 +103,7 @@ func handleGrowerAIWebSocket(conn *safeWSConn, cfg *config.Config, chatInst *cha
         Limit:             cfg.GrowerAI.Retrieval.MaxMemories,
         MinScore:          cfg.GrowerAI.Retrieval.MinScore,
         GoodBehaviorBias:  cfg.GrowerAI.Personality.GoodBehaviorBias,
+        SourceKinds:       sourceKinds,
     }
 
     log.Printf("[GrowerAI-WS] Searching PERSONAL memory (user=%s, limit=%d, min_score=%.2f)...", 
@@ -116,6 +124,7 @@ func handleGrowerAIWebSocket(conn *safeWSConn, cfg *config.Config, chatInst *cha
         Limit:             5, // Limit to 5 as requested
         MinScore:          0.25, // Lower threshold to ensure we catch broad learnings
         GoodBehaviorBias:  0.0, // No bias needed for factual data
+        SourceKinds:       sourceKinds,
     }
 
     log.Printf("[GrowerAI-WS] Searching COLLECTIVE memory (limit=5, min_score=0.25)...")
@@ -329,11 +338,14 @@ if len(allLinkedIDs) > 0 {
 		}
 	}
 
-	// Update access metadata for retrieved memories
-	for _, result := range allResults {
-		if err := storage.UpdateAccessMetadata(ctx, result.Memory.ID); err != nil {
-			log.Printf("[GrowerAI-WS] WARNING: Failed to update access metadata for memory %s: %v",
-				result.Memory.ID, err)
+	// Update access metadata for retrieved memories. With the background tracker running,
+	// Search has already queued the direct hits, so only linked memories are added here.
+	if !memory.RecordAccess(linkedMemories) {
+		for _, result := range allResults {
+			if err := storage.UpdateAccessMetadata(ctx, result.Memory.ID); err != nil {
+				log.Printf("[GrowerAI-WS] WARNING: Failed to update access metadata for memory %s: %v",
+					result.Memory.ID, err)
+			}
 		}
 	}
 
@@ -380,6 +392,14 @@ if len(allLinkedIDs) > 0 {
         log.Printf("[GrowerAI-WS] ✓ Injected %d collective learnings into Knowledge Section", len(collectiveResults))
     }
 
+    // "What have you been researching?" is answered from the dialogue state, not memory
+    if status := statusContext(ctx, cfg, engine, userID, content); status != "" {
+        llmMessages = append(llmMessages, map[string]string{
+            "role":    "system",
+            "content": status,
+        })
+    }
+
     // 1. Inject Historical Narrative (Oldest -> Newest)
     for _, hist := range historicalTimeline {
         llmMessages = append(llmMessages, map[string]string{
@@ -551,6 +571,7 @@ err = streamLLMResponseFromHTTP(conn, conn.conn, httpResp, &botResponse, &toksPe
 			Tier:               memory.TierRecent,
 			UserID:             &userIDStr,
 			IsCollective:       false,
+			SourceKind:         memory.SourceUserConversation,
 			CreatedAt:          now,
 			LastAccessedAt:     now,
 			AccessCount:        0,
@@ -580,6 +601,7 @@ err = streamLLMResponseFromHTTP(conn, conn.conn, httpResp, &botResponse, &toksPe
 		Content:   botResponseWithStats,
 		CreatedAt: time.Now(),
 	}
+	botMsg.SetUsedMemoryIDs(feedbackMemoryIDs(allResults, collectiveResults))
 	if err := db.DB.Create(&botMsg).Error; err != nil {
 		log.Printf("[GrowerAI-WS] WARNING: Failed to save bot message: %v", err)
 	}
@@ -732,7 +754,7 @@ Be honest about mistakes. Don't create goals for simple questions that were alre
 		}
 		req.Header.Set("Content-Type", "application/json")
 
-		client := &http.Client{Timeout: 90 * time.Second}
+		client := httpclient.New(90 * time.Second)
 		resp, err := client.Do(req)
 		if err != nil {
 			return fmt.Errorf("failed to send request: %w", err)
@@ -774,7 +796,7 @@ Be honest about mistakes. Don't create goals for simple questions that were alre
 	if err != nil {
 		log.Printf("[Reflection] WARNING: Failed to parse reflection S-expression: %v", err)
 		if len(content) > 200 {
-			log.Printf("[Reflection] Raw response: %s...", content[:200])
+			log.Printf("[Reflection] Raw response: %s", truncate(content, 200))
 		} else {
 			log.Printf("[Reflection] Raw response: %s", content)
 		}
@@ -830,6 +852,7 @@ if reflection.UserGaveFeedback && reflection.FeedbackSummary != "" {
 				Tier:            memory.TierRecent,
 				UserID:          &userIDStr,
 				IsCollective:    false,
+				SourceKind:      memory.SourceUserConversation,
 				CreatedAt:       time.Now(),
 				LastAccessedAt:  time.Now(),
 				ImportanceScore: 0.8, // Important for identity
@@ -855,6 +878,7 @@ if reflection.ImportantLearning && reflection.LearningContent != "" {
 				Content:         reflection.LearningContent,
 				Tier:            memory.TierRecent,
 				IsCollective:    true, // Collective learning
+				SourceKind:      memory.SourceUserConversation, // Learned from a chat
 				CreatedAt:       time.Now(),
 				LastAccessedAt:  time.Now(),
 				ImportanceScore: 0.7,
@@ -864,6 +888,12 @@ if reflection.ImportantLearning && reflection.LearningContent != "" {
 				ValidationCount: 1,
 				ConceptTags:     []string{"learning", "reflection"},
 			}
+
+			// Derived from this user's conversation: only collective if they consented
+			userIDStr := fmt.Sprintf("%d", userID)
+			memory.ApplyPersonalProvenance(mem, []string{userIDStr}, map[string]bool{
+				userIDStr: userAllowsCollectiveSharing(userID),
+			})
 			
 			if err := storage.Store(ctx, mem); err != nil {
 				log.Printf("[Reflection] WARNING: Failed to store learning: %v", err)
@@ -876,6 +906,19 @@ if reflection.ImportantLearning && reflection.LearningContent != "" {
 	return nil
 }
 
+// userAllowsCollectiveSharing reports whether a user consented to content derived from
+// their conversations being stored as collective memory
+func userAllowsCollectiveSharing(userID uint) bool {
+	if db.DB == nil {
+		return false
+	}
+	var u user.User
+	if err := db.DB.Select("allow_collective_sharing").First(&u, userID).Error; err != nil {
+		return false
+	}
+	return u.AllowCollectiveSharing
+}
+
 // createReflectionGoal creates a dialogue goal from reflection analysis
 func createReflectionGoal(ctx context.Context, db *gorm.DB, description string, source string, priority int, userID uint) error {
 	stateManager := dialogue.NewStateManager(db)
@@ -900,6 +943,10 @@ func createReflectionGoal(ctx context.Context, db *gorm.DB, description string,
 		Progress:    0.0,
 		Status:      dialogue.GoalStatusActive,
 		Actions:     []dialogue.Action{},
+		// Provenance: research from this goal inherits the user's sharing consent
+		Metadata: map[string]interface{}{
+			memory.MetadataSourceUserIDs: fmt.Sprintf("%d", userID),
+		},
 	}
 	
 	state.ActiveGoals = append(state.ActiveGoals, goal)
@@ -14,8 +14,10 @@ import (
 
     "go-llama/internal/chat"
     "go-llama/internal/config"
+    "go-llama/internal/httpclient"
     "go-llama/internal/memory"
     "go-llama/internal/db"
+    "go-llama/internal/utils"
 )
 
 // handleStandardLLMWebSocket processes standard LLM messages via WebSocket with streaming
@@ -439,7 +441,7 @@ func performWebSearch(cfg *config.Config, allMessages []chat.Message, conn *safe
         searchQuery = "site:" + siteFilter + " " + searchQuery
     }
 
-    httpResp, err := http.Get(searxngURL + "?q=" + url.QueryEscape(searchQuery) + "&format=json")
+    httpResp, err := httpclient.New(0).Get(searxngURL + "?q=" + url.QueryEscape(searchQuery) + "&format=json")
     if err != nil {
         log.Printf("SearxNG request failed: %v", err)
         return []map[string]string{}
@@ -497,7 +499,7 @@ func performWebSearch(cfg *config.Config, allMessages []chat.Message, conn *safe
                 "snippet": r.Content,
             })
             // Debug: Log what we're sending to LLM
-            log.Printf("📄 Source [%s]: %s", r.Title, r.Content[:min(len(r.Content), 100)])
+            log.Printf("📄 Source [%s]: %s", r.Title, utils.Prefix(r.Content, 100))
         }
     }
 
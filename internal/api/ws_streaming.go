@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"go-llama/internal/httpclient"
 )
 
 // streamLLMResponseWS handles streaming LLM responses over WebSocket
@@ -38,7 +39,7 @@ func streamLLMResponseWS(safeConn *safeWSConn, rawConn *websocket.Conn, llmURL s
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequestWithContext(ctx, "POST", llmURL, strings.NewReader(string(body)))
 	req.Header.Set("Content-Type", "application/json")
-	client := http.Client{Timeout: 0}
+	client := httpclient.New(0)
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Printf("LLM HTTP request failed: %v", err)
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditEntry records one call to a mutating protected endpoint, allowed or not
+type AuditEntry struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Subject    string    `gorm:"size:128;index" json:"subject"` // AdminTokenSubject, "user:<id>:<name>", or empty when unauthenticated
+	Method     string    `gorm:"size:8;not null" json:"method"`
+	Route      string    `gorm:"size:255;not null" json:"route"` // The route pattern, e.g. /admin/principles/:slot/rollback
+	Path       string    `gorm:"size:512;not null" json:"path"`
+	IP         string    `gorm:"size:64" json:"ip"`
+	Status     int       `gorm:"not null" json:"status"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// audit writes the entry for a finished call to a known route; a failed write is
+// logged and does not change the response
+func (g *Guard) audit(c *gin.Context, subject string, start time.Time) {
+	if g.db == nil || c.FullPath() == "" {
+		return
+	}
+	entry := AuditEntry{
+		Subject:    subject,
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Path:       c.Request.URL.Path,
+		IP:         c.ClientIP(),
+		Status:     c.Writer.Status(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err := g.db.WithContext(c.Request.Context()).Create(&entry).Error; err != nil {
+		log.Printf("[Auth] WARNING: Failed to write audit entry for %s %s: %v", entry.Method, entry.Path, err)
+	}
+}
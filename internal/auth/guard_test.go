@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-llama/internal/config"
+	"go-llama/internal/user"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeCounter counts hits in memory, without windows expiring
+type fakeCounter struct {
+	hits map[string]int64
+}
+
+func (f *fakeCounter) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	f.hits[key]++
+	return f.hits[key], nil
+}
+
+func setupGuard(t *testing.T, perIP, perCaller int) (*gin.Engine, *gorm.DB, *config.Config) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&AuditEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Server.JWTSecret = "secret"
+	cfg.Server.AdminToken = "admin-secret"
+	cfg.Server.RateLimit.PerIPPerMinute = perIP
+	cfg.Server.RateLimit.PerCallerPerMinute = perCaller
+
+	gin.SetMode(gin.TestMode)
+	guard := NewGuard(cfg, &fakeCounter{hits: map[string]int64{}}, db)
+	r := gin.New()
+	r.POST("/admin/config/reload", guard.Protect(true), func(c *gin.Context) { c.String(http.StatusOK, "reloaded") })
+	r.POST("/api/goals/:id/stop", guard.Protect(false), func(c *gin.Context) { c.String(http.StatusOK, "stopped") })
+	return r, db, cfg
+}
+
+func guardRequest(r *gin.Engine, path, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGuardAllowsAdminTokenAndSessions(t *testing.T) {
+	r, db, cfg := setupGuard(t, 100, 100)
+	adminJWT := setupTestJWT(cfg.Server.JWTSecret, 1, "root", string(user.RoleAdmin), time.Hour)
+	userJWT := setupTestJWT(cfg.Server.JWTSecret, 2, "alice", string(user.RoleUser), time.Hour)
+
+	for name, tc := range map[string]struct{ path, token string }{
+		"admin token on admin route":   {"/admin/config/reload", "admin-secret"},
+		"admin session on admin route": {"/admin/config/reload", adminJWT},
+		"user session on goal route":   {"/api/goals/g1/stop", userJWT},
+	} {
+		if w := guardRequest(r, tc.path, tc.token); w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", name, w.Code)
+		}
+	}
+
+	var entry AuditEntry
+	if err := db.Where("subject = ?", AdminTokenSubject).First(&entry).Error; err != nil {
+		t.Fatalf("expected the admin token call audited: %v", err)
+	}
+	if entry.Route != "/admin/config/reload" || entry.Method != http.MethodPost || entry.Status != http.StatusOK {
+		t.Errorf("unexpected audit entry %+v", entry)
+	}
+	var count int64
+	db.Model(&AuditEntry{}).Where("subject = ?", "user:2:alice").Count(&count)
+	if count != 1 {
+		t.Errorf("expected the session call audited with its user, got %d", count)
+	}
+}
+
+func TestGuardDeniesWithoutDetails(t *testing.T) {
+	r, db, cfg := setupGuard(t, 100, 100)
+	userJWT := setupTestJWT(cfg.Server.JWTSecret, 2, "alice", string(user.RoleUser), time.Hour)
+
+	for name, tc := range map[string]struct {
+		path, token string
+		want        int
+		body        string
+	}{
+		"no token":          {"/admin/config/reload", "", http.StatusUnauthorized, `{"error":{"message":"Unauthorized"}}`},
+		"wrong admin token": {"/admin/config/reload", "admin-guess", http.StatusUnauthorized, `{"error":{"message":"Unauthorized"}}`},
+		"no token on goal":  {"/api/goals/g1/stop", "", http.StatusUnauthorized, `{"error":{"message":"Unauthorized"}}`},
+		"user on admin":     {"/admin/config/reload", userJWT, http.StatusForbidden, `{"error":{"message":"Forbidden"}}`},
+	} {
+		w := guardRequest(r, tc.path, tc.token)
+		if w.Code != tc.want || w.Body.String() != tc.body {
+			t.Errorf("%s: expected %d %s, got %d %s", name, tc.want, tc.body, w.Code, w.Body.String())
+		}
+	}
+
+	var denied int64
+	db.Model(&AuditEntry{}).Where("status IN ?", []int{http.StatusUnauthorized, http.StatusForbidden}).Count(&denied)
+	if denied != 4 {
+		t.Errorf("expected every denied call audited, got %d", denied)
+	}
+}
+
+func TestGuardRateLimitsPerIPAndPerCaller(t *testing.T) {
+	r, _, _ := setupGuard(t, 3, 100)
+	for i := 0; i < 3; i++ {
+		guardRequest(r, "/api/goals/g1/stop", "")
+	}
+	w := guardRequest(r, "/admin/config/reload", "admin-secret")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("expected the IP limited before auth, got %d", w.Code)
+	}
+
+	r, _, _ = setupGuard(t, 100, 2)
+	for i := 0; i < 2; i++ {
+		if w := guardRequest(r, "/admin/config/reload", "admin-secret"); w.Code != http.StatusOK {
+			t.Fatalf("expected call %d allowed, got %d", i+1, w.Code)
+		}
+	}
+	if w := guardRequest(r, "/admin/config/reload", "admin-secret"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the caller limited, got %d", w.Code)
+	}
+}
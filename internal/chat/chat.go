@@ -1,7 +1,9 @@
 package chat
 
 import (
+	"encoding/json"
 	"time"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -23,10 +25,30 @@ type Message struct {
 	ChatID    uint           `json:"chat_id"`
 	Sender    string         `json:"sender"`   // "user" or "bot"
 	Content   string         `json:"content"`
+	Rating    string         `json:"rating,omitempty" gorm:"type:varchar(10)"` // "up", "down" or "" (unrated)
+	MemoryIDs datatypes.JSON `json:"-" gorm:"type:jsonb"`                      // Memories injected into a bot reply ([]string)
 	CreatedAt time.Time      `json:"createdAt"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// UsedMemoryIDs returns the IDs of the memories a bot reply was generated from
+func (m *Message) UsedMemoryIDs() []string {
+	var ids []string
+	if len(m.MemoryIDs) > 0 {
+		_ = json.Unmarshal(m.MemoryIDs, &ids)
+	}
+	return ids
+}
+
+// SetUsedMemoryIDs records the memories a bot reply was generated from
+func (m *Message) SetUsedMemoryIDs(ids []string) {
+	if len(ids) == 0 {
+		m.MemoryIDs = nil
+		return
+	}
+	m.MemoryIDs, _ = json.Marshal(ids)
+}
+
 // Add a trivial method so coverage can be measured
 func (c *Chat) DisplayTitle() string {
 	return c.Title
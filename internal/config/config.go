@@ -12,6 +12,8 @@ import (
     "strings"
     "sync"
     "time"
+
+    "go-llama/internal/httpclient"
 )
 
 type LLMConfig struct {
@@ -20,6 +22,158 @@ type LLMConfig struct {
     ContextSize int    `json:"context_size"`
 }
 
+// Model providers accepted in ModelConfig.Provider
+const (
+    ProviderOpenAICompatible = "openai-compatible"
+    ProviderOpenAI           = "openai"
+    ProviderAnthropic        = "anthropic"
+)
+
+// hostedModelURLs are the default base URLs of hosted providers
+var hostedModelURLs = map[string]string{
+    ProviderOpenAI:    "https://api.openai.com",
+    ProviderAnthropic: "https://api.anthropic.com",
+}
+
+// ModelConfig is a GrowerAI model role. The default provider is a local
+// OpenAI-compatible server whose name and context size are discovered from /v1/models;
+// hosted providers are not queried, so their name must be set.
+type ModelConfig struct {
+    Name            string `json:"name"`
+    URL             string `json:"url"`
+    ContextSize     int    `json:"context_size"`
+    Provider        string `json:"provider,omitempty"`          // "openai-compatible" (default), "openai" or "anthropic"
+    APIKey          string `json:"api_key,omitempty"`
+    APIKeyEnv       string `json:"api_key_env,omitempty"`       // Environment variable read when api_key is empty
+    Organization    string `json:"organization,omitempty"`      // OpenAI only
+    MaxOutputTokens int    `json:"max_output_tokens,omitempty"` // Hosted only: caps max_tokens (default 4096)
+}
+
+// Hosted reports whether the role is served by a hosted API rather than a local server
+func (m ModelConfig) Hosted() bool {
+    return m.Provider != "" && m.Provider != ProviderOpenAICompatible
+}
+
+// Key returns the API key, reading APIKeyEnv when APIKey is empty
+func (m ModelConfig) Key() string {
+    if m.APIKey == "" && m.APIKeyEnv != "" {
+        return os.Getenv(m.APIKeyEnv)
+    }
+    return m.APIKey
+}
+
+// validate checks the provider and that a hosted role can be called without discovery
+func (m ModelConfig) validate() error {
+    switch m.Provider {
+    case "", ProviderOpenAICompatible:
+        return nil
+    case ProviderOpenAI, ProviderAnthropic:
+    default:
+        return fmt.Errorf("unknown provider %q", m.Provider)
+    }
+    if m.Name == "" {
+        return fmt.Errorf("%s models need a name", m.Provider)
+    }
+    if m.Key() == "" {
+        return fmt.Errorf("%s models need api_key or api_key_env", m.Provider)
+    }
+    return nil
+}
+
+// validateBudget rejects negative limits and soft limits above their hard limit
+func validateBudget(gai GrowerAIConfig) error {
+    b := gai.Budget
+    for _, period := range []struct {
+        name       string
+        soft, hard int64
+    }{
+        {"daily", b.DailySoftTokens, b.DailyHardTokens},
+        {"monthly", b.MonthlySoftTokens, b.MonthlyHardTokens},
+    } {
+        if period.soft < 0 || period.hard < 0 {
+            return fmt.Errorf("%s limits must not be negative", period.name)
+        }
+        if period.soft > 0 && period.hard > 0 && period.soft > period.hard {
+            return fmt.Errorf("%s soft limit %d is above the hard limit %d", period.name, period.soft, period.hard)
+        }
+    }
+    return nil
+}
+
+// memorySourceKinds matches memory.SourceKinds (config cannot import memory)
+var memorySourceKinds = map[string]bool{
+    "user_conversation":  true,
+    "dialogue_learning":  true,
+    "research_synthesis": true,
+    "digest":             true,
+    "principle_related":  true,
+}
+
+// validateSourceKinds rejects unknown memory source kinds
+func validateSourceKinds(kinds []string) error {
+    for _, kind := range kinds {
+        if !memorySourceKinds[kind] {
+            return fmt.Errorf("unknown source kind %q", kind)
+        }
+    }
+    return nil
+}
+
+// SamplingConfig holds the sampling parameters for one LLM call category. Temperature
+// is a pointer because 0 is a valid setting; the other fields are unset at zero.
+type SamplingConfig struct {
+    Temperature   *float64 `json:"temperature,omitempty"`
+    TopP          float64  `json:"top_p,omitempty"`
+    MaxTokens     int      `json:"max_tokens,omitempty"`     // Overrides the model's context size as the response limit
+    RepeatPenalty float64  `json:"repeat_penalty,omitempty"` // Sent as repeat_penalty; servers without it ignore the field
+}
+
+// SamplingSummarize is the sampling category for chat-side page summaries
+const SamplingSummarize = "summarize"
+
+// SamplingCategories lists the keys accepted in growerai.sampling
+var SamplingCategories = []string{
+    "reflection", "deep_reflection", "plan_generation", "evaluation", "synthesis", "synthesis_review", "validation", "post_mortem",
+    SamplingSummarize,
+}
+
+// Validate checks that the parameters are within the ranges servers accept
+func (s SamplingConfig) Validate() error {
+    if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+        return fmt.Errorf("temperature must be between 0 and 2, got %.2f", *s.Temperature)
+    }
+    if s.TopP < 0 || s.TopP > 1 {
+        return fmt.Errorf("top_p must be between 0 and 1, got %.2f", s.TopP)
+    }
+    if s.MaxTokens < 0 {
+        return fmt.Errorf("max_tokens must not be negative, got %d", s.MaxTokens)
+    }
+    if s.RepeatPenalty < 0 || s.RepeatPenalty > 2 {
+        return fmt.Errorf("repeat_penalty must be between 0 and 2, got %.2f", s.RepeatPenalty)
+    }
+    return nil
+}
+
+// validateSampling rejects unknown categories and out-of-range parameters
+func validateSampling(sampling map[string]SamplingConfig) error {
+    for name, params := range sampling {
+        known := false
+        for _, category := range SamplingCategories {
+            if name == category {
+                known = true
+                break
+            }
+        }
+        if !known {
+            return fmt.Errorf("unknown category %q (expected one of %s)", name, strings.Join(SamplingCategories, ", "))
+        }
+        if err := params.Validate(); err != nil {
+            return fmt.Errorf("%s: %w", name, err)
+        }
+    }
+    return nil
+}
+
 type GrowerAIConfig struct {
     Enabled bool `json:"enabled"`
 
@@ -30,21 +184,39 @@ type GrowerAIConfig struct {
         BackgroundQueueSize      int  `json:"background_queue_size"`
         CriticalTimeoutSeconds   int  `json:"critical_timeout_seconds"`
         BackgroundTimeoutSeconds int  `json:"background_timeout_seconds"`
+        // Cancel a just-started dialogue request when user chat is waiting for a slot
+        PreemptBackground    bool `json:"preempt_background"`
+        PreemptWindowSeconds int  `json:"preempt_window_seconds"` // Only requests younger than this are preempted
+        // A streamed completion whose last StreamRepeatWindow characters already occur
+        // StreamRepeatLimit times is stopped as a repetition loop
+        StreamRepeatWindow int `json:"stream_repeat_window"`
+        StreamRepeatLimit  int `json:"stream_repeat_limit"`
     } `json:"llm_queue"`
-    ReasoningModel struct {
-        Name        string `json:"name"`
-        URL         string `json:"url"`
-        ContextSize int    `json:"context_size"`
-    } `json:"reasoning_model"`
+    // Token limits on hosted model providers, per UTC day and calendar month (0 = no
+    // limit). At a soft limit dialogue calls move to the simple model; at a hard limit
+    // dialogue cycles and other background hosted calls stop. Chat is exempt.
+    Budget struct {
+        Enabled           bool  `json:"enabled"`
+        DailySoftTokens   int64 `json:"daily_soft_tokens"`
+        DailyHardTokens   int64 `json:"daily_hard_tokens"`
+        MonthlySoftTokens int64 `json:"monthly_soft_tokens"`
+        MonthlyHardTokens int64 `json:"monthly_hard_tokens"`
+    } `json:"budget"`
+    ReasoningModel ModelConfig `json:"reasoning_model"`
     EmbeddingModel struct {
         Name string `json:"name"`
         URL  string `json:"url"`
     } `json:"embedding_model"`
-    SimpleModel struct {
-        Name        string `json:"name"`
-        URL         string `json:"url"`
-        ContextSize int    `json:"context_size"`
-    } `json:"simple_model"`
+    SimpleModel ModelConfig `json:"simple_model"`
+    // Model for memory compression; defaults to the reasoning model when no URL is set
+    CompressionModel ModelConfig `json:"compression_model"`
+    // Use the closest served model when a configured name is not served, instead of
+    // failing at startup
+    AllowModelFallback bool `json:"allow_model_fallback"`
+    // Sampling overrides per LLM call category: the dialogue call types used by
+    // dialogue.model_routing, plus "summarize" for chat-side page summaries. Fields left
+    // unset keep the call site's built-in default.
+    Sampling map[string]SamplingConfig `json:"sampling"`
     Qdrant struct {
         URL        string `json:"url"`
         Collection string `json:"collection"`
@@ -54,6 +226,7 @@ type GrowerAIConfig struct {
     // Storage limits and space-based compression
     StorageLimits struct {
         MaxTotalMemories int     `json:"max_total_memories"`   // Total memory limit across all tiers
+        MaxCollectiveMemories int `json:"max_collective_memories"` // Evict lowest-value collective memories beyond this (0 = unlimited)
         TierAllocation   struct {
             Recent  float64 `json:"recent"`   // Percentage allocation for Recent tier (0.0-1.0)
             Medium  float64 `json:"medium"`   // Percentage allocation for Medium tier
@@ -74,11 +247,22 @@ type GrowerAIConfig struct {
         MaxMemories       int     `json:"max_memories"`        // Max memories to retrieve per query
         MinScore          float64 `json:"min_score"`           // Minimum similarity score
         MaxLinkedMemories int     `json:"max_linked_memories"` // Max linked memories to traverse
+        SourceKinds       []string `json:"source_kinds"`       // Default memory sources for chat retrieval (empty = all)
+        AccessTracking    struct {
+            Mode         string `json:"mode"`          // "every_hit" or "hourly" (count each memory at most once per hour)
+            FlushSeconds int    `json:"flush_seconds"` // How often batched access updates are written
+        } `json:"access_tracking"`
     } `json:"retrieval"`
 
     // Tagging configuration
     Tagging struct {
         BatchSize int `json:"batch_size"` // Memories to tag per cycle
+        // Re-extracts concept tags for memories tagged by an older prompt or model
+        Retag struct {
+            OnStartup bool `json:"on_startup"` // Start (or resume) a retag run at boot
+            BatchSize int  `json:"batch_size"` // Memories per page and checkpoint
+            PerMinute int  `json:"per_minute"` // Rate limit on LLM tagging calls
+        } `json:"retag"`
     } `json:"tagging"`
 
     Compression struct {
@@ -103,6 +287,10 @@ type GrowerAIConfig struct {
         AIManagedSlots     []int   `json:"ai_managed_slots"`     // Slots 4-10: AI-managed
         MinRatingThreshold float64 `json:"min_rating_threshold"` // Minimum rating to become a principle
         ExtractionLimit    int     `json:"extraction_limit"`    // Max memories to analyze for patterns
+        // Evolution runs on its own schedule, and skips a run while the dialogue engine
+        // changed a principle within the last DialogueCooldownHours (negative never skips)
+        EvolutionScheduleHours int     `json:"evolution_schedule_hours"`
+        DialogueCooldownHours  float64 `json:"dialogue_cooldown_hours"`
     } `json:"principles"`
 
     // Phase 4: Personality Control
@@ -130,13 +318,189 @@ type GrowerAIConfig struct {
         MaxThoughtsPerCycle       int    `json:"max_thoughts_per_cycle"`
         ActionRequirementInterval int    `json:"action_requirement_interval"`
         NoveltyWindowHours        int    `json:"novelty_window_hours"`
+        // Keyword overlap (0-1) at which a thought repeats one from the novelty window
+        NoveltyThreshold float64 `json:"novelty_threshold"`
+        // Embedding similarity (0-1) above which a memory is left out of the reflection context as a near-copy (1 keeps them)
+        ReflectionDiversityThreshold float64 `json:"reflection_diversity_threshold"`
+        // Times a research goal's plan may be replaced after a progress assessment
+        MaxReplansPerGoal int `json:"max_replans_per_goal"`
         // Enhanced reasoning
-        ReasoningDepth         string `json:"reasoning_depth"`         // "conservative", "moderate", "deep"
+        ReasoningDepth         string `json:"reasoning_depth"`         // "conservative", "moderate", "deep" or "auto" to choose each cycle
         EnableSelfAssessment   bool   `json:"enable_self_assessment"`   // Analyze strengths/weaknesses
         EnableMetaLearning     bool   `json:"enable_meta_learning"`     // Learn about learning strategies
         EnableStrategyTracking bool   `json:"enable_strategy_tracking"` // Track what works/doesn't
         StoreInsights          bool   `json:"store_insights"`           // Store learnings in memory
         DynamicActionPlanning  bool   `json:"dynamic_action_planning"`  // LLM generates action plans
+        // Similarity above which a new learning is merged into an existing memory (>1.0 disables)
+        DedupThreshold float64 `json:"dedup_threshold"`
+        // Empirical A/B trials run before committing a principle modification
+        PrincipleTrials      int     `json:"principle_trials"`       // Trials per branch
+        PrincipleTrialMargin float64 `json:"principle_trial_margin"` // Score lead the new principle needs (0.0-1.0; negative accepts any lead)
+        // Periodic digest memory summarizing recent cycles: "daily", "weekly" or "off"
+        DigestFrequency string `json:"digest_frequency"`
+        // Seed for every cycle's random choices, to replay a cycle from its recorded seed (0 = time-based)
+        RandomSeed int64 `json:"random_seed"`
+        // Heuristic detection of prompt-injection phrases in parsed web content
+        InjectionDetection struct {
+            Enabled           bool    `json:"enabled"`
+            ConfidencePenalty float64 `json:"confidence_penalty"` // Subtracted from parse confidence when flagged
+        } `json:"injection_detection"`
+        // Action deadlines are capped to end this long before the cycle does; actions that
+        // would get less than the minimum are left pending for the next cycle
+        ActionTimeMarginSeconds int `json:"action_time_margin_seconds"`
+        MinActionTimeSeconds    int `json:"min_action_time_seconds"`
+        // Model tier per LLM call type ("simple" or "reasoning"); omitted types keep their defaults
+        ModelRouting map[string]string `json:"model_routing"`
+        // Goal priority rises over the window before a deadline by up to MaxBoost,
+        // following (elapsed fraction of the window)^CurveExponent
+        DeadlineEscalation struct {
+            WindowHours   float64 `json:"window_hours"`
+            MaxBoost      int     `json:"max_boost"`
+            CurveExponent float64 `json:"curve_exponent"`
+        } `json:"deadline_escalation"`
+        // Proposed goals are scored against existing goals by combining string, keyword
+        // and embedding similarity; weights are normalized over the available signals
+        GoalDedup struct {
+            StringWeight    float64 `json:"string_weight"`
+            KeywordWeight   float64 `json:"keyword_weight"`
+            EmbeddingWeight float64 `json:"embedding_weight"`
+            Threshold       float64 `json:"threshold"`
+        } `json:"goal_dedup"`
+        // Proposed goals without a priority get DefaultPriority (1-10); descriptions shorter
+        // than MinDescriptionChars, or with no words beyond stop words, are rejected. When
+        // fewer than MinAcceptanceRate of the proposals over the last FeedbackWindowCycles
+        // were accepted, the reflection is asked for fewer, more distinct goals (negative: never).
+        GoalProposals struct {
+            DefaultPriority      int     `json:"default_priority"`
+            MinDescriptionChars  int     `json:"min_description_chars"`
+            FeedbackWindowCycles int     `json:"feedback_window_cycles"`
+            MinAcceptanceRate    float64 `json:"min_acceptance_rate"`
+        } `json:"goal_proposals"`
+        // Base values the adaptive thresholds start from before adjusting to memory count
+        // and goal success rate
+        Adaptive struct {
+            SearchThreshold    float64 `json:"search_threshold"`
+            GoalSimilarity     float64 `json:"goal_similarity"`
+            ToolTimeoutSeconds int     `json:"tool_timeout_seconds"`
+            // Ranges the adapted thresholds are clamped to
+            Bounds struct {
+                SearchMin         float64 `json:"search_min"`
+                SearchMax         float64 `json:"search_max"`
+                CollectiveMax     float64 `json:"collective_max"` // Cap on the collective memory search threshold
+                GoalSimilarityMin float64 `json:"goal_similarity_min"`
+                GoalSimilarityMax float64 `json:"goal_similarity_max"`
+            } `json:"bounds"`
+            // A non-zero pin fixes that threshold and turns off its adaptation
+            Pins struct {
+                SearchThreshold     float64 `json:"search_threshold"`
+                CollectiveThreshold float64 `json:"collective_threshold"`
+                GoalSimilarity      float64 `json:"goal_similarity"`
+            } `json:"pins"`
+        } `json:"adaptive"`
+        // Completed goals beyond Retain move from the persisted state to an archive table;
+        // ArchiveLookup lets the recently abandoned window reach into it
+        CompletedGoals struct {
+            Retain        int  `json:"retain"`
+            ArchiveLookup bool `json:"archive_lookup"`
+        } `json:"completed_goals"`
+        // Saved states kept to roll back to when the latest fails its checksum on load
+        StateVersions int `json:"state_versions"`
+        // Action results larger than this many bytes are kept in a result table, with
+        // only a preview and a reference in goal state
+        ResultStoreThresholdBytes int `json:"result_store_threshold_bytes"`
+        // Evaluator prompt templates found here replace the built-in ones, by file name
+        // (e.g. research_plan.tmpl); loaded once at startup
+        PromptsDir string `json:"prompts_dir"`
+        // Cycles never overlap in one process; with Redis set, replicas also share a lease
+        // on the cycle, renewed while it runs and lapsing LeaseSeconds after a crash
+        CycleLock struct {
+            Redis        bool `json:"redis"`
+            LeaseSeconds int  `json:"lease_seconds"`
+        } `json:"cycle_lock"`
+        // User interests count each memory's concept tags with a weight that halves every
+        // HalfLifeDays; a topic a user turns down is left out of goals for the cooldown
+        Interests struct {
+            HalfLifeDays            float64 `json:"half_life_days"`
+            SuppressionCooldownDays float64 `json:"suppression_cooldown_days"`
+        } `json:"interests"`
+        // A parse of a page that a research synthesis at least MinSimilarity to the
+        // question already drew on, within MaxAgeDays, is answered from that memory
+        MemoryReuse struct {
+            Enabled       bool    `json:"enabled"`
+            MinSimilarity float64 `json:"min_similarity"`
+            MaxAgeDays    float64 `json:"max_age_days"`
+        } `json:"memory_reuse"`
+        // A search whose normalized query overlaps one run within WindowHours by at least
+        // MinOverlap goes straight to the page that search chose, if that page parsed with
+        // confidence of at least MinParseQuality
+        SearchLedger struct {
+            Enabled         bool    `json:"enabled"`
+            WindowHours     float64 `json:"window_hours"`
+            MinOverlap      float64 `json:"min_overlap"`
+            MinParseQuality float64 `json:"min_parse_quality"`
+        } `json:"search_ledger"`
+        // Each cycle's model replies and starting goals are recorded for cmd/replay,
+        // keeping the last KeepCycles cycles
+        Transcripts struct {
+            Enabled    bool `json:"enabled"`
+            KeepCycles int  `json:"keep_cycles"`
+        } `json:"transcripts"`
+
+        // Focus areas from a self-assessment steer the next cycles' prompts and goal selection
+        FocusAreas struct {
+            ExpiryCycles  int     `json:"expiry_cycles"`  // Cycles an area lasts unless replaced
+            PriorityBonus int     `json:"priority_bonus"` // Added to matching goals' effective priority
+            MinSimilarity float64 `json:"min_similarity"` // Goal/area similarity needed for the bonus
+        } `json:"focus_areas"`
+
+        // Synthesis and deep reflection are streamed so a looping or overlong answer can be
+        // stopped early; a cut-short synthesis of at least MinSynthesisChars is still kept
+        Streaming struct {
+            Enabled           bool `json:"enabled"`
+            MinSynthesisChars int  `json:"min_synthesis_chars"`
+        } `json:"streaming"`
+
+        // A research synthesis is scored by the simple model before it is stored; one
+        // scoring below MinScore is stored with importance and trust scaled down
+        // ("discount") or its research is replanned once first ("retry")
+        SynthesisGate struct {
+            Enabled  bool    `json:"enabled"`
+            MinScore float64 `json:"min_score"`
+            OnFail   string  `json:"on_fail"`
+        } `json:"synthesis_gate"`
+
+        // Chat questions about what the system has been working on are answered with a
+        // summary of its dialogue state of about MaxTokens added to the chat context
+        StatusIntent struct {
+            Enabled   bool `json:"enabled"`
+            MaxTokens int  `json:"max_tokens"`
+        } `json:"status_intent"`
+
+        // While more than MaxQueueDepth requests are queued ahead of the dialogue's calls,
+        // or they recently waited longer than MaxWaitSeconds, cycles skip reflection and
+        // planning and only run goal steps already planned
+        LoadShedding struct {
+            Enabled        bool    `json:"enabled"`
+            MaxQueueDepth  int     `json:"max_queue_depth"`
+            MaxWaitSeconds float64 `json:"max_wait_seconds"`
+        } `json:"load_shedding"`
+
+        // Execution capacity is ActionsPerCycle actions per cycle at the base interval;
+        // while the actions left across active goals exceed BacklogMultiple days of it,
+        // proposed goals are refused and, with ShortestJobFirst, the goal scheduler
+        // prefers goals with the fewest steps left
+        Backpressure struct {
+            Enabled          bool    `json:"enabled"`
+            ActionsPerCycle  int     `json:"actions_per_cycle"`
+            BacklogMultiple  float64 `json:"backlog_multiple"`
+            ShortestJobFirst bool    `json:"shortest_job_first"`
+        } `json:"backpressure"`
+
+        // A goal paused on a question for the user resumes after TimeoutHours without an
+        // answer, noting it should proceed with its best judgment
+        UserQuestions struct {
+            TimeoutHours float64 `json:"timeout_hours"`
+        } `json:"user_questions"`
     } `json:"dialogue"`
 
     // Phase 3.2: Tool Infrastructure
@@ -149,13 +513,46 @@ type GrowerAIConfig struct {
             MaxResultsInteractive int    `json:"max_results_interactive"`
             MaxResultsIdle        int    `json:"max_results_idle"`
             SafeSearch            bool   `json:"safe_search"`
+            // Multiple instances with round-robin failover (overrides URL when set)
+            Instances []struct {
+                URL    string `json:"url"`
+                Weight int    `json:"weight"`
+            } `json:"instances"`
+            QueryTimeout    int `json:"query_timeout"`    // seconds per instance attempt, before failing over
+            UnhealthyAfter  int `json:"unhealthy_after"`  // consecutive failures before skipping an instance
+            ReprobeInterval int `json:"reprobe_interval"` // seconds before retrying an unhealthy instance
+            Cache struct {
+                Enabled    bool `json:"enabled"`
+                TTLHours   int  `json:"ttl_hours"`
+                MaxEntries int  `json:"max_entries"`
+            } `json:"cache"`
         } `json:"searxng"`
         WebParse struct {
             Enabled       bool   `json:"enabled"`
             MaxPageSizeMB int    `json:"max_page_size_mb"`
             Timeout       int    `json:"timeout"` // seconds
             UserAgent     string `json:"user_agent"`
-            ChunkSize     int    `json:"chunk_size"`
+            // Selective parsing splits large pages into chunks of this many estimated
+            // tokens, each repeating the last ChunkOverlapTokens of the previous one
+            ChunkTokens        int `json:"chunk_tokens"`
+            ChunkOverlapTokens int `json:"chunk_overlap_tokens"` // Negative disables overlap
+            ExtractionMode string `json:"extraction_mode"` // "raw", "readability" or "selective"
+            // Language pages are expected in (ISO 639-1) and what happens to others:
+            // "keep", "skip" (a fallback URL is tried) or "translate" (with the simple model)
+            Language struct {
+                Target string `json:"target"`
+                Policy string `json:"policy"`
+            } `json:"language"`
+            HTTPCache struct {
+                Enabled      bool `json:"enabled"`
+                MaxSizeMB    int  `json:"max_size_mb"`    // Total body budget
+                ReuseMinutes int  `json:"reuse_minutes"`  // Reuse without revalidating for this long
+                TTLHours     int  `json:"ttl_hours"`      // Keep for conditional requests this long
+            } `json:"http_cache"`
+            DomainPolicy struct {
+                Mode     string   `json:"mode"`     // "off", "allowlist" or "blocklist"; private/LAN targets are always refused
+                Patterns []string `json:"patterns"` // "example.com" (and subdomains), "*.example.com", ".gov"
+            } `json:"domain_policy"`
         } `json:"webparse"`
         Sandbox struct {
             Enabled       bool   `json:"enabled"`
@@ -168,17 +565,40 @@ type GrowerAIConfig struct {
             WorkspacePath string `json:"workspace_path"`
             LogLevel      string `json:"log_level"`
         } `json:"sandbox"`
+        // Each idle action is traced through its tool, HTTP requests and LLM calls; the
+        // timings are kept in action metadata, and also sent to an OTLP/HTTP collector
+        // when OTLPEndpoint (e.g. "http://otel-collector:4318") is set
+        Tracing struct {
+            OTLPEndpoint string `json:"otlp_endpoint"`
+            ServiceName  string `json:"service_name"`
+        } `json:"tracing"`
+        // Every tool's output is cut to MaxBytes (or its PerTool entry), keeping the head
+        // and tail; outputs over HardMaxBytes fail the call
+        OutputLimits struct {
+            MaxBytes     int            `json:"max_bytes"`
+            HardMaxBytes int            `json:"hard_max_bytes"`
+            PerTool      map[string]int `json:"per_tool"` // Tool name -> max bytes
+        } `json:"output_limits"`
     } `json:"tools"`
 }
 
 type Config struct {
-    modelsMu sync.RWMutex // Mutex for protecting model updates during refresh
+    modelsMu       sync.RWMutex      // Mutex for protecting model updates during refresh
+    resolvedModels map[string]string // GrowerAI role -> served model name, set by discovery
 
     Server struct {
         Host      string `json:"host"`
         Port      int    `json:"port"`
         Subpath   string `json:"subpath"`
         JWTSecret string `json:"jwtSecret"`
+        // Bearer token accepted on admin and mutating dialogue endpoints in place of an
+        // admin session, for scripts; empty disables it
+        AdminToken string `json:"adminToken"`
+        // Requests per minute allowed on those endpoints, per client IP and per caller
+        RateLimit struct {
+            PerIPPerMinute     int `json:"perIpPerMinute"`
+            PerCallerPerMinute int `json:"perCallerPerMinute"`
+        } `json:"rateLimit"`
     } `json:"server"`
     Postgres struct {
         DSN string `json:"dsn"`
@@ -194,6 +614,38 @@ type Config struct {
         URL        string `json:"url"`
         MaxResults int    `json:"max_results"`
     } `json:"searxng"`
+    // The config file is polled for changes and reloaded without a restart where possible
+    ConfigReload struct {
+        PollSeconds int `json:"poll_seconds"` // Negative disables polling; manual reloads still work
+    } `json:"config_reload"`
+    // Dependency checks behind /readyz and the dialogue engine's pre-cycle check
+    Health struct {
+        TimeoutSeconds int `json:"timeout_seconds"` // Per-dependency check timeout
+        CacheSeconds   int `json:"cache_seconds"`   // How long a readiness snapshot is reused
+    } `json:"health"`
+    // Pooled transport shared by the tools, embedder and LLM queue's outbound requests
+    HTTPClient struct {
+        MaxIdleConnsPerHost    int    `json:"max_idle_conns_per_host"`
+        MaxConnsPerHost        int    `json:"max_conns_per_host"` // Negative removes the cap
+        IdleConnTimeoutSeconds int    `json:"idle_conn_timeout_seconds"`
+    } `json:"http_client"`
+    // Proxy and TLS trust for all outbound requests, including Qdrant's gRPC connection
+    Network NetworkConfig `json:"network"`
+}
+
+// NetworkConfig routes outbound requests through a proxy and trusts an internal CA
+type NetworkConfig struct {
+    ProxyURL           string               `json:"proxy_url"`            // Empty uses HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+    NoProxy            []string             `json:"no_proxy"`             // Hosts, domains, CIDRs or host:port reached directly
+    CABundle           string               `json:"ca_bundle"`            // PEM file trusted in addition to the system roots
+    InsecureSkipVerify bool                 `json:"insecure_skip_verify"` // Disables certificate checks; never use in production
+    Destinations       []NetworkDestination `json:"destinations"`         // Per-host overrides, checked before no_proxy
+}
+
+// NetworkDestination overrides the proxy for one host pattern
+type NetworkDestination struct {
+    Host     string `json:"host"`      // Same patterns as no_proxy
+    ProxyURL string `json:"proxy_url"` // Empty connects directly
 }
 
 var (
@@ -205,42 +657,148 @@ var (
 // LoadConfig reads config.json from disk (singleton)
 func LoadConfig(path string) (*Config, error) {
     once.Do(func() {
-        raw, err := os.ReadFile(path)
+        c, err := ReadConfig(path)
         if err != nil {
-            cfgErr = fmt.Errorf("failed to read config file: %w", err)
-            return
-        }
-        var c Config
-        if err := json.Unmarshal(raw, &c); err != nil {
-            cfgErr = fmt.Errorf("invalid config format: %w", err)
+            cfgErr = err
             return
         }
-        // Minimal validation
-        if c.Server.JWTSecret == "" {
-            cfgErr = errors.New("jwtSecret must be set in config")
+
+        // Outbound clients share one pooled transport; configure its proxy and CA trust
+        // before discovery sends anything
+        if err := httpclient.Configure(c.HTTPClientConfig()); err != nil {
+            cfgErr = fmt.Errorf("invalid http_client/network config: %w", err)
             return
         }
 
-        // Apply defaults for Phase 4 settings if not provided
-        applyGrowerAIDefaults(&c.GrowerAI)
-
         // Perform initial model discovery
         // We do this before assigning the global cfg to ensure valid data is exposed
         log.Println("[Config] Performing initial model discovery...")
-        if err := discoverModels(&c); err != nil {
-            log.Printf("[Config] Warning: Initial model discovery encountered issues: %v", err)
-            // We do not fail hard here; we proceed with potentially static config values
+        // Unreachable servers only log; a configured model its server does not serve fails
+        if err := discoverModels(c); err != nil {
+            cfgErr = fmt.Errorf("model check failed: %w", err)
+            return
         } else {
             log.Println("[Config] Initial model discovery complete.")
         }
 
-        cfg = &c
+        cfg = c
     })
     return cfg, cfgErr
 }
 
+// HTTPClientConfig returns the shared HTTP transport settings from the http_client and
+// network sections
+func (c *Config) HTTPClientConfig() httpclient.Config {
+    maxConnsPerHost := c.HTTPClient.MaxConnsPerHost
+    if maxConnsPerHost < 0 {
+        maxConnsPerHost = 0 // Unlimited
+    }
+    destinations := make([]httpclient.Destination, len(c.Network.Destinations))
+    for i, d := range c.Network.Destinations {
+        destinations[i] = httpclient.Destination{Host: d.Host, ProxyURL: d.ProxyURL}
+    }
+    return httpclient.Config{
+        MaxIdleConnsPerHost: c.HTTPClient.MaxIdleConnsPerHost,
+        MaxConnsPerHost:     maxConnsPerHost,
+        IdleConnTimeout:     time.Duration(c.HTTPClient.IdleConnTimeoutSeconds) * time.Second,
+        ProxyURL:            c.Network.ProxyURL,
+        NoProxy:             c.Network.NoProxy,
+        Destinations:        destinations,
+        CABundle:            c.Network.CABundle,
+        InsecureSkipVerify:  c.Network.InsecureSkipVerify,
+    }
+}
+
+// ReadConfig parses and validates a config file with defaults applied, without model
+// discovery or touching the loaded singleton. Used to reload individual settings at runtime.
+func ReadConfig(path string) (*Config, error) {
+    raw, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read config file: %w", err)
+    }
+    return parseConfig(raw)
+}
+
+// parseConfig validates raw config JSON and applies defaults
+func parseConfig(raw []byte) (*Config, error) {
+    var c Config
+    if err := json.Unmarshal(raw, &c); err != nil {
+        return nil, fmt.Errorf("invalid config format: %w", err)
+    }
+    // Minimal validation
+    if c.Server.JWTSecret == "" {
+        return nil, errors.New("jwtSecret must be set in config")
+    }
+
+    if err := validateSampling(c.GrowerAI.Sampling); err != nil {
+        return nil, fmt.Errorf("invalid growerai.sampling: %w", err)
+    }
+    if err := validateBudget(c.GrowerAI); err != nil {
+        return nil, fmt.Errorf("invalid growerai.budget: %w", err)
+    }
+    if err := validateSourceKinds(c.GrowerAI.Retrieval.SourceKinds); err != nil {
+        return nil, fmt.Errorf("invalid growerai.retrieval.source_kinds: %w", err)
+    }
+    for name, model := range map[string]ModelConfig{
+        "reasoning_model":   c.GrowerAI.ReasoningModel,
+        "simple_model":      c.GrowerAI.SimpleModel,
+        "compression_model": c.GrowerAI.CompressionModel,
+    } {
+        if err := model.validate(); err != nil {
+            return nil, fmt.Errorf("invalid growerai.%s: %w", name, err)
+        }
+    }
+
+    // Apply defaults for Phase 4 settings if not provided
+    applyGrowerAIDefaults(&c.GrowerAI)
+    if c.Server.RateLimit.PerIPPerMinute == 0 {
+        c.Server.RateLimit.PerIPPerMinute = 120
+    }
+    if c.Server.RateLimit.PerCallerPerMinute == 0 {
+        c.Server.RateLimit.PerCallerPerMinute = 60
+    }
+    if c.ConfigReload.PollSeconds == 0 {
+        c.ConfigReload.PollSeconds = 10
+    }
+    if c.Health.TimeoutSeconds == 0 {
+        c.Health.TimeoutSeconds = 2
+    }
+    if c.Health.CacheSeconds == 0 {
+        c.Health.CacheSeconds = 30
+    }
+    if c.HTTPClient.MaxIdleConnsPerHost <= 0 {
+        c.HTTPClient.MaxIdleConnsPerHost = 10
+    }
+    if c.HTTPClient.MaxConnsPerHost == 0 {
+        c.HTTPClient.MaxConnsPerHost = 64
+    }
+    if c.HTTPClient.IdleConnTimeoutSeconds <= 0 {
+        c.HTTPClient.IdleConnTimeoutSeconds = 90
+    }
+    return &c, nil
+}
+
 // applyGrowerAIDefaults sets sensible defaults for Phase 4 configuration
 func applyGrowerAIDefaults(gai *GrowerAIConfig) {
+    // Model role defaults
+    if gai.CompressionModel.URL == "" && !gai.CompressionModel.Hosted() {
+        gai.CompressionModel = gai.ReasoningModel
+    }
+    for _, model := range []*ModelConfig{&gai.ReasoningModel, &gai.SimpleModel, &gai.CompressionModel} {
+        if !model.Hosted() {
+            continue
+        }
+        if model.URL == "" {
+            model.URL = hostedModelURLs[model.Provider]
+        }
+        if model.ContextSize == 0 {
+            model.ContextSize = 128000
+        }
+        if model.MaxOutputTokens == 0 {
+            model.MaxOutputTokens = 4096
+        }
+    }
+
     // LLM Queue defaults
     if gai.LLMQueue.MaxConcurrent == 0 {
         gai.LLMQueue.MaxConcurrent = 2
@@ -257,6 +815,15 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if gai.LLMQueue.BackgroundTimeoutSeconds == 0 {
         gai.LLMQueue.BackgroundTimeoutSeconds = 180
     }
+    if gai.LLMQueue.PreemptWindowSeconds == 0 {
+        gai.LLMQueue.PreemptWindowSeconds = 5
+    }
+    if gai.LLMQueue.StreamRepeatWindow == 0 {
+        gai.LLMQueue.StreamRepeatWindow = 40
+    }
+    if gai.LLMQueue.StreamRepeatLimit == 0 {
+        gai.LLMQueue.StreamRepeatLimit = 8
+    }
     // Enable queue by default
     if !gai.LLMQueue.Enabled {
         gai.LLMQueue.Enabled = true
@@ -286,6 +853,12 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if gai.Principles.ExtractionLimit == 0 {
         gai.Principles.ExtractionLimit = 1000 // Analyze up to 1000 good memories
     }
+    if gai.Principles.EvolutionScheduleHours == 0 {
+        gai.Principles.EvolutionScheduleHours = 24
+    }
+    if gai.Principles.DialogueCooldownHours == 0 {
+        gai.Principles.DialogueCooldownHours = 12
+    }
 
     // Personality control
     if gai.Personality.GoodBehaviorBias == 0 {
@@ -332,6 +905,15 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if gai.Dialogue.NoveltyWindowHours == 0 {
         gai.Dialogue.NoveltyWindowHours = 2
     }
+    if gai.Dialogue.NoveltyThreshold == 0 {
+        gai.Dialogue.NoveltyThreshold = 0.85
+    }
+    if gai.Dialogue.ReflectionDiversityThreshold == 0 {
+        gai.Dialogue.ReflectionDiversityThreshold = 0.9
+    }
+    if gai.Dialogue.MaxReplansPerGoal == 0 {
+        gai.Dialogue.MaxReplansPerGoal = 2
+    }
     // Enhanced reasoning defaults
     if gai.Dialogue.ReasoningDepth == "" {
         gai.Dialogue.ReasoningDepth = "conservative"
@@ -352,6 +934,156 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if !gai.Dialogue.DynamicActionPlanning {
         gai.Dialogue.DynamicActionPlanning = true
     }
+    if gai.Dialogue.DedupThreshold == 0 {
+        gai.Dialogue.DedupThreshold = 0.93
+    }
+    if gai.Dialogue.PrincipleTrials == 0 {
+        gai.Dialogue.PrincipleTrials = 3
+    }
+    if gai.Dialogue.PrincipleTrialMargin == 0 {
+        gai.Dialogue.PrincipleTrialMargin = 0.05
+    }
+    if gai.Dialogue.DigestFrequency == "" {
+        gai.Dialogue.DigestFrequency = "daily"
+    }
+    if gai.Dialogue.InjectionDetection.ConfidencePenalty == 0 {
+        gai.Dialogue.InjectionDetection.ConfidencePenalty = 0.4
+    }
+    if gai.Dialogue.ActionTimeMarginSeconds == 0 {
+        gai.Dialogue.ActionTimeMarginSeconds = 30
+    }
+    if gai.Dialogue.MinActionTimeSeconds == 0 {
+        gai.Dialogue.MinActionTimeSeconds = 60
+    }
+    if gai.Dialogue.DeadlineEscalation.WindowHours == 0 {
+        gai.Dialogue.DeadlineEscalation.WindowHours = 72
+    }
+    if gai.Dialogue.DeadlineEscalation.MaxBoost == 0 {
+        gai.Dialogue.DeadlineEscalation.MaxBoost = 40
+    }
+    if gai.Dialogue.DeadlineEscalation.CurveExponent == 0 {
+        gai.Dialogue.DeadlineEscalation.CurveExponent = 2.0
+    }
+    // Weights default together so a single weight can be set to 0 to drop that signal
+    if gai.Dialogue.GoalDedup.StringWeight == 0 && gai.Dialogue.GoalDedup.KeywordWeight == 0 && gai.Dialogue.GoalDedup.EmbeddingWeight == 0 {
+        gai.Dialogue.GoalDedup.StringWeight = 0.05
+        gai.Dialogue.GoalDedup.KeywordWeight = 0.05
+        gai.Dialogue.GoalDedup.EmbeddingWeight = 0.90
+    }
+    if gai.Dialogue.GoalDedup.Threshold == 0 {
+        gai.Dialogue.GoalDedup.Threshold = 0.62
+    }
+    if gai.Dialogue.GoalProposals.DefaultPriority == 0 {
+        gai.Dialogue.GoalProposals.DefaultPriority = 5
+    }
+    if gai.Dialogue.GoalProposals.MinDescriptionChars == 0 {
+        gai.Dialogue.GoalProposals.MinDescriptionChars = 15
+    }
+    if gai.Dialogue.GoalProposals.FeedbackWindowCycles == 0 {
+        gai.Dialogue.GoalProposals.FeedbackWindowCycles = 20
+    }
+    if gai.Dialogue.GoalProposals.MinAcceptanceRate == 0 {
+        gai.Dialogue.GoalProposals.MinAcceptanceRate = 0.5
+    }
+    if gai.Dialogue.Adaptive.SearchThreshold == 0 {
+        gai.Dialogue.Adaptive.SearchThreshold = 0.30
+    }
+    if gai.Dialogue.Adaptive.GoalSimilarity == 0 {
+        gai.Dialogue.Adaptive.GoalSimilarity = 0.75
+    }
+    if gai.Dialogue.Adaptive.ToolTimeoutSeconds == 0 {
+        gai.Dialogue.Adaptive.ToolTimeoutSeconds = 60
+    }
+    if gai.Dialogue.Adaptive.Bounds.SearchMin == 0 {
+        gai.Dialogue.Adaptive.Bounds.SearchMin = 0.05
+    }
+    if gai.Dialogue.Adaptive.Bounds.SearchMax == 0 {
+        gai.Dialogue.Adaptive.Bounds.SearchMax = 0.95
+    }
+    if gai.Dialogue.Adaptive.Bounds.CollectiveMax == 0 {
+        gai.Dialogue.Adaptive.Bounds.CollectiveMax = 0.20
+    }
+    if gai.Dialogue.Adaptive.Bounds.GoalSimilarityMin == 0 {
+        gai.Dialogue.Adaptive.Bounds.GoalSimilarityMin = 0.75
+    }
+    if gai.Dialogue.Adaptive.Bounds.GoalSimilarityMax == 0 {
+        gai.Dialogue.Adaptive.Bounds.GoalSimilarityMax = 0.99
+    }
+    if gai.Dialogue.CompletedGoals.Retain == 0 {
+        gai.Dialogue.CompletedGoals.Retain = 100
+    }
+    if gai.Dialogue.StateVersions == 0 {
+        gai.Dialogue.StateVersions = 5
+    }
+    if gai.Dialogue.ResultStoreThresholdBytes == 0 {
+        gai.Dialogue.ResultStoreThresholdBytes = 2048
+    }
+    if gai.Dialogue.PromptsDir == "" {
+        gai.Dialogue.PromptsDir = "prompts"
+    }
+    if gai.Dialogue.CycleLock.LeaseSeconds == 0 {
+        gai.Dialogue.CycleLock.LeaseSeconds = 60
+    }
+    if gai.Dialogue.Interests.HalfLifeDays == 0 {
+        gai.Dialogue.Interests.HalfLifeDays = 14
+    }
+    if gai.Dialogue.Interests.SuppressionCooldownDays == 0 {
+        gai.Dialogue.Interests.SuppressionCooldownDays = 30
+    }
+    if gai.Dialogue.MemoryReuse.MinSimilarity == 0 {
+        gai.Dialogue.MemoryReuse.MinSimilarity = 0.85
+    }
+    if gai.Dialogue.MemoryReuse.MaxAgeDays == 0 {
+        gai.Dialogue.MemoryReuse.MaxAgeDays = 30
+    }
+    if gai.Dialogue.SearchLedger.WindowHours == 0 {
+        gai.Dialogue.SearchLedger.WindowHours = 24
+    }
+    if gai.Dialogue.SearchLedger.MinOverlap == 0 {
+        gai.Dialogue.SearchLedger.MinOverlap = 0.8
+    }
+    if gai.Dialogue.SearchLedger.MinParseQuality == 0 {
+        gai.Dialogue.SearchLedger.MinParseQuality = 0.6
+    }
+    if gai.Dialogue.Transcripts.KeepCycles == 0 {
+        gai.Dialogue.Transcripts.KeepCycles = 200
+    }
+    if gai.Dialogue.FocusAreas.ExpiryCycles == 0 {
+        gai.Dialogue.FocusAreas.ExpiryCycles = 5
+    }
+    if gai.Dialogue.FocusAreas.PriorityBonus == 0 {
+        gai.Dialogue.FocusAreas.PriorityBonus = 10
+    }
+    if gai.Dialogue.FocusAreas.MinSimilarity == 0 {
+        gai.Dialogue.FocusAreas.MinSimilarity = 0.6
+    }
+    if gai.Dialogue.Streaming.MinSynthesisChars == 0 {
+        gai.Dialogue.Streaming.MinSynthesisChars = 600
+    }
+    if gai.Dialogue.SynthesisGate.MinScore == 0 {
+        gai.Dialogue.SynthesisGate.MinScore = 0.6
+    }
+    if gai.Dialogue.SynthesisGate.OnFail == "" {
+        gai.Dialogue.SynthesisGate.OnFail = "discount"
+    }
+    if gai.Dialogue.StatusIntent.MaxTokens == 0 {
+        gai.Dialogue.StatusIntent.MaxTokens = 300
+    }
+    if gai.Dialogue.LoadShedding.MaxQueueDepth == 0 {
+        gai.Dialogue.LoadShedding.MaxQueueDepth = 8
+    }
+    if gai.Dialogue.LoadShedding.MaxWaitSeconds == 0 {
+        gai.Dialogue.LoadShedding.MaxWaitSeconds = 120
+    }
+    if gai.Dialogue.Backpressure.ActionsPerCycle == 0 {
+        gai.Dialogue.Backpressure.ActionsPerCycle = 1
+    }
+    if gai.Dialogue.Backpressure.BacklogMultiple == 0 {
+        gai.Dialogue.Backpressure.BacklogMultiple = 2
+    }
+    if gai.Dialogue.UserQuestions.TimeoutHours == 0 {
+        gai.Dialogue.UserQuestions.TimeoutHours = 24
+    }
 
     // Tools defaults (Phase 3.2)
     if gai.Tools.SearXNG.URL == "" {
@@ -369,6 +1101,21 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if gai.Tools.SearXNG.MaxResultsIdle == 0 {
         gai.Tools.SearXNG.MaxResultsIdle = 20
     }
+    if gai.Tools.SearXNG.QueryTimeout == 0 {
+        gai.Tools.SearXNG.QueryTimeout = 30
+    }
+    if gai.Tools.SearXNG.UnhealthyAfter == 0 {
+        gai.Tools.SearXNG.UnhealthyAfter = 3
+    }
+    if gai.Tools.SearXNG.ReprobeInterval == 0 {
+        gai.Tools.SearXNG.ReprobeInterval = 60
+    }
+    if gai.Tools.SearXNG.Cache.TTLHours == 0 {
+        gai.Tools.SearXNG.Cache.TTLHours = 6
+    }
+    if gai.Tools.SearXNG.Cache.MaxEntries == 0 {
+        gai.Tools.SearXNG.Cache.MaxEntries = 500
+    }
     // SafeSearch defaults to false (zero value)
 
     // WebParse defaults (Phase 3.4)
@@ -381,8 +1128,32 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if gai.Tools.WebParse.UserAgent == "" {
         gai.Tools.WebParse.UserAgent = "GrowerAI/1.0"
     }
-    if gai.Tools.WebParse.ChunkSize == 0 {
-        gai.Tools.WebParse.ChunkSize = 4000
+    if gai.Tools.WebParse.ChunkTokens == 0 {
+        gai.Tools.WebParse.ChunkTokens = 500
+    }
+    if gai.Tools.WebParse.ChunkOverlapTokens == 0 {
+        gai.Tools.WebParse.ChunkOverlapTokens = 50
+    }
+    if gai.Tools.WebParse.ExtractionMode == "" {
+        gai.Tools.WebParse.ExtractionMode = "selective"
+    }
+    if gai.Tools.WebParse.Language.Target == "" {
+        gai.Tools.WebParse.Language.Target = "en"
+    }
+    if gai.Tools.WebParse.Language.Policy == "" {
+        gai.Tools.WebParse.Language.Policy = "keep"
+    }
+    if gai.Tools.WebParse.HTTPCache.MaxSizeMB == 0 {
+        gai.Tools.WebParse.HTTPCache.MaxSizeMB = 64
+    }
+    if gai.Tools.WebParse.HTTPCache.ReuseMinutes == 0 {
+        gai.Tools.WebParse.HTTPCache.ReuseMinutes = 10
+    }
+    if gai.Tools.WebParse.HTTPCache.TTLHours == 0 {
+        gai.Tools.WebParse.HTTPCache.TTLHours = 24
+    }
+    if gai.Tools.WebParse.DomainPolicy.Mode == "" {
+        gai.Tools.WebParse.DomainPolicy.Mode = "off"
     }
 
     // Sandbox defaults (Phase 3.5)
@@ -410,6 +1181,12 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if gai.Tools.Sandbox.LogLevel == "" {
         gai.Tools.Sandbox.LogLevel = "info"
     }
+    if gai.Tools.OutputLimits.MaxBytes == 0 {
+        gai.Tools.OutputLimits.MaxBytes = 64 * 1024
+    }
+    if gai.Tools.OutputLimits.HardMaxBytes == 0 {
+        gai.Tools.OutputLimits.HardMaxBytes = 4 * 1024 * 1024
+    }
 
     // Retrieval defaults
     if gai.Retrieval.MaxMemories == 0 {
@@ -421,11 +1198,23 @@ func applyGrowerAIDefaults(gai *GrowerAIConfig) {
     if gai.Retrieval.MaxLinkedMemories == 0 {
         gai.Retrieval.MaxLinkedMemories = 5
     }
+    if gai.Retrieval.AccessTracking.Mode == "" {
+        gai.Retrieval.AccessTracking.Mode = "every_hit"
+    }
+    if gai.Retrieval.AccessTracking.FlushSeconds == 0 {
+        gai.Retrieval.AccessTracking.FlushSeconds = 10
+    }
 
     // Tagging defaults
     if gai.Tagging.BatchSize == 0 {
         gai.Tagging.BatchSize = 100
     }
+    if gai.Tagging.Retag.BatchSize == 0 {
+        gai.Tagging.Retag.BatchSize = 50
+    }
+    if gai.Tagging.Retag.PerMinute == 0 {
+        gai.Tagging.Retag.PerMinute = 30
+    }
 
     // Storage limits defaults
     if gai.StorageLimits.MaxTotalMemories == 0 {
@@ -477,7 +1266,7 @@ func (c *Config) StartModelRefresher(interval time.Duration) {
         for range ticker.C {
             log.Println("[Config] Running scheduled model refresh...")
             if err := discoverModels(c); err != nil {
-                log.Printf("[Config] Scheduled refresh failed: %v", err)
+                log.Printf("[Config] Scheduled refresh found model mismatches, keeping the previous names: %v", err)
             } else {
                 log.Println("[Config] Scheduled model refresh successful.")
             }
@@ -485,63 +1274,73 @@ func (c *Config) StartModelRefresher(interval time.Duration) {
     }()
 }
 
-// discoverModels updates the config with live data from /v1/models endpoints
+// discoverModels updates the config with live data from /v1/models endpoints. The llms
+// list takes the first model each server lists; a growerai role must name a served
+// model, and a mismatch is returned and leaves the role unchanged unless
+// growerai.allow_model_fallback substitutes the closest one.
 func discoverModels(c *Config) error {
     c.modelsMu.Lock()
     defer c.modelsMu.Unlock()
 
-    // Helper to update a single model entry
-    updateEntry := func(url *string, name *string, ctx *int) error {
-        if url == nil || *url == "" {
-            return nil
-        }
-        dName, dCtx, err := fetchModelInfo(*url)
-        if err != nil {
-            return fmt.Errorf("failed to fetch info for %s: %w", *url, err)
-        }
-
-        // Update Name if discovered
-        if dName != "" {
-            *name = dName
-        }
-        // Update Context if discovered and valid, otherwise fallback to 4096
-        // Check if ctx is not nil (some models like embeddings don't need context)
-        if ctx != nil {
-            if dCtx > 0 {
-                *ctx = dCtx
-            } else if *ctx == 0 {
-                log.Printf("[Config] Context size not returned by API for %s, defaulting to 4096", *url)
-                *ctx = 4096
-            }
+    // Update Context if discovered and valid, otherwise fallback to 4096
+    setContext := func(ctx *int, discovered int, url string) {
+        if discovered > 0 {
+            *ctx = discovered
+        } else if *ctx == 0 {
+            log.Printf("[Config] Context size not returned by API for %s, defaulting to 4096", url)
+            *ctx = 4096
         }
-        return nil
     }
 
     // 1. Update main LLMs list
     for i := range c.LLMs {
-        if err := updateEntry(&c.LLMs[i].URL, &c.LLMs[i].Name, &c.LLMs[i].ContextSize); err != nil {
-            log.Printf("[Config] Error updating LLM[%d]: %v", i, err)
-        } else {
-            log.Printf("[Config] Updated LLM[%d]: Name=%s, Context=%d", i, c.LLMs[i].Name, c.LLMs[i].ContextSize)
+        entry := &c.LLMs[i]
+        if entry.URL == "" {
+            continue
+        }
+        model, _, err := resolveModel(fmt.Sprintf("llms[%d]", i), entry.URL, "", false)
+        if err != nil {
+            log.Printf("[Config] Error updating LLM[%d]: failed to fetch info for %s: %v", i, entry.URL, err)
+            continue
+        }
+        entry.Name = model.ID
+        setContext(&entry.ContextSize, model.ContextSize, entry.URL)
+        log.Printf("[Config] Updated LLM[%d]: Name=%s, Context=%d", i, entry.Name, entry.ContextSize)
+    }
+
+    // 2. Check each GrowerAI role's model against its server (hosted roles keep their config)
+    var errs []error
+    resolved := make(map[string]string)
+    for _, r := range c.modelRoles() {
+        resolved[r.role] = *r.name
+        if r.hosted || r.url == "" {
+            continue
+        }
+        model, substituted, err := resolveModel(r.role, r.url, *r.name, c.GrowerAI.AllowModelFallback)
+        var mismatch *ModelMismatchError
+        switch {
+        case errors.As(err, &mismatch):
+            errs = append(errs, err)
+            continue
+        case errors.Is(err, errModelListUnsupported):
+            log.Printf("[Config] Warning: %s server %s does not list its models, skipping the model name check", r.role, r.url)
+            continue
+        case err != nil:
+            log.Printf("[Config] Error updating %s: failed to fetch info for %s: %v", r.role, r.url, err)
+            continue
+        case substituted:
+            log.Printf("[Config] WARNING: MODEL SUBSTITUTED for %s: %q is not served at %s, using the closest match %q (growerai.allow_model_fallback)",
+                r.role, *r.name, r.url, model.ID)
+        }
+        *r.name = model.ID
+        resolved[r.role] = model.ID
+        if r.ctx != nil {
+            setContext(r.ctx, model.ContextSize, r.url)
         }
     }
+    c.resolvedModels = resolved
 
-    // 2. Update GrowerAI Reasoning Model
-    if err := updateEntry(&c.GrowerAI.ReasoningModel.URL, &c.GrowerAI.ReasoningModel.Name, &c.GrowerAI.ReasoningModel.ContextSize); err != nil {
-        log.Printf("[Config] Error updating Reasoning Model: %v", err)
-    }
-
-    // 3. Update GrowerAI Embedding Model
-    if err := updateEntry(&c.GrowerAI.EmbeddingModel.URL, &c.GrowerAI.EmbeddingModel.Name, nil); err != nil {
-        log.Printf("[Config] Error updating Embedding Model: %v", err)
-    }
-
-    // 4. Update GrowerAI Simple Model
-    if err := updateEntry(&c.GrowerAI.SimpleModel.URL, &c.GrowerAI.SimpleModel.Name, &c.GrowerAI.SimpleModel.ContextSize); err != nil {
-        log.Printf("[Config] Error updating Simple Model: %v", err)
-    }
-
-    return nil
+    return errors.Join(errs...)
 }
 
 // LlamaCppProps represents the structure of the /props endpoint (llama.cpp)
@@ -555,109 +1354,6 @@ type LlamaCppProps struct {
     } `json:"default_generation_settings"`
 }
 
-// fetchModelInfo queries the OpenAI /v1/models endpoint
-func fetchModelInfo(endpointURL string) (string, int, error) {
-    // 1. Derive Base URL
-    baseURL := endpointURL
-    // Remove known suffixes to get the base URL
-    suffixes := []string{"/v1/chat/completions", "/v1/embeddings", "/v1/completions"}
-    for _, suffix := range suffixes {
-        if strings.HasSuffix(baseURL, suffix) {
-            baseURL = strings.TrimSuffix(baseURL, suffix)
-            break
-        }
-    }
-
-    // Ensure no double slash if joining
-    if strings.HasSuffix(baseURL, "/") {
-        baseURL = baseURL[:len(baseURL)-1]
-    }
-
-    targetURL := baseURL + "/v1/models"
-
-    // 2. Create Request
-    req, err := http.NewRequest("GET", targetURL, nil)
-    if err != nil {
-        return "", 0, err
-    }
-
-    // Set timeout context
-    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-    defer cancel()
-    req = req.WithContext(ctx)
-
-    // 3. Execute Request
-    client := &http.Client{}
-    resp, err := client.Do(req)
-    if err != nil {
-        return "", 0, err
-    }
-    defer resp.Body.Close()
-
-    if resp.StatusCode != http.StatusOK {
-        return "", 0, fmt.Errorf("received status %d", resp.StatusCode)
-    }
-
-    // 4. Parse Response
-    body, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return "", 0, err
-    }
-
-    var data struct {
-        Object string `json:"object"`
-        Data   []struct {
-            ID      string `json:"id"`
-            Details struct {
-                // Common context limit fields in local providers (Ollama, vLLM, etc.)
-                ContextLength    int `json:"context_length"`
-                MaxModelLen      int `json:"max_model_len"`
-                MaxTokens        int `json:"max_tokens"`
-                TotalContextLen  int `json:"total_context_len"` // Some custom implementations
-            } `json:"details,omitempty"`
-            // Some providers put it at root level of model object
-            ContextLength int `json:"context_length,omitempty"`
-        } `json:"data"`
-    }
-
-    if err := json.Unmarshal(body, &data); err != nil {
-        return "", 0, err
-    }
-
-    if len(data.Data) == 0 {
-        return "", 0, fmt.Errorf("no models found in response")
-    }
-
-    // Assume the first model returned is the one we are querying if the endpoint is specific
-    // Or if the list is just one model behind that URL.
-    model := data.Data[0]
-    name := model.ID
-    contextSize := 0
-
-    // Try to find context limit
-    if model.Details.ContextLength > 0 {
-        contextSize = model.Details.ContextLength
-    } else if model.Details.MaxModelLen > 0 {
-        contextSize = model.Details.MaxModelLen
-    } else if model.Details.MaxTokens > 0 {
-        contextSize = model.Details.MaxTokens
-    } else if model.Details.TotalContextLen > 0 {
-        contextSize = model.Details.TotalContextLen
-    } else if model.ContextLength > 0 {
-        contextSize = model.ContextLength
-    }
-
-    // Fallback: Try /props endpoint if context is still 0
-    if contextSize == 0 {
-        log.Printf("[Config] Context size not found in /v1/models, attempting /props...")
-        if ctxFromProps := fetchContextFromProps(baseURL); ctxFromProps > 0 {
-            contextSize = ctxFromProps
-        }
-    }
-
-    return name, contextSize, nil
-}
-
 // GetChatURL ensures the URL ends with /v1/chat/completions
 func GetChatURL(baseURL string) string {
     return ensureSuffix(baseURL, "/v1/chat/completions")
@@ -683,7 +1379,7 @@ func fetchContextFromProps(baseURL string) int {
     defer cancel()
     req = req.WithContext(ctx)
 
-    client := &http.Client{}
+    client := httpclient.New(0)
     resp, err := client.Do(req)
     if err != nil {
         log.Printf("[Config] Failed to fetch /props from %s: %v", baseURL, err)
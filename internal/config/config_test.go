@@ -90,3 +90,95 @@ func TestLoadConfig_InvalidJSON(t *testing.T) {
 // 	// If your loader validates required fields, this should fail.
 // 	// If not, you can remove or adjust this test.
 // }
+
+func TestParseConfig_SamplingValidated(t *testing.T) {
+	c, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"},
+		"growerai": {"sampling": {"evaluation": {"temperature": 0, "top_p": 0.9}, "summarize": {"max_tokens": 512}}}}`))
+	if err != nil {
+		t.Fatalf("expected valid sampling to load: %v", err)
+	}
+	if eval := c.GrowerAI.Sampling["evaluation"]; eval.Temperature == nil || *eval.Temperature != 0 || eval.TopP != 0.9 {
+		t.Errorf("expected an explicit zero temperature to be kept, got %+v", eval)
+	}
+
+	for _, raw := range []string{
+		`{"evaluation": {"temperature": 2.5}}`,
+		`{"evaluation": {"top_p": 1.5}}`,
+		`{"synthesis": {"max_tokens": -1}}`,
+		`{"reflection": {"repeat_penalty": -0.1}}`,
+		`{"plan": {"temperature": 0.2}}`,
+	} {
+		if _, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"}, "growerai": {"sampling": ` + raw + `}}`)); err == nil {
+			t.Errorf("expected sampling %s to be rejected", raw)
+		}
+	}
+}
+
+func TestParseConfig_HostedModelProviders(t *testing.T) {
+	t.Setenv("TEST_ANTHROPIC_KEY", "sk-ant")
+	c, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"}, "growerai": {
+		"reasoning_model": {"url": "http://local:11434", "name": "local"},
+		"simple_model": {"name": "gpt-4o-mini", "provider": "openai", "api_key": "sk", "organization": "org-1"},
+		"compression_model": {"name": "claude-haiku-4-5", "provider": "anthropic", "api_key_env": "TEST_ANTHROPIC_KEY"}}}`))
+	if err != nil {
+		t.Fatalf("expected hosted models to load: %v", err)
+	}
+	if c.GrowerAI.ReasoningModel.Hosted() {
+		t.Error("expected a model without a provider to be local")
+	}
+	simple := c.GrowerAI.SimpleModel
+	if !simple.Hosted() || simple.URL != "https://api.openai.com" || simple.ContextSize == 0 || simple.MaxOutputTokens != 4096 {
+		t.Errorf("expected hosted defaults for the simple model, got %+v", simple)
+	}
+	if compression := c.GrowerAI.CompressionModel; compression.Key() != "sk-ant" || compression.URL != "https://api.anthropic.com" {
+		t.Errorf("expected the compression key read from the environment, got %+v", compression)
+	}
+
+	local, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"}, "growerai": {"reasoning_model": {"url": "http://local:11434"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.GrowerAI.CompressionModel != local.GrowerAI.ReasoningModel {
+		t.Errorf("expected the compression model to default to the reasoning model, got %+v", local.GrowerAI.CompressionModel)
+	}
+
+	for _, raw := range []string{
+		`{"simple_model": {"name": "gpt-4o", "provider": "openai"}}`,
+		`{"simple_model": {"provider": "anthropic", "api_key": "k"}}`,
+		`{"reasoning_model": {"name": "m", "provider": "gemini", "api_key": "k"}}`,
+	} {
+		if _, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"}, "growerai": ` + raw + `}`)); err == nil {
+			t.Errorf("expected %s to be rejected", raw)
+		}
+	}
+}
+
+func TestParseConfig_BudgetValidated(t *testing.T) {
+	if _, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"},
+		"growerai": {"budget": {"enabled": true, "daily_soft_tokens": 100, "daily_hard_tokens": 200, "monthly_hard_tokens": 5000}}}`)); err != nil {
+		t.Fatalf("expected valid limits to load: %v", err)
+	}
+	for _, raw := range []string{
+		`{"daily_soft_tokens": 300, "daily_hard_tokens": 200}`,
+		`{"monthly_hard_tokens": -1}`,
+	} {
+		if _, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"}, "growerai": {"budget": ` + raw + `}}`)); err == nil {
+			t.Errorf("expected budget %s to be rejected", raw)
+		}
+	}
+}
+
+func TestParseConfig_RetrievalSourceKinds(t *testing.T) {
+	c, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"},
+		"growerai": {"retrieval": {"source_kinds": ["user_conversation", "digest"]}}}`))
+	if err != nil {
+		t.Fatalf("expected known source kinds to load: %v", err)
+	}
+	if len(c.GrowerAI.Retrieval.SourceKinds) != 2 {
+		t.Errorf("expected two source kinds, got %v", c.GrowerAI.Retrieval.SourceKinds)
+	}
+	if _, err := parseConfig([]byte(`{"server": {"jwtSecret": "s"},
+		"growerai": {"retrieval": {"source_kinds": ["web"]}}}`)); err == nil {
+		t.Error("expected an unknown source kind to be rejected")
+	}
+}
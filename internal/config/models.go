@@ -0,0 +1,254 @@
+// internal/config/models.go
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go-llama/internal/httpclient"
+)
+
+// errModelListUnsupported marks a backend without a /v1/models endpoint
+var errModelListUnsupported = errors.New("backend does not implement /v1/models")
+
+// ModelMismatchError reports a configured model name its server does not serve
+type ModelMismatchError struct {
+	Role       string
+	Configured string
+	URL        string
+	Available  []string
+}
+
+func (e *ModelMismatchError) Error() string {
+	return fmt.Sprintf("%s: model %q is not served at %s (available: %s); fix the name or set growerai.allow_model_fallback to use the closest one",
+		e.Role, e.Configured, e.URL, strings.Join(e.Available, ", "))
+}
+
+// servedModel is one entry of a backend's /v1/models list
+type servedModel struct {
+	ID          string
+	ContextSize int // 0 when the backend does not report it
+}
+
+// modelRole is a growerai model role checked against the models its server lists
+type modelRole struct {
+	role   string
+	url    string
+	name   *string
+	ctx    *int // nil for roles without a context window
+	hosted bool // Hosted APIs are not checked
+}
+
+// modelRoles lists the growerai model roles
+func (c *Config) modelRoles() []modelRole {
+	var roles []modelRole
+	add := func(role string, m *ModelConfig) {
+		roles = append(roles, modelRole{role: role, url: m.URL, name: &m.Name, ctx: &m.ContextSize, hosted: m.Hosted()})
+	}
+	add("reasoning_model", &c.GrowerAI.ReasoningModel)
+	add("simple_model", &c.GrowerAI.SimpleModel)
+	add("compression_model", &c.GrowerAI.CompressionModel)
+	roles = append(roles, modelRole{role: "embedding_model", url: c.GrowerAI.EmbeddingModel.URL, name: &c.GrowerAI.EmbeddingModel.Name})
+	return roles
+}
+
+// ResolvedModels returns the model name each growerai role resolved to at the last
+// discovery, keyed by role
+func (c *Config) ResolvedModels() map[string]string {
+	c.modelsMu.RLock()
+	defer c.modelsMu.RUnlock()
+	resolved := make(map[string]string, len(c.resolvedModels))
+	for role, name := range c.resolvedModels {
+		resolved[role] = name
+	}
+	return resolved
+}
+
+// VerifyModels checks next's growerai model names against what their servers list,
+// without changing next. Backends that are unreachable or have no /v1/models pass.
+func VerifyModels(next *Config) error {
+	var errs []error
+	for _, r := range next.modelRoles() {
+		if r.hosted || r.url == "" {
+			continue
+		}
+		if _, _, err := resolveModel(r.role, r.url, *r.name, next.GrowerAI.AllowModelFallback); err != nil {
+			var mismatch *ModelMismatchError
+			if errors.As(err, &mismatch) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveModel picks the served model for a role. An empty name takes the first model
+// served; otherwise the name must match one, unless allowFallback substitutes the
+// closest. substituted reports a fallback.
+func resolveModel(role, endpointURL, configured string, allowFallback bool) (model servedModel, substituted bool, err error) {
+	baseURL, models, err := listModels(endpointURL)
+	if err != nil {
+		return servedModel{}, false, err
+	}
+	if len(models) == 0 {
+		return servedModel{}, false, fmt.Errorf("no models found in response")
+	}
+
+	switch match, ok := matchModel(configured, models); {
+	case configured == "":
+		model = models[0]
+	case ok:
+		model = match
+	case allowFallback:
+		model, substituted = closestModel(configured, models), true
+	default:
+		available := make([]string, len(models))
+		for i, m := range models {
+			available[i] = m.ID
+		}
+		return servedModel{}, false, &ModelMismatchError{Role: role, Configured: configured, URL: endpointURL, Available: available}
+	}
+
+	if model.ContextSize == 0 {
+		log.Printf("[Config] Context size not found in /v1/models, attempting /props...")
+		model.ContextSize = fetchContextFromProps(baseURL)
+	}
+	return model, substituted, nil
+}
+
+// matchModel finds configured among models, exactly or else ignoring case, directories
+// and a .gguf extension, since llama.cpp lists the model file path
+func matchModel(configured string, models []servedModel) (servedModel, bool) {
+	for _, m := range models {
+		if m.ID == configured {
+			return m, true
+		}
+	}
+	want := normalizeModelName(configured)
+	for _, m := range models {
+		if normalizeModelName(m.ID) == want {
+			return m, true
+		}
+	}
+	return servedModel{}, false
+}
+
+// closestModel returns the model whose normalized name is the fewest edits from
+// configured's, preferring earlier models on ties
+func closestModel(configured string, models []servedModel) servedModel {
+	want := normalizeModelName(configured)
+	best, bestDistance := models[0], -1
+	for _, m := range models {
+		d := editDistance(want, normalizeModelName(m.ID))
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = m, d
+		}
+	}
+	return best
+}
+
+func normalizeModelName(name string) string {
+	name = strings.ToLower(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	return strings.TrimSuffix(name, ".gguf")
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur := make([]int, len(br)+1)
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(br)]
+}
+
+// listModels queries the OpenAI /v1/models endpoint behind endpointURL and returns the
+// server's base URL with every model it lists
+func listModels(endpointURL string) (string, []servedModel, error) {
+	// Remove known suffixes to get the base URL
+	baseURL := endpointURL
+	for _, suffix := range []string{"/v1/chat/completions", "/v1/embeddings", "/v1/completions"} {
+		if strings.HasSuffix(baseURL, suffix) {
+			baseURL = strings.TrimSuffix(baseURL, suffix)
+			break
+		}
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v1/models", nil)
+	if err != nil {
+		return baseURL, nil, err
+	}
+
+	resp, err := httpclient.New(0).Do(req)
+	if err != nil {
+		return baseURL, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return baseURL, nil, errModelListUnsupported
+	default:
+		return baseURL, nil, fmt.Errorf("received status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return baseURL, nil, err
+	}
+
+	var data struct {
+		Data []struct {
+			ID      string `json:"id"`
+			Details struct {
+				// Common context limit fields in local providers (Ollama, vLLM, etc.)
+				ContextLength   int `json:"context_length"`
+				MaxModelLen     int `json:"max_model_len"`
+				MaxTokens       int `json:"max_tokens"`
+				TotalContextLen int `json:"total_context_len"` // Some custom implementations
+			} `json:"details,omitempty"`
+			// Some providers put it at root level of model object
+			ContextLength int `json:"context_length,omitempty"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return baseURL, nil, err
+	}
+
+	models := make([]servedModel, 0, len(data.Data))
+	for _, m := range data.Data {
+		contextSize := m.ContextLength
+		for _, size := range []int{m.Details.ContextLength, m.Details.MaxModelLen, m.Details.MaxTokens, m.Details.TotalContextLen} {
+			if size > 0 {
+				contextSize = size
+				break
+			}
+		}
+		models = append(models, servedModel{ID: m.ID, ContextSize: contextSize})
+	}
+	return baseURL, models, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// modelServer serves a /v1/models list of ids, or 404 when ids is nil
+func modelServer(t *testing.T, ids ...string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || ids == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var entries []string
+		for _, id := range ids {
+			entries = append(entries, `{"id":"`+id+`","context_length":8192}`)
+		}
+		w.Write([]byte(`{"object":"list","data":[` + strings.Join(entries, ",") + `]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiscoverModels_MatchesServedNames(t *testing.T) {
+	server := modelServer(t, "/models/Qwen2.5-7B-Instruct-Q4_K_M.gguf", "llama-3")
+	c := &Config{}
+	c.GrowerAI.ReasoningModel = ModelConfig{Name: "qwen2.5-7b-instruct-q4_k_m", URL: server.URL}
+	c.GrowerAI.SimpleModel = ModelConfig{URL: server.URL + "/v1/chat/completions"}
+
+	if err := discoverModels(c); err != nil {
+		t.Fatalf("expected the names to match, got %v", err)
+	}
+	if got := c.GrowerAI.ReasoningModel; got.Name != "/models/Qwen2.5-7B-Instruct-Q4_K_M.gguf" || got.ContextSize != 8192 {
+		t.Errorf("expected the served name and context, got %+v", got)
+	}
+	if got := c.ResolvedModels()["simple_model"]; got != "/models/Qwen2.5-7B-Instruct-Q4_K_M.gguf" {
+		t.Errorf("expected an unnamed role to take the first served model, got %q", got)
+	}
+}
+
+func TestDiscoverModels_MismatchListsAvailableModels(t *testing.T) {
+	server := modelServer(t, "llama-3", "phi-3")
+	c := &Config{}
+	c.GrowerAI.ReasoningModel = ModelConfig{Name: "mistral-7b", URL: server.URL}
+
+	err := discoverModels(c)
+	var mismatch *ModelMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Role != "reasoning_model" {
+		t.Fatalf("expected a mismatch for the reasoning model, got %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "llama-3, phi-3") || !strings.Contains(msg, "allow_model_fallback") {
+		t.Errorf("expected the available models and the fallback flag in %q", msg)
+	}
+	if c.GrowerAI.ReasoningModel.Name != "mistral-7b" {
+		t.Errorf("expected a mismatched role left unchanged, got %q", c.GrowerAI.ReasoningModel.Name)
+	}
+	if err := VerifyModels(c); !errors.As(err, &mismatch) {
+		t.Errorf("expected a reload with the same names rejected, got %v", err)
+	}
+}
+
+func TestDiscoverModels_FallbackUsesClosestModel(t *testing.T) {
+	server := modelServer(t, "phi-3", "llama-3-8b-instruct")
+	c := &Config{}
+	c.GrowerAI.AllowModelFallback = true
+	c.GrowerAI.ReasoningModel = ModelConfig{Name: "llama-3.1-8b-instruct", URL: server.URL}
+
+	if err := VerifyModels(c); err != nil || c.GrowerAI.ReasoningModel.Name != "llama-3.1-8b-instruct" {
+		t.Fatalf("expected verification to pass without changing the config, got %v (%q)", err, c.GrowerAI.ReasoningModel.Name)
+	}
+	if err := discoverModels(c); err != nil {
+		t.Fatalf("expected the fallback to be allowed, got %v", err)
+	}
+	if got := c.ResolvedModels()["reasoning_model"]; got != "llama-3-8b-instruct" {
+		t.Errorf("expected the closest model substituted, got %q", got)
+	}
+}
+
+func TestDiscoverModels_SkipsBackendsWithoutModelList(t *testing.T) {
+	server := modelServer(t)
+	c := &Config{}
+	c.GrowerAI.ReasoningModel = ModelConfig{Name: "custom", URL: server.URL}
+
+	if err := discoverModels(c); err != nil {
+		t.Fatalf("expected the check skipped, got %v", err)
+	}
+	if got := c.ResolvedModels()["reasoning_model"]; got != "custom" {
+		t.Errorf("expected the configured name kept, got %q", got)
+	}
+	if err := VerifyModels(c); err != nil {
+		t.Errorf("expected verification skipped, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ReloadHook lets a component take new settings without a restart. Check must reject
+// anything Apply cannot take, so a reload reaches every hook or none of them.
+type ReloadHook struct {
+	Name  string
+	Check func(next *Config) error // Optional
+	Apply func(next *Config)
+}
+
+// ReloadStatus describes the config file the running server has applied
+type ReloadStatus struct {
+	Path            string    `json:"path"`
+	Hash            string    `json:"hash"` // SHA-256 of the applied file
+	LoadedAt        time.Time `json:"loaded_at"`
+	Reloads         int       `json:"reloads"`
+	LastReload      time.Time `json:"last_reload,omitempty"`
+	LastError       string    `json:"last_error,omitempty"` // Most recent rejected reload
+	LastErrorAt     time.Time `json:"last_error_at,omitempty"`
+	LastErrorHash   string    `json:"last_error_hash,omitempty"`
+	RestartRequired []string  `json:"restart_required,omitempty"` // Changed settings that only apply after a restart
+	Components      []string  `json:"components"`                 // Registered reload hooks
+}
+
+// Watcher polls the config file and applies changes through registered hooks
+type Watcher struct {
+	path     string
+	interval time.Duration
+	stopChan chan struct{}
+
+	mu      sync.Mutex
+	hooks   []ReloadHook
+	startup *Config // Restart-only settings are compared against what the server started with
+	modTime time.Time
+	status  ReloadStatus
+}
+
+// NewWatcher reads the config file as the baseline later reloads are compared against.
+// A non-positive interval disables polling; Reload can still be called directly.
+func NewWatcher(path string, interval time.Duration) (*Watcher, error) {
+	raw, modTime, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	startup, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		stopChan: make(chan struct{}),
+		startup:  startup,
+		modTime:  modTime,
+		status: ReloadStatus{
+			Path:     path,
+			Hash:     hashConfig(raw),
+			LoadedAt: time.Now(),
+		},
+	}, nil
+}
+
+// Register adds a component to every future reload
+func (w *Watcher) Register(hook ReloadHook) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hooks = append(w.hooks, hook)
+}
+
+// Start begins polling the file's modification time
+func (w *Watcher) Start() {
+	if w.interval <= 0 {
+		log.Printf("[ConfigWatcher] Polling disabled, reload %s manually", w.path)
+		return
+	}
+	log.Printf("[ConfigWatcher] Watching %s (every %s)", w.path, w.interval)
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.poll()
+			case <-w.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends polling
+func (w *Watcher) Stop() {
+	close(w.stopChan)
+}
+
+// poll reloads when the file's modification time has changed
+func (w *Watcher) poll() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	changed := !info.ModTime().Equal(w.modTime)
+	w.mu.Unlock()
+	if changed {
+		w.Reload()
+	}
+}
+
+// Reload reads the file and, if its content changed and every hook accepts it, applies
+// it to all hooks. A rejected file leaves the running config untouched.
+func (w *Watcher) Reload() (ReloadStatus, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	raw, modTime, err := readConfigFile(w.path)
+	if err != nil {
+		return w.rejectLocked("", err)
+	}
+	w.modTime = modTime
+
+	hash := hashConfig(raw)
+	if hash == w.status.Hash {
+		return w.statusLocked(), nil
+	}
+
+	next, err := parseConfig(raw)
+	if err != nil {
+		return w.rejectLocked(hash, err)
+	}
+	for _, hook := range w.hooks {
+		if hook.Check == nil {
+			continue
+		}
+		if err := hook.Check(next); err != nil {
+			return w.rejectLocked(hash, fmt.Errorf("%s: %w", hook.Name, err))
+		}
+	}
+
+	for _, hook := range w.hooks {
+		hook.Apply(next)
+	}
+	restart := RestartRequiredChanges(w.startup, next)
+
+	w.status.Hash = hash
+	w.status.Reloads++
+	w.status.LastReload = time.Now()
+	w.status.RestartRequired = restart
+	log.Printf("[ConfigWatcher] Applied %s (sha256 %s) to %d components", w.path, hash[:12], len(w.hooks))
+	if len(restart) > 0 {
+		log.Printf("[ConfigWatcher] Restart required for changed settings: %v", restart)
+	}
+	return w.statusLocked(), nil
+}
+
+// Status reports the applied config and the outcome of the last reload
+func (w *Watcher) Status() ReloadStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.statusLocked()
+}
+
+func (w *Watcher) statusLocked() ReloadStatus {
+	status := w.status
+	status.RestartRequired = append([]string(nil), w.status.RestartRequired...)
+	status.Components = make([]string, 0, len(w.hooks))
+	for _, hook := range w.hooks {
+		status.Components = append(status.Components, hook.Name)
+	}
+	return status
+}
+
+func (w *Watcher) rejectLocked(hash string, err error) (ReloadStatus, error) {
+	w.status.LastError = err.Error()
+	w.status.LastErrorAt = time.Now()
+	w.status.LastErrorHash = hash
+	log.Printf("[ConfigWatcher] Rejected %s, keeping current config: %v", w.path, err)
+	return w.statusLocked(), err
+}
+
+// readConfigFile returns the file content and modification time
+func readConfigFile(path string) ([]byte, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return raw, info.ModTime(), nil
+}
+
+func hashConfig(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// restartOnly lists settings read once at startup. Fields that reload hooks apply live are
+// cleared before comparing.
+var restartOnly = []struct {
+	name string
+	get  func(c *Config) interface{}
+}{
+	{"server", func(c *Config) interface{} { return c.Server }},
+	{"postgres", func(c *Config) interface{} { return c.Postgres }},
+	{"redis", func(c *Config) interface{} { return c.Redis }},
+	{"llms", func(c *Config) interface{} { return c.LLMs }},
+	{"searxng", func(c *Config) interface{} { return c.SearxNG }},
+	{"config_reload", func(c *Config) interface{} { return c.ConfigReload }},
+	{"health", func(c *Config) interface{} { return c.Health }},
+	{"growerai.enabled", func(c *Config) interface{} { return c.GrowerAI.Enabled }},
+	{"growerai.llm_queue", func(c *Config) interface{} { return c.GrowerAI.LLMQueue }},
+	{"growerai.budget.enabled", func(c *Config) interface{} { return c.GrowerAI.Budget.Enabled }},
+	{"growerai.reasoning_model", func(c *Config) interface{} { return c.GrowerAI.ReasoningModel }},
+	{"growerai.embedding_model", func(c *Config) interface{} { return c.GrowerAI.EmbeddingModel }},
+	{"growerai.simple_model", func(c *Config) interface{} { return c.GrowerAI.SimpleModel }},
+	{"growerai.compression_model", func(c *Config) interface{} { return c.GrowerAI.CompressionModel }},
+	{"growerai.allow_model_fallback", func(c *Config) interface{} { return c.GrowerAI.AllowModelFallback }},
+	{"growerai.sampling.summarize", func(c *Config) interface{} { return c.GrowerAI.Sampling[SamplingSummarize] }},
+	{"growerai.qdrant", func(c *Config) interface{} { return c.GrowerAI.Qdrant }},
+	{"growerai.storage_limits", func(c *Config) interface{} { return c.GrowerAI.StorageLimits }},
+	{"growerai.retrieval", func(c *Config) interface{} { return c.GrowerAI.Retrieval }},
+	{"growerai.tagging", func(c *Config) interface{} { return c.GrowerAI.Tagging }},
+	{"growerai.compression", func(c *Config) interface{} { return c.GrowerAI.Compression }},
+	{"growerai.principles", func(c *Config) interface{} { return c.GrowerAI.Principles }},
+	{"growerai.personality", func(c *Config) interface{} { return c.GrowerAI.Personality }},
+	{"growerai.linking", func(c *Config) interface{} { return c.GrowerAI.Linking }},
+	{"growerai.dialogue.enabled", func(c *Config) interface{} { return c.GrowerAI.Dialogue.Enabled }},
+	{"growerai.dialogue.digest_frequency", func(c *Config) interface{} { return c.GrowerAI.Dialogue.DigestFrequency }},
+	{"growerai.dialogue.completed_goals", func(c *Config) interface{} { return c.GrowerAI.Dialogue.CompletedGoals }},
+	{"growerai.dialogue.result_store_threshold_bytes", func(c *Config) interface{} { return c.GrowerAI.Dialogue.ResultStoreThresholdBytes }},
+	{"growerai.dialogue.cycle_lock", func(c *Config) interface{} { return c.GrowerAI.Dialogue.CycleLock }},
+	{"growerai.tools.searxng", func(c *Config) interface{} {
+		s := c.GrowerAI.Tools.SearXNG
+		s.Enabled = false
+		s.TimeoutInteractive, s.TimeoutIdle = 0, 0
+		s.MaxResultsInteractive, s.MaxResultsIdle = 0, 0
+		return s
+	}},
+	{"growerai.tools.webparse", func(c *Config) interface{} {
+		p := c.GrowerAI.Tools.WebParse
+		p.DomainPolicy.Mode, p.DomainPolicy.Patterns = "", nil
+		return p
+	}},
+	{"growerai.tools.sandbox", func(c *Config) interface{} { return c.GrowerAI.Tools.Sandbox }},
+}
+
+// RestartRequiredChanges names the settings that differ between two configs but only
+// take effect after a restart. Search can be disabled live but not enabled.
+func RestartRequiredChanges(old, next *Config) []string {
+	var changed []string
+	for _, setting := range restartOnly {
+		if !reflect.DeepEqual(setting.get(old), setting.get(next)) {
+			changed = append(changed, setting.name)
+		}
+	}
+	if !old.GrowerAI.Tools.SearXNG.Enabled && next.GrowerAI.Tools.SearXNG.Enabled {
+		changed = append(changed, "growerai.tools.searxng.enabled")
+	}
+	return changed
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeReloadConfig(t *testing.T, path string, port, timeoutIdle int) {
+	t.Helper()
+	raw := fmt.Sprintf(`{
+		"server": {"host": "localhost", "port": %d, "jwtSecret": "secret"},
+		"growerai": {"tools": {"searxng": {"enabled": true, "timeout_idle": %d}}}
+	}`, port, timeoutIdle)
+	if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestWatcherReload_AppliesToAllHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeReloadConfig(t, path, 8080, 100)
+
+	w, err := NewWatcher(path, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	startHash := w.Status().Hash
+
+	var first, second int
+	w.Register(ReloadHook{Name: "first", Apply: func(c *Config) { first = c.GrowerAI.Tools.SearXNG.TimeoutIdle }})
+	w.Register(ReloadHook{Name: "second", Apply: func(c *Config) { second = c.GrowerAI.Tools.SearXNG.TimeoutIdle }})
+
+	// Unchanged file: nothing applied
+	if _, err := w.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if first != 0 || second != 0 {
+		t.Fatalf("unchanged file was applied: %d, %d", first, second)
+	}
+
+	writeReloadConfig(t, path, 8080, 200)
+	status, err := w.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if first != 200 || second != 200 {
+		t.Errorf("hooks got %d and %d, want 200", first, second)
+	}
+	if status.Hash == startHash || status.Reloads != 1 {
+		t.Errorf("status = %+v, want new hash and 1 reload", status)
+	}
+	if len(status.RestartRequired) != 0 {
+		t.Errorf("RestartRequired = %v, want none for a live setting", status.RestartRequired)
+	}
+	if len(status.Components) != 2 {
+		t.Errorf("Components = %v, want both hooks", status.Components)
+	}
+}
+
+func TestWatcherReload_RejectedCheckAppliesNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeReloadConfig(t, path, 8080, 100)
+
+	w, err := NewWatcher(path, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	startHash := w.Status().Hash
+
+	applied := false
+	w.Register(ReloadHook{Name: "ok", Apply: func(c *Config) { applied = true }})
+	w.Register(ReloadHook{
+		Name:  "strict",
+		Check: func(c *Config) error { return errors.New("timeout too long") },
+		Apply: func(c *Config) { applied = true },
+	})
+
+	writeReloadConfig(t, path, 8080, 200)
+	status, err := w.Reload()
+	if err == nil {
+		t.Fatal("expected reload to be rejected")
+	}
+	if applied {
+		t.Error("a hook was applied although another rejected the config")
+	}
+	if status.Hash != startHash || status.Reloads != 0 {
+		t.Errorf("status = %+v, want the original hash kept", status)
+	}
+	if status.LastError == "" || status.LastErrorHash == "" {
+		t.Errorf("status = %+v, want the rejection recorded", status)
+	}
+}
+
+func TestWatcherReload_InvalidFileRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeReloadConfig(t, path, 8080, 100)
+
+	w, err := NewWatcher(path, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"server": {}}`), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := w.Reload(); err == nil {
+		t.Fatal("expected a config without jwtSecret to be rejected")
+	}
+}
+
+func TestWatcherReload_RestartRequired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeReloadConfig(t, path, 8080, 100)
+
+	w, err := NewWatcher(path, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+
+	writeReloadConfig(t, path, 9090, 100)
+	status, err := w.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(status.RestartRequired) != 1 || status.RestartRequired[0] != "server" {
+		t.Fatalf("RestartRequired = %v, want [server]", status.RestartRequired)
+	}
+
+	// Still pending after a later reload of a live setting
+	writeReloadConfig(t, path, 9090, 300)
+	status, err = w.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(status.RestartRequired) != 1 || status.RestartRequired[0] != "server" {
+		t.Errorf("RestartRequired = %v, want [server] until restart", status.RestartRequired)
+	}
+}
+
+func TestRestartRequiredChanges_EnablingSearch(t *testing.T) {
+	old, _ := parseConfig([]byte(`{"server": {"jwtSecret": "s"}}`))
+	next, _ := parseConfig([]byte(`{"server": {"jwtSecret": "s"}, "growerai": {"tools": {"searxng": {"enabled": true}}}}`))
+
+	if changed := RestartRequiredChanges(old, next); len(changed) != 1 || changed[0] != "growerai.tools.searxng.enabled" {
+		t.Errorf("enabling search: got %v", changed)
+	}
+	if changed := RestartRequiredChanges(next, old); len(changed) != 0 {
+		t.Errorf("disabling search should apply live, got %v", changed)
+	}
+}
@@ -1,14 +1,19 @@
 package db
 
 import (
+	"context"
+	"fmt"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"go-llama/internal/auth"
 	"go-llama/internal/config"
 	"go-llama/internal/user"
 	"go-llama/internal/chat"
 	"go-llama/internal/memory"
 	"go-llama/internal/dialogue"  // NEW
+	"go-llama/internal/llm"
 	"log"
 )
 
@@ -33,20 +38,60 @@ func Init(cfg *config.Config) error {
 	}
 	
 	// Auto-migrate GrowerAI principles
-	if err := db.AutoMigrate(&memory.Principle{}); err != nil {
+	if err := db.AutoMigrate(&memory.Principle{}, &memory.PrincipleHistory{}); err != nil {
+		return err
+	}
+	
+	// Auto-migrate concept tag backfill progress
+	if err := db.AutoMigrate(&memory.RetagCheckpoint{}); err != nil {
 		return err
 	}
 	
 	// Auto-migrate dialogue state tables (Phase 3.1)
 	if err := db.AutoMigrate(
 		&dialogue.DialogueState{},
+		&dialogue.StateVersion{},
+		&dialogue.GoalRecord{},
+		&dialogue.GoalActionRecord{},
 		&dialogue.DialogueMetrics{},
 		&dialogue.DialogueThought{},
+		&dialogue.DialogueAction{},
+		&dialogue.DialogueGoalEvent{},
+		&dialogue.GoalArchive{},
+		&dialogue.ActionResult{},
+		&dialogue.TopicSuppression{},
+		&dialogue.StateQuarantine{},
+		&dialogue.SearchLedgerEntry{},
+		&dialogue.CycleTranscript{},
+		&dialogue.GoalAnswer{},
 	); err != nil {
 		return err
 	}
+	dialogue.EnsureHistoryIndexes(db)
+
+	// Auto-migrate the audit log of admin and mutating dialogue calls
+	if err := db.AutoMigrate(&auth.AuditEntry{}); err != nil {
+		return err
+	}
+
+	// Auto-migrate hosted LLM token usage
+	if err := db.AutoMigrate(&llm.LLMUsage{}); err != nil {
+		return err
+	}
 	
 	DB = db
 	log.Printf("Database connected and migrated")
 	return nil
 }
+
+// Ping checks that Postgres answers on the shared connection
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
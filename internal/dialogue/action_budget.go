@@ -0,0 +1,68 @@
+// internal/dialogue/action_budget.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-llama/internal/goal"
+)
+
+// actionBudget decides how long an action may run. The tool timeout already bounds each
+// attempt, so a shorter cap is only returned when the cycle would end first. It returns
+// goal.ErrInsufficientTime when less than minimum would be left after the safety margin.
+func actionBudget(now, deadline time.Time, hasDeadline bool, toolTimeout, margin, minimum time.Duration) (time.Duration, bool, error) {
+	if !hasDeadline {
+		return toolTimeout, false, nil
+	}
+
+	remaining := deadline.Sub(now) - margin
+	if remaining < minimum {
+		return 0, false, fmt.Errorf("%w: %s left after %s margin, need %s",
+			goal.ErrInsufficientTime, remaining.Round(time.Second), margin, minimum)
+	}
+	if remaining < toolTimeout {
+		return remaining, true, nil
+	}
+	return toolTimeout, false, nil
+}
+
+type outsideCycleKey struct{}
+
+// outsideCycle marks ctx as belonging to work done outside a dialogue cycle, whose
+// deadline is its own budget: actions may run right up to it, without the cycle's end
+// margin or minimum action time
+func outsideCycle(ctx context.Context) context.Context {
+	return context.WithValue(ctx, outsideCycleKey{}, true)
+}
+
+// actionContext derives the context an action runs under. Actions that cannot get the
+// minimum time before the cycle ends are refused with goal.ErrInsufficientTime.
+func (e *Engine) actionContext(ctx context.Context, tool string) (context.Context, context.CancelFunc, error) {
+	toolTimeout := 240 * time.Second
+	if e.toolRegistry != nil {
+		toolTimeout = e.toolRegistry.IdleTimeout(tool)
+	}
+
+	margin, minimum := e.actionTimeMargin, e.minActionTime
+	if _, ok := ctx.Value(outsideCycleKey{}).(bool); ok {
+		margin, minimum = 0, 0
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	budget, capped, err := actionBudget(time.Now(), deadline, hasDeadline, toolTimeout, margin, minimum)
+	if err != nil {
+		log.Printf("[Dialogue] Skipping %s action, leaving it pending: %v", tool, err)
+		return ctx, func() {}, err
+	}
+	if !capped {
+		return ctx, func() {}, nil
+	}
+
+	log.Printf("[Dialogue] %s action limited to %s (tool timeout %s) to finish before the cycle ends",
+		tool, budget.Round(time.Second), toolTimeout)
+	actionCtx, cancel := context.WithTimeout(ctx, budget)
+	return actionCtx, cancel, nil
+}
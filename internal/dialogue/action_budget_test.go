@@ -0,0 +1,96 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-llama/internal/goal"
+	"go-llama/internal/tools"
+)
+
+func TestActionBudgetBoundaries(t *testing.T) {
+	now := time.Now()
+	toolTimeout := 15 * time.Minute
+	margin := 30 * time.Second
+	minimum := time.Minute
+
+	cases := []struct {
+		name      string
+		remaining time.Duration
+		want      time.Duration
+		capped    bool
+		skipped   bool
+	}{
+		{"plenty of time", 20 * time.Minute, toolTimeout, false, false},
+		{"exactly tool timeout after margin", toolTimeout + margin, toolTimeout, false, false},
+		{"just under tool timeout", toolTimeout + margin - time.Second, toolTimeout - time.Second, true, false},
+		{"minute 28 of 30", 2 * time.Minute, 90 * time.Second, true, false},
+		{"exactly minimum", minimum + margin, minimum, true, false},
+		{"just under minimum", minimum + margin - time.Second, 0, false, true},
+		{"past deadline", -time.Second, 0, false, true},
+	}
+	for _, tc := range cases {
+		budget, capped, err := actionBudget(now, now.Add(tc.remaining), true, toolTimeout, margin, minimum)
+		if tc.skipped {
+			if !errors.Is(err, goal.ErrInsufficientTime) {
+				t.Errorf("%s: expected ErrInsufficientTime, got %v", tc.name, err)
+			}
+			continue
+		}
+		if err != nil || budget != tc.want || capped != tc.capped {
+			t.Errorf("%s: got %s capped=%v (%v), want %s capped=%v", tc.name, budget, capped, err, tc.want, tc.capped)
+		}
+	}
+
+	if budget, capped, err := actionBudget(now, time.Time{}, false, toolTimeout, margin, minimum); err != nil || capped || budget != toolTimeout {
+		t.Errorf("expected no cap without a cycle deadline, got %s capped=%v (%v)", budget, capped, err)
+	}
+}
+
+// countingTool records whether it ran and how long its context allowed
+type countingTool struct {
+	calls    int
+	deadline time.Duration
+}
+
+func (c *countingTool) Name() string        { return tools.ToolNameSearch }
+func (c *countingTool) Description() string { return "test search" }
+func (c *countingTool) RequiresAuth() bool  { return false }
+func (c *countingTool) Execute(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+	c.calls++
+	if d, ok := ctx.Deadline(); ok {
+		c.deadline = time.Until(d)
+	}
+	return &tools.ToolResult{Success: true, Output: "ok"}, nil
+}
+
+func TestExecuteToolActionDefersWhenCycleIsNearlyOver(t *testing.T) {
+	tool := &countingTool{}
+	registry := tools.NewRegistry()
+	registry.Register(tool)
+	e := &Engine{
+		toolRegistry:     tools.NewContextualRegistry(registry, map[string]tools.ToolConfig{tools.ToolNameSearch: {TimeoutIdle: 15 * time.Minute}}),
+		actionTimeMargin: 30 * time.Second,
+		minActionTime:    time.Minute,
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 80*time.Second)
+	defer cancel()
+	if _, err := e.ExecuteToolAction(short, tools.ToolNameSearch, map[string]interface{}{"query": "go"}); !errors.Is(err, goal.ErrInsufficientTime) {
+		t.Fatalf("expected the action to be deferred, got %v", err)
+	}
+	if tool.calls != 0 {
+		t.Fatal("expected the tool not to run")
+	}
+
+	longer, cancel2 := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel2()
+	if _, err := e.ExecuteToolAction(longer, tools.ToolNameSearch, map[string]interface{}{"query": "go"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tool.calls != 1 || tool.deadline > 150*time.Second || tool.deadline < 140*time.Second {
+		t.Errorf("expected one run capped near 2m30s, got %d runs with %s", tool.calls, tool.deadline)
+	}
+}
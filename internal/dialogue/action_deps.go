@@ -0,0 +1,118 @@
+// internal/dialogue/action_deps.go
+package dialogue
+
+import (
+	"fmt"
+	"log"
+)
+
+// findAction returns the goal's action with the given ID
+func findAction(goal *Goal, id string) *Action {
+	for i := range goal.Actions {
+		if goal.Actions[i].ID == id {
+			return &goal.Actions[i]
+		}
+	}
+	return nil
+}
+
+// dependencyState reports whether an action's dependencies have all completed
+// successfully, and if one never will, why
+func dependencyState(goal *Goal, action *Action) (ready bool, blockedReason string) {
+	ready = true
+	for _, id := range action.DependsOn {
+		dep := findAction(goal, id)
+		switch {
+		case dep == nil:
+			return false, fmt.Sprintf("depends on action %s, which no longer exists", id)
+		case dep.Status == ActionStatusBlocked:
+			return false, fmt.Sprintf("depends on action %s (%s), which was blocked", id, dep.Tool)
+		case dep.Status == ActionStatusCompleted && actionFailed(*dep):
+			return false, fmt.Sprintf("depends on action %s (%s), which failed", id, dep.Tool)
+		case dep.Status != ActionStatusCompleted:
+			ready = false
+		}
+	}
+	return ready, ""
+}
+
+// nextRunnableAction returns the goal's first pending action whose dependencies have
+// completed successfully, regardless of where its dependencies sit in the slice.
+// Pending actions whose dependencies failed are marked blocked on the way, with the
+// reason as their result, so they never run with a placeholder input.
+func nextRunnableAction(goal *Goal) *Action {
+	// Blocking one action can block its own dependents, so repeat until settled
+	for blocked := true; blocked; {
+		blocked = false
+		for i := range goal.Actions {
+			action := &goal.Actions[i]
+			if action.Status != ActionStatusPending {
+				continue
+			}
+			if _, reason := dependencyState(goal, action); reason != "" {
+				action.Status = ActionStatusBlocked
+				action.Result = "Blocked: " + reason
+				log.Printf("[Dialogue] Action %s (%s) of goal %s blocked: %s", action.ID, action.Tool, goal.ID, reason)
+				blocked = true
+			}
+		}
+	}
+	for i := range goal.Actions {
+		action := &goal.Actions[i]
+		if action.Status != ActionStatusPending {
+			continue
+		}
+		if ready, _ := dependencyState(goal, action); ready {
+			return action
+		}
+	}
+	return nil
+}
+
+// completedDependency returns the action's completed dependency that used tool, so a
+// parse action reads the results of its own search rather than whichever ran last
+func completedDependency(goal *Goal, action *Action, tool string) *Action {
+	for i := len(action.DependsOn) - 1; i >= 0; i-- {
+		dep := findAction(goal, action.DependsOn[i])
+		if dep != nil && dep.Tool == tool && dep.Status == ActionStatusCompleted {
+			return dep
+		}
+	}
+	return nil
+}
+
+// inheritSearchResults copies the URLs and results of a parse action's own search into
+// its metadata, where executeAction looks for them. It reports whether there was a
+// completed search dependency to copy from.
+func inheritSearchResults(goal *Goal, action *Action) bool {
+	search := completedDependency(goal, action, ActionToolSearch)
+	if search == nil || search.Metadata == nil {
+		return false
+	}
+	if action.Metadata == nil {
+		action.Metadata = make(map[string]interface{})
+	}
+	if urls, ok := search.Metadata["extracted_urls"].([]string); ok && len(urls) > 0 {
+		action.Metadata["previous_search_urls"] = urls
+	}
+	if results, ok := search.Metadata[MetadataSearchResults]; ok {
+		action.Metadata[MetadataSearchResults] = results
+	}
+	return true
+}
+
+// linkPlanDependencies makes each parse action in a freshly planned sequence depend on
+// the search before it, which supplies its URL
+func linkPlanDependencies(actions []Action) {
+	lastSearch := ""
+	for i := range actions {
+		switch actions[i].Tool {
+		case ActionToolSearch:
+			lastSearch = actions[i].ID
+		case ActionToolWebParseUnified:
+			if lastSearch != "" && len(actions[i].DependsOn) == 0 {
+				actions[i].DependsOn = []string{lastSearch}
+			}
+		}
+	}
+}
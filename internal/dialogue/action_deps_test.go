@@ -0,0 +1,117 @@
+package dialogue
+
+import (
+	"strings"
+	"testing"
+)
+
+// reorderedGoal has a parse action placed before the search it depends on, as a
+// reordering scheduler or a retry could leave it
+func reorderedGoal() *Goal {
+	return &Goal{
+		ID: "goal_reorder",
+		Actions: []Action{
+			{ID: "parse", Tool: ActionToolWebParseUnified, Description: "URL from search results", Status: ActionStatusPending, DependsOn: []string{"search"}},
+			{ID: "search", Tool: ActionToolSearch, Description: "bee navigation", Status: ActionStatusPending},
+		},
+	}
+}
+
+func TestNextRunnableActionWaitsForDependency(t *testing.T) {
+	goal := reorderedGoal()
+	next := nextRunnableAction(goal)
+	if next == nil || next.ID != "search" {
+		t.Fatalf("expected the search to run before its parse, got %+v", next)
+	}
+
+	next.Status = ActionStatusCompleted
+	next.Result = "1. Bee navigation - https://example.com/bees"
+	next.Metadata = map[string]interface{}{"extracted_urls": []string{"https://example.com/bees"}}
+
+	next = nextRunnableAction(goal)
+	if next == nil || next.ID != "parse" {
+		t.Fatalf("expected the parse once its search completed, got %+v", next)
+	}
+	if !inheritSearchResults(goal, next) {
+		t.Fatal("expected the parse to find its search")
+	}
+	if urls, _ := next.Metadata["previous_search_urls"].([]string); len(urls) != 1 || urls[0] != "https://example.com/bees" {
+		t.Errorf("expected the parse to inherit its search's URLs, got %v", next.Metadata)
+	}
+}
+
+func TestNextRunnableActionBlocksDependentsOfFailures(t *testing.T) {
+	goal := reorderedGoal()
+	goal.Actions = append(goal.Actions, Action{ID: "summarize", Tool: ActionToolSynthesis, Status: ActionStatusPending, DependsOn: []string{"parse"}})
+	goal.Actions[1].Status = ActionStatusCompleted
+	goal.Actions[1].FailureKind = "timeout"
+	goal.Actions[1].Result = "Error: search timed out"
+
+	if next := nextRunnableAction(goal); next != nil {
+		t.Fatalf("expected nothing runnable, got %+v", next)
+	}
+	parse, summarize := goal.Actions[0], goal.Actions[2]
+	if parse.Status != ActionStatusBlocked || !strings.Contains(parse.Result, "search") || !strings.Contains(parse.Result, "failed") {
+		t.Errorf("expected the parse blocked by the failed search, got %s %q", parse.Status, parse.Result)
+	}
+	if summarize.Status != ActionStatusBlocked || !strings.Contains(summarize.Result, "blocked") {
+		t.Errorf("expected the blocking to carry to dependents, got %s %q", summarize.Status, summarize.Result)
+	}
+}
+
+func TestNextRunnableActionBlocksMissingDependency(t *testing.T) {
+	goal := &Goal{Actions: []Action{{ID: "parse", Tool: ActionToolWebParseUnified, Status: ActionStatusPending, DependsOn: []string{"gone"}}}}
+	if next := nextRunnableAction(goal); next != nil || goal.Actions[0].Status != ActionStatusBlocked {
+		t.Errorf("expected a parse whose search no longer exists to be blocked, got %+v", goal.Actions[0])
+	}
+}
+
+func TestLinkPlanDependencies(t *testing.T) {
+	actions := []Action{
+		{ID: "s1", Tool: ActionToolSearch},
+		{ID: "p1", Tool: ActionToolWebParseUnified},
+		{ID: "s2", Tool: ActionToolSearch},
+		{ID: "p2", Tool: ActionToolWebParseUnified},
+		{ID: "p3", Tool: ActionToolWebParseUnified, DependsOn: []string{"s1"}},
+	}
+	linkPlanDependencies(actions)
+	for i, want := range [][]string{nil, {"s1"}, nil, {"s2"}, {"s1"}} {
+		if strings.Join(actions[i].DependsOn, ",") != strings.Join(want, ",") {
+			t.Errorf("action %s: expected dependencies %v, got %v", actions[i].ID, want, actions[i].DependsOn)
+		}
+	}
+}
+
+func TestNewActionIDUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := newActionID()
+		if seen[id] {
+			t.Fatalf("duplicate action ID %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestApplyReplanDropsDependentsOfDroppedActions(t *testing.T) {
+	goal := &Goal{Actions: []Action{
+		{ID: "s1", Tool: ActionToolSearch, Status: ActionStatusPending, Metadata: map[string]interface{}{"research_question_id": "q1"}},
+		{ID: "p1", Tool: ActionToolWebParseUnified, Status: ActionStatusPending, DependsOn: []string{"s1"}},
+		{ID: "other", Tool: ActionToolSearch, Status: ActionStatusPending},
+	}}
+	applyReplan(goal, &ResearchPlan{}, "new angle")
+	if len(goal.Actions) != 1 || goal.Actions[0].ID != "other" {
+		t.Errorf("expected the old question's search and its parse dropped, got %+v", goal.Actions)
+	}
+}
+
+func TestGoalArchiveKeepsDependencies(t *testing.T) {
+	goal := reorderedGoal()
+	restored := newGoalArchive(*goal, goal.Created).goal()
+	if restored.Actions[0].ID != "parse" || len(restored.Actions[0].DependsOn) != 1 || restored.Actions[0].DependsOn[0] != "search" {
+		t.Errorf("expected action IDs and dependencies restored, got %+v", restored.Actions)
+	}
+	if next := nextRunnableAction(&restored); next == nil || next.ID != "search" {
+		t.Errorf("expected the restored search to run first, got %+v", next)
+	}
+}
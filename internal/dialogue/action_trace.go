@@ -0,0 +1,32 @@
+// internal/dialogue/action_trace.go
+package dialogue
+
+import (
+	"time"
+
+	"go-llama/internal/tools"
+)
+
+// SetTraceExporter sends each action's trace to an OTLP collector as well as recording
+// it in the action's metadata. nil stops exporting.
+func (e *Engine) SetTraceExporter(exporter *tools.OTLPExporter) {
+	e.traceExporter = exporter
+}
+
+// finishActionTrace times the whole action and records its trace ID and spans in the
+// action's metadata, so a slow action can be broken down without an external collector
+func (e *Engine) finishActionTrace(action *Action, trace *tools.Trace, start time.Time) {
+	trace.Record(tools.SpanAction, start, time.Since(start))
+	if action.Metadata == nil {
+		action.Metadata = make(map[string]interface{})
+	}
+	action.Metadata[tools.MetaTraceID] = trace.ID
+	action.Metadata[tools.MetaTraceSpans] = trace.Metadata()
+
+	if e.traceExporter != nil {
+		e.traceExporter.ExportAsync(trace, "action "+action.Tool, map[string]string{
+			"action.id":   action.ID,
+			"action.tool": action.Tool,
+		})
+	}
+}
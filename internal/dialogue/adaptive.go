@@ -2,12 +2,77 @@ package dialogue
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"strings"
+	"sync"
+
+	"go-llama/internal/tools"
 )
 
+// AdaptiveLimits bound the adaptive thresholds and can pin them. A pinned (non-zero)
+// threshold is used as is; UpdateMetrics no longer adapts it.
+type AdaptiveLimits struct {
+	SearchMin         float64
+	SearchMax         float64
+	CollectiveMax     float64 // Collective memories are searched at the search threshold, capped here
+	GoalSimilarityMin float64 // Floor that keeps duplicate goals from slipping through
+	GoalSimilarityMax float64
+
+	PinnedSearch         float64
+	PinnedCollective     float64
+	PinnedGoalSimilarity float64
+}
+
+// DefaultAdaptiveLimits returns the bounds used before they were configurable, with
+// nothing pinned
+func DefaultAdaptiveLimits() AdaptiveLimits {
+	return AdaptiveLimits{
+		SearchMin:         minSearchThreshold,
+		SearchMax:         maxSearchThreshold,
+		CollectiveMax:     0.20,
+		GoalSimilarityMin: minGoalSimilarityThreshold,
+		GoalSimilarityMax: maxGoalSimilarityThreshold,
+	}
+}
+
+// Validate rejects bounds that are empty or outside (0, 1) and pins outside [0, 1)
+func (l AdaptiveLimits) Validate() error {
+	if l.SearchMin <= 0 || l.SearchMin > l.SearchMax || l.SearchMax >= 1 {
+		return fmt.Errorf("adaptive.bounds search range [%.2f, %.2f] must lie within (0, 1)", l.SearchMin, l.SearchMax)
+	}
+	if l.CollectiveMax <= 0 || l.CollectiveMax >= 1 {
+		return fmt.Errorf("adaptive.bounds.collective_max must be between 0 and 1, got %.2f", l.CollectiveMax)
+	}
+	if l.GoalSimilarityMin <= 0 || l.GoalSimilarityMin > l.GoalSimilarityMax || l.GoalSimilarityMax >= 1 {
+		return fmt.Errorf("adaptive.bounds goal similarity range [%.2f, %.2f] must lie within (0, 1)", l.GoalSimilarityMin, l.GoalSimilarityMax)
+	}
+	pins := []struct {
+		name  string
+		value float64
+	}{{"search_threshold", l.PinnedSearch}, {"collective_threshold", l.PinnedCollective}, {"goal_similarity", l.PinnedGoalSimilarity}}
+	for _, pin := range pins {
+		if pin.value < 0 || pin.value >= 1 {
+			return fmt.Errorf("adaptive.pins.%s must be between 0 and 1, got %.2f", pin.name, pin.value)
+		}
+	}
+	return nil
+}
+
+// clampThreshold keeps v within [lo, hi]
+func clampThreshold(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
 // AdaptiveConfig manages dynamic threshold adjustments
 type AdaptiveConfig struct {
+	mu sync.RWMutex // Cycles update the values while status requests read them
+
 	// Base values from config
 	baseSearchThreshold    float64
 	baseGoalSimilarity     float64
@@ -17,11 +82,14 @@ type AdaptiveConfig struct {
 	searchThreshold        float64
 	goalSimilarityThreshold float64
 	toolTimeout            int
+	limits                 AdaptiveLimits
 	
 	// Historical metrics for adaptation
 	recentSearchSuccessRate float64
 	recentGoalSuccessRate   float64
 	averageMemoryCount      int
+	goalSamples             int // Goals behind recentGoalSuccessRate
+	updateCount             int // UpdateMetrics calls since the state was first created
 }
 
 // NewAdaptiveConfig creates a new adaptive configuration manager
@@ -33,6 +101,7 @@ func NewAdaptiveConfig(baseSearchThreshold, baseGoalSimilarity float64, baseTool
 		searchThreshold:         baseSearchThreshold,
 		goalSimilarityThreshold: baseGoalSimilarity,
 		toolTimeout:             baseToolTimeout,
+		limits:                  DefaultAdaptiveLimits(),
 		recentSearchSuccessRate: 0.5, // Start neutral
 		recentGoalSuccessRate:   0.5,
 		averageMemoryCount:      0,
@@ -41,8 +110,12 @@ func NewAdaptiveConfig(baseSearchThreshold, baseGoalSimilarity float64, baseTool
 
 // UpdateMetrics recalculates adaptive thresholds based on current performance
 func (ac *AdaptiveConfig) UpdateMetrics(ctx context.Context, state *InternalState, totalMemories int) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
 	// Update memory count
 	ac.averageMemoryCount = totalMemories
+	ac.updateCount++
 	
 	// Calculate goal success rate from recent goals
 	timeoutCount := 0
@@ -57,15 +130,13 @@ func (ac *AdaptiveConfig) UpdateMetrics(ctx context.Context, state *InternalStat
 		
 		for _, goal := range recentGoals {
 			totalGoals++
-			if goal.Outcome == "good" {
+			if goal.Outcome.Succeeded() {
 				successCount++
 			}
 			
 			// Check if goal failed due to timeout
 			for _, action := range goal.Actions {
-				if action.Status == ActionStatusCompleted && 
-				   (strings.Contains(strings.ToLower(action.Result), "timeout") ||
-				    strings.Contains(strings.ToLower(action.Result), "deadline exceeded")) {
+				if action.FailureKind == tools.FailureKindTimeout {
 					timeoutCount++
 					break // Count goal once even if multiple actions timed out
 				}
@@ -74,6 +145,7 @@ func (ac *AdaptiveConfig) UpdateMetrics(ctx context.Context, state *InternalStat
 		
 		if totalGoals > 0 {
 			ac.recentGoalSuccessRate = float64(successCount) / float64(totalGoals)
+			ac.goalSamples = totalGoals
 		}
 	}
 	
@@ -103,12 +175,24 @@ func (ac *AdaptiveConfig) UpdateMetrics(ctx context.Context, state *InternalStat
 	}
 	
 	// CRITICAL: Enforce minimum threshold to prevent duplicate goal spam
-	// Even during high timeout scenarios, never allow threshold below 0.75
-	if ac.goalSimilarityThreshold < 0.75 {
-		log.Printf("[AdaptiveConfig] Clamping goal similarity threshold from %.2f to 0.75 (minimum)", 
-			ac.goalSimilarityThreshold)
-		ac.goalSimilarityThreshold = 0.75
+	// Even during high timeout scenarios, never allow threshold below the configured floor
+	if clamped := clampThreshold(ac.goalSimilarityThreshold, ac.limits.GoalSimilarityMin, ac.limits.GoalSimilarityMax); clamped != ac.goalSimilarityThreshold {
+		log.Printf("[AdaptiveConfig] Clamping goal similarity threshold from %.2f to %.2f",
+			ac.goalSimilarityThreshold, clamped)
+		ac.goalSimilarityThreshold = clamped
+	}
+	ac.searchThreshold = clampThreshold(ac.searchThreshold, ac.limits.SearchMin, ac.limits.SearchMax)
+
+	// Pinned thresholds override whatever was learned
+	if ac.limits.PinnedSearch > 0 {
+		log.Printf("[AdaptiveConfig] Search threshold pinned at %.2f, not adapting (would be %.2f)",
+			ac.limits.PinnedSearch, ac.searchThreshold)
+	}
+	if ac.limits.PinnedGoalSimilarity > 0 {
+		log.Printf("[AdaptiveConfig] Goal similarity threshold pinned at %.2f, not adapting (would be %.2f)",
+			ac.limits.PinnedGoalSimilarity, ac.goalSimilarityThreshold)
 	}
+	ac.applyPins()
 	
 	// Adapt tool timeout based on success rate AND timeout frequency
 	// Low success = give tools more time
@@ -162,17 +246,67 @@ func (ac *AdaptiveConfig) UpdateMetrics(ctx context.Context, state *InternalStat
 		ac.averageMemoryCount, ac.recentGoalSuccessRate, timeoutRate, timeoutCount, totalGoals)
 }
 
+// SetBase changes the base values; current thresholds move to them at the next UpdateMetrics
+// so values learned in earlier cycles are not discarded
+func (ac *AdaptiveConfig) SetBase(baseSearchThreshold, baseGoalSimilarity float64, baseToolTimeout int) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.baseSearchThreshold = baseSearchThreshold
+	ac.baseGoalSimilarity = baseGoalSimilarity
+	ac.baseToolTimeout = baseToolTimeout
+}
+
+// SetLimits changes the bounds and pins. Pins take effect at once; bounds at the next
+// UpdateMetrics.
+func (ac *AdaptiveConfig) SetLimits(limits AdaptiveLimits) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.limits = limits
+	ac.applyPins()
+}
+
+// applyPins replaces learned thresholds with pinned ones; callers hold mu
+func (ac *AdaptiveConfig) applyPins() {
+	if ac.limits.PinnedSearch > 0 {
+		ac.searchThreshold = ac.limits.PinnedSearch
+	}
+	if ac.limits.PinnedGoalSimilarity > 0 {
+		ac.goalSimilarityThreshold = ac.limits.PinnedGoalSimilarity
+	}
+}
+
 // GetSearchThreshold returns the current adaptive search threshold
 func (ac *AdaptiveConfig) GetSearchThreshold() float64 {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.searchThreshold
+}
+
+// GetCollectiveThreshold returns the threshold for searching collective memories. Recent
+// learnings rarely match a query closely, so it is the search threshold capped at the
+// collective maximum, unless pinned.
+func (ac *AdaptiveConfig) GetCollectiveThreshold() float64 {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	if ac.limits.PinnedCollective > 0 {
+		return ac.limits.PinnedCollective
+	}
+	if ac.searchThreshold > ac.limits.CollectiveMax {
+		return ac.limits.CollectiveMax
+	}
 	return ac.searchThreshold
 }
 
 // GetGoalSimilarityThreshold returns the current adaptive goal similarity threshold
 func (ac *AdaptiveConfig) GetGoalSimilarityThreshold() float64 {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
 	return ac.goalSimilarityThreshold
 }
 
 // GetToolTimeout returns the current adaptive tool timeout in seconds
 func (ac *AdaptiveConfig) GetToolTimeout() int {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
 	return ac.toolTimeout
 }
@@ -0,0 +1,250 @@
+// internal/dialogue/adaptive_state.go
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// adaptiveStateVersion is written with every snapshot. Fields are only ever added, so a
+// blob of any version decodes onto the current values and missing fields keep them.
+const adaptiveStateVersion = 1
+
+// AdaptiveSnapshot is the learned part of AdaptiveConfig, persisted across restarts.
+// Base values are not stored; they come from config.
+type AdaptiveSnapshot struct {
+	Version                 int       `json:"version"`
+	SearchThreshold         float64   `json:"search_threshold"`
+	GoalSimilarityThreshold float64   `json:"goal_similarity_threshold"`
+	ToolTimeout             int       `json:"tool_timeout"` // Seconds
+	RecentSearchSuccessRate float64   `json:"recent_search_success_rate"`
+	RecentGoalSuccessRate   float64   `json:"recent_goal_success_rate"`
+	GoalSamples             int       `json:"goal_samples"`
+	AverageMemoryCount      int       `json:"average_memory_count"`
+	UpdateCount             int       `json:"update_count"`
+	SavedAt                 time.Time `json:"saved_at"`
+}
+
+// Default ranges for restored values; anything outside the configured ones is clamped so
+// a corrupted blob cannot push thresholds somewhere UpdateMetrics would never take them
+const (
+	minSearchThreshold         = 0.05
+	maxSearchThreshold         = 0.95
+	minGoalSimilarityThreshold = 0.75 // UpdateMetrics' own floor
+	maxGoalSimilarityThreshold = 0.99
+	minAdaptiveToolTimeout     = 30 // Seconds, matching UpdateMetrics' caps
+	maxAdaptiveToolTimeout     = 600
+)
+
+// Snapshot captures the learned state for persistence
+func (ac *AdaptiveConfig) Snapshot() AdaptiveSnapshot {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return AdaptiveSnapshot{
+		Version:                 adaptiveStateVersion,
+		SearchThreshold:         ac.searchThreshold,
+		GoalSimilarityThreshold: ac.goalSimilarityThreshold,
+		ToolTimeout:             ac.toolTimeout,
+		RecentSearchSuccessRate: ac.recentSearchSuccessRate,
+		RecentGoalSuccessRate:   ac.recentGoalSuccessRate,
+		GoalSamples:             ac.goalSamples,
+		AverageMemoryCount:      ac.averageMemoryCount,
+		UpdateCount:             ac.updateCount,
+		SavedAt:                 time.Now(),
+	}
+}
+
+// Restore loads a snapshot, clamping out-of-range values. It returns the fields that had
+// to be clamped.
+func (ac *AdaptiveConfig) Restore(snap AdaptiveSnapshot) []string {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	var clamped []string
+	clampFloat := func(name string, v, lo, hi float64) float64 {
+		if math.IsNaN(v) || v < lo || v > hi {
+			clamped = append(clamped, name)
+			if v > hi {
+				return hi
+			}
+			return lo
+		}
+		return v
+	}
+	clampInt := func(name string, v, lo, hi int) int {
+		if v < lo || v > hi {
+			clamped = append(clamped, name)
+			if v > hi {
+				return hi
+			}
+			return lo
+		}
+		return v
+	}
+
+	ac.searchThreshold = clampFloat("search_threshold", snap.SearchThreshold, ac.limits.SearchMin, ac.limits.SearchMax)
+	ac.goalSimilarityThreshold = clampFloat("goal_similarity_threshold", snap.GoalSimilarityThreshold,
+		ac.limits.GoalSimilarityMin, ac.limits.GoalSimilarityMax)
+	ac.toolTimeout = clampInt("tool_timeout", snap.ToolTimeout, minAdaptiveToolTimeout, maxAdaptiveToolTimeout)
+	ac.recentSearchSuccessRate = clampFloat("recent_search_success_rate", snap.RecentSearchSuccessRate, 0, 1)
+	ac.recentGoalSuccessRate = clampFloat("recent_goal_success_rate", snap.RecentGoalSuccessRate, 0, 1)
+	ac.goalSamples = clampInt("goal_samples", snap.GoalSamples, 0, math.MaxInt32)
+	ac.averageMemoryCount = clampInt("average_memory_count", snap.AverageMemoryCount, 0, math.MaxInt32)
+	ac.updateCount = clampInt("update_count", snap.UpdateCount, 0, math.MaxInt32)
+	ac.applyPins()
+	return clamped
+}
+
+// decodeAdaptiveSnapshot reads a persisted blob onto the current state, so fields a
+// blob's version predates keep their current values
+func decodeAdaptiveSnapshot(raw []byte, current AdaptiveSnapshot) (AdaptiveSnapshot, error) {
+	snap := current
+	snap.Version = 0
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return current, fmt.Errorf("invalid adaptive state: %w", err)
+	}
+	if snap.Version > adaptiveStateVersion {
+		log.Printf("[AdaptiveConfig] Adaptive state version %d is newer than %d, loading known fields only",
+			snap.Version, adaptiveStateVersion)
+	}
+	return snap, nil
+}
+
+// LoadAdaptiveState reads the persisted adaptive state; nil when none has been saved
+func (sm *StateManager) LoadAdaptiveState(ctx context.Context) (datatypes.JSON, error) {
+	var dbState DialogueState
+	err := sm.db.WithContext(ctx).Select("adaptive_state").First(&dbState, 1).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil // First boot: the state row is created by the first cycle
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load adaptive state: %w", err)
+	}
+	if len(dbState.AdaptiveState) == 0 || string(dbState.AdaptiveState) == "null" {
+		return nil, nil
+	}
+	return dbState.AdaptiveState, nil
+}
+
+// SaveAdaptiveState persists a snapshot of the adaptive state
+func (sm *StateManager) SaveAdaptiveState(ctx context.Context, snap AdaptiveSnapshot) error {
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to encode adaptive state: %w", err)
+	}
+	if err := sm.db.WithContext(ctx).Model(&DialogueState{}).Where("id = ?", 1).
+		Update("adaptive_state", datatypes.JSON(raw)).Error; err != nil {
+		return fmt.Errorf("failed to save adaptive state: %w", err)
+	}
+	return nil
+}
+
+// restoreAdaptiveState loads the learned thresholds saved by earlier runs
+func (e *Engine) restoreAdaptiveState(ctx context.Context) {
+	raw, err := e.stateManager.LoadAdaptiveState(ctx)
+	if err != nil {
+		log.Printf("[AdaptiveConfig] WARNING: %v, starting from base values", err)
+		return
+	}
+	if raw == nil {
+		log.Printf("[AdaptiveConfig] No saved adaptive state, starting from base values")
+		return
+	}
+
+	snap, err := decodeAdaptiveSnapshot(raw, e.adaptiveConfig.Snapshot())
+	if err != nil {
+		log.Printf("[AdaptiveConfig] WARNING: %v, starting from base values", err)
+		return
+	}
+	if clamped := e.adaptiveConfig.Restore(snap); len(clamped) > 0 {
+		log.Printf("[AdaptiveConfig] WARNING: Clamped out-of-range saved values: %v", clamped)
+	}
+	log.Printf("[AdaptiveConfig] Restored adaptive state v%d from %s: search=%.2f, goal_sim=%.2f, timeout=%ds, goal_success=%.2f (%d goals), %d updates",
+		snap.Version, snap.SavedAt.Format(time.RFC3339),
+		e.adaptiveConfig.searchThreshold, e.adaptiveConfig.goalSimilarityThreshold, e.adaptiveConfig.toolTimeout,
+		e.adaptiveConfig.recentGoalSuccessRate, e.adaptiveConfig.goalSamples, e.adaptiveConfig.updateCount)
+}
+
+// updateAdaptiveState adapts thresholds to the finished cycle and saves them
+func (e *Engine) updateAdaptiveState(ctx context.Context, state *InternalState) {
+	totalMemories := e.adaptiveConfig.averageMemoryCount
+	if e.storage != nil {
+		if count, err := e.storage.GetTotalMemoryCount(ctx); err == nil {
+			totalMemories = count
+		}
+	}
+	e.adaptiveConfig.UpdateMetrics(ctx, state, totalMemories)
+
+	if err := e.stateManager.SaveAdaptiveState(ctx, e.adaptiveConfig.Snapshot()); err != nil {
+		log.Printf("[AdaptiveConfig] WARNING: %v", err)
+	}
+}
+
+// AdaptiveThresholds are the retrieval and duplicate thresholds in use, which of them are
+// pinned by config, and the bounds the rest adapt within
+type AdaptiveThresholds struct {
+	Search               float64        `json:"search"`
+	Collective           float64        `json:"collective"`
+	GoalSimilarity       float64        `json:"goal_similarity"`
+	SearchPinned         bool           `json:"search_pinned"`
+	CollectivePinned     bool           `json:"collective_pinned"`
+	GoalSimilarityPinned bool           `json:"goal_similarity_pinned"`
+	Limits               AdaptiveLimits `json:"limits"`
+}
+
+// Thresholds returns the thresholds in use
+func (ac *AdaptiveConfig) Thresholds() AdaptiveThresholds {
+	collective := ac.GetCollectiveThreshold()
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return AdaptiveThresholds{
+		Search:               ac.searchThreshold,
+		Collective:           collective,
+		GoalSimilarity:       ac.goalSimilarityThreshold,
+		SearchPinned:         ac.limits.PinnedSearch > 0,
+		CollectivePinned:     ac.limits.PinnedCollective > 0,
+		GoalSimilarityPinned: ac.limits.PinnedGoalSimilarity > 0,
+		Limits:               ac.limits,
+	}
+}
+
+// AdaptiveThresholds reports the thresholds the next cycle will use
+func (e *Engine) AdaptiveThresholds() AdaptiveThresholds {
+	return e.adaptiveConfig.Thresholds()
+}
+
+// ThresholdRecord is the thresholds one cycle ran with
+type ThresholdRecord struct {
+	CycleID        int       `json:"cycle_id"`
+	StartTime      time.Time `json:"start_time"`
+	Search         float64   `json:"search"`
+	Collective     float64   `json:"collective"`
+	GoalSimilarity float64   `json:"goal_similarity"`
+}
+
+// AdaptiveThresholdHistory returns the thresholds of the last n cycles, newest first, so
+// a drift that starved retrieval can be seen
+func (e *Engine) AdaptiveThresholdHistory(ctx context.Context, n int) ([]ThresholdRecord, error) {
+	cycles, err := e.stateManager.RecentMetrics(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]ThresholdRecord, 0, len(cycles))
+	for _, c := range cycles {
+		history = append(history, ThresholdRecord{
+			CycleID:        c.CycleID,
+			StartTime:      c.StartTime,
+			Search:         c.SearchThreshold,
+			Collective:     c.CollectiveThreshold,
+			GoalSimilarity: c.GoalSimilarityThreshold,
+		})
+	}
+	return history, nil
+}
@@ -0,0 +1,144 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestAdaptiveSnapshotRoundTrip(t *testing.T) {
+	learned := NewAdaptiveConfig(0.30, 0.75, 60)
+	learned.searchThreshold = 0.40
+	learned.goalSimilarityThreshold = 0.85
+	learned.toolTimeout = 135
+	learned.recentGoalSuccessRate = 0.25
+	learned.goalSamples = 10
+	learned.updateCount = 42
+
+	raw, err := json.Marshal(learned.Snapshot())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	fresh := NewAdaptiveConfig(0.30, 0.75, 60)
+	snap, err := decodeAdaptiveSnapshot(raw, fresh.Snapshot())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if clamped := fresh.Restore(snap); len(clamped) != 0 {
+		t.Fatalf("unexpected clamping: %v", clamped)
+	}
+	if fresh.GetSearchThreshold() != 0.40 || fresh.GetGoalSimilarityThreshold() != 0.85 || fresh.GetToolTimeout() != 135 {
+		t.Errorf("thresholds not restored: %+v", fresh.Snapshot())
+	}
+	if fresh.recentGoalSuccessRate != 0.25 || fresh.goalSamples != 10 || fresh.updateCount != 42 {
+		t.Errorf("metrics not restored: %+v", fresh.Snapshot())
+	}
+	// Bases come from config, not the snapshot
+	if fresh.baseSearchThreshold != 0.30 {
+		t.Errorf("base changed by restore: %.2f", fresh.baseSearchThreshold)
+	}
+}
+
+func TestAdaptiveSnapshotClampsCorruptValues(t *testing.T) {
+	ac := NewAdaptiveConfig(0.30, 0.75, 60)
+	raw := []byte(`{"version": 1, "search_threshold": 7.5, "goal_similarity_threshold": 0.1,
+		"tool_timeout": 99999, "recent_goal_success_rate": -3, "update_count": -1}`)
+
+	snap, err := decodeAdaptiveSnapshot(raw, ac.Snapshot())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	clamped := ac.Restore(snap)
+	if len(clamped) != 5 {
+		t.Errorf("clamped %v, want 5 fields", clamped)
+	}
+	if ac.GetSearchThreshold() != maxSearchThreshold || ac.GetGoalSimilarityThreshold() != minGoalSimilarityThreshold ||
+		ac.GetToolTimeout() != maxAdaptiveToolTimeout || ac.recentGoalSuccessRate != 0 || ac.updateCount != 0 {
+		t.Errorf("values not clamped into range: %+v", ac.Snapshot())
+	}
+}
+
+func TestAdaptiveSnapshotVersionCompatibility(t *testing.T) {
+	ac := NewAdaptiveConfig(0.30, 0.75, 60)
+
+	// A blob written before a field existed keeps the current value for it
+	snap, err := decodeAdaptiveSnapshot([]byte(`{"version": 1, "search_threshold": 0.35}`), ac.Snapshot())
+	if err != nil {
+		t.Fatalf("decode old blob: %v", err)
+	}
+	if snap.SearchThreshold != 0.35 || snap.ToolTimeout != 60 || snap.RecentGoalSuccessRate != 0.5 {
+		t.Errorf("old blob decoded to %+v", snap)
+	}
+
+	// A newer blob's unknown fields are ignored
+	snap, err = decodeAdaptiveSnapshot([]byte(`{"version": 9, "search_threshold": 0.45, "future_field": [1, 2]}`), ac.Snapshot())
+	if err != nil {
+		t.Fatalf("decode newer blob: %v", err)
+	}
+	if snap.SearchThreshold != 0.45 {
+		t.Errorf("newer blob decoded to %+v", snap)
+	}
+
+	if _, err := decodeAdaptiveSnapshot([]byte(`not json`), ac.Snapshot()); err == nil {
+		t.Error("expected a corrupt blob to be rejected")
+	}
+}
+
+func TestAdaptiveLimitsBoundAndPinThresholds(t *testing.T) {
+	ac := NewAdaptiveConfig(0.30, 0.75, 60)
+	limits := DefaultAdaptiveLimits()
+	limits.SearchMax = 0.35
+	limits.GoalSimilarityMin = 0.80
+	ac.SetLimits(limits)
+
+	// A large memory store and poor success would push both thresholds past the bounds
+	ac.UpdateMetrics(context.Background(), &InternalState{CompletedGoals: []Goal{{Outcome: GoalOutcomeFailed}, {Outcome: GoalOutcomeSuccess}, {Outcome: GoalOutcomeSuccess}, {Outcome: GoalOutcomeSuccess}}}, 200000)
+	if ac.GetSearchThreshold() != 0.35 || ac.GetGoalSimilarityThreshold() != 0.80 {
+		t.Errorf("expected thresholds clamped to the bounds, got search %.2f, goal %.2f", ac.GetSearchThreshold(), ac.GetGoalSimilarityThreshold())
+	}
+	if ac.GetCollectiveThreshold() != 0.20 {
+		t.Errorf("expected the collective threshold capped at 0.20, got %.2f", ac.GetCollectiveThreshold())
+	}
+
+	limits.PinnedSearch = 0.12
+	limits.PinnedCollective = 0.10
+	ac.SetLimits(limits)
+	if ac.GetSearchThreshold() != 0.12 || ac.GetCollectiveThreshold() != 0.10 {
+		t.Fatalf("expected pins applied at once, got search %.2f, collective %.2f", ac.GetSearchThreshold(), ac.GetCollectiveThreshold())
+	}
+	ac.UpdateMetrics(context.Background(), &InternalState{}, 200000)
+	got := ac.Thresholds()
+	if got.Search != 0.12 || !got.SearchPinned || !got.CollectivePinned || got.GoalSimilarityPinned {
+		t.Errorf("expected the pinned search threshold kept through an update, got %+v", got)
+	}
+}
+
+func TestAdaptiveLimitsValidate(t *testing.T) {
+	if err := DefaultAdaptiveLimits().Validate(); err != nil {
+		t.Fatalf("defaults rejected: %v", err)
+	}
+	bad := DefaultAdaptiveLimits()
+	bad.SearchMin, bad.SearchMax = 0.6, 0.4
+	if bad.Validate() == nil {
+		t.Error("expected an inverted search range rejected")
+	}
+	bad = DefaultAdaptiveLimits()
+	bad.PinnedGoalSimilarity = 1.5
+	if bad.Validate() == nil {
+		t.Error("expected a pin above 1 rejected")
+	}
+}
+
+func TestRestoreClampsToConfiguredBounds(t *testing.T) {
+	ac := NewAdaptiveConfig(0.30, 0.75, 60)
+	limits := DefaultAdaptiveLimits()
+	limits.SearchMax = 0.40
+	ac.SetLimits(limits)
+
+	snap := ac.Snapshot()
+	snap.SearchThreshold = 0.60
+	if clamped := ac.Restore(snap); len(clamped) != 1 || ac.GetSearchThreshold() != 0.40 {
+		t.Errorf("expected the restored search threshold clamped to 0.40, got %.2f (%v)", ac.GetSearchThreshold(), clamped)
+	}
+}
@@ -11,19 +11,32 @@ import (
     "go-llama/internal/memory"
 )
 
-// runPhaseReflection executes the reflection phase
-func (e *Engine) runPhaseReflection(ctx context.Context, state *InternalState) (*ReasoningResponse, []memory.Principle, int, string, error) {
+// runPhaseReflection reflects on recent activity, generating the thoughts and learnings
+// that later goal proposals are derived from, and records the reflection as a thought
+// and a memory
+func (e *Engine) runPhaseReflection(ctx context.Context, cc *cycleContext) error {
+    state, metrics := cc.state, cc.metrics
     log.Printf("[Dialogue] PHASE 1: Enhanced Reflection")
 
+    // An overloaded reasoning model would hold the cycle for minutes; reflect next time
+    if cc.shedding {
+        log.Printf("[Dialogue] Skipping reflection: reasoning model overloaded")
+        cc.reasoning = &ReasoningResponse{}
+        return nil
+    }
+
     // Check context before expensive operation
     if ctx.Err() != nil {
-        return nil, nil, 0, "", fmt.Errorf("cycle cancelled before reflection: %w", ctx.Err())
+        return fmt.Errorf("cycle cancelled before reflection: %w", ctx.Err())
     }
 
-    reasoning, principles, tokens, err := e.performEnhancedReflection(ctx, state)
+    depth := e.reflectionDepth(ctx, state)
+    metrics.ReasoningDepth = depth
+    reasoning, principles, tokens, err := e.performEnhancedReflection(ctx, state, depth, metrics)
     if err != nil {
-        return nil, nil, 0, "", fmt.Errorf("reflection failed: %w", err)
+        return fmt.Errorf("reflection failed: %w", err)
     }
+    cc.reasoning, cc.principles, cc.reflection = reasoning, principles, reasoning.Reflection
 
     // Log reflection content
     if reasoning.Reflection == "" {
@@ -53,23 +66,41 @@ func (e *Engine) runPhaseReflection(ctx context.Context, state *InternalState) (
         }
         if len(reasoning.SelfAssessment.FocusAreas) > 0 {
             log.Printf("[Dialogue]   Focus Areas: %v", reasoning.SelfAssessment.FocusAreas)
+            e.recordFocusAreas(state, reasoning.SelfAssessment.FocusAreas)
         }
     }
 
+    // Notes are kept on the goal the reflection was shown
+    e.recordGoalNotes(pursuedGoal(state), reasoning.RawResponse)
+    e.askUser(ctx, pursuedGoal(state), reasoning.RawResponse)
+
     // Store learnings as memories if enabled
     if e.storeInsights && len(reasoning.Learnings.ToSlice()) > 0 {
         storedCount := 0
+        mergedCount := 0
         storedIDs := []string{}
         for _, learning := range reasoning.Learnings.ToSlice() {
-            memID, err := e.storeLearning(ctx, learning)
+            result, err := e.storeLearning(ctx, learning)
             if err != nil {
                 log.Printf("[Dialogue] ERROR: Failed to store learning: %v", err)
+            } else if result.Decision == memory.StoreDecisionMerged {
+                // The dedup path already kept it out of storage; the next reflection hears of it
+                mergedCount++
+                e.noteRepetition("learning", learning.What)
             } else {
                 storedCount++
-                storedIDs = append(storedIDs, memID)
+                storedIDs = append(storedIDs, result.MemoryID)
+                e.publishEvent(EventLearningStored, "", "", map[string]interface{}{
+                    "kind":      "learning",
+                    "memory_id": result.MemoryID,
+                    "category":  learning.Category,
+                })
             }
         }
-        log.Printf("[Dialogue] Stored %d/%d learnings in memory (collective=true)", storedCount, len(reasoning.Learnings))
+        metrics.MemoriesStored += storedCount
+        metrics.MemoriesMerged += mergedCount
+        log.Printf("[Dialogue] Stored %d/%d learnings in memory (collective=true, %d merged into existing)",
+            storedCount, len(reasoning.Learnings), mergedCount)
 
         // Give Qdrant time to index the new embeddings
         if storedCount > 0 {
@@ -88,11 +119,77 @@ func (e *Engine) runPhaseReflection(ctx context.Context, state *InternalState) (
         }
     }
 
-    return reasoning, principles, tokens, reasoning.Reflection, nil
+    cc.thoughts++
+    cc.tokens += tokens
+
+    // Save thought record to state file
+    novel := e.saveThought(ctx, &ThoughtRecord{
+        CycleID:     state.CycleCount,
+        ThoughtNum:  cc.thoughts,
+        Content:     cc.reflection,
+        TokensUsed:  tokens,
+        ActionTaken: false,
+        Timestamp:   time.Now(),
+    })
+    e.publishEvent(EventThoughtRecorded, "", "", map[string]interface{}{
+        "thought_num": cc.thoughts,
+        "tokens":      tokens,
+        "content":     truncate(cc.reflection, 500),
+    })
+
+    // The Goal Derivation Engine finds reflections by semantic search. A repeat of a
+    // recent reflection is already there.
+    if cc.reflection != "" && novel {
+        e.storeReflection(ctx, cc.reflection)
+    }
+    return nil
+}
+
+// storeReflection persists a reflection to memory for the Goal Derivation Engine
+func (e *Engine) storeReflection(ctx context.Context, reflection string) {
+    embedding, err := e.embedder.Embed(ctx, reflection)
+    if err != nil {
+        log.Printf("[Engine] ERROR: Failed to embed reflection, skipping storage: %v", err)
+        return
+    }
+
+    mem := &memory.Memory{
+        Content: reflection,
+        Metadata: map[string]interface{}{
+            "type":   "reflection",
+            "source": "autonomous_cycle",
+        },
+        Embedding:      embedding,
+        Tier:           memory.TierRecent,
+        IsCollective:   true,
+        SourceKind:     memory.SourceDialogueLearning,
+        CreatedAt:      time.Now(),
+        LastAccessedAt: time.Now(),
+    }
+    e.stampProvenance(ctx, mem, "", nil)
+
+    if err := e.storage.Store(ctx, mem); err != nil {
+        log.Printf("[Engine] Warning: Failed to store reflection in memory: %v", err)
+        return
+    }
+    log.Printf("[Engine] Persisted reflection to memory for Derivation Engine.")
+    e.publishEvent(EventLearningStored, "", "", map[string]interface{}{"kind": "reflection", "memory_id": mem.ID})
 }
 
-// runPhaseGoalManagement handles goal creation, validation, and metacognitive checks.
-func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalState, reasoning *ReasoningResponse, principles []memory.Principle, metrics *CycleMetrics, totalTokens *int) error {
+// runPhaseGoalManagement acts on the reflection: it records gaps, failures and
+// patterns, creates goals from its proposals (or exploratory goals when idle, looping
+// or failing), and runs the metacognitive principle checks. A cycle shedding load
+// skips it, as it did the reflection.
+func (e *Engine) runPhaseGoalManagement(ctx context.Context, cc *cycleContext) error {
+    if cc.shedding {
+        log.Printf("[Dialogue] Skipping goal management: reasoning model overloaded")
+        return nil
+    }
+    state, reasoning, principles, metrics := cc.state, cc.reasoning, cc.principles, cc.metrics
+    if reasoning == nil {
+        reasoning = &ReasoningResponse{}
+    }
+
     // Check for extended idle periods and trigger exploration
     if len(state.ActiveGoals) == 0 {
         timeSinceLastCycle := time.Since(state.LastCycleTime)
@@ -102,13 +199,14 @@ func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalStat
             log.Printf("[Dialogue] Extended idle period detected (%s), generating exploratory goal",
                 timeSinceLastCycle.Round(time.Minute))
 
-            userInterests, err := e.analyzeUserInterests(ctx)
+            userInterests, interestSources, err := e.analyzeUserInterests(ctx)
             if err != nil {
                 log.Printf("[Dialogue] WARNING: Failed to analyze user interests: %v", err)
                 userInterests = []string{}
             }
 
-            exploratoryGoal := e.generateExploratoryGoal(ctx, userInterests, "", []string{})
+            exploratoryGoal := e.generateExploratoryGoal(ctx, state.rng, userInterests, "", []string{})
+            tagGoalSources(&exploratoryGoal, interestSources)
             state.ActiveGoals = append(state.ActiveGoals, exploratoryGoal)
             metrics.GoalsCreated++
 
@@ -164,7 +262,7 @@ func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalStat
         log.Printf("[Dialogue] Meta-loop detected, switching to exploratory mode")
 
         // Get user interests for context
-        userInterests, err := e.analyzeUserInterests(ctx)
+        userInterests, interestSources, err := e.analyzeUserInterests(ctx)
         if err != nil {
             log.Printf("[Dialogue] WARNING: Failed to analyze user interests: %v", err)
             userInterests = []string{}
@@ -185,7 +283,8 @@ func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalStat
         }
 
         // Create exploratory goal
-        exploratoryGoal := e.generateExploratoryGoal(ctx, userInterests, loopTopic, recentGoalDescriptions)
+        exploratoryGoal := e.generateExploratoryGoal(ctx, state.rng, userInterests, loopTopic, recentGoalDescriptions)
+        tagGoalSources(&exploratoryGoal, interestSources)
 
         // Add to state immediately
         state.ActiveGoals = append(state.ActiveGoals, exploratoryGoal)
@@ -224,7 +323,7 @@ func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalStat
                 }
 
                 // Generate user-aligned goal
-                userGoal, err := e.GenerateUserAlignedGoal(ctx, userProfile, recentTopics)
+                userGoal, err := e.GenerateUserAlignedGoal(ctx, state.rng, userProfile, recentTopics)
                 if err != nil {
                     log.Printf("[Dialogue] WARNING: Failed to generate user-aligned goal: %v", err)
                 } else {
@@ -247,7 +346,7 @@ func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalStat
         log.Printf("[Dialogue] ⚠ CRITICAL: Goal success rate is %.2f (below %.2f). Halting LLM proposals to break failure loop.", e.adaptiveConfig.recentGoalSuccessRate, CRITICAL_SUCCESS_THRESHOLD)
 
         // Force exploratory goal based on user interests to reset context
-        userInterests, err := e.analyzeUserInterests(ctx)
+        userInterests, interestSources, err := e.analyzeUserInterests(ctx)
         if err != nil {
             log.Printf("[Dialogue] WARNING: Failed to analyze user interests for recovery: %v", err)
             userInterests = []string{}
@@ -263,77 +362,74 @@ func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalStat
             recentGoalDescriptions = append(recentGoalDescriptions, goal.Description)
         }
 
-        recoveryGoal := e.generateExploratoryGoal(ctx, userInterests, "system failure", recentGoalDescriptions)
+        recoveryGoal := e.generateExploratoryGoal(ctx, state.rng, userInterests, "system failure", recentGoalDescriptions)
+        tagGoalSources(&recoveryGoal, interestSources)
         newGoals = append(newGoals, recoveryGoal)
 
         log.Printf("[Dialogue] ✓ Created RECOVERY goal to stabilize system: %s", truncate(recoveryGoal.Description, 60))
 
+    } else if backlog, full := e.backlogFull(state); full && len(reasoning.GoalsToCreate.ToSlice()) > 0 {
+        // BACKPRESSURE: More actions are pending than cycles can work through soon
+        e.refuseGoalProposals(reasoning, backlog, metrics)
+
     } else if len(reasoning.GoalsToCreate.ToSlice()) > 0 && len(state.ActiveGoals) < 15 {
         log.Printf("[Dialogue] LLM proposed %d new goals", len(reasoning.GoalsToCreate))
 
-        // Get recently abandoned goals (last 10)
-        recentlyAbandoned := []Goal{}
-        if len(state.CompletedGoals) > 0 {
-            startIdx := len(state.CompletedGoals) - 10
-            if startIdx < 0 {
-                startIdx = 0
-            }
-            for i := startIdx; i < len(state.CompletedGoals); i++ {
-                if state.CompletedGoals[i].Status == GoalStatusAbandoned {
-                    recentlyAbandoned = append(recentlyAbandoned, state.CompletedGoals[i])
-                }
-            }
-        }
+        proposals, created := e.acceptGoalProposals(ctx, state, reasoning, metrics)
 
-        for _, proposal := range reasoning.GoalsToCreate.ToSlice() {
-            // Check for duplicates against active goals
-            if e.isGoalDuplicate(ctx, proposal.Description, state.ActiveGoals) {
-                log.Printf("[Dialogue] Skipping duplicate goal (matches active): %s", truncate(proposal.Description, 40))
+        // TIER VALIDATION: Secondary goals must link to a primary goal. They are
+        // validated together so the primary goals are sent once per batch, not per goal.
+        primaryGoals := e.getPrimaryGoals(state.ActiveGoals)
+        pending := []*Goal{}
+        for i := range created {
+            if created[i].Tier != "secondary" {
                 continue
             }
-
-            // Check for duplicates against recently abandoned goals
-            if len(recentlyAbandoned) > 0 && e.isGoalDuplicate(ctx, proposal.Description, recentlyAbandoned) {
-                log.Printf("[Dialogue] Skipping duplicate goal (matches recently abandoned): %s", truncate(proposal.Description, 40))
+            if len(primaryGoals) == 0 {
+                log.Printf("[Dialogue] No primary goals exist, promoting secondary to primary: %s",
+                    truncate(created[i].Description, 60))
+                created[i].Tier = "primary"
                 continue
             }
+            pending = append(pending, &created[i])
+        }
 
-            goal := e.createGoalFromProposal(proposal)
+        if len(pending) > 0 {
+            validations, errs, validationTokens := e.validateGoalSupportBatch(ctx, pending, primaryGoals)
+            cc.tokens += validationTokens
+            metrics.GoalValidationTokens += validationTokens
+            log.Printf("[Dialogue] Validated %d secondary goals (%d tokens)", len(pending), validationTokens)
 
-            // TIER VALIDATION: If secondary, validate linkage to primary goals
-            if goal.Tier == "secondary" {
-                primaryGoals := e.getPrimaryGoals(state.ActiveGoals)
-                if len(primaryGoals) == 0 {
-                    log.Printf("[Dialogue] No primary goals exist, promoting secondary to primary: %s",
+            for i, goal := range pending {
+                validation, err := validations[i], errs[i]
+                if err != nil {
+                    log.Printf("[Dialogue] WARNING: Failed to validate goal support: %v", err)
+                    // Allow goal but mark as unvalidated
+                    goal.DependencyScore = 0.5
+                } else if !validation.IsValid {
+                    log.Printf("[Dialogue] Secondary goal does not support any primary, converting to tactical: %s",
                         truncate(goal.Description, 60))
-                    goal.Tier = "primary"
+                    goal.Tier = "tactical"
+                    metrics.GoalProposalsDowngraded++
+                } else if err := NewGoalGraph(state).AddSupport(goal, validation.SupportsGoalID, validation.Confidence); err != nil {
+                    log.Printf("[Dialogue] Rejected support link (%v), converting to tactical: %s",
+                        err, truncate(goal.Description, 60))
+                    goal.Tier = "tactical"
+                    metrics.GoalProposalsDowngraded++
                 } else {
-                    // Validate linkage to at least one primary
-                    validation, err := e.validateGoalSupport(ctx, &goal, primaryGoals)
-                    if err != nil {
-                        log.Printf("[Dialogue] WARNING: Failed to validate goal support: %v", err)
-                        // Allow goal but mark as unvalidated
-                        goal.DependencyScore = 0.5
-                    } else if !validation.IsValid {
-                        log.Printf("[Dialogue] Secondary goal does not support any primary, converting to tactical: %s",
-                            truncate(goal.Description, 60))
-                        goal.Tier = "tactical"
-                    } else {
-                        // Link to primary
-                        goal.SupportsGoals = []string{validation.SupportsGoalID}
-                        goal.DependencyScore = validation.Confidence
-
-                        log.Printf("[Dialogue] Secondary goal validated: supports %s (confidence: %.2f)",
-                            truncate(validation.SupportsGoalID, 20), validation.Confidence)
-                        log.Printf("[Dialogue]   Reasoning: %s", truncate(validation.Reasoning, 80))
-                    }
+                    // Linked to primary through the support graph (cycle-checked)
+                    log.Printf("[Dialogue] Secondary goal validated: supports %s (confidence: %.2f)",
+                        truncate(validation.SupportsGoalID, 20), validation.Confidence)
+                    log.Printf("[Dialogue]   Reasoning: %s", truncate(validation.Reasoning, 80))
                 }
             }
+        }
 
+        for i, goal := range created {
             newGoals = append(newGoals, goal)
             log.Printf("[Dialogue] Created goal [%s]: %s (priority: %d)",
                 goal.Tier, truncate(goal.Description, 60), goal.Priority)
-            log.Printf("[Dialogue]   Reasoning: %s", truncate(proposal.Reasoning, 80))
+            log.Printf("[Dialogue]   Reasoning: %s", truncate(proposals[i].Reasoning, 80))
         }
     } else {
         // Fallback to old goal formation
@@ -348,12 +444,15 @@ func (e *Engine) runPhaseGoalManagement(ctx context.Context, state *InternalStat
 
     // NEW: Metacognitive evaluation - should we modify our thinking principles?
     if e.enableMetaLearning {
+        // Validate and commit self-modification goals proposed in earlier cycles
+        e.processSelfModificationGoals(ctx, state, principles)
+
         log.Printf("[Dialogue] Evaluating principle effectiveness (metacognitive check)...")
         principleFeedback, feedbackTokens, err := e.evaluatePrincipleEffectiveness(ctx, principles, state)
         if err != nil {
             log.Printf("[Dialogue] WARNING: Principle evaluation failed: %v", err)
         } else {
-            *totalTokens += feedbackTokens
+            cc.tokens += feedbackTokens
 
             if principleFeedback.ShouldModify {
                 log.Printf("[Dialogue] ✓ Principle modification recommended:")
@@ -410,7 +509,7 @@ func (e *Engine) reflectOnRecentActivity(ctx context.Context) (string, int, erro
     prompt += "\nProvide a brief 2-3 sentence reflection."
 
     // Call LLM
-    reflection, tokens, err := e.callLLM(ctx, prompt, true) // Use Simple Model
+    reflection, tokens, err := e.callLLM(ctx, prompt, CallReflection)
     if err != nil {
         return "", 0, fmt.Errorf("LLM call failed: %w", err)
     }
@@ -507,18 +606,7 @@ func (e *Engine) formGoals(state *InternalState) []Goal {
     ctx := context.Background()
 
     // Get recently abandoned goals for duplicate checking
-    recentlyAbandoned := []Goal{}
-    if len(state.CompletedGoals) > 0 {
-        startIdx := len(state.CompletedGoals) - 10
-        if startIdx < 0 {
-            startIdx = 0
-        }
-        for i := startIdx; i < len(state.CompletedGoals); i++ {
-            if state.CompletedGoals[i].Status == GoalStatusAbandoned {
-                recentlyAbandoned = append(recentlyAbandoned, state.CompletedGoals[i])
-            }
-        }
-    }
+    recentlyAbandoned := e.recentlyAbandonedGoals(ctx, state, 10)
 
     // Create goals from knowledge gaps (user requests)
     for _, gap := range state.KnowledgeGaps {
@@ -546,14 +634,14 @@ func (e *Engine) formGoals(state *InternalState) []Goal {
         }
 
         // Check for duplicates against active goals
-        if e.isGoalDuplicate(ctx, description, state.ActiveGoals) {
-            log.Printf("[Dialogue] Skipping duplicate goal (matches active): %s", truncate(description, 40))
+        if dup, _, why := e.isGoalDuplicate(ctx, description, state.ActiveGoals); dup {
+            log.Printf("[Dialogue] Skipping duplicate goal (matches active): %s: %s", truncate(description, 40), why)
             continue
         }
 
         // Check for duplicates against recently abandoned goals
-        if len(recentlyAbandoned) > 0 && e.isGoalDuplicate(ctx, description, recentlyAbandoned) {
-            log.Printf("[Dialogue] Skipping duplicate goal (matches recently abandoned): %s", truncate(description, 40))
+        if dup, _, why := e.isGoalDuplicate(ctx, description, recentlyAbandoned); dup {
+            log.Printf("[Dialogue] Skipping duplicate goal (matches recently abandoned): %s: %s", truncate(description, 40), why)
             continue
         }
 
@@ -605,156 +693,38 @@ func (e *Engine) formGoals(state *InternalState) []Goal {
     return goals
 }
 
-// isGoalDuplicate checks if a proposed goal is too similar to existing goals
-// Uses both string matching and semantic similarity
-func (e *Engine) isGoalDuplicate(ctx context.Context, proposalDesc string, existingGoals []Goal) bool {
-    proposalLower := strings.ToLower(proposalDesc)
-
-    // Quick check: exact match or very similar strings
-    for _, existingGoal := range existingGoals {
-        existingLower := strings.ToLower(existingGoal.Description)
-
-        // Exact match
-        if proposalLower == existingLower {
-            log.Printf("[Dialogue] Duplicate detected (exact match): '%s'", truncate(proposalDesc, 50))
-            return true
-        }
-
-        // Check if first 50 chars match (increased from 30 for better detection)
-        minLen := min(len(proposalLower), len(existingLower))
-        if minLen > 50 {
-            minLen = 50
-        }
-        if minLen >= 20 { // Only check if we have enough characters
-            if strings.Contains(existingLower, proposalLower[:minLen]) ||
-                strings.Contains(proposalLower, existingLower[:minLen]) {
-                log.Printf("[Dialogue] Duplicate detected (prefix match): '%s' ~= '%s'",
-                    truncate(proposalDesc, 40), truncate(existingGoal.Description, 40))
-                return true
-            }
-        }
-    }
-
-    // Keyword overlap detection (high precision, catches semantic duplicates)
-    proposalKeywords := extractSignificantKeywords(proposalDesc)
-    for _, existingGoal := range existingGoals {
-        existingKeywords := extractSignificantKeywords(existingGoal.Description)
-
-        // Calculate keyword overlap ratio
-        overlap := calculateKeywordOverlap(proposalKeywords, existingKeywords)
-
-        // If 80%+ keywords overlap, it's likely a duplicate (raised from 60% to allow more diversity)
-        if overlap >= 0.80 {
-            log.Printf("[Dialogue] Duplicate detected (keyword overlap %.0f%%): '%s' ~= '%s'",
-                overlap*100, truncate(proposalDesc, 40), truncate(existingGoal.Description, 40))
-            log.Printf("[Dialogue]   Proposal keywords: %v", proposalKeywords)
-            log.Printf("[Dialogue]   Existing keywords: %v", existingKeywords)
-            return true
-        }
-    }
-
-    // Semantic similarity check using embeddings
-    // Only check if we have at least 3 existing goals (avoid overhead for small lists)
-    if len(existingGoals) >= 3 {
-        proposalEmbedding, err := e.embedder.Embed(ctx, proposalDesc)
-        if err != nil {
-            log.Printf("[Dialogue] WARNING: Failed to generate embedding for duplicate check: %v", err)
-            return false // Don't block on embedding failure
-        }
-
-        // Compare with each existing goal
-        for _, existingGoal := range existingGoals {
-            existingEmbedding, err := e.embedder.Embed(ctx, existingGoal.Description)
-            if err != nil {
-                continue // Skip this comparison
-            }
-
-            // Calculate cosine similarity
-            similarity := cosineSimilarity(proposalEmbedding, existingEmbedding)
-
-            // Use adaptive threshold for duplicate detection (bounded between 0.70-0.80)
-            threshold := e.adaptiveConfig.GetGoalSimilarityThreshold()
-            // Cap threshold at 0.80 to allow reasonable variation (was 0.75)
-            if threshold > 0.80 {
-                threshold = 0.80
-            }
-            // But never go below 0.70 to still catch obvious duplicates
-            if threshold < 0.70 {
-                threshold = 0.70
-            }
-
-            log.Printf("[Dialogue] Using semantic similarity threshold: %.2f (adaptive: %.2f)",
-                threshold, e.adaptiveConfig.GetGoalSimilarityThreshold())
-
-            if similarity > threshold {
-                log.Printf("[Dialogue] Detected semantic duplicate (%.2f > %.2f threshold): '%s' ~= '%s'",
-                    similarity, threshold, truncate(proposalDesc, 40), truncate(existingGoal.Description, 40))
-                return true
-            }
-        }
-    }
-
-    return false
-}
-
-// analyzeUserInterests extracts topics the user has shown interest in
-func (e *Engine) analyzeUserInterests(ctx context.Context) ([]string, error) {
+// analyzeUserInterests extracts topics users have shown interest in, along with the
+// IDs of the users whose memories they came from. Only users who opted in are read.
+func (e *Engine) analyzeUserInterests(ctx context.Context) ([]string, []string, error) {
     // Search for user interactions (non-collective memories)
     embedding, err := e.embedder.Embed(ctx, "user questions topics interests discussion")
     if err != nil {
-        return []string{}, err
+        return []string{}, nil, err
     }
 
+    allowedUsers, _ := e.personalMemoryConsent()
     query := memory.RetrievalQuery{
         Limit:             20,
         MinScore:          0.3,
         IncludePersonal:   true,
         IncludeCollective: false, // Only user interactions
+        AllowedUserIDs:    allowedUsers,
     }
 
     results, err := e.storage.Search(ctx, query, embedding)
     if err != nil {
-        return []string{}, err
+        return []string{}, nil, err
     }
 
     if len(results) == 0 {
-        return []string{}, nil
-    }
-
-    // Extract concept tags from user memories
-    topicFrequency := make(map[string]int)
-    for _, result := range results {
-        for _, tag := range result.Memory.ConceptTags {
-            topicFrequency[tag]++
-        }
+        return []string{}, nil, nil
     }
 
-    // Sort by frequency
-    type topicCount struct {
-        topic string
-        count int
-    }
-    var topics []topicCount
-    for topic, count := range topicFrequency {
-        topics = append(topics, topicCount{topic, count})
-    }
+    // Weight concept tags by memory age, so recent interests outrank old ones, and
+    // leave out topics users asked to stop researching
+    result := rankInterests(results, time.Now(), e.interestHalfLife, e.suppressedTopics(ctx), 5)
 
-    // Sort descending by count
-    for i := 0; i < len(topics); i++ {
-        for j := i + 1; j < len(topics); j++ {
-            if topics[j].count > topics[i].count {
-                topics[i], topics[j] = topics[j], topics[i]
-            }
-        }
-    }
-
-    // Return top topics
-    result := []string{}
-    for i := 0; i < len(topics) && i < 5; i++ {
-        result = append(result, topics[i].topic)
-    }
-
-    return result, nil
+    return result, memory.PersonalSourceUserIDs(results), nil
 }
 
 // detectMetaLoop checks if system is stuck researching the same topic
@@ -800,7 +770,7 @@ func (e *Engine) detectMetaLoop(state *InternalState) (bool, string) {
 }
 
 // generateExploratoryGoal creates a curiosity-driven goal based on context
-func (e *Engine) generateExploratoryGoal(ctx context.Context, userInterests []string, avoidTopic string, recentGoalDescriptions []string) Goal {
+func (e *Engine) generateExploratoryGoal(ctx context.Context, rng *rand.Rand, userInterests []string, avoidTopic string, recentGoalDescriptions []string) Goal {
     var description string
     var priority int
 
@@ -828,6 +798,7 @@ func (e *Engine) generateExploratoryGoal(ctx context.Context, userInterests []st
         if len(candidates) == 0 {
             candidates = userInterests
         }
+        candidates = withoutSuppressed(candidates, e.suppressedTopics(ctx))
 
         // Pick a topic that hasn't been explored in recent goals
         selectedTopic := ""
@@ -859,7 +830,7 @@ func (e *Engine) generateExploratoryGoal(ctx context.Context, userInterests []st
                 fmt.Sprintf("Analyze how %s contributes to natural dialogue", selectedTopic),
             }
 
-            description = variations[rand.Intn(len(variations))]
+            description = variations[rng.Intn(len(variations))]
             priority = 6
 
             log.Printf("[Dialogue] Generated user-interest exploratory goal: %s", description)
@@ -878,7 +849,7 @@ func (e *Engine) generateExploratoryGoal(ctx context.Context, userInterests []st
             "Research how AI can develop and maintain a backstory",
         }
 
-        description = exploratoryTopics[rand.Intn(len(exploratoryTopics))]
+        description = exploratoryTopics[rng.Intn(len(exploratoryTopics))]
         priority = 5
 
         log.Printf("[Dialogue] Generated conversation-focused exploratory goal: %s", description)
@@ -898,12 +869,23 @@ func (e *Engine) generateExploratoryGoal(ctx context.Context, userInterests []st
 
 // thinkAboutGoal generates thoughts about pursuing a goal
 func (e *Engine) thinkAboutGoal(ctx context.Context, goal *Goal) (string, int, error) {
-    prompt := fmt.Sprintf("You are pursuing this goal: %s\n\nThink about how to approach this. What should you do next? Keep it brief (2-3 sentences).", goal.Description)
+    prompt := fmt.Sprintf("You are pursuing this goal: %s\n\n", goal.Description)
+    if notes := goalNotesContext(goal); notes != "" {
+        prompt += notes + "\n"
+    }
+    prompt += "Think about how to approach this. What should you do next? Keep it brief (2-3 sentences)."
 
-    thought, tokens, err := e.callLLM(ctx, prompt, true) // Use Simple Model
+    thought, tokens, err := e.callLLM(ctx, prompt, CallReflection)
     if err != nil {
         return "", 0, err
     }
 
+    e.saveThought(ctx, &ThoughtRecord{
+        CycleID:    int(e.currentCycle.Load()),
+        GoalID:     goal.ID,
+        Content:    thought,
+        TokensUsed: tokens,
+        Timestamp:  time.Now(),
+    })
     return thought, tokens, nil
 }
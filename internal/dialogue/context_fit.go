@@ -0,0 +1,169 @@
+// internal/dialogue/context_fit.go
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-llama/internal/goal"
+	"go-llama/internal/utils"
+)
+
+// ErrContextOverflow is returned when a request cannot be made to fit in the model's
+// context window, even after shrinking it
+var ErrContextOverflow = errors.New("request does not fit in the model's context window")
+
+const (
+	charsPerToken         = 4.0  // Prompt estimate before sending, as chat's sliding window uses
+	strictCharsPerToken   = 2.5  // Estimate after the server reported an overflow anyway
+	messageOverheadTokens = 16   // Chat template tokens around the system and user messages
+	contextSafetyTokens   = 64   // Headroom left for estimate error
+	minCompletionTokens   = 256  // max_tokens is not shrunk below this; the prompt is trimmed instead
+	minFittedPromptChars  = 1000 // A prompt trimmed shorter than this is not worth sending
+)
+
+// contextTrimMarker replaces the middle of a prompt trimmed to fit
+const contextTrimMarker = "\n\n[... %d characters omitted to fit the context window ...]\n\n"
+
+// isContextOverflow reports whether a call failed because the request did not fit.
+// The llm package marks these errors with a ContextOverflow method.
+func isContextOverflow(err error) bool {
+	var o interface{ ContextOverflow() bool }
+	return errors.Is(err, ErrContextOverflow) || (errors.As(err, &o) && o.ContextOverflow())
+}
+
+// estimateTokens estimates the prompt tokens of a system and user message
+func estimateTokens(system, prompt string, perToken float64) int {
+	return int(float64(len(system)+len(prompt))/perToken) + messageOverheadTokens
+}
+
+// fitToContext shrinks a request that would not fit in contextSize tokens. max_tokens
+// is reduced first, down to minCompletionTokens; past that the middle of the prompt,
+// where retrieved context sits between the task and the output format, is trimmed.
+// trimmed reports whether the prompt was cut. A contextSize <= 0 disables fitting.
+func fitToContext(contextSize int, perToken float64, system, prompt string, sampling Sampling) (string, Sampling, bool, error) {
+	if contextSize <= 0 {
+		return prompt, sampling, false, nil
+	}
+	budget := contextSize - contextSafetyTokens
+	promptTokens := estimateTokens(system, prompt, perToken)
+	available := budget - promptTokens
+	if sampling.MaxTokens > 0 && sampling.MaxTokens <= available {
+		return prompt, sampling, false, nil
+	}
+	if available >= minCompletionTokens {
+		sampling.MaxTokens = available
+		return prompt, sampling, false, nil
+	}
+
+	if sampling.MaxTokens <= 0 || sampling.MaxTokens > minCompletionTokens {
+		sampling.MaxTokens = minCompletionTokens
+	}
+	maxChars := int(float64(budget-sampling.MaxTokens-messageOverheadTokens)*perToken) - len(system)
+	if maxChars < minFittedPromptChars {
+		return prompt, sampling, false, fmt.Errorf("%w: about %d prompt tokens for a %d token context",
+			ErrContextOverflow, promptTokens, contextSize)
+	}
+	return trimMiddle(prompt, maxChars), sampling, true, nil
+}
+
+// trimMiddle cuts the middle of text so it is at most maxChars long, keeping the
+// start and end and cutting at line breaks where it can
+func trimMiddle(text string, maxChars int) string {
+	if len(text) <= maxChars {
+		return text
+	}
+	keep := maxChars - len(contextTrimMarker) - 10
+	if keep <= 0 {
+		return utils.Prefix(text, maxChars)
+	}
+	head := utils.Prefix(text, keep/2)
+	if i := strings.LastIndex(head, "\n"); i > len(head)/2 {
+		head = head[:i]
+	}
+	tail := utils.Suffix(text, keep-keep/2)
+	if i := strings.Index(tail, "\n"); i >= 0 && i < len(tail)/2 {
+		tail = tail[i+1:]
+	}
+	return head + fmt.Sprintf(contextTrimMarker, len(text)-len(head)-len(tail)) + tail
+}
+
+// contextSizeFor returns the context window of the model serving route, falling back to
+// the reasoning model's when it is unknown
+func (e *Engine) contextSizeFor(route ModelRoute) int {
+	if route.ContextSize > 0 {
+		return route.ContextSize
+	}
+	return e.contextSize
+}
+
+// chatRequest builds a non-streaming chat completion request
+func chatRequest(model, system, prompt string, sampling Sampling) map[string]interface{} {
+	reqBody := map[string]interface{}{
+		"model": model,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": prompt},
+		},
+		"stream": false,
+	}
+	sampling.apply(reqBody)
+	return reqBody
+}
+
+// completeWithinContext sends a chat completion fitted to the route's context window.
+// If the server still reports an overflow, the request is refitted with a stricter
+// token estimate and half the max_tokens, and retried once.
+func (e *Engine) completeWithinContext(ctx context.Context, client goal.LLMCaller, callType LLMCallType, route ModelRoute, system, prompt string, sampling Sampling) ([]byte, error) {
+	contextSize := e.contextSizeFor(route)
+	fitted, fittedSampling, trimmed, err := fitToContext(contextSize, charsPerToken, system, prompt, sampling)
+	if err != nil {
+		e.recordContextOverflow(callType)
+		return nil, err
+	}
+	if trimmed {
+		e.recordContextOverflow(callType)
+		log.Printf("[Dialogue] WARNING: %s prompt trimmed from %d to %d chars to fit the %d token context (max_tokens %d)",
+			callType, len(prompt), len(fitted), contextSize, fittedSampling.MaxTokens)
+	}
+
+	body, err := e.sendCompletion(ctx, client, callType, route.URL, chatRequest(route.Model, system, fitted, fittedSampling))
+	if !isContextOverflow(err) {
+		return body, err
+	}
+
+	if !trimmed {
+		e.recordContextOverflow(callType) // Counted once per call
+	}
+	retrySampling := fittedSampling
+	if retrySampling.MaxTokens > minCompletionTokens {
+		retrySampling.MaxTokens = max(retrySampling.MaxTokens/2, minCompletionTokens)
+	}
+	refitted, retrySampling, _, fitErr := fitToContext(contextSize, strictCharsPerToken, system, fitted, retrySampling)
+	if fitErr != nil {
+		return nil, fmt.Errorf("%w (server rejected the request: %v)", ErrContextOverflow, err)
+	}
+	if refitted == fitted && retrySampling.MaxTokens == fittedSampling.MaxTokens {
+		// Nothing left to shrink by estimate; cut a quarter of the prompt
+		refitted = trimMiddle(fitted, len(fitted)*3/4)
+	}
+	log.Printf("[Dialogue] WARNING: %s request overflowed the context, retrying with %d prompt chars and max_tokens %d",
+		callType, len(refitted), retrySampling.MaxTokens)
+
+	body, err = e.sendCompletion(ctx, client, callType, route.URL, chatRequest(route.Model, system, refitted, retrySampling))
+	if isContextOverflow(err) {
+		return nil, fmt.Errorf("%w (%s, after retrying smaller): %v", ErrContextOverflow, callType, err)
+	}
+	return body, err
+}
+
+// recordContextOverflow counts a call whose prompt had to be trimmed to fit the model's
+// context window, or that the server rejected as too long
+func (e *Engine) recordContextOverflow(callType LLMCallType) {
+	if e.modelRouter != nil {
+		e.modelRouter.RecordContextOverflow(callType)
+	}
+}
@@ -0,0 +1,116 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type overflowErr struct{}
+
+func (overflowErr) Error() string {
+	return "LLM returned status 400: request exceeds the model's context window"
+}
+func (overflowErr) ContextOverflow() bool { return true }
+
+// overflowCaller rejects requests as too long until its budget of overflows is spent,
+// recording each request's max_tokens and prompt length
+type overflowCaller struct {
+	overflows int
+	maxTokens []int
+	prompts   []int
+}
+
+func (c *overflowCaller) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	messages := payload["messages"].([]map[string]string)
+	c.maxTokens = append(c.maxTokens, payload["max_tokens"].(int))
+	c.prompts = append(c.prompts, len(messages[len(messages)-1]["content"]))
+	if len(c.maxTokens) <= c.overflows {
+		return nil, overflowErr{}
+	}
+	return json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"content": "done"}}},
+		"usage":   map[string]int{"total_tokens": 10},
+	})
+}
+
+func TestFitToContextReducesMaxTokensFirst(t *testing.T) {
+	prompt := strings.Repeat("x", 4000) // ~1000 tokens
+
+	_, sampling, trimmed, err := fitToContext(8192, charsPerToken, "", prompt, Sampling{MaxTokens: 512})
+	if err != nil || trimmed || sampling.MaxTokens != 512 {
+		t.Errorf("expected a fitting request unchanged, got %d max_tokens, trimmed=%v, %v", sampling.MaxTokens, trimmed, err)
+	}
+
+	fitted, sampling, trimmed, err := fitToContext(2048, charsPerToken, "", prompt, Sampling{MaxTokens: 2048})
+	if err != nil || trimmed || fitted != prompt {
+		t.Fatalf("expected only max_tokens reduced, got trimmed=%v, %v", trimmed, err)
+	}
+	if want := 2048 - contextSafetyTokens - estimateTokens("", prompt, charsPerToken); sampling.MaxTokens != want {
+		t.Errorf("expected max_tokens %d, got %d", want, sampling.MaxTokens)
+	}
+
+	if _, sampling, _, _ := fitToContext(0, charsPerToken, "", prompt, Sampling{MaxTokens: 99999}); sampling.MaxTokens != 99999 {
+		t.Errorf("expected no fitting without a context size, got %d", sampling.MaxTokens)
+	}
+}
+
+func TestFitToContextTrimsPromptMiddle(t *testing.T) {
+	prompt := "TASK: summarise\n" + strings.Repeat("context line\n", 1000) + "OUTPUT FORMAT: (answer)"
+	fitted, sampling, trimmed, err := fitToContext(2048, charsPerToken, "system", prompt, Sampling{MaxTokens: 2048})
+	if err != nil || !trimmed {
+		t.Fatalf("expected the prompt trimmed, got trimmed=%v, %v", trimmed, err)
+	}
+	if sampling.MaxTokens != minCompletionTokens {
+		t.Errorf("expected max_tokens lowered to %d, got %d", minCompletionTokens, sampling.MaxTokens)
+	}
+	if estimateTokens("system", fitted, charsPerToken)+sampling.MaxTokens > 2048-contextSafetyTokens {
+		t.Errorf("expected the trimmed request to fit, got %d prompt chars", len(fitted))
+	}
+	if !strings.HasPrefix(fitted, "TASK: summarise") || !strings.HasSuffix(fitted, "OUTPUT FORMAT: (answer)") || !strings.Contains(fitted, "omitted to fit the context window") {
+		t.Errorf("expected the start and end kept around a marker, got %q...%q", fitted[:40], fitted[len(fitted)-40:])
+	}
+
+	if _, _, _, err := fitToContext(512, charsPerToken, strings.Repeat("s", 2000), prompt, Sampling{}); !errors.Is(err, ErrContextOverflow) {
+		t.Errorf("expected ErrContextOverflow when the system prompt alone fills the context, got %v", err)
+	}
+}
+
+func TestCallLLMRetriesContextOverflowSmaller(t *testing.T) {
+	caller := &overflowCaller{overflows: 1}
+	e := &Engine{llmClient: caller, contextSize: 8192, modelRouter: NewModelRouter("http://reasoning", "8b", "", "")}
+
+	content, _, err := e.callLLM(context.Background(), "Reflect briefly.", CallReflection)
+	if err != nil || content != "done" {
+		t.Fatalf("expected the retry to succeed, got %q, %v", content, err)
+	}
+	if len(caller.maxTokens) != 2 || caller.maxTokens[1] >= caller.maxTokens[0] {
+		t.Errorf("expected one retry with fewer max_tokens, got %v", caller.maxTokens)
+	}
+	if got := e.ModelRoutingStats().ByCallType["reflection"]; got.Overflows != 1 {
+		t.Errorf("expected one overflow counted, got %+v", got)
+	}
+}
+
+func TestCallLLMGivesUpAfterOneOverflowRetry(t *testing.T) {
+	caller := &overflowCaller{overflows: 5}
+	e := &Engine{llmClient: caller, contextSize: 8192, modelRouter: NewModelRouter("http://reasoning", "8b", "", "")}
+
+	_, _, err := e.callLLM(context.Background(), "Reflect briefly.", CallReflection)
+	if !errors.Is(err, ErrContextOverflow) || len(caller.maxTokens) != 2 {
+		t.Errorf("expected ErrContextOverflow after one retry, got %v after %d calls", err, len(caller.maxTokens))
+	}
+}
+
+func TestRouteCarriesModelContextSize(t *testing.T) {
+	router := NewModelRouter("http://reasoning", "8b", "http://simple", "1b")
+	router.SetContextSizes(32768, 4096)
+	if got := router.Route(CallReflection).ContextSize; got != 4096 {
+		t.Errorf("expected the simple model's context, got %d", got)
+	}
+	if got := router.Route(CallSynthesis).ContextSize; got != 32768 {
+		t.Errorf("expected the reasoning model's context, got %d", got)
+	}
+}
@@ -0,0 +1,130 @@
+// internal/dialogue/cycle_lock.go
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCycleLeaseTTL is how long a cycle lease lasts without a heartbeat
+const DefaultCycleLeaseTTL = 60 * time.Second
+
+var (
+	// ErrCycleInProgress is returned when a tick finds another cycle still running
+	ErrCycleInProgress = errors.New("dialogue cycle already in progress")
+	// ErrStaleCycle is returned by SaveState when a newer cycle has claimed the state
+	ErrStaleCycle = errors.New("dialogue state claimed by a newer cycle")
+)
+
+// CycleLease is a lock shared by replicas, held under a token until released or its
+// TTL lapses without a renewal
+type CycleLease interface {
+	Acquire(ctx context.Context, token string, ttl time.Duration) (bool, error)
+	Renew(ctx context.Context, token string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, token string) error
+}
+
+// cycleLock is held for the length of one cycle
+type cycleLock struct {
+	token string
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// SetCycleLease makes cycles also take a lease shared with other replicas, renewed
+// every third of ttl while the cycle runs (default 60s)
+func (e *Engine) SetCycleLease(lease CycleLease, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCycleLeaseTTL
+	}
+	e.cycleLease = lease
+	e.cycleLeaseTTL = ttl
+}
+
+// acquireCycleLock takes the in-process guard, then the shared lease when there is one.
+// It returns ErrCycleInProgress while another cycle holds either.
+func (e *Engine) acquireCycleLock(ctx context.Context) (*cycleLock, error) {
+	if !e.cycleActive.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("%w in this process", ErrCycleInProgress)
+	}
+	lock := &cycleLock{token: uuid.NewString()}
+	if e.cycleLease == nil {
+		return lock, nil
+	}
+
+	acquired, err := e.cycleLease.Acquire(ctx, lock.token, e.cycleLeaseTTL)
+	if err != nil {
+		e.cycleActive.Store(false)
+		return nil, fmt.Errorf("failed to acquire cycle lease: %w", err)
+	}
+	if !acquired {
+		e.cycleActive.Store(false)
+		return nil, fmt.Errorf("%w on another replica", ErrCycleInProgress)
+	}
+	lock.stop = make(chan struct{})
+	lock.done = make(chan struct{})
+	go e.heartbeatCycleLease(lock)
+	return lock, nil
+}
+
+// heartbeatCycleLease renews the lease until the cycle ends. A lost lease is only
+// logged: the next holder claims the state, so this cycle's save is refused.
+func (e *Engine) heartbeatCycleLease(lock *cycleLock) {
+	defer close(lock.done)
+	ticker := time.NewTicker(e.cycleLeaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-lock.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), e.cycleLeaseTTL/3)
+			renewed, err := e.cycleLease.Renew(ctx, lock.token, e.cycleLeaseTTL)
+			cancel()
+			if err != nil {
+				log.Printf("[Dialogue] WARNING: failed to renew cycle lease: %v", err)
+				continue
+			}
+			if !renewed {
+				log.Printf("[Dialogue] WARNING: cycle lease lost; this cycle's state will not be saved if another cycle claims it")
+				return
+			}
+		}
+	}
+}
+
+// releaseCycleLock stops the heartbeat and frees the lease and the in-process guard
+func (e *Engine) releaseCycleLock(lock *cycleLock) {
+	if lock.stop != nil {
+		close(lock.stop)
+		<-lock.done
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := e.cycleLease.Release(ctx, lock.token); err != nil {
+			log.Printf("[Dialogue] WARNING: failed to release cycle lease: %v", err)
+		}
+		cancel()
+	}
+	e.cycleActive.Store(false)
+}
+
+// recordLockedCycle counts and reports a tick skipped because the cycle lock was held
+// or the lease could not be checked
+func (e *Engine) recordLockedCycle(err error) {
+	now := time.Now()
+
+	e.skipMu.Lock()
+	e.skipStats.Total++
+	e.skipStats.Locked++
+	e.skipStats.LastReason = err.Error()
+	e.skipStats.LastSkippedAt = &now
+	locked := e.skipStats.Locked
+	e.skipMu.Unlock()
+
+	log.Printf("[Dialogue] Skipping cycle: %v (%d skipped for the cycle lock so far)", err, locked)
+	e.publishEvent(EventCycleSkipped, "", "", map[string]interface{}{"reason": err.Error()})
+}
@@ -0,0 +1,175 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryLease is a CycleLease shared by engines standing in for replicas
+type memoryLease struct {
+	mu     sync.Mutex
+	holder string
+}
+
+func (l *memoryLease) Acquire(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder != "" {
+		return false, nil
+	}
+	l.holder = token
+	return true, nil
+}
+
+func (l *memoryLease) Renew(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder == token, nil
+}
+
+func (l *memoryLease) Release(ctx context.Context, token string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder == token {
+		l.holder = ""
+	}
+	return nil
+}
+
+// expire drops the lease as if its TTL lapsed
+func (l *memoryLease) expire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holder = ""
+}
+
+func TestOverlappingTickIsSkipped(t *testing.T) {
+	e := &Engine{}
+	lock, err := e.acquireCycleLock(context.Background())
+	if err != nil {
+		t.Fatalf("failed to take the cycle lock: %v", err)
+	}
+
+	// The overrunning cycle still holds the lock, so the next tick returns at once
+	if err := e.RunDialogueCycle(context.Background()); err != nil {
+		t.Fatalf("expected the tick skipped without error, got %v", err)
+	}
+	if stats := e.SkippedCycles(); stats.Locked != 1 || stats.Total != 1 || stats.LastReason == "" {
+		t.Errorf("expected one locked skip recorded, got %+v", stats)
+	}
+
+	e.releaseCycleLock(lock)
+	if lock, err = e.acquireCycleLock(context.Background()); err != nil {
+		t.Fatalf("expected the lock free after release, got %v", err)
+	}
+	e.releaseCycleLock(lock)
+}
+
+func TestCycleLeaseIsSharedByReplicas(t *testing.T) {
+	lease := &memoryLease{}
+	first, second := &Engine{}, &Engine{}
+	first.SetCycleLease(lease, time.Minute)
+	second.SetCycleLease(lease, time.Minute)
+
+	lock, err := first.acquireCycleLock(context.Background())
+	if err != nil {
+		t.Fatalf("failed to take the lease: %v", err)
+	}
+	if _, err := second.acquireCycleLock(context.Background()); !errors.Is(err, ErrCycleInProgress) {
+		t.Fatalf("expected the other replica refused, got %v", err)
+	}
+	if second.cycleActive.Load() {
+		t.Error("expected a refused lease to free the in-process guard")
+	}
+
+	first.releaseCycleLock(lock)
+	if lease.holder != "" {
+		t.Error("expected the lease released")
+	}
+	lock, err = second.acquireCycleLock(context.Background())
+	if err != nil {
+		t.Fatalf("expected the lease free after release, got %v", err)
+	}
+	second.releaseCycleLock(lock)
+}
+
+func TestOverrunningCycleLosesNoUpdates(t *testing.T) {
+	ctx := context.Background()
+	sm := NewStateManager(setupProvenanceDB(t))
+	lease := &memoryLease{}
+	slow, other := &Engine{stateManager: sm}, &Engine{stateManager: sm}
+	slow.SetCycleLease(lease, time.Minute)
+	other.SetCycleLease(lease, time.Minute)
+
+	// runCycle claims and loads the state like RunDialogueCycle, then adds a goal
+	runCycle := func(e *Engine, lock *cycleLock, goalID string) *InternalState {
+		if err := sm.ClaimState(ctx, lock.token); err != nil {
+			t.Fatalf("failed to claim state: %v", err)
+		}
+		state, err := sm.LoadState(ctx)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		state.owner = lock.token
+		state.CycleCount++
+		state.ActiveGoals = append(state.ActiveGoals, Goal{ID: goalID, Status: GoalStatusActive})
+		return state
+	}
+
+	slowLock, err := slow.acquireCycleLock(ctx)
+	if err != nil {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+	slowState := runCycle(slow, slowLock, "goal_slow")
+
+	// While the slow cycle overruns, the next tick on either replica is refused
+	if _, err := other.acquireCycleLock(ctx); !errors.Is(err, ErrCycleInProgress) {
+		t.Fatalf("expected the overlapping tick refused, got %v", err)
+	}
+	if err := sm.SaveState(ctx, slowState); err != nil {
+		t.Fatalf("failed to save the slow cycle: %v", err)
+	}
+	slow.releaseCycleLock(slowLock)
+
+	otherLock, err := other.acquireCycleLock(ctx)
+	if err != nil {
+		t.Fatalf("failed to take the lock after release: %v", err)
+	}
+	if err := sm.SaveState(ctx, runCycle(other, otherLock, "goal_next")); err != nil {
+		t.Fatalf("failed to save the next cycle: %v", err)
+	}
+	other.releaseCycleLock(otherLock)
+
+	state, err := sm.LoadState(ctx)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if state.CycleCount != 2 || len(state.ActiveGoals) != 2 {
+		t.Fatalf("expected both cycles' updates, got cycle %d with goals %+v", state.CycleCount, state.ActiveGoals)
+	}
+
+	// A cycle whose lease lapsed mid-run cannot overwrite the cycle that took over
+	staleLock, _ := slow.acquireCycleLock(ctx)
+	staleState := runCycle(slow, staleLock, "goal_stale")
+	lease.expire()
+	newLock, err := other.acquireCycleLock(ctx)
+	if err != nil {
+		t.Fatalf("expected the lapsed lease taken over, got %v", err)
+	}
+	if err := sm.SaveState(ctx, runCycle(other, newLock, "goal_new")); err != nil {
+		t.Fatalf("failed to save the new owner's cycle: %v", err)
+	}
+	if err := sm.SaveState(ctx, staleState); !errors.Is(err, ErrStaleCycle) {
+		t.Fatalf("expected the stale cycle refused, got %v", err)
+	}
+	other.releaseCycleLock(newLock)
+	slow.releaseCycleLock(staleLock)
+
+	state, _ = sm.LoadState(ctx)
+	if state.CycleCount != 3 || len(state.ActiveGoals) != 3 || state.ActiveGoals[2].ID != "goal_new" {
+		t.Errorf("expected the new owner's state kept, got cycle %d with goals %+v", state.CycleCount, state.ActiveGoals)
+	}
+}
@@ -0,0 +1,141 @@
+// internal/dialogue/cycle_phases.go
+package dialogue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-llama/internal/memory"
+)
+
+// cycleContext is what a dialogue cycle's phases share: the state they change, the
+// metrics they record into, what they have spent and the reflection's output
+type cycleContext struct {
+	state   *InternalState
+	metrics *CycleMetrics
+
+	thoughts int  // Thoughts recorded so far
+	tokens   int  // Tokens spent so far
+	shedding bool // Reasoning model overloaded: only planned actions run
+
+	// Set by the reflection phase
+	reasoning  *ReasoningResponse
+	principles []memory.Principle
+	reflection string
+}
+
+// cyclePhase is one step of a dialogue cycle. A phase logs and absorbs failures it can
+// work around; an error it returns ends the cycle.
+type cyclePhase struct {
+	name string
+	run  func(ctx context.Context, cc *cycleContext) error
+}
+
+// cyclePhases lists the phases of a dialogue cycle in the order they run. The goal
+// orchestrator pursues its goals first, the reflection it feeds follows, and goal
+// management acts on the reflection's proposals last.
+func (e *Engine) cyclePhases() []cyclePhase {
+	return []cyclePhase{
+		{name: "maintenance", run: e.runPhaseMaintenance},
+		{name: "goal_pursuit", run: e.runPhaseGoalPursuit},
+		{name: "reflection", run: e.runPhaseReflection},
+		{name: "goal_management", run: e.runPhaseGoalManagement},
+	}
+}
+
+// runDialoguePhases executes the dialogue phases with safety mechanisms
+func (e *Engine) runDialoguePhases(ctx context.Context, state *InternalState, metrics *CycleMetrics) (string, error) {
+	cc := &cycleContext{state: state, metrics: metrics}
+	e.shedLoad(cc)
+	return e.runPhases(ctx, cc, e.cyclePhases())
+}
+
+// runPhases runs phases in order and decides why the cycle stopped. Before each phase
+// after the first, and after the last, the cycle stops if a budget has run out. A
+// phase failing because the cycle ran out of time is recorded as a timeout rather than
+// failing the cycle.
+func (e *Engine) runPhases(ctx context.Context, cc *cycleContext, phases []cyclePhase) (string, error) {
+	for i, phase := range phases {
+		if i > 0 {
+			if reason := e.budgetStopReason(ctx, cc.thoughts, cc.tokens); reason != "" {
+				return e.stopCycle(cc, reason), nil
+			}
+		}
+		if err := phase.run(ctx, cc); err != nil {
+			if ctx.Err() != nil {
+				log.Printf("[Dialogue] Phase %s cut short by cycle timeout: %v", phase.name, err)
+				return e.stopCycle(cc, StopReasonTimeout), nil
+			}
+			return StopReasonNaturalStop, err
+		}
+	}
+
+	// A budget the last phase exhausted is what ended the cycle
+	if reason := e.budgetStopReason(ctx, cc.thoughts, cc.tokens); reason != "" {
+		return e.stopCycle(cc, reason), nil
+	}
+	return e.stopCycle(cc, StopReasonNaturalStop), nil
+}
+
+// stopCycle records how far the cycle got, so metrics show it whatever the reason
+func (e *Engine) stopCycle(cc *cycleContext, reason string) string {
+	metrics := cc.metrics
+	metrics.ThoughtCount = cc.thoughts
+	metrics.TokensUsed = cc.tokens
+	if reason != StopReasonNaturalStop {
+		log.Printf("[Dialogue] Stopping early (%s): %d/%d thoughts, %d/%d tokens, %s elapsed of %s",
+			reason, cc.thoughts, metrics.ThoughtLimit, cc.tokens, metrics.TokenLimit,
+			time.Since(metrics.StartTime).Round(time.Second), metrics.DurationLimit)
+	}
+	return reason
+}
+
+// runPhaseMaintenance decays principle confidence, settles finished goals and expires
+// old focus areas
+func (e *Engine) runPhaseMaintenance(ctx context.Context, cc *cycleContext) error {
+	if err := memory.ApplyConfidenceDecay(e.db); err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to apply principle decay: %v", err)
+	}
+
+	// Finished goals leave the active list; a finished primary first re-evaluates its
+	// secondaries. This runs every cycle, however soon a budget ends it.
+	e.settleFinishedGoals(ctx, cc.state, &cc.tokens)
+
+	// Focus areas from earlier self-assessments steer this cycle until they expire
+	e.expireFocusAreas(cc.state)
+
+	// Goals whose question went unanswered too long carry on without the answer
+	e.expireUserQuestions(ctx, cc.state, time.Now())
+	return nil
+}
+
+// runPhaseGoalPursuit hands the cycle to the goal orchestrator, which validates,
+// selects and executes goals and calls back into the engine to run tools, then checks
+// the plan of every research goal that made progress. A full action backlog first
+// switches selection to shortest job first, when configured.
+func (e *Engine) runPhaseGoalPursuit(ctx context.Context, cc *cycleContext) error {
+	e.applyBackpressure(cc.state, cc.metrics)
+	if e.goalOrchestrator != nil {
+		// Connect the bridge for this cycle
+		e.goalOrchestrator.SetExecutor(e)
+		e.goalOrchestrator.SetOverdueNotifier(e)
+		if e.toolRegistry != nil {
+			// Tools can be registered at runtime, so validate against the current set
+			e.goalOrchestrator.SetAvailableTools(toolNames(e.toolRegistry))
+		}
+
+		if err := e.goalOrchestrator.ExecuteCycle(ctx); err != nil {
+			log.Printf("[Dialogue] Goal Cycle Error: %v", err)
+		}
+	} else {
+		log.Printf("[Dialogue] WARNING: GoalOrchestrator not initialized")
+	}
+
+	// Progress assessments ask the reasoning model; they wait for a cycle that isn't shedding
+	if !cc.shedding {
+		cc.tokens += e.assessResearchGoals(ctx, cc.state, cc.metrics)
+	}
+	cc.metrics.ActionCount = 0 // Action counting is internal to the orchestrator
+	return nil
+}
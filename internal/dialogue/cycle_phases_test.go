@@ -0,0 +1,99 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// spendingPhase records that it ran and spends the given thoughts and tokens
+func spendingPhase(name string, ran *[]string, thoughts, tokens int) cyclePhase {
+	return cyclePhase{name: name, run: func(ctx context.Context, cc *cycleContext) error {
+		*ran = append(*ran, name)
+		cc.thoughts += thoughts
+		cc.tokens += tokens
+		return nil
+	}}
+}
+
+func TestRunPhasesInOrderToANaturalStop(t *testing.T) {
+	e := &Engine{maxThoughtsPerCycle: 3, maxTokensPerCycle: 1000}
+	metrics := &CycleMetrics{StartTime: time.Now()}
+	var ran []string
+
+	reason, err := e.runPhases(context.Background(), &cycleContext{state: &InternalState{}, metrics: metrics}, []cyclePhase{
+		spendingPhase("maintenance", &ran, 0, 0),
+		spendingPhase("goal_pursuit", &ran, 0, 300),
+		spendingPhase("reflection", &ran, 1, 200),
+	})
+	if err != nil || reason != StopReasonNaturalStop {
+		t.Fatalf("expected a natural stop, got %q (%v)", reason, err)
+	}
+	if strings.Join(ran, ",") != "maintenance,goal_pursuit,reflection" {
+		t.Errorf("expected every phase in order, got %v", ran)
+	}
+	if metrics.ThoughtCount != 1 || metrics.TokensUsed != 500 {
+		t.Errorf("expected 1 thought and 500 tokens recorded, got %d and %d", metrics.ThoughtCount, metrics.TokensUsed)
+	}
+}
+
+func TestRunPhasesStopsBetweenPhasesWhenABudgetRunsOut(t *testing.T) {
+	e := &Engine{maxThoughtsPerCycle: 3, maxTokensPerCycle: 1000}
+	metrics := &CycleMetrics{StartTime: time.Now()}
+	var ran []string
+
+	reason, err := e.runPhases(context.Background(), &cycleContext{state: &InternalState{}, metrics: metrics}, []cyclePhase{
+		spendingPhase("goal_pursuit", &ran, 0, 1200),
+		spendingPhase("reflection", &ran, 1, 200),
+	})
+	if err != nil || reason != StopReasonMaxTokens {
+		t.Fatalf("expected the token budget to stop the cycle, got %q (%v)", reason, err)
+	}
+	if len(ran) != 1 || metrics.TokensUsed != 1200 {
+		t.Errorf("expected reflection skipped after 1200 tokens, ran %v with %d tokens", ran, metrics.TokensUsed)
+	}
+
+	// The last phase's spending is checked too
+	ran = nil
+	reason, _ = e.runPhases(context.Background(), &cycleContext{state: &InternalState{}, metrics: metrics}, []cyclePhase{
+		spendingPhase("reflection", &ran, 3, 100),
+	})
+	if reason != StopReasonMaxThoughts {
+		t.Errorf("expected the thought limit reached by the last phase, got %q", reason)
+	}
+}
+
+func TestRunPhasesTimeoutVersusFailure(t *testing.T) {
+	e := &Engine{}
+	failing := cyclePhase{name: "reflection", run: func(ctx context.Context, cc *cycleContext) error {
+		cc.thoughts++
+		return errors.New("reflection failed: model unavailable")
+	}}
+
+	// A phase failing with time left fails the cycle
+	_, err := e.runPhases(context.Background(), &cycleContext{state: &InternalState{}, metrics: &CycleMetrics{}}, []cyclePhase{failing})
+	if err == nil || !strings.Contains(err.Error(), "model unavailable") {
+		t.Errorf("expected the phase error, got %v", err)
+	}
+
+	// The same failure after the deadline is the cycle timing out
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	metrics := &CycleMetrics{}
+	reason, err := e.runPhases(expired, &cycleContext{state: &InternalState{}, metrics: metrics}, []cyclePhase{failing})
+	if err != nil || reason != StopReasonTimeout || metrics.ThoughtCount != 1 {
+		t.Errorf("expected a recorded timeout, got %q (%v) with %d thoughts", reason, err, metrics.ThoughtCount)
+	}
+}
+
+func TestCyclePhaseOrder(t *testing.T) {
+	var names []string
+	for _, phase := range (&Engine{}).cyclePhases() {
+		names = append(names, phase.name)
+	}
+	if strings.Join(names, ",") != "maintenance,goal_pursuit,reflection,goal_management" {
+		t.Errorf("unexpected cycle phases %v", names)
+	}
+}
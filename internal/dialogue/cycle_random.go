@@ -0,0 +1,28 @@
+// internal/dialogue/cycle_random.go
+package dialogue
+
+import (
+	"math/rand"
+	"time"
+)
+
+// SetCycleSeed makes every cycle seed its random choices (exploratory topics, goal
+// wording) with seed, so a cycle recorded with that seed can be replayed. 0 restores
+// a time-based seed per cycle.
+func (e *Engine) SetCycleSeed(seed int64) {
+	e.fixedSeed = seed
+}
+
+// cycleSeed returns the seed for the next cycle's random choices
+func (e *Engine) cycleSeed() int64 {
+	if e.fixedSeed != 0 {
+		return e.fixedSeed
+	}
+	return time.Now().UnixNano()
+}
+
+// newRand returns a generator of its own, so concurrent cycles and tests don't share
+// the global source
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
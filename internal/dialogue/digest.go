@@ -0,0 +1,366 @@
+// internal/dialogue/digest.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go-llama/internal/memory"
+	"go-llama/internal/tools"
+)
+
+// DigestTag marks periodic digest memories in ConceptTags
+const DigestTag = "daily_digest"
+
+// Digest frequencies (config: dialogue.digest_frequency)
+const (
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+	DigestFrequencyOff    = "off"
+)
+
+// digestCheckInterval is how often the worker checks whether a digest is due
+const digestCheckInterval = time.Hour
+
+// digestPeriod returns the span one digest covers, or 0 when digests are disabled
+func digestPeriod(frequency string) time.Duration {
+	switch strings.ToLower(strings.TrimSpace(frequency)) {
+	case DigestFrequencyDaily, "":
+		return 24 * time.Hour
+	case DigestFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// digestActivity is everything that happened in a digest period
+type digestActivity struct {
+	Cycles         int
+	Thoughts       int
+	Actions        int
+	GoalsCreated   int
+	GoalsCompleted int
+	MemoriesStored int
+	CompletedGoals []Goal
+	Learnings      []memory.Memory
+}
+
+// empty reports whether there is nothing worth summarizing
+func (a digestActivity) empty() bool {
+	return a.Cycles == 0 && len(a.CompletedGoals) == 0 && len(a.Learnings) == 0
+}
+
+// GenerateDigest summarizes activity between since and until into a single
+// high-importance collective memory. It returns nil without storing anything
+// when the period had no activity.
+func (e *Engine) GenerateDigest(ctx context.Context, frequency string, since, until time.Time) (*memory.Memory, error) {
+	activity, err := e.gatherDigestActivity(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+	if activity.empty() {
+		log.Printf("[Digest] No activity between %s and %s, skipping digest",
+			since.Format(time.RFC3339), until.Format(time.RFC3339))
+		return nil, nil
+	}
+
+	response, _, err := e.callLLM(ctx, buildDigestPrompt(activity, since, until), CallReflection)
+	if err != nil {
+		return nil, fmt.Errorf("digest LLM call failed: %w", err)
+	}
+	summary, takeaways := parseDigestResponse(response)
+	if summary == "" {
+		return nil, fmt.Errorf("digest response contained no summary")
+	}
+
+	content := fmt.Sprintf("DIGEST [%s, %s]: %s", frequency, until.Format("2006-01-02"), summary)
+	if len(takeaways) > 0 {
+		content += "\nKey takeaways:\n- " + strings.Join(takeaways, "\n- ")
+	}
+
+	embedding, err := e.embedder.Embed(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed digest: %w", err)
+	}
+
+	now := time.Now()
+	mem := &memory.Memory{
+		Content:         content,
+		ImportanceScore: 0.9,
+		IsCollective:    true,
+		SourceKind:      memory.SourceDigest,
+		ConceptTags:     []string{DigestTag, "learning"},
+		OutcomeTag:      "good",
+		ValidationCount: 1,
+		TrustScore:      0.8,
+		Tier:            memory.TierRecent,
+		CreatedAt:       now,
+		LastAccessedAt:  now,
+		Embedding:       embedding,
+		Metadata: map[string]interface{}{
+			"digest_date":      until.Format("2006-01-02"),
+			"digest_frequency": frequency,
+			"period_start":     since.Format(time.RFC3339),
+			"period_end":       until.Format(time.RFC3339),
+			"takeaways":        strings.Join(takeaways, "\n"), // Payload conversion keeps scalars only
+			"cycles":           activity.Cycles,
+			"goals_completed":  len(activity.CompletedGoals),
+			"learnings":        len(activity.Learnings),
+		},
+	}
+
+	goalIDs := make([]string, len(activity.CompletedGoals))
+	for i, g := range activity.CompletedGoals {
+		goalIDs[i] = g.ID
+	}
+	if ids := nonEmpty(goalIDs); len(ids) > 0 {
+		mem.Metadata[MetadataGoalIDs] = strings.Join(ids, ",")
+	}
+	e.stampProvenance(ctx, mem, "", nil)
+
+	if err := e.storage.Store(ctx, mem); err != nil {
+		return nil, fmt.Errorf("failed to store digest: %w", err)
+	}
+
+	log.Printf("[Digest] ✓ Stored %s digest for %s (%d cycles, %d goals, %d learnings)",
+		frequency, until.Format("2006-01-02"), activity.Cycles, len(activity.CompletedGoals), len(activity.Learnings))
+	e.publishEvent(EventLearningStored, "", "", map[string]interface{}{
+		"kind":      "digest",
+		"memory_id": mem.ID,
+	})
+	return mem, nil
+}
+
+// gatherDigestActivity collects cycle metrics, goals finished and learnings stored in the period
+func (e *Engine) gatherDigestActivity(ctx context.Context, since, until time.Time) (digestActivity, error) {
+	var activity digestActivity
+
+	var cycles []DialogueMetrics
+	if err := e.db.WithContext(ctx).
+		Where("start_time >= ? AND start_time < ?", since, until).
+		Find(&cycles).Error; err != nil {
+		return activity, fmt.Errorf("failed to load cycle metrics: %w", err)
+	}
+	for _, c := range cycles {
+		activity.Cycles++
+		activity.Thoughts += c.ThoughtCount
+		activity.Actions += c.ActionCount
+		activity.GoalsCreated += c.GoalsCreated
+		activity.GoalsCompleted += c.GoalsCompleted
+		activity.MemoriesStored += c.MemoriesStored
+	}
+
+	// Goals carry no completion timestamp; LastPursued is the closest signal
+	if state, err := e.stateManager.LoadState(ctx); err == nil {
+		for _, g := range state.CompletedGoals {
+			if !g.LastPursued.Before(since) && g.LastPursued.Before(until) {
+				activity.CompletedGoals = append(activity.CompletedGoals, g)
+			}
+		}
+	} else {
+		log.Printf("[Digest] WARNING: Failed to load state for completed goals: %v", err)
+	}
+
+	embedding, err := e.embedder.Embed(ctx, "recent learnings insights knowledge")
+	if err != nil {
+		return activity, fmt.Errorf("failed to generate embedding: %w", err)
+	}
+	results, err := e.storage.Search(ctx, memory.RetrievalQuery{
+		Limit:             50,
+		IncludeCollective: true,
+		ConceptTags:       []string{"learning"},
+	}, embedding)
+	if err != nil {
+		return activity, fmt.Errorf("failed to search learnings: %w", err)
+	}
+	for _, r := range results {
+		if isDigest(r.Memory) || r.Memory.CreatedAt.Before(since) || !r.Memory.CreatedAt.Before(until) {
+			continue
+		}
+		activity.Learnings = append(activity.Learnings, r.Memory)
+	}
+
+	return activity, nil
+}
+
+// buildDigestPrompt asks for one paragraph and exactly three takeaways in a line format
+func buildDigestPrompt(activity digestActivity, since, until time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Summarize GrowerAI's activity from %s to %s.\n\n",
+		since.Format("2006-01-02 15:04"), until.Format("2006-01-02 15:04")))
+
+	sb.WriteString(fmt.Sprintf("ACTIVITY: %d cycles, %d thoughts, %d actions, %d goals created, %d goals completed, %d memories stored\n",
+		activity.Cycles, activity.Thoughts, activity.Actions, activity.GoalsCreated, activity.GoalsCompleted, activity.MemoriesStored))
+
+	if len(activity.CompletedGoals) > 0 {
+		sb.WriteString("\nFINISHED GOALS:\n")
+		for i, g := range activity.CompletedGoals {
+			sb.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, g.Status, truncate(g.Description, 120)))
+		}
+	}
+
+	if len(activity.Learnings) > 0 {
+		// Learnings can quote parsed web content, so they are contained like any tool output
+		var learnings strings.Builder
+		for i, l := range activity.Learnings {
+			if i >= 20 {
+				learnings.WriteString(fmt.Sprintf("... and %d more\n", len(activity.Learnings)-i))
+				break
+			}
+			learnings.WriteString(fmt.Sprintf("%d. %s\n", i+1, truncate(l.Content, 160)))
+		}
+		wrapped, _ := tools.WrapUntrusted("stored learnings", learnings.String())
+		sb.WriteString("\nLEARNINGS STORED:\n" + wrapped + "\n")
+	}
+
+	sb.WriteString(`
+Write a high-level narrative: what was the focus, what was concluded, what remains open.
+Respond in exactly this format:
+DIGEST: <one paragraph>
+TAKEAWAY: <key takeaway 1>
+TAKEAWAY: <key takeaway 2>
+TAKEAWAY: <key takeaway 3>`)
+	return sb.String()
+}
+
+// parseDigestResponse extracts the summary paragraph and up to three takeaways.
+// If the DIGEST marker is missing, the text before the first takeaway is used.
+func parseDigestResponse(response string) (string, []string) {
+	var summary []string
+	takeaways := []string{}
+	inSummary := true
+
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(strings.ReplaceAll(line, "**", ""))
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "TAKEAWAY"):
+			inSummary = false
+			if idx := strings.Index(trimmed, ":"); idx >= 0 {
+				if t := strings.TrimSpace(trimmed[idx+1:]); t != "" && len(takeaways) < 3 {
+					takeaways = append(takeaways, t)
+				}
+			}
+		case strings.HasPrefix(upper, "DIGEST:"):
+			inSummary = true
+			summary = append(summary, strings.TrimSpace(trimmed[len("DIGEST:"):]))
+		case inSummary && trimmed != "":
+			summary = append(summary, trimmed)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(summary, " ")), takeaways
+}
+
+// isDigest reports whether a memory is a periodic digest
+func isDigest(m memory.Memory) bool {
+	for _, tag := range m.ConceptTags {
+		if tag == DigestTag {
+			return true
+		}
+	}
+	return false
+}
+
+// recentDigests returns up to limit digest memories, newest first
+func (e *Engine) recentDigests(ctx context.Context, limit int) ([]memory.Memory, error) {
+	embedding, err := e.embedder.Embed(ctx, "digest summary of recent focus and conclusions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+	}
+	// Digests are few; over-fetch so recency, not similarity, decides which are returned
+	results, err := e.storage.Search(ctx, memory.RetrievalQuery{
+		Limit:             limit * 4,
+		IncludeCollective: true,
+		ConceptTags:       []string{DigestTag},
+	}, embedding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search digests: %w", err)
+	}
+
+	digests := []memory.Memory{}
+	for _, r := range results {
+		if isDigest(r.Memory) {
+			digests = append(digests, r.Memory)
+		}
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return digests[i].CreatedAt.After(digests[j].CreatedAt)
+	})
+	if len(digests) > limit {
+		digests = digests[:limit]
+	}
+	return digests, nil
+}
+
+// DigestWorker periodically stores a digest of the dialogue's activity
+type DigestWorker struct {
+	engine    *Engine
+	frequency string
+	period    time.Duration
+	stopChan  chan struct{}
+}
+
+// NewDigestWorker creates a digest worker for "daily" or "weekly" digests
+func NewDigestWorker(engine *Engine, frequency string) *DigestWorker {
+	return &DigestWorker{
+		engine:    engine,
+		frequency: strings.ToLower(strings.TrimSpace(frequency)),
+		period:    digestPeriod(frequency),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start runs the digest loop. A digest is written whenever the newest stored one
+// is older than the period, so restarts neither skip nor duplicate digests.
+func (w *DigestWorker) Start() {
+	if w.period == 0 {
+		log.Printf("[Digest] Digests disabled (frequency: %s)", w.frequency)
+		return
+	}
+	log.Printf("[Digest] Starting digest worker (frequency: %s)", w.frequency)
+
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	w.runIfDue()
+	for {
+		select {
+		case <-ticker.C:
+			w.runIfDue()
+		case <-w.stopChan:
+			log.Printf("[Digest] Stopping digest worker")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the worker
+func (w *DigestWorker) Stop() {
+	close(w.stopChan)
+}
+
+// runIfDue writes a digest when the period since the last one has elapsed
+func (w *DigestWorker) runIfDue() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	latest, err := w.engine.recentDigests(ctx, 1)
+	if err != nil {
+		log.Printf("[Digest] WARNING: Could not check previous digests: %v", err)
+		return
+	}
+	if len(latest) > 0 && now.Sub(latest[0].CreatedAt) < w.period {
+		return
+	}
+
+	if _, err := w.engine.GenerateDigest(ctx, w.frequency, now.Add(-w.period), now); err != nil {
+		log.Printf("[Digest] ERROR: Failed to generate digest: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package dialogue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDigestResponse(t *testing.T) {
+	response := `**DIGEST:** This week focused on soil pH research.
+Most questions were answered from extension sites.
+TAKEAWAY: Extension sites are the most reliable source
+TAKEAWAY 2: Forums need corroboration
+- TAKEAWAY: ignored because of the leading dash
+TAKEAWAY: pH drift is seasonal
+TAKEAWAY: a fourth takeaway is dropped`
+
+	summary, takeaways := parseDigestResponse(response)
+	if summary != "This week focused on soil pH research. Most questions were answered from extension sites." {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	want := []string{"Extension sites are the most reliable source", "Forums need corroboration", "pH drift is seasonal"}
+	if len(takeaways) != len(want) {
+		t.Fatalf("expected %d takeaways, got %v", len(want), takeaways)
+	}
+	for i := range want {
+		if takeaways[i] != want[i] {
+			t.Errorf("takeaway %d: expected %q, got %q", i, want[i], takeaways[i])
+		}
+	}
+}
+
+func TestParseDigestResponseWithoutMarker(t *testing.T) {
+	summary, takeaways := parseDigestResponse("Quiet day with two cycles.\nTAKEAWAY: nothing new")
+	if summary != "Quiet day with two cycles." {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if len(takeaways) != 1 {
+		t.Errorf("expected 1 takeaway, got %v", takeaways)
+	}
+}
+
+func TestDigestPeriod(t *testing.T) {
+	cases := map[string]time.Duration{
+		"daily":  24 * time.Hour,
+		"":       24 * time.Hour,
+		"Weekly": 7 * 24 * time.Hour,
+		"off":    0,
+		"hourly": 0,
+	}
+	for frequency, want := range cases {
+		if got := digestPeriod(frequency); got != want {
+			t.Errorf("digestPeriod(%q) = %s, want %s", frequency, got, want)
+		}
+	}
+}
@@ -2,10 +2,16 @@ package dialogue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go-llama/internal/health"
 	"go-llama/internal/memory"
 	"go-llama/internal/tools"
 	"gorm.io/gorm"
@@ -23,6 +29,7 @@ type Engine struct {
     llmModel			string
     simpleLLMURL			string
     simpleLLMModel			string
+    modelRouter			*ModelRouter	// Chooses the simple or reasoning model per call type
     llmClient			interface{}	// Will be *llm.Client but avoid import cycle
     db				*gorm.DB	// For loading principles
     contextSize			int
@@ -40,8 +47,66 @@ type Engine struct {
     dynamicActionPlanning	bool
     adaptiveConfig		*AdaptiveConfig
     circuitBreaker		*tools.CircuitBreaker
+    dedupThreshold		float64	// Similarity for merging near-duplicate learnings
+    goalDedup			GoalDedupConfig	// Weights for scoring proposed goals against existing ones
+    goalProposals		GoalProposalConfig	// Priority default and description checks for proposed goals
+    principleTrials		int	// A/B trials per branch before committing a principle change
+    principleTrialMargin	float64	// Score lead the proposed principle needs to be committed
+    injectionDetection	bool	// Flag imperative phrases in parsed content and lower parse confidence
+    injectionPenalty	float64	// Confidence subtracted from a flagged parse evaluation
+    domainPolicy	*tools.DomainPolicy	// Optional; drops refused URLs before they are evaluated or fetched
+    toolHealth	*tools.HealthTracker	// Optional; recent tool health shown alongside the tool list
+    targetLanguage	string	// ISO 639-1 code research is done in; search evaluation ranks other languages lower
+    actionTimeMargin	time.Duration	// Kept free at the end of a cycle when capping action deadlines
+    minActionTime	time.Duration	// Actions are deferred when less than this would remain
+    // Config reloads: settings arriving mid-cycle wait for the cycle to end
+    settingsMu		sync.Mutex
+    cycleRunning	bool
+    pendingSettings	*Settings
+    // Cycles are skipped rather than started while a dependency they need is down
+    readiness		*health.Checker
+    readinessDeps	[]string
+    skipMu		sync.Mutex
+    skipStats		SkippedCycleStats
+    // Overlapping cycles: an in-process guard, plus an optional lease shared by replicas
+    cycleActive		atomic.Bool
+    cycleLease		CycleLease
+    cycleLeaseTTL	time.Duration
+    tokenBudget		TokenBudget	// Optional; cycles pause at its hard limit
+    loadShedding	LoadSheddingConfig	// When an overloaded reasoning model sheds a cycle's reflection
+    llmLoad		func() LLMLoad	// Optional; reads the reasoning model's queue load
+    backpressure	BackpressureConfig	// When the action backlog stops new goal proposals
+    userQuestionTimeout	time.Duration	// How long a goal waits for the user's answer
+    resultThreshold	int	// Action results longer than this go to the result store (0 = default)
+    interestHalfLife	time.Duration	// Age at which a memory counts half in interest analysis (0 = default)
+    memoryReuse		MemoryReuseConfig	// Parse actions may be answered by a synthesis of the same page
+    focusConfig		FocusConfig	// How long self-assessed focus areas steer later cycles
+    streaming		StreamingConfig	// Long calls are streamed so they can be cut short
+    prompts		*PromptRegistry	// Evaluator prompt templates; nil uses the embedded defaults
+    promptUsesMu	sync.Mutex
+    promptUses		map[string]int	// Structured calls this cycle by template version
+    memoryReuseHits	atomic.Int64
+    searchLedger	SearchLedgerConfig	// Searches may reuse a recent identical search's chosen page
+    searchLedgerHits	atomic.Int64
+    searchLedgerMisses	atomic.Int64
+    transcripts	TranscriptConfig	// Record each cycle's model replies for cmd/replay
+    transcriptMu	sync.Mutex
+    transcript	*transcriptRecorder	// The running cycle's transcript, nil between cycles
+    noveltyThreshold	float64	// Keyword overlap at which a thought repeats a recent one
+    reflectionDiversity	float64	// Embedding similarity at which a memory is a near-copy in the reflection context
+    repetitiveThoughts	atomic.Int64
+    repetitionMu	sync.Mutex
+    repetitionHint	string	// Last repeated thought or learning, for the next reflection
+    maxReplans		int	// Plan replacements allowed per research goal
+    synthesisGate	SynthesisGateConfig
+    traceExporter	*tools.OTLPExporter	// Optional; action traces are kept in metadata either way
+    conceptTagger	*memory.Tagger	// Optional; tags research syntheses
+    fixedSeed		int64	// Seed every cycle's random choices use (0 = time-based), see SetCycleSeed
     // MILESTONE 4: Goal System Integration
     goalOrchestrator		*goal.Orchestrator
+    // Live monitoring
+    events			*EventBus
+    currentCycle		atomic.Int64	// Cycle ID stamped on published events
 }
 
 // NewEngine creates a new dialogue engine
@@ -96,6 +161,11 @@ skillRepo, err := memory.NewSkillRepository(qdrantClient, "skills", adapter)
         log.Printf("[Engine] WARNING: Failed to init SkillRepo: %v", err)
     }
 
+    // Calls the queue preempts for user chat are resubmitted rather than failed
+    if caller, ok := llmClient.(goal.LLMCaller); ok {
+        llmClient = &requeueingCaller{caller: caller}
+    }
+
     // Wire up the Goal Subsystem with Dual LLM Support
     
     var mainLLMAdapter goal.LLMService
@@ -170,12 +240,7 @@ skillRepo, err := memory.NewSkillRepository(qdrantClient, "skills", adapter)
     // Post-Initialization Wiring
     // Set available tools from registry dynamically
     if toolRegistry != nil {
-        // Access the underlying Registry via GetRegistry() to retrieve the tool list
-        toolList := toolRegistry.GetRegistry().List()
-        availableTools := make([]string, 0, len(toolList))
-        for name := range toolList {
-            availableTools = append(availableTools, name)
-        }
+        availableTools := toolNames(toolRegistry)
         orchestrator.SetAvailableTools(availableTools)
         log.Printf("[Engine] Loaded %d tools for Goal Validation: %v", len(availableTools), availableTools)
     }
@@ -183,7 +248,7 @@ skillRepo, err := memory.NewSkillRepository(qdrantClient, "skills", adapter)
     // Set embedder for Orchestrator (used in semantic operations if needed directly)
     orchestrator.SetEmbedder(adapter)
 
-    return &Engine{
+    e := &Engine{
         storage:			storage,
         embedder:			embedder,
         stateManager:			stateManager,
@@ -193,6 +258,7 @@ skillRepo, err := memory.NewSkillRepository(qdrantClient, "skills", adapter)
         llmModel:			llmModel,
         simpleLLMURL:			simpleLLMURL,
         simpleLLMModel:			simpleLLMModel,
+        modelRouter:			NewModelRouter(llmURL, llmModel, simpleLLMURL, simpleLLMModel),
         llmClient:			llmClient,	// Store client
         contextSize:			contextSize,
         maxTokensPerCycle:		maxTokensPerCycle,
@@ -207,9 +273,199 @@ skillRepo, err := memory.NewSkillRepository(qdrantClient, "skills", adapter)
         storeInsights:			storeInsights,
         dynamicActionPlanning:		dynamicActionPlanning,
         adaptiveConfig:			NewAdaptiveConfig(0.30, 0.75, 60),
+        goalDedup:			DefaultGoalDedupConfig(),
         circuitBreaker:			circuitBreaker,
         // Milestone 4
         goalOrchestrator:		orchestrator,
+        events:				NewEventBus(),
+    }
+
+    e.modelRouter.SetContextSizes(contextSize, 0)
+
+    // Surface goal lifecycle changes from the orchestrator as dialogue events
+    stateMgr.AddListener(e.onGoalTransition)
+
+    // Pick up thresholds learned before the last restart
+    restoreCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    e.restoreAdaptiveState(restoreCtx)
+    cancel()
+
+    return e
+}
+
+// SetConceptTagger sets the tagger research syntheses get their concept tags from
+func (e *Engine) SetConceptTagger(tagger *memory.Tagger) {
+    e.conceptTagger = tagger
+}
+
+// SetDedupThreshold configures the similarity above which learnings are merged
+func (e *Engine) SetDedupThreshold(threshold float64) {
+    e.dedupThreshold = threshold
+}
+
+// SetGoalDedup configures how proposed goals are scored against existing goals
+func (e *Engine) SetGoalDedup(cfg GoalDedupConfig) error {
+    if err := cfg.Validate(); err != nil {
+        return err
+    }
+    e.goalDedup = cfg
+    return nil
+}
+
+// SetAdaptiveBase configures the base values adaptive thresholds are derived from
+func (e *Engine) SetAdaptiveBase(searchThreshold, goalSimilarity float64, toolTimeoutSeconds int) {
+    e.adaptiveConfig.SetBase(searchThreshold, goalSimilarity, toolTimeoutSeconds)
+}
+
+// SetAdaptiveLimits configures the bounds adaptive thresholds stay within and pins any
+// that should not adapt
+func (e *Engine) SetAdaptiveLimits(limits AdaptiveLimits) error {
+    if err := limits.Validate(); err != nil {
+        return err
+    }
+    e.adaptiveConfig.SetLimits(limits)
+    return nil
+}
+
+// SetPrincipleTrialConfig configures empirical validation of principle modifications.
+// Zero trials or margin take the defaults; a negative margin accepts any lead.
+func (e *Engine) SetPrincipleTrialConfig(trials int, margin float64) {
+    e.principleTrials = trials
+    e.principleTrialMargin = margin
+}
+
+// SetModelRouting overrides the model tier used for individual call types
+func (e *Engine) SetModelRouting(policy map[string]string) error {
+    if err := e.modelRouter.SetPolicy(policy); err != nil {
+        return err
+    }
+    log.Printf("[Dialogue] Model routing: %s", e.modelRouter)
+    return nil
+}
+
+// SetSampling overrides the sampling parameters for individual call types
+func (e *Engine) SetSampling(sampling map[string]SamplingParams) error {
+    return e.modelRouter.SetSampling(sampling)
+}
+
+// SetSimpleContextSize records the simple model's context window, so calls routed to it
+// are fitted to its own context rather than the reasoning model's
+func (e *Engine) SetSimpleContextSize(contextSize int) {
+    e.modelRouter.SetContextSizes(e.contextSize, contextSize)
+}
+
+// ModelRoutingStats reports the routing policy and how many calls each model served
+func (e *Engine) ModelRoutingStats() ModelRouterStats {
+    if e.modelRouter == nil {
+        return ModelRouterStats{}
+    }
+    return e.modelRouter.Stats()
+}
+
+// recordEmptyCompletion counts an empty completion against its call type
+func (e *Engine) recordEmptyCompletion(callType LLMCallType) {
+    if e.modelRouter != nil {
+        e.modelRouter.RecordEmpty(callType)
+    }
+}
+
+// routeModel picks the model for a call; engines built without a router use the reasoning model
+func (e *Engine) routeModel(callType LLMCallType) ModelRoute {
+    if e.modelRouter == nil {
+        return ModelRoute{CallType: callType, Tier: ModelTierReasoning, URL: e.llmURL, Model: e.llmModel,
+            Sampling: SamplingParams{MaxTokens: defaultCallMaxTokens[callType]}, ContextSize: e.contextSize}
+    }
+    return e.modelRouter.Route(callType)
+}
+
+// SetInjectionDetection enables the prompt-injection heuristic for parsed content
+func (e *Engine) SetInjectionDetection(enabled bool, penalty float64) {
+    e.injectionDetection = enabled
+    e.injectionPenalty = penalty
+}
+
+// SetActionTimeBudget configures how action deadlines are fitted into the remaining cycle time
+func (e *Engine) SetActionTimeBudget(margin, minimum time.Duration) {
+    e.actionTimeMargin = margin
+    e.minActionTime = minimum
+}
+
+// SetDeadlineEscalation configures how goal priority rises as a deadline approaches
+func (e *Engine) SetDeadlineEscalation(window time.Duration, maxBoost int, exponent float64) {
+    if e.goalOrchestrator == nil {
+        return
+    }
+    e.goalOrchestrator.Calculator.SetDeadlineEscalation(window, maxBoost, exponent)
+}
+
+// GoalOverdue records a reflection note for a goal that missed its deadline and
+// publishes its pending report for the user
+func (e *Engine) GoalOverdue(ctx context.Context, g *goal.Goal) {
+    if g.OverdueReport == nil {
+        return
+    }
+    note := fmt.Sprintf("Reflection: I missed the deadline for goal %q (%.0f%% complete). %s",
+        g.Title, g.ProgressPercentage, g.OverdueReport.Question)
+    e.saveThought(ctx, &ThoughtRecord{
+        CycleID:   int(e.currentCycle.Load()),
+        GoalID:    g.ID,
+        Content:   note,
+        Timestamp: time.Now(),
+    })
+    e.publishEvent(EventGoalOverdue, g.ID, "", map[string]interface{}{
+        "origin":        string(g.Origin),
+        "deadline":      g.OverdueReport.Deadline,
+        "progress":      g.OverdueReport.Progress,
+        "question":      g.OverdueReport.Question,
+        "awaiting_user": g.Origin == goal.OriginUser,
+    })
+}
+
+// SetDomainPolicy filters candidate URLs with the same policy the web parser enforces
+func (e *Engine) SetDomainPolicy(policy *tools.DomainPolicy) {
+    e.domainPolicy = policy
+}
+
+// SetToolHealth shows each tool's recent health in the tool list given to the LLM, so
+// plans avoid tools that are currently failing
+func (e *Engine) SetToolHealth(tracker *tools.HealthTracker) {
+    e.toolHealth = tracker
+}
+
+// SetTargetLanguage sets the language search evaluation prefers pages in
+func (e *Engine) SetTargetLanguage(code string) {
+    e.targetLanguage = code
+}
+
+// Events exposes the engine's event bus for live monitoring
+func (e *Engine) Events() *EventBus {
+    return e.events
+}
+
+// publishEvent stamps an event with the current cycle and publishes it
+func (e *Engine) publishEvent(eventType EventType, goalID, actionID string, data map[string]interface{}) {
+    if e.events == nil {
+        return
+    }
+    e.events.Publish(Event{
+        Type:     eventType,
+        CycleID:  int(e.currentCycle.Load()),
+        GoalID:   goalID,
+        ActionID: actionID,
+        Data:     data,
+    })
+}
+
+// onGoalTransition maps orchestrator state changes onto goal events
+func (e *Engine) onGoalTransition(goalID string, from, to goal.GoalState, _ time.Time) {
+    data := map[string]interface{}{"from": string(from), "to": string(to)}
+    switch {
+    case from == goal.StateValidating && to == goal.StateQueued:
+        e.publishEvent(EventGoalCreated, goalID, "", data)
+    case to == goal.StateCompleted:
+        e.publishEvent(EventGoalCompleted, goalID, "", data)
+    case to == goal.StateArchived:
+        e.publishEvent(EventGoalAbandoned, goalID, "", data)
     }
 }
 
@@ -222,31 +478,82 @@ func (e *Engine) GetOrchestrator() *goal.Orchestrator {
 func (e *Engine) RunDialogueCycle(ctx context.Context) error {
 	startTime := time.Now()
 
-	// Load current state
+	// A cycle that overran its schedule still owns the state; this tick waits its turn
+	lock, err := e.acquireCycleLock(ctx)
+	if err != nil {
+		e.recordLockedCycle(err)
+		return nil
+	}
+	defer e.releaseCycleLock(lock)
+
+	// Idle cycles stop spending once the hosted token budget is used up; chat is exempt
+	if e.tokenBudget != nil && e.tokenBudget.OverHardLimit() {
+		e.recordBudgetCycle()
+		return nil
+	}
+
+	// Don't start a cycle that would fail partway through on a dependency that is down
+	if down := e.unavailableDependencies(ctx); len(down) > 0 {
+		e.recordSkippedCycle(down)
+		return nil
+	}
+
+	// Claim the state before loading it, so a stale cycle that lost its lease cannot
+	// overwrite what this one saves
+	if err := e.stateManager.ClaimState(ctx, lock.token); err != nil {
+		return err
+	}
 	state, err := e.stateManager.LoadState(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load state: %w", err)
 	}
+	state.owner = lock.token
+	answered := e.applyUserAnswers(ctx, state)
+	seed := e.cycleSeed()
+	state.rng = newRand(seed)
 
 	state.CycleCount++
 	cycleID := state.CycleCount
 
-	log.Printf("[Dialogue] Starting cycle #%d at %s", cycleID, startTime.Format(time.RFC3339))
+	e.beginCycle()
+	defer e.endCycle()
+
+	log.Printf("[Dialogue] Starting cycle #%d at %s (seed %d)", cycleID, startTime.Format(time.RFC3339), seed)
+	e.currentCycle.Store(int64(cycleID))
+	e.beginTranscript(cycleID, state)
+	defer e.finishTranscript(ctx)
+	e.publishEvent(EventCycleStarted, "", "", map[string]interface{}{"seed": seed})
 
 	// Initialize metrics
 	metrics := &CycleMetrics{
 		CycleID:	cycleID,
 		StartTime:	startTime,
+		ThoughtLimit:	e.maxThoughtsPerCycle,
+		TokenLimit:	e.maxTokensPerCycle,
+		DurationLimit:	time.Duration(e.maxDurationMinutes) * time.Minute,
+		SearchThreshold:	e.adaptiveConfig.GetSearchThreshold(),
+		CollectiveThreshold:	e.adaptiveConfig.GetCollectiveThreshold(),
+		GoalSimilarityThreshold:	e.adaptiveConfig.GetGoalSimilarityThreshold(),
+		RandomSeed:	seed,
 	}
 
+	cacheBefore := e.searchCacheStats()
+	pageCacheBefore := e.pageCacheStats()
+	modelCallsBefore := e.ModelRoutingStats().Total
+	reuseHitsBefore := e.memoryReuseHits.Load()
+	ledgerBefore := e.SearchLedgerStats()
+	repetitiveBefore := e.repetitiveThoughts.Load()
+	e.takePromptUses() // Count only this cycle's template versions
+
 	// Create context with timeout
-	cycleCtx, cancel := context.WithTimeout(ctx, time.Duration(e.maxDurationMinutes)*time.Minute)
+	cycleCtx, cancel := context.WithTimeout(ctx, metrics.DurationLimit)
 	defer cancel()
 
 	// Run dialogue phases with safety checks
 	stopReason, err := e.runDialoguePhases(cycleCtx, state, metrics)
 	if err != nil {
 		log.Printf("[Dialogue] ERROR in cycle #%d: %v", cycleID, err)
+		e.publishEvent(EventCycleCompleted, "", "", map[string]interface{}{"error": err.Error()})
 		return err
 	}
 
@@ -254,117 +561,91 @@ func (e *Engine) RunDialogueCycle(ctx context.Context) error {
 	metrics.EndTime = time.Now()
 	metrics.Duration = metrics.EndTime.Sub(metrics.StartTime)
 	metrics.StopReason = stopReason
+	cacheAfter := e.searchCacheStats()
+	metrics.SearchCacheHits = int(cacheAfter.Hits - cacheBefore.Hits)
+	metrics.SearchCacheMisses = int(cacheAfter.Misses - cacheBefore.Misses)
+	pageCacheAfter := e.pageCacheStats()
+	metrics.PageCacheHits = int(pageCacheAfter.Hits + pageCacheAfter.Revalidated - pageCacheBefore.Hits - pageCacheBefore.Revalidated)
+	metrics.PageCacheMisses = int(pageCacheAfter.Misses - pageCacheBefore.Misses)
+	modelCallsAfter := e.ModelRoutingStats().Total
+	metrics.SimpleModelCalls = int(modelCallsAfter.Simple - modelCallsBefore.Simple)
+	metrics.ReasoningModelCalls = int(modelCallsAfter.Reasoning - modelCallsBefore.Reasoning)
+	metrics.EmptyCompletions = int(modelCallsAfter.Empty - modelCallsBefore.Empty)
+	metrics.ContextOverflows = int(modelCallsAfter.Overflows - modelCallsBefore.Overflows)
+	metrics.MemoryReuseHits = int(e.memoryReuseHits.Load() - reuseHitsBefore)
+	ledgerAfter := e.SearchLedgerStats()
+	metrics.SearchLedgerHits = int(ledgerAfter.Hits - ledgerBefore.Hits)
+	metrics.SearchLedgerMisses = int(ledgerAfter.Misses - ledgerBefore.Misses)
+	metrics.RepetitiveThoughts = int(e.repetitiveThoughts.Load() - repetitiveBefore)
+	metrics.PromptTemplates = e.takePromptUses()
 
 	// Update state
 	state.LastCycleTime = time.Now()
 
+	// Adapt thresholds to this cycle's outcome and persist them for the next restart
+	e.updateAdaptiveState(ctx, state)
+
 	// Save state and metrics
-	if err := e.stateManager.SaveState(ctx, state); err != nil {
+	if err := e.stateManager.SaveState(ctx, state); errors.Is(err, ErrStaleCycle) {
+		log.Printf("[Dialogue] Cycle #%d not saved: %v", cycleID, err)
+	} else if err != nil {
 		log.Printf("[Dialogue] ERROR saving state: %v", err)
+	} else if err := e.stateManager.MarkGoalAnswersApplied(ctx, answered); err != nil {
+		log.Printf("[Dialogue] WARNING: %v", err)
 	}
 	if err := e.stateManager.SaveMetrics(ctx, metrics); err != nil {
 		log.Printf("[Dialogue] ERROR saving metrics: %v", err)
 	}
 
-	log.Printf("[Dialogue] Cycle #%d complete: %d thoughts, %d actions, %d tokens, took %s (reason: %s)",
-		cycleID, metrics.ThoughtCount, metrics.ActionCount, metrics.TokensUsed,
-		metrics.Duration.Round(time.Second), stopReason)
+	log.Printf("[Dialogue] Cycle #%d complete: %d/%d thoughts, %d actions, %d/%d tokens, %d/%d search cache hits, %d simple/%d reasoning model calls (%d empty), took %s of %s (reason: %s)",
+		cycleID, metrics.ThoughtCount, metrics.ThoughtLimit, metrics.ActionCount, metrics.TokensUsed, metrics.TokenLimit,
+		metrics.SearchCacheHits, metrics.SearchCacheHits+metrics.SearchCacheMisses,
+		metrics.SimpleModelCalls, metrics.ReasoningModelCalls, metrics.EmptyCompletions,
+		metrics.Duration.Round(time.Second), metrics.DurationLimit, stopReason)
+	if recent, err := e.stateManager.RecentMetrics(ctx, stopReasonWindow); err == nil {
+		log.Printf("[Dialogue] Stop reasons over last %d cycles: %s", len(recent), formatHistogram(StopReasonHistogram(recent)))
+	}
+
+	e.publishEvent(EventCycleCompleted, "", "", map[string]interface{}{
+		"stop_reason":   stopReason,
+		"thoughts":      metrics.ThoughtCount,
+		"thought_limit": metrics.ThoughtLimit,
+		"tokens":        metrics.TokensUsed,
+		"token_limit":   metrics.TokenLimit,
+		"duration_ms":   metrics.Duration.Milliseconds(),
+	})
 
 	return nil
 }
 
-// runDialoguePhases executes the dialogue phases with safety mechanisms
-func (e *Engine) runDialoguePhases(ctx context.Context, state *InternalState, metrics *CycleMetrics) (string, error) {
-    // MAINTENANCE: Apply time-based confidence decay to principles
-    if err := memory.ApplyConfidenceDecay(e.db); err != nil {
-        log.Printf("[Dialogue] WARNING: Failed to apply principle decay: %v", err)
+// searchCacheStats reads the search tool's cache counters (zero if unavailable)
+func (e *Engine) searchCacheStats() tools.SearchCacheStats {
+    if e.toolRegistry == nil {
+        return tools.SearchCacheStats{}
     }
-
-    // MILESTONE 4: Handoff to Goal Orchestrator
-    // The Orchestrator handles validation, selection, and execution.
-    // It calls back into the Engine for Tool Execution (via interface).
-    if e.goalOrchestrator != nil {
-        // Connect the bridge for this cycle
-        e.goalOrchestrator.SetExecutor(e) 
-        
-        if err := e.goalOrchestrator.ExecuteCycle(ctx); err != nil {
-            log.Printf("[Dialogue] Goal Cycle Error: %v", err)
-        }
-    } else {
-        log.Printf("[Dialogue] WARNING: GoalOrchestrator not initialized")
+    tool, err := e.toolRegistry.GetRegistry().Get(tools.ToolNameSearch)
+    if err != nil {
+        return tools.SearchCacheStats{}
+    }
+    if cached, ok := tool.(interface{ CacheStats() tools.SearchCacheStats }); ok {
+        return cached.CacheStats()
     }
+    return tools.SearchCacheStats{}
+}
 
-    // Legacy thought process for reflection (Phase 1) can remain here if desired,
-    // but the core Goal logic is now delegated.
-    
-    // For the purpose of the migration, we are replacing the manual goal logic
-    // but keeping the reflection logic which drives the "Proposals" for the new system.
-    
-    // Execute Phase 1 Reflection to generate thoughts/proposals
-    thoughtCount := 0
-    totalTokens := 0
-    
-    reasoning, principles, phaseTokens, reflectionText, err := e.runPhaseReflection(ctx, state)
+// pageCacheStats reads the web parser's HTTP cache counters (zero if unavailable)
+func (e *Engine) pageCacheStats() tools.HTTPCacheStats {
+    if e.toolRegistry == nil {
+        return tools.HTTPCacheStats{}
+    }
+    tool, err := e.toolRegistry.GetRegistry().Get(ActionToolWebParseUnified)
     if err != nil {
-        return StopReasonNaturalStop, err
+        return tools.HTTPCacheStats{}
     }
-    
-    thoughtCount++
-    totalTokens += phaseTokens
-    
-    // Save thought record to state file
-    e.stateManager.SaveThought(ctx, &ThoughtRecord{
-        CycleID:	state.CycleCount,
-        ThoughtNum:	thoughtCount,
-        Content:	reflectionText,
-        TokensUsed:	phaseTokens,
-        ActionTaken:	false,
-        Timestamp:	time.Now(),
-    })
-
-    // MILESTONE 3/4 INTEGRATION: Persist reflection to Memory (Qdrant)
-    // This ensures the Goal Derivation Engine can find this reflection via semantic search.
-    if reflectionText != "" {
-        metadata := map[string]interface{}{
-            "type":        "reflection",
-            "cycle_id":    state.CycleCount,
-            "source":      "autonomous_cycle",
-        }
-        
-        // 1. Generate Embedding (Required by Storage.Store validation)
-        embedding, embErr := e.embedder.Embed(ctx, reflectionText)
-        if embErr != nil {
-             log.Printf("[Engine] ERROR: Failed to embed reflection, skipping storage: %v", embErr)
-        } else {
-            // 2. Construct the Memory object with Embedding
-            mem := &memory.Memory{
-                Content:  reflectionText,
-                Metadata: metadata,
-                Embedding: embedding,
-                // Set required defaults for autonomous reflection
-                Tier: memory.TierRecent,
-                IsCollective: true, 
-                CreatedAt: time.Now(),
-                LastAccessedAt: time.Now(),
-            }
-            
-            if err := e.storage.Store(ctx, mem); err != nil {
-                log.Printf("[Engine] Warning: Failed to store reflection in memory: %v", err)
-            } else {
-                log.Printf("[Engine] Persisted reflection to memory for Derivation Engine.")
-            }
-        }
+    if cached, ok := tool.(interface{ HTTPCacheStats() tools.HTTPCacheStats }); ok {
+        return cached.HTTPCacheStats()
     }
-    
-    _ = reasoning // Avoid unused variable error for now
-    _ = principles
-
-    // Update metrics for this simplified cycle
-    metrics.ThoughtCount = thoughtCount
-    metrics.ActionCount = 0 // Action counting is now internal to Orchestrator
-    metrics.TokensUsed = totalTokens
-
-    return StopReasonNaturalStop, nil
+    return tools.HTTPCacheStats{}
 }
 
 // ExecuteToolAction implements the goal.ActionExecutor interface.
@@ -378,17 +659,151 @@ func (e *Engine) ExecuteToolAction(ctx context.Context, tool string, params map[
     // and requires the longer timeouts and higher result limits associated with idle exploration.
     log.Printf("[Engine] Bridging Goal action to Tool Registry (Idle Mode): %s", tool)
 
-    result, err := e.toolRegistry.ExecuteIdle(ctx, tool, params)
+    goalID, actionID := "", newActionID()
+    if ac, ok := goal.ActionContextFrom(ctx); ok {
+        goalID = ac.GoalID
+        if ac.SubGoalID != "" {
+            actionID = ac.SubGoalID
+        }
+    }
+
+    actionCtx, cancel, err := e.actionContext(ctx, tool)
     if err != nil {
+        return "", err // Deferred, not failed: nothing is published or recorded
+    }
+    defer cancel()
+
+    e.publishEvent(EventActionStarted, goalID, actionID, map[string]interface{}{"tool": tool})
+
+    if tool == ActionToolWebParseUnified {
+        params["goal"] = paramsParsePurpose(params)
+    }
+
+    start := time.Now()
+    // A page a recent research synthesis already covers is answered from memory
+    if url, ok := params["url"].(string); ok && tool == ActionToolWebParseUnified {
+        if reused, ok := e.reusePage(actionCtx, url, parseQuestion(params)); ok {
+            e.publishActionCompleted(goalID, actionID, tool, start, nil)
+            e.recordAction(ctx, goalID, actionID, tool, formatActionParams(params), reused.Memory.Content, start, nil)
+            return reused.Memory.Content, nil
+        }
+    }
+    result, err := e.toolRegistry.ExecuteIdle(actionCtx, tool, params)
+    e.publishActionCompleted(goalID, actionID, tool, start, err)
+    if err != nil {
+        e.recordAction(ctx, goalID, actionID, tool, formatActionParams(params), "", start, err)
         return "", err
     }
 
     if result == nil {
+        e.recordAction(ctx, goalID, actionID, tool, formatActionParams(params), "", start, nil)
         return "", nil
     }
 
-    // ToolResult.Output contains the string result from the tool execution
-    return result.Output, nil
+    // ToolResult.Output contains the string result from the tool execution. It is
+    // fed to later planning prompts, so prompt-like structure is neutralized first.
+    output, sanitized := tools.SanitizeUntrusted(result.Output)
+    if sanitized {
+        log.Printf("[Engine] Sanitized prompt-like sequences in %s output", tool)
+    }
+    e.recordAction(ctx, goalID, actionID, tool, formatActionParams(params), output, start, nil)
+    return output, nil
+}
+
+// saveThought persists a thought for history search. The goal is taken from the context
+// when the record does not name one. Thoughts repeating one from the last
+// noveltyWindowHours are counted and dropped, and false is returned.
+func (e *Engine) saveThought(ctx context.Context, thought *ThoughtRecord) bool {
+    if e.stateManager == nil {
+        return true
+    }
+    if thought.GoalID == "" {
+        thought.GoalID = goalIDFromContext(ctx)
+    }
+    if thought.GoalID == "" {
+        if ac, ok := goal.ActionContextFrom(ctx); ok {
+            thought.GoalID = ac.GoalID
+        }
+    }
+    // A thought that repeats a recent one is not stored again; stamps of structured
+    // calls record provenance and are always kept
+    if thought.PromptTemplate == "" {
+        if repeated, similarity, ok := e.repeatedThought(ctx, thought.Content); ok {
+            e.repetitiveThoughts.Add(1)
+            e.noteRepetition("thought", thought.Content)
+            log.Printf("[Dialogue] Skipping repetitive thought (%.0f%% overlap with cycle #%d): %s",
+                similarity*100, repeated.CycleID, truncate(thought.Content, 80))
+            return false
+        }
+    }
+    if err := e.stateManager.SaveThought(ctx, thought); err != nil {
+        log.Printf("[Dialogue] WARNING: %v", err)
+    }
+    return true
+}
+
+// recordAction persists a tool action for history search
+func (e *Engine) recordAction(ctx context.Context, goalID, actionID, tool, input, output string, start time.Time, err error) {
+    if e.stateManager == nil {
+        return
+    }
+    record := &ActionRecord{
+        CycleID:   int(e.currentCycle.Load()),
+        GoalID:    goalID,
+        ActionID:  actionID,
+        Tool:      tool,
+        Input:     input,
+        Output:    output,
+        Err:       err,
+        Duration:  time.Since(start),
+        Timestamp: start,
+    }
+    if saveErr := e.stateManager.SaveAction(ctx, record); saveErr != nil {
+        log.Printf("[Dialogue] WARNING: %v", saveErr)
+    }
+}
+
+// formatActionParams renders tool parameters as sorted "key=value" lines for history
+func formatActionParams(params map[string]interface{}) string {
+    keys := make([]string, 0, len(params))
+    for k := range params {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    lines := make([]string, 0, len(keys))
+    for _, k := range keys {
+        lines = append(lines, fmt.Sprintf("%s=%v", k, params[k]))
+    }
+    return strings.Join(lines, "\n")
+}
+
+// lastActionID is the timestamp of the latest generated action ID
+var lastActionID atomic.Int64
+
+// newActionID generates an ID for actions that do not come from a sub-goal. IDs are
+// unique within the process even when several actions are created at once, since
+// dependencies refer to them.
+func newActionID() string {
+    for {
+        last := lastActionID.Load()
+        next := max(time.Now().UnixNano(), last+1)
+        if lastActionID.CompareAndSwap(last, next) {
+            return fmt.Sprintf("action_%d", next)
+        }
+    }
+}
+
+// publishActionCompleted reports the outcome of a tool action
+func (e *Engine) publishActionCompleted(goalID, actionID, tool string, start time.Time, err error) {
+    data := map[string]interface{}{
+        "tool":        tool,
+        "success":     err == nil,
+        "duration_ms": time.Since(start).Milliseconds(),
+    }
+    if err != nil {
+        data["error"] = err.Error()
+    }
+    e.publishEvent(EventActionCompleted, goalID, actionID, data)
 }
 
 // embedderAdapter wraps memory.Embedder to implement goal.Embedder
@@ -0,0 +1,268 @@
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/tools"
+)
+
+func TestResearchConfidenceWeightsByPriority(t *testing.T) {
+	plan := &ResearchPlan{SubQuestions: []ResearchQuestion{
+		{ID: "q1", Status: ResearchStatusCompleted, Priority: 3, ConfidenceLevel: 0.9},
+		{ID: "q2", Status: ResearchStatusCompleted, Priority: 1, ConfidenceLevel: 0.1},
+		{ID: "q3", Status: ResearchStatusPending, Priority: 10, ConfidenceLevel: 0},
+	}}
+
+	mean, perQuestion, ok := researchConfidence(plan)
+	if !ok {
+		t.Fatal("expected completed questions to produce a confidence")
+	}
+	if math.Abs(mean-0.7) > 1e-9 {
+		t.Errorf("expected weighted mean 0.7, got %.3f", mean)
+	}
+	if len(perQuestion) != 2 || perQuestion["q1"] != 0.9 || perQuestion["q2"] != 0.1 {
+		t.Errorf("unexpected per-question confidences %v", perQuestion)
+	}
+}
+
+func TestResearchConfidenceWithoutCompletedQuestions(t *testing.T) {
+	plan := &ResearchPlan{SubQuestions: []ResearchQuestion{{ID: "q1", Status: ResearchStatusPending}}}
+	if _, _, ok := researchConfidence(plan); ok {
+		t.Error("expected no confidence when nothing completed")
+	}
+}
+
+func TestResearchAnswerConfidence(t *testing.T) {
+	eval := &ParseEvaluation{Confidence: 0.85}
+	if got := researchAnswerConfidence(eval, false); got != 0.85 {
+		t.Errorf("expected evaluation confidence, got %.2f", got)
+	}
+	if got := researchAnswerConfidence(eval, true); got != 0 {
+		t.Errorf("expected 0 for a failed fallback, got %.2f", got)
+	}
+	if got := researchAnswerConfidence(&ParseEvaluation{Confidence: 1.4}, false); got != 1 {
+		t.Errorf("expected confidence clamped to 1, got %.2f", got)
+	}
+}
+
+func TestSearchResultURLsPrefersStructuredResults(t *testing.T) {
+	results := []tools.StructuredSearchResult{
+		{URL: "https://structured.example", Rank: 1},
+		{URL: "ftp://ignored.example", Rank: 2},
+	}
+	legacy := "[1] Title\n    URL: https://scraped.example\n"
+
+	if urls := searchResultURLs(results, legacy); len(urls) != 1 || urls[0] != "https://structured.example" {
+		t.Errorf("expected structured URL only, got %v", urls)
+	}
+	if urls := searchResultURLs(nil, legacy); len(urls) != 1 || urls[0] != "https://scraped.example" {
+		t.Errorf("expected legacy text fallback, got %v", urls)
+	}
+}
+
+func TestCitedSourcesNumbersQuestionSourcesFirst(t *testing.T) {
+	parsed := &Action{Metadata: map[string]interface{}{}}
+	recordSourceProvenance(parsed, "https://a.example/page", &tools.ToolResult{
+		Metadata: map[string]interface{}{tools.MetaPageTitle: "Page A"},
+	})
+	other := &Action{Metadata: map[string]interface{}{}}
+	recordSourceProvenance(other, "https://b.example/other", &tools.ToolResult{})
+
+	goal := &Goal{
+		Actions: []Action{*other, *parsed},
+		ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{
+			{ID: "q1", Status: ResearchStatusCompleted},
+		}},
+	}
+	q := &goal.ResearchPlan.SubQuestions[0]
+	recordQuestionSource(q, parsed)
+	recordQuestionSource(q, parsed)
+	recordQuestionSource(q, &Action{}) // Search actions carry no page
+
+	if len(q.SourcesFound) != 1 || q.SourceTitles["https://a.example/page"] != "Page A" {
+		t.Fatalf("expected one titled source, got %v %v", q.SourcesFound, q.SourceTitles)
+	}
+
+	cited := citedSources(goal)
+	if len(cited) != 2 || cited[0]["url"] != "https://a.example/page" || cited[1]["url"] != "https://b.example/other" {
+		t.Fatalf("expected question source numbered first, got %v", cited)
+	}
+	if refs := citationNumbers(*q, cited); refs != "[1]" {
+		t.Errorf("expected question to cite [1], got %q", refs)
+	}
+	want := "[1] Page A - https://a.example/page\n[2] https://b.example/other\n"
+	if list := formatSourceList(cited); list != want {
+		t.Errorf("unexpected source list:\n%s", list)
+	}
+}
+
+func TestCitedSourcesWithoutPages(t *testing.T) {
+	goal := &Goal{ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{{ID: "q1"}}}}
+	cited := citedSources(goal)
+	if len(cited) != 0 || formatSourceList(cited) != "" {
+		t.Errorf("expected no sources for pure reasoning, got %v", cited)
+	}
+}
+
+// pageTool fails each URL with the error mapped to it and parses the rest
+type pageTool struct {
+	errs    map[string]error
+	visited []string
+}
+
+func (p *pageTool) Name() string        { return ActionToolWebParseUnified }
+func (p *pageTool) Description() string { return "test parser" }
+func (p *pageTool) RequiresAuth() bool  { return false }
+func (p *pageTool) Execute(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+	url := params["url"].(string)
+	p.visited = append(p.visited, url)
+	if err := p.errs[url]; err != nil {
+		return &tools.ToolResult{Success: false, Error: err.Error(), FailureKind: tools.FailureKindOf(err)}, err
+	}
+	return &tools.ToolResult{Success: true, Output: "parsed " + url, Metadata: map[string]interface{}{}}, nil
+}
+
+func parseWithFallbacks(t *testing.T, errs map[string]error) (*pageTool, *Action, error) {
+	t.Helper()
+	tool := &pageTool{errs: errs}
+	registry := tools.NewRegistry()
+	registry.Register(tool)
+	e := &Engine{toolRegistry: tools.NewContextualRegistry(registry, map[string]tools.ToolConfig{
+		ActionToolWebParseUnified: {TimeoutIdle: time.Minute},
+	})}
+	action := &Action{Tool: ActionToolWebParseUnified, Metadata: map[string]interface{}{
+		"selected_url":  "https://a.example/page",
+		"fallback_urls": []string{"https://b.example/page"},
+	}}
+	_, err := e.executeAction(context.Background(), action)
+	return tool, action, err
+}
+
+func TestTypedParseFailuresMoveToFallbackURL(t *testing.T) {
+	// The wording is deliberately unlike any message the tools produce
+	for _, cause := range []error{
+		fmt.Errorf("upstream said no: %w", &tools.HTTPStatusError{Code: http.StatusForbidden}),
+		fmt.Errorf("nope: %w", tools.ErrRobotsBlocked),
+		fmt.Errorf("nope: %w", tools.ErrPageTooLarge),
+		&tools.UnsupportedContentError{ContentType: "image/png"},
+	} {
+		tool, action, err := parseWithFallbacks(t, map[string]error{"https://a.example/page": cause})
+		if err != nil || len(tool.visited) != 2 || action.FailureKind != "" {
+			t.Errorf("%v: expected the fallback parsed, visited %v (%v)", cause, tool.visited, err)
+		}
+	}
+
+	// Server errors and timeouts are not about the page, so the action fails; the
+	// registry retries the timeout once on the same URL
+	for _, cause := range []error{
+		&tools.HTTPStatusError{Code: http.StatusBadGateway},
+		fmt.Errorf("reading body: %w", tools.ErrTimeout),
+	} {
+		tool, action, err := parseWithFallbacks(t, map[string]error{"https://a.example/page": cause})
+		if err == nil || tool.visited[len(tool.visited)-1] != "https://a.example/page" {
+			t.Errorf("%v: expected no fallback, visited %v", cause, tool.visited)
+		}
+		if action.FailureKind != tools.FailureKindOf(cause) {
+			t.Errorf("%v: expected failure kind %q, got %q", cause, tools.FailureKindOf(cause), action.FailureKind)
+		}
+	}
+}
+
+func TestFailureKindDrivesOutcomeAndTimeoutCounting(t *testing.T) {
+	timedOut := Action{Tool: ActionToolSearch, Status: ActionStatusCompleted, Result: "partial results",
+		FailureKind: tools.FailureKindTimeout}
+	parsed := Action{Tool: ActionToolSearch, Status: ActionStatusCompleted, Result: "the request timeout was raised"}
+
+	summaries := summarizeActions([]Action{timedOut, parsed})
+	if summaries[0].Success || !summaries[1].Success {
+		t.Errorf("expected success decided by failure kind, got %+v", summaries)
+	}
+
+	state := &InternalState{CompletedGoals: []Goal{
+		{Outcome: GoalOutcomeFailed, Actions: []Action{timedOut}},
+		{Outcome: GoalOutcomeSuccess, Actions: []Action{parsed}},
+	}}
+	ac := NewAdaptiveConfig(0.3, 0.75, 60)
+	ac.UpdateMetrics(context.Background(), state, 0)
+	withoutTimeouts := NewAdaptiveConfig(0.3, 0.75, 60)
+	withoutTimeouts.UpdateMetrics(context.Background(), &InternalState{CompletedGoals: []Goal{
+		{Outcome: GoalOutcomeFailed, Actions: []Action{parsed}},
+		{Outcome: GoalOutcomeSuccess, Actions: []Action{parsed}},
+	}}, 0)
+	if ac.GetToolTimeout() <= withoutTimeouts.GetToolTimeout() {
+		t.Errorf("expected only the typed timeout to extend the tool timeout, got %d vs %d",
+			ac.GetToolTimeout(), withoutTimeouts.GetToolTimeout())
+	}
+}
+
+func TestParseGoalSupportValidationsByIndex(t *testing.T) {
+	raw := "```lisp\n(goal_support_validations\n" +
+		"  (validation (index 2) (supports_goal_id \"goal_b\") (confidence 0.7) (reasoning \"feeds (part of) b\") (is_valid true))\n" +
+		"  (validation (index 1) (supports_goal_id \"\") (confidence 0.2) (reasoning \"unrelated\") (is_valid false)))\n```"
+
+	validations, err := parseGoalSupportValidations(raw, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validations[0].IsValid || validations[0].Reasoning != "unrelated" {
+		t.Errorf("expected the first proposal rejected, got %+v", validations[0])
+	}
+	if !validations[1].IsValid || validations[1].SupportsGoalID != "goal_b" || validations[1].Confidence != 0.7 {
+		t.Errorf("expected the second proposal linked to goal_b, got %+v", validations[1])
+	}
+
+	for name, bad := range map[string]string{
+		"missing validation": "(goal_support_validations (validation (index 1) (supports_goal_id \"goal_a\") (is_valid true)))",
+		"index out of range": "(goal_support_validations (validation (index 3) (is_valid false)) (validation (index 1) (is_valid false)))",
+		"valid without goal": "(goal_support_validations (validation (index 1) (is_valid true)) (validation (index 2) (is_valid false)))",
+		"not an expression":  "I think both goals help.",
+	} {
+		if _, err := parseGoalSupportValidations(bad, 2); err == nil {
+			t.Errorf("%s: expected the batch rejected", name)
+		}
+	}
+}
+
+func TestParseGoalSupportValidationReadsFields(t *testing.T) {
+	e := &Engine{}
+	validation, err := e.parseGoalSupportValidation(`(goal_support_validation (supports_goal_id "goal_a") (confidence 0.9) (reasoning "direct") (is_valid true))`)
+	if err != nil || !validation.IsValid || validation.SupportsGoalID != "goal_a" || validation.Confidence != 0.9 {
+		t.Errorf("unexpected validation %+v (%v)", validation, err)
+	}
+}
+
+func TestToolListShowsRecentToolHealth(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(&countingTool{})
+	registry.Register(&pageTool{})
+	e := &Engine{toolRegistry: tools.NewContextualRegistry(registry, nil)}
+
+	plain := e.getAvailableToolsList()
+	if strings.Contains(plain, "Health:") || strings.Contains(plain, "UNHEALTHY") {
+		t.Errorf("expected no health without a tracker, got:\n%s", plain)
+	}
+
+	tracker := tools.NewHealthTracker(10, 3, time.Hour)
+	for i := 0; i < 4; i++ {
+		tracker.Record(tools.ToolNameSearch, true, 2*time.Second, "connection refused")
+	}
+	tracker.Record(ActionToolWebParseUnified, false, 1500*time.Millisecond, "")
+	e.SetToolHealth(tracker)
+
+	list := e.getAvailableToolsList()
+	if !strings.Contains(list, "Health: UNHEALTHY, circuit open after 4 consecutive failures (0% of last 4 runs succeeded, avg 2s)") {
+		t.Errorf("expected search flagged unhealthy, got:\n%s", list)
+	}
+	if !strings.Contains(list, "Health: healthy (100% of last 1 runs succeeded, avg 1.5s)") {
+		t.Errorf("expected the parser shown healthy, got:\n%s", list)
+	}
+	if !strings.Contains(list, "marked UNHEALTHY") {
+		t.Errorf("expected the instruction to avoid unhealthy tools, got:\n%s", list)
+	}
+}
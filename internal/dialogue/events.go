@@ -0,0 +1,127 @@
+// internal/dialogue/events.go
+package dialogue
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType identifies a dialogue event for monitoring consumers
+type EventType string
+
+// Dialogue event types
+const (
+	EventCycleStarted    EventType = "cycle_started"
+	EventCycleCompleted  EventType = "cycle_completed"
+	EventCycleSkipped    EventType = "cycle_skipped"
+	EventThoughtRecorded EventType = "thought_recorded"
+	EventActionStarted   EventType = "action_started"
+	EventActionCompleted EventType = "action_completed"
+	EventGoalCreated     EventType = "goal_created"
+	EventGoalCompleted   EventType = "goal_completed"
+	EventGoalAbandoned   EventType = "goal_abandoned"
+	EventGoalOverdue     EventType = "goal_overdue"
+	EventUserQuestion    EventType = "user_question"
+	EventLearningStored  EventType = "learning_stored"
+	EventBudgetAlert     EventType = "budget_alert"
+)
+
+// DefaultEventBuffer is the per-subscriber queue length
+const DefaultEventBuffer = 256
+
+// Event is a single engine occurrence. CycleID is always set; GoalID and ActionID
+// are set whenever the event concerns a goal or action.
+type Event struct {
+	Type      EventType              `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	CycleID   int                    `json:"cycle_id"`
+	GoalID    string                 `json:"goal_id,omitempty"`
+	ActionID  string                 `json:"action_id,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// EventSubscription receives events on a bounded channel. When the buffer is full
+// events are dropped and counted rather than blocking the publisher.
+type EventSubscription struct {
+	id      int
+	events  chan Event
+	dropped atomic.Int64
+	bus     *EventBus
+}
+
+// Events returns the channel events are delivered on. It is closed on Unsubscribe.
+func (s *EventSubscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns how many events were discarded because the subscriber fell behind
+func (s *EventSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe stops delivery and closes the channel
+func (s *EventSubscription) Unsubscribe() {
+	s.bus.unsubscribe(s.id)
+}
+
+// EventBus fans engine events out to subscribers without ever blocking the engine
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[int]*EventSubscription
+	nextID      int
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]*EventSubscription)}
+}
+
+// Subscribe registers a subscriber with the given buffer size (<= 0 uses DefaultEventBuffer)
+func (b *EventBus) Subscribe(buffer int) *EventSubscription {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &EventSubscription{id: b.nextID, events: make(chan Event, buffer), bus: b}
+	b.subscribers[sub.id] = sub
+	return sub
+}
+
+func (b *EventBus) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub, ok := b.subscribers[id]; ok {
+		delete(b.subscribers, id)
+		close(sub.events)
+	}
+}
+
+// Publish delivers an event to every subscriber, dropping it for any whose buffer is full
+func (b *EventBus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers
+func (b *EventBus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
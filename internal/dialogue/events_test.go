@@ -0,0 +1,54 @@
+package dialogue
+
+import "testing"
+
+func TestEventBusDeliversToEverySubscriber(t *testing.T) {
+	bus := NewEventBus()
+	a := bus.Subscribe(4)
+	b := bus.Subscribe(4)
+	defer a.Unsubscribe()
+	defer b.Unsubscribe()
+
+	bus.Publish(Event{Type: EventActionStarted, CycleID: 3, GoalID: "g1", ActionID: "act1"})
+
+	for _, sub := range []*EventSubscription{a, b} {
+		ev := <-sub.Events()
+		if ev.Type != EventActionStarted || ev.CycleID != 3 || ev.GoalID != "g1" || ev.ActionID != "act1" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+		if ev.Timestamp.IsZero() {
+			t.Error("expected publish to stamp the event")
+		}
+	}
+}
+
+func TestEventBusDropsWhenSubscriberIsFull(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(2)
+	defer sub.Unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: EventThoughtRecorded, CycleID: i})
+	}
+
+	if got := sub.Dropped(); got != 3 {
+		t.Errorf("expected 3 dropped events, got %d", got)
+	}
+	if ev := <-sub.Events(); ev.CycleID != 0 {
+		t.Errorf("expected oldest buffered event first, got cycle %d", ev.CycleID)
+	}
+}
+
+func TestEventBusUnsubscribeClosesChannel(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(0)
+	sub.Unsubscribe()
+
+	if _, ok := <-sub.Events(); ok {
+		t.Error("expected channel to be closed")
+	}
+	if bus.SubscriberCount() != 0 {
+		t.Errorf("expected no subscribers, got %d", bus.SubscriberCount())
+	}
+	bus.Publish(Event{Type: EventCycleStarted}) // must not panic on the closed channel
+}
@@ -0,0 +1,141 @@
+// internal/dialogue/focus_areas.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-llama/internal/goal"
+)
+
+// maxFocusAreas caps how many self-assessed focus areas are kept
+const maxFocusAreas = 5
+
+// FocusArea is an area the self-assessment decided to focus on, kept until it expires
+// or the next self-assessment replaces it
+type FocusArea struct {
+	Area         string `json:"area"`
+	SetCycle     int    `json:"set_cycle"`
+	ExpiresCycle int    `json:"expires_cycle"` // Last cycle the area still steers
+}
+
+// FocusConfig controls how self-assessed focus areas steer the following cycles
+type FocusConfig struct {
+	ExpiryCycles  int     // Cycles an area steers for unless replaced
+	PriorityBonus int     // Priority added to goals matching an area; 0 turns the bias off
+	MinSimilarity float64 // Similarity a goal needs to match an area
+}
+
+// Validate rejects settings that would keep focus areas forever or match any goal
+func (c FocusConfig) Validate() error {
+	if c.ExpiryCycles <= 0 {
+		return fmt.Errorf("focus_areas.expiry_cycles must be positive, got %d", c.ExpiryCycles)
+	}
+	if c.PriorityBonus < 0 || c.PriorityBonus > 100 {
+		return fmt.Errorf("focus_areas.priority_bonus must be between 0 and 100, got %d", c.PriorityBonus)
+	}
+	if c.MinSimilarity <= 0 || c.MinSimilarity > 1 {
+		return fmt.Errorf("focus_areas.min_similarity must be in (0, 1], got %.2f", c.MinSimilarity)
+	}
+	return nil
+}
+
+// SetFocusConfig configures how long focus areas last and how much they bias goal selection
+func (e *Engine) SetFocusConfig(cfg FocusConfig) {
+	e.focusConfig = cfg
+	if e.goalOrchestrator == nil {
+		return
+	}
+	e.goalOrchestrator.SetFocusConfig(goal.FocusConfig{
+		PriorityBonus: cfg.PriorityBonus,
+		MinSimilarity: cfg.MinSimilarity,
+	})
+}
+
+// activeFocusAreas drops the areas that expired before cycle
+func activeFocusAreas(areas []FocusArea, cycle int) []FocusArea {
+	active := []FocusArea{}
+	for _, area := range areas {
+		if area.ExpiresCycle >= cycle {
+			active = append(active, area)
+		}
+	}
+	return active
+}
+
+// expireFocusAreas drops expired focus areas at the start of a cycle and hands the rest
+// to the goal scheduler
+func (e *Engine) expireFocusAreas(state *InternalState) {
+	active := activeFocusAreas(state.FocusAreas, state.CycleCount)
+	for _, area := range state.FocusAreas {
+		if area.ExpiresCycle < state.CycleCount {
+			log.Printf("[Dialogue] Focus area %q expired (set in cycle #%d)", area.Area, area.SetCycle)
+		}
+	}
+	state.FocusAreas = active
+
+	if e.goalOrchestrator != nil {
+		names := make([]string, len(active))
+		for i, area := range active {
+			names[i] = area.Area
+		}
+		e.goalOrchestrator.SetFocusAreas(names)
+	}
+}
+
+// recordFocusAreas replaces the focus areas with the ones the self-assessment just chose.
+// An assessment without focus areas leaves the current ones to run out.
+func (e *Engine) recordFocusAreas(state *InternalState, assessed []string) {
+	expiry := e.focusConfig.ExpiryCycles
+	if expiry <= 0 {
+		return
+	}
+	areas := []FocusArea{}
+	seen := make(map[string]bool)
+	for _, a := range assessed {
+		a = strings.TrimSpace(a)
+		key := strings.ToLower(a)
+		if a == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		areas = append(areas, FocusArea{Area: a, SetCycle: state.CycleCount, ExpiresCycle: state.CycleCount + expiry})
+		if len(areas) == maxFocusAreas {
+			break
+		}
+	}
+	if len(areas) == 0 {
+		return
+	}
+	if len(state.FocusAreas) > 0 {
+		log.Printf("[Dialogue] Replacing %d focus areas from the previous self-assessment", len(state.FocusAreas))
+	}
+	state.FocusAreas = areas
+	log.Printf("[Dialogue] Focusing on %d areas until cycle #%d", len(areas), state.CycleCount+expiry)
+}
+
+// focusContext reminds the next reflection of the focus areas it chose earlier
+func focusContext(areas []FocusArea) string {
+	if len(areas) == 0 {
+		return ""
+	}
+	text := "\nYou previously decided to focus on:\n"
+	for i, area := range areas {
+		text += fmt.Sprintf("%d. %s (since cycle #%d)\n", i+1, area.Area, area.SetCycle)
+	}
+	return text
+}
+
+// FocusAreas returns the focus areas that will steer the next cycle
+func (e *Engine) FocusAreas(ctx context.Context) ([]FocusArea, error) {
+	if e.stateManager == nil {
+		return []FocusArea{}, nil
+	}
+	state, err := e.stateManager.LoadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return activeFocusAreas(state.FocusAreas, state.CycleCount+1), nil
+}
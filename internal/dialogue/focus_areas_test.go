@@ -0,0 +1,74 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFocusAreasReplaceAndExpire(t *testing.T) {
+	e := &Engine{focusConfig: FocusConfig{ExpiryCycles: 2, PriorityBonus: 10, MinSimilarity: 0.6}}
+	state := &InternalState{CycleCount: 3}
+
+	e.recordFocusAreas(state, []string{" Rust ownership ", "rust ownership", "", "web scraping"})
+	if len(state.FocusAreas) != 2 || state.FocusAreas[0].Area != "Rust ownership" || state.FocusAreas[0].ExpiresCycle != 5 {
+		t.Fatalf("expected two deduplicated areas lasting two cycles, got %+v", state.FocusAreas)
+	}
+
+	// An assessment without focus areas keeps the current ones
+	e.recordFocusAreas(state, nil)
+	if len(state.FocusAreas) != 2 {
+		t.Fatalf("expected focus areas kept, got %+v", state.FocusAreas)
+	}
+
+	// The next cycles are reminded of them until they run out
+	for cycle := 4; cycle <= 5; cycle++ {
+		state.CycleCount = cycle
+		e.expireFocusAreas(state)
+		if text := focusContext(state.FocusAreas); !strings.Contains(text, "You previously decided to focus on:") || !strings.Contains(text, "web scraping") {
+			t.Errorf("cycle %d: expected the focus areas in the prompt, got %q", cycle, text)
+		}
+	}
+	state.CycleCount = 6
+	e.expireFocusAreas(state)
+	if len(state.FocusAreas) != 0 || focusContext(state.FocusAreas) != "" {
+		t.Errorf("expected focus areas expired after two cycles, got %+v", state.FocusAreas)
+	}
+
+	// A new assessment replaces the previous areas outright
+	e.recordFocusAreas(state, []string{"gardening"})
+	e.recordFocusAreas(state, []string{"chess endgames"})
+	if len(state.FocusAreas) != 1 || state.FocusAreas[0].Area != "chess endgames" {
+		t.Errorf("expected the newer areas to replace the older ones, got %+v", state.FocusAreas)
+	}
+}
+
+func TestFocusAreasPersistAcrossCycles(t *testing.T) {
+	ctx := context.Background()
+	sm := NewStateManager(setupProvenanceDB(t))
+	e := &Engine{stateManager: sm, focusConfig: FocusConfig{ExpiryCycles: 1, PriorityBonus: 10, MinSimilarity: 0.6}}
+
+	state, err := sm.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.CycleCount = 1
+	e.recordFocusAreas(state, []string{"Rust ownership"})
+	if err := sm.SaveState(ctx, state); err != nil {
+		t.Fatal(err)
+	}
+
+	areas, err := e.FocusAreas(ctx)
+	if err != nil || len(areas) != 1 || areas[0].Area != "Rust ownership" || areas[0].SetCycle != 1 {
+		t.Fatalf("expected the focus area reported for the next cycle, got %+v (%v)", areas, err)
+	}
+
+	// Once the cycle it was meant for has run, it is no longer reported
+	state.CycleCount = 2
+	if err := sm.SaveState(ctx, state); err != nil {
+		t.Fatal(err)
+	}
+	if areas, err := e.FocusAreas(ctx); err != nil || len(areas) != 0 {
+		t.Errorf("expected the focus area expired, got %+v (%v)", areas, err)
+	}
+}
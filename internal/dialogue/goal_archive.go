@@ -0,0 +1,289 @@
+// internal/dialogue/goal_archive.go
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-llama/internal/utils"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultCompletedGoalRetention is how many completed goals stay in the persisted state
+// when no retention is configured
+const DefaultCompletedGoalRetention = 100
+
+// GoalArchive holds a completed or abandoned goal moved out of the persisted state, with
+// its actions reduced to summaries
+type GoalArchive struct {
+	ID          int            `gorm:"primaryKey;autoIncrement" json:"id"` // Archive order; higher is more recent
+	GoalID      string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"goal_id"`
+	Description string         `gorm:"type:text;not null" json:"description"`
+	Status      string         `gorm:"type:varchar(20);not null;index" json:"status"`
+	Outcome     string         `gorm:"type:varchar(20)" json:"outcome,omitempty"`
+	Source      string         `gorm:"type:varchar(50)" json:"source"`
+	Tier        string         `gorm:"type:varchar(20)" json:"tier"`
+	Priority    int            `gorm:"not null;default:0" json:"priority"`
+	Progress    float64        `gorm:"not null;default:0" json:"progress"`
+	Actions     datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"actions"` // []ArchivedAction
+	Questions   datatypes.JSON `gorm:"type:jsonb" json:"questions,omitempty"`           // Research plan questions ([]string)
+	Notes       datatypes.JSON `gorm:"type:jsonb" json:"notes,omitempty"`               // []GoalNote
+	CreatedAt   time.Time      `json:"created_at"`                                      // When the goal was created
+	LastPursued time.Time      `json:"last_pursued"`
+	ArchivedAt  time.Time      `gorm:"not null" json:"archived_at"`
+}
+
+// TableName specifies the table name for GORM
+func (GoalArchive) TableName() string {
+	return "growerai_goal_archive"
+}
+
+// ArchivedAction is the summary of an action kept for an archived goal
+type ArchivedAction struct {
+	ID          string   `json:"id,omitempty"`
+	Tool        string   `json:"tool"`
+	Description string   `json:"description"`
+	Success     bool     `json:"success"`
+	Result      string   `json:"result,omitempty"`     // First maxActionResultLength characters
+	DependsOn   []string `json:"depends_on,omitempty"` // IDs of the actions it waited for
+}
+
+// actionFailed reports whether an action's tool call failed. Actions saved before
+// failure kinds were recorded are judged by their result's error prefix.
+func actionFailed(action Action) bool {
+	if action.FailureKind != "" {
+		return true
+	}
+	prefix := strings.ToLower(utils.Prefix(action.Result, len("failed:")))
+	return strings.HasPrefix(prefix, "error:") || strings.HasPrefix(prefix, "failed:")
+}
+
+// summarizeActions reduces actions to archive summaries. An action succeeded when it
+// completed without a failed tool call.
+func summarizeActions(actions []Action) []ArchivedAction {
+	summaries := make([]ArchivedAction, len(actions))
+	for i, action := range actions {
+		summaries[i] = ArchivedAction{
+			ID:          action.ID,
+			Tool:        action.Tool,
+			Description: action.Description,
+			Success:     action.Status == ActionStatusCompleted && !actionFailed(action),
+			Result:      action.Result,
+			DependsOn:   action.DependsOn,
+		}
+		summaries[i].Result = utils.Prefix(action.Result, maxActionResultLength)
+	}
+	return summaries
+}
+
+// newGoalArchive builds the archive row for a goal
+func newGoalArchive(goal Goal, archivedAt time.Time) GoalArchive {
+	actions, _ := json.Marshal(summarizeActions(goal.Actions))
+	var questions datatypes.JSON
+	if goal.ResearchPlan != nil && len(goal.ResearchPlan.SubQuestions) > 0 {
+		texts := make([]string, len(goal.ResearchPlan.SubQuestions))
+		for i, q := range goal.ResearchPlan.SubQuestions {
+			texts[i] = q.Question
+		}
+		questions, _ = json.Marshal(texts)
+	}
+	var notes datatypes.JSON
+	if len(goal.Notes) > 0 {
+		notes, _ = json.Marshal(goal.Notes)
+	}
+	return GoalArchive{
+		GoalID:      goal.ID,
+		Description: goal.Description,
+		Status:      goal.Status,
+		Outcome:     string(goal.Outcome),
+		Source:      goal.Source,
+		Tier:        goal.Tier,
+		Priority:    goal.Priority,
+		Progress:    goal.Progress,
+		Actions:     datatypes.JSON(actions),
+		Questions:   questions,
+		Notes:       notes,
+		CreatedAt:   goal.Created,
+		LastPursued: goal.LastPursued,
+		ArchivedAt:  archivedAt,
+	}
+}
+
+// goal rebuilds a Goal from the archive row; each action's status is completed when it
+// succeeded and pending otherwise, so a failed dependency runs again before its
+// dependents, and the research plan holds only the questions
+func (a GoalArchive) goal() Goal {
+	var summaries []ArchivedAction
+	json.Unmarshal(a.Actions, &summaries)
+	goal := Goal{
+		ID:          a.GoalID,
+		Description: a.Description,
+		Source:      a.Source,
+		Priority:    a.Priority,
+		Created:     a.CreatedAt,
+		Progress:    a.Progress,
+		Status:      a.Status,
+		Outcome:     ParseGoalOutcome(a.Outcome),
+		Tier:        a.Tier,
+		LastPursued: a.LastPursued,
+		Actions:     make([]Action, len(summaries)),
+	}
+	for i, s := range summaries {
+		status := ActionStatusPending
+		if s.Success {
+			status = ActionStatusCompleted
+		}
+		goal.Actions[i] = Action{ID: s.ID, Tool: s.Tool, Description: s.Description, Status: status, Result: s.Result, DependsOn: s.DependsOn}
+	}
+
+	var questions []string
+	if len(a.Questions) > 0 && json.Unmarshal(a.Questions, &questions) == nil && len(questions) > 0 {
+		goal.ResearchPlan = &ResearchPlan{SubQuestions: make([]ResearchQuestion, len(questions))}
+		for i, q := range questions {
+			goal.ResearchPlan.SubQuestions[i] = ResearchQuestion{Question: q}
+		}
+	}
+	if len(a.Notes) > 0 {
+		json.Unmarshal(a.Notes, &goal.Notes)
+	}
+	return goal
+}
+
+// splitCompletedGoals separates the oldest goals beyond retention from the ones kept
+func splitCompletedGoals(goals []Goal, retention int) (archive, keep []Goal) {
+	if retention <= 0 || len(goals) <= retention {
+		return nil, goals
+	}
+	cut := len(goals) - retention
+	return goals[:cut], goals[cut:]
+}
+
+// SetCompletedGoalRetention sets how many completed goals stay in the persisted state
+// (default 100) and whether the recently abandoned window used for reflection and
+// duplicate checks reaches into the archive when the state holds fewer goals than it
+func (sm *StateManager) SetCompletedGoalRetention(retention int, archiveLookup bool) {
+	if retention <= 0 {
+		retention = DefaultCompletedGoalRetention
+	}
+	sm.retention = retention
+	sm.archiveLookup = archiveLookup
+}
+
+// completedGoalRetention returns the configured retention or the default
+func (sm *StateManager) completedGoalRetention() int {
+	if sm.retention <= 0 {
+		return DefaultCompletedGoalRetention
+	}
+	return sm.retention
+}
+
+// archiveGoals inserts goals into the archive, oldest first, and drops their stored
+// action results. Goals already archived are skipped, so a save retried after a failure
+// does not duplicate them.
+func archiveGoals(tx *gorm.DB, goals []Goal) error {
+	if len(goals) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([]GoalArchive, len(goals))
+	ids := make([]string, len(goals))
+	for i, goal := range goals {
+		rows[i] = newGoalArchive(goal, now)
+		ids[i] = goal.ID
+	}
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "goal_id"}}, DoNothing: true}).
+		Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to archive completed goals: %w", err)
+	}
+	return deleteGoalResults(tx, ids)
+}
+
+// RecentArchivedGoals returns up to limit of the most recently archived goals, oldest
+// first like CompletedGoals. An empty status matches every goal.
+func (sm *StateManager) RecentArchivedGoals(ctx context.Context, status string, limit int) ([]Goal, error) {
+	query := sm.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var rows []GoalArchive
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load archived goals: %w", err)
+	}
+	goals := make([]Goal, len(rows))
+	for i, row := range rows {
+		goals[len(rows)-1-i] = row.goal()
+	}
+	return goals, nil
+}
+
+// ArchiveOversizedState moves completed goals beyond the retention out of the persisted
+// state. It runs at startup so state saved before retention existed is trimmed before
+// the first cycle loads it.
+func (sm *StateManager) ArchiveOversizedState(ctx context.Context) (int, error) {
+	state, err := sm.LoadState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	archive, keep := splitCompletedGoals(state.CompletedGoals, sm.completedGoalRetention())
+	if len(archive) == 0 {
+		return 0, nil
+	}
+
+	// Both goal columns are written, so a state still holding its goals inline moves to records
+	records := newRecordWriter(state.records)
+	activeGoals := records.manifest(truncateGoalsForStorage(state.ActiveGoals))
+	completedGoals := records.manifest(truncateGoalsForStorage(keep))
+	err = sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := archiveGoals(tx, archive); err != nil {
+			return err
+		}
+		if err := records.write(tx); err != nil {
+			return err
+		}
+		if err := tx.Model(&DialogueState{}).Where("id = ?", 1).Updates(map[string]interface{}{
+			"active_goals":    datatypes.JSON(activeGoals),
+			"completed_goals": datatypes.JSON(completedGoals),
+			"schema_version":  stateSchemaVersion,
+		}).Error; err != nil {
+			return err
+		}
+		return resealState(tx)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive oversized dialogue state: %w", err)
+	}
+	return len(archive), nil
+}
+
+// recentlyAbandonedGoals returns the abandoned goals among the last window completed
+// goals. When the state holds fewer than window and archive lookup is on, the rest of
+// the window is taken from the most recently archived goals.
+func (e *Engine) recentlyAbandonedGoals(ctx context.Context, state *InternalState, window int) []Goal {
+	recent := state.CompletedGoals
+	if len(recent) > window {
+		recent = recent[len(recent)-window:]
+	}
+	if short := window - len(recent); short > 0 && e.stateManager != nil && e.stateManager.archiveLookup {
+		archived, err := e.stateManager.RecentArchivedGoals(ctx, "", short)
+		if err != nil {
+			log.Printf("[Dialogue] WARNING: %v", err)
+		} else {
+			recent = append(archived, recent...)
+		}
+	}
+
+	abandoned := []Goal{}
+	for _, goal := range recent {
+		if goal.Status == GoalStatusAbandoned {
+			abandoned = append(abandoned, goal)
+		}
+	}
+	return abandoned
+}
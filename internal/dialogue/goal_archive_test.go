@@ -0,0 +1,71 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCompletedGoalsArchivesOldestBeyondRetention(t *testing.T) {
+	goals := []Goal{{ID: "g1"}, {ID: "g2"}, {ID: "g3"}, {ID: "g4"}}
+
+	archive, keep := splitCompletedGoals(goals, 3)
+	if len(archive) != 1 || archive[0].ID != "g1" {
+		t.Fatalf("expected the oldest goal archived, got %+v", archive)
+	}
+	if len(keep) != 3 || keep[0].ID != "g2" || keep[2].ID != "g4" {
+		t.Fatalf("expected the newest 3 goals kept, got %+v", keep)
+	}
+
+	if archive, keep := splitCompletedGoals(goals, 10); archive != nil || len(keep) != 4 {
+		t.Errorf("expected nothing archived under retention, got %d archived", len(archive))
+	}
+}
+
+func TestGoalArchiveSummarizesActions(t *testing.T) {
+	goal := Goal{
+		ID:          "goal_1",
+		Description: "Research vector databases",
+		Status:      GoalStatusAbandoned,
+		Outcome:     GoalOutcomeFailed,
+		Created:     time.Now().Add(-time.Hour),
+		Actions: []Action{
+			{Tool: ActionToolSearch, Description: "search", Status: ActionStatusCompleted, Result: strings.Repeat("x", 2000),
+				Metadata: map[string]interface{}{"url": "https://example.com"}},
+			{Tool: ActionToolSearch, Description: "retry", Status: ActionStatusCompleted, Result: "Error: timeout"},
+			{Tool: ActionToolSandbox, Description: "never ran", Status: ActionStatusPending},
+		},
+	}
+
+	summaries := summarizeActions(goal.Actions)
+	if len(summaries[0].Result) != maxActionResultLength || !summaries[0].Success {
+		t.Errorf("expected a successful action with a %d char result, got %d chars (success %v)",
+			maxActionResultLength, len(summaries[0].Result), summaries[0].Success)
+	}
+	if summaries[1].Success || summaries[2].Success {
+		t.Errorf("expected the failed and pending actions to be unsuccessful, got %+v", summaries[1:])
+	}
+
+	restored := newGoalArchive(goal, time.Now()).goal()
+	if restored.ID != goal.ID || restored.Status != GoalStatusAbandoned || restored.Outcome != GoalOutcomeFailed {
+		t.Errorf("unexpected restored goal %+v", restored)
+	}
+	if len(restored.Actions) != 3 || restored.Actions[0].Metadata != nil || restored.Actions[1].Status != ActionStatusPending {
+		t.Errorf("unexpected restored actions %+v", restored.Actions)
+	}
+}
+
+func TestRecentlyAbandonedGoalsUsesWindow(t *testing.T) {
+	state := &InternalState{CompletedGoals: []Goal{
+		{ID: "old", Status: GoalStatusAbandoned},
+		{ID: "a", Status: GoalStatusCompleted},
+		{ID: "b", Status: GoalStatusAbandoned},
+	}}
+
+	e := &Engine{}
+	abandoned := e.recentlyAbandonedGoals(context.Background(), state, 2)
+	if len(abandoned) != 1 || abandoned[0].ID != "b" {
+		t.Errorf("expected only the abandoned goal inside the window, got %+v", abandoned)
+	}
+}
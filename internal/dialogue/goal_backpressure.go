@@ -0,0 +1,143 @@
+// internal/dialogue/goal_backpressure.go
+package dialogue
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Defaults for a zero actions per cycle or backlog multiple
+const (
+	DefaultBacklogMultiple = 2.0
+	DefaultActionsPerCycle = 1
+)
+
+// BackpressureConfig keeps goal creation in step with execution. Cycles run about
+// ActionsPerCycle actions every CycleInterval; while the actions left across active
+// goals exceed BacklogMultiple days of that capacity, proposed goals are refused and,
+// with ShortestJobFirst, the scheduler prefers goals with the fewest steps left.
+type BackpressureConfig struct {
+	Enabled          bool
+	CycleInterval    time.Duration // Base time between cycles
+	ActionsPerCycle  int
+	BacklogMultiple  float64 // Days of capacity the backlog may hold
+	ShortestJobFirst bool
+}
+
+// Validate rejects an enabled config without a positive interval, or with a negative
+// rate or multiple; zero takes the default
+func (c BackpressureConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.CycleInterval <= 0 {
+		return fmt.Errorf("backpressure needs a positive base_interval_minutes, got %s", c.CycleInterval)
+	}
+	if c.ActionsPerCycle < 0 {
+		return fmt.Errorf("backpressure.actions_per_cycle must not be negative, got %d", c.ActionsPerCycle)
+	}
+	if c.BacklogMultiple < 0 {
+		return fmt.Errorf("backpressure.backlog_multiple must not be negative, got %.2f", c.BacklogMultiple)
+	}
+	return nil
+}
+
+// DailyCapacity estimates how many actions cycles execute in a day
+func (c BackpressureConfig) DailyCapacity() float64 {
+	if c.CycleInterval <= 0 {
+		return 0
+	}
+	return float64(24*time.Hour) / float64(c.CycleInterval) * float64(c.ActionsPerCycle)
+}
+
+// MaxBacklog is the backlog above which proposals are refused
+func (c BackpressureConfig) MaxBacklog() int {
+	return int(c.DailyCapacity() * c.BacklogMultiple)
+}
+
+// SetBackpressure configures when the action backlog stops new goal proposals
+func (e *Engine) SetBackpressure(cfg BackpressureConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.ActionsPerCycle == 0 {
+		cfg.ActionsPerCycle = DefaultActionsPerCycle
+	}
+	if cfg.BacklogMultiple == 0 {
+		cfg.BacklogMultiple = DefaultBacklogMultiple
+	}
+	e.backpressure = cfg
+	return nil
+}
+
+// remainingGoalActions estimates the actions a goal has left: its pending actions, plus
+// one search for each pending research question no pending action is working on yet
+func remainingGoalActions(goal *Goal) int {
+	remaining := 0
+	started := make(map[string]bool)
+	for _, action := range goal.Actions {
+		if action.Status != ActionStatusPending {
+			continue
+		}
+		remaining++
+		if id, ok := action.Metadata["research_question_id"].(string); ok {
+			started[id] = true
+		}
+	}
+	if goal.ResearchPlan != nil {
+		for _, q := range goal.ResearchPlan.SubQuestions {
+			if q.Status == ResearchStatusPending && !started[q.ID] {
+				remaining++
+			}
+		}
+	}
+	return remaining
+}
+
+// pendingActionBacklog sums the actions left across goals
+func pendingActionBacklog(goals []Goal) int {
+	backlog := 0
+	for i := range goals {
+		backlog += remainingGoalActions(&goals[i])
+	}
+	return backlog
+}
+
+// backlogFull reports whether the action backlog exceeds the configured multiple of
+// daily capacity, along with the backlog
+func (e *Engine) backlogFull(state *InternalState) (int, bool) {
+	backlog := pendingActionBacklog(state.ActiveGoals)
+	cfg := e.backpressure
+	return backlog, cfg.Enabled && backlog > cfg.MaxBacklog()
+}
+
+// applyBackpressure records the action backlog on the cycle's metrics and, with
+// shortest job first configured, has the scheduler prefer nearly finished goals while
+// the backlog is full
+func (e *Engine) applyBackpressure(state *InternalState, metrics *CycleMetrics) {
+	backlog, full := e.backlogFull(state)
+	metrics.PendingActionBacklog = backlog
+	if e.goalOrchestrator != nil {
+		e.goalOrchestrator.SetShortestJobFirst(full && e.backpressure.ShortestJobFirst)
+	}
+}
+
+// refuseGoalProposals drops the reflection's proposals while the backlog is full
+func (e *Engine) refuseGoalProposals(reasoning *ReasoningResponse, backlog int, metrics *CycleMetrics) {
+	refused := len(reasoning.GoalsToCreate.ToSlice())
+	metrics.GoalProposalsBackpressure += refused
+	log.Printf("[Dialogue] Refused %d proposed goals: %d actions pending, over the backlog limit of %d",
+		refused, backlog, e.backpressure.MaxBacklog())
+}
+
+// backpressureHint returns the reflection prompt's note that proposal slots are full,
+// or "" while the backlog has room
+func (e *Engine) backpressureHint(state *InternalState) string {
+	backlog, full := e.backlogFull(state)
+	if !full {
+		return ""
+	}
+	return fmt.Sprintf("\nNOTE: Goal proposal slots are full: %d actions are pending, more than %.0f days of execution capacity (about %.0f actions a day). New goals will not be accepted; focus on finishing the active ones.\n",
+		backlog, e.backpressure.BacklogMultiple, e.backpressure.DailyCapacity())
+}
@@ -0,0 +1,61 @@
+package dialogue
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackpressureRefusesProposalsOverCapacity(t *testing.T) {
+	e := &Engine{}
+	// 24 cycles a day at one action each: more than 48 actions left is over two days
+	if err := e.SetBackpressure(BackpressureConfig{Enabled: true, CycleInterval: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.backpressure.MaxBacklog(); got != 48 {
+		t.Fatalf("expected a backlog limit of 48 with the defaults, got %d", got)
+	}
+
+	research := Goal{ID: "goal_research", Actions: []Action{
+		{Status: ActionStatusPending, Metadata: map[string]interface{}{"research_question_id": "q1"}},
+		{Status: ActionStatusCompleted},
+	}, ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{
+		{ID: "q1", Status: ResearchStatusPending},
+		{ID: "q2", Status: ResearchStatusPending},
+		{ID: "q3", Status: ResearchStatusCompleted},
+	}}}
+	if got := remainingGoalActions(&research); got != 2 {
+		t.Errorf("expected the pending search and the unstarted question, got %d", got)
+	}
+
+	state := &InternalState{ActiveGoals: []Goal{research}}
+	for i := 0; i < 47; i++ {
+		state.ActiveGoals[0].Actions = append(state.ActiveGoals[0].Actions, Action{Status: ActionStatusPending})
+	}
+	metrics := &CycleMetrics{}
+	e.applyBackpressure(state, metrics)
+	if metrics.PendingActionBacklog != 49 {
+		t.Errorf("expected a backlog of 49, got %d", metrics.PendingActionBacklog)
+	}
+	if hint := e.backpressureHint(state); !strings.Contains(hint, "slots are full") || !strings.Contains(hint, "49 actions") {
+		t.Errorf("expected the reflection told proposal slots are full, got %q", hint)
+	}
+
+	reasoning := &ReasoningResponse{GoalsToCreate: GoalsOrString{{Description: "Learn about vector databases"}, {Description: "Compare embedding models"}}}
+	if _, full := e.backlogFull(state); !full {
+		t.Fatal("expected the backlog to be full")
+	}
+	e.refuseGoalProposals(reasoning, 49, metrics)
+	if metrics.GoalProposalsBackpressure != 2 || metrics.GoalProposalsReceived != 0 {
+		t.Errorf("expected both proposals refused without counting them received, got %+v", metrics)
+	}
+
+	state.ActiveGoals[0].Actions = state.ActiveGoals[0].Actions[:2]
+	if hint := e.backpressureHint(state); hint != "" {
+		t.Errorf("expected no note once the backlog has room, got %q", hint)
+	}
+	e.backpressure.Enabled = false
+	if err := e.SetBackpressure(BackpressureConfig{Enabled: true}); err == nil {
+		t.Error("expected an enabled config without an interval rejected")
+	}
+}
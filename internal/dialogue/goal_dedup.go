@@ -0,0 +1,222 @@
+// internal/dialogue/goal_dedup.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// GoalDedupConfig weighs the signals combined into one goal similarity score. Weights
+// need not sum to 1; they are normalized over the signals available for a comparison.
+type GoalDedupConfig struct {
+	StringWeight    float64 // Word-level edit similarity of the normalized descriptions
+	KeywordWeight   float64 // Jaccard overlap of significant keywords
+	EmbeddingWeight float64 // Rescaled cosine similarity of the description embeddings
+	Threshold       float64 // Scores at or above this are duplicates
+}
+
+// DefaultGoalDedupConfig returns weights calibrated against testdata/goal_pairs.json.
+// Distinct goals often share most of their wording ("research X in chatbots" vs "research
+// Y in chatbots") while paraphrases share little, so the text signals carry little weight
+// and mainly decide near-verbatim repeats and comparisons without embeddings.
+func DefaultGoalDedupConfig() GoalDedupConfig {
+	return GoalDedupConfig{
+		StringWeight:    0.05,
+		KeywordWeight:   0.05,
+		EmbeddingWeight: 0.90,
+		Threshold:       0.62,
+	}
+}
+
+// Validate rejects weights that cannot produce a meaningful score
+func (c GoalDedupConfig) Validate() error {
+	if c.StringWeight < 0 || c.KeywordWeight < 0 || c.EmbeddingWeight < 0 {
+		return fmt.Errorf("goal dedup weights must not be negative")
+	}
+	if c.StringWeight+c.KeywordWeight == 0 {
+		return fmt.Errorf("goal dedup needs a string or keyword weight for when embeddings are unavailable")
+	}
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		return fmt.Errorf("goal dedup threshold must be in (0, 1], got %.2f", c.Threshold)
+	}
+	return nil
+}
+
+// embeddingSimilarityFloor is the cosine similarity unrelated goal descriptions typically
+// reach; similarities are rescaled from [floor, 1] to [0, 1] so the embedding signal uses
+// the same range as the others
+const embeddingSimilarityFloor = 0.6
+
+// goalSimilarity holds the signals behind one pairwise score
+type goalSimilarity struct {
+	String       float64
+	Keyword      float64
+	Embedding    float64 // Rescaled; only meaningful when HasEmbedding
+	HasEmbedding bool
+	Score        float64
+}
+
+// scoreGoalPair combines the signals for two descriptions. cosine is the embedding
+// similarity, ignored when hasEmbedding is false.
+func scoreGoalPair(cfg GoalDedupConfig, a, b string, cosine float64, hasEmbedding bool) goalSimilarity {
+	normA, normB := normalizeGoalText(a), normalizeGoalText(b)
+	sim := goalSimilarity{
+		String:       wordEditSimilarity(strings.Fields(normA), strings.Fields(normB)),
+		Keyword:      calculateKeywordOverlap(goalKeywordStems(a), goalKeywordStems(b)),
+		HasEmbedding: hasEmbedding,
+	}
+	if normA == normB {
+		sim.Score = 1
+		return sim
+	}
+
+	weighted := cfg.StringWeight*sim.String + cfg.KeywordWeight*sim.Keyword
+	total := cfg.StringWeight + cfg.KeywordWeight
+	if hasEmbedding {
+		sim.Embedding = (cosine - embeddingSimilarityFloor) / (1 - embeddingSimilarityFloor)
+		if sim.Embedding < 0 {
+			sim.Embedding = 0
+		}
+		weighted += cfg.EmbeddingWeight * sim.Embedding
+		total += cfg.EmbeddingWeight
+	}
+	if total > 0 {
+		sim.Score = weighted / total
+	}
+	return sim
+}
+
+// explain describes a score for logs
+func (s goalSimilarity) explain(cfg GoalDedupConfig, existing string) string {
+	embedding := "embedding n/a"
+	if s.HasEmbedding {
+		embedding = fmt.Sprintf("embedding %.2f×%.2f", s.Embedding, cfg.EmbeddingWeight)
+	}
+	verdict := "<"
+	if s.Score >= cfg.Threshold {
+		verdict = ">="
+	}
+	return fmt.Sprintf("score %.2f %s %.2f vs '%s' (string %.2f×%.2f, keywords %.2f×%.2f, %s)",
+		s.Score, verdict, cfg.Threshold, truncate(existing, 50),
+		s.String, cfg.StringWeight, s.Keyword, cfg.KeywordWeight, embedding)
+}
+
+// isGoalDuplicate scores a proposed goal against existing goals and reports the closest
+// match, with an explanation of how its score was reached
+func (e *Engine) isGoalDuplicate(ctx context.Context, proposalDesc string, existingGoals []Goal) (bool, float64, string) {
+	i, score, explanation := e.closestGoal(ctx, proposalDesc, existingGoals)
+	return i >= 0 && score >= e.goalDedup.Threshold, score, explanation
+}
+
+// closestGoal returns the index and score of the existing goal most similar to the
+// proposal, or -1 when there are none, with an explanation of the score
+func (e *Engine) closestGoal(ctx context.Context, proposalDesc string, existingGoals []Goal) (int, float64, string) {
+	if len(existingGoals) == 0 {
+		return -1, 0, "no existing goals"
+	}
+	cfg := e.goalDedup
+
+	var proposalEmbedding []float32
+	if e.embedder != nil && cfg.EmbeddingWeight > 0 {
+		embedding, err := e.embedder.Embed(ctx, proposalDesc)
+		if err != nil {
+			// Don't block on embedding failure; score on text alone
+			log.Printf("[Dialogue] WARNING: Failed to generate embedding for duplicate check: %v", err)
+		} else {
+			proposalEmbedding = embedding
+		}
+	}
+
+	var best goalSimilarity
+	bestIndex := -1
+	for i, existingGoal := range existingGoals {
+		cosine, hasEmbedding := 0.0, false
+		if proposalEmbedding != nil {
+			if existingEmbedding, err := e.embedder.Embed(ctx, existingGoal.Description); err == nil {
+				cosine, hasEmbedding = cosineSimilarity(proposalEmbedding, existingEmbedding), true
+			}
+		}
+		sim := scoreGoalPair(cfg, proposalDesc, existingGoal.Description, cosine, hasEmbedding)
+		if bestIndex < 0 || sim.Score > best.Score {
+			best, bestIndex = sim, i
+		}
+	}
+
+	return bestIndex, best.Score, best.explain(cfg, existingGoals[bestIndex].Description)
+}
+
+// goalPrefixes are framing words goal descriptions often start with
+var goalPrefixes = []string{"learn about:", "learn about", "research", "develop", "create", "investigate", "explore", "understand"}
+
+// normalizeGoalText lowercases, strips punctuation and framing prefixes, and collapses spaces
+func normalizeGoalText(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, prefix := range goalPrefixes {
+		if strings.HasPrefix(text, prefix+" ") || strings.HasPrefix(text, prefix+":") {
+			text = text[len(prefix):]
+			break
+		}
+	}
+	words := strings.Fields(text)
+	cleaned := words[:0]
+	for _, word := range words {
+		word = strings.Trim(word, ".,;:!?—-\"'()")
+		if word != "" {
+			cleaned = append(cleaned, word)
+		}
+	}
+	return strings.Join(cleaned, " ")
+}
+
+// goalKeywordStems reduces significant keywords to crude stems so inflections of the
+// same word ("summarize", "summarizing") count as overlap
+func goalKeywordStems(text string) []string {
+	keywords := extractSignificantKeywords(text)
+	for i, keyword := range keywords {
+		keywords[i] = stemWord(keyword)
+	}
+	return keywords
+}
+
+// stemSuffixes are stripped longest first, keeping at least four characters
+var stemSuffixes = []string{"ations", "ation", "ings", "ing", "ies", "ed", "es", "s", "e"}
+
+func stemWord(word string) string {
+	for _, suffix := range stemSuffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 4 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// wordEditSimilarity is 1 minus the word-level edit distance over the longer length
+func wordEditSimilarity(a, b []string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(b)])/float64(longest)
+}
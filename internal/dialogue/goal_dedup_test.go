@@ -0,0 +1,101 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+type goalPairFixture struct {
+	A                   string  `json:"a"`
+	B                   string  `json:"b"`
+	EmbeddingSimilarity float64 `json:"embedding_similarity"`
+	Duplicate           bool    `json:"duplicate"`
+}
+
+func loadGoalPairs(t *testing.T) []goalPairFixture {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/goal_pairs.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var fixture struct {
+		Pairs []goalPairFixture `json:"pairs"`
+	}
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+	return fixture.Pairs
+}
+
+func TestDefaultGoalDedupConfigMatchesLabeledPairs(t *testing.T) {
+	cfg := DefaultGoalDedupConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+
+	for _, pair := range loadGoalPairs(t) {
+		sim := scoreGoalPair(cfg, pair.A, pair.B, pair.EmbeddingSimilarity, true)
+		if got := sim.Score >= cfg.Threshold; got != pair.Duplicate {
+			t.Errorf("%q vs %q: duplicate = %v, want %v (%s)",
+				pair.A, pair.B, got, pair.Duplicate, sim.explain(cfg, pair.B))
+		}
+	}
+}
+
+func TestScoreGoalPairWithoutEmbedding(t *testing.T) {
+	cfg := DefaultGoalDedupConfig()
+
+	sim := scoreGoalPair(cfg, "Learn about: Quantum error correction!", "learn about quantum error correction", 0, false)
+	if sim.Score != 1 {
+		t.Errorf("normalized repeat scored %.2f, want 1", sim.Score)
+	}
+
+	sim = scoreGoalPair(cfg, "Research memory consolidation in chatbots", "Research memory consolidation techniques for chatbots", 0, false)
+	if sim.Score < cfg.Threshold {
+		t.Errorf("near-verbatim repeat scored %.2f on text alone, want >= %.2f", sim.Score, cfg.Threshold)
+	}
+
+	sim = scoreGoalPair(cfg, "Learn about transformer attention mechanisms", "Learn about the history of the Roman Empire", 0, false)
+	if sim.Score >= cfg.Threshold {
+		t.Errorf("unrelated goals scored %.2f on text alone", sim.Score)
+	}
+	if !strings.Contains(sim.explain(cfg, "x"), "embedding n/a") {
+		t.Error("explanation should say the embedding signal was unavailable")
+	}
+}
+
+func TestIsGoalDuplicateExplainsClosestMatch(t *testing.T) {
+	e := &Engine{goalDedup: DefaultGoalDedupConfig()}
+	existing := []Goal{
+		{Description: "Learn about the history of the Roman Empire"},
+		{Description: "Research quantum error correction"},
+	}
+
+	dup, score, why := e.isGoalDuplicate(context.Background(), "research quantum error correction.", existing)
+	if !dup || score != 1 {
+		t.Fatalf("expected a duplicate scoring 1, got %v %.2f", dup, score)
+	}
+	if !strings.Contains(why, "quantum error correction") || !strings.Contains(why, ">=") {
+		t.Errorf("explanation should name the matched goal and verdict: %s", why)
+	}
+
+	if dup, _, _ := e.isGoalDuplicate(context.Background(), "anything", nil); dup {
+		t.Error("nothing can duplicate an empty goal list")
+	}
+}
+
+func TestValidateGoalDedupConfig(t *testing.T) {
+	bad := []GoalDedupConfig{
+		{StringWeight: -0.1, KeywordWeight: 0.5, EmbeddingWeight: 0.6, Threshold: 0.6},
+		{EmbeddingWeight: 1, Threshold: 0.6},
+		{StringWeight: 0.5, KeywordWeight: 0.5, Threshold: 0},
+	}
+	for _, cfg := range bad {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package dialogue
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrGoalSupportCycle is returned when a support link would close a cycle and is
+// weaker than every link already on that cycle
+var ErrGoalSupportCycle = errors.New("goal support link would create a cycle")
+
+// Goal tier constants
+const (
+	GoalTierPrimary   = "primary"
+	GoalTierSecondary = "secondary"
+	GoalTierTactical  = "tactical"
+)
+
+// Cascade outcomes for a secondary goal whose primary finished
+const (
+	CascadeRetained   = "retained"   // Still supports another active goal
+	CascadePromoted   = "promoted"   // No primaries left, became primary
+	CascadeRetargeted = "retargeted" // Linked to another primary
+	CascadeDowngraded = "downgraded" // No valid primary, became tactical
+)
+
+// SupportValidator decides which primary (if any) a secondary goal supports.
+// The engine backs this with validateGoalSupport.
+type SupportValidator func(secondary *Goal, primaries []Goal) (*GoalSupportValidation, error)
+
+// CascadeResult records what happened to one secondary goal during a cascade
+type CascadeResult struct {
+	GoalID    string `json:"goal_id"`
+	Action    string `json:"action"`
+	NewTarget string `json:"new_target,omitempty"`
+}
+
+// GoalGraph is the support graph over active and completed goals. Edges point from a
+// supporting goal to the goal it supports, weighted by the supporter's DependencyScore.
+// Nodes are pointers into the state slices, so mutations apply to the state directly.
+type GoalGraph struct {
+	nodes map[string]*Goal
+	order []string // Insertion order for stable output
+}
+
+// GoalGraphNode is the JSON view of a goal in the graph
+type GoalGraphNode struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Tier        string  `json:"tier"`
+	Status      string  `json:"status"`
+	Priority    int     `json:"priority"`
+	Progress    float64 `json:"progress"`
+}
+
+// GoalGraphEdge is the JSON view of a support link
+type GoalGraphEdge struct {
+	From       string  `json:"from"` // Supporting goal
+	To         string  `json:"to"`   // Supported goal
+	Confidence float64 `json:"confidence"`
+}
+
+// GoalGraphView is the serialisable form of a GoalGraph for visualisation
+type GoalGraphView struct {
+	Nodes []GoalGraphNode `json:"nodes"`
+	Edges []GoalGraphEdge `json:"edges"`
+}
+
+// NewGoalGraph builds the support graph from the state's active and completed goals
+func NewGoalGraph(state *InternalState) *GoalGraph {
+	g := &GoalGraph{nodes: make(map[string]*Goal)}
+	for i := range state.ActiveGoals {
+		g.addNode(&state.ActiveGoals[i])
+	}
+	for i := range state.CompletedGoals {
+		g.addNode(&state.CompletedGoals[i])
+	}
+	return g
+}
+
+func (g *GoalGraph) addNode(goal *Goal) {
+	if goal.ID == "" {
+		return
+	}
+	if _, exists := g.nodes[goal.ID]; !exists {
+		g.order = append(g.order, goal.ID)
+	}
+	g.nodes[goal.ID] = goal
+}
+
+// Goal returns the node for an ID, or nil
+func (g *GoalGraph) Goal(id string) *Goal {
+	return g.nodes[id]
+}
+
+// Supporters returns the goals that directly support the given goal
+func (g *GoalGraph) Supporters(id string) []*Goal {
+	supporters := []*Goal{}
+	for _, nodeID := range g.order {
+		node := g.nodes[nodeID]
+		for _, target := range node.SupportsGoals {
+			if target == id {
+				supporters = append(supporters, node)
+				break
+			}
+		}
+	}
+	return supporters
+}
+
+// pathTo returns a chain of goal IDs from -> ... -> to following support links,
+// or nil if to is unreachable
+func (g *GoalGraph) pathTo(from, to string) []string {
+	visited := map[string]bool{}
+	var walk func(id string) []string
+	walk = func(id string) []string {
+		if id == to {
+			return []string{id}
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		node := g.nodes[id]
+		if node == nil {
+			return nil
+		}
+		for _, next := range node.SupportsGoals {
+			if rest := walk(next); rest != nil {
+				return append([]string{id}, rest...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// WouldCreateCycle reports whether linking supporter -> target closes a cycle
+func (g *GoalGraph) WouldCreateCycle(supporterID, targetID string) bool {
+	if supporterID == targetID {
+		return true
+	}
+	return g.pathTo(targetID, supporterID) != nil
+}
+
+// AddSupport links supporter to target with the given confidence. If the link would
+// close a cycle, the weakest existing link on that cycle is removed when it is weaker
+// than the new one; otherwise ErrGoalSupportCycle is returned and nothing changes.
+func (g *GoalGraph) AddSupport(supporter *Goal, targetID string, confidence float64) error {
+	if supporter.ID == targetID {
+		return fmt.Errorf("goal %s cannot support itself: %w", supporter.ID, ErrGoalSupportCycle)
+	}
+	g.addNode(supporter)
+
+	if path := g.pathTo(targetID, supporter.ID); path != nil {
+		// Find the weakest link along target -> ... -> supporter
+		weakFrom, weakTo := "", ""
+		weakScore := confidence
+		for i := 0; i < len(path)-1; i++ {
+			node := g.nodes[path[i]]
+			if node.DependencyScore < weakScore {
+				weakFrom, weakTo, weakScore = path[i], path[i+1], node.DependencyScore
+			}
+		}
+		if weakFrom == "" {
+			return fmt.Errorf("%s -> %s: %w", supporter.ID, targetID, ErrGoalSupportCycle)
+		}
+		g.removeSupport(g.nodes[weakFrom], weakTo)
+	}
+
+	for _, existing := range supporter.SupportsGoals {
+		if existing == targetID {
+			supporter.DependencyScore = confidence
+			return nil
+		}
+	}
+	supporter.SupportsGoals = append(supporter.SupportsGoals, targetID)
+	supporter.DependencyScore = confidence
+	return nil
+}
+
+// removeSupport drops one outgoing link from a goal
+func (g *GoalGraph) removeSupport(goal *Goal, targetID string) {
+	kept := goal.SupportsGoals[:0]
+	for _, id := range goal.SupportsGoals {
+		if id != targetID {
+			kept = append(kept, id)
+		}
+	}
+	goal.SupportsGoals = kept
+}
+
+// activePrimaries lists active primary goals, excluding one ID
+func (g *GoalGraph) activePrimaries(excludeID string) []Goal {
+	primaries := []Goal{}
+	for _, id := range g.order {
+		node := g.nodes[id]
+		if id != excludeID && node.Tier == GoalTierPrimary && node.Status == GoalStatusActive {
+			primaries = append(primaries, *node)
+		}
+	}
+	return primaries
+}
+
+// supportsActiveGoal reports whether any of the goal's links point to an active goal
+func (g *GoalGraph) supportsActiveGoal(goal *Goal) bool {
+	for _, id := range goal.SupportsGoals {
+		if target := g.nodes[id]; target != nil && target.Status == GoalStatusActive {
+			return true
+		}
+	}
+	return false
+}
+
+// Cascade re-evaluates the active goals that supported a primary which has just been
+// completed or abandoned. Each supporter is unlinked from the finished primary, then:
+// kept if it still supports another active goal, promoted to primary when no other
+// primary exists, retargeted when the validator finds a new primary, or downgraded to
+// tactical otherwise.
+func (g *GoalGraph) Cascade(finishedID string, validate SupportValidator) []CascadeResult {
+	results := []CascadeResult{}
+	for _, supporter := range g.Supporters(finishedID) {
+		if supporter.Status != GoalStatusActive {
+			continue
+		}
+		g.removeSupport(supporter, finishedID)
+
+		if g.supportsActiveGoal(supporter) {
+			results = append(results, CascadeResult{GoalID: supporter.ID, Action: CascadeRetained})
+			continue
+		}
+
+		// Candidate primaries must not route back to this goal
+		candidates := []Goal{}
+		for _, p := range g.activePrimaries(finishedID) {
+			if p.ID != supporter.ID && !g.WouldCreateCycle(supporter.ID, p.ID) {
+				candidates = append(candidates, p)
+			}
+		}
+
+		if len(candidates) == 0 {
+			supporter.Tier = GoalTierPrimary
+			supporter.DependencyScore = 0
+			results = append(results, CascadeResult{GoalID: supporter.ID, Action: CascadePromoted})
+			continue
+		}
+
+		if validate != nil {
+			validation, err := validate(supporter, candidates)
+			if err == nil && validation != nil && validation.IsValid && g.isCandidate(validation.SupportsGoalID, candidates) {
+				if err := g.AddSupport(supporter, validation.SupportsGoalID, validation.Confidence); err == nil {
+					results = append(results, CascadeResult{
+						GoalID:    supporter.ID,
+						Action:    CascadeRetargeted,
+						NewTarget: validation.SupportsGoalID,
+					})
+					continue
+				}
+			}
+		}
+
+		supporter.Tier = GoalTierTactical
+		supporter.DependencyScore = 0
+		results = append(results, CascadeResult{GoalID: supporter.ID, Action: CascadeDowngraded})
+	}
+	return results
+}
+
+func (g *GoalGraph) isCandidate(id string, candidates []Goal) bool {
+	for _, c := range candidates {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// View returns the graph as nodes and edges for JSON consumers
+func (g *GoalGraph) View() GoalGraphView {
+	view := GoalGraphView{Nodes: []GoalGraphNode{}, Edges: []GoalGraphEdge{}}
+	for _, id := range g.order {
+		node := g.nodes[id]
+		view.Nodes = append(view.Nodes, GoalGraphNode{
+			ID:          node.ID,
+			Description: node.Description,
+			Tier:        node.Tier,
+			Status:      node.Status,
+			Priority:    node.Priority,
+			Progress:    node.Progress,
+		})
+		targets := append([]string(nil), node.SupportsGoals...)
+		sort.Strings(targets)
+		for _, target := range targets {
+			view.Edges = append(view.Edges, GoalGraphEdge{From: node.ID, To: target, Confidence: node.DependencyScore})
+		}
+	}
+	return view
+}
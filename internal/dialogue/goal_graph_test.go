@@ -0,0 +1,125 @@
+package dialogue
+
+import (
+	"errors"
+	"testing"
+)
+
+func testGoal(id, tier string, supports ...string) Goal {
+	return Goal{ID: id, Tier: tier, Status: GoalStatusActive, SupportsGoals: supports}
+}
+
+func TestGoalGraphDetectsCycles(t *testing.T) {
+	state := &InternalState{ActiveGoals: []Goal{
+		testGoal("a", GoalTierSecondary, "b"),
+		testGoal("b", GoalTierSecondary, "c"),
+		testGoal("c", GoalTierPrimary),
+	}}
+	state.ActiveGoals[0].DependencyScore = 0.9
+	state.ActiveGoals[1].DependencyScore = 0.9
+	graph := NewGoalGraph(state)
+
+	if !graph.WouldCreateCycle("c", "a") {
+		t.Error("expected c -> a to close a cycle")
+	}
+	if !graph.WouldCreateCycle("a", "a") {
+		t.Error("expected a self-link to be a cycle")
+	}
+	if graph.WouldCreateCycle("a", "c") {
+		t.Error("a -> c does not close a cycle")
+	}
+
+	// Weaker than every link on the cycle: rejected, nothing changes
+	err := graph.AddSupport(&state.ActiveGoals[2], "a", 0.5)
+	if !errors.Is(err, ErrGoalSupportCycle) {
+		t.Fatalf("expected ErrGoalSupportCycle, got %v", err)
+	}
+	if len(state.ActiveGoals[2].SupportsGoals) != 0 {
+		t.Errorf("rejected link was recorded: %v", state.ActiveGoals[2].SupportsGoals)
+	}
+}
+
+func TestGoalGraphBreaksWeakerLink(t *testing.T) {
+	state := &InternalState{ActiveGoals: []Goal{
+		testGoal("a", GoalTierSecondary, "b"),
+		testGoal("b", GoalTierSecondary, "c"),
+		testGoal("c", GoalTierSecondary),
+	}}
+	state.ActiveGoals[0].DependencyScore = 0.9
+	state.ActiveGoals[1].DependencyScore = 0.2 // Weakest link on a -> b -> c
+	graph := NewGoalGraph(state)
+
+	if err := graph.AddSupport(&state.ActiveGoals[2], "a", 0.8); err != nil {
+		t.Fatalf("expected weaker link to be broken, got %v", err)
+	}
+	if len(state.ActiveGoals[1].SupportsGoals) != 0 {
+		t.Errorf("expected b -> c to be removed, got %v", state.ActiveGoals[1].SupportsGoals)
+	}
+	if got := state.ActiveGoals[2].SupportsGoals; len(got) != 1 || got[0] != "a" {
+		t.Errorf("expected c -> a, got %v", got)
+	}
+	if graph.WouldCreateCycle("a", "b") {
+		t.Error("graph still contains a cycle")
+	}
+}
+
+func TestGoalGraphCascade(t *testing.T) {
+	state := &InternalState{ActiveGoals: []Goal{
+		testGoal("p1", GoalTierPrimary),
+		testGoal("p2", GoalTierPrimary),
+		testGoal("keep", GoalTierSecondary, "p1", "p2"),
+		testGoal("move", GoalTierSecondary, "p1"),
+		testGoal("drop", GoalTierSecondary, "p1"),
+	}}
+	state.ActiveGoals[0].Status = GoalStatusCompleted
+	graph := NewGoalGraph(state)
+
+	validate := func(secondary *Goal, primaries []Goal) (*GoalSupportValidation, error) {
+		if len(primaries) != 1 || primaries[0].ID != "p2" {
+			t.Errorf("unexpected candidates for %s: %v", secondary.ID, primaries)
+		}
+		if secondary.ID == "move" {
+			return &GoalSupportValidation{SupportsGoalID: "p2", Confidence: 0.7, IsValid: true}, nil
+		}
+		return &GoalSupportValidation{IsValid: false}, nil
+	}
+
+	results := graph.Cascade("p1", validate)
+	got := map[string]CascadeResult{}
+	for _, r := range results {
+		got[r.GoalID] = r
+	}
+
+	if got["keep"].Action != CascadeRetained {
+		t.Errorf("keep: expected retained, got %q", got["keep"].Action)
+	}
+	if got["move"].Action != CascadeRetargeted || got["move"].NewTarget != "p2" {
+		t.Errorf("move: expected retargeted to p2, got %+v", got["move"])
+	}
+	if got["drop"].Action != CascadeDowngraded || state.ActiveGoals[4].Tier != GoalTierTactical {
+		t.Errorf("drop: expected downgraded to tactical, got %+v (tier %s)", got["drop"], state.ActiveGoals[4].Tier)
+	}
+	if sup := state.ActiveGoals[2].SupportsGoals; len(sup) != 1 || sup[0] != "p2" {
+		t.Errorf("keep: expected link to p1 removed, got %v", sup)
+	}
+}
+
+func TestGoalGraphCascadePromotesWithoutPrimaries(t *testing.T) {
+	state := &InternalState{ActiveGoals: []Goal{
+		testGoal("p1", GoalTierPrimary),
+		testGoal("s", GoalTierSecondary, "p1"),
+	}}
+	state.ActiveGoals[0].Status = GoalStatusAbandoned
+	graph := NewGoalGraph(state)
+
+	results := graph.Cascade("p1", func(*Goal, []Goal) (*GoalSupportValidation, error) {
+		t.Error("validator should not be called without candidate primaries")
+		return nil, nil
+	})
+	if len(results) != 1 || results[0].Action != CascadePromoted {
+		t.Fatalf("expected promotion, got %+v", results)
+	}
+	if state.ActiveGoals[1].Tier != GoalTierPrimary || len(state.ActiveGoals[1].SupportsGoals) != 0 {
+		t.Errorf("unexpected goal after promotion: %+v", state.ActiveGoals[1])
+	}
+}
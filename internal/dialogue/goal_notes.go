@@ -0,0 +1,105 @@
+// internal/dialogue/goal_notes.go
+package dialogue
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-llama/internal/utils"
+)
+
+// Goal notes are bounded so the scratchpad cannot crowd out the rest of a prompt
+const (
+	maxGoalNotes     = 10  // Oldest notes are dropped first
+	maxGoalNoteChars = 300 // Longer notes are cut
+)
+
+// GoalNote is a short conclusion the model recorded about a goal, so later cycles start
+// from it instead of working it out again
+type GoalNote struct {
+	Text    string    `json:"text"`
+	Cycle   int       `json:"cycle"`
+	Created time.Time `json:"created"`
+}
+
+// goalNotesInstruction asks a prompt for notes on the goal it concerns
+const goalNotesInstruction = `Optionally record short facts worth remembering for this goal in later cycles (e.g. which source to prefer, what already failed):
+(goal_notes (note "...") (note "..."))`
+
+// parseGoalNotes extracts the notes of every (goal_notes (note "...")) block in raw
+func parseGoalNotes(raw string) []string {
+	notes := []string{}
+	for _, block := range findBlocksRecursive(raw, "goal_notes") {
+		notes = append(notes, extractMultipleFieldContents(block, "note")...)
+	}
+	return notes
+}
+
+// addGoalNotes appends notes to the goal, skipping blanks and notes it already has, and
+// drops the oldest beyond maxGoalNotes. It returns how many were added.
+func addGoalNotes(goal *Goal, notes []string, cycle int) int {
+	added := 0
+	for _, text := range notes {
+		text = strings.TrimSpace(text)
+		if len(text) > maxGoalNoteChars {
+			text = strings.TrimSpace(utils.Prefix(text, maxGoalNoteChars))
+		}
+		if text == "" || hasGoalNote(goal, text) {
+			continue
+		}
+		goal.Notes = append(goal.Notes, GoalNote{Text: text, Cycle: cycle, Created: time.Now()})
+		added++
+	}
+	if len(goal.Notes) > maxGoalNotes {
+		goal.Notes = append([]GoalNote(nil), goal.Notes[len(goal.Notes)-maxGoalNotes:]...)
+	}
+	return added
+}
+
+func hasGoalNote(goal *Goal, text string) bool {
+	for _, note := range goal.Notes {
+		if strings.EqualFold(note.Text, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordGoalNotes adds the notes in a model response to the goal it concerned
+func (e *Engine) recordGoalNotes(goal *Goal, raw string) {
+	if goal == nil {
+		return
+	}
+	if added := addGoalNotes(goal, parseGoalNotes(raw), int(e.currentCycle.Load())); added > 0 {
+		log.Printf("[Dialogue] Recorded %d notes on goal %s (%d kept)", added, goal.ID, len(goal.Notes))
+	}
+}
+
+// goalNotesContext lists a goal's notes for a prompt, oldest first
+func goalNotesContext(goal *Goal) string {
+	if len(goal.Notes) == 0 {
+		return ""
+	}
+	text := "YOUR NOTES ON THIS GOAL (from earlier cycles):\n"
+	for _, note := range goal.Notes {
+		text += fmt.Sprintf("- %s (cycle #%d)\n", note.Text, note.Cycle)
+	}
+	return text
+}
+
+// pursuedGoal returns the active goal worked on most recently, or nil if none has been
+func pursuedGoal(state *InternalState) *Goal {
+	var pursued *Goal
+	for i := range state.ActiveGoals {
+		g := &state.ActiveGoals[i]
+		if g.LastPursued.IsZero() {
+			continue
+		}
+		if pursued == nil || g.LastPursued.After(pursued.LastPursued) {
+			pursued = g
+		}
+	}
+	return pursued
+}
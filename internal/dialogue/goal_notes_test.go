@@ -0,0 +1,101 @@
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGoalNotesAreBounded(t *testing.T) {
+	goal := &Goal{ID: "goal_1"}
+	notes := []string{strings.Repeat("a", maxGoalNoteChars+50), "  ", "Prefer the official docs"}
+	for i := 0; i < maxGoalNotes; i++ {
+		notes = append(notes, fmt.Sprintf("note %d", i))
+	}
+	notes = append(notes, "prefer the official docs")
+
+	if added := addGoalNotes(goal, notes, 3); added != maxGoalNotes+2 {
+		t.Errorf("expected blanks and repeats skipped, got %d added", added)
+	}
+	if len(goal.Notes) != maxGoalNotes || goal.Notes[0].Text != "note 0" || goal.Notes[maxGoalNotes-1].Text != fmt.Sprintf("note %d", maxGoalNotes-1) {
+		t.Fatalf("expected the %d newest notes kept, got %+v", maxGoalNotes, goal.Notes)
+	}
+
+	goal.Notes = nil
+	addGoalNotes(goal, notes[:1], 3)
+	if len(goal.Notes[0].Text) != maxGoalNoteChars || goal.Notes[0].Cycle != 3 {
+		t.Errorf("expected a long note cut to %d chars, got %d", maxGoalNoteChars, len(goal.Notes[0].Text))
+	}
+}
+
+func TestParseGoalNotes(t *testing.T) {
+	raw := `(assessment (progress_quality "good") (recommendation "continue"))
+(goal_notes (note "Official docs are at go.dev/doc") (note "Blog posts are outdated"))`
+	notes := parseGoalNotes(raw)
+	if len(notes) != 2 || notes[0] != "Official docs are at go.dev/doc" || notes[1] != "Blog posts are outdated" {
+		t.Errorf("unexpected notes %q", notes)
+	}
+	if notes := parseGoalNotes(`(assessment (reasoning "no notes"))`); len(notes) != 0 {
+		t.Errorf("expected no notes, got %q", notes)
+	}
+}
+
+func TestGoalNoteWrittenInOneCycleReachesTheNext(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&memory.Principle{}); err != nil {
+		t.Fatal(err)
+	}
+	caller := &completionCaller{replies: []string{
+		`(assessment (progress_quality "good") (plan_validity "valid") (reasoning "Found the docs.") (recommendation "continue"))
+(goal_notes (note "The official docs are at go.dev/doc, prefer those"))`,
+		"Read the generics tutorial next.",
+	}}
+	e := &Engine{db: db, llmClient: caller, modelRouter: NewModelRouter("http://reasoning", "8b", "", "")}
+	goal := &Goal{ID: "goal_1", Description: "Learn Go generics", LastPursued: time.Now()}
+
+	e.currentCycle.Store(4)
+	if _, _, err := e.assessProgress(context.Background(), goal); err != nil {
+		t.Fatal(err)
+	}
+	if len(goal.Notes) != 1 || goal.Notes[0].Cycle != 4 {
+		t.Fatalf("expected the assessment's note recorded in cycle 4, got %+v", goal.Notes)
+	}
+
+	e.currentCycle.Store(5)
+	if _, _, err := e.thinkAboutGoal(context.Background(), goal); err != nil {
+		t.Fatal(err)
+	}
+	if prompt := caller.prompts[1]; !strings.Contains(prompt, "- The official docs are at go.dev/doc, prefer those (cycle #4)") {
+		t.Errorf("expected the note in the next cycle's prompt, got %q", prompt)
+	}
+
+	restored := newGoalArchive(*goal, time.Now()).goal()
+	if len(restored.Notes) != 1 || restored.Notes[0].Text != goal.Notes[0].Text {
+		t.Errorf("expected the notes kept in the archive, got %+v", restored.Notes)
+	}
+}
+
+func TestPursuedGoalIsMostRecent(t *testing.T) {
+	now := time.Now()
+	state := &InternalState{ActiveGoals: []Goal{
+		{ID: "never"},
+		{ID: "older", LastPursued: now.Add(-time.Hour)},
+		{ID: "latest", LastPursued: now},
+	}}
+	if g := pursuedGoal(state); g == nil || g.ID != "latest" {
+		t.Errorf("expected the latest pursued goal, got %+v", g)
+	}
+	if g := pursuedGoal(&InternalState{ActiveGoals: []Goal{{ID: "never"}}}); g != nil {
+		t.Errorf("expected no pursued goal, got %+v", g)
+	}
+}
@@ -0,0 +1,141 @@
+// internal/dialogue/goal_outcome.go
+package dialogue
+
+import "encoding/json"
+
+// GoalOutcome is how a finished goal turned out. Active goals have none.
+type GoalOutcome string
+
+const (
+	GoalOutcomeSuccess        GoalOutcome = "success"          // Finished with every question answered
+	GoalOutcomePartialSuccess GoalOutcome = "partial_success"  // Finished, but some of its work came to nothing
+	GoalOutcomeFailed         GoalOutcome = "failed"           // Abandoned after its actions failed
+	GoalOutcomeNoUsefulOutput GoalOutcome = "no_useful_output" // Ended without failing, and without anything worth keeping
+	GoalOutcomeExpired        GoalOutcome = "expired"          // Went stale or passed its deadline
+	GoalOutcomeUserCancelled  GoalOutcome = "user_cancelled"   // A user asked for it to stop
+)
+
+// legacyGoalOutcomes maps the outcomes goals were saved with before GoalOutcome
+var legacyGoalOutcomes = map[string]GoalOutcome{
+	"good":    GoalOutcomeSuccess,
+	"bad":     GoalOutcomeFailed,
+	"neutral": GoalOutcomeNoUsefulOutput,
+}
+
+// ParseGoalOutcome reads a stored outcome, mapping the legacy "good", "bad" and
+// "neutral". Anything unrecognised reads as no outcome.
+func ParseGoalOutcome(s string) GoalOutcome {
+	if legacy, ok := legacyGoalOutcomes[s]; ok {
+		return legacy
+	}
+	switch o := GoalOutcome(s); o {
+	case GoalOutcomeSuccess, GoalOutcomePartialSuccess, GoalOutcomeFailed,
+		GoalOutcomeNoUsefulOutput, GoalOutcomeExpired, GoalOutcomeUserCancelled:
+		return o
+	}
+	return ""
+}
+
+// UnmarshalJSON maps legacy outcomes in persisted state
+func (o *GoalOutcome) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*o = ParseGoalOutcome(s)
+	return nil
+}
+
+// Succeeded reports whether the goal achieved at least part of what it set out to
+func (o GoalOutcome) Succeeded() bool {
+	return o == GoalOutcomeSuccess || o == GoalOutcomePartialSuccess
+}
+
+// goalEnd is why a goal leaves the active list
+type goalEnd int
+
+const (
+	goalEndCompleted goalEnd = iota // Its work is done
+	goalEndAbandoned                // Given up, usually after failures
+	goalEndExpired                  // Stale or past its deadline
+	goalEndCancelled                // Cancelled by a user
+)
+
+// closeGoal ends a goal, setting its status and the outcome evaluateGoalOutcome gives it
+func closeGoal(goal *Goal, end goalEnd) {
+	if end == goalEndCompleted {
+		goal.Status = GoalStatusCompleted
+	} else {
+		goal.Status = GoalStatusAbandoned
+	}
+	goal.Outcome = evaluateGoalOutcome(goal, end)
+}
+
+// evaluateGoalOutcome is the one place a goal's outcome is decided. Action failures
+// along the way do not decide it: a completed goal is judged by what it produced, and
+// only an abandoned one by whether it failed.
+func evaluateGoalOutcome(goal *Goal, end goalEnd) GoalOutcome {
+	switch end {
+	case goalEndCancelled:
+		return GoalOutcomeUserCancelled
+	case goalEndExpired:
+		return GoalOutcomeExpired
+	case goalEndAbandoned:
+		if goal.FailureCount > 0 || goal.LastActionFailed {
+			return GoalOutcomeFailed
+		}
+		for _, action := range goal.Actions {
+			if actionFailed(action) {
+				return GoalOutcomeFailed
+			}
+		}
+		return GoalOutcomeNoUsefulOutput
+	}
+
+	if !producedOutput(goal) {
+		return GoalOutcomeNoUsefulOutput
+	}
+	if goal.Progress < 1 {
+		return GoalOutcomePartialSuccess
+	}
+	if plan := goal.ResearchPlan; plan != nil {
+		for _, q := range plan.SubQuestions {
+			if q.Status != ResearchStatusCompleted {
+				return GoalOutcomePartialSuccess
+			}
+		}
+	}
+	return GoalOutcomeSuccess
+}
+
+// producedOutput reports whether a goal has anything to show: a validated principle
+// change, a research finding or a successful action's result
+func producedOutput(goal *Goal) bool {
+	if goal.SelfModGoal != nil && goal.SelfModGoal.ValidationStatus == "validated" {
+		return true
+	}
+	if plan := goal.ResearchPlan; plan != nil {
+		for _, q := range plan.SubQuestions {
+			if q.KeyFindings != "" {
+				return true
+			}
+		}
+	}
+	for _, action := range goal.Actions {
+		if action.Status == ActionStatusCompleted && action.Result != "" && !actionFailed(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// noteActionOutcome records on a still-active goal whether its latest action failed,
+// counting consecutive failures. It never sets the outcome, which waits for the goal to end.
+func noteActionOutcome(goal *Goal, failed bool) {
+	goal.LastActionFailed = failed
+	if failed {
+		goal.FailureCount++
+	} else {
+		goal.FailureCount = 0
+	}
+}
@@ -0,0 +1,74 @@
+package dialogue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLegacyGoalOutcomesMapOnLoad(t *testing.T) {
+	var state InternalState
+	raw := `{"completed_goals":[{"id":"a","outcome":"good"},{"id":"b","outcome":"bad"},{"id":"c","outcome":"neutral"},{"id":"d","outcome":"partial_success"},{"id":"e","outcome":"bogus"}]}`
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		t.Fatal(err)
+	}
+	want := []GoalOutcome{GoalOutcomeSuccess, GoalOutcomeFailed, GoalOutcomeNoUsefulOutput, GoalOutcomePartialSuccess, ""}
+	for i, goal := range state.CompletedGoals {
+		if goal.Outcome != want[i] {
+			t.Errorf("goal %s: expected %q, got %q", goal.ID, want[i], goal.Outcome)
+		}
+	}
+
+	if got := (GoalArchive{GoalID: "x", Outcome: "bad"}).goal().Outcome; got != GoalOutcomeFailed {
+		t.Errorf("expected an archived legacy outcome mapped, got %q", got)
+	}
+}
+
+func TestEvaluateGoalOutcome(t *testing.T) {
+	answered := &ResearchPlan{SubQuestions: []ResearchQuestion{{ID: "q1", Status: ResearchStatusCompleted, KeyFindings: "found it"}}}
+	halfAnswered := &ResearchPlan{SubQuestions: []ResearchQuestion{
+		{ID: "q1", Status: ResearchStatusCompleted, KeyFindings: "found it"},
+		{ID: "q2", Status: ResearchStatusPending},
+	}}
+	failedSearch := Action{Tool: ActionToolSearch, Status: ActionStatusCompleted, FailureKind: "timeout"}
+
+	cases := []struct {
+		name string
+		goal Goal
+		end  goalEnd
+		want GoalOutcome
+	}{
+		{"answered despite a failed action", Goal{Progress: 1, ResearchPlan: answered, Actions: []Action{failedSearch}, LastActionFailed: true}, goalEndCompleted, GoalOutcomeSuccess},
+		{"questions left open", Goal{Progress: 1, ResearchPlan: halfAnswered}, goalEndCompleted, GoalOutcomePartialSuccess},
+		{"completed with nothing found", Goal{Progress: 1, Actions: []Action{failedSearch}}, goalEndCompleted, GoalOutcomeNoUsefulOutput},
+		{"abandoned after failures", Goal{FailureCount: 3}, goalEndAbandoned, GoalOutcomeFailed},
+		{"abandoned after a failed action", Goal{Actions: []Action{failedSearch}}, goalEndAbandoned, GoalOutcomeFailed},
+		{"abandoned quietly", Goal{}, goalEndAbandoned, GoalOutcomeNoUsefulOutput},
+		{"stale", Goal{FailureCount: 2}, goalEndExpired, GoalOutcomeExpired},
+		{"cancelled", Goal{Progress: 1, ResearchPlan: answered}, goalEndCancelled, GoalOutcomeUserCancelled},
+	}
+	for _, c := range cases {
+		if got := evaluateGoalOutcome(&c.goal, c.end); got != c.want {
+			t.Errorf("%s: expected %q, got %q", c.name, c.want, got)
+		}
+	}
+}
+
+func TestActionFailureLeavesActiveGoalWithoutOutcome(t *testing.T) {
+	goal := &Goal{Status: GoalStatusActive, ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{
+		{ID: "q1", Status: ResearchStatusCompleted, KeyFindings: "found it"},
+	}}}
+	noteActionOutcome(goal, true)
+	if goal.Outcome != "" || !goal.LastActionFailed || goal.FailureCount != 1 {
+		t.Fatalf("expected the failure tracked without an outcome, got %+v", goal)
+	}
+	noteActionOutcome(goal, false)
+	if goal.LastActionFailed || goal.FailureCount != 0 {
+		t.Errorf("expected a success to clear the failure streak, got %+v", goal)
+	}
+
+	goal.Progress = 1
+	closeGoal(goal, goalEndCompleted)
+	if goal.Status != GoalStatusCompleted || goal.Outcome != GoalOutcomeSuccess {
+		t.Errorf("expected the goal to succeed after its earlier failure, got %s/%s", goal.Status, goal.Outcome)
+	}
+}
@@ -0,0 +1,111 @@
+// internal/dialogue/goal_proposal_stats.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+)
+
+// minProposalsForFeedback is how many proposals the window must hold before a low
+// acceptance rate is pointed out to the reflection
+const minProposalsForFeedback = 5
+
+// GoalProposalStats sums what became of the goals the reflection proposed over a
+// number of cycles
+type GoalProposalStats struct {
+	Cycles             int     `json:"cycles"`
+	Received           int     `json:"received"`
+	Accepted           int     `json:"accepted"`
+	Invalid            int     `json:"invalid"`
+	DuplicateActive    int     `json:"duplicate_active"`
+	DuplicateAbandoned int     `json:"duplicate_abandoned"`
+	Merged             int     `json:"merged"` // Folded into a sibling from the same response
+	Downgraded         int     `json:"downgraded"`
+	Clamped            int     `json:"clamped"`
+	Backpressure       int     `json:"backpressure"`    // Refused unchecked for a full action backlog; not in received
+	AcceptanceRate     float64 `json:"acceptance_rate"` // Accepted of received, 0 with none received
+	DuplicateRate      float64 `json:"duplicate_rate"`  // Duplicates of active or abandoned goals, of received
+}
+
+// add folds one cycle's counts into the stats
+func (s *GoalProposalStats) add(c DialogueMetrics) {
+	s.Cycles++
+	s.Received += c.GoalProposalsReceived
+	s.Accepted += c.GoalProposalsAccepted
+	s.Invalid += c.GoalProposalsInvalid
+	s.DuplicateActive += c.GoalProposalsDuplicateActive
+	s.DuplicateAbandoned += c.GoalProposalsDuplicateAbandoned
+	s.Merged += c.GoalsMerged
+	s.Downgraded += c.GoalProposalsDowngraded
+	s.Clamped += c.GoalProposalsClamped
+	s.Backpressure += c.GoalProposalsBackpressure
+}
+
+// rates derives the acceptance and duplicate rates from the counts
+func (s *GoalProposalStats) rates() {
+	if s.Received == 0 {
+		return
+	}
+	s.AcceptanceRate = float64(s.Accepted) / float64(s.Received)
+	s.DuplicateRate = float64(s.DuplicateActive+s.DuplicateAbandoned) / float64(s.Received)
+}
+
+// SummarizeGoalProposals sums the proposal counts of the given cycles
+func SummarizeGoalProposals(cycles []DialogueMetrics) GoalProposalStats {
+	stats := GoalProposalStats{}
+	for _, c := range cycles {
+		stats.add(c)
+	}
+	stats.rates()
+	return stats
+}
+
+// GoalProposalTotals sums the proposal counts of every recorded cycle
+func (sm *StateManager) GoalProposalTotals(ctx context.Context) (GoalProposalStats, error) {
+	var stats GoalProposalStats
+	err := sm.db.WithContext(ctx).Model(&DialogueMetrics{}).Select(`COUNT(*) AS cycles,
+		COALESCE(SUM(goal_proposals_received), 0) AS received,
+		COALESCE(SUM(goal_proposals_accepted), 0) AS accepted,
+		COALESCE(SUM(goal_proposals_invalid), 0) AS invalid,
+		COALESCE(SUM(goal_proposals_duplicate_active), 0) AS duplicate_active,
+		COALESCE(SUM(goal_proposals_duplicate_abandoned), 0) AS duplicate_abandoned,
+		COALESCE(SUM(goals_merged), 0) AS merged,
+		COALESCE(SUM(goal_proposals_downgraded), 0) AS downgraded,
+		COALESCE(SUM(goal_proposals_clamped), 0) AS clamped,
+		COALESCE(SUM(goal_proposals_backpressure), 0) AS backpressure`).Scan(&stats).Error
+	if err != nil {
+		return GoalProposalStats{}, fmt.Errorf("failed to sum goal proposal counts: %w", err)
+	}
+	stats.rates()
+	return stats, nil
+}
+
+// GoalProposalAcceptance returns the proposal stats over the configured window of
+// recent cycles and over all cycles
+func (e *Engine) GoalProposalAcceptance(ctx context.Context) (recent, cumulative GoalProposalStats, err error) {
+	cycles, err := e.stateManager.RecentMetrics(ctx, e.goalProposalConfig().FeedbackWindow)
+	if err != nil {
+		return recent, cumulative, err
+	}
+	cumulative, err = e.stateManager.GoalProposalTotals(ctx)
+	return SummarizeGoalProposals(cycles), cumulative, err
+}
+
+// goalProposalHint returns the reflection prompt's note on a low acceptance rate of
+// recent proposals, or "" while it is at or above the configured minimum
+func (e *Engine) goalProposalHint(ctx context.Context) string {
+	cfg := e.goalProposalConfig()
+	if cfg.MinAcceptanceRate < 0 || e.stateManager == nil {
+		return ""
+	}
+	cycles, err := e.stateManager.RecentMetrics(ctx, cfg.FeedbackWindow)
+	if err != nil {
+		return ""
+	}
+	stats := SummarizeGoalProposals(cycles)
+	if stats.Received < minProposalsForFeedback || stats.AcceptanceRate >= cfg.MinAcceptanceRate {
+		return ""
+	}
+	return fmt.Sprintf("\nNOTE: Only %.0f%% of your recent goal proposals were accepted; %.0f%% were duplicates of existing or abandoned goals. Propose fewer, more distinct goals.\n",
+		stats.AcceptanceRate*100, stats.DuplicateRate*100)
+}
@@ -0,0 +1,214 @@
+// internal/dialogue/goal_proposals.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Goal priorities run from 1 to 10; a proposal outside that range is clamped
+const (
+	minGoalPriority = 1
+	maxGoalPriority = 10
+
+	DefaultGoalProposalPriority    = 5
+	DefaultMinGoalDescriptionChars = 15
+	DefaultGoalProposalWindow      = 20
+	DefaultMinGoalAcceptanceRate   = 0.5
+)
+
+// GoalProposalConfig controls how goals proposed by the model are checked before they
+// are created
+type GoalProposalConfig struct {
+	DefaultPriority     int // Priority of a proposal that gives none, or 0
+	MinDescriptionChars int // Proposals with shorter descriptions are rejected
+
+	// The acceptance rate over the last FeedbackWindow cycles is reported, and below
+	// MinAcceptanceRate the reflection is told to propose fewer, more distinct goals.
+	// A negative MinAcceptanceRate never tells it.
+	FeedbackWindow    int
+	MinAcceptanceRate float64
+}
+
+// Validate rejects a default priority outside the goal priority range
+func (c GoalProposalConfig) Validate() error {
+	if c.DefaultPriority < minGoalPriority || c.DefaultPriority > maxGoalPriority {
+		return fmt.Errorf("goal_proposals.default_priority must be between %d and %d, got %d", minGoalPriority, maxGoalPriority, c.DefaultPriority)
+	}
+	if c.MinDescriptionChars < 0 {
+		return fmt.Errorf("goal_proposals.min_description_chars must not be negative, got %d", c.MinDescriptionChars)
+	}
+	if c.FeedbackWindow < 0 {
+		return fmt.Errorf("goal_proposals.feedback_window_cycles must not be negative, got %d", c.FeedbackWindow)
+	}
+	if c.MinAcceptanceRate > 1 {
+		return fmt.Errorf("goal_proposals.min_acceptance_rate must be at most 1, got %.2f", c.MinAcceptanceRate)
+	}
+	return nil
+}
+
+// SetGoalProposalConfig configures the checks on proposed goals
+func (e *Engine) SetGoalProposalConfig(cfg GoalProposalConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	e.goalProposals = cfg
+	return nil
+}
+
+// goalProposalConfig returns the configured checks, with defaults for an engine that
+// was never configured
+func (e *Engine) goalProposalConfig() GoalProposalConfig {
+	cfg := e.goalProposals
+	if cfg.DefaultPriority == 0 {
+		cfg.DefaultPriority = DefaultGoalProposalPriority
+	}
+	if cfg.MinDescriptionChars == 0 {
+		cfg.MinDescriptionChars = DefaultMinGoalDescriptionChars
+	}
+	if cfg.FeedbackWindow == 0 {
+		cfg.FeedbackWindow = DefaultGoalProposalWindow
+	}
+	if cfg.MinAcceptanceRate == 0 {
+		cfg.MinAcceptanceRate = DefaultMinGoalAcceptanceRate
+	}
+	return cfg
+}
+
+// normalizeGoalProposal trims the description and brings the priority into range,
+// using the default for a missing or zero priority. Proposals whose description is too
+// short or made only of stop words are rejected.
+func normalizeGoalProposal(proposal GoalProposal, cfg GoalProposalConfig) (GoalProposal, error) {
+	proposal.Description = strings.TrimSpace(proposal.Description)
+	if len(proposal.Description) < cfg.MinDescriptionChars {
+		return proposal, fmt.Errorf("description %q is shorter than %d characters", proposal.Description, cfg.MinDescriptionChars)
+	}
+	if len(extractSignificantKeywords(proposal.Description)) == 0 {
+		return proposal, fmt.Errorf("description %q has no significant words", proposal.Description)
+	}
+
+	switch {
+	case proposal.Priority == 0:
+		proposal.Priority = cfg.DefaultPriority
+	case proposal.Priority < minGoalPriority || proposal.Priority > maxGoalPriority:
+		clamped := min(max(proposal.Priority, minGoalPriority), maxGoalPriority)
+		log.Printf("[Dialogue] Clamped proposed goal priority %d to %d: %s",
+			proposal.Priority, clamped, truncate(proposal.Description, 60))
+		proposal.Priority = clamped
+	}
+	return proposal, nil
+}
+
+// tierRank orders tiers by how much a goal in them is favoured; unknown tiers rank
+// below all
+var tierRank = map[string]int{
+	GoalTierTactical:  1,
+	GoalTierSecondary: 2,
+	GoalTierPrimary:   3,
+}
+
+// stricterTier returns the less favoured of the heuristic tier and the tier the
+// proposal claimed, ignoring a claim that is missing or not a tier
+func stricterTier(heuristic, claimed string) string {
+	claimed = strings.ToLower(strings.TrimSpace(claimed))
+	if tierRank[claimed] == 0 || tierRank[claimed] >= tierRank[heuristic] {
+		return heuristic
+	}
+	return claimed
+}
+
+// acceptGoalProposals turns the reflection's proposals into goals, dropping those that
+// duplicate an active or recently abandoned goal. Proposals in one response can repeat
+// each other, and neither is in ActiveGoals yet, so each is also compared with those
+// accepted before it: a near-duplicate is merged into the one with the higher priority.
+// It returns the accepted proposals and their goals, in step.
+func (e *Engine) acceptGoalProposals(ctx context.Context, state *InternalState, reasoning *ReasoningResponse, metrics *CycleMetrics) ([]GoalProposal, []Goal) {
+	recentlyAbandoned := e.recentlyAbandonedGoals(ctx, state, 10)
+
+	proposals := []GoalProposal{}
+	created := []Goal{}
+	clamped := []bool{}
+	for _, proposal := range reasoning.GoalsToCreate.ToSlice() {
+		metrics.GoalProposalsReceived++
+		goal, err := e.createGoalFromProposal(proposal)
+		if err != nil {
+			metrics.GoalProposalsInvalid++
+			log.Printf("[Dialogue] Rejected proposed goal: %v", err)
+			continue
+		}
+
+		// Check for duplicates against active goals
+		if dup, _, why := e.isGoalDuplicate(ctx, proposal.Description, state.ActiveGoals); dup {
+			metrics.GoalProposalsDuplicateActive++
+			log.Printf("[Dialogue] Skipping duplicate goal (matches active): %s: %s", truncate(proposal.Description, 40), why)
+			continue
+		}
+
+		// Check for duplicates against recently abandoned goals
+		if dup, _, why := e.isGoalDuplicate(ctx, proposal.Description, recentlyAbandoned); dup {
+			metrics.GoalProposalsDuplicateAbandoned++
+			log.Printf("[Dialogue] Skipping duplicate goal (matches recently abandoned): %s: %s", truncate(proposal.Description, 40), why)
+			continue
+		}
+
+		// Check for duplicates among this response's proposals
+		if i, score, why := e.closestGoal(ctx, proposal.Description, created); i >= 0 && score >= e.goalDedup.Threshold {
+			kept, dropped := created[i], goal
+			if goal.Priority > kept.Priority {
+				kept, dropped = goal, created[i]
+				proposals[i] = proposal
+				clamped[i] = priorityOutOfRange(proposal.Priority)
+			}
+			keywords := mergeSiblingGoal(&kept, dropped, int(e.currentCycle.Load()))
+			created[i] = kept
+			metrics.GoalsMerged++
+			log.Printf("[Dialogue] Merged sibling proposal '%s' into '%s' (adds: %s): %s",
+				truncate(dropped.Description, 40), truncate(kept.Description, 40), strings.Join(keywords, ", "), why)
+			continue
+		}
+
+		proposals = append(proposals, proposal)
+		created = append(created, goal)
+		clamped = append(clamped, priorityOutOfRange(proposal.Priority))
+	}
+
+	metrics.GoalProposalsAccepted += len(created)
+	for _, c := range clamped {
+		if c {
+			metrics.GoalProposalsClamped++
+		}
+	}
+	return proposals, created
+}
+
+// priorityOutOfRange reports whether a proposed priority is clamped by
+// normalizeGoalProposal; a missing priority takes the default instead
+func priorityOutOfRange(priority int) bool {
+	return priority != 0 && (priority < minGoalPriority || priority > maxGoalPriority)
+}
+
+// mergeSiblingGoal folds a near-duplicate proposal into kept. The keywords of dropped
+// that kept's description lacks are noted on kept, so the research still covers them,
+// and returned.
+func mergeSiblingGoal(kept *Goal, dropped Goal, cycle int) []string {
+	have := make(map[string]bool)
+	for _, stem := range goalKeywordStems(kept.Description) {
+		have[stem] = true
+	}
+	distinct := []string{}
+	for _, keyword := range extractSignificantKeywords(dropped.Description) {
+		if stem := stemWord(keyword); !have[stem] {
+			have[stem] = true
+			distinct = append(distinct, keyword)
+		}
+	}
+
+	note := fmt.Sprintf("Merged near-duplicate proposal: %s", dropped.Description)
+	if len(distinct) > 0 {
+		note += fmt.Sprintf(" (also cover: %s)", strings.Join(distinct, ", "))
+	}
+	addGoalNotes(kept, []string{note}, cycle)
+	return distinct
+}
@@ -0,0 +1,198 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCreateGoalFromProposalNormalizesPriority(t *testing.T) {
+	e := &Engine{}
+	cases := []struct {
+		name     string
+		priority int
+		want     int
+	}{
+		{"missing or zero", 0, DefaultGoalProposalPriority},
+		{"above range", 15, 10},
+		{"negative", -3, 1},
+		{"in range", 8, 8},
+	}
+	for _, tc := range cases {
+		goal, err := e.createGoalFromProposal(GoalProposal{Description: "Research how bees navigate by the sun", Priority: tc.priority})
+		if err != nil || goal.Priority != tc.want {
+			t.Errorf("%s: expected priority %d, got %d (%v)", tc.name, tc.want, goal.Priority, err)
+		}
+	}
+
+	// The tier is decided on the clamped priority
+	if goal, _ := e.createGoalFromProposal(GoalProposal{Description: "Research how bees navigate by the sun", Priority: 15}); goal.Tier != GoalTierPrimary {
+		t.Errorf("expected a clamped priority of 10 to stay primary, got %s", goal.Tier)
+	}
+}
+
+func TestCreateGoalFromProposalRejectsEmptyDescriptions(t *testing.T) {
+	e := &Engine{}
+	for _, desc := range []string{"", "   ", "Learn Go", "learn about the research of the", strings.Repeat(" and", 10)} {
+		if _, err := e.createGoalFromProposal(GoalProposal{Description: desc, Priority: 5}); err == nil {
+			t.Errorf("expected %q rejected", desc)
+		}
+	}
+
+	if err := e.SetGoalProposalConfig(GoalProposalConfig{DefaultPriority: 3, MinDescriptionChars: 5}); err != nil {
+		t.Fatal(err)
+	}
+	goal, err := e.createGoalFromProposal(GoalProposal{Description: "  Go generics  "})
+	if err != nil || goal.Description != "Go generics" || goal.Priority != 3 {
+		t.Errorf("expected the configured limits used, got %+v (%v)", goal, err)
+	}
+}
+
+func TestProposalTierClaimCanOnlyLowerTier(t *testing.T) {
+	e := &Engine{}
+	cases := []struct {
+		priority int
+		claim    string
+		want     string
+	}{
+		{9, "tactical", GoalTierTactical}, // Stricter claim wins
+		{5, "primary", GoalTierSecondary}, // Looser claim ignored
+		{9, "PRIMARY", GoalTierPrimary},   // Agreeing claim
+		{5, "urgent", GoalTierSecondary},  // Not a tier
+		{3, "", GoalTierTactical},         // No claim
+	}
+	for _, tc := range cases {
+		goal, err := e.createGoalFromProposal(GoalProposal{Description: "Research how bees navigate by the sun", Priority: tc.priority, Tier: tc.claim})
+		if err != nil || goal.Tier != tc.want {
+			t.Errorf("priority %d claiming %q: expected %s, got %s (%v)", tc.priority, tc.claim, tc.want, goal.Tier, err)
+		}
+	}
+}
+
+func TestParsedProposalsKeepMissingPriorityAndTier(t *testing.T) {
+	resp, err := ParseReasoningSExpr(`(reasoning (reflection "r") (goals_to_create (goal (description "Study honeybee waggle dances") (priority "high") (tier "tactical"))))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goals := resp.GoalsToCreate.ToSlice()
+	if len(goals) != 1 || goals[0].Priority != 0 || goals[0].Tier != "tactical" {
+		t.Fatalf("expected an unparseable priority left at 0 and the tier kept, got %+v", goals)
+	}
+}
+
+func TestSiblingProposalsAreMerged(t *testing.T) {
+	e := &Engine{goalDedup: DefaultGoalDedupConfig()}
+	reasoning := &ReasoningResponse{GoalsToCreate: GoalsOrString{
+		{Description: "Learn about Rust ownership and borrowing", Priority: 6},
+		{Description: "Research Rust ownership and borrowing lifetimes", Priority: 8},
+		{Description: "Study the history of the printing press", Priority: 5},
+	}}
+	metrics := &CycleMetrics{}
+
+	proposals, created := e.acceptGoalProposals(context.Background(), &InternalState{}, reasoning, metrics)
+	if len(created) != 2 || len(proposals) != 2 {
+		t.Fatalf("expected the paraphrase merged into one goal, got %d goals", len(created))
+	}
+	kept := created[0]
+	if kept.Description != "Research Rust ownership and borrowing lifetimes" || kept.Priority != 8 || proposals[0].Priority != 8 {
+		t.Errorf("expected the higher-priority sibling kept, got %+v", kept)
+	}
+	if len(kept.Notes) != 1 || !strings.Contains(kept.Notes[0].Text, "Learn about Rust ownership and borrowing") {
+		t.Errorf("expected the merged proposal noted on the kept goal, got %+v", kept.Notes)
+	}
+	if metrics.GoalsMerged != 1 {
+		t.Errorf("expected one merge counted, got %d", metrics.GoalsMerged)
+	}
+}
+
+func TestMergeSiblingGoalNotesDistinctKeywords(t *testing.T) {
+	kept := &Goal{Description: "Research Rust ownership rules"}
+	keywords := mergeSiblingGoal(kept, Goal{Description: "Learn about Rust ownership and lifetimes"}, 3)
+	if len(keywords) != 1 || keywords[0] != "lifetimes" {
+		t.Errorf("expected only the missing keyword, got %v", keywords)
+	}
+	if len(kept.Notes) != 1 || !strings.Contains(kept.Notes[0].Text, "also cover: lifetimes") || kept.Notes[0].Cycle != 3 {
+		t.Errorf("unexpected note %+v", kept.Notes)
+	}
+}
+
+func TestGoalProposalOutcomesAreCounted(t *testing.T) {
+	e := &Engine{goalDedup: DefaultGoalDedupConfig()}
+	state := &InternalState{
+		ActiveGoals:    []Goal{{Description: "Research how honey bees navigate by the sun", Status: GoalStatusActive}},
+		CompletedGoals: []Goal{{Description: "Study the history of the printing press", Status: GoalStatusAbandoned}},
+	}
+	reasoning := &ReasoningResponse{GoalsToCreate: GoalsOrString{
+		{Description: "Research how honey bees navigate by the sun", Priority: 6}, // Duplicate of active
+		{Description: "Study the history of the printing press", Priority: 6},     // Duplicate of abandoned
+		{Description: "Learn Go", Priority: 6},                                    // Invalid
+		{Description: "Learn about Rust ownership and borrowing", Priority: 6},
+		{Description: "Research Rust ownership and borrowing lifetimes", Priority: 15}, // Merged, kept and clamped
+		{Description: "Understand volcanic eruption forecasting methods", Priority: 4},
+	}}
+	metrics := &CycleMetrics{}
+
+	_, created := e.acceptGoalProposals(context.Background(), state, reasoning, metrics)
+	if len(created) != 2 {
+		t.Fatalf("expected two goals, got %d", len(created))
+	}
+	got := []int{metrics.GoalProposalsReceived, metrics.GoalProposalsAccepted, metrics.GoalProposalsInvalid,
+		metrics.GoalProposalsDuplicateActive, metrics.GoalProposalsDuplicateAbandoned, metrics.GoalsMerged, metrics.GoalProposalsClamped}
+	want := []int{6, 2, 1, 1, 1, 1, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected received, accepted, invalid, duplicate active, duplicate abandoned, merged, clamped %v, got %v", want, got)
+		}
+	}
+}
+
+func TestGoalProposalStatsAndHint(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&DialogueMetrics{}); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewStateManager(db)
+	e := &Engine{stateManager: sm}
+	if err := e.SetGoalProposalConfig(GoalProposalConfig{DefaultPriority: 5, FeedbackWindow: 2, MinAcceptanceRate: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An old cycle that accepted everything, then two that mostly repeated existing goals
+	for _, m := range []CycleMetrics{
+		{CycleID: 1, GoalProposalsReceived: 10, GoalProposalsAccepted: 10},
+		{CycleID: 2, GoalProposalsReceived: 4, GoalProposalsAccepted: 1, GoalProposalsDuplicateActive: 3},
+		{CycleID: 3, GoalProposalsReceived: 4, GoalProposalsAccepted: 1, GoalProposalsDuplicateAbandoned: 2, GoalsMerged: 1},
+	} {
+		if err := sm.SaveMetrics(ctx, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recent, cumulative, err := e.GoalProposalAcceptance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recent.Cycles != 2 || recent.Received != 8 || recent.AcceptanceRate != 0.25 || recent.DuplicateRate != 0.625 || recent.Merged != 1 {
+		t.Errorf("unexpected window stats %+v", recent)
+	}
+	if cumulative.Cycles != 3 || cumulative.Received != 18 || cumulative.Accepted != 12 || cumulative.DuplicateActive != 3 {
+		t.Errorf("unexpected cumulative stats %+v", cumulative)
+	}
+
+	hint := e.goalProposalHint(ctx)
+	if !strings.Contains(hint, "Only 25% of your recent goal proposals") || !strings.Contains(hint, "62% were duplicates") {
+		t.Errorf("expected a hint on the low acceptance rate, got %q", hint)
+	}
+
+	e.goalProposals.MinAcceptanceRate = -1
+	if hint := e.goalProposalHint(ctx); hint != "" {
+		t.Errorf("expected no hint when disabled, got %q", hint)
+	}
+}
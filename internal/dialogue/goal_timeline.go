@@ -0,0 +1,312 @@
+// internal/dialogue/goal_timeline.go
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Goal timeline event types
+const (
+	TimelineGoalCreated     = "goal_created"
+	TimelineActionStarted   = "action_started"
+	TimelineActionCompleted = "action_completed"
+	TimelineThought         = "thought"
+	TimelinePlanGenerated   = "plan_generated"
+	TimelinePlanReplanned   = "plan_replanned"
+	TimelineAssessment      = "assessment"
+	TimelineStatusChanged   = "status_changed"
+)
+
+// Goal timeline limits
+const (
+	DefaultTimelineLimit  = 100
+	MaxTimelineLimit      = 500  // Hard cap per page
+	maxTimelineSourceRows = 2000 // Oldest rows read from each table; a longer life is cut short
+	maxTimelineContent    = 500  // Bytes of an event's content; longer results link to the full one
+	maxGoalEventSummary   = 1000
+)
+
+// DialogueGoalEvent records a plan change, assessment or status change of a goal, which
+// goal state only keeps the latest of
+type DialogueGoalEvent struct {
+	ID        int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	CycleID   int            `gorm:"not null;default:0" json:"cycle_id"`
+	GoalID    string         `gorm:"type:varchar(100);not null;index" json:"goal_id"`
+	Type      string         `gorm:"type:varchar(30);not null" json:"type"` // One of the Timeline* types
+	Summary   string         `gorm:"type:text;not null;default:''" json:"summary"`
+	Data      datatypes.JSON `gorm:"type:jsonb" json:"data,omitempty"`
+	Timestamp time.Time      `gorm:"not null" json:"timestamp"`
+}
+
+// TableName specifies the table name for GORM
+func (DialogueGoalEvent) TableName() string {
+	return "growerai_dialogue_goal_events"
+}
+
+// SaveGoalEvent stores a goal event
+func (sm *StateManager) SaveGoalEvent(ctx context.Context, event *DialogueGoalEvent) error {
+	if err := sm.db.WithContext(ctx).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to save goal event: %w", err)
+	}
+	return nil
+}
+
+// recordGoalEvent persists a goal event for the goal's timeline
+func (e *Engine) recordGoalEvent(ctx context.Context, goalID, eventType, summary string, data map[string]interface{}) {
+	if e.stateManager == nil || goalID == "" {
+		return
+	}
+	event := &DialogueGoalEvent{
+		CycleID:   int(e.currentCycle.Load()),
+		GoalID:    goalID,
+		Type:      eventType,
+		Summary:   truncate(summary, maxGoalEventSummary),
+		Timestamp: time.Now(),
+	}
+	if len(data) > 0 {
+		if raw, err := json.Marshal(data); err == nil {
+			event.Data = raw
+		}
+	}
+	if err := e.stateManager.SaveGoalEvent(ctx, event); err != nil {
+		log.Printf("[Dialogue] WARNING: %v", err)
+	}
+}
+
+// goalIDKey carries the goal a piece of work is for, so thoughts saved along the way
+// are stamped with it
+type goalIDKey struct{}
+
+// withGoalID marks ctx as working on goalID
+func withGoalID(ctx context.Context, goalID string) context.Context {
+	if goalID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, goalIDKey{}, goalID)
+}
+
+// goalIDFromContext returns the goal set by withGoalID, or ""
+func goalIDFromContext(ctx context.Context) string {
+	goalID, _ := ctx.Value(goalIDKey{}).(string)
+	return goalID
+}
+
+// TimelineQuery selects one page of a goal's timeline
+type TimelineQuery struct {
+	GoalID    string
+	Limit     int
+	Offset    int
+	ResultURL func(actionID string) string // Optional link to an action's full result
+}
+
+// Normalize validates the query and applies the default and maximum page size
+func (q *TimelineQuery) Normalize() error {
+	q.GoalID = strings.TrimSpace(q.GoalID)
+	if q.GoalID == "" {
+		return fmt.Errorf("goal ID required")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultTimelineLimit
+	}
+	if q.Limit > MaxTimelineLimit {
+		q.Limit = MaxTimelineLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return nil
+}
+
+// TimelineEvent is one step in a goal's life
+type TimelineEvent struct {
+	Type       string                 `json:"type"`
+	Timestamp  time.Time              `json:"timestamp"`
+	CycleID    int                    `json:"cycle_id,omitempty"`
+	ActionID   string                 `json:"action_id,omitempty"`
+	Tool       string                 `json:"tool,omitempty"`
+	Content    string                 `json:"content,omitempty"`
+	Success    *bool                  `json:"success,omitempty"`     // Completed actions only
+	DurationMs int                    `json:"duration_ms,omitempty"` // Completed actions only
+	Truncated  bool                   `json:"truncated,omitempty"`   // Content was cut short
+	ResultRef  string                 `json:"result_ref,omitempty"`  // Result store key of the full output
+	ResultURL  string                 `json:"result_url,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// TimelinePage is one page of a goal's timeline, oldest event first
+type TimelinePage struct {
+	GoalID      string          `json:"goal_id"`
+	Description string          `json:"description,omitempty"`
+	Status      string          `json:"status,omitempty"`
+	Events      []TimelineEvent `json:"events"`
+	Total       int             `json:"total"`
+	Limit       int             `json:"limit"`
+	Offset      int             `json:"offset"`
+	HasMore     bool            `json:"has_more"`
+}
+
+// GoalTimeline assembles a goal's life from goal state or its archive, goal events,
+// thoughts and actions, in chronological order. It returns ErrGoalNotFound when none
+// of them know the goal.
+func (sm *StateManager) GoalTimeline(ctx context.Context, q TimelineQuery) (*TimelinePage, error) {
+	if err := q.Normalize(); err != nil {
+		return nil, err
+	}
+
+	page := &TimelinePage{GoalID: q.GoalID, Events: []TimelineEvent{}, Limit: q.Limit, Offset: q.Offset}
+	var events []TimelineEvent
+	if goal := sm.timelineGoal(ctx, q.GoalID); goal != nil {
+		page.Description, page.Status = goal.Description, goal.Status
+		events = append(events, goalStateEvents(goal)...)
+	}
+
+	db := sm.db.WithContext(ctx)
+	var goalEvents []DialogueGoalEvent
+	if err := db.Where("goal_id = ?", q.GoalID).Order(`"timestamp", id`).Limit(maxTimelineSourceRows).Find(&goalEvents).Error; err != nil {
+		return nil, fmt.Errorf("failed to load goal events: %w", err)
+	}
+	for _, ge := range goalEvents {
+		event := TimelineEvent{Type: ge.Type, Timestamp: ge.Timestamp, CycleID: ge.CycleID}
+		event.Content, event.Truncated = timelineContent(ge.Summary)
+		if len(ge.Data) > 0 {
+			json.Unmarshal(ge.Data, &event.Data)
+		}
+		events = append(events, event)
+	}
+
+	var thoughts []DialogueThought
+	if err := db.Where("goal_id = ?", q.GoalID).Order(`"timestamp", id`).Limit(maxTimelineSourceRows).Find(&thoughts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load goal thoughts: %w", err)
+	}
+	for _, t := range thoughts {
+		event := TimelineEvent{Type: TimelineThought, Timestamp: t.Timestamp, CycleID: t.CycleID}
+		event.Content, event.Truncated = timelineContent(t.Content)
+		if t.PromptTemplate != "" {
+			event.Data = map[string]interface{}{"prompt_template": t.PromptTemplate}
+		}
+		events = append(events, event)
+	}
+
+	var actions []DialogueAction
+	if err := db.Where("goal_id = ?", q.GoalID).Order(`"timestamp", id`).Limit(maxTimelineSourceRows).Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load goal actions: %w", err)
+	}
+	var storedIDs []string
+	if len(actions) > 0 {
+		if err := db.Model(&ActionResult{}).Where("goal_id = ?", q.GoalID).Pluck("action_id", &storedIDs).Error; err != nil {
+			log.Printf("[Dialogue] WARNING: Failed to list stored results of goal %s: %v", q.GoalID, err)
+		}
+	}
+	stored := make(map[string]bool, len(storedIDs))
+	for _, id := range storedIDs {
+		stored[id] = true
+	}
+	for _, a := range actions {
+		events = append(events, actionEvents(a, stored[a.ActionID], q.ResultURL)...)
+	}
+
+	if len(events) == 0 && page.Description == "" {
+		return nil, ErrGoalNotFound
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	page.Total = len(events)
+	if q.Offset < len(events) {
+		page.Events = events[q.Offset:min(q.Offset+q.Limit, len(events))]
+	}
+	page.HasMore = q.Offset+len(page.Events) < page.Total
+	return page, nil
+}
+
+// timelineGoal finds the goal in state, then in the archive, or returns nil
+func (sm *StateManager) timelineGoal(ctx context.Context, goalID string) *Goal {
+	if state, err := sm.LoadState(ctx); err == nil {
+		if goal := findGoal(state.ActiveGoals, goalID); goal != nil {
+			return goal
+		}
+		if goal := findGoal(state.CompletedGoals, goalID); goal != nil {
+			return goal
+		}
+	}
+	var archived GoalArchive
+	err := sm.db.WithContext(ctx).Where("goal_id = ?", goalID).First(&archived).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("[Dialogue] WARNING: Failed to look up archived goal %s: %v", goalID, err)
+		}
+		return nil
+	}
+	goal := archived.goal()
+	return &goal
+}
+
+// goalStateEvents derives the events goal state records itself: its creation and, until
+// a replan replaces it, when its research plan was made
+func goalStateEvents(goal *Goal) []TimelineEvent {
+	var events []TimelineEvent
+	if !goal.Created.IsZero() {
+		events = append(events, TimelineEvent{
+			Type:      TimelineGoalCreated,
+			Timestamp: goal.Created,
+			Content:   goal.Description,
+			Data:      map[string]interface{}{"source": goal.Source, "tier": goal.Tier, "priority": goal.Priority},
+		})
+	}
+	if plan := goal.ResearchPlan; plan != nil && goal.ReplanCount == 0 && !plan.CreatedAt.IsZero() {
+		events = append(events, TimelineEvent{
+			Type:      TimelinePlanGenerated,
+			Timestamp: plan.CreatedAt,
+			Content:   plan.RootQuestion,
+			Data:      map[string]interface{}{"questions": len(plan.SubQuestions)},
+		})
+	}
+	return events
+}
+
+// actionEvents splits a recorded action into its start and completion. A cut result
+// links to the full one when the result store has it.
+func actionEvents(a DialogueAction, stored bool, resultURL func(actionID string) string) []TimelineEvent {
+	started := TimelineEvent{Type: TimelineActionStarted, Timestamp: a.Timestamp, CycleID: a.CycleID, ActionID: a.ActionID, Tool: a.Tool}
+	started.Content, started.Truncated = timelineContent(a.Input)
+
+	success := a.Success
+	completed := TimelineEvent{
+		Type:       TimelineActionCompleted,
+		Timestamp:  a.Timestamp.Add(time.Duration(a.DurationMs) * time.Millisecond),
+		CycleID:    a.CycleID,
+		ActionID:   a.ActionID,
+		Tool:       a.Tool,
+		Success:    &success,
+		DurationMs: a.DurationMs,
+	}
+	output := a.Output
+	if !a.Success && a.Error != "" {
+		output = "error: " + a.Error
+	}
+	completed.Content, completed.Truncated = timelineContent(output)
+	if stored {
+		completed.ResultRef = a.ActionID
+		if resultURL != nil {
+			completed.ResultURL = resultURL(a.ActionID)
+		}
+	}
+	return []TimelineEvent{started, completed}
+}
+
+// timelineContent cuts s to maxTimelineContent bytes and reports whether it did
+func timelineContent(s string) (string, bool) {
+	if len(s) <= maxTimelineContent {
+		return s, false
+	}
+	return truncate(s, maxTimelineContent), true
+}
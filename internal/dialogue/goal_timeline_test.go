@@ -0,0 +1,88 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoalTimelineCombinesSourcesInOrder(t *testing.T) {
+	ctx := context.Background()
+	db := setupProvenanceDB(t)
+	if err := db.AutoMigrate(&DialogueGoalEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	// The thoughts table defaults to NOW(), which sqlite rejects
+	if err := db.Exec(`CREATE TABLE growerai_dialogue_thoughts (id integer PRIMARY KEY AUTOINCREMENT,
+		cycle_id integer NOT NULL, thought_num integer NOT NULL DEFAULT 0, goal_id text, content text NOT NULL,
+		tokens_used integer NOT NULL DEFAULT 0, action_taken boolean NOT NULL DEFAULT false,
+		prompt_template text, "timestamp" datetime NOT NULL)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	e := &Engine{stateManager: NewStateManager(db)}
+	e.currentCycle.Store(3)
+
+	start := time.Now().Add(-time.Hour)
+	goal := Goal{ID: "goal_7", Description: "Research vector databases", Status: GoalStatusActive, Created: start}
+	if err := archiveGoals(db, []Goal{goal}); err != nil {
+		t.Fatal(err)
+	}
+	longOutput := strings.Repeat("pgvector and qdrant compared. ", 40)
+	for _, action := range []ActionRecord{
+		{CycleID: 3, GoalID: goal.ID, ActionID: "action_1", Tool: ActionToolSearch, Input: "vector databases", Output: longOutput, Duration: 2 * time.Second, Timestamp: start.Add(time.Minute)},
+		{CycleID: 3, GoalID: goal.ID, ActionID: "action_2", Tool: ActionToolWebParseUnified, Input: "url=https://example.com", Err: errors.New("timeout"), Timestamp: start.Add(3 * time.Minute)},
+	} {
+		if err := e.stateManager.SaveAction(ctx, &action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.stateManager.SaveActionResult(ctx, "action_1", goal.ID, longOutput); err != nil {
+		t.Fatal(err)
+	}
+
+	// Thoughts saved while working on the goal are stamped with it
+	e.saveThought(withGoalID(ctx, goal.ID), &ThoughtRecord{CycleID: 3, Content: "[assessment@abc] (assessment ...)", PromptTemplate: "assessment@abc", Timestamp: start.Add(2 * time.Minute)})
+	if !e.askUser(ctx, &goal, `(user_question "Open source only?")`) {
+		t.Fatal("expected the goal to wait for the user")
+	}
+
+	page, err := e.stateManager.GoalTimeline(ctx, TimelineQuery{
+		GoalID:    goal.ID,
+		ResultURL: func(id string) string { return "/dialogue/actions/" + id + "/result" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, event := range page.Events {
+		types = append(types, event.Type)
+	}
+	want := []string{TimelineGoalCreated, TimelineActionStarted, TimelineActionCompleted, TimelineThought,
+		TimelineActionStarted, TimelineActionCompleted, TimelineStatusChanged}
+	if strings.Join(types, ",") != strings.Join(want, ",") || page.Description != goal.Description {
+		t.Fatalf("expected events %v, got %v (%+v)", want, types, page)
+	}
+
+	completed := page.Events[2]
+	if !completed.Truncated || len(completed.Content) > maxTimelineContent+3 || completed.DurationMs != 2000 ||
+		completed.ResultURL != "/dialogue/actions/action_1/result" || completed.Success == nil || !*completed.Success {
+		t.Errorf("expected a cut result linking to the full one, got %+v", completed)
+	}
+	if failed := page.Events[5]; *failed.Success || failed.Content != "error: timeout" || failed.ResultURL != "" {
+		t.Errorf("expected the failed action's error, got %+v", failed)
+	}
+	if status := page.Events[6]; status.Data["status"] != GoalStatusAwaitingUser {
+		t.Errorf("expected the awaiting_user status change, got %+v", status)
+	}
+
+	second, err := e.stateManager.GoalTimeline(ctx, TimelineQuery{GoalID: goal.ID, Limit: 3, Offset: 3})
+	if err != nil || len(second.Events) != 3 || second.Events[0].Type != TimelineThought || !second.HasMore || second.Total != 7 {
+		t.Errorf("expected the second page of three, got %+v (%v)", second, err)
+	}
+
+	if _, err := e.stateManager.GoalTimeline(ctx, TimelineQuery{GoalID: "goal_unknown"}); !errors.Is(err, ErrGoalNotFound) {
+		t.Errorf("expected an unknown goal reported, got %v", err)
+	}
+}
@@ -1,10 +1,52 @@
 package dialogue
 
 import (
+    "log"
     "strings"
+
+    "go-llama/internal/tools"
+    "go-llama/internal/utils"
 )
 
+// MetadataSearchResults is the action metadata key holding a search's structured results
+const MetadataSearchResults = "search_results"
+
+// searchResultURLs returns result URLs in rank order from structured search results,
+// scraping the text output only when none are available (legacy recorded actions)
+func searchResultURLs(results []tools.StructuredSearchResult, searchOutput string) []string {
+    if len(results) == 0 {
+        return extractURLsFromSearchResults(searchOutput)
+    }
+
+    urls := []string{}
+    for _, r := range results {
+        if strings.HasPrefix(r.URL, "http://") || strings.HasPrefix(r.URL, "https://") {
+            urls = append(urls, r.URL)
+        }
+    }
+    return urls
+}
+
+// fetchableURLs drops URLs the domain policy would refuse, so search evaluation never
+// picks a target the web parser cannot fetch
+func (e *Engine) fetchableURLs(urls []string) []string {
+    if e.domainPolicy == nil {
+        return urls
+    }
+    allowed := make([]string, 0, len(urls))
+    for _, u := range urls {
+        if e.domainPolicy.Allowed(u) {
+            allowed = append(allowed, u)
+        }
+    }
+    if dropped := len(urls) - len(allowed); dropped > 0 {
+        log.Printf("[Dialogue] Domain policy removed %d of %d candidate URLs", dropped, len(urls))
+    }
+    return allowed
+}
+
 // extractURLsFromSearchResults extracts valid http/https URLs from search output lines.
+// It depends on the search tool's text format; prefer searchResultURLs.
 func extractURLsFromSearchResults(searchOutput string) []string {
     urls := []string{}
     lines := strings.Split(searchOutput, "\n")
@@ -83,10 +125,7 @@ func extractSearchKeywords(goalDesc string) string {
     // Join into search query
     if len(keywords) == 0 {
         // Fallback: use first 30 chars of original
-        if len(goalDesc) > 30 {
-            return goalDesc[:30]
-        }
-        return goalDesc
+        return utils.Prefix(goalDesc, 30)
     }
 
     return strings.Join(keywords, " ")
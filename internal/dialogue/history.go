@@ -0,0 +1,290 @@
+// internal/dialogue/history.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// History entry types
+const (
+	HistoryTypeThought = "thought"
+	HistoryTypeAction  = "action"
+)
+
+// History search limits
+const (
+	DefaultHistoryLimit   = 50
+	MaxHistoryLimit       = 200  // Hard cap per page
+	maxHistoryOffset      = 5000 // Deep pagination is refused rather than scanned
+	maxActionInputLength  = 1000
+	maxActionOutputLength = 2000
+	historyMinQueryLength = 2
+)
+
+// DialogueAction records one tool action for history search
+type DialogueAction struct {
+	ID         int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	CycleID    int       `gorm:"not null;index" json:"cycle_id"`
+	GoalID     string    `gorm:"type:varchar(100);index" json:"goal_id"`
+	ActionID   string    `gorm:"type:varchar(100)" json:"action_id"`
+	Tool       string    `gorm:"type:varchar(50);not null" json:"tool"`
+	Input      string    `gorm:"type:text;not null;default:''" json:"input"`  // Query, URL or description
+	Output     string    `gorm:"type:text;not null;default:''" json:"output"` // Truncated result
+	Success    bool      `gorm:"not null;default:false" json:"success"`
+	Error      string    `gorm:"type:text" json:"error,omitempty"`
+	DurationMs int       `gorm:"not null;default:0" json:"duration_ms"`
+	Timestamp  time.Time `gorm:"not null;default:NOW();index" json:"timestamp"`
+}
+
+// TableName specifies the table name for GORM
+func (DialogueAction) TableName() string {
+	return "growerai_dialogue_actions"
+}
+
+// ActionRecord logs a tool action during a dialogue cycle
+type ActionRecord struct {
+	CycleID   int
+	GoalID    string
+	ActionID  string
+	Tool      string
+	Input     string
+	Output    string
+	Err       error
+	Duration  time.Duration
+	Timestamp time.Time
+}
+
+// SaveAction stores an action record
+func (sm *StateManager) SaveAction(ctx context.Context, action *ActionRecord) error {
+	dbAction := DialogueAction{
+		CycleID:    action.CycleID,
+		GoalID:     action.GoalID,
+		ActionID:   action.ActionID,
+		Tool:       action.Tool,
+		Input:      truncate(action.Input, maxActionInputLength),
+		Output:     truncate(action.Output, maxActionOutputLength),
+		Success:    action.Err == nil,
+		DurationMs: int(action.Duration.Milliseconds()),
+		Timestamp:  action.Timestamp,
+	}
+	if action.Err != nil {
+		dbAction.Error = action.Err.Error()
+	}
+
+	if err := sm.db.WithContext(ctx).Create(&dbAction).Error; err != nil {
+		return fmt.Errorf("failed to save action: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureHistoryIndexes creates trigram indexes for history text search. pg_trgm may be
+// unavailable to the database user; search still works without it, only slower.
+func EnsureHistoryIndexes(db *gorm.DB) {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("[Dialogue] WARNING: pg_trgm unavailable, history search will not be indexed: %v", err)
+		return
+	}
+	statements := []string{
+		"CREATE INDEX IF NOT EXISTS idx_dialogue_thoughts_content_trgm ON growerai_dialogue_thoughts USING gin (content gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_dialogue_actions_input_trgm ON growerai_dialogue_actions USING gin (input gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_dialogue_actions_output_trgm ON growerai_dialogue_actions USING gin (output gin_trgm_ops)",
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Printf("[Dialogue] WARNING: Failed to create history index: %v", err)
+		}
+	}
+}
+
+// HistoryQuery filters thought/action history. Type "" searches both.
+type HistoryQuery struct {
+	Text   string
+	Type   string
+	From   time.Time // Zero = unbounded
+	To     time.Time // Zero = unbounded
+	Limit  int
+	Offset int
+}
+
+// Normalize validates the query and applies the default and maximum page size
+func (q *HistoryQuery) Normalize() error {
+	q.Text = strings.TrimSpace(q.Text)
+	if len([]rune(q.Text)) < historyMinQueryLength {
+		return fmt.Errorf("query must be at least %d characters", historyMinQueryLength)
+	}
+	switch q.Type {
+	case "", HistoryTypeThought, HistoryTypeAction:
+	default:
+		return fmt.Errorf("type must be %q or %q", HistoryTypeThought, HistoryTypeAction)
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return fmt.Errorf("to must not be before from")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultHistoryLimit
+	}
+	if q.Limit > MaxHistoryLimit {
+		q.Limit = MaxHistoryLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	if q.Offset > maxHistoryOffset {
+		return fmt.Errorf("offset must not exceed %d; narrow the time range instead", maxHistoryOffset)
+	}
+	return nil
+}
+
+// HistoryEntry is one matching thought or action with its cycle and goal context
+type HistoryEntry struct {
+	Type            string    `json:"type"`
+	ID              int       `json:"id"`
+	CycleID         int       `json:"cycle_id"`
+	GoalID          string    `json:"goal_id,omitempty"`
+	GoalDescription string    `json:"goal_description,omitempty"`
+	ActionID        string    `json:"action_id,omitempty"`
+	Tool            string    `json:"tool,omitempty"`
+	Content         string    `json:"content"`
+	Success         *bool     `json:"success,omitempty"` // Actions only
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// HistoryGoalGroup summarises the matches on one page that relate to a goal
+type HistoryGoalGroup struct {
+	GoalID      string `json:"goal_id"`
+	Description string `json:"description,omitempty"`
+	Thoughts    int    `json:"thoughts"`
+	Actions     int    `json:"actions"`
+}
+
+// HistoryPage is one page of history search results
+type HistoryPage struct {
+	Results []HistoryEntry     `json:"results"`
+	Goals   []HistoryGoalGroup `json:"goals"` // Page matches grouped by goal
+	Total   int64              `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+	HasMore bool               `json:"has_more"`
+}
+
+// escapeLike escapes LIKE wildcards so the query matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// historySQL builds the UNION query selecting matches for the requested types
+func historySQL(q HistoryQuery) (string, []interface{}) {
+	pattern := "%" + escapeLike(q.Text) + "%"
+	timeFilter := func(args []interface{}) (string, []interface{}) {
+		clause := ""
+		if !q.From.IsZero() {
+			clause += ` AND "timestamp" >= ?`
+			args = append(args, q.From)
+		}
+		if !q.To.IsZero() {
+			clause += ` AND "timestamp" <= ?`
+			args = append(args, q.To)
+		}
+		return clause, args
+	}
+
+	parts := []string{}
+	args := []interface{}{}
+	if q.Type == "" || q.Type == HistoryTypeThought {
+		args = append(args, pattern)
+		clause, withTime := timeFilter(args)
+		args = withTime
+		parts = append(parts, `SELECT 'thought' AS type, id, cycle_id, goal_id, '' AS action_id, '' AS tool,
+			content, NULL::boolean AS success, "timestamp"
+			FROM growerai_dialogue_thoughts WHERE content ILIKE ?`+clause)
+	}
+	if q.Type == "" || q.Type == HistoryTypeAction {
+		args = append(args, pattern, pattern)
+		clause, withTime := timeFilter(args)
+		args = withTime
+		parts = append(parts, `SELECT 'action' AS type, id, cycle_id, goal_id, action_id, tool,
+			input || E'\n' || output AS content, success, "timestamp"
+			FROM growerai_dialogue_actions WHERE (input ILIKE ? OR output ILIKE ?)`+clause)
+	}
+	return strings.Join(parts, " UNION ALL "), args
+}
+
+// SearchHistory finds thoughts and actions containing the query text, newest first
+func (sm *StateManager) SearchHistory(ctx context.Context, q HistoryQuery) (*HistoryPage, error) {
+	if err := q.Normalize(); err != nil {
+		return nil, err
+	}
+	union, args := historySQL(q)
+
+	var total int64
+	if err := sm.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+union+") AS matches", args...).Scan(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count history matches: %w", err)
+	}
+
+	entries := []HistoryEntry{}
+	pageArgs := append(append([]interface{}{}, args...), q.Limit, q.Offset)
+	err := sm.db.WithContext(ctx).
+		Raw("SELECT * FROM ("+union+`) AS matches ORDER BY "timestamp" DESC, id DESC LIMIT ? OFFSET ?`, pageArgs...).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+
+	goals := map[string]string{}
+	if state, err := sm.LoadState(ctx); err == nil {
+		for _, g := range append(state.ActiveGoals, state.CompletedGoals...) {
+			goals[g.ID] = g.Description
+		}
+	}
+
+	return &HistoryPage{
+		Results: entries,
+		Goals:   groupHistoryByGoal(entries, goals),
+		Total:   total,
+		Limit:   q.Limit,
+		Offset:  q.Offset,
+		HasMore: int64(q.Offset+len(entries)) < total,
+	}, nil
+}
+
+// groupHistoryByGoal fills in goal descriptions and counts matches per goal
+func groupHistoryByGoal(entries []HistoryEntry, descriptions map[string]string) []HistoryGoalGroup {
+	groups := map[string]*HistoryGoalGroup{}
+	order := []string{}
+	for i := range entries {
+		e := &entries[i]
+		if e.GoalID == "" {
+			continue
+		}
+		e.GoalDescription = descriptions[e.GoalID]
+
+		g, ok := groups[e.GoalID]
+		if !ok {
+			g = &HistoryGoalGroup{GoalID: e.GoalID, Description: e.GoalDescription}
+			groups[e.GoalID] = g
+			order = append(order, e.GoalID)
+		}
+		if e.Type == HistoryTypeAction {
+			g.Actions++
+		} else {
+			g.Thoughts++
+		}
+	}
+
+	out := make([]HistoryGoalGroup, 0, len(order))
+	for _, id := range order {
+		out = append(out, *groups[id])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Thoughts+out[i].Actions > out[j].Thoughts+out[j].Actions
+	})
+	return out
+}
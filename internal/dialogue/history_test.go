@@ -0,0 +1,79 @@
+package dialogue
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryQueryNormalize(t *testing.T) {
+	q := HistoryQuery{Text: "  embeddings ", Limit: 5000, Offset: -3}
+	if err := q.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Text != "embeddings" || q.Limit != MaxHistoryLimit || q.Offset != 0 {
+		t.Errorf("unexpected normalized query %+v", q)
+	}
+
+	defaulted := HistoryQuery{Text: "wikipedia.org"}
+	if err := defaulted.Normalize(); err != nil || defaulted.Limit != DefaultHistoryLimit {
+		t.Errorf("expected default limit, got %d (%v)", defaulted.Limit, err)
+	}
+
+	now := time.Now()
+	invalid := []HistoryQuery{
+		{Text: "a"},
+		{Text: "embeddings", Type: "memory"},
+		{Text: "embeddings", From: now, To: now.Add(-time.Hour)},
+		{Text: "embeddings", Offset: maxHistoryOffset + 1},
+	}
+	for _, q := range invalid {
+		if err := q.Normalize(); err == nil {
+			t.Errorf("expected %+v to be rejected", q)
+		}
+	}
+}
+
+func TestHistorySQLPlaceholders(t *testing.T) {
+	from := time.Now().Add(-24 * time.Hour)
+	cases := []struct {
+		query HistoryQuery
+		parts int
+		args  int
+	}{
+		{HistoryQuery{Text: "50%_off"}, 2, 3},
+		{HistoryQuery{Text: "embeddings", Type: HistoryTypeThought, From: from}, 1, 2},
+		{HistoryQuery{Text: "wikipedia.org", Type: HistoryTypeAction, From: from, To: time.Now()}, 1, 4},
+	}
+	for _, tc := range cases {
+		sql, args := historySQL(tc.query)
+		if got := strings.Count(sql, "UNION ALL") + 1; got != tc.parts {
+			t.Errorf("%+v: expected %d selects, got %d", tc.query, tc.parts, got)
+		}
+		if got := strings.Count(sql, "?"); got != len(args) || got != tc.args {
+			t.Errorf("%+v: %d placeholders for %d args, want %d", tc.query, got, len(args), tc.args)
+		}
+	}
+
+	_, args := historySQL(HistoryQuery{Text: "50%_off", Type: HistoryTypeThought})
+	if args[0] != `%50\%\_off%` {
+		t.Errorf("expected LIKE wildcards to be escaped, got %v", args[0])
+	}
+}
+
+func TestGroupHistoryByGoal(t *testing.T) {
+	entries := []HistoryEntry{
+		{Type: HistoryTypeThought, GoalID: "g1"},
+		{Type: HistoryTypeAction, GoalID: "g2"},
+		{Type: HistoryTypeAction, GoalID: "g2"},
+		{Type: HistoryTypeThought},
+	}
+	groups := groupHistoryByGoal(entries, map[string]string{"g2": "Learn about embeddings"})
+
+	if len(groups) != 2 || groups[0].GoalID != "g2" || groups[0].Actions != 2 || groups[1].Thoughts != 1 {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+	if entries[1].GoalDescription != "Learn about embeddings" || groups[0].Description != "Learn about embeddings" {
+		t.Errorf("expected goal description to be filled in, got %+v", entries[1])
+	}
+}
@@ -0,0 +1,283 @@
+// internal/dialogue/interests.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go-llama/internal/memory"
+
+	"gorm.io/gorm"
+)
+
+// DefaultInterestHalfLife is how long it takes a memory's weight in interest analysis
+// to halve when no half-life is configured
+const DefaultInterestHalfLife = 14 * 24 * time.Hour
+
+// InterestSuppressionTag marks the memory recording that a user wants a topic dropped
+const InterestSuppressionTag = "interest_suppression"
+
+// Reasons a topic was suppressed
+const (
+	SuppressionThumbsDown  = "thumbs_down"  // The user rated a message mentioning the topic down
+	SuppressionChatRequest = "chat_request" // The user asked in chat to stop researching it
+)
+
+// TopicSuppression keeps a topic out of interest-driven goals until it expires
+type TopicSuppression struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Topic     string    `gorm:"type:varchar(200);not null;index" json:"topic"`
+	Reason    string    `gorm:"type:varchar(20);not null" json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TopicSuppression) TableName() string {
+	return "growerai_topic_suppressions"
+}
+
+// SuppressTopic records that a user doesn't want a topic researched for the cooldown.
+// A topic the user already suppressed has its cooldown restarted.
+func SuppressTopic(db *gorm.DB, userID uint, topic, reason string, cooldown time.Duration) (*TopicSuppression, error) {
+	topic = NormalizeTopic(topic)
+	if topic == "" {
+		return nil, fmt.Errorf("empty topic")
+	}
+	now := time.Now()
+
+	var s TopicSuppression
+	err := db.Where("user_id = ? AND topic = ? AND expires_at > ?", userID, topic, now).Limit(1).Find(&s).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topic suppression: %w", err)
+	}
+	s.UserID, s.Topic, s.Reason, s.ExpiresAt = userID, topic, reason, now.Add(cooldown)
+	if err := db.Save(&s).Error; err != nil {
+		return nil, fmt.Errorf("failed to save topic suppression: %w", err)
+	}
+	log.Printf("[Interests] User %d suppressed topic %q (%s) until %s", userID, topic, reason, s.ExpiresAt.Format(time.RFC3339))
+	return &s, nil
+}
+
+// ActiveTopicSuppressions returns a user's unexpired suppressions, or every user's
+// when userID is 0
+func ActiveTopicSuppressions(db *gorm.DB, userID uint) ([]TopicSuppression, error) {
+	query := db.Where("expires_at > ?", time.Now())
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var suppressions []TopicSuppression
+	if err := query.Order("expires_at DESC").Find(&suppressions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load topic suppressions: %w", err)
+	}
+	return suppressions, nil
+}
+
+// NormalizeTopic lower-cases a topic and trims spaces and punctuation
+func NormalizeTopic(topic string) string {
+	topic = strings.ToLower(strings.TrimSpace(topic))
+	topic = strings.Trim(topic, " \t\"'`.,;:!?()[]")
+	return strings.Join(strings.Fields(topic), " ")
+}
+
+var (
+	// suppressionPhrases match a user asking for a topic to be dropped
+	suppressionPhrases = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:stop|quit|no more) (?:researching|looking into|reading about|exploring|learning about|digging into) ([^.!?,;\n]+)`),
+		regexp.MustCompile(`(?i)\bi(?:'m| am) (?:done|finished) with ([^.!?,;\n]+)`),
+		regexp.MustCompile(`(?i)\b(?:i'm|i am) (?:not|no longer) interested in ([^.!?,;\n]+)`),
+	}
+	// researchMentionPhrases match a proactive mention of something the system researched
+	researchMentionPhrases = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:i(?:'ve| have)? )?(?:been )?(?:researching|researched|looking into|looked into|exploring|explored|reading about|read up on) ([^.!?,;\n]+)`),
+	}
+	// correctionPhrases match a user saying the previous reply was wrong
+	correctionPhrases = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:that'?s|that is|this is|it'?s|it is) (?:wrong|incorrect|not (?:right|true|correct|accurate))\b`),
+		regexp.MustCompile(`(?i)\b(?:you'?re|you are) (?:wrong|mistaken|incorrect)\b`),
+		regexp.MustCompile(`(?i)^\s*(?:no|nope|actually)[,.!]\s+(?:it'?s|it is|that'?s|that is|it was|the)\b`),
+		regexp.MustCompile(`(?i)^\s*(?:wrong|incorrect|not true)\b`),
+	}
+	// topicFillers are dropped from the end of a captured topic
+	topicFillers = []string{"please", "anymore", "any more", "for now", "now", "thanks", "thank you"}
+)
+
+// DetectTopicSuppression reports the topic a chat message asks to stop researching
+func DetectTopicSuppression(content string) (string, bool) {
+	return matchTopic(suppressionPhrases, content)
+}
+
+// DetectResearchMention reports the topic a reply says the system has been researching
+func DetectResearchMention(content string) (string, bool) {
+	return matchTopic(researchMentionPhrases, content)
+}
+
+// DetectCorrection reports whether a chat message corrects the previous reply
+func DetectCorrection(content string) bool {
+	for _, pattern := range correctionPhrases {
+		if pattern.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTopic(patterns []*regexp.Regexp, content string) (string, bool) {
+	for _, pattern := range patterns {
+		match := pattern.FindStringSubmatch(content)
+		if match == nil {
+			continue
+		}
+		topic := NormalizeTopic(match[1])
+		for trimmed := true; trimmed; {
+			trimmed = false
+			for _, filler := range topicFillers {
+				if strings.HasSuffix(topic, " "+filler) {
+					topic = NormalizeTopic(strings.TrimSuffix(topic, filler))
+					trimmed = true
+				}
+			}
+		}
+		if words := strings.Fields(topic); len(words) > 0 && len(words) <= 6 {
+			return topic, true
+		}
+	}
+	return "", false
+}
+
+// topicSuppressed reports whether a topic matches a suppressed one: every word of the
+// shorter of the two appears in the longer, so "kubernetes" matches "kubernetes operators"
+func topicSuppressed(topic string, suppressed map[string]bool) bool {
+	topic = NormalizeTopic(topic)
+	if topic == "" || len(suppressed) == 0 {
+		return false
+	}
+	if suppressed[topic] {
+		return true
+	}
+	topicWords := strings.Fields(topic)
+	for s := range suppressed {
+		shorter, longer := topicWords, strings.Fields(s)
+		if len(shorter) > len(longer) {
+			shorter, longer = longer, shorter
+		}
+		if containsAllWords(longer, shorter) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAllWords(words, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, word := range words {
+			if word == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// suppressedTopics loads every user's active suppressions. Goals are shared, so a
+// topic any user suppressed is left out of interest-driven goals.
+func (e *Engine) suppressedTopics(ctx context.Context) map[string]bool {
+	if e.db == nil {
+		return nil
+	}
+	suppressions, err := ActiveTopicSuppressions(e.db.WithContext(ctx), 0)
+	if err != nil {
+		log.Printf("[Interests] WARNING: %v", err)
+		return nil
+	}
+	topics := make(map[string]bool, len(suppressions))
+	for _, s := range suppressions {
+		topics[s.Topic] = true
+	}
+	return topics
+}
+
+// SetInterestHalfLife configures how quickly old memories lose weight in interest analysis
+func (e *Engine) SetInterestHalfLife(halfLife time.Duration) {
+	e.interestHalfLife = halfLife
+}
+
+// interestWeight halves a memory's weight for every half-life of its age
+func interestWeight(createdAt, now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		halfLife = DefaultInterestHalfLife
+	}
+	age := now.Sub(createdAt)
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// rankInterests scores concept tags by the decayed weight of the memories carrying them
+// and returns up to limit topics, skipping suppressed topics and suppression requests
+func rankInterests(results []memory.RetrievalResult, now time.Time, halfLife time.Duration, suppressed map[string]bool, limit int) []string {
+	scores := make(map[string]float64)
+	for _, result := range results {
+		tags := result.Memory.ConceptTags
+		isRequest := false
+		for _, tag := range tags {
+			if tag == InterestSuppressionTag {
+				isRequest = true
+				break
+			}
+		}
+		if isRequest {
+			continue
+		}
+		weight := interestWeight(result.Memory.CreatedAt, now, halfLife)
+		for _, tag := range tags {
+			if !topicSuppressed(tag, suppressed) {
+				scores[tag] += weight
+			}
+		}
+	}
+
+	topics := make([]string, 0, len(scores))
+	for topic := range scores {
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if scores[topics[i]] != scores[topics[j]] {
+			return scores[topics[i]] > scores[topics[j]]
+		}
+		return topics[i] < topics[j]
+	})
+	if len(topics) > limit {
+		topics = topics[:limit]
+	}
+	return topics
+}
+
+// withoutSuppressed drops suppressed topics from a candidate list
+func withoutSuppressed(topics []string, suppressed map[string]bool) []string {
+	if len(suppressed) == 0 {
+		return topics
+	}
+	kept := []string{}
+	for _, topic := range topics {
+		if topicSuppressed(topic, suppressed) {
+			log.Printf("[Interests] Skipping suppressed topic %q", topic)
+			continue
+		}
+		kept = append(kept, topic)
+	}
+	return kept
+}
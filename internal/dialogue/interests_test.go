@@ -0,0 +1,127 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newSuppressionDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&TopicSuppression{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestDetectTopicSuppression(t *testing.T) {
+	for content, want := range map[string]string{
+		"Please stop researching Kubernetes operators, I'm done.": "kubernetes operators",
+		"ok, no more looking into sourdough please":               "sourdough",
+		"I'm done with chess openings.":                           "chess openings",
+		"I am no longer interested in crypto anymore":             "crypto",
+		"Tell me more about kubernetes":                           "",
+	} {
+		got, ok := DetectTopicSuppression(content)
+		if got != want || ok != (want != "") {
+			t.Errorf("%q: expected %q, got %q (%v)", content, want, got, ok)
+		}
+	}
+	if topic, ok := DetectResearchMention("I've been researching quantum error correction lately. Want a summary?"); !ok || topic != "quantum error correction lately" {
+		t.Errorf("expected the researched topic, got %q", topic)
+	}
+}
+
+func TestDetectCorrection(t *testing.T) {
+	for content, want := range map[string]bool{
+		"That's wrong, the release was in 2019.": true,
+		"you're mistaken about the port":         true,
+		"No, it's the other way around":          true,
+		"Actually, the default is 8080":          true,
+		"That is not true at all":                true,
+		"Wrong. Try again":                       true,
+		"No thanks, that's all":                  false,
+		"What's wrong with my config?":           false,
+		"That's right, thanks!":                  false,
+		"Is it true that Go has generics now?":   false,
+	} {
+		if got := DetectCorrection(content); got != want {
+			t.Errorf("%q: expected %v, got %v", content, want, got)
+		}
+	}
+}
+
+func TestRankInterestsDecaysAndSuppresses(t *testing.T) {
+	now := time.Now()
+	mem := func(age time.Duration, tags ...string) memory.RetrievalResult {
+		return memory.RetrievalResult{Memory: memory.Memory{CreatedAt: now.Add(-age), ConceptTags: tags}}
+	}
+	results := []memory.RetrievalResult{
+		mem(60*24*time.Hour, "chess"),
+		mem(61*24*time.Hour, "chess"),
+		mem(62*24*time.Hour, "chess"),
+		mem(time.Hour, "gardening"),
+		mem(2*time.Hour, "kubernetes"),
+		mem(3*time.Hour, "kubernetes"),
+		mem(time.Hour, InterestSuppressionTag, "kubernetes"),
+	}
+
+	got := rankInterests(results, now, 14*24*time.Hour, nil, 5)
+	if len(got) != 3 || got[0] != "kubernetes" || got[1] != "gardening" || got[len(got)-1] != "chess" {
+		t.Errorf("expected recent topics ahead of an old frequent one, got %v", got)
+	}
+
+	got = rankInterests(results, now, 14*24*time.Hour, map[string]bool{"kubernetes operators": true}, 5)
+	for _, topic := range got {
+		if topic == "kubernetes" || topic == InterestSuppressionTag {
+			t.Errorf("expected suppressed topics and suppression requests skipped, got %v", got)
+		}
+	}
+}
+
+func TestSuppressionsExcludeTopicsFromGoals(t *testing.T) {
+	db := newSuppressionDB(t)
+	if _, err := SuppressTopic(db, 1, " Kubernetes ", SuppressionChatRequest, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	again, err := SuppressTopic(db, 1, "kubernetes", SuppressionThumbsDown, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SuppressTopic(db, 2, "chess", SuppressionChatRequest, -time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := ActiveTopicSuppressions(db, 0)
+	if err != nil || len(active) != 1 || active[0].ID != again.ID || active[0].Reason != SuppressionThumbsDown {
+		t.Fatalf("expected one refreshed suppression and the expired one left out, got %+v (%v)", active, err)
+	}
+	if mine, _ := ActiveTopicSuppressions(db, 2); len(mine) != 0 {
+		t.Errorf("expected no active suppressions for user 2, got %+v", mine)
+	}
+
+	e := &Engine{db: db}
+	profile := &UserProfile{TopTopics: []string{"kubernetes", "gardening"}, TechnicalLevel: 0.5}
+	goal, err := e.GenerateUserAlignedGoal(context.Background(), newRand(1), profile, nil)
+	if err != nil || !strings.Contains(goal.Description, "gardening") {
+		t.Errorf("expected the suppressed topic skipped, got %q (%v)", goal.Description, err)
+	}
+	if _, err := e.GenerateUserAlignedGoal(context.Background(), newRand(1), &UserProfile{TopTopics: []string{"kubernetes"}}, nil); err == nil {
+		t.Error("expected no goal when every topic is suppressed")
+	}
+
+	exploratory := e.generateExploratoryGoal(context.Background(), newRand(1), []string{"kubernetes"}, "", nil)
+	if strings.Contains(exploratory.Description, "kubernetes") {
+		t.Errorf("expected an exploratory goal without the suppressed topic, got %q", exploratory.Description)
+	}
+}
@@ -3,11 +3,13 @@ package dialogue
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "log"
     "strings"
     "time"
 
+    "go-llama/internal/goal"
     "go-llama/internal/memory"
 )
 
@@ -17,22 +19,62 @@ func (e *Engine) detectPatterns(ctx context.Context) ([]string, error) {
     return []string{}, nil
 }
 
-// callLLM makes a request to the reasoning or simple model
-func (e *Engine) callLLM(ctx context.Context, prompt string, useSimpleModel bool) (string, int, error) {
-    // Determine target URL and Model based on routing flag
-    targetURL := e.llmURL
-    targetModel := e.llmModel
-
-    if useSimpleModel {
-        if e.simpleLLMURL != "" {
-            targetURL = e.simpleLLMURL
-            targetModel = e.simpleLLMModel
-            log.Printf("[Dialogue] Routing to Simple Model (1B)")
-        } else {
-            // Fallback to reasoning model if simple model not configured
-            log.Printf("[Dialogue] Simple Model requested but not configured, using Reasoning Model (8B)")
+// maxPreemptRequeues bounds how often one call is resubmitted after being preempted
+const maxPreemptRequeues = 10
+
+// requeueingCaller resubmits calls the LLM queue cancelled to serve user chat first, so a
+// preemption costs the dialogue time rather than failing the step
+type requeueingCaller struct {
+    caller goal.LLMCaller
+}
+
+// Call implements goal.LLMCaller
+func (r *requeueingCaller) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+    return r.requeue(ctx, url, payload, r.caller.Call)
+}
+
+// requeue makes the call, resubmitting it while the queue preempts it
+func (r *requeueingCaller) requeue(ctx context.Context, url string, payload map[string]interface{}, call func(context.Context, string, map[string]interface{}) ([]byte, error)) ([]byte, error) {
+    for attempt := 1; ; attempt++ {
+        body, err := call(ctx, url, payload)
+        if err == nil || !isPreempted(err) || attempt > maxPreemptRequeues || ctx.Err() != nil {
+            return body, err
         }
+        log.Printf("[Dialogue] LLM call preempted by user chat, requeueing (attempt %d/%d)", attempt, maxPreemptRequeues)
     }
+}
+
+// isPreempted reports whether the queue cancelled a call in favour of a critical request.
+// The llm package marks these errors with a Preempted method.
+func isPreempted(err error) bool {
+    var p interface{ Preempted() bool }
+    return errors.As(err, &p) && p.Preempted()
+}
+
+// ErrEmptyCompletion is returned when the model answers with empty or whitespace-only
+// content, typically after a context overflow or a stop-token problem on the backend
+var ErrEmptyCompletion = errors.New("LLM returned an empty completion")
+
+// emptyCompletionNudge is appended to the prompt when an empty completion is retried
+const emptyCompletionNudge = "\n\nYour previous answer was empty; respond with the requested format."
+
+// callLLM makes a request to the model the router assigns to callType. An empty
+// completion is retried once with a nudge; if it is still empty ErrEmptyCompletion is
+// returned so the caller fails only its own step.
+func (e *Engine) callLLM(ctx context.Context, prompt string, callType LLMCallType) (string, int, error) {
+    content, tokens, err := e.callLLMOnce(ctx, prompt, callType)
+    if !errors.Is(err, ErrEmptyCompletion) {
+        return content, tokens, err
+    }
+    log.Printf("[Dialogue] WARNING: Empty %s completion, retrying once", callType)
+    content, retryTokens, err := e.callLLMOnce(ctx, prompt+emptyCompletionNudge, callType)
+    return content, tokens + retryTokens, err
+}
+
+// callLLMOnce makes a single callLLM request
+func (e *Engine) callLLMOnce(ctx context.Context, prompt string, callType LLMCallType) (string, int, error) {
+    route := e.routeModel(callType)
+    sampling := route.Sampling.resolve(0.3, e.contextSizeFor(route))
 
     // If queue client is available, use it
     if e.llmClient != nil {
@@ -42,27 +84,12 @@ func (e *Engine) callLLM(ctx context.Context, prompt string, useSimpleModel bool
         }
 
         if client, ok := e.llmClient.(LLMCaller); ok {
-            reqBody := map[string]interface{}{
-                "model":	targetModel,
-                "max_tokens":	e.contextSize,
-                "messages": []map[string]string{
-                    {
-                        "role":		"system",
-                        "content":	"You are GrowerAI's internal dialogue system. Think briefly and clearly.",
-                    },
-                    {
-                        "role":		"user",
-                        "content":	prompt,
-                    },
-                },
-                "temperature":	0.3,
-                "stream":	false,
-            }
+            systemPrompt := e.renderSystemPrompt(PromptDialogueSystem, dialogueSystemPrompt{})
 
-            log.Printf("[Dialogue] LLM call via queue (prompt length: %d chars)", len(prompt))
+            log.Printf("[Dialogue] LLM call via queue (%s -> %s model %s, %s, prompt length: %d chars)", callType, route.Tier, route.Model, sampling, len(prompt))
             startTime := time.Now()
 
-            body, err := client.Call(ctx, targetURL, reqBody)
+            body, err := e.completeWithinContext(ctx, client, callType, route, systemPrompt, prompt, sampling)
             if err != nil {
                 log.Printf("[Dialogue] LLM queue call failed after %s: %v", time.Since(startTime), err)
                 return "", 0, fmt.Errorf("LLM call failed: %w", err)
@@ -76,10 +103,12 @@ func (e *Engine) callLLM(ctx context.Context, prompt string, useSimpleModel bool
                     Message struct {
                         Content string `json:"content"`
                     } `json:"message"`
+                    FinishReason string `json:"finish_reason"`
                 }	`json:"choices"`
                 Usage	struct {
                     TotalTokens int `json:"total_tokens"`
                 }	`json:"usage"`
+                Truncated bool `json:"truncated"` // Set by the streaming client
             }
 
             if err := json.Unmarshal(body, &result); err != nil {
@@ -92,6 +121,13 @@ func (e *Engine) callLLM(ctx context.Context, prompt string, useSimpleModel bool
 
             content := strings.TrimSpace(result.Choices[0].Message.Content)
             tokens := result.Usage.TotalTokens
+            if content == "" {
+                e.recordEmptyCompletion(callType)
+                return "", tokens, fmt.Errorf("%w (%s)", ErrEmptyCompletion, callType)
+            }
+            if result.Truncated {
+                return content, tokens, e.truncatedCompletion(callType, result.Choices[0].FinishReason, len(content))
+            }
 
             return content, tokens, nil
         }
@@ -102,56 +138,50 @@ func (e *Engine) callLLM(ctx context.Context, prompt string, useSimpleModel bool
     return "", 0, fmt.Errorf("LLM queue client required for dialogue")
 }
 
-func (e *Engine) callLLMWithStructuredReasoning(ctx context.Context, prompt string, expectJSON bool, systemPromptOverride string) (*ReasoningResponse, int, error) {
-    // Default system prompt for general reasoning
-    defaultSystemPrompt := `Output ONLY S-expressions (Lisp-style). No Markdown.
-
-CRITICAL ASSESSMENT RULES:
-1. Outcome Determination: If the user expresses dissatisfaction, frustration, or uses negative language (e.g., 'terrible', 'bad', 'wrong'), you MUST classify the outcome as 'bad' and 'mistake=true'.
-2. Heuristic Override: Extracting a lesson (learning=true) from a negative event does NOT make the outcome 'good'. 'outcome' reflects user satisfaction, not internal learning success.
-3. Mistakes: If outcome=bad due to user feedback, set mistake=true.
-
-Format: (reasoning (reflection "...") (insights "...") (goals_to_create (goal (description "...") (priority 8))))
-Example: (reasoning (reflection "Good session") (insights "Learned X") (goals_to_create (goal (description "Do Y") (priority 8))))`
-
+func (e *Engine) callLLMWithStructuredReasoning(ctx context.Context, prompt string, expectJSON bool, systemPromptOverride string, callType LLMCallType) (*ReasoningResponse, int, error) {
     // CRITICAL: Load and Inject Principles for ALL reasoning steps
     // This ensures Identity, Admin Rules, and Evolved Principles are front and centre
     // for Reflection, Planning, Assessment, and Goal Thinking.
-    principlesContext := ""
     principles, err := memory.LoadPrinciples(e.db)
     if err != nil {
         log.Printf("[Dialogue] WARNING: Failed to load principles for reasoning: %v", err)
         // Proceed without principles if DB error, but log it
-    } else {
-        // Format: "Today is... You are X... === PRINCIPLES ===..."
-        principlesContext = memory.FormatAsSystemPrompt(principles, 0.7)
+        principles = nil
     }
 
-    // Combine: Principles (Identity & Rules) + Technical Instructions (S-expression format)
-    finalSystemPrompt := principlesContext + "\n\n" + defaultSystemPrompt
-    
-    // Use override if provided (e.g., for assessments), but prepend principles first
-    if systemPromptOverride != "" {
-        finalSystemPrompt = principlesContext + "\n\n" + systemPromptOverride
+    return e.callLLMWithPrincipleSet(ctx, prompt, expectJSON, systemPromptOverride, principles, callType)
+}
+
+// callLLMWithPrincipleSet is callLLMWithStructuredReasoning with an explicit principle set.
+// Principle trials use it to evaluate a proposed principle without committing it. Empty
+// completions are retried once with a nudge, as in callLLM.
+func (e *Engine) callLLMWithPrincipleSet(ctx context.Context, prompt string, expectJSON bool, systemPromptOverride string, principles []memory.Principle, callType LLMCallType) (*ReasoningResponse, int, error) {
+    reasoning, tokens, err := e.callLLMWithPrincipleSetOnce(ctx, prompt, expectJSON, systemPromptOverride, principles, callType)
+    if !errors.Is(err, ErrEmptyCompletion) {
+        return reasoning, tokens, err
     }
+    log.Printf("[Dialogue] WARNING: Empty %s completion, retrying once", callType)
+    reasoning, retryTokens, err := e.callLLMWithPrincipleSetOnce(ctx, prompt+emptyCompletionNudge, expectJSON, systemPromptOverride, principles, callType)
+    return reasoning, tokens + retryTokens, err
+}
 
-    reqBody := map[string]interface{}{
-        "model":	e.llmModel,
-        "max_tokens":	e.contextSize,
-        "messages": []map[string]string{
-            {
-                "role":		"system",
-                "content": finalSystemPrompt,
-            },
-            {
-                "role":		"user",
-                "content":	prompt,
-            },
-        },
-        "temperature":	0.7,
-        "stream":	false,
+// callLLMWithPrincipleSetOnce makes a single structured reasoning request
+func (e *Engine) callLLMWithPrincipleSetOnce(ctx context.Context, prompt string, expectJSON bool, systemPromptOverride string, principles []memory.Principle, callType LLMCallType) (*ReasoningResponse, int, error) {
+    principlesContext := ""
+    if len(principles) > 0 {
+        // Format: "Today is... You are X... === PRINCIPLES ===..."
+        principlesContext = memory.FormatAsSystemPrompt(principles, 0.7)
     }
 
+    // The reasoning system template places the principles (identity and rules) around the
+    // call's own instructions (e.g. for assessments), or the default S-expression format
+    finalSystemPrompt := e.renderSystemPrompt(PromptReasoningSystem, reasoningSystemPrompt{
+        Principles:   principlesContext,
+        Instructions: systemPromptOverride,
+    })
+
+    route := e.routeModel(callType)
+    sampling := route.Sampling.resolve(0.7, e.contextSizeFor(route))
     // Use queue if available
     if e.llmClient != nil {
         type LLMCaller interface {
@@ -159,10 +189,10 @@ Example: (reasoning (reflection "Good session") (insights "Learned X") (goals_to
         }
 
         if client, ok := e.llmClient.(LLMCaller); ok {
-            log.Printf("[Dialogue] Structured reasoning LLM call via queue (prompt length: %d chars)", len(prompt))
+            log.Printf("[Dialogue] Structured reasoning LLM call via queue (%s -> %s model %s, %s, prompt length: %d chars)", callType, route.Tier, route.Model, sampling, len(prompt))
             startTime := time.Now()
 
-            body, err := client.Call(ctx, e.llmURL, reqBody)
+            body, err := e.completeWithinContext(ctx, client, callType, route, finalSystemPrompt, prompt, sampling)
             if err != nil {
                 log.Printf("[Dialogue] Structured reasoning queue call failed after %s: %v", time.Since(startTime), err)
                 return nil, 0, fmt.Errorf("LLM call failed: %w", err)
@@ -176,10 +206,12 @@ Example: (reasoning (reflection "Good session") (insights "Learned X") (goals_to
                     Message struct {
                         Content string `json:"content"`
                     } `json:"message"`
+                    FinishReason string `json:"finish_reason"`
                 }	`json:"choices"`
                 Usage	struct {
                     TotalTokens int `json:"total_tokens"`
                 }	`json:"usage"`
+                Truncated bool `json:"truncated"` // Set by the streaming client
             }
 
             if err := json.Unmarshal(body, &result); err != nil {
@@ -192,6 +224,15 @@ Example: (reasoning (reflection "Good session") (insights "Learned X") (goals_to
 
             content := strings.TrimSpace(result.Choices[0].Message.Content)
             tokens := result.Usage.TotalTokens
+            if content == "" {
+                e.recordEmptyCompletion(callType)
+                return nil, tokens, fmt.Errorf("%w (%s)", ErrEmptyCompletion, callType)
+            }
+            if result.Truncated {
+                // A cut-short S-expression is missing its closing structure; don't guess at it
+                return nil, tokens, e.truncatedCompletion(callType, result.Choices[0].FinishReason, len(content))
+            }
+            e.recordTranscriptCall(ctx, callType, prompt, content)
 
             // Parse S-expression with automatic repair
             reasoning, err := ParseReasoningSExpr(content)
@@ -406,7 +447,7 @@ func (e *Engine) determineGoalTier(description string, priority int, reasoning s
 }
 
 // performEnhancedReflection performs structured reasoning about recent activity
-func (e *Engine) performEnhancedReflection(ctx context.Context, state *InternalState) (*ReasoningResponse, []memory.Principle, int, error) {
+func (e *Engine) performEnhancedReflection(ctx context.Context, state *InternalState, depth string, metrics *CycleMetrics) (*ReasoningResponse, []memory.Principle, int, error) {
     // CRITICAL: Load principles FIRST - these define identity and values
     principles, err := memory.LoadPrinciples(e.db)
     if err != nil {
@@ -429,25 +470,40 @@ func (e *Engine) performEnhancedReflection(ctx context.Context, state *InternalS
 
     // For collective memory search (learnings), use a lower threshold
     // Recent learnings might not have perfect semantic match but should still be retrieved
-    collectiveThreshold := searchThreshold
-    if collectiveThreshold > 0.20 {
-        collectiveThreshold = 0.20	// Lower threshold for collective memories
+    collectiveThreshold := e.adaptiveConfig.GetCollectiveThreshold()
+
+    // Digests summarize many learnings at once; when available they take precedence
+    // and fewer raw memories are pulled in alongside them
+    digests, err := e.recentDigests(ctx, 3)
+    if err != nil {
+        log.Printf("[Dialogue] WARNING: Failed to load digests: %v", err)
+        digests = nil
+    }
+    memoryLimit, learningLimit := 10, 5
+    if len(digests) > 0 {
+        memoryLimit, learningLimit = 6, 2
+        log.Printf("[Dialogue] Using %d recent digests in reflection context", len(digests))
     }
 
+    // Extra candidates with their embeddings let near-copies of one learning be
+    // replaced by the next distinct memories
     query := memory.RetrievalQuery{
-        Limit:			10,	// Increased from 8 to get more learnings
+        Limit:			memoryLimit * reflectionCandidateFactor,
         MinScore:		collectiveThreshold,
         IncludeCollective:	true,
         IncludePersonal:	false,	// Explicitly exclude personal for collective-only search
+        WithVectors:		true,
     }
 
     log.Printf("[Dialogue] Searching collective memories (threshold: %.2f [adaptive: %.2f], limit: %d)",
-        collectiveThreshold, searchThreshold, 10)
+        collectiveThreshold, searchThreshold, memoryLimit)
 
     results, err := e.storage.Search(ctx, query, embedding)
     if err != nil {
         return nil, nil, 0, fmt.Errorf("failed to search memories: %w", err)
     }
+    diversity := e.reflectionDiversityThreshold()
+    results, suppressed := diverseResults(results, memoryLimit, diversity)
 
     log.Printf("[Dialogue] Collective memory search returned %d results", len(results))
     if len(results) > 0 {
@@ -459,11 +515,12 @@ func (e *Engine) performEnhancedReflection(ctx context.Context, state *InternalS
 
     // Additionally search specifically for learnings (by concept tag)
     learningQuery := memory.RetrievalQuery{
-        Limit:			5,
+        Limit:			learningLimit * reflectionCandidateFactor,
         MinScore:		0.15,	// Very low threshold for tagged learnings
         IncludeCollective:	true,
         IncludePersonal:	false,
         ConceptTags:		[]string{"learning"},	// Search for learning tag specifically
+        WithVectors:		true,
     }
 
     // Create a simple embedding for "learning" query
@@ -473,28 +530,50 @@ func (e *Engine) performEnhancedReflection(ctx context.Context, state *InternalS
         if err == nil && len(learningResults) > 0 {
             log.Printf("[Dialogue] Found %d additional learnings by concept tag", len(learningResults))
 
-            // Merge learning results with main results (avoid duplicates)
+            // Merge learning results with main results (avoid duplicates and near-copies)
             existingIDs := make(map[string]bool)
             for _, r := range results {
                 existingIDs[r.Memory.ID] = true
             }
 
+            var candidates []memory.RetrievalResult
             for _, lr := range learningResults {
                 if !existingIDs[lr.Memory.ID] {
-                    results = append(results, lr)
-                    log.Printf("[Dialogue]   Learning: score=%.2f, content=%s",
-                        lr.Score, truncate(lr.Memory.Content, 60))
+                    candidates = append(candidates, lr)
                 }
             }
+            merged, learningSuppressed := diverseResults(append(results, candidates...), len(results)+learningLimit, diversity)
+            for _, lr := range merged[len(results):] {
+                log.Printf("[Dialogue]   Learning: score=%.2f, content=%s",
+                    lr.Score, truncate(lr.Memory.Content, 60))
+            }
+            results, suppressed = merged, suppressed+learningSuppressed
         }
     }
+    if suppressed > 0 {
+        log.Printf("[Dialogue] Left %d near-duplicate memories out of the reflection context (similarity > %.2f)", suppressed, diversity)
+    }
+    if metrics != nil {
+        metrics.ReflectionNearDuplicates = suppressed
+    }
 
     // Build context for reasoning
-    memoryContext := "Recent memories:\n"
+    memoryContext := ""
+    if len(digests) > 0 {
+        memoryContext += "Recent digests (high-level summaries, newest first):\n"
+        for i, digest := range digests {
+            memoryContext += fmt.Sprintf("%d. %s\n", i+1, truncate(digest.Content, 600))
+        }
+        memoryContext += "\n"
+    }
+    memoryContext += "Recent memories:\n"
     if len(results) == 0 {
         memoryContext += "No recent memories found.\n"
     } else {
         for i, result := range results {
+            if isDigest(result.Memory) {
+                continue
+            }
             outcome := result.Memory.OutcomeTag
             if outcome == "" {
                 outcome = "unrated"
@@ -503,93 +582,92 @@ func (e *Engine) performEnhancedReflection(ctx context.Context, state *InternalS
         }
     }
 
-    // Add current goals context
+    // Add current goals context, most important first
     goalsContext := fmt.Sprintf("\nCurrent active goals: %d\n", len(state.ActiveGoals))
     if len(state.ActiveGoals) > 0 {
-        for i, goal := range state.ActiveGoals {
-            goalsContext += fmt.Sprintf("%d. %s (progress: %.0f%%, priority: %d, age: %s)\n",
+        for i, goal := range sortGoalsByPriority(state.ActiveGoals) {
+            waiting := ""
+            if goal.Status == GoalStatusAwaitingUser {
+                waiting = " [waiting for the user's answer]"
+            }
+            goalsContext += fmt.Sprintf("%d. %s (progress: %.0f%%, priority: %d, age: %s)%s\n",
                 i+1, truncate(goal.Description, 60), goal.Progress*100, goal.Priority,
-                time.Since(goal.Created).Round(time.Minute))
+                time.Since(goal.Created).Round(time.Minute), waiting)
         }
     }
 
-    // Add recently abandoned goals context (last 5)
-    recentlyAbandoned := []Goal{}
-    if len(state.CompletedGoals) > 0 {
-        startIdx := len(state.CompletedGoals) - 5
-        if startIdx < 0 {
-            startIdx = 0
-        }
-        for i := startIdx; i < len(state.CompletedGoals); i++ {
-            if state.CompletedGoals[i].Status == GoalStatusAbandoned {
-                recentlyAbandoned = append(recentlyAbandoned, state.CompletedGoals[i])
-            }
+    // Remind the reflection of the focus areas it chose in earlier cycles
+    goalsContext += focusContext(state.FocusAreas)
+
+    // A thought or learning repeated since the last reflection is pointed out once
+    goalsContext += e.takeRepetitionHint()
+
+    // A low acceptance rate of recent proposals asks for fewer, more distinct ones
+    goalsContext += e.goalProposalHint(ctx)
+
+    // A full action backlog stops new goals until the active ones are worked through
+    goalsContext += e.backpressureHint(state)
+
+    // Notes on the goal worked on last carry its intermediate conclusions forward
+    if pursued := pursuedGoal(state); pursued != nil {
+        goalsContext += fmt.Sprintf("\nMost recently pursued goal: %s\n", truncate(pursued.Description, 100))
+        goalsContext += goalNotesContext(pursued)
+        goalsContext += goalNotesInstruction + "\n"
+        if question := pursued.PendingUserQuestion; question != nil {
+            goalsContext += fmt.Sprintf("This goal is paused until the user answers: %s\n", question.Question)
+        } else {
+            goalsContext += userQuestionInstruction + "\n"
         }
     }
 
+    // Add recently abandoned goals context (last 5)
+    recentlyAbandoned := e.recentlyAbandonedGoals(ctx, state, 5)
+
     if len(recentlyAbandoned) > 0 {
         goalsContext += "\nRecently abandoned goals (avoid recreating these):\n"
         for i, goal := range recentlyAbandoned {
             goalsContext += fmt.Sprintf("%d. %s (outcome: %s)\n",
                 i+1, truncate(goal.Description, 60), goal.Outcome)
         }
+
+        // Post-mortems say why those goals failed, so a rephrased goal can avoid the same approach
+        topics := make([]string, len(recentlyAbandoned))
+        for i, goal := range recentlyAbandoned {
+            topics[i] = goal.Description
+        }
+        if lessons := e.recentPostMortems(ctx, strings.Join(topics, "; ")); len(lessons) > 0 {
+            goalsContext += "\nPost-mortems of failed goals (a new goal on these topics must take a different approach):\n"
+            for _, lesson := range lessons {
+                goalsContext += "- " + lesson + "\n"
+            }
+        }
     }
 
     // Add available tools to context
     toolsContext := e.getAvailableToolsList()
 
-    // Build prompt based on reasoning depth
-    var prompt string
-    switch e.reasoningDepth {
-    case "deep":
-        prompt = fmt.Sprintf(`%s
-
-%s%s
-%s
-
-Perform deep analysis:
-1. Reflect on what these memories reveal about recent interactions
-2. Identify at least 3 insights or patterns
-3. Assess your strengths and weaknesses honestly
-4. Identify knowledge gaps that need addressing
-5. Propose 1-3 specific goals with detailed action plans (use only available tools)
-6. Extract learnings about what strategies work
-7. Provide comprehensive self-assessment
-
-Be thorough and analytical. Focus on actionable insights.`, principlesContext, memoryContext, goalsContext, toolsContext)
-
-    case "moderate":
-        prompt = fmt.Sprintf(`%s
-
-%s%s
-%s
-
-Analyze recent activity:
-1. What patterns do you see in these memories?
-2. What are you doing well? What needs improvement?
-3. What knowledge gaps should you address?
-4. Propose 1-2 goals with action plans (use only available tools)
-5. What have you learned about effective strategies?
-
-Be analytical but concise.`, principlesContext, memoryContext, goalsContext, toolsContext)
-
-    default:	// conservative
-        prompt = fmt.Sprintf(`%s
-
-%s%s
-%s
-
-Brief analysis:
-1. Key takeaway from recent memories?
-2. One strength, one weakness
-3. Most important knowledge gap to address?
-4. Propose one goal if needed (use only available tools if action plan provided)
-
-Keep it focused and actionable.`, principlesContext, memoryContext, goalsContext, toolsContext)
+    // The reflection template positions the principles and context; its wording depends
+    // on the reasoning depth
+    rendered, err := e.promptRegistry().Render(PromptReflection, reflectionPrompt{
+        Principles: principlesContext,
+        Memories:   memoryContext,
+        Goals:      goalsContext,
+        Tools:      toolsContext,
+        Depth:      depth,
+    })
+    if err != nil {
+        return nil, nil, 0, err
     }
+    e.recordPromptUse(rendered.Template)
 
     // Call LLM with structured reasoning
-    reasoning, tokens, err := e.callLLMWithStructuredReasoning(ctx, prompt, true, "")
+    reasoning, tokens, err := e.callLLMWithStructuredReasoning(withTranscriptStep(ctx, TranscriptStepReflection), rendered.Text, true, rendered.System, CallDeepReflection)
+    if errors.Is(err, ErrEmptyCompletion) {
+        // Reflect from metrics alone rather than failing the cycle; the smart fallback
+        // below fills in the reflection text
+        log.Printf("[Dialogue] WARNING: Reflection returned nothing, continuing without LLM insights")
+        reasoning, err = &ReasoningResponse{Insights: []string{}}, nil
+    }
     if err != nil {
         return nil, nil, tokens, err
     }
@@ -664,7 +742,7 @@ func (e *Engine) calculateConfidence(ctx context.Context, state *InternalState)
     if len(recentGoals) > 0 {
         successCount := 0
         for _, goal := range recentGoals {
-            if goal.Outcome == "good" {
+            if goal.Outcome.Succeeded() {
                 successCount++
             }
         }
@@ -713,10 +791,26 @@ func (e *Engine) calculateConfidence(ctx context.Context, state *InternalState)
     return confidence
 }
 
-// createGoalFromProposal creates a Goal from an LLM proposal
-func (e *Engine) createGoalFromProposal(proposal GoalProposal) Goal {
-    // Determine tier based on priority and description
+// createGoalFromProposal creates a Goal from an LLM proposal, after bringing its
+// priority into range. It fails for a proposal without a usable description.
+func (e *Engine) createGoalFromProposal(proposal GoalProposal) (Goal, error) {
+    proposal, err := normalizeGoalProposal(proposal, e.goalProposalConfig())
+    if err != nil {
+        return Goal{}, err
+    }
+
+    // Determine tier based on priority and description; the model's own claim can only
+    // lower it
     tier := e.determineGoalTier(proposal.Description, proposal.Priority, proposal.Reasoning)
+    if stricter := stricterTier(tier, proposal.Tier); stricter != tier {
+        log.Printf("[Dialogue] Proposal claims tier %s, not %s: %s", stricter, tier, truncate(proposal.Description, 60))
+        tier = stricter
+    }
+
+    deadline, err := goal.ParseDeadline(proposal.Deadline)
+    if err != nil {
+        log.Printf("[Dialogue] Ignoring deadline on proposed goal: %v", err)
+    }
 
     goal := Goal{
         ID:			fmt.Sprintf("goal_%d", time.Now().UnixNano()),
@@ -724,6 +818,7 @@ func (e *Engine) createGoalFromProposal(proposal GoalProposal) Goal {
         Source:			GoalSourceKnowledgeGap,	// Could be smarter based on reasoning
         Priority:		proposal.Priority,
         Created:		time.Now(),
+        Deadline:		deadline,
         Progress:		0.0,
         Status:			GoalStatusActive,
         Actions:		[]Action{},
@@ -739,10 +834,11 @@ func (e *Engine) createGoalFromProposal(proposal GoalProposal) Goal {
             action := e.parseActionFromPlan(planStep)
             goal.Actions = append(goal.Actions, action)
         }
+        linkPlanDependencies(goal.Actions)
         log.Printf("[Dialogue] Created %d actions from LLM action plan", len(goal.Actions))
     }
 
-    return goal
+    return goal, nil
 }
 
 // parseActionFromPlan converts an LLM action plan step into an Action
@@ -765,21 +861,23 @@ func (e *Engine) createGoalFromProposal(proposal GoalProposal) Goal {
 
 // List tools in logical order
 
-// storeLearning stores a learning as a collective memory and returns the memory ID
-func (e *Engine) storeLearning(ctx context.Context, learning Learning) (string, error) {
+// storeLearning stores a learning as a collective memory, merging it into an
+// existing near-duplicate when one is found, and reports which happened
+func (e *Engine) storeLearning(ctx context.Context, learning Learning) (*memory.StoreResult, error) {
     content := fmt.Sprintf("LEARNING [%s]: %s (Context: %s, Confidence: %.2f)",
         learning.Category, learning.What, learning.Context, learning.Confidence)
 
     embedding, err := e.embedder.Embed(ctx, content)
     if err != nil {
         log.Printf("[Dialogue] WARNING: Failed to embed learning: %v", err)
-        return "", err
+        return nil, err
     }
 
     mem := &memory.Memory{
         Content:		content,
         ImportanceScore:	learning.Confidence,	// Use confidence as importance
         IsCollective:		true,			// Learnings are collective knowledge
+        SourceKind:		memory.SourceDialogueLearning,
         ConceptTags:		[]string{"learning", learning.Category},
         OutcomeTag:		"good",	// Learnings are positive
         ValidationCount:	1,	// Pre-validated
@@ -791,16 +889,22 @@ func (e *Engine) storeLearning(ctx context.Context, learning Learning) (string,
         Embedding:		embedding,
     }
 
+    e.stampProvenance(ctx, mem, "", nil)
+
     log.Printf("[Dialogue] Storing learning as collective memory (is_collective=true): %s", truncate(learning.What, 60))
 
-    err = e.storage.Store(ctx, mem)
+    result, err := e.storage.StoreWithDedup(ctx, mem, e.dedupThreshold)
     if err != nil {
         log.Printf("[Dialogue] ERROR: Failed to store learning in Qdrant: %v", err)
-        return "", err
+        return nil, err
     }
 
-    log.Printf("[Dialogue] ✓ Learning stored successfully (ID: %s, is_collective: true)", mem.ID)
-    return mem.ID, nil
+    if result.Decision == memory.StoreDecisionMerged {
+        log.Printf("[Dialogue] ✓ Learning merged into existing memory %s (similarity: %.3f)", result.MemoryID, result.Similarity)
+    } else {
+        log.Printf("[Dialogue] ✓ Learning stored successfully (ID: %s, is_collective: true)", result.MemoryID)
+    }
+    return result, nil
 }
 
 // Misc helpers (generateJitter, truncateResponse) moved to utils.go
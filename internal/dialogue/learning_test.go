@@ -0,0 +1,100 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type preemptedErr struct{}
+
+func (preemptedErr) Error() string   { return "preempted" }
+func (preemptedErr) Preempted() bool { return true }
+
+// scriptedCaller returns the queued errors in order, then succeeds
+type scriptedCaller struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedCaller) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	return []byte("ok"), nil
+}
+
+func TestRequeueingCallerRetriesPreemptedCalls(t *testing.T) {
+	inner := &scriptedCaller{errs: []error{preemptedErr{}, fmt.Errorf("queue: %w", preemptedErr{})}}
+	body, err := (&requeueingCaller{caller: inner}).Call(context.Background(), "http://llm", nil)
+	if err != nil || string(body) != "ok" || inner.calls != 3 {
+		t.Fatalf("expected success on the third attempt, got %q, %v after %d calls", body, err, inner.calls)
+	}
+
+	failing := &scriptedCaller{errs: []error{errors.New("circuit breaker open")}}
+	if _, err := (&requeueingCaller{caller: failing}).Call(context.Background(), "http://llm", nil); err == nil || failing.calls != 1 {
+		t.Errorf("expected other errors to be returned without retry, got %v after %d calls", err, failing.calls)
+	}
+
+	always := &scriptedCaller{}
+	for i := 0; i <= maxPreemptRequeues+5; i++ {
+		always.errs = append(always.errs, preemptedErr{})
+	}
+	if _, err := (&requeueingCaller{caller: always}).Call(context.Background(), "http://llm", nil); !isPreempted(err) || always.calls != maxPreemptRequeues+1 {
+		t.Errorf("expected retries to stop after %d requeues, got %d calls (%v)", maxPreemptRequeues, always.calls, err)
+	}
+}
+
+// completionCaller answers with the queued completions in order and records each prompt
+type completionCaller struct {
+	replies []string
+	prompts []string
+}
+
+func (c *completionCaller) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	messages := payload["messages"].([]map[string]string)
+	c.prompts = append(c.prompts, messages[len(messages)-1]["content"])
+	reply := ""
+	if len(c.prompts) <= len(c.replies) {
+		reply = c.replies[len(c.prompts)-1]
+	}
+	return json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"content": reply}}},
+		"usage":   map[string]int{"total_tokens": 10},
+	})
+}
+
+func TestCallLLMRetriesEmptyCompletionOnce(t *testing.T) {
+	caller := &completionCaller{replies: []string{"  \n", "A synthesis."}}
+	e := &Engine{llmClient: caller, modelRouter: NewModelRouter("http://reasoning", "8b", "", "")}
+
+	content, tokens, err := e.callLLM(context.Background(), "Synthesize.", CallSynthesis)
+	if err != nil || content != "A synthesis." || tokens != 20 {
+		t.Fatalf("expected the retry to succeed, got %q, %d tokens, %v", content, tokens, err)
+	}
+	if len(caller.prompts) != 2 || !strings.HasSuffix(caller.prompts[1], emptyCompletionNudge) {
+		t.Errorf("expected one retry with the nudge appended, got %q", caller.prompts)
+	}
+	if got := e.ModelRoutingStats().ByCallType["synthesis"]; got.Empty != 1 || got.Reasoning != 2 {
+		t.Errorf("expected one empty completion counted, got %+v", got)
+	}
+}
+
+func TestStructuredCallFailsAfterSecondEmptyCompletion(t *testing.T) {
+	caller := &completionCaller{}
+	e := &Engine{llmClient: caller, modelRouter: NewModelRouter("http://reasoning", "8b", "", "")}
+
+	_, _, err := e.callLLMWithPrincipleSet(context.Background(), "Plan.", true, "", nil, CallPlanGeneration)
+	if !errors.Is(err, ErrEmptyCompletion) || len(caller.prompts) != 2 {
+		t.Fatalf("expected ErrEmptyCompletion after one retry, got %v after %d calls", err, len(caller.prompts))
+	}
+	if total := e.ModelRoutingStats().Total; total.Empty != 2 {
+		t.Errorf("expected both empty completions counted, got %+v", total)
+	}
+}
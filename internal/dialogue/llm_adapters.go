@@ -70,7 +70,6 @@ func (g *GoalsOrString) UnmarshalJSON(data []byte) error {
             if s != "" {
                 proposals = append(proposals, GoalProposal{
                     Description:  s,
-                    Priority:     7, // Default priority
                     Reasoning:    "Generated from simplified goal description",
                     ActionPlan:   []string{},
                     ExpectedTime: "unknown",
@@ -142,10 +141,12 @@ func (l LearningsOrString) ToSlice() []Learning {
 // GoalProposal represents a goal the LLM wants to create
 type GoalProposal struct {
     Description  string   `json:"description"`
-    Priority     int      `json:"priority"`
+    Priority     int      `json:"priority"` // 0 when missing; createGoalFromProposal applies the default
+    Tier         string   `json:"tier,omitempty"` // The model's own tier claim, if any
     Reasoning    string   `json:"reasoning"`
     ActionPlan   []string `json:"action_plan"`
     ExpectedTime string   `json:"expected_time"` // e.g., "2 cycles", "1 week"
+    Deadline     string   `json:"deadline,omitempty"` // Optional, RFC3339 or YYYY-MM-DD
 }
 
 // Learning represents something learned from experience
@@ -0,0 +1,89 @@
+// internal/dialogue/load_shedding.go
+package dialogue
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// SkippedReasonLLMOverloaded marks a cycle that skipped reflection and planning because
+// the reasoning model's queue was backed up
+const SkippedReasonLLMOverloaded = "llm_overloaded"
+
+// LoadSheddingConfig sets when the reasoning model counts as overloaded. An overloaded
+// cycle skips reflection, goal proposals and planning, but still runs the tool steps
+// goals already have planned, which don't need the reasoning model.
+type LoadSheddingConfig struct {
+	Enabled       bool
+	MaxQueueDepth int           // Requests queued ahead of the dialogue's next call (0 = no limit)
+	MaxWait       time.Duration // Recent time dialogue calls waited for a slot (0 = no limit)
+}
+
+// Validate rejects negative limits, and an enabled config with no limit at all
+func (c LoadSheddingConfig) Validate() error {
+	if c.MaxQueueDepth < 0 {
+		return fmt.Errorf("load_shedding.max_queue_depth must not be negative, got %d", c.MaxQueueDepth)
+	}
+	if c.MaxWait < 0 {
+		return fmt.Errorf("load_shedding.max_wait_seconds must not be negative, got %s", c.MaxWait)
+	}
+	if c.Enabled && c.MaxQueueDepth == 0 && c.MaxWait == 0 {
+		return fmt.Errorf("load_shedding needs max_queue_depth or max_wait_seconds when enabled")
+	}
+	return nil
+}
+
+// LLMLoad is how backed up the reasoning model's queue is for the dialogue's calls
+type LLMLoad struct {
+	QueueDepth int
+	RecentWait time.Duration
+}
+
+// SetLoadShedding sets when cycles shed their reasoning-model phases
+func (e *Engine) SetLoadShedding(cfg LoadSheddingConfig) {
+	e.loadShedding = cfg
+}
+
+// SetLLMLoadProbe gives the engine a way to read the reasoning model's queue load.
+// Without one, cycles never shed load.
+func (e *Engine) SetLLMLoadProbe(probe func() LLMLoad) {
+	e.llmLoad = probe
+}
+
+// llmOverloaded reports whether the reasoning model is too backed up for this cycle's
+// reflection and planning, and why
+func (e *Engine) llmOverloaded() (bool, string) {
+	cfg := e.loadShedding
+	if !cfg.Enabled || e.llmLoad == nil {
+		return false, ""
+	}
+	load := e.llmLoad()
+	if cfg.MaxQueueDepth > 0 && load.QueueDepth > cfg.MaxQueueDepth {
+		return true, fmt.Sprintf("%d requests queued, max %d", load.QueueDepth, cfg.MaxQueueDepth)
+	}
+	if cfg.MaxWait > 0 && load.RecentWait > cfg.MaxWait {
+		return true, fmt.Sprintf("recent calls waited %s, max %s", load.RecentWait.Round(time.Second), cfg.MaxWait)
+	}
+	return false, ""
+}
+
+// shedLoad checks the reasoning model's load at the start of a cycle and marks the cycle
+// to skip its reasoning-model phases when it is overloaded
+func (e *Engine) shedLoad(cc *cycleContext) {
+	overloaded, why := e.llmOverloaded()
+	cc.shedding = overloaded
+	if e.goalOrchestrator != nil {
+		e.goalOrchestrator.SetReasoningPaused(overloaded)
+	}
+	if !overloaded {
+		return
+	}
+	cc.metrics.SkippedReason = SkippedReasonLLMOverloaded
+	log.Printf("[Dialogue] Reasoning model overloaded, %s: running planned actions only, skipping reflection and planning", why)
+	e.publishEvent(EventCycleSkipped, "", "", map[string]interface{}{
+		"reason":  SkippedReasonLLMOverloaded,
+		"partial": true,
+		"detail":  why,
+	})
+}
@@ -0,0 +1,58 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOverloadedCycleSkipsReflection(t *testing.T) {
+	caller := &overflowCaller{}
+	e := &Engine{events: NewEventBus(), llmClient: caller}
+	sub := e.events.Subscribe(4)
+	e.SetLoadShedding(LoadSheddingConfig{Enabled: true, MaxQueueDepth: 4, MaxWait: time.Minute})
+	load := LLMLoad{QueueDepth: 12}
+	e.SetLLMLoadProbe(func() LLMLoad { return load })
+
+	cc := &cycleContext{state: &InternalState{}, metrics: &CycleMetrics{}}
+	e.shedLoad(cc)
+	if !cc.shedding || cc.metrics.SkippedReason != SkippedReasonLLMOverloaded {
+		t.Fatalf("expected the cycle to shed load, got shedding=%v reason=%q", cc.shedding, cc.metrics.SkippedReason)
+	}
+	if event := <-sub.Events(); event.Type != EventCycleSkipped || event.Data["reason"] != SkippedReasonLLMOverloaded {
+		t.Errorf("expected a partial skip event, got %+v", event)
+	}
+
+	if err := e.runPhaseReflection(context.Background(), cc); err != nil {
+		t.Fatalf("expected reflection skipped without error, got %v", err)
+	}
+	if cc.reasoning == nil || cc.thoughts != 0 || len(caller.prompts) != 0 {
+		t.Errorf("expected an empty reasoning object and no LLM call, got %d calls", len(caller.prompts))
+	}
+
+	// Slow recent calls count too; a quiet queue doesn't shed
+	load = LLMLoad{QueueDepth: 1, RecentWait: 3 * time.Minute}
+	if overloaded, why := e.llmOverloaded(); !overloaded || why == "" {
+		t.Error("expected a long recent wait to count as overloaded")
+	}
+	load = LLMLoad{QueueDepth: 4, RecentWait: time.Minute}
+	cc = &cycleContext{state: &InternalState{}, metrics: &CycleMetrics{}}
+	if e.shedLoad(cc); cc.shedding || cc.metrics.SkippedReason != "" {
+		t.Errorf("expected load at the limits not shed, got reason %q", cc.metrics.SkippedReason)
+	}
+}
+
+func TestLoadSheddingConfigValidate(t *testing.T) {
+	for _, cfg := range []LoadSheddingConfig{
+		{Enabled: true},
+		{MaxQueueDepth: -1},
+		{Enabled: true, MaxWait: -time.Second},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected %+v rejected", cfg)
+		}
+	}
+	if err := (LoadSheddingConfig{Enabled: true, MaxWait: time.Minute}).Validate(); err != nil {
+		t.Errorf("expected a wait-only limit accepted, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+// internal/dialogue/memory_diversity.go
+package dialogue
+
+import (
+	"go-llama/internal/memory"
+)
+
+// Reflection context diversity defaults
+const (
+	DefaultReflectionDiversity = 0.9 // Embedding similarity above which two memories are near-copies
+	reflectionCandidateFactor  = 3   // Candidates fetched per context slot, so suppressed copies can be replaced
+)
+
+// SetReflectionDiversity sets the embedding similarity (0-1) above which a memory is left
+// out of the reflection context as a near-copy of a more relevant one. A threshold <= 0
+// uses DefaultReflectionDiversity; 1 keeps near-copies.
+func (e *Engine) SetReflectionDiversity(threshold float64) {
+	if threshold <= 0 {
+		threshold = DefaultReflectionDiversity
+	}
+	e.reflectionDiversity = threshold
+}
+
+func (e *Engine) reflectionDiversityThreshold() float64 {
+	if e.reflectionDiversity <= 0 {
+		return DefaultReflectionDiversity
+	}
+	return e.reflectionDiversity
+}
+
+// diverseResults walks results in order and keeps up to limit of them, leaving out any
+// whose embedding is more than threshold similar to one already kept, so each cluster
+// of near-copies is represented by its first member and the next distinct memories fill
+// the freed slots. Results without an embedding are always kept. It also returns how
+// many near-copies were left out before the limit was reached.
+func diverseResults(results []memory.RetrievalResult, limit int, threshold float64) ([]memory.RetrievalResult, int) {
+	kept := make([]memory.RetrievalResult, 0, limit)
+	suppressed := 0
+	for _, candidate := range results {
+		if len(kept) >= limit {
+			break
+		}
+		if nearCopyOfAny(candidate.Memory.Embedding, kept, threshold) {
+			suppressed++
+			continue
+		}
+		kept = append(kept, candidate)
+	}
+	return kept, suppressed
+}
+
+// nearCopyOfAny reports whether embedding is more than threshold similar to a kept result
+func nearCopyOfAny(embedding []float32, kept []memory.RetrievalResult, threshold float64) bool {
+	if len(embedding) == 0 {
+		return false
+	}
+	for _, k := range kept {
+		if len(k.Memory.Embedding) > 0 && cosineSimilarity(embedding, k.Memory.Embedding) > threshold {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package dialogue
+
+import (
+	"fmt"
+	"testing"
+
+	"go-llama/internal/memory"
+)
+
+// clusteredResults returns count results per cluster in descending score order, the
+// first cluster's near-copies ranked above everything else. Members of a cluster point
+// almost the same way; clusters are orthogonal.
+func clusteredResults(clusters, count int) []memory.RetrievalResult {
+	var results []memory.RetrievalResult
+	score := 1.0
+	for c := 0; c < clusters; c++ {
+		for i := 0; i < count; i++ {
+			embedding := make([]float32, clusters+1)
+			embedding[c] = 1
+			embedding[clusters] = float32(i) * 0.05 // Slight variation within the cluster
+			results = append(results, memory.RetrievalResult{
+				Memory: memory.Memory{ID: fmt.Sprintf("c%d_%d", c, i), Embedding: embedding},
+				Score:  score,
+			})
+			score -= 0.01
+		}
+	}
+	return results
+}
+
+func TestDiverseResultsPicksOnePerCluster(t *testing.T) {
+	kept, suppressed := diverseResults(clusteredResults(3, 4), 10, DefaultReflectionDiversity)
+	if len(kept) != 3 || kept[0].Memory.ID != "c0_0" || kept[1].Memory.ID != "c1_0" || kept[2].Memory.ID != "c2_0" {
+		t.Fatalf("expected the best of each cluster, got %v", resultIDs(kept))
+	}
+	if suppressed != 9 {
+		t.Errorf("expected 9 near-copies suppressed, got %d", suppressed)
+	}
+
+	// Top-2 by score would be two copies of the first cluster; the second slot backfills
+	kept, suppressed = diverseResults(clusteredResults(3, 4), 2, DefaultReflectionDiversity)
+	if len(kept) != 2 || kept[1].Memory.ID != "c1_0" || suppressed != 3 {
+		t.Errorf("expected the next distinct memory backfilled, got %v (%d suppressed)", resultIDs(kept), suppressed)
+	}
+}
+
+func TestDiverseResultsKeepsWhatItCannotCompare(t *testing.T) {
+	results := append(clusteredResults(1, 2), memory.RetrievalResult{Memory: memory.Memory{ID: "no_vector"}})
+	if kept, suppressed := diverseResults(results, 10, DefaultReflectionDiversity); len(kept) != 2 || kept[1].Memory.ID != "no_vector" || suppressed != 1 {
+		t.Errorf("expected a memory without an embedding kept, got %v (%d suppressed)", resultIDs(kept), suppressed)
+	}
+	if kept, suppressed := diverseResults(clusteredResults(2, 3), 10, 1); len(kept) != 6 || suppressed != 0 {
+		t.Errorf("expected a threshold of 1 to keep near-copies, got %v (%d suppressed)", resultIDs(kept), suppressed)
+	}
+}
+
+func resultIDs(results []memory.RetrievalResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Memory.ID
+	}
+	return ids
+}
@@ -0,0 +1,315 @@
+// internal/dialogue/memory_provenance.go
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-llama/internal/goal"
+	"go-llama/internal/memory"
+
+	"gorm.io/gorm"
+)
+
+// Memory metadata keys that tie a memory to the cycle, goal and actions that produced
+// it. Lists are comma-separated because payload conversion keeps scalars only.
+const (
+	MetadataGoalID    = "goal_id"
+	MetadataGoalIDs   = "goal_ids" // For memories summarizing several goals, e.g. digests
+	MetadataCycleID   = "cycle_id"
+	MetadataActionIDs = "action_ids"
+)
+
+// OriginUnknown is reported for memories stored without provenance metadata
+const OriginUnknown = "unknown origin"
+
+// maxProvenanceActions caps the actions listed for one memory
+const maxProvenanceActions = 100
+
+// MemoryProvenance traces a memory back to the cycle, goal and actions that produced it
+type MemoryProvenance struct {
+	MemoryID string             `json:"memory_id"`
+	Known    bool               `json:"known"`
+	Origin   string             `json:"origin"` // research_synthesis, learning, digest, reflection, or OriginUnknown
+	StoredAt time.Time          `json:"stored_at"`
+	CycleID  int                `json:"cycle_id,omitempty"`
+	Goals    []ProvenanceGoal   `json:"goals,omitempty"`
+	Actions  []ProvenanceAction `json:"actions,omitempty"`
+	Missing  []string           `json:"missing,omitempty"` // Referenced goals no longer on record
+}
+
+// ProvenanceGoal is the goal a memory came from
+type ProvenanceGoal struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Outcome     string   `json:"outcome,omitempty"`
+	Questions   []string `json:"questions,omitempty"` // Research plan questions or sub-goals
+	FoundIn     string   `json:"found_in"`            // "state", "archive" or "goal_system"
+}
+
+// ProvenanceAction is a tool action taken for the goal a memory came from
+type ProvenanceAction struct {
+	ActionID   string    `json:"action_id"`
+	CycleID    int       `json:"cycle_id"`
+	Tool       string    `json:"tool"`
+	Input      string    `json:"input"` // Query, URL or description
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int       `json:"duration_ms"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// stampProvenance records the current cycle, the goal and the actions behind a memory.
+// The goal is taken from the action context when goalID is empty.
+func (e *Engine) stampProvenance(ctx context.Context, mem *memory.Memory, goalID string, actionIDs []string) {
+	if mem.Metadata == nil {
+		mem.Metadata = map[string]interface{}{}
+	}
+	if cycle := e.currentCycle.Load(); cycle > 0 {
+		mem.Metadata[MetadataCycleID] = int(cycle)
+	}
+	if goalID == "" {
+		if ac, ok := goal.ActionContextFrom(ctx); ok {
+			goalID = ac.GoalID
+		}
+	}
+	if goalID != "" {
+		mem.Metadata[MetadataGoalID] = goalID
+	}
+	if ids := nonEmpty(actionIDs); len(ids) > 0 {
+		mem.Metadata[MetadataActionIDs] = strings.Join(ids, ",")
+	}
+}
+
+// goalActionIDs returns the IDs of a goal's actions that ran
+func goalActionIDs(goal *Goal) []string {
+	ids := []string{}
+	for _, action := range goal.Actions {
+		if action.ID != "" && action.Status == ActionStatusCompleted {
+			ids = append(ids, action.ID)
+		}
+	}
+	return ids
+}
+
+// ExplainMemory traces a memory back to its originating cycle, goal and actions. A
+// memory stored without provenance metadata is reported with OriginUnknown. Personal
+// memories of other users are reported as not found; an empty viewerID sees every memory.
+func (e *Engine) ExplainMemory(ctx context.Context, memoryID, viewerID string) (*MemoryProvenance, error) {
+	mem, err := e.storage.GetMemoryByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if viewerID != "" && !mem.IsCollective && (mem.UserID == nil || *mem.UserID != viewerID) {
+		return nil, fmt.Errorf("%w: %s", memory.ErrNotFound, memoryID)
+	}
+	return e.traceMemory(ctx, mem)
+}
+
+// traceMemory assembles provenance for a loaded memory
+func (e *Engine) traceMemory(ctx context.Context, mem *memory.Memory) (*MemoryProvenance, error) {
+	prov := &MemoryProvenance{MemoryID: mem.ID, StoredAt: mem.CreatedAt, Origin: memoryOrigin(mem)}
+
+	goalIDs := splitIDs(metadataString(mem.Metadata, MetadataGoalIDs))
+	if id := metadataString(mem.Metadata, MetadataGoalID); id != "" {
+		goalIDs = append([]string{id}, goalIDs...)
+	}
+	actionIDs := splitIDs(metadataString(mem.Metadata, MetadataActionIDs))
+	prov.CycleID = metadataInt(mem.Metadata, MetadataCycleID)
+
+	if len(goalIDs) == 0 && len(actionIDs) == 0 && prov.CycleID == 0 {
+		prov.Origin = OriginUnknown
+		return prov, nil
+	}
+	prov.Known = true
+
+	var state *InternalState
+	if e.stateManager != nil && len(goalIDs) > 0 {
+		loaded, err := e.stateManager.LoadState(ctx)
+		if err != nil {
+			return nil, err
+		}
+		state = loaded
+	}
+	for _, id := range goalIDs {
+		found, err := e.findProvenanceGoal(ctx, state, id)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			prov.Missing = append(prov.Missing, id)
+			continue
+		}
+		prov.Goals = append(prov.Goals, *found)
+	}
+
+	if e.stateManager != nil && (len(actionIDs) > 0 || len(goalIDs) == 1) {
+		goalID := ""
+		if len(goalIDs) == 1 {
+			goalID = goalIDs[0]
+		}
+		actions, err := e.stateManager.provenanceActions(ctx, goalID, actionIDs)
+		if err != nil {
+			return nil, err
+		}
+		prov.Actions = actions
+	}
+	return prov, nil
+}
+
+// memoryOrigin names the kind of store site a memory came from
+func memoryOrigin(mem *memory.Memory) string {
+	if metadataString(mem.Metadata, "research_type") == "synthesis" {
+		return "research_synthesis"
+	}
+	if metadataString(mem.Metadata, "type") == "reflection" {
+		return "reflection"
+	}
+	for _, tag := range mem.ConceptTags {
+		if tag == DigestTag {
+			return "digest"
+		}
+	}
+	for _, tag := range mem.ConceptTags {
+		if tag == "learning" {
+			return "learning"
+		}
+	}
+	return OriginUnknown
+}
+
+// findProvenanceGoal looks a goal up in the dialogue state, then the goal archive, then
+// the goal system. It returns nil when the goal is on record nowhere.
+func (e *Engine) findProvenanceGoal(ctx context.Context, state *InternalState, goalID string) (*ProvenanceGoal, error) {
+	if state != nil {
+		for _, goals := range [][]Goal{state.ActiveGoals, state.CompletedGoals} {
+			for i := range goals {
+				if goals[i].ID == goalID {
+					return provenanceGoal(goals[i], "state"), nil
+				}
+			}
+		}
+	}
+
+	if e.stateManager != nil {
+		var archived GoalArchive
+		err := e.stateManager.db.WithContext(ctx).Where("goal_id = ?", goalID).First(&archived).Error
+		if err == nil {
+			return provenanceGoal(archived.goal(), "archive"), nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load archived goal: %w", err)
+		}
+	}
+
+	if e.goalOrchestrator != nil {
+		if g, err := e.goalOrchestrator.GetGoalDetails(ctx, goalID); err == nil && g != nil {
+			prov := &ProvenanceGoal{
+				ID:          g.ID,
+				Description: g.Description,
+				Status:      string(g.State),
+				Outcome:     string(g.ArchiveReason),
+				FoundIn:     "goal_system",
+			}
+			if prov.Description == "" {
+				prov.Description = g.Title
+			}
+			for _, sg := range g.SubGoals {
+				prov.Questions = append(prov.Questions, sg.Title)
+			}
+			return prov, nil
+		}
+	}
+	return nil, nil
+}
+
+// provenanceGoal summarizes a dialogue goal with its research plan questions
+func provenanceGoal(goal Goal, foundIn string) *ProvenanceGoal {
+	prov := &ProvenanceGoal{
+		ID:          goal.ID,
+		Description: goal.Description,
+		Status:      goal.Status,
+		Outcome:     string(goal.Outcome),
+		FoundIn:     foundIn,
+	}
+	if goal.ResearchPlan != nil {
+		for _, q := range goal.ResearchPlan.SubQuestions {
+			prov.Questions = append(prov.Questions, q.Question)
+		}
+	}
+	return prov
+}
+
+// provenanceActions loads recorded actions in time order. Sub-goal action IDs are only
+// unique within their goal, so a goal narrows the lookup when there is one.
+func (sm *StateManager) provenanceActions(ctx context.Context, goalID string, actionIDs []string) ([]ProvenanceAction, error) {
+	query := sm.db.WithContext(ctx).Order(`"timestamp" ASC`).Limit(maxProvenanceActions)
+	if goalID != "" {
+		query = query.Where("goal_id = ?", goalID)
+	}
+	if len(actionIDs) > 0 {
+		query = query.Where("action_id IN ?", actionIDs)
+	}
+
+	var rows []DialogueAction
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load actions: %w", err)
+	}
+	actions := make([]ProvenanceAction, len(rows))
+	for i, row := range rows {
+		actions[i] = ProvenanceAction{
+			ActionID:   row.ActionID,
+			CycleID:    row.CycleID,
+			Tool:       row.Tool,
+			Input:      row.Input,
+			Success:    row.Success,
+			Error:      row.Error,
+			DurationMs: row.DurationMs,
+			Timestamp:  row.Timestamp,
+		}
+	}
+	return actions, nil
+}
+
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
+
+// metadataInt reads an integer that may come back from the payload as int or float64
+func metadataInt(metadata map[string]interface{}, key string) int {
+	switch value := metadata[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+func splitIDs(joined string) []string {
+	if joined == "" {
+		return nil
+	}
+	return nonEmpty(strings.Split(joined, ","))
+}
+
+// nonEmpty returns the trimmed, non-empty, distinct IDs in their original order
+func nonEmpty(ids []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
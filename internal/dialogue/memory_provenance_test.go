@@ -0,0 +1,111 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupProvenanceDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&GoalArchive{}, &ActionResult{}, &StateVersion{}, &GoalRecord{}, &GoalActionRecord{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	// The state and action tables default to NOW(), which sqlite rejects
+	for _, ddl := range []string{
+		`CREATE TABLE growerai_dialogue_state (id integer PRIMARY KEY, active_goals text NOT NULL DEFAULT '[]',
+			completed_goals text NOT NULL DEFAULT '[]', knowledge_gaps text NOT NULL DEFAULT '[]',
+			recent_failures text NOT NULL DEFAULT '[]', patterns text NOT NULL DEFAULT '[]',
+			focus_areas text NOT NULL DEFAULT '[]',
+			last_cycle_time datetime, cycle_count integer NOT NULL DEFAULT 0,
+			migration_memory_id_complete boolean NOT NULL DEFAULT false,
+			migration_is_collective_complete boolean NOT NULL DEFAULT false,
+			migration_source_kind_complete boolean NOT NULL DEFAULT false,
+			adaptive_state text, cycle_owner text, schema_version integer NOT NULL DEFAULT 0,
+			state_version integer NOT NULL DEFAULT 0, state_checksum text,
+			created_at datetime, updated_at datetime)`,
+		`CREATE TABLE growerai_dialogue_actions (id integer PRIMARY KEY AUTOINCREMENT, cycle_id integer NOT NULL,
+			goal_id text, action_id text, tool text NOT NULL, input text NOT NULL DEFAULT '',
+			output text NOT NULL DEFAULT '', success boolean NOT NULL DEFAULT false, error text,
+			duration_ms integer NOT NULL DEFAULT 0, "timestamp" datetime NOT NULL)`,
+	} {
+		if err := db.Exec(ddl).Error; err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+	}
+	return db
+}
+
+func TestTraceMemoryFollowsGoalAndActions(t *testing.T) {
+	ctx := context.Background()
+	db := setupProvenanceDB(t)
+	e := &Engine{stateManager: NewStateManager(db)}
+	e.currentCycle.Store(42)
+
+	goal := Goal{
+		ID:          "goal_7",
+		Description: "Research vector databases",
+		Status:      GoalStatusCompleted,
+		Outcome:     GoalOutcomeSuccess,
+		ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{
+			{ID: "q1", Question: "Which databases exist?"},
+		}},
+		Actions: []Action{
+			{ID: "action_1", Tool: ActionToolSearch, Status: ActionStatusCompleted},
+			{ID: "action_2", Tool: ActionToolWebParseUnified, Status: ActionStatusPending},
+		},
+	}
+	if err := archiveGoals(db, []Goal{goal}); err != nil {
+		t.Fatalf("failed to archive goal: %v", err)
+	}
+	for _, action := range []ActionRecord{
+		{CycleID: 42, GoalID: "goal_7", ActionID: "action_1", Tool: ActionToolSearch, Input: "vector databases", Duration: time.Second, Timestamp: time.Now()},
+		{CycleID: 42, GoalID: "goal_8", ActionID: "action_9", Tool: ActionToolSearch, Input: "unrelated", Timestamp: time.Now()},
+	} {
+		if err := e.stateManager.SaveAction(ctx, &action); err != nil {
+			t.Fatalf("failed to save action: %v", err)
+		}
+	}
+
+	mem := &memory.Memory{ID: "mem_1", Metadata: map[string]interface{}{"research_type": "synthesis"}}
+	e.stampProvenance(ctx, mem, goal.ID, goalActionIDs(&goal))
+	if mem.Metadata[MetadataActionIDs] != "action_1" || mem.Metadata[MetadataCycleID] != 42 {
+		t.Fatalf("unexpected provenance metadata %+v", mem.Metadata)
+	}
+
+	prov, err := e.traceMemory(ctx, mem)
+	if err != nil {
+		t.Fatalf("traceMemory failed: %v", err)
+	}
+	if !prov.Known || prov.Origin != "research_synthesis" || prov.CycleID != 42 {
+		t.Errorf("unexpected provenance %+v", prov)
+	}
+	if len(prov.Goals) != 1 || prov.Goals[0].FoundIn != "archive" || prov.Goals[0].Outcome != "success" ||
+		len(prov.Goals[0].Questions) != 1 {
+		t.Errorf("expected the archived goal with its questions, got %+v", prov.Goals)
+	}
+	if len(prov.Actions) != 1 || prov.Actions[0].Input != "vector databases" || prov.Actions[0].DurationMs != 1000 {
+		t.Errorf("expected only the goal's recorded action, got %+v", prov.Actions)
+	}
+}
+
+func TestTraceMemoryWithoutMetadataIsUnknownOrigin(t *testing.T) {
+	e := &Engine{stateManager: NewStateManager(setupProvenanceDB(t))}
+
+	prov, err := e.traceMemory(context.Background(), &memory.Memory{ID: "mem_2", ConceptTags: []string{"learning"}})
+	if err != nil {
+		t.Fatalf("traceMemory failed: %v", err)
+	}
+	if prov.Known || prov.Origin != OriginUnknown || len(prov.Goals) != 0 {
+		t.Errorf("expected an unknown origin, got %+v", prov)
+	}
+}
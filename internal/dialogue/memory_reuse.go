@@ -0,0 +1,115 @@
+// internal/dialogue/memory_reuse.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-llama/internal/memory"
+)
+
+// MemoryReuseConfig controls answering a parse action from a research synthesis that
+// already drew on the same page instead of fetching it again
+type MemoryReuseConfig struct {
+	Enabled       bool
+	MinSimilarity float64       // Similarity of the synthesis to the action's question
+	MaxAge        time.Duration // Older syntheses are ignored
+}
+
+// Validate rejects settings that would reuse unrelated or arbitrarily old memories
+func (c MemoryReuseConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.MinSimilarity <= 0 || c.MinSimilarity > 1 {
+		return fmt.Errorf("memory_reuse.min_similarity must be in (0, 1], got %.2f", c.MinSimilarity)
+	}
+	if c.MaxAge <= 0 {
+		return fmt.Errorf("memory_reuse.max_age_days must be positive, got %s", c.MaxAge)
+	}
+	return nil
+}
+
+// MetadataReusedMemory marks an action whose result came from memory rather than a fetch
+const MetadataReusedMemory = "reused_memory"
+
+// SetMemoryReuse configures reuse of research syntheses for parse actions
+func (e *Engine) SetMemoryReuse(cfg MemoryReuseConfig) {
+	e.memoryReuse = cfg
+}
+
+// reusePage returns a fresh research synthesis that drew on url and is similar enough to
+// question, but only once the parse evaluation judges it sufficient for the question.
+// Anything else, including stale or off-topic syntheses, leaves the page to be fetched.
+func (e *Engine) reusePage(ctx context.Context, url, question string) (*memory.RetrievalResult, bool) {
+	cfg := e.memoryReuse
+	if !cfg.Enabled || e.storage == nil || e.embedder == nil || question == "" {
+		return nil, false
+	}
+	page := memory.SourcePageKey(url)
+	if page == "" {
+		return nil, false
+	}
+
+	embedding, err := e.embedder.Embed(ctx, question)
+	if err != nil {
+		log.Printf("[MemoryReuse] WARNING: Failed to embed question, fetching %s: %v", truncate(url, 60), err)
+		return nil, false
+	}
+	results, err := e.storage.Search(ctx, memory.RetrievalQuery{
+		Query:             question,
+		IncludeCollective: true,
+		SourceKinds:       []string{memory.SourceResearchSynthesis},
+		SourcePages:       []string{page},
+		CreatedAfter:      time.Now().Add(-cfg.MaxAge),
+		MinScore:          cfg.MinSimilarity,
+		Limit:             1,
+	}, embedding)
+	if err != nil {
+		log.Printf("[MemoryReuse] WARNING: Memory search failed, fetching %s: %v", truncate(url, 60), err)
+		return nil, false
+	}
+	if len(results) == 0 {
+		return nil, false
+	}
+	found := results[0]
+
+	evaluation, err := e.evaluateParseResults(ctx, found.Memory.Content, question, url, nil, nil)
+	if err != nil || evaluation.Quality != "sufficient" {
+		quality := "unknown"
+		if evaluation != nil {
+			quality = evaluation.Quality
+		}
+		log.Printf("[MemoryReuse] Memory %s on %s judged %s, fetching the page", found.Memory.ID, page, quality)
+		return nil, false
+	}
+
+	e.memoryReuseHits.Add(1)
+	log.Printf("[MemoryReuse] ✓ Reusing memory %s (similarity %.2f, %s old) instead of parsing %s",
+		found.Memory.ID, found.Score, time.Since(found.Memory.CreatedAt).Round(time.Hour), truncate(url, 60))
+	return &found, true
+}
+
+// markReusedMemory records on the action that its result is a reused memory, keeping the
+// page as its source so citations still point at it
+func markReusedMemory(action *Action, url string, reused *memory.RetrievalResult) {
+	if action.Metadata == nil {
+		action.Metadata = make(map[string]interface{})
+	}
+	action.Metadata[MetadataReusedMemory] = true
+	action.Metadata["reused_memory_id"] = reused.Memory.ID
+	action.Metadata["reused_memory_score"] = reused.Score
+	action.Metadata[sourceMetaKey("url")] = url
+}
+
+// parseQuestion is what a parse action is trying to answer: its goal, else its query
+func parseQuestion(params map[string]interface{}) string {
+	for _, key := range []string{"goal", "query"} {
+		if q, ok := params[key].(string); ok && q != "" {
+			return q
+		}
+	}
+	return ""
+}
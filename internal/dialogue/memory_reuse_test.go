@@ -0,0 +1,65 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+)
+
+func TestMemoryReuseConfigValidate(t *testing.T) {
+	if err := (MemoryReuseConfig{}).Validate(); err != nil {
+		t.Errorf("expected disabled reuse to need no settings: %v", err)
+	}
+	for name, cfg := range map[string]MemoryReuseConfig{
+		"no similarity":  {Enabled: true, MaxAge: time.Hour},
+		"similarity >1":  {Enabled: true, MinSimilarity: 1.2, MaxAge: time.Hour},
+		"no maximum age": {Enabled: true, MinSimilarity: 0.85},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	s := testSettings()
+	s.MemoryReuse = MemoryReuseConfig{Enabled: true, MinSimilarity: 0.85}
+	if err := (&Engine{}).CheckSettings(s); err == nil {
+		t.Error("expected CheckSettings to reject invalid memory reuse")
+	}
+}
+
+func TestReusePageNeedsConfigAndPage(t *testing.T) {
+	e := &Engine{}
+	if _, ok := e.reusePage(context.Background(), "https://example.com/a", "what is a?"); ok {
+		t.Error("expected no reuse while disabled")
+	}
+	e.SetMemoryReuse(MemoryReuseConfig{Enabled: true, MinSimilarity: 0.85, MaxAge: time.Hour})
+	if _, ok := e.reusePage(context.Background(), "not a url", "what is a?"); ok {
+		t.Error("expected no reuse without storage or a page")
+	}
+	if e.memoryReuseHits.Load() != 0 {
+		t.Error("expected no reuse hits counted")
+	}
+}
+
+func TestMarkReusedMemoryKeepsPageAsSource(t *testing.T) {
+	action := &Action{Tool: ActionToolWebParseUnified}
+	markReusedMemory(action, "https://example.com/a", &memory.RetrievalResult{
+		Memory: memory.Memory{ID: "mem_1"},
+		Score:  0.91,
+	})
+	if action.Metadata[MetadataReusedMemory] != true || action.Metadata["reused_memory_id"] != "mem_1" {
+		t.Errorf("expected the reuse flagged, got %v", action.Metadata)
+	}
+
+	q := &ResearchQuestion{}
+	recordQuestionSource(q, action)
+	if len(q.SourcesFound) != 1 || q.SourcesFound[0] != "https://example.com/a" {
+		t.Errorf("expected the reused page cited as a source, got %v", q.SourcesFound)
+	}
+
+	if got := parseQuestion(map[string]interface{}{"query": "q", "goal": "g"}); got != "g" {
+		t.Errorf("expected the goal preferred over the query, got %q", got)
+	}
+}
@@ -0,0 +1,383 @@
+// internal/dialogue/model_router.go
+package dialogue
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LLMCallType identifies what a dialogue LLM call is for, so it can be routed to a model
+type LLMCallType string
+
+const (
+	CallReflection      LLMCallType = "reflection"       // Brief reflections, goal thoughts and digests
+	CallDeepReflection  LLMCallType = "deep_reflection"  // Structured reflection that proposes goals and principles
+	CallPlanGeneration  LLMCallType = "plan_generation"  // Research plans and replanning
+	CallEvaluation      LLMCallType = "evaluation"       // Search/parse evaluation, progress and principle assessment
+	CallSynthesis       LLMCallType = "synthesis"        // Research synthesis
+	CallSynthesisReview LLMCallType = "synthesis_review" // Quality review of a research synthesis before it is stored
+	CallValidation      LLMCallType = "validation"       // Goal support and principle validation
+	CallPostMortem      LLMCallType = "post_mortem"      // Analysis of why an abandoned goal failed
+)
+
+// Model tiers a call type can be routed to
+const (
+	ModelTierSimple    = "simple"
+	ModelTierReasoning = "reasoning"
+)
+
+// defaultModelPolicy reproduces the routing the engine used before it was configurable
+var defaultModelPolicy = map[LLMCallType]string{
+	CallReflection:      ModelTierSimple,
+	CallDeepReflection:  ModelTierReasoning,
+	CallPlanGeneration:  ModelTierReasoning,
+	CallEvaluation:      ModelTierReasoning,
+	CallSynthesis:       ModelTierReasoning,
+	CallSynthesisReview: ModelTierSimple,
+	CallValidation:      ModelTierReasoning,
+	CallPostMortem:      ModelTierSimple,
+}
+
+// defaultCallMaxTokens caps the completions of call types that only need a short
+// answer, unless growerai.sampling sets max_tokens for them
+var defaultCallMaxTokens = map[LLMCallType]int{
+	CallPostMortem: 400,
+}
+
+// ModelRoute is the model chosen for one call
+type ModelRoute struct {
+	CallType    LLMCallType
+	Tier        string // Tier that actually serves the call
+	URL         string
+	Model       string
+	Sampling    SamplingParams // Configured overrides for the call type
+	ContextSize int            // Context window of the serving model in tokens; 0 if unknown
+}
+
+// SamplingParams overrides the sampling parameters of a call type. Temperature is a
+// pointer because 0 is valid; the other fields are unset at zero.
+type SamplingParams struct {
+	Temperature   *float64
+	TopP          float64
+	MaxTokens     int
+	RepeatPenalty float64
+}
+
+// Sampling is the effective set of parameters sent with one call
+type Sampling struct {
+	Temperature   float64
+	TopP          float64 // 0 leaves the server default
+	MaxTokens     int
+	RepeatPenalty float64 // 0 leaves the server default
+}
+
+// resolve fills the parameters the overrides leave unset with the call site's defaults
+func (p SamplingParams) resolve(temperature float64, maxTokens int) Sampling {
+	s := Sampling{Temperature: temperature, TopP: p.TopP, MaxTokens: maxTokens, RepeatPenalty: p.RepeatPenalty}
+	if p.Temperature != nil {
+		s.Temperature = *p.Temperature
+	}
+	if p.MaxTokens > 0 {
+		s.MaxTokens = p.MaxTokens
+	}
+	return s
+}
+
+// apply writes the parameters into a chat completion request
+func (s Sampling) apply(reqBody map[string]interface{}) {
+	reqBody["temperature"] = s.Temperature
+	reqBody["max_tokens"] = s.MaxTokens
+	if s.TopP > 0 {
+		reqBody["top_p"] = s.TopP
+	}
+	if s.RepeatPenalty > 0 {
+		reqBody["repeat_penalty"] = s.RepeatPenalty
+	}
+}
+
+// String renders the parameters for call logs
+func (s Sampling) String() string {
+	out := fmt.Sprintf("temperature=%.2f max_tokens=%d", s.Temperature, s.MaxTokens)
+	if s.TopP > 0 {
+		out += fmt.Sprintf(" top_p=%.2f", s.TopP)
+	}
+	if s.RepeatPenalty > 0 {
+		out += fmt.Sprintf(" repeat_penalty=%.2f", s.RepeatPenalty)
+	}
+	return out
+}
+
+// ModelCallCounts counts calls served by each tier and the completions that came back
+// empty or were cut short
+type ModelCallCounts struct {
+	Simple     int64 `json:"simple"`
+	Reasoning  int64 `json:"reasoning"`
+	Empty      int64 `json:"empty"`
+	Truncated  int64 `json:"truncated"`         // Streamed completions stopped at max_tokens, on repetition or at the deadline
+	Downgraded int64 `json:"downgraded"`        // Reasoning calls sent to the simple model over the soft budget
+	Overflows  int64 `json:"context_overflows"` // Calls trimmed to fit the context window or rejected as too long
+}
+
+// ModelRouterStats reports the routing policy and how many calls each tier served
+type ModelRouterStats struct {
+	Policy     map[string]string          `json:"policy"`
+	Total      ModelCallCounts            `json:"total"`
+	ByCallType map[string]ModelCallCounts `json:"by_call_type"`
+}
+
+// ModelRouter picks the simple or reasoning model for each call type from a policy table
+type ModelRouter struct {
+	reasoningURL   string
+	reasoningModel string
+	simpleURL      string
+	simpleModel    string
+
+	mu               sync.Mutex
+	budget           TokenBudget // Optional; over its soft limit reasoning calls use the simple model
+	reasoningContext int         // Context windows in tokens; 0 if unknown
+	simpleContext    int
+	policy           map[LLMCallType]string
+	sampling         map[LLMCallType]SamplingParams
+	calls            map[LLMCallType]*ModelCallCounts
+}
+
+// NewModelRouter creates a router using the default policy
+func NewModelRouter(reasoningURL, reasoningModel, simpleURL, simpleModel string) *ModelRouter {
+	policy := make(map[LLMCallType]string, len(defaultModelPolicy))
+	for callType, tier := range defaultModelPolicy {
+		policy[callType] = tier
+	}
+	return &ModelRouter{
+		reasoningURL:   reasoningURL,
+		reasoningModel: reasoningModel,
+		simpleURL:      simpleURL,
+		simpleModel:    simpleModel,
+		policy:         policy,
+		sampling:       make(map[LLMCallType]SamplingParams),
+		calls:          make(map[LLMCallType]*ModelCallCounts),
+	}
+}
+
+// SetPolicy overrides the tier for the given call types; omitted call types keep their
+// current tier. The whole update is rejected if any entry is invalid.
+func (r *ModelRouter) SetPolicy(overrides map[string]string) error {
+	updates, err := parseModelPolicy(overrides)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for callType, tier := range updates {
+		r.policy[callType] = tier
+	}
+	return nil
+}
+
+// ResetPolicy replaces the policy with the defaults plus the given overrides, so call
+// types dropped from a reloaded config return to their default tier
+func (r *ModelRouter) ResetPolicy(overrides map[string]string) error {
+	updates, err := parseModelPolicy(overrides)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for callType, tier := range defaultModelPolicy {
+		r.policy[callType] = tier
+	}
+	for callType, tier := range updates {
+		r.policy[callType] = tier
+	}
+	return nil
+}
+
+// parseModelPolicy validates a "call_type" -> "tier" table
+func parseModelPolicy(overrides map[string]string) (map[LLMCallType]string, error) {
+	updates := make(map[LLMCallType]string, len(overrides))
+	for name, tier := range overrides {
+		callType := LLMCallType(strings.ToLower(strings.TrimSpace(name)))
+		if _, ok := defaultModelPolicy[callType]; !ok {
+			return nil, fmt.Errorf("unknown call type %q", name)
+		}
+		tier = strings.ToLower(strings.TrimSpace(tier))
+		if tier != ModelTierSimple && tier != ModelTierReasoning {
+			return nil, fmt.Errorf("call type %q: tier must be %q or %q, got %q", name, ModelTierSimple, ModelTierReasoning, tier)
+		}
+		updates[callType] = tier
+	}
+	return updates, nil
+}
+
+// SetSampling replaces the sampling overrides; call types without an entry use their
+// call site's defaults. The whole update is rejected if any call type is unknown.
+func (r *ModelRouter) SetSampling(overrides map[string]SamplingParams) error {
+	sampling, err := parseSampling(overrides)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sampling = sampling
+	return nil
+}
+
+// parseSampling validates the call types of a "call_type" -> parameters table
+func parseSampling(overrides map[string]SamplingParams) (map[LLMCallType]SamplingParams, error) {
+	sampling := make(map[LLMCallType]SamplingParams, len(overrides))
+	for name, params := range overrides {
+		callType := LLMCallType(strings.ToLower(strings.TrimSpace(name)))
+		if _, ok := defaultModelPolicy[callType]; !ok {
+			return nil, fmt.Errorf("unknown call type %q", name)
+		}
+		sampling[callType] = params
+	}
+	return sampling, nil
+}
+
+// Route returns the model that should serve a call and counts it. A simple-tier call
+// falls back to the reasoning model when no simple model is configured; over the soft
+// token budget a reasoning-tier call is downgraded to the simple model when there is one.
+func (r *ModelRouter) Route(callType LLMCallType) ModelRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tier, ok := r.policy[callType]
+	if !ok {
+		tier = ModelTierReasoning
+	}
+	downgraded := false
+	if tier == ModelTierReasoning && r.simpleURL != "" && r.budget != nil && r.budget.OverSoftLimit() {
+		tier = ModelTierSimple
+		downgraded = true
+	}
+	route := ModelRoute{CallType: callType, Tier: ModelTierReasoning, URL: r.reasoningURL, Model: r.reasoningModel, ContextSize: r.reasoningContext}
+	if tier == ModelTierSimple {
+		if r.simpleURL != "" {
+			route = ModelRoute{CallType: callType, Tier: ModelTierSimple, URL: r.simpleURL, Model: r.simpleModel, ContextSize: r.simpleContext}
+		} else {
+			log.Printf("[Dialogue] Simple Model requested for %s but not configured, using Reasoning Model", callType)
+		}
+	}
+	route.Sampling = r.sampling[callType]
+	if route.Sampling.MaxTokens == 0 {
+		route.Sampling.MaxTokens = defaultCallMaxTokens[callType]
+	}
+
+	counts, ok := r.calls[callType]
+	if !ok {
+		counts = &ModelCallCounts{}
+		r.calls[callType] = counts
+	}
+	if route.Tier == ModelTierSimple {
+		counts.Simple++
+	} else {
+		counts.Reasoning++
+	}
+	if downgraded {
+		counts.Downgraded++
+	}
+	return route
+}
+
+// SetBudget downgrades reasoning calls to the simple model while budget is over its
+// soft limit
+func (r *ModelRouter) SetBudget(budget TokenBudget) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.budget = budget
+}
+
+// SetContextSizes records each model's context window in tokens, so requests can be
+// fitted to the model that serves them. 0 leaves a size unknown.
+func (r *ModelRouter) SetContextSizes(reasoning, simple int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reasoningContext = reasoning
+	r.simpleContext = simple
+}
+
+// RecordEmpty counts a call of the given type that returned an empty completion
+func (r *ModelRouter) RecordEmpty(callType LLMCallType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts, ok := r.calls[callType]
+	if !ok {
+		counts = &ModelCallCounts{}
+		r.calls[callType] = counts
+	}
+	counts.Empty++
+}
+
+// RecordTruncated counts a call of the given type whose streamed completion was cut short
+func (r *ModelRouter) RecordTruncated(callType LLMCallType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts, ok := r.calls[callType]
+	if !ok {
+		counts = &ModelCallCounts{}
+		r.calls[callType] = counts
+	}
+	counts.Truncated++
+}
+
+// RecordContextOverflow counts a call of the given type that did not fit the context
+// window as built
+func (r *ModelRouter) RecordContextOverflow(callType LLMCallType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts, ok := r.calls[callType]
+	if !ok {
+		counts = &ModelCallCounts{}
+		r.calls[callType] = counts
+	}
+	counts.Overflows++
+}
+
+// Stats returns the current policy and call counts
+func (r *ModelRouter) Stats() ModelRouterStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := ModelRouterStats{
+		Policy:     make(map[string]string, len(r.policy)),
+		ByCallType: make(map[string]ModelCallCounts, len(r.calls)),
+	}
+	for callType, tier := range r.policy {
+		stats.Policy[string(callType)] = tier
+	}
+	for callType, counts := range r.calls {
+		stats.ByCallType[string(callType)] = *counts
+		stats.Total.Simple += counts.Simple
+		stats.Total.Reasoning += counts.Reasoning
+		stats.Total.Empty += counts.Empty
+		stats.Total.Truncated += counts.Truncated
+		stats.Total.Downgraded += counts.Downgraded
+		stats.Total.Overflows += counts.Overflows
+	}
+	return stats
+}
+
+// String renders the policy as "call_type=tier" pairs in key order
+func (r *ModelRouter) String() string {
+	policy := r.Stats().Policy
+	keys := make([]string, 0, len(policy))
+	for k := range policy {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+policy[k])
+	}
+	return strings.Join(parts, ", ")
+}
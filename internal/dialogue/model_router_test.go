@@ -0,0 +1,91 @@
+package dialogue
+
+import "testing"
+
+func TestModelRouterDefaultsMatchLegacyRouting(t *testing.T) {
+	r := NewModelRouter("http://reasoning", "8b", "http://simple", "1b")
+
+	if route := r.Route(CallReflection); route.Tier != ModelTierSimple || route.URL != "http://simple" || route.Model != "1b" {
+		t.Errorf("expected reflection on the simple model, got %+v", route)
+	}
+	for _, callType := range []LLMCallType{CallDeepReflection, CallPlanGeneration, CallEvaluation, CallSynthesis, CallValidation} {
+		if route := r.Route(callType); route.Tier != ModelTierReasoning || route.URL != "http://reasoning" {
+			t.Errorf("expected %s on the reasoning model, got %+v", callType, route)
+		}
+	}
+}
+
+func TestModelRouterPolicyOverrides(t *testing.T) {
+	r := NewModelRouter("http://reasoning", "8b", "http://simple", "1b")
+
+	if err := r.SetPolicy(map[string]string{"evaluation": "Simple", "reflection": "banana"}); err == nil {
+		t.Fatal("expected invalid tier to be rejected")
+	}
+	if route := r.Route(CallEvaluation); route.Tier != ModelTierReasoning {
+		t.Errorf("expected a rejected update to change nothing, got %+v", route)
+	}
+	if err := r.SetPolicy(map[string]string{"summarise": "simple"}); err == nil {
+		t.Error("expected unknown call type to be rejected")
+	}
+
+	if err := r.SetPolicy(map[string]string{"evaluation": "Simple"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.Route(CallEvaluation)
+	r.Route(CallSynthesis)
+
+	stats := r.Stats()
+	if stats.Policy["evaluation"] != ModelTierSimple || stats.Policy["validation"] != ModelTierReasoning {
+		t.Errorf("unexpected policy %v", stats.Policy)
+	}
+	if got := stats.ByCallType["evaluation"]; got.Simple != 1 || got.Reasoning != 1 {
+		t.Errorf("expected one call on each tier for evaluation, got %+v", got)
+	}
+	if stats.Total.Simple != 1 || stats.Total.Reasoning != 2 {
+		t.Errorf("unexpected totals %+v", stats.Total)
+	}
+}
+
+func TestModelRouterFallsBackWithoutSimpleModel(t *testing.T) {
+	r := NewModelRouter("http://reasoning", "8b", "", "")
+
+	route := r.Route(CallReflection)
+	if route.Tier != ModelTierReasoning || route.Model != "8b" {
+		t.Errorf("expected fallback to the reasoning model, got %+v", route)
+	}
+	if got := r.Stats().ByCallType["reflection"]; got.Reasoning != 1 || got.Simple != 0 {
+		t.Errorf("expected the fallback to be counted against the serving model, got %+v", got)
+	}
+}
+
+func TestModelRouterSamplingDefaultsToCallSite(t *testing.T) {
+	r := NewModelRouter("http://reasoning", "8b", "http://simple", "1b")
+
+	if got := r.Route(CallEvaluation).Sampling.resolve(0.7, 4096); got != (Sampling{Temperature: 0.7, MaxTokens: 4096}) {
+		t.Errorf("expected the call site defaults without overrides, got %+v", got)
+	}
+
+	zero := 0.0
+	if err := r.SetSampling(map[string]SamplingParams{"plan": {Temperature: &zero}}); err == nil {
+		t.Error("expected unknown call type to be rejected")
+	}
+	if err := r.SetSampling(map[string]SamplingParams{"evaluation": {Temperature: &zero, TopP: 0.9, MaxTokens: 512}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sampling := r.Route(CallEvaluation).Sampling.resolve(0.7, 4096)
+	if sampling != (Sampling{Temperature: 0, TopP: 0.9, MaxTokens: 512}) {
+		t.Errorf("expected the overrides applied, got %+v", sampling)
+	}
+
+	reqBody := map[string]interface{}{}
+	sampling.apply(reqBody)
+	if reqBody["temperature"] != 0.0 || reqBody["top_p"] != 0.9 || reqBody["max_tokens"] != 512 {
+		t.Errorf("unexpected request parameters %v", reqBody)
+	}
+	if _, ok := reqBody["repeat_penalty"]; ok {
+		t.Error("expected an unset repeat penalty to be left to the server")
+	}
+	if got := r.Route(CallSynthesis).Sampling.resolve(0.3, 4096); got.Temperature != 0.3 {
+		t.Errorf("expected other call types unaffected, got %+v", got)
+	}
+}
@@ -0,0 +1,92 @@
+// internal/dialogue/novelty.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Novelty check defaults and limits
+const (
+	DefaultNoveltyThreshold = 0.85 // Keyword overlap at which a thought counts as a repeat
+	maxNoveltyCandidates    = 200  // Recent thoughts compared against, newest first
+	maxRepetitionHintChars  = 200
+)
+
+// SetNoveltyThreshold sets the keyword overlap (0-1) at which a new thought repeats one
+// from the last noveltyWindowHours. A threshold <= 0 uses DefaultNoveltyThreshold.
+func (e *Engine) SetNoveltyThreshold(threshold float64) {
+	if threshold <= 0 {
+		threshold = DefaultNoveltyThreshold
+	}
+	e.noveltyThreshold = threshold
+}
+
+// thoughtSimilarity compares two thoughts by the overlap of their significant words, so
+// reordering and filler words do not make a repeat look new
+func thoughtSimilarity(a, b string) float64 {
+	return calculateKeywordOverlap(extractSignificantKeywords(a), extractSignificantKeywords(b))
+}
+
+// RecentThoughts returns up to limit thoughts recorded since the given time, newest
+// first. Stamps of structured calls are left out.
+func (sm *StateManager) RecentThoughts(ctx context.Context, since time.Time, limit int) ([]DialogueThought, error) {
+	var thoughts []DialogueThought
+	err := sm.db.WithContext(ctx).
+		Where("timestamp >= ? AND (prompt_template = '' OR prompt_template IS NULL)", since).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&thoughts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recent thoughts: %w", err)
+	}
+	return thoughts, nil
+}
+
+// repeatedThought returns the thought from the novelty window that content repeats, if
+// any. The check is skipped, and content treated as new, when the window is off or the
+// history cannot be read.
+func (e *Engine) repeatedThought(ctx context.Context, content string) (*DialogueThought, float64, bool) {
+	if e.noveltyWindowHours <= 0 || e.stateManager == nil {
+		return nil, 0, false
+	}
+	threshold := e.noveltyThreshold
+	if threshold <= 0 {
+		threshold = DefaultNoveltyThreshold
+	}
+
+	since := time.Now().Add(-time.Duration(e.noveltyWindowHours) * time.Hour)
+	recent, err := e.stateManager.RecentThoughts(ctx, since, maxNoveltyCandidates)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: Novelty check skipped: %v", err)
+		return nil, 0, false
+	}
+	for i := range recent {
+		if similarity := thoughtSimilarity(content, recent[i].Content); similarity >= threshold {
+			return &recent[i], similarity, true
+		}
+	}
+	return nil, 0, false
+}
+
+// noteRepetition remembers a repeated thought or learning so the next reflection is told
+// it is going over old ground
+func (e *Engine) noteRepetition(kind, text string) {
+	e.repetitionMu.Lock()
+	defer e.repetitionMu.Unlock()
+	e.repetitionHint = fmt.Sprintf("%s: %q", kind, truncate(text, maxRepetitionHintChars))
+}
+
+// takeRepetitionHint returns the reflection prompt's note on the last repetition, once
+func (e *Engine) takeRepetitionHint() string {
+	e.repetitionMu.Lock()
+	defer e.repetitionMu.Unlock()
+	if e.repetitionHint == "" {
+		return ""
+	}
+	hint := fmt.Sprintf("\nNOTE: You are repeating yourself. This %s\nalmost restates what you already concluded recently. Do not restate it; look for something new or take a different angle.\n", e.repetitionHint)
+	e.repetitionHint = ""
+	return hint
+}
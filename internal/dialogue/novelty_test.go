@@ -0,0 +1,112 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	reflectionA = "I keep failing to find primary sources on bee navigation; I should search academic journals next."
+	// Same conclusion, reordered around the same key words
+	reflectionParaphrase = "Next I should search academic journals, because I keep failing to find primary sources on bee navigation."
+	reflectionNew        = "The generics tutorial covered type constraints well, so the remaining gap is type inference."
+)
+
+func noveltyEngine(t *testing.T) *Engine {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The thoughts table defaults to NOW(), which sqlite rejects
+	if err := db.Exec(`CREATE TABLE growerai_dialogue_thoughts (id integer PRIMARY KEY AUTOINCREMENT,
+		cycle_id integer NOT NULL, thought_num integer NOT NULL DEFAULT 0, goal_id text, content text NOT NULL,
+		tokens_used integer NOT NULL DEFAULT 0, action_taken boolean NOT NULL DEFAULT false,
+		prompt_template text, "timestamp" datetime NOT NULL)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &Engine{db: db, stateManager: NewStateManager(db), noveltyWindowHours: 2}
+}
+
+func thoughtCount(t *testing.T, e *Engine) int64 {
+	t.Helper()
+	var n int64
+	if err := e.db.Model(&DialogueThought{}).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestThoughtSimilarity(t *testing.T) {
+	if s := thoughtSimilarity(reflectionA, reflectionA); s != 1 {
+		t.Errorf("expected identical thoughts to match fully, got %.2f", s)
+	}
+	if s := thoughtSimilarity(reflectionA, reflectionParaphrase); s < DefaultNoveltyThreshold {
+		t.Errorf("expected the paraphrase above the default threshold, got %.2f", s)
+	}
+	if s := thoughtSimilarity(reflectionA, reflectionNew); s >= DefaultNoveltyThreshold {
+		t.Errorf("expected a different thought below the default threshold, got %.2f", s)
+	}
+}
+
+func TestRepetitiveThoughtsAreNotStored(t *testing.T) {
+	e := noveltyEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	if !e.saveThought(ctx, &ThoughtRecord{CycleID: 1, Content: reflectionA, Timestamp: now}) {
+		t.Fatal("expected the first thought stored")
+	}
+	if e.saveThought(ctx, &ThoughtRecord{CycleID: 2, Content: reflectionA, Timestamp: now}) {
+		t.Error("expected an identical thought skipped")
+	}
+	if e.saveThought(ctx, &ThoughtRecord{CycleID: 3, Content: reflectionParaphrase, Timestamp: now}) {
+		t.Error("expected a paraphrased thought skipped")
+	}
+	if !e.saveThought(ctx, &ThoughtRecord{CycleID: 4, Content: reflectionNew, Timestamp: now}) {
+		t.Error("expected a new thought stored")
+	}
+	if n := thoughtCount(t, e); n != 2 {
+		t.Errorf("expected 2 thoughts stored, got %d", n)
+	}
+	if n := e.repetitiveThoughts.Load(); n != 2 {
+		t.Errorf("expected 2 repetitive thoughts counted, got %d", n)
+	}
+
+	hint := e.takeRepetitionHint()
+	if !strings.Contains(hint, "repeating yourself") || !strings.Contains(hint, "academic journals") {
+		t.Errorf("expected a hint naming the repeat, got %q", hint)
+	}
+	if again := e.takeRepetitionHint(); again != "" {
+		t.Errorf("expected the hint given once, got %q", again)
+	}
+}
+
+func TestNoveltyWindowAndStamps(t *testing.T) {
+	e := noveltyEngine(t)
+	ctx := context.Background()
+
+	old := DialogueThought{CycleID: 1, Content: reflectionA, Timestamp: time.Now().Add(-3 * time.Hour)}
+	if err := e.db.Create(&old).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !e.saveThought(ctx, &ThoughtRecord{CycleID: 2, Content: reflectionA, Timestamp: time.Now()}) {
+		t.Error("expected a thought repeating one outside the window stored")
+	}
+
+	stamp := &ThoughtRecord{CycleID: 3, Content: "[evaluate_search@abc] (evaluation)", PromptTemplate: "evaluate_search@abc", Timestamp: time.Now()}
+	e.saveThought(ctx, stamp)
+	if !e.saveThought(ctx, stamp) {
+		t.Error("expected prompt stamps always stored")
+	}
+
+	e.noveltyWindowHours = 0
+	if !e.saveThought(ctx, &ThoughtRecord{CycleID: 4, Content: reflectionA, Timestamp: time.Now()}) {
+		t.Error("expected no check with the window off")
+	}
+}
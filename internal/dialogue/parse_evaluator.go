@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
+
+	"go-llama/internal/tools"
+	"go-llama/internal/utils"
 )
 
 // ParseEvaluation represents the LLM's evaluation of parsed content quality
@@ -16,6 +20,7 @@ type ParseEvaluation struct {
     NextAction     string   // Recommended next step
     ShouldContinue bool     // Continue pursuing this goal?
     UsefulContent  string   // Brief summary of what WAS useful
+    InjectionFlags []string // Suspicious instruction-like phrases found in the content
 }
 
 // evaluateParseResults uses LLM to determine if parsed content helps achieve the goal
@@ -25,6 +30,7 @@ func (e *Engine) evaluateParseResults(
 	goalDescription string,
 	parsedURL string,
 	fallbackURLs []string,
+	sourceMeta map[string]interface{}, // Action metadata carrying source_* provenance (may be nil)
 ) (*ParseEvaluation, error) {
 	
 	// Quick validation checks before LLM call
@@ -58,13 +64,13 @@ func (e *Engine) evaluateParseResults(
 	}
 	
 	// Build evaluation prompt
-	prompt := e.buildParseEvaluationPrompt(parseOutput, goalDescription, parsedURL, fallbackURLs)
+	prompt := e.buildParseEvaluationPrompt(parseOutput, goalDescription, parsedURL, fallbackURLs, sourceMeta)
 	
 	// Call LLM with structured response
 	log.Printf("[ParseEval] Requesting LLM evaluation of parse results (goal: %s)", 
 		truncate(goalDescription, 60))
 	
-	response, tokens, err := e.callLLMWithStructuredReasoning(ctx, prompt, false, "")
+	response, tokens, err := e.callLLMWithStructuredReasoning(withTranscriptStep(ctx, TranscriptStepParseEvaluation), prompt, false, "", CallEvaluation)
 	if err != nil {
 		log.Printf("[ParseEval] LLM evaluation failed: %v", err)
 		// Fallback to conservative evaluation
@@ -102,6 +108,8 @@ func (e *Engine) evaluateParseResults(
 		}, nil
 	}
 	
+	e.applyInjectionPenalty(evaluation, parseOutput)
+
 	log.Printf("[ParseEval] Quality: %s (confidence: %.2f)", evaluation.Quality, evaluation.Confidence)
 	log.Printf("[ParseEval] Reasoning: %s", truncate(evaluation.Reasoning, 100))
 	if len(evaluation.MissingInfo) > 0 {
@@ -117,6 +125,7 @@ func (e *Engine) buildParseEvaluationPrompt(
     goalDescription string,
     parsedURL string,
     fallbackURLs []string,
+    sourceMeta map[string]interface{},
 ) string {
     var prompt strings.Builder
 
@@ -146,7 +155,25 @@ func (e *Engine) buildParseEvaluationPrompt(
     
     // 2. TASK CONTEXT
     prompt.WriteString(fmt.Sprintf("GOAL: %s\n", goalDescription))
+    // Quality is judged against what the parse was looking for
+    if purpose, ok := sourceMeta[MetadataParsePurpose].(string); ok && purpose != "" && purpose != goalDescription {
+        prompt.WriteString(fmt.Sprintf("PARSE PURPOSE: %s\n", purpose))
+    }
     prompt.WriteString(fmt.Sprintf("SOURCE URL: %s\n", parsedURL))
+
+    // Provenance lets the evaluator judge whether the content is stale
+    if title, ok := sourceMeta[sourceMetaKey(tools.MetaPageTitle)].(string); ok {
+        prompt.WriteString(fmt.Sprintf("SOURCE TITLE: %s\n", title))
+    }
+    if published, ok := sourceMeta[sourceMetaKey(tools.MetaPagePublished)].(string); ok {
+        prompt.WriteString(fmt.Sprintf("PUBLISHED: %s\n", published))
+    }
+    if modified, ok := sourceMeta[sourceMetaKey(tools.MetaPageModified)].(string); ok {
+        prompt.WriteString(fmt.Sprintf("LAST UPDATED: %s\n", modified))
+    } else if modified, ok := sourceMeta[sourceMetaKey(tools.MetaPageLastModified)].(string); ok {
+        prompt.WriteString(fmt.Sprintf("LAST UPDATED: %s\n", modified))
+    }
+    prompt.WriteString(fmt.Sprintf("TODAY: %s\n", time.Now().Format("2006-01-02")))
     
     if len(fallbackURLs) > 0 {
         prompt.WriteString(fmt.Sprintf("FALLBACK AVAILABLE: Yes (%d URLs)\n", len(fallbackURLs)))
@@ -157,12 +184,14 @@ func (e *Engine) buildParseEvaluationPrompt(
     
     // 3. INPUT DATA
     prompt.WriteString("PARSED CONTENT TO EVALUATE:\n")
-    // Limit content to avoid token overflow (keep first 2000 chars)
-    content := parseOutput
-    if len(content) > 2000 {
-        content = content[:2000] + "... [truncated]"
+    if truncated, _ := sourceMeta[tools.MetaOutputTruncated].(bool); truncated {
+        prompt.WriteString(fmt.Sprintf("NOTE: This is an excerpt; the page's text was cut from %v bytes, keeping its start and end. "+
+            "Judge what is shown, and rate \"parse_deeper\" if the answer likely sits in the omitted middle.\n", sourceMeta[tools.MetaOriginalOutputBytes]))
     }
-    prompt.WriteString(content)
+    // Limit content to avoid token overflow (keep first 2000 chars)
+    content := utils.TruncateWith(parseOutput, 2000, "... [truncated]")
+    wrapped, _ := tools.WrapUntrusted("web page content", content)
+    prompt.WriteString(wrapped)
     prompt.WriteString("\n\n")
     
     // 4. CRITERIA
@@ -171,6 +200,7 @@ func (e *Engine) buildParseEvaluationPrompt(
     prompt.WriteString("- missing_info: Use empty list () if nothing is missing. Otherwise list gaps.\n")
     prompt.WriteString("- next_action: Choose based on quality. Use \"try_fallback\" only if fallback is available.\n")
     prompt.WriteString("- useful_content: Brief summary if quality > 0, otherwise empty string.\n")
+    prompt.WriteString("- If the source is dated, consider whether it is too old for the goal; outdated content should not be rated \"sufficient\" when currency matters.\n")
     
     return prompt.String()
 }
@@ -254,3 +284,24 @@ func (e *Engine) parseParseEvaluation(rawResponse string) (*ParseEvaluation, err
 	
 	return evaluation, nil
 }
+
+// applyInjectionPenalty lowers confidence when parsed content contains phrases that
+// read like instructions to the evaluator (no-op unless detection is enabled)
+func (e *Engine) applyInjectionPenalty(evaluation *ParseEvaluation, parseOutput string) {
+    if !e.injectionDetection || evaluation == nil {
+        return
+    }
+    flags := tools.DetectInjection(parseOutput)
+    if len(flags) == 0 {
+        return
+    }
+
+    evaluation.InjectionFlags = flags
+    evaluation.Confidence -= e.injectionPenalty
+    if evaluation.Confidence < 0 {
+        evaluation.Confidence = 0
+    }
+    evaluation.Reasoning += fmt.Sprintf(" [content contained instruction-like text: %s]", strings.Join(flags, "; "))
+    log.Printf("[ParseEval] ⚠ Possible prompt injection in parsed content (%d flags), confidence lowered to %.2f",
+        len(flags), evaluation.Confidence)
+}
@@ -0,0 +1,66 @@
+package dialogue
+
+import (
+	"fmt"
+	"strings"
+)
+
+// genericParsePurpose is a parse's purpose when nothing says what it should look for
+const genericParsePurpose = "Extract relevant information for research goal"
+
+// Parse params the goal orchestrator passes to say what a parse is for
+const (
+	paramParseQuestion        = "question"         // Query of the search the parse follows
+	paramParseGoalDescription = "goal_description" // Goal the parse serves
+)
+
+// MetadataParsePurpose is the action metadata key recording what a parse extracted for
+const MetadataParsePurpose = "parse_purpose"
+
+// parsePurpose is what a parse should extract: the research question it follows up
+// along with the goal it serves, else the goal's keywords and description, and the
+// generic purpose only when neither is known
+func parsePurpose(question, goalDescription string) string {
+	question = strings.TrimSpace(question)
+	goalDescription = strings.TrimSpace(goalDescription)
+	switch {
+	case question != "" && goalDescription != "" && !strings.EqualFold(question, goalDescription):
+		return fmt.Sprintf("Answer %q for the goal: %s", question, goalDescription)
+	case question != "":
+		return question
+	case goalDescription != "":
+		if keywords := extractSearchKeywords(goalDescription); keywords != "" && !strings.EqualFold(keywords, goalDescription) {
+			return fmt.Sprintf("Find %s: %s", keywords, goalDescription)
+		}
+		return goalDescription
+	}
+	return genericParsePurpose
+}
+
+// actionParsePurpose returns the purpose of a parse action: one set explicitly in its
+// metadata, else one built from the research question it carries
+func actionParsePurpose(action *Action) string {
+	for _, key := range []string{"goal", "purpose"} {
+		if purpose, ok := action.Metadata[key].(string); ok && purpose != "" && purpose != genericParsePurpose {
+			return purpose
+		}
+	}
+	question, _ := action.Metadata["question_text"].(string)
+	goalDescription, _ := action.Metadata[paramParseGoalDescription].(string)
+	return parsePurpose(question, goalDescription)
+}
+
+// paramsParsePurpose returns the purpose of a parse requested by the goal orchestrator:
+// an explicit goal param, else one built from the search question and goal it passes
+func paramsParsePurpose(params map[string]interface{}) string {
+	if purpose, ok := params["goal"].(string); ok && purpose != "" && purpose != genericParsePurpose {
+		return purpose
+	}
+	question, _ := params[paramParseQuestion].(string)
+	goalDescription, _ := params[paramParseGoalDescription].(string)
+	if question == "" && goalDescription == "" {
+		// Without either, the step's own description is the best guide there is
+		goalDescription, _ = params["query"].(string)
+	}
+	return parsePurpose(question, goalDescription)
+}
@@ -0,0 +1,47 @@
+package dialogue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePurposeFallsBackToGenericOnlyWhenNothingIsKnown(t *testing.T) {
+	cases := []struct {
+		name     string
+		action   *Action
+		contains []string
+	}{
+		{"explicit", &Action{Metadata: map[string]interface{}{"goal": "Find bee navigation studies"}}, []string{"Find bee navigation studies"}},
+		{"question and goal", &Action{Metadata: map[string]interface{}{"question_text": "How do bees navigate?", paramParseGoalDescription: "Understand insect navigation"}}, []string{"How do bees navigate?", "Understand insect navigation"}},
+		{"goal only", &Action{Metadata: map[string]interface{}{paramParseGoalDescription: "Understand how honey bees navigate"}}, []string{"Understand how honey bees navigate"}},
+		{"generic explicit ignored", &Action{Metadata: map[string]interface{}{"goal": genericParsePurpose, "question_text": "How do bees navigate?"}}, []string{"How do bees navigate?"}},
+	}
+	for _, c := range cases {
+		purpose := actionParsePurpose(c.action)
+		if purpose == genericParsePurpose {
+			t.Errorf("%s: expected a specific purpose, got the generic one", c.name)
+		}
+		for _, want := range c.contains {
+			if !strings.Contains(purpose, want) {
+				t.Errorf("%s: expected %q in purpose %q", c.name, want, purpose)
+			}
+		}
+	}
+
+	if purpose := actionParsePurpose(&Action{}); purpose != genericParsePurpose {
+		t.Errorf("expected the generic purpose with nothing known, got %q", purpose)
+	}
+}
+
+func TestParamsParsePurposeUsesSearchQuestion(t *testing.T) {
+	params := map[string]interface{}{paramParseQuestion: "bee waggle dance", paramParseGoalDescription: "Understand insect navigation"}
+	if purpose := paramsParsePurpose(params); !strings.Contains(purpose, "bee waggle dance") || !strings.Contains(purpose, "Understand insect navigation") {
+		t.Errorf("expected the question and goal in the purpose, got %q", purpose)
+	}
+	if purpose := paramsParsePurpose(map[string]interface{}{"query": "Read the bee study"}); !strings.Contains(purpose, "Read the bee study") {
+		t.Errorf("expected the step description without question or goal, got %q", purpose)
+	}
+	if purpose := paramsParsePurpose(map[string]interface{}{}); purpose != genericParsePurpose {
+		t.Errorf("expected the generic purpose with nothing known, got %q", purpose)
+	}
+}
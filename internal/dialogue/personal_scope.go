@@ -0,0 +1,54 @@
+package dialogue
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"go-llama/internal/memory"
+	"go-llama/internal/user"
+)
+
+// personalMemoryConsent loads per-user consent. allowed lists the users whose personal
+// memories the engine may read (never nil, so searches stay scoped even when empty);
+// sharing marks users who also allow derived content to be stored collectively.
+func (e *Engine) personalMemoryConsent() (allowed []string, sharing map[string]bool) {
+	allowed = []string{}
+	sharing = map[string]bool{}
+	if e.db == nil {
+		return allowed, sharing
+	}
+
+	var users []user.User
+	if err := e.db.Where("allow_dialogue_access = ?", true).Find(&users).Error; err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to load personal memory consent: %v", err)
+		return allowed, sharing
+	}
+
+	for _, u := range users {
+		id := fmt.Sprintf("%d", u.ID)
+		allowed = append(allowed, id)
+		sharing[id] = u.AllowCollectiveSharing
+	}
+	return allowed, sharing
+}
+
+// tagGoalSources records which users' personal memories inspired a goal, so anything
+// the goal later produces inherits the same provenance
+func tagGoalSources(goal *Goal, sourceUserIDs []string) {
+	if len(sourceUserIDs) == 0 {
+		return
+	}
+	if goal.Metadata == nil {
+		goal.Metadata = map[string]interface{}{}
+	}
+	goal.Metadata[memory.MetadataSourceUserIDs] = strings.Join(sourceUserIDs, ",")
+}
+
+// goalSourceUserIDs returns the provenance recorded by tagGoalSources
+func goalSourceUserIDs(goal *Goal) []string {
+	if goal.Metadata == nil {
+		return nil
+	}
+	return memory.SourceUserIDsFromMetadata(goal.Metadata)
+}
@@ -0,0 +1,198 @@
+// internal/dialogue/plan_adaptation.go
+package dialogue
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// DefaultMaxReplansPerGoal is how often a research goal's plan may be replaced when
+// dialogue.max_replans_per_goal is not set
+const DefaultMaxReplansPerGoal = 2
+
+// SetMaxReplans sets how many times an assessment may replace a research goal's plan.
+// n <= 0 uses DefaultMaxReplansPerGoal.
+func (e *Engine) SetMaxReplans(n int) {
+	if n <= 0 {
+		n = DefaultMaxReplansPerGoal
+	}
+	e.maxReplans = n
+}
+
+func (e *Engine) replanLimit() int {
+	if e.maxReplans <= 0 {
+		return DefaultMaxReplansPerGoal
+	}
+	return e.maxReplans
+}
+
+// completedActionCount counts the goal's completed actions
+func completedActionCount(goal *Goal) int {
+	count := 0
+	for _, action := range goal.Actions {
+		if action.Status == ActionStatusCompleted {
+			count++
+		}
+	}
+	return count
+}
+
+// needsAssessment reports whether a research goal has completed an action since its
+// plan was last assessed
+func needsAssessment(goal *Goal) bool {
+	if goal.ResearchPlan == nil {
+		return false
+	}
+	assessed := 0
+	if goal.LastAssessment != nil {
+		assessed = goal.LastAssessment.CompletedActions
+	}
+	return completedActionCount(goal) > assessed
+}
+
+// assessResearchGoals checks the plan of every research goal that completed an action
+// since its last assessment, while the cycle budget lasts. It returns the tokens used.
+func (e *Engine) assessResearchGoals(ctx context.Context, state *InternalState, metrics *CycleMetrics) int {
+	tokens := 0
+	for i := range state.ActiveGoals {
+		if e.budgetStopReason(ctx, 0, tokens) != "" {
+			break
+		}
+		// A goal waiting for the user's answer is not worked on until it has one
+		if goal := &state.ActiveGoals[i]; goal.Status != GoalStatusAwaitingUser && needsAssessment(goal) {
+			tokens += e.adaptPlanAfterAction(ctx, goal, metrics)
+		}
+	}
+	return tokens
+}
+
+// adaptPlanAfterAction assesses the goal's progress after a completed action and acts
+// on the recommendation: "adjust" changes the next pending action, "replan" replaces
+// the plan unless the goal has used up its replans. It returns the tokens used.
+func (e *Engine) adaptPlanAfterAction(ctx context.Context, goal *Goal, metrics *CycleMetrics) int {
+	ctx = withGoalID(ctx, goal.ID)
+	assessment, tokens, err := e.assessProgress(ctx, goal)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to assess plan for goal %s: %v", goal.ID, err)
+		return tokens
+	}
+	assessment.GoalID = goal.ID
+	assessment.Timestamp = time.Now()
+	assessment.CompletedActions = completedActionCount(goal)
+	goal.LastAssessment = assessment
+	e.recordGoalEvent(ctx, goal.ID, TimelineAssessment, assessment.Reasoning, map[string]interface{}{
+		"progress_quality":  assessment.ProgressQuality,
+		"plan_validity":     assessment.PlanValidity,
+		"recommendation":    assessment.Recommendation,
+		"adjustment":        assessment.Adjustment,
+		"completed_actions": assessment.CompletedActions,
+	})
+
+	// The answer may change the plan, so it waits for the answer
+	if goal.Status == GoalStatusAwaitingUser {
+		return tokens
+	}
+
+	switch assessment.Recommendation {
+	case "adjust":
+		if applyAdjustment(goal, assessment.Adjustment) {
+			metrics.PlanAdjustments++
+			log.Printf("[Dialogue] Adjusted next action of goal %s: %s", goal.ID, truncate(assessment.Adjustment, 100))
+		}
+	case "replan":
+		if goal.ReplanCount >= e.replanLimit() {
+			log.Printf("[Dialogue] Goal %s needs a new plan but has used its %d replans, continuing", goal.ID, e.replanLimit())
+			break
+		}
+		plan, replanTokens, err := e.replanGoal(ctx, goal, assessment.Reasoning)
+		tokens += replanTokens
+		if err != nil {
+			log.Printf("[Dialogue] WARNING: Failed to replan goal %s: %v", goal.ID, err)
+			break
+		}
+		applyReplan(goal, plan, assessment.Reasoning)
+		e.recordReplan(ctx, goal)
+		metrics.Replans++
+		log.Printf("[Dialogue] Replanned goal %s (%d/%d): %s", goal.ID, goal.ReplanCount, e.replanLimit(), truncate(assessment.Reasoning, 100))
+	}
+	return tokens
+}
+
+// applyAdjustment puts the assessment's revised query or URL on the goal's next pending
+// action. It reports whether there was an adjustment and an action to take it.
+func applyAdjustment(goal *Goal, adjustment string) bool {
+	adjustment = strings.TrimSpace(adjustment)
+	if adjustment == "" {
+		return false
+	}
+	for i := range goal.Actions {
+		action := &goal.Actions[i]
+		if action.Status != ActionStatusPending {
+			continue
+		}
+		if action.Tool == ActionToolWebParseUnified && strings.HasPrefix(adjustment, "http") {
+			if action.Metadata == nil {
+				action.Metadata = map[string]interface{}{}
+			}
+			action.Metadata["selected_url"] = adjustment
+		} else {
+			action.Description = adjustment
+		}
+		return true
+	}
+	return false
+}
+
+// recordReplan adds the goal's new plan to its timeline
+func (e *Engine) recordReplan(ctx context.Context, goal *Goal) {
+	questions := make([]string, 0, len(goal.ResearchPlan.SubQuestions))
+	for _, q := range goal.ResearchPlan.SubQuestions {
+		questions = append(questions, q.Question)
+	}
+	e.recordGoalEvent(ctx, goal.ID, TimelinePlanReplanned, goal.LastReplanReason, map[string]interface{}{
+		"replan_count":  goal.ReplanCount,
+		"root_question": goal.ResearchPlan.RootQuestion,
+		"questions":     questions,
+	})
+}
+
+// applyReplan replaces the goal's research plan and drops the pending actions created
+// for the old plan's questions, along with pending actions that depend on them,
+// recording why
+func applyReplan(goal *Goal, plan *ResearchPlan, reason string) {
+	goal.ResearchPlan = plan
+	dropped := make([]bool, len(goal.Actions))
+	droppedIDs := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for i, action := range goal.Actions {
+			if action.Status != ActionStatusPending || dropped[i] {
+				continue
+			}
+			_, fromPlan := action.Metadata["research_question_id"]
+			dependsOnDropped := false
+			for _, id := range action.DependsOn {
+				dependsOnDropped = dependsOnDropped || droppedIDs[id]
+			}
+			if fromPlan || dependsOnDropped {
+				dropped[i] = true
+				if action.ID != "" {
+					droppedIDs[action.ID] = true
+				}
+				changed = true
+			}
+		}
+	}
+	kept := goal.Actions[:0]
+	for i, action := range goal.Actions {
+		if dropped[i] {
+			continue
+		}
+		kept = append(kept, action)
+	}
+	goal.Actions = kept
+	goal.ReplanCount++
+	goal.LastReplanReason = reason
+}
@@ -0,0 +1,121 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const replanAssessment = `(assessment (progress_quality "poor") (plan_validity "needs_replan") (reasoning "Searches for bee GPS only return phone apps.") (recommendation "replan"))`
+
+const replannedPlan = `(research_plan
+  (root_question "How do honeybees find their way?")
+  (sub_questions
+    (question (id "q1") (text "Do bees use the sun as a compass?") (search_query "honeybee sun compass navigation study") (priority 10) (deps ()))
+    (question (id "q2") (text "What is the waggle dance?") (search_query "waggle dance distance direction") (priority 8) (deps ()))))`
+
+// searchFixtureGoal is a research goal whose first search returned only irrelevant
+// results, with the next question's search still pending
+func searchFixtureGoal() *Goal {
+	return &Goal{
+		ID:          "goal_bees",
+		Description: "Learn how bees navigate",
+		ResearchPlan: &ResearchPlan{
+			RootQuestion: "How do bees navigate?",
+			SubQuestions: []ResearchQuestion{
+				{ID: "q1", Question: "Do bees have GPS?", SearchQuery: "bee gps", Status: ResearchStatusInProgress},
+				{ID: "q2", Question: "Which bee GPS is best?", SearchQuery: "best bee gps tracker", Status: ResearchStatusPending},
+			},
+		},
+		Actions: []Action{
+			{Description: "bee gps", Tool: ActionToolSearch, Status: ActionStatusCompleted,
+				Result:   "1. BeeTrack GPS app - track your phone\n2. Bee GPS: buy now, free shipping",
+				Metadata: map[string]interface{}{"research_question_id": "q1"}},
+			{Description: "best bee gps tracker", Tool: ActionToolSearch, Status: ActionStatusPending,
+				Metadata: map[string]interface{}{"research_question_id": "q2"}},
+			{Description: "Check memory for bees", Tool: ActionToolSearch, Status: ActionStatusPending},
+		},
+	}
+}
+
+func planEngine(t *testing.T, replies ...string) (*Engine, *completionCaller) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&memory.Principle{}); err != nil {
+		t.Fatal(err)
+	}
+	caller := &completionCaller{replies: replies}
+	return &Engine{db: db, llmClient: caller, modelRouter: NewModelRouter("http://reasoning", "8b", "", "")}, caller
+}
+
+func TestPoorSearchResultsForceReplan(t *testing.T) {
+	e, caller := planEngine(t, replanAssessment, replannedPlan)
+	state := &InternalState{ActiveGoals: []Goal{*searchFixtureGoal()}}
+	metrics := &CycleMetrics{}
+
+	if tokens := e.assessResearchGoals(context.Background(), state, metrics); tokens != 20 {
+		t.Errorf("expected the assessment and replan tokens counted, got %d", tokens)
+	}
+	if len(caller.prompts) != 2 || !strings.Contains(caller.prompts[0], "BeeTrack GPS app") {
+		t.Fatalf("expected the search results assessed then a replan, got %d calls", len(caller.prompts))
+	}
+
+	goal := state.ActiveGoals[0]
+	if goal.ReplanCount != 1 || goal.LastReplanReason != "Searches for bee GPS only return phone apps." || metrics.Replans != 1 {
+		t.Fatalf("expected the replan recorded, got count %d, reason %q, metrics %d", goal.ReplanCount, goal.LastReplanReason, metrics.Replans)
+	}
+	for _, q := range goal.ResearchPlan.SubQuestions {
+		if q.SearchQuery == "bee gps" || q.SearchQuery == "best bee gps tracker" {
+			t.Errorf("expected new search queries, got %q again", q.SearchQuery)
+		}
+	}
+	if len(goal.Actions) != 2 || goal.Actions[0].Status != ActionStatusCompleted || goal.Actions[1].Description != "Check memory for bees" {
+		t.Errorf("expected only the old plan's pending search dropped, got %+v", goal.Actions)
+	}
+
+	// The same completed action is not assessed twice
+	e.assessResearchGoals(context.Background(), state, metrics)
+	if len(caller.prompts) != 2 {
+		t.Errorf("expected no new assessment without a new completed action, got %d calls", len(caller.prompts))
+	}
+}
+
+func TestReplanLimit(t *testing.T) {
+	e, caller := planEngine(t, replanAssessment, replannedPlan)
+	goal := searchFixtureGoal()
+	goal.ReplanCount = DefaultMaxReplansPerGoal
+	metrics := &CycleMetrics{}
+
+	e.adaptPlanAfterAction(context.Background(), goal, metrics)
+	if len(caller.prompts) != 1 || goal.ReplanCount != DefaultMaxReplansPerGoal || metrics.Replans != 0 {
+		t.Errorf("expected no replan past the limit, got %d calls, count %d", len(caller.prompts), goal.ReplanCount)
+	}
+	if goal.ResearchPlan.SubQuestions[0].SearchQuery != "bee gps" || goal.LastAssessment.Recommendation != "replan" {
+		t.Errorf("expected the plan kept and the assessment recorded, got %+v", goal.LastAssessment)
+	}
+}
+
+func TestAdjustChangesNextPendingAction(t *testing.T) {
+	e, _ := planEngine(t, `(assessment (progress_quality "partial") (reasoning "Too commercial.") (recommendation "adjust") (adjustment "bee navigation research"))`)
+	goal := searchFixtureGoal()
+	metrics := &CycleMetrics{}
+
+	e.adaptPlanAfterAction(context.Background(), goal, metrics)
+	if goal.Actions[1].Description != "bee navigation research" || goal.Actions[2].Description != "Check memory for bees" || metrics.PlanAdjustments != 1 {
+		t.Errorf("expected only the next pending action adjusted, got %+v", goal.Actions)
+	}
+
+	parse := &Goal{Actions: []Action{{Tool: ActionToolWebParseUnified, Status: ActionStatusPending, Timestamp: time.Now()}}}
+	if !applyAdjustment(parse, "https://example.org/bees") || parse.Actions[0].Metadata["selected_url"] != "https://example.org/bees" {
+		t.Errorf("expected a URL adjustment to select the page to parse, got %+v", parse.Actions[0])
+	}
+}
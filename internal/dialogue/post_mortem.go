@@ -0,0 +1,230 @@
+// internal/dialogue/post_mortem.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-llama/internal/memory"
+)
+
+const (
+	postMortemTag        = "post_mortem"       // Concept tag of post-mortem memories
+	postMortemReserve    = 1500                // Tokens a post-mortem is assumed to need
+	maxPostMortemActions = 15                  // Most recent actions shown to the post-mortem
+	maxPostMortemLessons = 3                   // Post-mortems put in one prompt
+	postMortemMinScore   = 0.5                 // Similarity a post-mortem needs to count as on topic
+	postMortemMaxAge     = 30 * 24 * time.Hour // Older post-mortems are not looked up
+)
+
+// PostMortem is the analysis stored when a goal is abandoned after failures
+type PostMortem struct {
+	WentWrong     string
+	DoDifferently string
+}
+
+// goalFailed reports whether an abandoned goal ended in failures worth a post-mortem
+func goalFailed(goal *Goal) bool {
+	if goal.Status != GoalStatusAbandoned {
+		return false
+	}
+	if goal.Outcome == GoalOutcomeFailed || goal.FailureCount > 0 {
+		return true
+	}
+	for _, action := range goal.Actions {
+		if action.FailureKind != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// failedTargets returns the URLs and search queries of the goal's failed actions
+func failedTargets(goal *Goal) (urls, queries []string) {
+	for _, action := range goal.Actions {
+		if action.FailureKind == "" {
+			continue
+		}
+		switch action.Tool {
+		case ActionToolSearch:
+			queries = append(queries, action.Description)
+		case ActionToolWebParseUnified:
+			url := strings.TrimSpace(action.Description)
+			for _, key := range []string{"selected_url", "best_url"} {
+				if u, ok := action.Metadata[key].(string); ok && u != "" {
+					url = u
+					break
+				}
+			}
+			urls = append(urls, url)
+		}
+	}
+	return urls, queries
+}
+
+// postMortemAffordable reports whether the cycle has the tokens left for a post-mortem
+func (e *Engine) postMortemAffordable(totalTokens int) bool {
+	if e.tokenBudget != nil && e.tokenBudget.OverHardLimit() {
+		return false
+	}
+	return e.maxTokensPerCycle <= 0 || totalTokens+postMortemReserve <= e.maxTokensPerCycle
+}
+
+// runPostMortem asks the simple model what went wrong with an abandoned goal and stores
+// the answer as a collective memory. It returns the tokens used.
+func (e *Engine) runPostMortem(ctx context.Context, goal *Goal) (int, error) {
+	actions := goal.Actions
+	if len(actions) > maxPostMortemActions {
+		actions = actions[len(actions)-maxPostMortemActions:]
+	}
+	var history strings.Builder
+	for i, action := range actions {
+		status := action.Status
+		if action.FailureKind != "" {
+			status = "failed: " + action.FailureKind
+		}
+		history.WriteString(fmt.Sprintf("%d. %s [%s] %s\n", i+1, action.Tool, status, truncate(action.Description, 120)))
+	}
+	if len(actions) == 0 {
+		history.WriteString("(no actions recorded)\n")
+	}
+
+	response, tokens, err := e.callPrompt(ctx, PromptPostMortem, postMortemPrompt{
+		Goal:    goal.Description,
+		Outcome: string(goal.Outcome),
+		Actions: history.String(),
+	}, false, CallPostMortem)
+	if err != nil {
+		return tokens, fmt.Errorf("post-mortem failed: %w", err)
+	}
+	pm, err := parsePostMortem(response.RawResponse)
+	if err != nil {
+		return tokens, err
+	}
+	return tokens, e.storePostMortem(ctx, goal, pm)
+}
+
+// parsePostMortem reads the post_mortem S-expression
+func parsePostMortem(rawResponse string) (*PostMortem, error) {
+	content := strings.TrimSpace(rawResponse)
+	content = strings.TrimPrefix(content, "```lisp")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	blocks := findBlocksRecursive(content, "post_mortem")
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no post_mortem block in response: %s", truncate(content, 100))
+	}
+	pm := &PostMortem{
+		WentWrong:     strings.TrimSpace(extractFieldContent(blocks[0], "went_wrong")),
+		DoDifferently: strings.TrimSpace(extractFieldContent(blocks[0], "do_differently")),
+	}
+	if pm.WentWrong == "" && pm.DoDifferently == "" {
+		return nil, fmt.Errorf("post-mortem is empty: %s", truncate(blocks[0], 100))
+	}
+	return pm, nil
+}
+
+// storePostMortem stores the analysis tagged post_mortem, with the goal and the URLs
+// and queries that failed in its metadata
+func (e *Engine) storePostMortem(ctx context.Context, goal *Goal, pm *PostMortem) error {
+	content := fmt.Sprintf("POST-MORTEM: %s\nWhat went wrong: %s\nDo differently: %s",
+		goal.Description, pm.WentWrong, pm.DoDifferently)
+	embedding, err := e.embedder.Embed(ctx, content)
+	if err != nil {
+		return fmt.Errorf("failed to embed post-mortem: %w", err)
+	}
+
+	urls, queries := failedTargets(goal)
+	mem := &memory.Memory{
+		Content:         content,
+		Tier:            memory.TierRecent,
+		IsCollective:    true,
+		SourceKind:      memory.SourceDialogueLearning,
+		CreatedAt:       time.Now(),
+		LastAccessedAt:  time.Now(),
+		ImportanceScore: 0.7,
+		Embedding:       embedding,
+		OutcomeTag:      "bad",
+		TrustScore:      0.6,
+		ValidationCount: 1,
+		ConceptTags:     []string{postMortemTag},
+		Metadata: map[string]interface{}{
+			"goal_description": goal.Description,
+			"went_wrong":       pm.WentWrong,
+			"do_differently":   pm.DoDifferently,
+			"failed_urls":      urls,
+			"failed_queries":   queries,
+		},
+	}
+	e.stampProvenance(ctx, mem, goal.ID, goalActionIDs(goal))
+
+	if err := e.storage.Store(ctx, mem); err != nil {
+		return fmt.Errorf("failed to store post-mortem: %w", err)
+	}
+	e.publishEvent(EventLearningStored, goal.ID, "", map[string]interface{}{"kind": postMortemTag, "memory_id": mem.ID})
+	return nil
+}
+
+// recentPostMortems returns lessons from recent post-mortems on topic, one line each.
+// Lookup failures are logged and yield none; the lessons only inform a prompt.
+func (e *Engine) recentPostMortems(ctx context.Context, topic string) []string {
+	if e.storage == nil || e.embedder == nil || strings.TrimSpace(topic) == "" {
+		return nil
+	}
+	embedding, err := e.embedder.Embed(ctx, topic)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to embed topic for post-mortem lookup: %v", err)
+		return nil
+	}
+	results, err := e.storage.Search(ctx, memory.RetrievalQuery{
+		IncludeCollective: true,
+		ConceptTags:       []string{postMortemTag},
+		CreatedAfter:      time.Now().Add(-postMortemMaxAge),
+		Limit:             maxPostMortemLessons,
+		MinScore:          postMortemMinScore,
+	}, embedding)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: Post-mortem lookup failed: %v", err)
+		return nil
+	}
+
+	lessons := []string{}
+	for _, result := range results {
+		lessons = append(lessons, postMortemLesson(result.Memory))
+	}
+	return lessons
+}
+
+// postMortemLesson renders a post-mortem memory as one prompt line
+func postMortemLesson(mem memory.Memory) string {
+	goal, _ := mem.Metadata["goal_description"].(string)
+	wentWrong, _ := mem.Metadata["went_wrong"].(string)
+	doDifferently, _ := mem.Metadata["do_differently"].(string)
+	if goal == "" || (wentWrong == "" && doDifferently == "") {
+		return truncate(mem.Content, 200)
+	}
+	return fmt.Sprintf("%s: %s Instead: %s", truncate(goal, 60), truncate(wentWrong, 150), truncate(doDifferently, 150))
+}
+
+// postMortem runs the post-mortem of a failed goal, unless the cycle is out of tokens,
+// and adds its tokens to totalTokens
+func (e *Engine) postMortem(ctx context.Context, goal *Goal, totalTokens *int) {
+	if e.storage == nil || e.embedder == nil {
+		return
+	}
+	if !e.postMortemAffordable(*totalTokens) {
+		log.Printf("[Dialogue] Skipping post-mortem of goal %s: cycle token budget exhausted", goal.ID)
+		return
+	}
+	tokens, err := e.runPostMortem(ctx, goal)
+	*totalTokens += tokens
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: No post-mortem for goal %s: %v", goal.ID, err)
+		return
+	}
+	log.Printf("[Dialogue] ✓ Stored post-mortem of abandoned goal %s", goal.ID)
+}
@@ -0,0 +1,115 @@
+package dialogue
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-llama/internal/memory"
+)
+
+func TestGoalFailed(t *testing.T) {
+	cases := []struct {
+		name string
+		goal Goal
+		want bool
+	}{
+		{"active", Goal{Status: GoalStatusActive, Outcome: GoalOutcomeFailed}, false},
+		{"completed", Goal{Status: GoalStatusCompleted, FailureCount: 2}, false},
+		{"abandoned without output", Goal{Status: GoalStatusAbandoned, Outcome: GoalOutcomeNoUsefulOutput}, false},
+		{"abandoned failed", Goal{Status: GoalStatusAbandoned, Outcome: GoalOutcomeFailed}, true},
+		{"abandoned with failed action", Goal{Status: GoalStatusAbandoned, Actions: []Action{{FailureKind: "timeout"}}}, true},
+	}
+	for _, c := range cases {
+		if got := goalFailed(&c.goal); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestFailedTargets(t *testing.T) {
+	goal := &Goal{Actions: []Action{
+		{Tool: ActionToolSearch, Description: "go generics tutorial", FailureKind: "timeout"},
+		{Tool: ActionToolSearch, Description: "go generics spec"},
+		{Tool: ActionToolWebParseUnified, Description: "Parse the page", FailureKind: "http_status",
+			Metadata: map[string]interface{}{"selected_url": "https://example.com/generics"}},
+	}}
+	urls, queries := failedTargets(goal)
+	if !reflect.DeepEqual(urls, []string{"https://example.com/generics"}) {
+		t.Errorf("expected the selected URL, got %v", urls)
+	}
+	if !reflect.DeepEqual(queries, []string{"go generics tutorial"}) {
+		t.Errorf("expected only the failed query, got %v", queries)
+	}
+}
+
+func TestParsePostMortem(t *testing.T) {
+	pm, err := parsePostMortem("```lisp\n(post_mortem (went_wrong \"Every source was paywalled\") (do_differently \"Use the open data portal\"))\n```")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pm.WentWrong != "Every source was paywalled" || pm.DoDifferently != "Use the open data portal" {
+		t.Errorf("unexpected post-mortem %+v", pm)
+	}
+	if _, err := parsePostMortem("(post_mortem)"); err == nil {
+		t.Error("expected an empty post-mortem to be rejected")
+	}
+	if _, err := parsePostMortem("no idea"); err == nil {
+		t.Error("expected a response without a block to be rejected")
+	}
+}
+
+func TestPostMortemAffordable(t *testing.T) {
+	e := &Engine{maxTokensPerCycle: 10000}
+	if !e.postMortemAffordable(5000) {
+		t.Error("expected a post-mortem to fit in the remaining tokens")
+	}
+	if e.postMortemAffordable(10000 - postMortemReserve + 1) {
+		t.Error("expected a post-mortem to be skipped when the cycle is nearly out of tokens")
+	}
+	e.tokenBudget = &fixedBudget{hard: true}
+	if e.postMortemAffordable(0) {
+		t.Error("expected a post-mortem to be skipped at the hard budget limit")
+	}
+}
+
+func TestPostMortemRouteCapsTokens(t *testing.T) {
+	router := NewModelRouter("http://reasoning", "8b", "http://simple", "1b")
+	route := router.Route(CallPostMortem)
+	if route.Tier != ModelTierSimple || route.Sampling.MaxTokens != defaultCallMaxTokens[CallPostMortem] {
+		t.Errorf("expected a capped simple-model route, got %+v", route)
+	}
+	if got := router.Route(CallSynthesis).Sampling.MaxTokens; got != 0 {
+		t.Errorf("expected other call types uncapped, got %d", got)
+	}
+}
+
+func TestResearchPlanPromptIncludesPostMortems(t *testing.T) {
+	rendered, err := (&Engine{}).promptRegistry().Render(PromptResearchPlan, researchPlanPrompt{
+		Goal:        "Learn Go generics",
+		PostMortems: []string{"Learn Go generics: blog posts predated generics Instead: read the release notes"},
+	})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(rendered.Text, "LESSONS FROM ABANDONED GOALS") || !strings.Contains(rendered.Text, "read the release notes") {
+		t.Errorf("expected the lesson in the prompt, got:\n%s", rendered.Text)
+	}
+}
+
+func TestPostMortemLesson(t *testing.T) {
+	lesson := postMortemLesson(memory.Memory{
+		Content: "POST-MORTEM: x",
+		Metadata: map[string]interface{}{
+			"goal_description": "Find 2019 figures",
+			"went_wrong":       "Paywalled reports.",
+			"do_differently":   "Use the open data portal.",
+		},
+	})
+	if lesson != "Find 2019 figures: Paywalled reports. Instead: Use the open data portal." {
+		t.Errorf("unexpected lesson %q", lesson)
+	}
+	if got := postMortemLesson(memory.Memory{Content: "POST-MORTEM: x"}); got != "POST-MORTEM: x" {
+		t.Errorf("expected the content without metadata, got %q", got)
+	}
+}
@@ -0,0 +1,263 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-llama/internal/memory"
+	"go-llama/internal/tools"
+)
+
+// Principle trial methods
+const (
+	PrincipleTrialMethodEmpirical = "empirical"
+	PrincipleTrialMethodLLMOnly   = "llm_only"
+)
+
+const (
+	defaultPrincipleTrials      = 3
+	defaultPrincipleTrialMargin = 0.05
+	minParseOutputLength        = 50 // Matches evaluateParseResults' quick failure check
+)
+
+// errPrincipleTrialUnavailable signals that the empirical trial could not run
+var errPrincipleTrialUnavailable = errors.New("principle trial unavailable")
+
+// parseQualityScores maps ParseEvaluation.Quality onto a 0.0-1.0 scale
+var parseQualityScores = map[string]float64{
+	"sufficient":        1.0,
+	"parse_deeper":      0.6,
+	"try_fallback":      0.3,
+	"completely_failed": 0.0,
+}
+
+// trialBranchTotals accumulates raw measurements for one trial branch
+type trialBranchTotals struct {
+	trials          int
+	searchConfSum   float64
+	searchEvals     int
+	parseQualitySum float64
+	parseEvals      int
+	actionsRun      int
+	actionsOK       int
+}
+
+// summary converts totals into the averaged branch metrics
+func (t *trialBranchTotals) summary() PrincipleTrialBranch {
+	branch := PrincipleTrialBranch{Trials: t.trials}
+	components := []float64{}
+	if t.searchEvals > 0 {
+		branch.SearchConfidence = t.searchConfSum / float64(t.searchEvals)
+		components = append(components, branch.SearchConfidence)
+	}
+	if t.parseEvals > 0 {
+		branch.ParseQuality = t.parseQualitySum / float64(t.parseEvals)
+		components = append(components, branch.ParseQuality)
+	}
+	if t.actionsRun > 0 {
+		branch.ActionSuccessRate = float64(t.actionsOK) / float64(t.actionsRun)
+		components = append(components, branch.ActionSuccessRate)
+	}
+	// Score is the mean of whichever components were measured
+	for _, c := range components {
+		branch.Score += c
+	}
+	if len(components) > 0 {
+		branch.Score /= float64(len(components))
+	}
+	return branch
+}
+
+// trialParse is the outcome of parsing one page for a trial's search, which both
+// branches evaluate when they pick that page
+type trialParse struct {
+	output   string
+	metadata map[string]interface{}
+	err      error
+}
+
+// runPrincipleTrial executes the goal's test actions under the current principles and
+// under the proposed principle, then compares measured outcomes. Each search, and each
+// page parsed for it, runs once and both branches judge the same output, so only the
+// principle set differs.
+func (e *Engine) runPrincipleTrial(ctx context.Context, goal *Goal, currentPrinciples []memory.Principle) (*PrincipleTrialResult, error) {
+	modGoal := goal.SelfModGoal
+	if e.toolRegistry == nil || !e.validateToolExists(ActionToolSearch) {
+		return nil, fmt.Errorf("%w: search tool not available", errPrincipleTrialUnavailable)
+	}
+	if len(currentPrinciples) == 0 {
+		return nil, fmt.Errorf("%w: no current principles loaded", errPrincipleTrialUnavailable)
+	}
+
+	trials := e.principleTrials
+	if trials <= 0 {
+		trials = defaultPrincipleTrials
+	}
+	// Zero takes the default; a negative margin accepts any lead
+	margin := e.principleTrialMargin
+	switch {
+	case margin == 0:
+		margin = defaultPrincipleTrialMargin
+	case margin < 0:
+		margin = 0
+	}
+
+	candidatePrinciples := withProposedPrinciple(currentPrinciples, modGoal.TargetSlot, modGoal.ProposedPrinciple)
+	canParse := e.validateToolExists(ActionToolWebParseUnified)
+
+	var baseline, candidate trialBranchTotals
+	for t := 0; t < trials; t++ {
+		baselineMeasured, candidateMeasured := false, false
+		for _, testAction := range modGoal.TestActions {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if testAction.Tool != ActionToolSearch {
+				continue // Only search-rooted actions produce comparable metrics
+			}
+
+			query := trialQuery(testAction, modGoal)
+			searchAction := Action{Description: query, Tool: ActionToolSearch}
+			searchOutput, err := e.executeAction(ctx, &searchAction)
+			if err != nil {
+				log.Printf("[Dialogue] Principle trial %d: search failed: %v", t+1, err)
+				baseline.actionsRun++
+				candidate.actionsRun++
+				continue
+			}
+
+			results := tools.DecodeSearchResults(searchAction.Metadata[MetadataSearchResults])
+			parses := map[string]*trialParse{}
+			if e.measureTrialBranch(ctx, &baseline, currentPrinciples, query, searchOutput, results, canParse, parses) {
+				baselineMeasured = true
+			}
+			if e.measureTrialBranch(ctx, &candidate, candidatePrinciples, query, searchOutput, results, canParse, parses) {
+				candidateMeasured = true
+			}
+		}
+		// A trial counts once however many test actions it measured
+		if baselineMeasured {
+			baseline.trials++
+		}
+		if candidateMeasured {
+			candidate.trials++
+		}
+	}
+
+	if baseline.trials == 0 || candidate.trials == 0 {
+		return nil, fmt.Errorf("%w: no trial produced a measurement", errPrincipleTrialUnavailable)
+	}
+
+	result := &PrincipleTrialResult{
+		Method:    PrincipleTrialMethodEmpirical,
+		Baseline:  baseline.summary(),
+		Candidate: candidate.summary(),
+		Margin:    margin,
+	}
+	lead := result.Candidate.Score - result.Baseline.Score
+	result.Passed = lead > 0 && lead >= margin
+	result.Reasoning = fmt.Sprintf("proposed principle scored %.2f vs baseline %.2f over %d trial(s) (lead %.2f, required %.2f)",
+		result.Candidate.Score, result.Baseline.Score, result.Candidate.Trials, lead, margin)
+
+	log.Printf("[Dialogue] Principle trial for slot %d: %s", modGoal.TargetSlot, result.Reasoning)
+	return result, nil
+}
+
+// measureTrialBranch evaluates one search result (and the parse it leads to) under a
+// principle set and adds the outcome to the branch totals. Pages are parsed once per
+// search: parses holds those already parsed, by URL. It reports whether the search
+// evaluation produced a measurement.
+func (e *Engine) measureTrialBranch(ctx context.Context, totals *trialBranchTotals, principles []memory.Principle, query, searchOutput string, results []tools.StructuredSearchResult, canParse bool, parses map[string]*trialParse) bool {
+	// The search itself succeeded for both branches
+	totals.actionsRun++
+	totals.actionsOK++
+
+	urls := e.fetchableURLs(searchResultURLs(results, searchOutput))
+	if len(urls) == 0 {
+		return false
+	}
+
+	response, _, err := e.callLLMWithPrincipleSet(ctx, e.buildSearchEvaluationPrompt(searchOutput, query, "", urls), false, "", principles, CallEvaluation)
+	if err != nil {
+		log.Printf("[Dialogue] Principle trial: search evaluation failed: %v", err)
+		return false
+	}
+
+	evaluation, err := e.parseSearchEvaluation(response.RawResponse)
+	if err != nil {
+		// Same neutral fallback evaluateSearchResults uses
+		evaluation = &SearchEvaluation{BestURL: urls[0], Confidence: 0.5, ShouldProceed: true}
+	}
+	totals.searchConfSum += evaluation.Confidence
+	totals.searchEvals++
+
+	if !canParse || !evaluation.ShouldProceed || evaluation.BestURL == "" {
+		return true
+	}
+
+	parse, ok := parses[evaluation.BestURL]
+	if !ok {
+		parseAction := Action{
+			Tool: ActionToolWebParseUnified,
+			Metadata: map[string]interface{}{
+				"selected_url":  evaluation.BestURL,
+				"fallback_urls": evaluation.FallbackURLs,
+				"goal":          query,
+				"bypass_memory": true, // Trials judge the page as parsed, not a reused synthesis
+			},
+		}
+		parse = &trialParse{metadata: parseAction.Metadata}
+		parse.output, parse.err = e.executeAction(ctx, &parseAction)
+		parses[evaluation.BestURL] = parse
+	}
+	totals.actionsRun++
+	if parse.err != nil {
+		totals.parseEvals++ // A failed parse counts as completely_failed
+		return true
+	}
+	totals.actionsOK++
+
+	quality := "completely_failed"
+	if len(parse.output) >= minParseOutputLength {
+		prompt := e.buildParseEvaluationPrompt(parse.output, query, evaluation.BestURL, evaluation.FallbackURLs, parse.metadata)
+		parseResponse, _, err := e.callLLMWithPrincipleSet(ctx, prompt, false, "", principles, CallEvaluation)
+		if err != nil {
+			log.Printf("[Dialogue] Principle trial: parse evaluation failed: %v", err)
+			return true
+		}
+		parseEval, err := e.parseParseEvaluation(parseResponse.RawResponse)
+		if err != nil {
+			return true
+		}
+		quality = parseEval.Quality
+	}
+
+	totals.parseQualitySum += parseQualityScores[quality]
+	totals.parseEvals++
+	return true
+}
+
+// withProposedPrinciple returns a copy of principles with one slot's content replaced
+func withProposedPrinciple(principles []memory.Principle, slot int, content string) []memory.Principle {
+	modified := make([]memory.Principle, len(principles))
+	copy(modified, principles)
+	for i := range modified {
+		if modified[i].Slot == slot {
+			modified[i].Content = content
+			return modified
+		}
+	}
+	return append(modified, memory.Principle{Slot: slot, Content: content})
+}
+
+// trialQuery picks the search query for a principle test action
+func trialQuery(action Action, modGoal *SelfModificationGoal) string {
+	if action.Metadata != nil {
+		if q, ok := action.Metadata["query"].(string); ok && q != "" {
+			return q
+		}
+	}
+	return truncate(modGoal.Justification, 100)
+}
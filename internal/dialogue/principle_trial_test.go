@@ -0,0 +1,240 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+	"go-llama/internal/tools"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const proposedTrialPrinciple = "Prefer primary sources over summaries"
+
+// branchCaller answers evaluations by principle set: calls whose system prompt carries
+// the proposed principle get the candidate's replies, the rest the baseline's
+type branchCaller struct {
+	baselineConfidence, candidateConfidence float64
+	baselineQuality, candidateQuality       string
+	validation                              string
+	calls                                   int
+}
+
+func (c *branchCaller) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	c.calls++
+	messages := payload["messages"].([]map[string]string)
+	candidate := strings.Contains(messages[0]["content"], proposedTrialPrinciple)
+	prompt := messages[len(messages)-1]["content"]
+
+	confidence, quality := c.baselineConfidence, c.baselineQuality
+	if candidate {
+		confidence, quality = c.candidateConfidence, c.candidateQuality
+	}
+	var reply string
+	switch {
+	case strings.HasPrefix(prompt, "Evaluate these search results"):
+		reply = `(search_evaluation (best_url "https://go.dev/doc/effective_go") (confidence ` +
+			formatFloat(confidence) + `) (should_proceed true))`
+	case c.validation != "":
+		reply = c.validation
+	default:
+		reply = `(quality "` + quality + `") (confidence 0.8) (reasoning "judged")`
+	}
+	return json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"content": reply}}},
+		"usage":   map[string]int{"total_tokens": 10},
+	})
+}
+
+func formatFloat(f float64) string {
+	b, _ := json.Marshal(f)
+	return string(b)
+}
+
+// articleTool parses every page into text long enough to be evaluated
+type articleTool struct {
+	pageTool
+}
+
+func (a *articleTool) Execute(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+	url := params["url"].(string)
+	a.visited = append(a.visited, url)
+	return &tools.ToolResult{Success: true, Output: strings.Repeat("Effective Go explains idiomatic code. ", 4), Metadata: map[string]interface{}{}}, nil
+}
+
+func principleTrialEngine(t *testing.T, caller *branchCaller, withSearch bool) (*Engine, *articleTool) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&memory.Principle{}); err != nil {
+		t.Fatal(err)
+	}
+	parser := &articleTool{}
+	registry := tools.NewRegistry()
+	registry.Register(parser)
+	configs := map[string]tools.ToolConfig{ActionToolWebParseUnified: {TimeoutIdle: time.Minute}}
+	if withSearch {
+		registry.Register(&resultsTool{results: []tools.StructuredSearchResult{
+			{Title: "Effective Go", URL: "https://go.dev/doc/effective_go", Snippet: "idiomatic Go", Rank: 1},
+		}})
+		configs[tools.ToolNameSearch] = tools.ToolConfig{TimeoutIdle: time.Minute}
+	}
+	return &Engine{
+		db:               db,
+		llmClient:        caller,
+		modelRouter:      NewModelRouter("http://reasoning", "8b", "", ""),
+		toolRegistry:     tools.NewContextualRegistry(registry, configs),
+		actionTimeMargin: 30 * time.Second,
+		minActionTime:    time.Minute,
+	}, parser
+}
+
+func selfModGoal() *Goal {
+	return &Goal{ID: "goal_selfmod", SelfModGoal: &SelfModificationGoal{
+		TargetSlot:        5,
+		CurrentPrinciple:  "Answer quickly",
+		ProposedPrinciple: proposedTrialPrinciple,
+		Justification:     "Summaries led to shallow research",
+		TestActions: []Action{
+			{Tool: ActionToolSearch, Metadata: map[string]interface{}{"query": "effective go idioms"}},
+			{Tool: ActionToolSynthesis}, // Not search-rooted, so not measured
+		},
+		ValidationStatus: "pending",
+	}}
+}
+
+var trialPrinciples = []memory.Principle{
+	{Slot: 0, Content: "GrowerAI"},
+	{Slot: 5, Content: "Answer quickly"},
+}
+
+func TestTrialBranchSummaryAveragesMeasuredComponents(t *testing.T) {
+	tests := []struct {
+		name   string
+		totals trialBranchTotals
+		want   PrincipleTrialBranch
+	}{
+		{
+			name:   "nothing measured",
+			totals: trialBranchTotals{},
+			want:   PrincipleTrialBranch{},
+		},
+		{
+			name:   "search only",
+			totals: trialBranchTotals{trials: 2, searchConfSum: 1.2, searchEvals: 2, actionsRun: 2, actionsOK: 2},
+			want:   PrincipleTrialBranch{Trials: 2, SearchConfidence: 0.6, ActionSuccessRate: 1, Score: 0.8},
+		},
+		{
+			name: "search, parse and a failed parse",
+			totals: trialBranchTotals{trials: 2, searchConfSum: 1.6, searchEvals: 2,
+				parseQualitySum: 1.0, parseEvals: 2, actionsRun: 4, actionsOK: 3},
+			want: PrincipleTrialBranch{Trials: 2, SearchConfidence: 0.8, ParseQuality: 0.5, ActionSuccessRate: 0.75, Score: 0.6833},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.totals.summary()
+			if got.Trials != tt.want.Trials || !near(got.SearchConfidence, tt.want.SearchConfidence) ||
+				!near(got.ParseQuality, tt.want.ParseQuality) || !near(got.ActionSuccessRate, tt.want.ActionSuccessRate) ||
+				!near(got.Score, tt.want.Score) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func near(a, b float64) bool {
+	d := a - b
+	return d < 0.001 && d > -0.001
+}
+
+func TestPrincipleTrialScoresBranchesAndDecidesByMargin(t *testing.T) {
+	tests := []struct {
+		name       string
+		margin     float64
+		candidate  float64
+		quality    string
+		wantPassed bool
+		wantMargin float64
+	}{
+		{name: "clear win over the default margin", margin: 0, candidate: 0.9, quality: "sufficient", wantPassed: true, wantMargin: 0.05},
+		{name: "win short of a configured margin", margin: 0.3, candidate: 0.9, quality: "sufficient", wantPassed: false, wantMargin: 0.3},
+		{name: "small lead under the default margin", margin: 0, candidate: 0.66, quality: "parse_deeper", wantPassed: false, wantMargin: 0.05},
+		{name: "small lead with any win accepted", margin: -1, candidate: 0.66, quality: "parse_deeper", wantPassed: true, wantMargin: 0},
+		{name: "tie with any win accepted", margin: -1, candidate: 0.6, quality: "parse_deeper", wantPassed: false, wantMargin: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &branchCaller{
+				baselineConfidence: 0.6, baselineQuality: "parse_deeper",
+				candidateConfidence: tt.candidate, candidateQuality: tt.quality,
+			}
+			e, parser := principleTrialEngine(t, caller, true)
+			e.SetPrincipleTrialConfig(2, tt.margin)
+			goal := selfModGoal()
+			goal.SelfModGoal.TestActions = append(goal.SelfModGoal.TestActions,
+				Action{Tool: ActionToolSearch, Metadata: map[string]interface{}{"query": "go code review comments"}})
+
+			valid, reasoning := e.testPrincipleModification(context.Background(), goal, trialPrinciples)
+			trial := goal.SelfModGoal.Trial
+			if trial == nil || trial.Method != PrincipleTrialMethodEmpirical {
+				t.Fatalf("expected an empirical trial recorded, got %+v (%s)", trial, reasoning)
+			}
+			if valid != tt.wantPassed || trial.Passed != tt.wantPassed || trial.Margin != tt.wantMargin {
+				t.Errorf("expected passed=%v with margin %.2f, got %v and %+v", tt.wantPassed, tt.wantMargin, valid, trial)
+			}
+			// Two trials of two searches, each parsing the picked page once for both branches
+			if len(parser.visited) != 4 || trial.Baseline.Trials != 2 || trial.Candidate.Trials != 2 {
+				t.Errorf("expected two measured trials per branch, got %+v after %d parses", trial, len(parser.visited))
+			}
+			if !strings.Contains(trial.Reasoning, "over 2 trial(s)") {
+				t.Errorf("expected the trials counted in the reasoning, got %q", trial.Reasoning)
+			}
+			if !near(trial.Baseline.SearchConfidence, 0.6) || !near(trial.Baseline.ParseQuality, 0.6) ||
+				!near(trial.Baseline.Score, (0.6+0.6+1)/3) {
+				t.Errorf("expected the baseline scored on its own evaluations, got %+v", trial.Baseline)
+			}
+			want := (tt.candidate + parseQualityScores[tt.quality] + 1) / 3
+			if !near(trial.Candidate.SearchConfidence, tt.candidate) || !near(trial.Candidate.Score, want) {
+				t.Errorf("expected the candidate scored %.3f, got %+v", want, trial.Candidate)
+			}
+		})
+	}
+}
+
+func TestPrincipleTrialFallsBackToLLMValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		withSearch bool
+		principles []memory.Principle
+	}{
+		{name: "no search tool", withSearch: false, principles: trialPrinciples},
+		{name: "no principles loaded", withSearch: true, principles: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &branchCaller{validation: `(validation (is_valid true) (reasoning "primary sources deepen research"))`}
+			e, parser := principleTrialEngine(t, caller, tt.withSearch)
+			goal := selfModGoal()
+
+			valid, reasoning := e.testPrincipleModification(context.Background(), goal, tt.principles)
+			if !valid || reasoning != "Validated: primary sources deepen research" {
+				t.Errorf("expected the LLM's validation, got %v: %s", valid, reasoning)
+			}
+			trial := goal.SelfModGoal.Trial
+			if trial == nil || trial.Method != PrincipleTrialMethodLLMOnly || !trial.Passed || trial.Reasoning != reasoning {
+				t.Errorf("expected an llm_only trial recorded, got %+v", trial)
+			}
+			if len(parser.visited) != 0 || caller.calls != 1 {
+				t.Errorf("expected only the validation call, got %d calls and parses %v", caller.calls, parser.visited)
+			}
+		})
+	}
+}
@@ -0,0 +1,475 @@
+// internal/dialogue/prompts.go
+package dialogue
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"go-llama/internal/memory"
+)
+
+// Dialogue prompt templates. Each is a <name>.tmpl file under prompts/, rendered
+// together with the shared fragments in _fragments.tmpl. A template may define a
+// "system" block, which replaces the default system prompt for its call.
+const (
+	PromptResearchPlan        = "research_plan"
+	PromptGoalSupport         = "goal_support"
+	PromptGoalSupportBatch    = "goal_support_batch"
+	PromptAssessment          = "assessment"
+	PromptPrincipleEvaluation = "principle_evaluation"
+	PromptPrincipleValidation = "principle_validation"
+	PromptSynthesisReview     = "synthesis_review"
+	PromptPostMortem          = "post_mortem"
+	PromptReflection          = "reflection"
+
+	// System prompts: the persona of plain calls, and the structured reasoning prompt
+	// that places the principles around a call's instructions
+	PromptDialogueSystem  = "dialogue_system"
+	PromptReasoningSystem = "reasoning_system"
+)
+
+const (
+	promptFragments     = "_fragments"
+	promptSystemBlock   = "system"
+	promptTemplateExt   = ".tmpl"
+	promptHashLength    = 12
+	maxPromptStampChars = 500 // Response excerpt stored with the template stamp
+)
+
+//go:embed prompts/*.tmpl
+var defaultPromptFS embed.FS
+
+// Data rendered into each template
+type (
+	researchPlanPrompt struct {
+		Goal        string
+		PostMortems []string // Lessons from abandoned goals on the same topic
+	}
+	goalSupportPrompt struct {
+		Primaries []Goal
+		Secondary string
+	}
+	goalSupportBatchPrompt struct {
+		Primaries   []Goal
+		Secondaries []string
+	}
+	assessmentPrompt struct {
+		Goal      string
+		Completed string
+		Pending   string
+		Plan      string
+		Notes     string // The goal's notes from earlier cycles, if any
+	}
+	principleEvaluationPrompt struct {
+		Principles []memory.Principle // AI-managed slots only
+		Failures   []principleFailure
+		GoalCount  int
+	}
+	principleFailure struct {
+		Number      int
+		Description string
+		Source      string
+	}
+	principleValidationPrompt struct {
+		Slot          int
+		Current       string
+		Proposed      string
+		Justification string
+	}
+	synthesisReviewPrompt struct {
+		RootQuestion string
+		Questions    string // One line per sub-question with its status
+		Synthesis    string // Wrapped as untrusted; it is built from web content
+	}
+	postMortemPrompt struct {
+		Goal    string
+		Outcome string
+		Actions string // One line per action with its status and any failure
+	}
+	reflectionPrompt struct {
+		Principles string // Formatted by memory.FormatAsSystemPrompt
+		Memories   string
+		Goals      string
+		Tools      string
+		Depth      string // "deep", "moderate" or "conservative"
+	}
+	dialogueSystemPrompt  struct{}
+	reasoningSystemPrompt struct {
+		Principles   string // Formatted by memory.FormatAsSystemPrompt; empty if none loaded
+		Instructions string // The calling template's system block; empty for the default format
+	}
+)
+
+// promptSamples are the data each template must render with. An override is dry-run
+// against its sample when loaded, so a template that needs data the engine does not
+// pass fails at startup rather than mid-cycle.
+var promptSamples = map[string]interface{}{
+	PromptResearchPlan: researchPlanPrompt{Goal: "Learn Go generics", PostMortems: []string{"Blog posts predated generics; use the Go release notes"}},
+	PromptGoalSupport: goalSupportPrompt{
+		Primaries: []Goal{{ID: "goal_1", Description: "Build a web crawler"}},
+		Secondary: "Learn HTTP caching",
+	},
+	PromptGoalSupportBatch: goalSupportBatchPrompt{
+		Primaries:   []Goal{{ID: "goal_1", Description: "Build a web crawler"}},
+		Secondaries: []string{"Learn HTTP caching", "Study robots.txt"},
+	},
+	PromptAssessment: assessmentPrompt{Goal: "Learn Go generics", Completed: "1. search [x]\n", Pending: "1. web_parse_unified [y]\n", Plan: "Root Question: ?\n", Notes: "YOUR NOTES ON THIS GOAL (from earlier cycles):\n- Prefer the official docs (cycle #3)\n"},
+	PromptPrincipleEvaluation: principleEvaluationPrompt{
+		Principles: []memory.Principle{{Slot: 4, Content: "Verify sources"}},
+		Failures:   []principleFailure{{Number: 1, Description: "Research X", Source: "reflection"}},
+		GoalCount:  5,
+	},
+	PromptPrincipleValidation: principleValidationPrompt{Slot: 4, Current: "a", Proposed: "b", Justification: "c"},
+	PromptSynthesisReview:     synthesisReviewPrompt{RootQuestion: "How do bees navigate?", Questions: "1. [completed] Do bees use the sun?\n", Synthesis: "Bees use the sun as a compass."},
+	PromptPostMortem:          postMortemPrompt{Goal: "Learn Go generics", Outcome: "failed", Actions: "1. search [completed] Go generics\n2. web_parse_unified [failed: timeout] https://example.com\n"},
+	PromptReflection:          reflectionPrompt{Principles: "=== PRINCIPLES ===\n1. Be honest", Memories: "Recent memories:\n1. [good] x\n", Goals: "\nCurrent active goals: 0\n", Tools: "\nAvailable tools for creating actions:\n", Depth: "moderate"},
+	PromptDialogueSystem:      dialogueSystemPrompt{},
+	PromptReasoningSystem:     reasoningSystemPrompt{Principles: "=== PRINCIPLES ===\n1. Be honest", Instructions: "Output ONLY S-expressions."},
+}
+
+// promptRequiredFields are the data fields a template, its overrides included, must
+// use. Without them the engine's context would be silently dropped from the call.
+var promptRequiredFields = map[string][]string{
+	PromptReflection:      {"Principles", "Memories", "Goals", "Tools"},
+	PromptReasoningSystem: {"Principles", "Instructions"},
+}
+
+var promptFuncs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}
+
+// PromptRegistry holds the evaluator prompt templates, embedded defaults overlaid with
+// any overrides from a prompts directory
+type PromptRegistry struct {
+	templates map[string]*promptTemplate
+}
+
+type promptTemplate struct {
+	name   string
+	hash   string // Of the template and the fragments it was parsed with
+	origin string // "embedded" or the override's path
+	tmpl   *template.Template
+}
+
+// RenderedPrompt is a rendered prompt and the template version that produced it
+type RenderedPrompt struct {
+	Text     string
+	System   string // Empty unless the template defines a system block
+	Template string // name@hash
+}
+
+var (
+	defaultPromptsOnce sync.Once
+	defaultPrompts     *PromptRegistry
+)
+
+// DefaultPromptRegistry returns the embedded templates
+func DefaultPromptRegistry() *PromptRegistry {
+	defaultPromptsOnce.Do(func() {
+		registry, err := LoadPromptRegistry("")
+		if err != nil {
+			panic(fmt.Sprintf("embedded prompt templates are invalid: %v", err))
+		}
+		defaultPrompts = registry
+	})
+	return defaultPrompts
+}
+
+// LoadPromptRegistry loads the embedded templates, replacing any that have a file of the
+// same name in overrideDir. A missing directory means no overrides. Unknown files,
+// templates that do not parse and templates that use data the engine does not provide
+// are errors.
+func LoadPromptRegistry(overrideDir string) (*PromptRegistry, error) {
+	sources := make(map[string]string)
+	origins := make(map[string]string)
+	entries, err := fs.Glob(defaultPromptFS, "prompts/*"+promptTemplateExt)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range entries {
+		data, err := defaultPromptFS.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), promptTemplateExt)
+		sources[name], origins[name] = string(data), "embedded"
+	}
+
+	if overrideDir != "" {
+		files, err := os.ReadDir(overrideDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read prompts directory: %w", err)
+		}
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != promptTemplateExt {
+				continue
+			}
+			name := strings.TrimSuffix(file.Name(), promptTemplateExt)
+			if _, known := sources[name]; !known {
+				return nil, fmt.Errorf("prompt override %s does not match a known template", file.Name())
+			}
+			path := filepath.Join(overrideDir, file.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read prompt override: %w", err)
+			}
+			sources[name], origins[name] = string(data), path
+		}
+	}
+
+	registry := &PromptRegistry{templates: make(map[string]*promptTemplate)}
+	fragments := sources[promptFragments]
+	for name, sample := range promptSamples {
+		source, ok := sources[name]
+		if !ok {
+			return nil, fmt.Errorf("prompt template %s is missing", name)
+		}
+		pt, err := parsePromptTemplate(name, source, fragments, sample, promptRequiredFields[name])
+		if err != nil {
+			origin := origins[name]
+			if origins[promptFragments] != "embedded" {
+				origin += " with " + origins[promptFragments]
+			}
+			return nil, fmt.Errorf("prompt template %s (%s): %w", name, origin, err)
+		}
+		pt.origin = origins[name]
+		registry.templates[name] = pt
+	}
+	return registry, nil
+}
+
+// parsePromptTemplate parses a template with the shared fragments and checks it against
+// the data it will be rendered with and the fields it is required to use
+func parsePromptTemplate(name, source, fragments string, sample interface{}, required []string) (*promptTemplate, error) {
+	set, err := template.New(promptFragments).Funcs(promptFuncs).Option("missingkey=error").Parse(fragments)
+	if err != nil {
+		return nil, fmt.Errorf("fragments: %w", err)
+	}
+	tmpl, err := set.New(name).Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields a branch refers to must exist even if the sample does not reach it
+	dataType := reflect.TypeOf(sample)
+	used := make(map[string]bool)
+	for _, t := range []*template.Template{tmpl, tmpl.Lookup(promptSystemBlock)} {
+		if t == nil || t.Tree == nil {
+			continue
+		}
+		if err := checkPromptFields(t.Tree.Root, dataType, true, used); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range required {
+		if !used[field] {
+			return nil, fmt.Errorf("template must use .%s", field)
+		}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, sample); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(source + "\x00" + fragments))
+	return &promptTemplate{name: name, hash: hex.EncodeToString(sum[:])[:promptHashLength], tmpl: tmpl}, nil
+}
+
+// checkPromptFields reports a field the data type does not have, and records the fields
+// read in used. Only fields read from the template's own data are checked: inside range
+// and with, dot is something else and is left to the dry run.
+func checkPromptFields(node parse.Node, dataType reflect.Type, rootDot bool, used map[string]bool) error {
+	checkField := func(ident []string) error {
+		if len(ident) == 0 {
+			return nil
+		}
+		used[ident[0]] = true
+		t := dataType
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		if _, ok := t.FieldByName(ident[0]); !ok {
+			return fmt.Errorf("template uses .%s, which %s does not provide", ident[0], t.Name())
+		}
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkPromptFields(child, dataType, rootDot, used); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkPromptFields(n.Pipe, dataType, rootDot, used)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkPromptFields(arg, dataType, rootDot, used); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.FieldNode:
+		if rootDot {
+			return checkField(n.Ident)
+		}
+	case *parse.VariableNode:
+		// $ is always the template's own data
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return checkField(n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		return checkPromptFields(n.Node, dataType, rootDot, used)
+	case *parse.TemplateNode:
+		return checkPromptFields(n.Pipe, dataType, rootDot, used)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode, dataType, rootDot, rootDot, used)
+	case *parse.RangeNode:
+		return checkBranch(&n.BranchNode, dataType, rootDot, false, used)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode, dataType, rootDot, false, used)
+	}
+	return nil
+}
+
+func checkBranch(n *parse.BranchNode, dataType reflect.Type, rootDot, listRootDot bool, used map[string]bool) error {
+	if err := checkPromptFields(n.Pipe, dataType, rootDot, used); err != nil {
+		return err
+	}
+	if err := checkPromptFields(n.List, dataType, listRootDot, used); err != nil {
+		return err
+	}
+	return checkPromptFields(n.ElseList, dataType, rootDot, used)
+}
+
+// Render executes a template with data
+func (r *PromptRegistry) Render(name string, data interface{}) (RenderedPrompt, error) {
+	pt, ok := r.templates[name]
+	if !ok {
+		return RenderedPrompt{}, fmt.Errorf("unknown prompt template %s", name)
+	}
+	var buf bytes.Buffer
+	if err := pt.tmpl.Execute(&buf, data); err != nil {
+		return RenderedPrompt{}, fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	rendered := RenderedPrompt{
+		Text:     strings.TrimSpace(buf.String()),
+		Template: pt.name + "@" + pt.hash,
+	}
+	if system := pt.tmpl.Lookup(promptSystemBlock); system != nil {
+		buf.Reset()
+		if err := system.Execute(&buf, data); err != nil {
+			return RenderedPrompt{}, fmt.Errorf("failed to render system prompt %s: %w", name, err)
+		}
+		rendered.System = strings.TrimSpace(buf.String())
+	}
+	return rendered, nil
+}
+
+// Versions lists each template as name@hash with where it was loaded from
+func (r *PromptRegistry) Versions() []string {
+	versions := make([]string, 0, len(r.templates))
+	for _, pt := range r.templates {
+		versions = append(versions, fmt.Sprintf("%s@%s (%s)", pt.name, pt.hash, pt.origin))
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// SetPromptRegistry replaces the embedded evaluator prompts
+func (e *Engine) SetPromptRegistry(registry *PromptRegistry) {
+	e.prompts = registry
+	for _, version := range registry.Versions() {
+		log.Printf("[Dialogue] Prompt template %s", version)
+	}
+}
+
+func (e *Engine) promptRegistry() *PromptRegistry {
+	if e.prompts != nil {
+		return e.prompts
+	}
+	return DefaultPromptRegistry()
+}
+
+// callPrompt renders a prompt template and makes a structured call with it. The template
+// version is stamped into the thought history with an excerpt of the response, and
+// counted in the cycle's metrics.
+func (e *Engine) callPrompt(ctx context.Context, name string, data interface{}, expectJSON bool, callType LLMCallType) (*ReasoningResponse, int, error) {
+	rendered, err := e.promptRegistry().Render(name, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	response, tokens, err := e.callLLMWithStructuredReasoning(ctx, rendered.Text, expectJSON, rendered.System, callType)
+	e.recordPromptUse(rendered.Template)
+
+	excerpt := ""
+	if err != nil {
+		excerpt = "error: " + err.Error()
+	} else if response != nil {
+		excerpt = truncate(response.RawResponse, maxPromptStampChars)
+	}
+	e.saveThought(ctx, &ThoughtRecord{
+		CycleID:        int(e.currentCycle.Load()),
+		Content:        fmt.Sprintf("[%s] %s", rendered.Template, excerpt),
+		TokensUsed:     tokens,
+		PromptTemplate: rendered.Template,
+		Timestamp:      time.Now(),
+	})
+	return response, tokens, err
+}
+
+// renderSystemPrompt renders a system prompt template. Templates are checked when they
+// load, so a failure here is logged and the call goes ahead with what was rendered.
+func (e *Engine) renderSystemPrompt(name string, data interface{}) string {
+	rendered, err := e.promptRegistry().Render(name, data)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: %v", err)
+	}
+	return rendered.Text
+}
+
+// recordPromptUse counts a structured call by template version
+func (e *Engine) recordPromptUse(version string) {
+	e.promptUsesMu.Lock()
+	defer e.promptUsesMu.Unlock()
+	if e.promptUses == nil {
+		e.promptUses = make(map[string]int)
+	}
+	e.promptUses[version]++
+}
+
+// takePromptUses returns the template versions used since the last call and resets them
+func (e *Engine) takePromptUses() map[string]int {
+	e.promptUsesMu.Lock()
+	defer e.promptUsesMu.Unlock()
+	uses := e.promptUses
+	e.promptUses = nil
+	return uses
+}
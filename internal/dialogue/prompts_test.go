@@ -0,0 +1,168 @@
+package dialogue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultPromptsRender(t *testing.T) {
+	registry := DefaultPromptRegistry()
+
+	plan, err := registry.Render(PromptResearchPlan, researchPlanPrompt{Goal: "Learn Go generics"})
+	if err != nil || !strings.Contains(plan.Text, "GOAL: Learn Go generics") || !strings.HasPrefix(plan.Template, "research_plan@") {
+		t.Fatalf("unexpected research plan prompt %+v (%v)", plan, err)
+	}
+
+	batch, err := registry.Render(PromptGoalSupportBatch, goalSupportBatchPrompt{
+		Primaries:   []Goal{{ID: "goal_1", Description: "Build a crawler"}},
+		Secondaries: []string{"Learn caching", "Study robots.txt"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1. [ID: goal_1] Build a crawler", "2. Study robots.txt", "each of the 2 secondary goals",
+		"set its is_valid to false", "Removes blockers", "Output ONLY the S-expression, no markdown"} {
+		if !strings.Contains(batch.Text, want) {
+			t.Errorf("expected the batch prompt to contain %q:\n%s", want, batch.Text)
+		}
+	}
+
+	assessment, err := registry.Render(PromptAssessment, assessmentPrompt{Goal: "g"})
+	if err != nil || !strings.HasPrefix(assessment.System, "Output ONLY S-expressions") || strings.Contains(assessment.Text, "Format:") {
+		t.Errorf("expected the assessment system block split from the prompt, got %+v (%v)", assessment, err)
+	}
+
+	evaluation, err := registry.Render(PromptPrincipleEvaluation, principleEvaluationPrompt{GoalCount: 4})
+	if err != nil || !strings.Contains(evaluation.Text, "No AI-managed principles defined yet.") || !strings.Contains(evaluation.Text, "(0 out of last 4 goals)") {
+		t.Errorf("unexpected principle evaluation prompt %q (%v)", evaluation.Text, err)
+	}
+}
+
+func TestPromptOverrides(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := LoadPromptRegistry(filepath.Join(dir, "missing")); err != nil {
+		t.Fatalf("expected a missing prompts directory to mean no overrides, got %v", err)
+	}
+
+	write("research_plan.tmpl", "Plan research for {{.Goal}}. {{template \"sexpr_only\"}}")
+	registry, err := LoadPromptRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := registry.Render(PromptResearchPlan, researchPlanPrompt{Goal: "chess"})
+	want, _ := DefaultPromptRegistry().Render(PromptResearchPlan, researchPlanPrompt{Goal: "chess"})
+	if got.Text != "Plan research for chess. Output ONLY the S-expression, no markdown" || got.Template == want.Template {
+		t.Errorf("expected the override rendered under a new hash, got %+v (default %s)", got, want.Template)
+	}
+
+	// Variables the engine does not pass fail at load, even in a branch the sample skips
+	write("research_plan.tmpl", "{{if false}}{{.Deadline}}{{end}}Plan {{.Goal}}")
+	if _, err := LoadPromptRegistry(dir); err == nil || !strings.Contains(err.Error(), ".Deadline") {
+		t.Errorf("expected the missing variable rejected, got %v", err)
+	}
+	write("research_plan.tmpl", "Plan {{.Goal}}")
+
+	write("assessment.tmpl", "{{.Goal}}{{define \"system\"}}{{.Verdict}}{{end}}")
+	if _, err := LoadPromptRegistry(dir); err == nil {
+		t.Error("expected a missing variable in the system block rejected")
+	}
+	os.Remove(filepath.Join(dir, "assessment.tmpl"))
+
+	write("_fragments.tmpl", `{{define "primary_goals"}}{{range .}}{{.Title}}{{end}}{{end}}`)
+	if _, err := LoadPromptRegistry(dir); err == nil {
+		t.Error("expected a fragments override that breaks a template rejected")
+	}
+	os.Remove(filepath.Join(dir, "_fragments.tmpl"))
+
+	write("reserch_plan.tmpl", "typo")
+	if _, err := LoadPromptRegistry(dir); err == nil {
+		t.Error("expected an unknown override rejected")
+	}
+}
+
+func TestPromptUsesAreTakenPerCycle(t *testing.T) {
+	e := &Engine{}
+	e.recordPromptUse("research_plan@abc")
+	e.recordPromptUse("research_plan@abc")
+	e.recordPromptUse("assessment@def")
+	if uses := e.takePromptUses(); uses["research_plan@abc"] != 2 || uses["assessment@def"] != 1 {
+		t.Errorf("unexpected prompt uses %v", uses)
+	}
+	if uses := e.takePromptUses(); len(uses) != 0 {
+		t.Errorf("expected uses reset after being taken, got %v", uses)
+	}
+}
+
+func TestSystemPromptTemplates(t *testing.T) {
+	registry := DefaultPromptRegistry()
+
+	reasoning, err := registry.Render(PromptReasoningSystem, reasoningSystemPrompt{Principles: "=== PRINCIPLES ==="})
+	if err != nil || !strings.HasPrefix(reasoning.Text, "=== PRINCIPLES ===\n\nOutput ONLY S-expressions") || !strings.Contains(reasoning.Text, "CRITICAL ASSESSMENT RULES") {
+		t.Errorf("expected the principles before the default format, got %q (%v)", reasoning.Text, err)
+	}
+	assessment, _ := registry.Render(PromptReasoningSystem, reasoningSystemPrompt{Principles: "=== PRINCIPLES ===", Instructions: "Format: (assessment)"})
+	if assessment.Text != "=== PRINCIPLES ===\n\nFormat: (assessment)" {
+		t.Errorf("expected a call's instructions to replace the default format, got %q", assessment.Text)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "dialogue_system.tmpl"), []byte("You are Sprout's inner voice."), 0o644)
+	os.WriteFile(filepath.Join(dir, "reasoning_system.tmpl"), []byte("{{.Instructions}}\n\nValues:\n{{.Principles}}"), 0o644)
+	overridden, err := LoadPromptRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &Engine{}
+	e.SetPromptRegistry(overridden)
+	if got := e.renderSystemPrompt(PromptDialogueSystem, dialogueSystemPrompt{}); got != "You are Sprout's inner voice." {
+		t.Errorf("expected the persona override, got %q", got)
+	}
+	if got := e.renderSystemPrompt(PromptReasoningSystem, reasoningSystemPrompt{Principles: "P", Instructions: "I"}); got != "I\n\nValues:\nP" {
+		t.Errorf("expected the principles positioned by the override, got %q", got)
+	}
+}
+
+func TestPromptOverridesMustUseRequiredFields(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("reflection.tmpl", "{{.Memories}}{{.Goals}}{{.Tools}} Reflect.")
+	if _, err := LoadPromptRegistry(dir); err == nil || !strings.Contains(err.Error(), ".Principles") {
+		t.Errorf("expected a reflection override without the principles rejected, got %v", err)
+	}
+	write("reflection.tmpl", "{{.Memories}}{{.Goals}}{{.Tools}}\n\n{{if .Principles}}Stay true to:\n{{.Principles}}{{end}}")
+	if _, err := LoadPromptRegistry(dir); err != nil {
+		t.Errorf("expected the principles allowed after the context, got %v", err)
+	}
+
+	write("reasoning_system.tmpl", "{{.Principles}} Output S-expressions.")
+	if _, err := LoadPromptRegistry(dir); err == nil || !strings.Contains(err.Error(), ".Instructions") {
+		t.Errorf("expected a reasoning system override that drops call instructions rejected, got %v", err)
+	}
+}
+
+func TestReflectionPromptFollowsDepth(t *testing.T) {
+	registry := DefaultPromptRegistry()
+	data := reflectionPrompt{Principles: "=== PRINCIPLES ===", Memories: "Recent memories:\n", Goals: "\nCurrent active goals: 0\n", Tools: "\nAvailable tools:\n"}
+	for depth, want := range map[string]string{"deep": "Perform deep analysis:", "moderate": "Analyze recent activity:", "conservative": "Brief analysis:", "": "Brief analysis:"} {
+		data.Depth = depth
+		rendered, err := registry.Render(PromptReflection, data)
+		if err != nil || !strings.HasPrefix(rendered.Text, "=== PRINCIPLES ===\n\nRecent memories:") || !strings.Contains(rendered.Text, want) {
+			t.Errorf("depth %q: expected %q after the context, got %q (%v)", depth, want, rendered.Text, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// internal/dialogue/readiness.go
+package dialogue
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"go-llama/internal/health"
+)
+
+// SkippedCycleStats counts cycles skipped because a required dependency was down, the
+// cycle lock was held by a cycle still running or the token budget was spent
+type SkippedCycleStats struct {
+	Total         int64            `json:"total"`
+	ByDependency  map[string]int64 `json:"by_dependency"`
+	Locked        int64            `json:"locked"` // Ticks that could not take the cycle lock
+	Budget        int64            `json:"budget"` // Ticks paused at the hard token budget limit
+	LastReason    string           `json:"last_reason,omitempty"`
+	LastSkippedAt *time.Time       `json:"last_skipped_at,omitempty"`
+}
+
+// SetReadiness makes the engine consult checker's cached snapshot before each cycle and
+// skip the cycle while any of the required dependencies is down
+func (e *Engine) SetReadiness(checker *health.Checker, required []string) {
+	e.readiness = checker
+	e.readinessDeps = required
+}
+
+// unavailableDependencies lists the required dependencies currently down
+func (e *Engine) unavailableDependencies(ctx context.Context) []string {
+	if e.readiness == nil {
+		return nil
+	}
+	return e.readiness.Cached(ctx).Unhealthy(e.readinessDeps...)
+}
+
+// recordSkippedCycle counts and reports a cycle skipped for the given dependencies
+func (e *Engine) recordSkippedCycle(down []string) {
+	reason := "unavailable: " + strings.Join(down, ", ")
+	now := time.Now()
+
+	e.skipMu.Lock()
+	if e.skipStats.ByDependency == nil {
+		e.skipStats.ByDependency = make(map[string]int64)
+	}
+	e.skipStats.Total++
+	for _, name := range down {
+		e.skipStats.ByDependency[name]++
+	}
+	e.skipStats.LastReason = reason
+	e.skipStats.LastSkippedAt = &now
+	total := e.skipStats.Total
+	e.skipMu.Unlock()
+
+	log.Printf("[Dialogue] Skipping cycle, required dependencies %s (%d skipped so far)", reason, total)
+	e.publishEvent(EventCycleSkipped, "", "", map[string]interface{}{"unavailable": down})
+}
+
+// SkippedCycles reports how many cycles were skipped for unavailable dependencies, a
+// held cycle lock or the token budget
+func (e *Engine) SkippedCycles() SkippedCycleStats {
+	e.skipMu.Lock()
+	defer e.skipMu.Unlock()
+	stats := e.skipStats
+	stats.ByDependency = make(map[string]int64, len(e.skipStats.ByDependency))
+	for name, count := range e.skipStats.ByDependency {
+		stats.ByDependency[name] = count
+	}
+	return stats
+}
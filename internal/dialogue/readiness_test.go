@@ -0,0 +1,34 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-llama/internal/health"
+)
+
+func TestUnavailableDependenciesSkipsOnlyForRequired(t *testing.T) {
+	checker := health.NewChecker(time.Second, time.Minute)
+	checker.Register(health.Check{Name: health.DependencyQdrant, Probe: func(ctx context.Context) error { return errors.New("down") }})
+	checker.Register(health.Check{Name: health.DependencySearXNG, Probe: func(ctx context.Context) error { return errors.New("down") }})
+
+	e := &Engine{}
+	if down := e.unavailableDependencies(context.Background()); down != nil {
+		t.Fatalf("expected no gate without a checker, got %v", down)
+	}
+
+	e.SetReadiness(checker, []string{health.DependencyPostgres, health.DependencyQdrant})
+	down := e.unavailableDependencies(context.Background())
+	if len(down) != 1 || down[0] != health.DependencyQdrant {
+		t.Fatalf("expected only qdrant to block the cycle, got %v", down)
+	}
+
+	e.recordSkippedCycle(down)
+	e.recordSkippedCycle(down)
+	stats := e.SkippedCycles()
+	if stats.Total != 2 || stats.ByDependency[health.DependencyQdrant] != 2 || stats.LastReason == "" {
+		t.Errorf("unexpected skip stats %+v", stats)
+	}
+}
@@ -0,0 +1,98 @@
+// internal/dialogue/reasoning_depth.go
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reasoning depths the reflection prompt is written for. ReasoningDepthAuto picks one
+// of the others each cycle.
+const (
+	ReasoningDepthDeep         = "deep"
+	ReasoningDepthModerate     = "moderate"
+	ReasoningDepthConservative = "conservative"
+	ReasoningDepthAuto         = "auto"
+)
+
+// autoDeepInterval is how long auto depth goes without a deep reflection before it
+// chooses one even though goals are active
+const autoDeepInterval = 12 * time.Hour
+
+// depthSignals are what auto depth is chosen from
+type depthSignals struct {
+	PendingActions int
+	ActiveGoals    int
+	SinceDeep      time.Duration // Time since the last deep reflection; ignored unless HadDeep
+	HadDeep        bool          // A deep reflection is on record
+	OverBudget     bool          // The daily token budget is past its soft limit
+}
+
+// selectReasoningDepth chooses a reflection depth and says why. A tight budget always
+// saves tokens; otherwise an empty goal list or a long gap since the last deep reflection
+// earns a deep one, and a cycle with pending actions to execute keeps reflection brief.
+func selectReasoningDepth(s depthSignals) (string, string) {
+	switch {
+	case s.OverBudget:
+		return ReasoningDepthConservative, "daily token budget is past its soft limit"
+	case s.ActiveGoals == 0:
+		return ReasoningDepthDeep, "no active goals"
+	case !s.HadDeep:
+		return ReasoningDepthDeep, "no deep reflection on record"
+	case s.SinceDeep >= autoDeepInterval:
+		return ReasoningDepthDeep, fmt.Sprintf("last deep reflection was %s ago", s.SinceDeep.Round(time.Minute))
+	case s.PendingActions > 0:
+		return ReasoningDepthConservative, fmt.Sprintf("%d pending actions to execute", s.PendingActions)
+	}
+	return ReasoningDepthModerate, fmt.Sprintf("%d active goals with no pending actions", s.ActiveGoals)
+}
+
+// reflectionDepth returns the depth this cycle's reflection runs at: the configured one,
+// or with "auto" one chosen from the state, the budget and the last deep reflection
+func (e *Engine) reflectionDepth(ctx context.Context, state *InternalState) string {
+	if e.reasoningDepth != ReasoningDepthAuto {
+		return e.reasoningDepth
+	}
+
+	signals := depthSignals{
+		ActiveGoals: len(state.ActiveGoals),
+		OverBudget:  e.tokenBudget != nil && e.tokenBudget.OverSoftLimit(),
+	}
+	for _, goal := range state.ActiveGoals {
+		for _, action := range goal.Actions {
+			if action.Status == ActionStatusPending {
+				signals.PendingActions++
+			}
+		}
+	}
+	if e.stateManager != nil {
+		last, ok, err := e.stateManager.LastCycleAtDepth(ctx, ReasoningDepthDeep)
+		if err != nil {
+			log.Printf("[Dialogue] WARNING: %v", err)
+		}
+		signals.HadDeep, signals.SinceDeep = ok, time.Since(last)
+	}
+
+	depth, reason := selectReasoningDepth(signals)
+	log.Printf("[Dialogue] Auto reasoning depth: %s (%s; %d active goals, %d pending actions)",
+		depth, reason, signals.ActiveGoals, signals.PendingActions)
+	return depth
+}
+
+// LastCycleAtDepth returns when the most recent cycle that reflected at depth started
+func (sm *StateManager) LastCycleAtDepth(ctx context.Context, depth string) (time.Time, bool, error) {
+	var cycle DialogueMetrics
+	err := sm.db.WithContext(ctx).Where("reasoning_depth = ?", depth).Order("cycle_id DESC").First(&cycle).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to find last %s reflection: %w", depth, err)
+	}
+	return cycle.StartTime, true, nil
+}
@@ -0,0 +1,65 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSelectReasoningDepth(t *testing.T) {
+	for name, tc := range map[string]struct {
+		signals depthSignals
+		want    string
+	}{
+		"over budget":         {depthSignals{OverBudget: true}, ReasoningDepthConservative},
+		"no goals":            {depthSignals{HadDeep: true, SinceDeep: time.Minute}, ReasoningDepthDeep},
+		"never deep":          {depthSignals{ActiveGoals: 2, PendingActions: 1}, ReasoningDepthDeep},
+		"deep long ago":       {depthSignals{ActiveGoals: 2, PendingActions: 1, HadDeep: true, SinceDeep: 13 * time.Hour}, ReasoningDepthDeep},
+		"pending actions":     {depthSignals{ActiveGoals: 2, PendingActions: 1, HadDeep: true, SinceDeep: time.Hour}, ReasoningDepthConservative},
+		"goals, none pending": {depthSignals{ActiveGoals: 2, HadDeep: true, SinceDeep: time.Hour}, ReasoningDepthModerate},
+	} {
+		if got, reason := selectReasoningDepth(tc.signals); got != tc.want || reason == "" {
+			t.Errorf("%s: expected %s with a reason, got %s (%q)", name, tc.want, got, reason)
+		}
+	}
+}
+
+func TestAutoDepthReadsLastDeepReflectionFromMetrics(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&DialogueMetrics{}); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewStateManager(db)
+	e := &Engine{reasoningDepth: ReasoningDepthAuto, stateManager: sm}
+	state := &InternalState{ActiveGoals: []Goal{{ID: "goal_a", Actions: []Action{{Status: ActionStatusPending}}}}}
+
+	if got := e.reflectionDepth(ctx, state); got != ReasoningDepthDeep {
+		t.Errorf("expected deep with none on record, got %s", got)
+	}
+
+	if err := sm.SaveMetrics(ctx, &CycleMetrics{CycleID: 1, StartTime: time.Now().Add(-time.Hour), ReasoningDepth: ReasoningDepthDeep}); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.reflectionDepth(ctx, state); got != ReasoningDepthConservative {
+		t.Errorf("expected conservative with a pending action after a recent deep reflection, got %s", got)
+	}
+
+	e.tokenBudget = &fixedBudget{soft: true}
+	state.ActiveGoals = nil
+	if got := e.reflectionDepth(ctx, state); got != ReasoningDepthConservative {
+		t.Errorf("expected the budget to outweigh an empty goal list, got %s", got)
+	}
+
+	// A configured depth is used as is
+	e.reasoningDepth = ReasoningDepthModerate
+	if got := e.reflectionDepth(ctx, state); got != ReasoningDepthModerate {
+		t.Errorf("expected the configured depth, got %s", got)
+	}
+}
@@ -0,0 +1,288 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-llama/internal/tools"
+)
+
+// Defaults for a quick answer when the caller leaves a budget field unset
+const (
+	DefaultResearchNowTimeout   = 90 * time.Second
+	DefaultResearchNowMaxTokens = 6000
+	MaxResearchNowPages         = 2
+)
+
+// researchNowSynthesisShare is the part of the time budget kept back for synthesis, so
+// slow pages cannot use up the time needed to turn them into an answer
+const researchNowSynthesisShare = 0.25
+
+// researchNowExcerptLength caps how much of each parsed page the synthesis sees
+const researchNowExcerptLength = 1500
+
+// Stages a quick answer can stop at when its budget runs out
+const (
+	ResearchStageEvaluation = "evaluation"
+	ResearchStageParse      = "parse"
+	ResearchStageSynthesis  = "synthesis"
+)
+
+// ResearchBudget bounds a quick answer. Zero fields take the defaults.
+type ResearchBudget struct {
+	MaxTokens int           // LLM tokens for URL selection and synthesis
+	Timeout   time.Duration // Wall-clock time for the whole answer
+	MaxPages  int           // Pages to parse, 1 or 2
+}
+
+func (b ResearchBudget) withDefaults() ResearchBudget {
+	if b.MaxTokens <= 0 {
+		b.MaxTokens = DefaultResearchNowMaxTokens
+	}
+	if b.Timeout <= 0 {
+		b.Timeout = DefaultResearchNowTimeout
+	}
+	if b.MaxPages <= 0 || b.MaxPages > MaxResearchNowPages {
+		b.MaxPages = MaxResearchNowPages
+	}
+	return b
+}
+
+// ResearchAnswer is the result of ResearchNow. Partial is set when the budget ran out
+// before synthesis; Answer then holds the findings gathered so far and StoppedAt names
+// the stage that did not run.
+type ResearchAnswer struct {
+	Question   string              `json:"question"`
+	Answer     string              `json:"answer"`
+	Sources    []map[string]string `json:"sources"`
+	Partial    bool                `json:"partial"`
+	StoppedAt  string              `json:"stopped_at,omitempty"`
+	TokensUsed int                 `json:"tokens_used"`
+	Duration   time.Duration       `json:"duration_ns"`
+	Stored     bool                `json:"stored"`
+}
+
+// researchNowRun tracks a quick answer's spending against its budget
+type researchNowRun struct {
+	budget ResearchBudget
+	tokens int
+}
+
+// exhausted reports why the next stage cannot run, or "" if it can
+func (r *researchNowRun) exhausted(ctx context.Context) string {
+	if ctx.Err() != nil {
+		return "time budget exhausted"
+	}
+	if r.tokens >= r.budget.MaxTokens {
+		return fmt.Sprintf("token budget exhausted (%d of %d)", r.tokens, r.budget.MaxTokens)
+	}
+	return ""
+}
+
+// ResearchNow answers a user's question within budget: one search, the LLM's pick of
+// the best one or two results, a contextual parse of each with the question as its
+// purpose, and one synthesis. The synthesis is stored like any research synthesis.
+// When the budget runs out first, the findings gathered so far are returned as a
+// partial answer; an error means nothing at all was found.
+func (e *Engine) ResearchNow(ctx context.Context, question string, budget ResearchBudget) (*ResearchAnswer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, fmt.Errorf("question is empty")
+	}
+	budget = budget.withDefaults()
+	run := &researchNowRun{budget: budget}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(outsideCycle(ctx), budget.Timeout)
+	defer cancel()
+
+	now := time.Now()
+	goal := &Goal{
+		ID:          fmt.Sprintf("research_now_%d", now.UnixNano()),
+		Description: question,
+		Source:      GoalSourceUserRequest,
+		Priority:    10,
+		Created:     now,
+		Status:      GoalStatusActive,
+		LastPursued: now,
+		ResearchPlan: &ResearchPlan{
+			RootQuestion: question,
+			SubQuestions: []ResearchQuestion{{
+				ID:          "q1",
+				Question:    question,
+				SearchQuery: question,
+				Status:      ResearchStatusInProgress,
+				Priority:    10,
+			}},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	answer := &ResearchAnswer{Question: question, Sources: []map[string]string{}}
+	finish := func() *ResearchAnswer {
+		answer.TokensUsed = run.tokens
+		answer.Duration = time.Since(start)
+		return answer
+	}
+	log.Printf("[ResearchNow] Answering %q (budget: %d tokens, %s, %d page(s))",
+		truncate(question, 80), budget.MaxTokens, budget.Timeout, budget.MaxPages)
+
+	// 1. One search
+	search := Action{
+		ID:          newActionID(),
+		Description: question,
+		Tool:        ActionToolSearch,
+		Status:      ActionStatusInProgress,
+		Timestamp:   time.Now(),
+		Metadata:    map[string]interface{}{MetadataGoalID: goal.ID},
+	}
+	searchOutput, err := e.executeAction(ctx, &search)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	search.Status = ActionStatusCompleted
+	e.recordActionResult(ctx, goal.ID, &search, searchOutput)
+	goal.Actions = append(goal.Actions, search)
+	results := tools.DecodeSearchResults(search.Metadata[MetadataSearchResults])
+	urls, _ := search.Metadata["extracted_urls"].([]string)
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("search found no pages to read")
+	}
+
+	// 2. Pick the pages to read; without the LLM's pick the top results are read
+	if reason := run.exhausted(ctx); reason != "" {
+		return finish().stop(ResearchStageEvaluation, reason, searchFindings(results, searchOutput)), nil
+	}
+	picked, confidence := urls, 0.5
+	evaluation, tokens, err := e.evaluateSearchResults(ctx, searchOutput, results, question, "")
+	run.tokens += tokens
+	if err != nil {
+		log.Printf("[ResearchNow] WARNING: Search evaluation failed, reading top results: %v", err)
+	} else if evaluation.BestURL != "" {
+		picked = append([]string{evaluation.BestURL}, evaluation.FallbackURLs...)
+		confidence = clampConfidence(evaluation.Confidence)
+	}
+	if len(picked) > budget.MaxPages {
+		picked = picked[:budget.MaxPages]
+	}
+
+	// 3. Parse each page with the question as its purpose, keeping time for synthesis
+	parseCtx, cancelParse := context.WithDeadline(ctx, start.Add(time.Duration(float64(budget.Timeout)*(1-researchNowSynthesisShare))))
+	defer cancelParse()
+	var excerpts []string
+	for _, url := range picked {
+		if parseCtx.Err() != nil {
+			break
+		}
+		parse := Action{
+			ID:          newActionID(),
+			Description: "Read " + url,
+			Tool:        ActionToolWebParseUnified,
+			Status:      ActionStatusInProgress,
+			Timestamp:   time.Now(),
+			Metadata: map[string]interface{}{
+				MetadataGoalID: goal.ID,
+				"selected_url": url,
+				"goal":         question,
+			},
+			DependsOn: []string{search.ID},
+		}
+		output, err := e.executeAction(parseCtx, &parse)
+		noteActionOutcome(goal, err != nil)
+		if err != nil {
+			log.Printf("[ResearchNow] WARNING: Failed to read %s: %v", truncate(url, 60), err)
+			continue
+		}
+		parse.Status = ActionStatusCompleted
+		if err := e.updateResearchProgress(ctx, goal, "q1", &parse, output, confidence); err != nil {
+			log.Printf("[ResearchNow] WARNING: Failed to record findings: %v", err)
+		}
+		goal.Actions = append(goal.Actions, parse)
+		excerpts = append(excerpts, truncate(output, researchNowExcerptLength))
+	}
+	answer.Sources = citedSources(goal)
+	if len(excerpts) == 0 {
+		return finish().stop(ResearchStageParse, "no page could be read in time", searchFindings(results, searchOutput)), nil
+	}
+
+	// updateResearchProgress keeps a short preview as the finding; a quick answer has
+	// only this one question, so the synthesis gets the excerpts themselves
+	goal.ResearchPlan.SubQuestions[0].KeyFindings = strings.Join(excerpts, "\n\n")
+	findings := pageFindings(excerpts, answer.Sources)
+
+	// 4. One synthesis
+	if reason := run.exhausted(ctx); reason != "" {
+		return finish().stop(ResearchStageSynthesis, reason, findings), nil
+	}
+	synthesis, tokens, err := e.synthesizeResearchFindings(ctx, goal)
+	run.tokens += tokens
+	if err != nil {
+		log.Printf("[ResearchNow] WARNING: Synthesis failed: %v", err)
+		reason := err.Error()
+		if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			reason = "time budget exhausted"
+		}
+		return finish().stop(ResearchStageSynthesis, reason, findings), nil
+	}
+	answer.Answer = synthesis
+
+	// Stored without the review gate, which would spend more than the budget allows;
+	// the store is detached from the answer's deadline so a late finish still saves it
+	if e.storage != nil && e.embedder != nil {
+		storeCtx, cancelStore := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancelStore()
+		if err := e.storeResearchSynthesis(storeCtx, goal, synthesis, nil); err != nil {
+			log.Printf("[ResearchNow] WARNING: Failed to store synthesis: %v", err)
+		} else {
+			answer.Stored = true
+		}
+	}
+
+	finish()
+	log.Printf("[ResearchNow] ✓ Answered in %s using %d tokens from %d source(s)",
+		answer.Duration.Round(time.Millisecond), answer.TokensUsed, len(answer.Sources))
+	return answer, nil
+}
+
+// stop marks the answer partial, giving the findings gathered before stage
+func (a *ResearchAnswer) stop(stage, reason, findings string) *ResearchAnswer {
+	log.Printf("[ResearchNow] Stopping before %s: %s", stage, reason)
+	a.Partial = true
+	a.StoppedAt = stage
+	a.Answer = findings
+	return a
+}
+
+// searchFindings lists the top search results as a partial answer
+func searchFindings(results []tools.StructuredSearchResult, searchOutput string) string {
+	if len(results) == 0 {
+		return truncate(searchOutput, researchNowExcerptLength)
+	}
+	var b strings.Builder
+	b.WriteString("Not enough budget to read any page. Top search results:\n")
+	for i, r := range results {
+		if i == 5 {
+			break
+		}
+		b.WriteString(fmt.Sprintf("- %s (%s): %s\n", r.Title, r.URL, r.Snippet))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// pageFindings lists the parsed pages' excerpts as a partial answer, citing each
+func pageFindings(excerpts []string, sources []map[string]string) string {
+	var b strings.Builder
+	b.WriteString("Not enough budget to synthesize an answer. Findings from the pages read:\n")
+	for i, excerpt := range excerpts {
+		b.WriteString(fmt.Sprintf("\n[%d] %s\n", i+1, excerpt))
+	}
+	if len(sources) > 0 {
+		b.WriteString("\nSources:\n")
+		b.WriteString(formatSourceList(sources))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,168 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+	"go-llama/internal/tools"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// resultsTool returns fixed structured search results
+type resultsTool struct {
+	results []tools.StructuredSearchResult
+}
+
+func (r *resultsTool) Name() string        { return tools.ToolNameSearch }
+func (r *resultsTool) Description() string { return "test search" }
+func (r *resultsTool) RequiresAuth() bool  { return false }
+func (r *resultsTool) Execute(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+	var output strings.Builder
+	for _, result := range r.results {
+		output.WriteString(result.Title + " " + result.URL + "\n")
+	}
+	return &tools.ToolResult{Success: true, Output: output.String(), Metadata: map[string]interface{}{
+		tools.MetaSearchResults: r.results,
+	}}, nil
+}
+
+// purposeTool records the purpose each page was parsed for
+type purposeTool struct {
+	pageTool
+	goals []string
+}
+
+func (p *purposeTool) Execute(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+	goal, _ := params["goal"].(string)
+	p.goals = append(p.goals, goal)
+	result, err := p.pageTool.Execute(ctx, params)
+	if result != nil && result.Success {
+		result.Output = "The page " + params["url"].(string) + " explains that Go 1.22 changed loop variable scoping so each iteration gets a fresh copy."
+	}
+	return result, err
+}
+
+func researchNowEngine(t *testing.T, replies ...string) (*Engine, *purposeTool, *completionCaller) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&memory.Principle{}); err != nil {
+		t.Fatal(err)
+	}
+	search := &resultsTool{results: []tools.StructuredSearchResult{
+		{Title: "Blog", URL: "https://blog.example/loops", Snippet: "loop variables", Rank: 1},
+		{Title: "Release notes", URL: "https://go.dev/doc/go1.22", Snippet: "Go 1.22 release notes", Rank: 2},
+		{Title: "Forum", URL: "https://forum.example/t/1", Snippet: "a thread", Rank: 3},
+	}}
+	parser := &purposeTool{}
+	registry := tools.NewRegistry()
+	registry.Register(search)
+	registry.Register(parser)
+	caller := &completionCaller{replies: replies}
+	e := &Engine{
+		db:          db,
+		llmClient:   caller,
+		modelRouter: NewModelRouter("http://reasoning", "8b", "", ""),
+		toolRegistry: tools.NewContextualRegistry(registry, map[string]tools.ToolConfig{
+			tools.ToolNameSearch:      {TimeoutIdle: time.Minute},
+			ActionToolWebParseUnified: {TimeoutIdle: time.Minute},
+		}),
+		actionTimeMargin: 30 * time.Second,
+		minActionTime:    time.Minute,
+	}
+	return e, parser, caller
+}
+
+func TestResearchNowReadsPickedPagesAndSynthesizes(t *testing.T) {
+	e, parser, caller := researchNowEngine(t,
+		`(search_evaluation (best_url "https://go.dev/doc/go1.22") (reasoning "official") (fallback_urls "https://blog.example/loops" "https://forum.example/t/1") (confidence 0.9) (should_proceed true))`,
+		"Go 1.22 gives each loop iteration its own variable [1].",
+	)
+
+	// The cycle's margin and minimum action time would refuse every action in a
+	// 20-second budget; a quick answer is not a cycle
+	answer, err := e.ResearchNow(context.Background(), "What changed about loop variables in Go 1.22?", ResearchBudget{Timeout: 20 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer.Partial || answer.Answer != "Go 1.22 gives each loop iteration its own variable [1]." {
+		t.Fatalf("expected the synthesis, got %+v", answer)
+	}
+	if len(parser.visited) != 2 || parser.visited[0] != "https://go.dev/doc/go1.22" || parser.visited[1] != "https://blog.example/loops" {
+		t.Errorf("expected the picked page then its first fallback read, got %v", parser.visited)
+	}
+	for _, goal := range parser.goals {
+		if goal != "What changed about loop variables in Go 1.22?" {
+			t.Errorf("expected pages parsed for the question, got purpose %q", goal)
+		}
+	}
+	if answer.TokensUsed != 20 || len(caller.prompts) != 2 {
+		t.Errorf("expected one evaluation and one synthesis, got %d tokens over %d calls", answer.TokensUsed, len(caller.prompts))
+	}
+	if !strings.Contains(caller.prompts[1], "fresh copy") {
+		t.Errorf("expected the synthesis to see the parsed excerpts, got %q", caller.prompts[1])
+	}
+}
+
+func TestResearchNowReturnsPartialFindingsWhenTokensRunOut(t *testing.T) {
+	e, parser, caller := researchNowEngine(t,
+		`(search_evaluation (best_url "https://go.dev/doc/go1.22") (confidence 0.9) (should_proceed true))`,
+	)
+
+	answer, err := e.ResearchNow(context.Background(), "What changed about loop variables in Go 1.22?", ResearchBudget{MaxTokens: 10, MaxPages: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !answer.Partial || answer.StoppedAt != ResearchStageSynthesis {
+		t.Fatalf("expected a partial answer stopped before synthesis, got %+v", answer)
+	}
+	if len(parser.visited) != 1 || len(caller.prompts) != 1 {
+		t.Errorf("expected one page read and no synthesis, visited %v with %d LLM calls", parser.visited, len(caller.prompts))
+	}
+	if !strings.Contains(answer.Answer, "fresh copy") || !strings.Contains(answer.Answer, "https://go.dev/doc/go1.22") {
+		t.Errorf("expected the parsed excerpt and its source in the partial answer, got %q", answer.Answer)
+	}
+}
+
+func TestResearchNowReadsTopResultsWithoutAnEvaluation(t *testing.T) {
+	e, parser, _ := researchNowEngine(t)
+
+	// Empty completions fail the evaluation, and the retry spends the token budget
+	answer, err := e.ResearchNow(context.Background(), "Go 1.22 loops", ResearchBudget{MaxTokens: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parser.visited) != 2 || parser.visited[0] != "https://blog.example/loops" || parser.visited[1] != "https://go.dev/doc/go1.22" {
+		t.Errorf("expected the top two results read, got %v", parser.visited)
+	}
+	if !answer.Partial || answer.StoppedAt != ResearchStageSynthesis || answer.TokensUsed != 20 {
+		t.Errorf("expected a partial answer after 20 tokens, got %+v", answer)
+	}
+}
+
+func TestResearchNowFallsBackToSearchSnippets(t *testing.T) {
+	e, parser, _ := researchNowEngine(t)
+	parser.errs = map[string]error{
+		"https://blog.example/loops": tools.ErrRobotsBlocked,
+		"https://go.dev/doc/go1.22":  tools.ErrRobotsBlocked,
+	}
+
+	answer, err := e.ResearchNow(context.Background(), "Go 1.22 loops", ResearchBudget{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !answer.Partial || answer.StoppedAt != ResearchStageParse || !strings.Contains(answer.Answer, "Go 1.22 release notes") {
+		t.Errorf("expected the search snippets as a partial answer, got %+v", answer)
+	}
+
+	if _, err := e.ResearchNow(context.Background(), "  ", ResearchBudget{}); err == nil {
+		t.Error("expected an empty question to be rejected")
+	}
+}
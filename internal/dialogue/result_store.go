@@ -0,0 +1,120 @@
+// internal/dialogue/result_store.go
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-llama/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// DefaultResultStoreThreshold is the result size in bytes above which action output is
+// kept in the result store rather than in goal state
+const DefaultResultStoreThreshold = 2048
+
+// resultPreviewLength leaves room for the marker within maxActionResultLength, so state
+// truncation never cuts a stored result's preview
+const resultPreviewLength = 400
+
+// ErrActionResultNotFound is returned for an action with no stored result
+var ErrActionResultNotFound = errors.New("action result not found")
+
+// ActionResult holds the full output of an action whose result is too large for state
+type ActionResult struct {
+	ActionID  string    `gorm:"primaryKey;type:varchar(100)" json:"action_id"`
+	GoalID    string    `gorm:"type:varchar(100);index" json:"goal_id"`
+	Content   string    `gorm:"type:text;not null" json:"content"`
+	Size      int       `gorm:"not null;default:0" json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ActionResult) TableName() string {
+	return "growerai_action_results"
+}
+
+// SaveActionResult stores an action's full output, replacing any earlier output
+func (sm *StateManager) SaveActionResult(ctx context.Context, actionID, goalID, content string) error {
+	result := ActionResult{ActionID: actionID, GoalID: goalID, Content: content, Size: len(content)}
+	if err := sm.db.WithContext(ctx).Save(&result).Error; err != nil {
+		return fmt.Errorf("failed to save action result: %w", err)
+	}
+	return nil
+}
+
+// LoadActionResult returns an action's full output
+func (sm *StateManager) LoadActionResult(ctx context.Context, actionID string) (string, error) {
+	var result ActionResult
+	err := sm.db.WithContext(ctx).Where("action_id = ?", actionID).First(&result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("%w: %s", ErrActionResultNotFound, actionID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load action result: %w", err)
+	}
+	return result.Content, nil
+}
+
+// deleteGoalResults drops the stored outputs of the given goals' actions
+func deleteGoalResults(tx *gorm.DB, goalIDs []string) error {
+	if len(goalIDs) == 0 {
+		return nil
+	}
+	if err := tx.Where("goal_id IN ?", goalIDs).Delete(&ActionResult{}).Error; err != nil {
+		return fmt.Errorf("failed to delete action results: %w", err)
+	}
+	return nil
+}
+
+// SetResultStoreThreshold sets the result size in bytes above which action output goes
+// to the result store (default 2048)
+func (e *Engine) SetResultStoreThreshold(bytes int) {
+	e.resultThreshold = bytes
+}
+
+// recordActionResult sets the action's result. Output over the threshold is written to
+// the result store and the action keeps a preview and the reference; if the write fails
+// the action keeps only the preview.
+func (e *Engine) recordActionResult(ctx context.Context, goalID string, action *Action, output string) {
+	threshold := e.resultThreshold
+	if threshold <= 0 {
+		threshold = DefaultResultStoreThreshold
+	}
+	if len(output) <= threshold {
+		action.Result = output
+		action.ResultRef = ""
+		return
+	}
+
+	action.Result = utils.Prefix(output, resultPreviewLength) + "... [truncated, full result stored]"
+	if e.stateManager == nil {
+		return
+	}
+	if action.ID == "" {
+		action.ID = newActionID()
+	}
+	if err := e.stateManager.SaveActionResult(ctx, action.ID, goalID, output); err != nil {
+		log.Printf("[Dialogue] WARNING: %v; keeping only a preview of %s", err, action.ID)
+		return
+	}
+	action.ResultRef = action.ID
+}
+
+// ResolveActionResult returns an action's full result, fetching it from the result store
+// when state holds only a preview. On a store failure the preview is returned.
+func (e *Engine) ResolveActionResult(ctx context.Context, action *Action) string {
+	if action.ResultRef == "" || e.stateManager == nil {
+		return action.Result
+	}
+	content, err := e.stateManager.LoadActionResult(ctx, action.ResultRef)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: %v; using preview", err)
+		return action.Result
+	}
+	return content
+}
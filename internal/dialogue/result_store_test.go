@@ -0,0 +1,103 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupResultStoreDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&ActionResult{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestLargeParseOutputIsKeptOutOfState(t *testing.T) {
+	ctx := context.Background()
+	e := &Engine{stateManager: NewStateManager(setupResultStoreDB(t))}
+
+	output := "Parsed page content: " + strings.Repeat("lorem ipsum dolor sit amet ", 1<<20/27)
+	goal := Goal{
+		ID: "goal_1",
+		ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{
+			{ID: "q1", Question: "What is it?", Status: ResearchStatusInProgress},
+		}},
+		Actions: []Action{{Tool: ActionToolWebParseUnified, Status: ActionStatusCompleted, Timestamp: time.Now()}},
+	}
+	action := &goal.Actions[0]
+	if err := e.updateResearchProgress(ctx, &goal, "q1", action, output, 0.8); err != nil {
+		t.Fatalf("updateResearchProgress failed: %v", err)
+	}
+
+	if action.ResultRef == "" || len(action.Result) > maxActionResultLength {
+		t.Fatalf("expected a stored reference and a short preview, got ref %q and %d chars", action.ResultRef, len(action.Result))
+	}
+	stateJSON, _ := json.Marshal(truncateGoalsForStorage([]Goal{goal}))
+	if len(stateJSON) > 4096 {
+		t.Errorf("expected goal state under 4KB for a %d byte output, got %d bytes", len(output), len(stateJSON))
+	}
+	if full := e.ResolveActionResult(ctx, action); full != output {
+		t.Errorf("expected the full %d byte output resolved, got %d bytes", len(output), len(full))
+	}
+}
+
+func TestSmallResultStaysInState(t *testing.T) {
+	e := &Engine{stateManager: NewStateManager(setupResultStoreDB(t))}
+	e.SetResultStoreThreshold(100)
+
+	action := &Action{}
+	e.recordActionResult(context.Background(), "goal_1", action, "short result")
+	if action.Result != "short result" || action.ResultRef != "" {
+		t.Errorf("expected the result kept inline, got %+v", action)
+	}
+	if got := e.ResolveActionResult(context.Background(), action); got != "short result" {
+		t.Errorf("expected the inline result resolved, got %q", got)
+	}
+}
+
+func TestResearchFindingsKeepNonASCIITextValid(t *testing.T) {
+	e := &Engine{stateManager: NewStateManager(setupResultStoreDB(t))}
+	output := "x" + strings.Repeat("蜜蜂🐝", 40) // 199 bytes reach into the middle of a character
+	goal := Goal{
+		ID: "goal_1",
+		ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{
+			{ID: "q1", Question: "How do bees navigate?", Status: ResearchStatusInProgress},
+		}},
+		Actions: []Action{{Tool: ActionToolSearch, Status: ActionStatusCompleted, Timestamp: time.Now()}},
+	}
+	if err := e.updateResearchProgress(context.Background(), &goal, "q1", &goal.Actions[0], output, 0.8); err != nil {
+		t.Fatal(err)
+	}
+	findings := goal.ResearchPlan.SubQuestions[0].KeyFindings
+	if !utf8.ValidString(findings) || !strings.HasSuffix(findings, "...") || len(findings) > 203 {
+		t.Errorf("expected findings cut to valid UTF-8 within 200 bytes, got %q", findings)
+	}
+	if got := truncate("研究 "+strings.Repeat("🐝", 10), 9); !utf8.ValidString(got) {
+		t.Errorf("expected a valid truncation, got %q", got)
+	}
+}
+
+func TestActionFailedReadsOnlyTheResultPrefix(t *testing.T) {
+	for result, want := range map[string]bool{
+		"ERROR: page not found":            true,
+		"Failed: timeout":                  true,
+		"错误: 🐝 " + strings.Repeat("x", 50): false,
+		"🐝": false,
+	} {
+		if got := actionFailed(Action{Result: result}); got != want {
+			t.Errorf("actionFailed(%q) = %v, want %v", result, got, want)
+		}
+	}
+}
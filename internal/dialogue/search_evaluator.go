@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"strings"
+
+	"go-llama/internal/tools"
 )
 
 // SearchEvaluation represents the LLM's evaluation of search results
@@ -17,23 +19,25 @@ type SearchEvaluation struct {
 	ShouldProceed bool     `json:"should_proceed"`
 }
 
-// evaluateSearchResults uses LLM to analyze search results and select best URLs
-func (e *Engine) evaluateSearchResults(ctx context.Context, searchOutput string, goalDescription string) (*SearchEvaluation, error) {
-	// Extract URLs and metadata from search output
-	urls := extractURLsFromSearchResults(searchOutput)
+// evaluateSearchResults uses LLM to analyze search results and select best URLs, and
+// returns the tokens the evaluation used. results are the search's structured results;
+// when empty (legacy recorded actions) URLs are scraped from searchOutput instead.
+// recency is the research question's (recency ...) hint, "" when any age will do.
+func (e *Engine) evaluateSearchResults(ctx context.Context, searchOutput string, results []tools.StructuredSearchResult, goalDescription, recency string) (*SearchEvaluation, int, error) {
+	urls := e.fetchableURLs(searchResultURLs(results, searchOutput))
 	
 	if len(urls) == 0 {
-		return nil, fmt.Errorf("no URLs found in search results")
+		return nil, 0, fmt.Errorf("no URLs found in search results")
 	}
 	
 	// Build prompt for LLM evaluation
-	prompt := e.buildSearchEvaluationPrompt(searchOutput, goalDescription, urls)
+	prompt := e.buildSearchEvaluationPrompt(searchOutput, goalDescription, recency, urls)
 	
 	// Call LLM via queue with S-expression response
 	log.Printf("[SearchEval] Requesting LLM evaluation of %d search results", len(urls))
-	response, tokens, err := e.callLLMWithStructuredReasoning(ctx, prompt, false, "")
+	response, tokens, err := e.callLLMWithStructuredReasoning(withTranscriptStep(ctx, TranscriptStepSearchEvaluation), prompt, false, "", CallEvaluation)
 	if err != nil {
-		return nil, fmt.Errorf("LLM evaluation failed: %w", err)
+		return nil, tokens, fmt.Errorf("LLM evaluation failed: %w", err)
 	}
 	
 	log.Printf("[SearchEval] LLM evaluation completed (%d tokens)", tokens)
@@ -49,7 +53,7 @@ func (e *Engine) evaluateSearchResults(ctx context.Context, searchOutput string,
 			FallbackURLs:  urls[1:],
 			Confidence:    0.5,
 			ShouldProceed: true,
-		}, nil
+		}, tokens, nil
 	}
 	
     log.Printf("[SearchEval] Selected: %s (confidence: %.2f)", 
@@ -90,26 +94,40 @@ func (e *Engine) evaluateSearchResults(ctx context.Context, searchOutput string,
     }
     evaluation.FallbackURLs = recoveredFallbacks
     
-    return evaluation, nil
+    return evaluation, tokens, nil
 }
 
 // buildSearchEvaluationPrompt creates the LLM prompt for search evaluation
-func (e *Engine) buildSearchEvaluationPrompt(searchOutput string, goalDescription string, urls []string) string {
+func (e *Engine) buildSearchEvaluationPrompt(searchOutput, goalDescription, recency string, urls []string) string {
 	var prompt strings.Builder
 	
 	prompt.WriteString("Evaluate these search results and select the best URL to parse.\n\n")
 	
-	prompt.WriteString(fmt.Sprintf("GOAL: %s\n\n", goalDescription))
+	prompt.WriteString(fmt.Sprintf("GOAL: %s\n", goalDescription))
+	if recency != "" {
+		prompt.WriteString(fmt.Sprintf("REQUESTED RECENCY: past %s\n", recency))
+	}
+	prompt.WriteString("\n")
 	
 	prompt.WriteString("SEARCH RESULTS:\n")
-	prompt.WriteString(searchOutput)
+	wrapped, _ := tools.WrapUntrusted("search results", searchOutput)
+	prompt.WriteString(wrapped)
 	prompt.WriteString("\n\n")
 	
 	prompt.WriteString("EVALUATION CRITERIA:\n")
 	prompt.WriteString("1. Relevance to goal\n")
 	prompt.WriteString("2. Source quality and authority\n")
 	prompt.WriteString("3. Content accessibility (no PDFs, login pages, or paywalls)\n")
-	prompt.WriteString("4. Likely to contain actionable information\n\n")
+	prompt.WriteString("4. Likely to contain actionable information\n")
+	criterion := 5
+	if e.targetLanguage != "" {
+		prompt.WriteString(fmt.Sprintf("%d. Written in %s (titles and snippets show the page language)\n", criterion, tools.LanguageName(e.targetLanguage)))
+		criterion++
+	}
+	if recency != "" {
+		prompt.WriteString(fmt.Sprintf("%d. %s\n", criterion, recencyCriterion(recency)))
+	}
+	prompt.WriteString("\n")
 	
 	prompt.WriteString("CRITICAL: Respond ONLY with this S-expression format:\n\n")
 	prompt.WriteString("(search_evaluation\n")
@@ -123,6 +141,10 @@ func (e *Engine) buildSearchEvaluationPrompt(searchOutput string, goalDescriptio
 	prompt.WriteString("RULES:\n")
 	prompt.WriteString("- Skip PDFs, login pages, paywalls, social media\n")
 	prompt.WriteString("- Prefer .edu, .gov, .org, research journals, technical docs\n")
+	if e.targetLanguage != "" {
+		prompt.WriteString(fmt.Sprintf("- Rank pages not in %s below comparable ones in %s\n",
+			tools.LanguageName(e.targetLanguage), tools.LanguageName(e.targetLanguage)))
+	}
 	prompt.WriteString("- If ALL URLs are bad, set should_proceed to false\n")
 	prompt.WriteString("- Output ONLY the S-expression, no explanations\n")
 	
@@ -0,0 +1,64 @@
+package dialogue
+
+import (
+	"fmt"
+	"log"
+
+	"go-llama/internal/tools"
+)
+
+// Action metadata keys carrying a research question's search hints to its search
+const (
+	MetadataSearchCategory = "search_category"
+	MetadataSearchRecency  = "search_recency"
+)
+
+// setSearchHint records a (category ...) or (recency ...) hint from a research plan on
+// q, dropping values the search tool would reject so a bad hint never fails the search
+func setSearchHint(q *ResearchQuestion, field, value string) {
+	switch field {
+	case "category":
+		if q.Category = tools.NormalizeSearchCategory(value); q.Category == "" && value != "" {
+			log.Printf("[Dialogue] Ignoring unknown search category %q for question %s", value, q.ID)
+		}
+	case "recency":
+		if q.Recency = tools.NormalizeSearchTimeRange(value); q.Recency == "" && value != "" {
+			log.Printf("[Dialogue] Ignoring unknown search recency %q for question %s", value, q.ID)
+		}
+	}
+}
+
+// setSearchHintsFromBlock reads the optional hints of a (question ...) block
+func setSearchHintsFromBlock(q *ResearchQuestion, block string) {
+	setSearchHint(q, "category", extractFieldContent(block, "category"))
+	setSearchHint(q, "recency", extractFieldContent(block, "recency"))
+}
+
+// searchHintMetadata copies a question's hints into the metadata of its search action
+func searchHintMetadata(q *ResearchQuestion, metadata map[string]interface{}) {
+	if q.Category != "" {
+		metadata[MetadataSearchCategory] = q.Category
+	}
+	if q.Recency != "" {
+		metadata[MetadataSearchRecency] = q.Recency
+	}
+}
+
+// actionSearchHints returns the category and recency a search action asks for
+func actionSearchHints(action *Action) (category, recency string) {
+	if action.Metadata == nil {
+		return "", ""
+	}
+	category, _ = action.Metadata[MetadataSearchCategory].(string)
+	recency, _ = action.Metadata[MetadataSearchRecency].(string)
+	return category, recency
+}
+
+// recencyCriterion tells the search evaluation how fresh results should be, or "" when
+// any age will do
+func recencyCriterion(recency string) string {
+	if recency == "" {
+		return ""
+	}
+	return fmt.Sprintf("Published within the past %s; rank results that look older below fresher ones", recency)
+}
@@ -0,0 +1,89 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/tools"
+)
+
+// paramsTool records the params of each search
+type paramsTool struct {
+	params []map[string]interface{}
+}
+
+func (p *paramsTool) Name() string        { return tools.ToolNameSearch }
+func (p *paramsTool) Description() string { return "test search" }
+func (p *paramsTool) RequiresAuth() bool  { return false }
+func (p *paramsTool) Execute(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+	p.params = append(p.params, params)
+	return &tools.ToolResult{Success: true, Output: "ok"}, nil
+}
+
+func TestResearchPlanSearchHints(t *testing.T) {
+	plan, err := parseResearchPlanResponse(`(root "What is new in Rust?")
+(q "What changed in the latest Rust release?" (category "News") (recency "month"))
+(q "How does the borrow checker work?")
+(q "Which crates are trending?" (recency "decade"))`)
+	if err != nil || len(plan.SubQuestions) != 3 {
+		t.Fatalf("expected 3 questions, got %+v (%v)", plan, err)
+	}
+	if q := plan.SubQuestions[0]; q.Category != "news" || q.Recency != "month" || q.Question != "What changed in the latest Rust release?" {
+		t.Errorf("expected normalized hints on the first question, got %+v", q)
+	}
+	if q := plan.SubQuestions[1]; q.Category != "" || q.Recency != "" {
+		t.Errorf("expected no hints on a plain question, got %+v", q)
+	}
+	if q := plan.SubQuestions[2]; q.Recency != "" || q.Question != "Which crates are trending?" {
+		t.Errorf("expected an unknown recency dropped, got %+v", q)
+	}
+
+	malformed, err := extractResearchPlanFromMalformed(`(research_plan (sub_questions
+		(question (id "q1") (text "Latest CPU benchmarks") (search_query "cpu benchmarks") (category "it") (recency "week"))`)
+	if err != nil || malformed.SubQuestions[0].Category != "it" || malformed.SubQuestions[0].Recency != "week" {
+		t.Errorf("expected hints read from a question block, got %+v (%v)", malformed, err)
+	}
+}
+
+func TestSearchHintsReachTheSearchTool(t *testing.T) {
+	search := &paramsTool{}
+	registry := tools.NewRegistry()
+	registry.Register(search)
+	e := &Engine{
+		toolRegistry:     tools.NewContextualRegistry(registry, map[string]tools.ToolConfig{tools.ToolNameSearch: {TimeoutIdle: time.Minute}}),
+		actionTimeMargin: 30 * time.Second,
+		minActionTime:    time.Minute,
+	}
+	goal := &Goal{ID: "goal_1", ResearchPlan: &ResearchPlan{SubQuestions: []ResearchQuestion{
+		{ID: "q1", Question: "Recent Go releases", SearchQuery: "go release notes", Status: ResearchStatusPending, Category: "news", Recency: "year"},
+		{ID: "q2", Question: "Go syntax", SearchQuery: "go syntax", Status: ResearchStatusPending},
+	}}}
+
+	for range goal.ResearchPlan.SubQuestions {
+		action := e.getNextResearchAction(context.Background(), goal)
+		if _, err := e.executeAction(context.Background(), action); err != nil {
+			t.Fatal(err)
+		}
+		goal.ResearchPlan.SubQuestions[len(search.params)-1].Status = ResearchStatusCompleted
+	}
+
+	if p := search.params[0]; p["category"] != "news" || p["time_range"] != "year" {
+		t.Errorf("expected the hints passed as search params, got %v", p)
+	}
+	if p := search.params[1]; p["category"] != nil || p["time_range"] != nil {
+		t.Errorf("expected a plain search without filters, got %v", p)
+	}
+}
+
+func TestSearchEvaluationPromptMentionsRecency(t *testing.T) {
+	e := &Engine{}
+	prompt := e.buildSearchEvaluationPrompt("results", "Track Go releases", "week", []string{"https://go.dev"})
+	if !strings.Contains(prompt, "REQUESTED RECENCY: past week") || !strings.Contains(prompt, "rank results that look older below fresher ones") {
+		t.Errorf("expected the requested recency in the prompt, got:\n%s", prompt)
+	}
+	if plain := e.buildSearchEvaluationPrompt("results", "Track Go releases", "", []string{"https://go.dev"}); strings.Contains(plain, "RECENCY") {
+		t.Error("expected no recency without a hint")
+	}
+}
@@ -0,0 +1,233 @@
+// internal/dialogue/search_ledger.go
+package dialogue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-llama/internal/tools"
+
+	"gorm.io/gorm"
+)
+
+// SearchLedgerConfig controls reusing the outcome of a search another goal ran recently
+// instead of searching, evaluating and choosing a page again
+type SearchLedgerConfig struct {
+	Enabled         bool
+	Window          time.Duration // Searches older than this are run again
+	MinOverlap      float64       // Keyword overlap at which two queries count as the same
+	MinParseQuality float64       // Confidence the chosen page's parse needed to be reused
+}
+
+// Validate rejects settings that would reuse unrelated queries or unread pages
+func (c SearchLedgerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("search_ledger.window_hours must be positive, got %s", c.Window)
+	}
+	if c.MinOverlap <= 0 || c.MinOverlap > 1 {
+		return fmt.Errorf("search_ledger.min_overlap must be in (0, 1], got %.2f", c.MinOverlap)
+	}
+	if c.MinParseQuality < 0 || c.MinParseQuality > 1 {
+		return fmt.Errorf("search_ledger.min_parse_quality must be between 0 and 1, got %.2f", c.MinParseQuality)
+	}
+	return nil
+}
+
+// searchLedgerScan bounds how many recent searches are compared for a near-identical query
+const searchLedgerScan = 200
+
+// searchLedgerSummaryLength bounds the result summary kept with each search
+const searchLedgerSummaryLength = 1000
+
+// MetadataReusedSearch marks a search action answered from the ledger rather than run
+const MetadataReusedSearch = "reused"
+
+// SearchLedgerEntry is the last run of a normalized search query: what it found, the
+// page chosen from it and how well that page answered
+type SearchLedgerEntry struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	QueryHash     string    `gorm:"type:char(64);not null;uniqueIndex" json:"query_hash"`
+	Query         string    `gorm:"type:text;not null" json:"query"` // Normalized
+	ResultSummary string    `gorm:"type:text" json:"result_summary"`
+	ChosenURL     string    `gorm:"type:text" json:"chosen_url"`
+	ParseQuality  float64   `gorm:"not null;default:0" json:"parse_quality"`
+	Parsed        bool      `gorm:"not null;default:false" json:"parsed"` // ParseQuality has been recorded
+	GoalID        string    `gorm:"type:varchar(100)" json:"goal_id"`
+	ExecutedAt    time.Time `gorm:"not null;index" json:"executed_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (SearchLedgerEntry) TableName() string {
+	return "growerai_search_ledger"
+}
+
+// SearchLedgerStats counts searches checked against the ledger
+type SearchLedgerStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// SetSearchLedger configures reuse of recent searches across goals
+func (e *Engine) SetSearchLedger(cfg SearchLedgerConfig) {
+	e.searchLedger = cfg
+}
+
+// SearchLedgerStats returns how often searches were answered from the ledger
+func (e *Engine) SearchLedgerStats() SearchLedgerStats {
+	return SearchLedgerStats{Hits: e.searchLedgerHits.Load(), Misses: e.searchLedgerMisses.Load()}
+}
+
+// searchQueryHash keys the ledger by the normalized query, so reordered or
+// re-punctuated queries share an entry
+func searchQueryHash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// reusableSearch returns a search of the same or a near-identical query run within the
+// window whose chosen page parsed well enough to go straight to. Searches whose page
+// parsed poorly, or was never parsed, are run again.
+func (e *Engine) reusableSearch(ctx context.Context, query string) (*SearchLedgerEntry, bool) {
+	cfg := e.searchLedger
+	if !cfg.Enabled || e.db == nil {
+		return nil, false
+	}
+	normalized := tools.NormalizeSearchQuery(query)
+	if normalized == "" {
+		return nil, false
+	}
+
+	var recent []SearchLedgerEntry
+	err := e.db.WithContext(ctx).
+		Where("executed_at >= ?", time.Now().Add(-cfg.Window)).
+		Order("executed_at DESC").
+		Limit(searchLedgerScan).
+		Find(&recent).Error
+	if err != nil {
+		log.Printf("[SearchLedger] WARNING: Failed to read ledger, searching: %v", err)
+		return nil, false
+	}
+
+	hash := searchQueryHash(normalized)
+	var match *SearchLedgerEntry
+	best := 0.0
+	for i := range recent {
+		entry := &recent[i]
+		similarity := 1.0
+		if entry.QueryHash != hash {
+			similarity = thoughtSimilarity(entry.Query, normalized)
+		}
+		if similarity >= cfg.MinOverlap && similarity > best {
+			match, best = entry, similarity
+		}
+	}
+	if match == nil {
+		e.searchLedgerMisses.Add(1)
+		return nil, false
+	}
+	if !match.Parsed || match.ChosenURL == "" || match.ParseQuality < cfg.MinParseQuality {
+		e.searchLedgerMisses.Add(1)
+		log.Printf("[SearchLedger] %q ran %s ago but its page parsed poorly (%.2f), searching again",
+			truncate(match.Query, 60), time.Since(match.ExecutedAt).Round(time.Minute), match.ParseQuality)
+		return nil, false
+	}
+
+	e.searchLedgerHits.Add(1)
+	log.Printf("[SearchLedger] ✓ Reusing search %q from %s ago (overlap %.2f): going straight to %s",
+		truncate(match.Query, 60), time.Since(match.ExecutedAt).Round(time.Minute), best, truncate(match.ChosenURL, 60))
+	return match, true
+}
+
+// markReusedSearch records on a search action that it was answered from the ledger,
+// pointing the parse that depends on it at the page chosen last time
+func markReusedSearch(action *Action, entry *SearchLedgerEntry) {
+	if action.Metadata == nil {
+		action.Metadata = make(map[string]interface{})
+	}
+	action.Metadata[MetadataReusedSearch] = true
+	action.Metadata["reused_search_query"] = entry.Query
+	action.Metadata["extracted_urls"] = []string{entry.ChosenURL}
+	action.Metadata["selected_url"] = entry.ChosenURL
+}
+
+// recordSearch enters a search that was run in the ledger, replacing any earlier run
+// of the query along with the page chosen from it
+func (e *Engine) recordSearch(ctx context.Context, goalID, query, output string) {
+	if !e.searchLedger.Enabled || e.db == nil {
+		return
+	}
+	normalized := tools.NormalizeSearchQuery(query)
+	if normalized == "" {
+		return
+	}
+	entry := SearchLedgerEntry{
+		QueryHash:     searchQueryHash(normalized),
+		Query:         normalized,
+		ResultSummary: truncate(output, searchLedgerSummaryLength),
+		GoalID:        goalID,
+		ExecutedAt:    time.Now(),
+	}
+	err := e.db.WithContext(ctx).Where("query_hash = ?", entry.QueryHash).
+		Assign(map[string]interface{}{
+			"query":          entry.Query,
+			"result_summary": entry.ResultSummary,
+			"chosen_url":     "",
+			"parse_quality":  0,
+			"parsed":         false,
+			"goal_id":        entry.GoalID,
+			"executed_at":    entry.ExecutedAt,
+		}).
+		FirstOrCreate(&entry).Error
+	if err != nil {
+		log.Printf("[SearchLedger] WARNING: Failed to record search %q: %v", truncate(query, 60), err)
+	}
+}
+
+// recordSearchOutcome stores the page a search led to and how well its parse answered.
+// A better outcome replaces a worse one, so one good parse is not forgotten because a
+// fallback page from the same search parsed poorly. A reused search always records its
+// outcome, so a page that no longer answers stops being reused.
+func (e *Engine) recordSearchOutcome(ctx context.Context, search *Action, url string, quality float64) {
+	if !e.searchLedger.Enabled || e.db == nil || search == nil || url == "" {
+		return
+	}
+	reused, _ := search.Metadata[MetadataReusedSearch].(bool)
+	query := search.Description
+	if query == "" {
+		query, _ = search.Metadata["question_text"].(string)
+	}
+	normalized := tools.NormalizeSearchQuery(query)
+	if normalized == "" {
+		return
+	}
+
+	var entry SearchLedgerEntry
+	err := e.db.WithContext(ctx).Where("query_hash = ?", searchQueryHash(normalized)).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("[SearchLedger] WARNING: Failed to load search %q: %v", truncate(normalized, 60), err)
+		return
+	}
+	if entry.Parsed && entry.ParseQuality >= quality && !reused {
+		return
+	}
+	err = e.db.WithContext(ctx).Model(&entry).Updates(map[string]interface{}{
+		"chosen_url":    url,
+		"parse_quality": clampConfidence(quality),
+		"parsed":        true,
+	}).Error
+	if err != nil {
+		log.Printf("[SearchLedger] WARNING: Failed to record outcome of %q: %v", truncate(normalized, 60), err)
+	}
+}
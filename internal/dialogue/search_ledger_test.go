@@ -0,0 +1,135 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-llama/internal/tools"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func ledgerEngine(t *testing.T) (*Engine, *countingTool) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&SearchLedgerEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	search := &countingTool{}
+	registry := tools.NewRegistry()
+	registry.Register(search)
+	e := &Engine{db: db, toolRegistry: tools.NewContextualRegistry(registry, nil)}
+	e.SetSearchLedger(SearchLedgerConfig{Enabled: true, Window: 24 * time.Hour, MinOverlap: 0.8, MinParseQuality: 0.6})
+	return e, search
+}
+
+func TestSearchLedgerConfigValidate(t *testing.T) {
+	if err := (SearchLedgerConfig{}).Validate(); err != nil {
+		t.Errorf("expected a disabled ledger to need no settings: %v", err)
+	}
+	for name, cfg := range map[string]SearchLedgerConfig{
+		"no window":     {Enabled: true, MinOverlap: 0.8},
+		"no overlap":    {Enabled: true, Window: time.Hour},
+		"quality above": {Enabled: true, Window: time.Hour, MinOverlap: 0.8, MinParseQuality: 1.5},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRepeatedSearchGoesStraightToChosenPage(t *testing.T) {
+	ctx := context.Background()
+	e, search := ledgerEngine(t)
+
+	first := &Action{Tool: ActionToolSearch, Description: "conversational context chatbots", Metadata: map[string]interface{}{MetadataGoalID: "goal_a"}}
+	if _, err := e.executeAction(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	first.Status = ActionStatusCompleted
+	e.recordSearchOutcome(ctx, first, "https://example.com/context", 0.8)
+
+	// Another goal searching the same terms, reordered, skips the search
+	second := &Action{Tool: ActionToolSearch, Description: "Chatbots: conversational context", Metadata: map[string]interface{}{MetadataGoalID: "goal_b"}}
+	output, err := e.executeAction(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if search.calls != 1 {
+		t.Errorf("expected the repeated search skipped, tool ran %d times", search.calls)
+	}
+	if output != "ok" {
+		t.Errorf("expected the earlier result summary, got %q", output)
+	}
+	if second.Metadata[MetadataReusedSearch] != true || second.Metadata["selected_url"] != "https://example.com/context" {
+		t.Errorf("expected the reuse flagged with the chosen page, got %v", second.Metadata)
+	}
+	if stats := e.SearchLedgerStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %+v", stats)
+	}
+
+	// A fresh search is never answered from the ledger
+	fresh := &Action{Tool: ActionToolSearch, Description: "conversational context chatbots", Metadata: map[string]interface{}{"bypass_cache": true}}
+	if _, err := e.executeAction(ctx, fresh); err != nil {
+		t.Fatal(err)
+	}
+	if search.calls != 2 {
+		t.Errorf("expected a fresh search to run, tool ran %d times", search.calls)
+	}
+}
+
+func TestPoorSearchOutcomeIsNotReused(t *testing.T) {
+	ctx := context.Background()
+	e, search := ledgerEngine(t)
+
+	first := &Action{Tool: ActionToolSearch, Description: "vector database benchmarks"}
+	if _, err := e.executeAction(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	e.recordSearchOutcome(ctx, first, "https://example.com/bad", 0.2)
+
+	again := &Action{Tool: ActionToolSearch, Description: "vector database benchmarks"}
+	if _, err := e.executeAction(ctx, again); err != nil {
+		t.Fatal(err)
+	}
+	if search.calls != 2 {
+		t.Errorf("expected a poorly parsed search run again, tool ran %d times", search.calls)
+	}
+
+	// A better page from the rerun makes it reusable; a worse fallback does not undo that
+	e.recordSearchOutcome(ctx, again, "https://example.com/good", 0.9)
+	e.recordSearchOutcome(ctx, again, "https://example.com/fallback", 0.3)
+	entry, ok := e.reusableSearch(ctx, "benchmarks vector database")
+	if !ok || entry.ChosenURL != "https://example.com/good" {
+		t.Errorf("expected the good page reused, got %+v (%v)", entry, ok)
+	}
+
+	// A reused page that no longer answers stops being reused
+	reused := &Action{Tool: ActionToolSearch, Description: "vector database benchmarks"}
+	markReusedSearch(reused, entry)
+	e.recordSearchOutcome(ctx, reused, entry.ChosenURL, 0.1)
+	if _, ok := e.reusableSearch(ctx, "vector database benchmarks"); ok {
+		t.Error("expected a page that parsed poorly on reuse to be searched again")
+	}
+}
+
+func TestSearchLedgerIgnoresOldAndUnrelatedSearches(t *testing.T) {
+	ctx := context.Background()
+	e, _ := ledgerEngine(t)
+
+	e.recordSearch(ctx, "", "rust async runtimes", "results")
+	e.recordSearchOutcome(ctx, &Action{Description: "rust async runtimes"}, "https://example.com/rust", 0.9)
+	if _, ok := e.reusableSearch(ctx, "python web frameworks"); ok {
+		t.Error("expected an unrelated query not to match")
+	}
+
+	e.db.Model(&SearchLedgerEntry{}).Where("1 = 1").Update("executed_at", time.Now().Add(-48*time.Hour))
+	if _, ok := e.reusableSearch(ctx, "rust async runtimes"); ok {
+		t.Error("expected a search outside the window to run again")
+	}
+}
@@ -0,0 +1,223 @@
+// internal/dialogue/settings.go
+package dialogue
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Settings are the engine options a config reload can change. A running cycle keeps the
+// settings it started with; new settings take effect between cycles.
+type Settings struct {
+	MaxTokensPerCycle         int
+	MaxDurationMinutes        int
+	MaxThoughtsPerCycle       int
+	ActionRequirementInterval int
+	NoveltyWindowHours        int
+	NoveltyThreshold          float64
+	MaxReplansPerGoal         int
+	ReflectionDiversity       float64
+
+	ReasoningDepth         string
+	EnableSelfAssessment   bool
+	EnableMetaLearning     bool
+	EnableStrategyTracking bool
+	StoreInsights          bool
+	DynamicActionPlanning  bool
+
+	DedupThreshold       float64
+	PrincipleTrials      int
+	PrincipleTrialMargin float64
+	InjectionDetection   bool
+	InjectionPenalty     float64
+	ActionTimeMargin     time.Duration
+	MinActionTime        time.Duration
+	ModelRouting         map[string]string
+	Sampling             map[string]SamplingParams
+
+	DeadlineWindow        time.Duration
+	DeadlineMaxBoost      int
+	DeadlineCurveExponent float64
+
+	GoalDedup     GoalDedupConfig
+	GoalProposals GoalProposalConfig
+
+	AdaptiveSearchThreshold float64
+	AdaptiveGoalSimilarity  float64
+	AdaptiveToolTimeout     int // Seconds
+	AdaptiveLimits          AdaptiveLimits
+
+	InterestHalfLife time.Duration
+	MemoryReuse      MemoryReuseConfig
+	SearchLedger     SearchLedgerConfig
+	Transcripts      TranscriptConfig
+	FocusAreas       FocusConfig
+	Streaming        StreamingConfig
+	SynthesisGate    SynthesisGateConfig
+	LoadShedding     LoadSheddingConfig
+	Backpressure     BackpressureConfig
+
+	UserQuestionTimeout time.Duration
+}
+
+// CheckSettings rejects settings ApplySettings could not take
+func (e *Engine) CheckSettings(s Settings) error {
+	if s.MaxDurationMinutes <= 0 {
+		return fmt.Errorf("max_duration_minutes must be positive, got %d", s.MaxDurationMinutes)
+	}
+	if s.MaxThoughtsPerCycle <= 0 {
+		return fmt.Errorf("max_thoughts_per_cycle must be positive, got %d", s.MaxThoughtsPerCycle)
+	}
+	if s.NoveltyThreshold < 0 || s.NoveltyThreshold > 1 {
+		return fmt.Errorf("novelty_threshold must be between 0 and 1, got %.2f", s.NoveltyThreshold)
+	}
+	if s.ReflectionDiversity < 0 || s.ReflectionDiversity > 1 {
+		return fmt.Errorf("reflection_diversity_threshold must be between 0 and 1, got %.2f", s.ReflectionDiversity)
+	}
+	if s.MaxReplansPerGoal < 0 {
+		return fmt.Errorf("max_replans_per_goal must not be negative, got %d", s.MaxReplansPerGoal)
+	}
+	if s.PrincipleTrialMargin > 1 {
+		return fmt.Errorf("principle_trial_margin must be at most 1, got %.2f", s.PrincipleTrialMargin)
+	}
+	if s.AdaptiveSearchThreshold <= 0 || s.AdaptiveSearchThreshold >= 1 {
+		return fmt.Errorf("adaptive.search_threshold must be between 0 and 1, got %.2f", s.AdaptiveSearchThreshold)
+	}
+	if s.AdaptiveGoalSimilarity <= 0 || s.AdaptiveGoalSimilarity >= 1 {
+		return fmt.Errorf("adaptive.goal_similarity must be between 0 and 1, got %.2f", s.AdaptiveGoalSimilarity)
+	}
+	if s.AdaptiveToolTimeout <= 0 {
+		return fmt.Errorf("adaptive.tool_timeout_seconds must be positive, got %d", s.AdaptiveToolTimeout)
+	}
+	if err := s.AdaptiveLimits.Validate(); err != nil {
+		return err
+	}
+	if s.InterestHalfLife <= 0 {
+		return fmt.Errorf("interests.half_life_days must be positive, got %s", s.InterestHalfLife)
+	}
+	if err := s.GoalDedup.Validate(); err != nil {
+		return err
+	}
+	if err := s.GoalProposals.Validate(); err != nil {
+		return err
+	}
+	if err := s.MemoryReuse.Validate(); err != nil {
+		return err
+	}
+	if err := s.SearchLedger.Validate(); err != nil {
+		return err
+	}
+	if err := s.Transcripts.Validate(); err != nil {
+		return err
+	}
+	if err := s.FocusAreas.Validate(); err != nil {
+		return err
+	}
+	if err := s.SynthesisGate.Validate(); err != nil {
+		return err
+	}
+	if err := s.Streaming.Validate(); err != nil {
+		return err
+	}
+	if err := s.LoadShedding.Validate(); err != nil {
+		return err
+	}
+	if err := s.Backpressure.Validate(); err != nil {
+		return err
+	}
+	if s.UserQuestionTimeout <= 0 {
+		return fmt.Errorf("user_questions.timeout_hours must be positive, got %s", s.UserQuestionTimeout)
+	}
+	if _, err := parseModelPolicy(s.ModelRouting); err != nil {
+		return fmt.Errorf("model_routing: %w", err)
+	}
+	if _, err := parseSampling(s.Sampling); err != nil {
+		return fmt.Errorf("sampling: %w", err)
+	}
+	return nil
+}
+
+// ApplySettings installs checked settings. While a cycle is running they are held and
+// applied once it ends, so active research keeps consistent limits.
+func (e *Engine) ApplySettings(s Settings) {
+	e.settingsMu.Lock()
+	defer e.settingsMu.Unlock()
+
+	if e.cycleRunning {
+		e.pendingSettings = &s
+		log.Printf("[Dialogue] New settings will apply after the current cycle")
+		return
+	}
+	e.applySettingsLocked(s)
+}
+
+// beginCycle marks a cycle as running so reloaded settings wait for it to end
+func (e *Engine) beginCycle() {
+	e.settingsMu.Lock()
+	defer e.settingsMu.Unlock()
+	e.cycleRunning = true
+}
+
+// endCycle applies any settings that arrived during the cycle
+func (e *Engine) endCycle() {
+	e.settingsMu.Lock()
+	defer e.settingsMu.Unlock()
+	e.cycleRunning = false
+	if e.pendingSettings != nil {
+		e.applySettingsLocked(*e.pendingSettings)
+		e.pendingSettings = nil
+	}
+}
+
+func (e *Engine) applySettingsLocked(s Settings) {
+	e.maxTokensPerCycle = s.MaxTokensPerCycle
+	e.maxDurationMinutes = s.MaxDurationMinutes
+	e.maxThoughtsPerCycle = s.MaxThoughtsPerCycle
+	e.actionRequirementInterval = s.ActionRequirementInterval
+	e.noveltyWindowHours = s.NoveltyWindowHours
+	e.SetNoveltyThreshold(s.NoveltyThreshold)
+	e.SetMaxReplans(s.MaxReplansPerGoal)
+	e.SetReflectionDiversity(s.ReflectionDiversity)
+
+	e.reasoningDepth = s.ReasoningDepth
+	e.enableSelfAssessment = s.EnableSelfAssessment
+	e.enableMetaLearning = s.EnableMetaLearning
+	e.enableStrategyTracking = s.EnableStrategyTracking
+	e.storeInsights = s.StoreInsights
+	e.dynamicActionPlanning = s.DynamicActionPlanning
+
+	e.SetDedupThreshold(s.DedupThreshold)
+	e.SetPrincipleTrialConfig(s.PrincipleTrials, s.PrincipleTrialMargin)
+	e.SetInjectionDetection(s.InjectionDetection, s.InjectionPenalty)
+	e.SetActionTimeBudget(s.ActionTimeMargin, s.MinActionTime)
+	if e.modelRouter != nil {
+		// Checked by CheckSettings; reset so removed overrides fall back to defaults
+		if err := e.modelRouter.ResetPolicy(s.ModelRouting); err != nil {
+			log.Printf("[Dialogue] WARNING: Keeping previous model routing: %v", err)
+		}
+		if err := e.modelRouter.SetSampling(s.Sampling); err != nil {
+			log.Printf("[Dialogue] WARNING: Keeping previous sampling: %v", err)
+		}
+	}
+	e.SetDeadlineEscalation(s.DeadlineWindow, s.DeadlineMaxBoost, s.DeadlineCurveExponent)
+	e.goalDedup = s.GoalDedup
+	e.goalProposals = s.GoalProposals
+	e.SetAdaptiveBase(s.AdaptiveSearchThreshold, s.AdaptiveGoalSimilarity, s.AdaptiveToolTimeout)
+	e.adaptiveConfig.SetLimits(s.AdaptiveLimits)
+	e.SetInterestHalfLife(s.InterestHalfLife)
+	e.SetMemoryReuse(s.MemoryReuse)
+	e.SetSearchLedger(s.SearchLedger)
+	e.SetTranscripts(s.Transcripts)
+	e.SetFocusConfig(s.FocusAreas)
+	e.SetStreaming(s.Streaming)
+	e.synthesisGate = s.SynthesisGate
+	e.SetLoadShedding(s.LoadShedding)
+	if err := e.SetBackpressure(s.Backpressure); err != nil {
+		log.Printf("[Dialogue] WARNING: Keeping previous backpressure: %v", err)
+	}
+	e.SetUserQuestionTimeout(s.UserQuestionTimeout)
+
+	log.Printf("[Dialogue] Settings applied: %d thoughts/%d tokens/%d minutes per cycle, depth %s, routing %s",
+		s.MaxThoughtsPerCycle, s.MaxTokensPerCycle, s.MaxDurationMinutes, s.ReasoningDepth, e.modelRouter)
+}
@@ -0,0 +1,82 @@
+package dialogue
+
+import (
+	"testing"
+	"time"
+)
+
+func testSettings() Settings {
+	return Settings{
+		MaxTokensPerCycle:       50000,
+		MaxDurationMinutes:      10,
+		MaxThoughtsPerCycle:     5,
+		DedupThreshold:          0.9,
+		PrincipleTrialMargin:    0.1,
+		DeadlineWindow:          72 * time.Hour,
+		DeadlineMaxBoost:        40,
+		DeadlineCurveExponent:   2,
+		GoalDedup:               DefaultGoalDedupConfig(),
+		GoalProposals:           GoalProposalConfig{DefaultPriority: 5, MinDescriptionChars: 15},
+		AdaptiveSearchThreshold: 0.3,
+		AdaptiveGoalSimilarity:  0.75,
+		AdaptiveToolTimeout:     60,
+		AdaptiveLimits:          DefaultAdaptiveLimits(),
+		InterestHalfLife:        14 * 24 * time.Hour,
+		FocusAreas:              FocusConfig{ExpiryCycles: 5, PriorityBonus: 10, MinSimilarity: 0.6},
+		Streaming:               StreamingConfig{Enabled: true, MinSynthesisChars: 600},
+		SynthesisGate:           SynthesisGateConfig{Enabled: true, MinScore: 0.6, OnFail: SynthesisGateDiscount},
+		UserQuestionTimeout:     24 * time.Hour,
+	}
+}
+
+func TestApplySettingsWaitsForRunningCycle(t *testing.T) {
+	e := &Engine{maxThoughtsPerCycle: 3, adaptiveConfig: NewAdaptiveConfig(0.3, 0.75, 60)}
+
+	e.beginCycle()
+	e.ApplySettings(testSettings())
+	if e.maxThoughtsPerCycle != 3 {
+		t.Fatalf("settings applied mid-cycle: max thoughts = %d", e.maxThoughtsPerCycle)
+	}
+	e.endCycle()
+	if e.maxThoughtsPerCycle != 5 || e.maxDurationMinutes != 10 {
+		t.Errorf("settings not applied after the cycle: %d thoughts, %d minutes", e.maxThoughtsPerCycle, e.maxDurationMinutes)
+	}
+
+	next := testSettings()
+	next.MaxThoughtsPerCycle = 8
+	next.AdaptiveSearchThreshold = 0.4
+	e.ApplySettings(next)
+	if e.maxThoughtsPerCycle != 8 || e.adaptiveConfig.baseSearchThreshold != 0.4 {
+		t.Errorf("settings not applied between cycles: %d thoughts, base search threshold %.2f",
+			e.maxThoughtsPerCycle, e.adaptiveConfig.baseSearchThreshold)
+	}
+}
+
+func TestCheckSettingsRejectsInvalid(t *testing.T) {
+	e := &Engine{}
+	if err := e.CheckSettings(testSettings()); err != nil {
+		t.Fatalf("valid settings rejected: %v", err)
+	}
+
+	bad := testSettings()
+	bad.MaxDurationMinutes = 0
+	if err := e.CheckSettings(bad); err == nil {
+		t.Error("expected zero max duration to be rejected")
+	}
+
+	bad = testSettings()
+	bad.ModelRouting = map[string]string{"reflection": "huge"}
+	if err := e.CheckSettings(bad); err == nil {
+		t.Error("expected an unknown routing tier to be rejected")
+	}
+
+	margin := testSettings()
+	margin.PrincipleTrialMargin = -1
+	if err := e.CheckSettings(margin); err != nil {
+		t.Errorf("expected a negative trial margin (any win) accepted: %v", err)
+	}
+	margin.PrincipleTrialMargin = 1.5
+	if err := e.CheckSettings(margin); err == nil {
+		t.Error("expected a trial margin above 1 to be rejected")
+	}
+}
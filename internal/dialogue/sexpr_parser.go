@@ -10,6 +10,7 @@ import (
 // ParseReasoningSExpr parses S-expression format reasoning
 // extractResearchPlanFlat parses a flat S-expression list of questions.
 // Expected Format: (root "Main Question") (q "Sub Q 1") (q "Sub Q 2")
+// A question may carry search hints: (q "Sub Q" (category "news") (recency "week"))
 func extractResearchPlanFlat(input string) (*ResearchPlan, error) {
     input = strings.TrimSpace(input)
     
@@ -48,15 +49,23 @@ func extractResearchPlanFlat(input string) (*ResearchPlan, error) {
                 // Generate ID automatically based on count
                 qID := fmt.Sprintf("q%d", len(plan.SubQuestions)+1)
                 
-                plan.SubQuestions = append(plan.SubQuestions, ResearchQuestion{
+                question := ResearchQuestion{
                     ID:              qID,
                     Question:        qText,
                     SearchQuery:     qText, // Default search query to question text
                     Status:          "pending",
                     Priority:        10,
                     Dependencies:    []string{},
-                })
+                }
                 i++ // Skip next
+
+                // Optional hints: ( field "value" ) right after the text
+                for i+4 < len(tokens) && tokens[i+1].typ == "lparen" && tokens[i+2].typ == "atom" &&
+                    tokens[i+3].typ != "lparen" && tokens[i+3].typ != "rparen" && tokens[i+4].typ == "rparen" {
+                    setSearchHint(&question, tokens[i+2].value, tokens[i+3].value)
+                    i += 4
+                }
+                plan.SubQuestions = append(plan.SubQuestions, question)
             }
         }
     }
@@ -157,7 +166,6 @@ func extractGoalsFromMalformed(input string) []GoalProposal {
     
     for _, block := range goalBlocks {
         goal := GoalProposal{
-            Priority:   7, // Default
             ActionPlan: []string{},
         }
         
@@ -177,6 +185,8 @@ func extractGoalsFromMalformed(input string) []GoalProposal {
         if reason := extractFieldContent(block, "reasoning"); reason != "" {
             goal.Reasoning = reason
         }
+
+        goal.Tier = extractFieldContent(block, "tier")
         
         // Only add if we have at least a description
         if goal.Description != "" {
@@ -675,7 +685,6 @@ func extractGoals(exprs []expr) GoalsOrString {
         }
         
         goal := GoalProposal{
-            Priority:   7, // Default
             ActionPlan: []string{},
         }
         
@@ -702,12 +711,20 @@ func extractGoals(exprs []expr) GoalsOrString {
                 if field.list[1].isAtom {
                     goal.Reasoning = field.list[1].atom
                 }
+            case "tier":
+                if field.list[1].isAtom {
+                    goal.Tier = field.list[1].atom
+                }
             case "action_plan":
                 goal.ActionPlan = extractStringList(field.list[1:])
             case "expected_time":
                 if field.list[1].isAtom {
                     goal.ExpectedTime = field.list[1].atom
                 }
+            case "deadline":
+                if field.list[1].isAtom {
+                    goal.Deadline = field.list[1].atom
+                }
             }
         }
         
@@ -967,6 +984,7 @@ func extractResearchPlanFromMalformed(content string) (*ResearchPlan, error) {
 		Text     string
 		Query    string
 		Priority int
+		Block    string // The whole (question ...) block, for optional fields
 	}
 	
 	var questions []QuestionMatch
@@ -1020,6 +1038,7 @@ func extractResearchPlanFromMalformed(content string) (*ResearchPlan, error) {
 		if query := extractFieldContent(questionBlock, "search_query"); query != "" {
 			q.Query = query
 		}
+		q.Block = questionBlock
 		
 		// Extract priority
 		if prioStr := extractFieldContent(questionBlock, "priority"); prioStr != "" {
@@ -1067,6 +1086,7 @@ func extractResearchPlanFromMalformed(content string) (*ResearchPlan, error) {
 			KeyFindings:     "",
 			ConfidenceLevel: 0.0,
 		}
+		setSearchHintsFromBlock(&plan.SubQuestions[i], q.Block)
 	}
 	
 	return plan, nil
@@ -0,0 +1,165 @@
+// internal/dialogue/source_provenance.go
+package dialogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-llama/internal/tools"
+)
+
+// sourceMetaPrefix namespaces page provenance copied into Action.Metadata so it
+// cannot collide with planning keys such as "goal" or "purpose"
+const sourceMetaPrefix = "source_"
+
+// MetadataResearchSources is the memory metadata key holding a JSON list of the
+// pages a research synthesis drew on, in the order its [n] citations number them
+const MetadataResearchSources = "research_sources"
+
+func sourceMetaKey(key string) string {
+	return sourceMetaPrefix + key
+}
+
+// recordSourceProvenance copies a parse result's provenance into the action's
+// metadata. Fields the page did not supply are left absent.
+func recordSourceProvenance(action *Action, url string, result *tools.ToolResult) {
+	if action.Metadata == nil {
+		action.Metadata = make(map[string]interface{})
+	}
+	action.Metadata[sourceMetaKey("url")] = url
+	for _, key := range tools.PageProvenanceKeys {
+		if value, ok := result.Metadata[key].(string); ok && value != "" {
+			action.Metadata[sourceMetaKey(key)] = value
+		}
+	}
+}
+
+// researchSources collects the provenance of every page parsed for a goal
+func researchSources(goal *Goal) []map[string]string {
+	sources := []map[string]string{}
+	seen := map[string]bool{}
+	for _, action := range goal.Actions {
+		url, ok := action.Metadata[sourceMetaKey("url")].(string)
+		if !ok || url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		source := map[string]string{"url": url}
+		for _, key := range tools.PageProvenanceKeys {
+			if value, ok := action.Metadata[sourceMetaKey(key)].(string); ok && value != "" {
+				source[key] = value
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// encodeResearchSources serialises sources for Memory.Metadata, which only holds
+// flat values
+func encodeResearchSources(sources []map[string]string) string {
+	raw, err := json.Marshal(sources)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+// recordQuestionSource adds the page a completed parse action read to a research
+// question's sources. Actions that parsed no page are ignored.
+func recordQuestionSource(q *ResearchQuestion, action *Action) {
+	if action == nil {
+		return
+	}
+	url, _ := action.Metadata[sourceMetaKey("url")].(string)
+	if url == "" {
+		return
+	}
+
+	found := false
+	for _, existing := range q.SourcesFound {
+		if existing == url {
+			found = true
+			break
+		}
+	}
+	if !found {
+		q.SourcesFound = append(q.SourcesFound, url)
+	}
+	if title, ok := action.Metadata[sourceMetaKey(tools.MetaPageTitle)].(string); ok && title != "" {
+		if q.SourceTitles == nil {
+			q.SourceTitles = make(map[string]string)
+		}
+		q.SourceTitles[url] = title
+	}
+}
+
+// citedSources numbers the pages behind a research synthesis: sources found for each
+// question in plan order, then any other page parsed for the goal. Citation [n] refers
+// to element n-1.
+func citedSources(goal *Goal) []map[string]string {
+	parsed := researchSources(goal)
+	provenance := make(map[string]map[string]string, len(parsed))
+	for _, source := range parsed {
+		provenance[source["url"]] = source
+	}
+
+	cited := []map[string]string{}
+	seen := map[string]bool{}
+	add := func(source map[string]string) {
+		seen[source["url"]] = true
+		cited = append(cited, source)
+	}
+	if goal.ResearchPlan != nil {
+		for _, q := range goal.ResearchPlan.SubQuestions {
+			for _, url := range q.SourcesFound {
+				if url == "" || seen[url] {
+					continue
+				}
+				source, ok := provenance[url]
+				if !ok {
+					source = map[string]string{"url": url}
+					if title := q.SourceTitles[url]; title != "" {
+						source[tools.MetaPageTitle] = title
+					}
+				}
+				add(source)
+			}
+		}
+	}
+	for _, source := range parsed {
+		if !seen[source["url"]] {
+			add(source)
+		}
+	}
+	return cited
+}
+
+// citationNumbers lists the citation numbers of a question's sources, e.g. "[1], [3]"
+func citationNumbers(q ResearchQuestion, cited []map[string]string) string {
+	var refs []string
+	for _, url := range q.SourcesFound {
+		for i, source := range cited {
+			if source["url"] == url {
+				refs = append(refs, fmt.Sprintf("[%d]", i+1))
+				break
+			}
+		}
+	}
+	return strings.Join(refs, ", ")
+}
+
+// formatSourceList renders numbered sources one per line as "[n] Title - URL"
+func formatSourceList(cited []map[string]string) string {
+	var b strings.Builder
+	for i, source := range cited {
+		if title := source[tools.MetaPageTitle]; title != "" {
+			fmt.Fprintf(&b, "[%d] %s - %s\n", i+1, title, source["url"])
+		} else {
+			fmt.Fprintf(&b, "[%d] %s\n", i+1, source["url"])
+		}
+	}
+	return b.String()
+}
@@ -4,9 +4,13 @@ package dialogue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"go-llama/internal/utils"
+
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -19,10 +23,17 @@ type DialogueState struct {
 	KnowledgeGaps             datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"knowledge_gaps"`
 	RecentFailures            datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"recent_failures"`
 	Patterns                  datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"patterns"`
+	FocusAreas                datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"focus_areas"`
 	LastCycleTime             time.Time      `gorm:"not null;default:NOW()" json:"last_cycle_time"`
 	CycleCount                int            `gorm:"not null;default:0" json:"cycle_count"`
 	MigrationMemoryIDComplete       bool      `gorm:"not null;default:false" json:"migration_memory_id_complete"`       // Track if memory_id migration ran
 	MigrationIsCollectiveComplete   bool      `gorm:"not null;default:false" json:"migration_is_collective_complete"`   // Track if is_collective backfill ran
+	MigrationSourceKindComplete     bool      `gorm:"not null;default:false" json:"migration_source_kind_complete"`     // Track if source_kind backfill ran
+	AdaptiveState                   datatypes.JSON `gorm:"type:jsonb" json:"adaptive_state"` // Versioned AdaptiveSnapshot; null until the first cycle ends
+	CycleOwner                      string    `gorm:"type:varchar(64)" json:"cycle_owner,omitempty"` // Lock token of the cycle that last claimed the state
+	SchemaVersion                   int       `gorm:"not null;default:0" json:"schema_version"` // Layout of the JSON fields; see stateSchemaVersion
+	StateVersion                    int       `gorm:"not null;default:0" json:"state_version"` // The StateVersion this row was last saved as
+	StateChecksum                   string    `gorm:"type:char(64)" json:"state_checksum,omitempty"` // Of the saved fields; empty before versioning
 	CreatedAt                       time.Time `json:"created_at"`
 	UpdatedAt                       time.Time `json:"updated_at"`
 }
@@ -44,7 +55,46 @@ type DialogueMetrics struct {
 	GoalsCreated   int       `gorm:"not null;default:0" json:"goals_created"`
 	GoalsCompleted int       `gorm:"not null;default:0" json:"goals_completed"`
 	MemoriesStored int       `gorm:"not null;default:0" json:"memories_stored"`
+	MemoriesMerged int       `gorm:"not null;default:0" json:"memories_merged"`
+	SearchCacheHits   int    `gorm:"not null;default:0" json:"search_cache_hits"`
+	SearchCacheMisses int    `gorm:"not null;default:0" json:"search_cache_misses"`
+	PageCacheHits     int    `gorm:"not null;default:0" json:"page_cache_hits"`
+	PageCacheMisses   int    `gorm:"not null;default:0" json:"page_cache_misses"`
+	SimpleModelCalls    int  `gorm:"not null;default:0" json:"simple_model_calls"`
+	ReasoningModelCalls int  `gorm:"not null;default:0" json:"reasoning_model_calls"`
+	EmptyCompletions    int  `gorm:"not null;default:0" json:"empty_completions"`
+	ContextOverflows    int  `gorm:"not null;default:0" json:"context_overflows"`
+	GoalValidationTokens int `gorm:"not null;default:0" json:"goal_validation_tokens"`
+	MemoryReuseHits     int  `gorm:"not null;default:0" json:"memory_reuse_hits"`
+	SearchLedgerHits    int  `gorm:"not null;default:0" json:"search_ledger_hits"`
+	SearchLedgerMisses  int  `gorm:"not null;default:0" json:"search_ledger_misses"`
+	RepetitiveThoughts  int  `gorm:"not null;default:0" json:"repetitive_thoughts"`
+	PlanAdjustments     int  `gorm:"not null;default:0" json:"plan_adjustments"`
+	Replans             int  `gorm:"not null;default:0" json:"replans"`
+	SynthesisReviews    int  `gorm:"not null;default:0" json:"synthesis_reviews"`
+	SynthesesBelowGate  int  `gorm:"not null;default:0" json:"syntheses_below_gate"`
+	GoalsMerged         int  `gorm:"not null;default:0" json:"goals_merged"`
+	GoalProposalsReceived           int `gorm:"not null;default:0" json:"goal_proposals_received"`
+	GoalProposalsAccepted           int `gorm:"not null;default:0" json:"goal_proposals_accepted"`
+	GoalProposalsInvalid            int `gorm:"not null;default:0" json:"goal_proposals_invalid"`
+	GoalProposalsDuplicateActive    int `gorm:"not null;default:0" json:"goal_proposals_duplicate_active"`
+	GoalProposalsDuplicateAbandoned int `gorm:"not null;default:0" json:"goal_proposals_duplicate_abandoned"`
+	GoalProposalsDowngraded         int `gorm:"not null;default:0" json:"goal_proposals_downgraded"`
+	GoalProposalsClamped            int `gorm:"not null;default:0" json:"goal_proposals_clamped"`
+	GoalProposalsBackpressure       int `gorm:"not null;default:0" json:"goal_proposals_backpressure"`
+	PendingActionBacklog            int `gorm:"not null;default:0" json:"pending_action_backlog"`
+	ReflectionNearDuplicates        int `gorm:"not null;default:0" json:"reflection_near_duplicates"`
+	ReasoningDepth      string `gorm:"type:varchar(20);index" json:"reasoning_depth"`
+	RandomSeed          int64 `gorm:"not null;default:0" json:"random_seed"`
+	SearchThreshold         float64 `gorm:"not null;default:0" json:"search_threshold"`
+	CollectiveThreshold     float64 `gorm:"not null;default:0" json:"collective_threshold"`
+	GoalSimilarityThreshold float64 `gorm:"not null;default:0" json:"goal_similarity_threshold"`
+	PromptTemplates     datatypes.JSON `gorm:"type:jsonb" json:"prompt_templates"` // Structured calls by template name@hash
 	StopReason     string    `gorm:"type:varchar(50);not null" json:"stop_reason"`
+	SkippedReason  string    `gorm:"type:varchar(50);index" json:"skipped_reason,omitempty"`
+	ThoughtLimit    int      `gorm:"not null;default:0" json:"thought_limit"`
+	TokenLimit      int      `gorm:"not null;default:0" json:"token_limit"`
+	DurationLimitMs int      `gorm:"not null;default:0" json:"duration_limit_ms"`
 	CreatedAt      time.Time `json:"created_at"`
 }
 
@@ -58,9 +108,11 @@ type DialogueThought struct {
 	ID          int       `gorm:"primaryKey;autoIncrement" json:"id"`
 	CycleID     int       `gorm:"not null;index:idx_cycle_thought" json:"cycle_id"`
 	ThoughtNum  int       `gorm:"not null;index:idx_cycle_thought" json:"thought_num"`
+	GoalID      string    `gorm:"type:varchar(100);index" json:"goal_id"` // Empty when not about a specific goal
 	Content     string    `gorm:"type:text;not null" json:"content"`
 	TokensUsed  int       `gorm:"not null;default:0" json:"tokens_used"`
 	ActionTaken bool      `gorm:"not null;default:false" json:"action_taken"`
+	PromptTemplate string `gorm:"type:varchar(100);index" json:"prompt_template,omitempty"` // Template version of a structured call
 	Timestamp   time.Time `gorm:"not null;default:NOW()" json:"timestamp"`
 }
 
@@ -84,7 +136,7 @@ func truncateGoalsForStorage(goals []Goal) []Goal {
             
             // Truncate result field if it's too long
             if len(action.Result) > maxActionResultLength {
-                truncated[i].Actions[j].Result = action.Result[:maxActionResultLength] + "... [truncated for storage]"
+                truncated[i].Actions[j].Result = utils.Prefix(action.Result, maxActionResultLength) + "... [truncated for storage]"
             }
             
             // Preserve metadata to maintain action context (e.g., URLs) across cycles
@@ -95,7 +147,11 @@ func truncateGoalsForStorage(goals []Goal) []Goal {
 
 // StateManager handles loading and saving internal state
 type StateManager struct {
-	db *gorm.DB
+	db            *gorm.DB
+	retention     int  // Completed goals kept in state; older ones are archived (0 = default)
+	archiveLookup bool // Recently abandoned windows may reach into the archive
+	versions      int  // Saved state versions kept for rollback (0 = default)
+	failAt        func(step string) error // Test hook interrupting SaveState between steps
 }
 
 // NewStateManager creates a new state manager
@@ -112,65 +168,144 @@ func (sm *StateManager) LoadState(ctx context.Context) (*InternalState, error) {
 		return nil, fmt.Errorf("failed to load dialogue state: %w", err)
 	}
 
-	// Unmarshal JSONB fields into InternalState
-	state := &InternalState{
-		LastCycleTime: dbState.LastCycleTime,
-		CycleCount:    dbState.CycleCount,
-	}
+	// A row damaged since it was saved is replaced by the newest intact saved version
+	sm.verifyState(ctx, &dbState)
 
-	if err := json.Unmarshal(dbState.ActiveGoals, &state.ActiveGoals); err != nil {
-		state.ActiveGoals = []Goal{}
-	}
-	if err := json.Unmarshal(dbState.CompletedGoals, &state.CompletedGoals); err != nil {
-		state.CompletedGoals = []Goal{}
-	}
-	if err := json.Unmarshal(dbState.KnowledgeGaps, &state.KnowledgeGaps); err != nil {
-		state.KnowledgeGaps = []string{}
-	}
-	if err := json.Unmarshal(dbState.RecentFailures, &state.RecentFailures); err != nil {
-		state.RecentFailures = []string{}
+	// Goals saved as records are read back into the goal columns
+	records, missing := sm.expandGoalManifests(ctx, &dbState)
+
+	// Unmarshal JSONB fields into InternalState. Fields that fail are quarantined and
+	// the cycle continues from a fresh state rather than failing every cycle from now on.
+	state, failed := decodeDialogueState(&dbState)
+	for _, field := range stateFields(&dbState, state) {
+		if reason, ok := missing[field.column]; ok {
+			field.reset()
+			failed[field.column] = reason
+		}
 	}
-	if err := json.Unmarshal(dbState.Patterns, &state.Patterns); err != nil {
-		state.Patterns = []string{}
+	state.records = records
+	if len(failed) > 0 {
+		sm.recoverState(ctx, &dbState, state, failed)
 	}
 
 	return state, nil
 }
 
-// SaveState persists the internal state to database
+// ClaimState records token as the owner of the persisted state. A state loaded by an
+// earlier owner can no longer be saved over it.
+func (sm *StateManager) ClaimState(ctx context.Context, token string) error {
+	db := sm.db.WithContext(ctx)
+	if err := db.FirstOrCreate(&DialogueState{}, DialogueState{ID: 1}).Error; err != nil {
+		return fmt.Errorf("failed to load dialogue state: %w", err)
+	}
+	if err := db.Model(&DialogueState{}).Where("id = ?", 1).Update("cycle_owner", token).Error; err != nil {
+		return fmt.Errorf("failed to claim dialogue state: %w", err)
+	}
+	return nil
+}
+
+// SaveState persists the internal state to database. A state loaded by a cycle is
+// only saved while that cycle still owns it; ErrStaleCycle is returned otherwise.
 func (sm *StateManager) SaveState(ctx context.Context, state *InternalState) error {
-	// Truncate goals before marshaling to prevent huge SQL statements
-	activeGoalsTruncated := truncateGoalsForStorage(state.ActiveGoals)
-	completedGoalsTruncated := truncateGoalsForStorage(state.CompletedGoals)
-	
-	// Marshal truncated versions to JSON
-	activeGoals, _ := json.Marshal(activeGoalsTruncated)
-	completedGoals, _ := json.Marshal(completedGoalsTruncated)
+	stats, err := sm.saveState(ctx, state)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Dialogue] Saved state: wrote %d of %d goals and %d of %d actions (%d bytes)",
+		stats.GoalsWritten, stats.GoalsWritten+stats.GoalsUnchanged,
+		stats.ActionsWritten, stats.ActionsWritten+stats.ActionsUnchanged, stats.Bytes)
+	return nil
+}
+
+// saveState is SaveState, returning what it wrote of the goals
+func (sm *StateManager) saveState(ctx context.Context, state *InternalState) (StateSaveStats, error) {
+	// Completed goals beyond the retention move to the archive, oldest first
+	archive, keep := splitCompletedGoals(state.CompletedGoals, sm.completedGoalRetention())
+
+	// Truncate goals before storing them to prevent huge SQL statements. Goals and
+	// actions are stored as records keyed by their content: only those that changed
+	// since the state was loaded are written, and the state row lists them.
+	records := newRecordWriter(state.records)
+	activeGoals := records.manifest(truncateGoalsForStorage(state.ActiveGoals))
+	completedGoals := records.manifest(truncateGoalsForStorage(keep))
 	knowledgeGaps, _ := json.Marshal(state.KnowledgeGaps)
 	recentFailures, _ := json.Marshal(state.RecentFailures)
 	patterns, _ := json.Marshal(state.Patterns)
+	focusAreas, _ := json.Marshal(state.FocusAreas)
 
-	// Update the singleton record
-	updates := map[string]interface{}{
-		"active_goals":    datatypes.JSON(activeGoals),
-		"completed_goals": datatypes.JSON(completedGoals),
-		"knowledge_gaps":  datatypes.JSON(knowledgeGaps),
-		"recent_failures": datatypes.JSON(recentFailures),
-		"patterns":        datatypes.JSON(patterns),
-		"last_cycle_time": state.LastCycleTime,
-		"cycle_count":     state.CycleCount,
-		"updated_at":      time.Now(),
+	// The database keeps microseconds; the checksum must match what is read back
+	snapshot := stateSnapshot{
+		ActiveGoals:    activeGoals,
+		CompletedGoals: completedGoals,
+		KnowledgeGaps:  knowledgeGaps,
+		RecentFailures: recentFailures,
+		Patterns:       patterns,
+		FocusAreas:     focusAreas,
+		LastCycleTime:  state.LastCycleTime.Truncate(time.Microsecond),
+		CycleCount:     state.CycleCount,
+		SchemaVersion:  stateSchemaVersion,
 	}
 
-	if err := sm.db.WithContext(ctx).Model(&DialogueState{}).Where("id = ?", 1).Updates(updates).Error; err != nil {
-		return fmt.Errorf("failed to save dialogue state: %w", err)
+	// Write the state as a new version, then point the singleton record at it. Both
+	// happen in one transaction, so a crash part way leaves the previous save intact.
+	err := sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := archiveGoals(tx, archive); err != nil {
+			return err
+		}
+		if err := records.write(tx); err != nil {
+			return err
+		}
+		version, checksum, err := writeStateVersion(tx, snapshot)
+		if err != nil {
+			return err
+		}
+		if err := sm.failpoint(stateStepVersionWritten); err != nil {
+			return err
+		}
+
+		updates := snapshot.columns()
+		updates["state_version"] = version
+		updates["state_checksum"] = checksum
+		updates["updated_at"] = time.Now()
+		query := tx.Model(&DialogueState{}).Where("id = ?", 1)
+		if state.owner != "" {
+			query = query.Where("cycle_owner = ?", state.owner)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if state.owner != "" && result.RowsAffected == 0 {
+			return ErrStaleCycle
+		}
+		if err := sm.failpoint(stateStepPointerFlipped); err != nil {
+			return err
+		}
+		if err := pruneStateVersions(tx, version, sm.stateVersionsKept()); err != nil {
+			return err
+		}
+		// Records no kept version references are swept once per retention window,
+		// since finding them reads every goal record
+		if version%sm.stateVersionsKept() == 0 {
+			return pruneStateRecords(tx)
+		}
+		return nil
+	})
+	if errors.Is(err, ErrStaleCycle) {
+		return StateSaveStats{}, err
 	}
+	if err != nil {
+		return StateSaveStats{}, fmt.Errorf("failed to save dialogue state: %w", err)
+	}
+	state.CompletedGoals = keep
+	state.records = records.refs
 
-	return nil
+	return records.stats, nil
 }
 
 // SaveMetrics stores cycle performance metrics
 func (sm *StateManager) SaveMetrics(ctx context.Context, metrics *CycleMetrics) error {
+	promptTemplates, _ := json.Marshal(metrics.PromptTemplates)
 	dbMetrics := DialogueMetrics{
 		StartTime:      metrics.StartTime,
 		EndTime:        metrics.EndTime,
@@ -181,7 +316,46 @@ func (sm *StateManager) SaveMetrics(ctx context.Context, metrics *CycleMetrics)
 		GoalsCreated:   metrics.GoalsCreated,
 		GoalsCompleted: metrics.GoalsCompleted,
 		MemoriesStored: metrics.MemoriesStored,
+		MemoriesMerged: metrics.MemoriesMerged,
+		SearchCacheHits:   metrics.SearchCacheHits,
+		SearchCacheMisses: metrics.SearchCacheMisses,
+		PageCacheHits:     metrics.PageCacheHits,
+		PageCacheMisses:   metrics.PageCacheMisses,
+		SimpleModelCalls:    metrics.SimpleModelCalls,
+		ReasoningModelCalls: metrics.ReasoningModelCalls,
+		EmptyCompletions:    metrics.EmptyCompletions,
+		ContextOverflows:    metrics.ContextOverflows,
+		GoalValidationTokens: metrics.GoalValidationTokens,
+		MemoryReuseHits:     metrics.MemoryReuseHits,
+		SearchLedgerHits:    metrics.SearchLedgerHits,
+		SearchLedgerMisses:  metrics.SearchLedgerMisses,
+		RepetitiveThoughts:  metrics.RepetitiveThoughts,
+		PlanAdjustments:     metrics.PlanAdjustments,
+		Replans:             metrics.Replans,
+		SynthesisReviews:    metrics.SynthesisReviews,
+		SynthesesBelowGate:  metrics.SynthesesBelowGate,
+		GoalsMerged:         metrics.GoalsMerged,
+		GoalProposalsReceived:           metrics.GoalProposalsReceived,
+		GoalProposalsAccepted:           metrics.GoalProposalsAccepted,
+		GoalProposalsInvalid:            metrics.GoalProposalsInvalid,
+		GoalProposalsDuplicateActive:    metrics.GoalProposalsDuplicateActive,
+		GoalProposalsDuplicateAbandoned: metrics.GoalProposalsDuplicateAbandoned,
+		GoalProposalsDowngraded:         metrics.GoalProposalsDowngraded,
+		GoalProposalsClamped:            metrics.GoalProposalsClamped,
+		GoalProposalsBackpressure:       metrics.GoalProposalsBackpressure,
+		PendingActionBacklog:            metrics.PendingActionBacklog,
+		ReflectionNearDuplicates:        metrics.ReflectionNearDuplicates,
+		ReasoningDepth:      metrics.ReasoningDepth,
+		RandomSeed:          metrics.RandomSeed,
+		SearchThreshold:         metrics.SearchThreshold,
+		CollectiveThreshold:     metrics.CollectiveThreshold,
+		GoalSimilarityThreshold: metrics.GoalSimilarityThreshold,
+		PromptTemplates:     datatypes.JSON(promptTemplates),
 		StopReason:     metrics.StopReason,
+		SkippedReason:  metrics.SkippedReason,
+		ThoughtLimit:    metrics.ThoughtLimit,
+		TokenLimit:      metrics.TokenLimit,
+		DurationLimitMs: int(metrics.DurationLimit.Milliseconds()),
 	}
 
 	if err := sm.db.WithContext(ctx).Create(&dbMetrics).Error; err != nil {
@@ -196,9 +370,11 @@ func (sm *StateManager) SaveThought(ctx context.Context, thought *ThoughtRecord)
 	dbThought := DialogueThought{
 		CycleID:     thought.CycleID,
 		ThoughtNum:  thought.ThoughtNum,
+		GoalID:      thought.GoalID,
 		Content:     thought.Content,
 		TokensUsed:  thought.TokensUsed,
 		ActionTaken: thought.ActionTaken,
+		PromptTemplate: thought.PromptTemplate,
 		Timestamp:   thought.Timestamp,
 	}
 
@@ -219,8 +395,10 @@ func InitializeDefaultState(db *gorm.DB) error {
 		KnowledgeGaps:  datatypes.JSON([]byte("[]")),
 		RecentFailures: datatypes.JSON([]byte("[]")),
 		Patterns:       datatypes.JSON([]byte("[]")),
+		FocusAreas:     datatypes.JSON([]byte("[]")),
 		LastCycleTime:  time.Now(),
 		CycleCount:     0,
+		SchemaVersion:  stateSchemaVersion,
 	}
 
 	if err := db.Where(DialogueState{ID: 1}).FirstOrCreate(&defaultState).Error; err != nil {
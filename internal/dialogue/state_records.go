@@ -0,0 +1,346 @@
+// internal/dialogue/state_records.go
+package dialogue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// goalRecordsSchemaVersion is the first state schema storing goals as records, with the
+// state row's goal columns holding manifests that list them
+const goalRecordsSchemaVersion = 2
+
+// stateRecordBatch bounds the rows read, written or deleted per statement
+const stateRecordBatch = 200
+
+// GoalRecord is one goal of the persisted state as it was at some save, without its
+// actions, which it lists by record hash. Records are keyed by their content, so a goal
+// that did not change since the state was loaded is not written again.
+type GoalRecord struct {
+	Hash      string         `gorm:"type:char(64);primaryKey" json:"hash"`
+	GoalID    string         `gorm:"type:varchar(100);index" json:"goal_id"`
+	Payload   datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"` // goalRecordBody
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (GoalRecord) TableName() string {
+	return "growerai_dialogue_goal_records"
+}
+
+// GoalActionRecord is one action of a persisted goal, keyed by its content like GoalRecord
+type GoalActionRecord struct {
+	Hash      string         `gorm:"type:char(64);primaryKey" json:"hash"`
+	GoalID    string         `gorm:"type:varchar(100);index" json:"goal_id"`
+	Payload   datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"` // Action
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (GoalActionRecord) TableName() string {
+	return "growerai_dialogue_action_records"
+}
+
+// goalRecordBody is what a GoalRecord holds
+type goalRecordBody struct {
+	Goal    Goal     `json:"goal"` // Without its actions
+	Actions []string `json:"actions"`
+}
+
+// goalManifestEntry is one goal in a goal column of the state row
+type goalManifestEntry struct {
+	ID     string `json:"id"`
+	Record string `json:"record"`
+}
+
+// StateSaveStats is what a save wrote of the state's goals
+type StateSaveStats struct {
+	GoalsWritten     int `json:"goals_written"`
+	GoalsUnchanged   int `json:"goals_unchanged"`
+	ActionsWritten   int `json:"actions_written"`
+	ActionsUnchanged int `json:"actions_unchanged"`
+	Bytes            int `json:"bytes"` // Of the records written and the goal manifests
+}
+
+// encodeRecord returns v's JSON and its content hash. The hash is of the JSON
+// re-encoded, as jsonb keeps its own key order and spacing, so a record read back
+// hashes the same.
+func encodeRecord(v interface{}) (datatypes.JSON, string) {
+	payload, _ := json.Marshal(v)
+	return datatypes.JSON(payload), recordHash(payload)
+}
+
+// recordHash hashes a record's JSON re-encoded; invalid JSON hashes as its raw bytes
+func recordHash(payload []byte) string {
+	var generic interface{}
+	if err := json.Unmarshal(payload, &generic); err == nil {
+		payload, _ = json.Marshal(generic)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordWriter encodes a state's goals as records, keeping those not already stored
+type recordWriter struct {
+	stored  map[string]bool // Records the state was loaded from
+	refs    map[string]bool // Records the encoded goals reference
+	goals   []GoalRecord
+	actions []GoalActionRecord
+	stats   StateSaveStats
+}
+
+func newRecordWriter(stored map[string]bool) *recordWriter {
+	return &recordWriter{stored: stored, refs: make(map[string]bool)}
+}
+
+// manifest encodes goals as records and returns the manifest listing them
+func (w *recordWriter) manifest(goals []Goal) json.RawMessage {
+	entries := make([]goalManifestEntry, 0, len(goals))
+	for _, goal := range goals {
+		body := goalRecordBody{Actions: make([]string, 0, len(goal.Actions))}
+		for _, action := range goal.Actions {
+			payload, hash := encodeRecord(action)
+			body.Actions = append(body.Actions, hash)
+			if w.add(hash) {
+				w.actions = append(w.actions, GoalActionRecord{Hash: hash, GoalID: goal.ID, Payload: payload})
+				w.stats.ActionsWritten++
+				w.stats.Bytes += len(payload)
+			} else {
+				w.stats.ActionsUnchanged++
+			}
+		}
+
+		goal.Actions = nil
+		body.Goal = goal
+		payload, hash := encodeRecord(body)
+		if w.add(hash) {
+			w.goals = append(w.goals, GoalRecord{Hash: hash, GoalID: goal.ID, Payload: payload})
+			w.stats.GoalsWritten++
+			w.stats.Bytes += len(payload)
+		} else {
+			w.stats.GoalsUnchanged++
+		}
+		entries = append(entries, goalManifestEntry{ID: goal.ID, Record: hash})
+	}
+	manifest, _ := json.Marshal(entries)
+	w.stats.Bytes += len(manifest)
+	return manifest
+}
+
+// add references the record hash and reports whether it still has to be written
+func (w *recordWriter) add(hash string) bool {
+	if w.refs[hash] {
+		return false
+	}
+	w.refs[hash] = true
+	return !w.stored[hash]
+}
+
+// write inserts the new records inside tx. A record that is already there, saved by an
+// earlier state with the same content, is left as it is.
+func (w *recordWriter) write(tx *gorm.DB) error {
+	if len(w.actions) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(w.actions, stateRecordBatch).Error; err != nil {
+			return fmt.Errorf("failed to write action records: %w", err)
+		}
+	}
+	if len(w.goals) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(w.goals, stateRecordBatch).Error; err != nil {
+			return fmt.Errorf("failed to write goal records: %w", err)
+		}
+	}
+	return nil
+}
+
+// expandGoalManifests replaces the goal manifests of a state row saved as records with
+// the goals they list, so the row decodes as before. It returns the records read, and
+// the columns whose records are missing or damaged with the reason; those keep their
+// manifest, to be quarantined. Rows from other schema versions are left as they are.
+func (sm *StateManager) expandGoalManifests(ctx context.Context, dbState *DialogueState) (map[string]bool, map[string]string) {
+	stored := make(map[string]bool)
+	failed := make(map[string]string)
+	if dbState.SchemaVersion < goalRecordsSchemaVersion || dbState.SchemaVersion > stateSchemaVersion {
+		return stored, failed
+	}
+
+	db := sm.db.WithContext(ctx)
+	for _, column := range []struct {
+		name string
+		raw  *datatypes.JSON
+	}{
+		{"active_goals", &dbState.ActiveGoals},
+		{"completed_goals", &dbState.CompletedGoals},
+	} {
+		var entries []goalManifestEntry
+		if len(*column.raw) == 0 || json.Unmarshal(*column.raw, &entries) != nil {
+			continue // Decoding quarantines it
+		}
+		goals, err := loadGoalRecords(db, entries, stored)
+		if err != nil {
+			failed[column.name] = err.Error()
+			continue
+		}
+		expanded, _ := json.Marshal(goals)
+		*column.raw = datatypes.JSON(expanded)
+	}
+	return stored, failed
+}
+
+// loadGoalRecords assembles the goals a manifest lists, adding the records it reads to
+// stored. It fails for a record that is missing or does not match its hash.
+func loadGoalRecords(db *gorm.DB, entries []goalManifestEntry, stored map[string]bool) ([]Goal, error) {
+	hashes := make([]string, len(entries))
+	for i, entry := range entries {
+		hashes[i] = entry.Record
+	}
+	goalRecords, err := findRecords(db, &GoalRecord{}, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make([]goalRecordBody, len(entries))
+	actionHashes := []string{}
+	for i, entry := range entries {
+		payload, ok := goalRecords[entry.Record]
+		if !ok {
+			return nil, fmt.Errorf("record %s of goal %s is missing or damaged", entry.Record, entry.ID)
+		}
+		if err := json.Unmarshal(payload, &bodies[i]); err != nil {
+			return nil, fmt.Errorf("record %s of goal %s: %w", entry.Record, entry.ID, err)
+		}
+		actionHashes = append(actionHashes, bodies[i].Actions...)
+	}
+	actionRecords, err := findRecords(db, &GoalActionRecord{}, actionHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	goals := make([]Goal, len(entries))
+	for i, body := range bodies {
+		goal := body.Goal
+		goal.Actions = make([]Action, len(body.Actions))
+		for j, hash := range body.Actions {
+			payload, ok := actionRecords[hash]
+			if !ok {
+				return nil, fmt.Errorf("record %s of an action of goal %s is missing or damaged", hash, goal.ID)
+			}
+			if err := json.Unmarshal(payload, &goal.Actions[j]); err != nil {
+				return nil, fmt.Errorf("record %s of an action of goal %s: %w", hash, goal.ID, err)
+			}
+			stored[hash] = true
+		}
+		stored[entries[i].Record] = true
+		goals[i] = goal
+	}
+	return goals, nil
+}
+
+// findRecords reads the records of model's table with the given hashes, returning the
+// payload of each that still matches its hash
+func findRecords(db *gorm.DB, model interface{}, hashes []string) (map[string][]byte, error) {
+	found := make(map[string][]byte, len(hashes))
+	for start := 0; start < len(hashes); start += stateRecordBatch {
+		batch := hashes[start:min(start+stateRecordBatch, len(hashes))]
+		var rows []struct {
+			Hash    string
+			Payload datatypes.JSON
+		}
+		if err := db.Model(model).Select("hash", "payload").Where("hash IN ?", batch).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read state records: %w", err)
+		}
+		for _, row := range rows {
+			if recordHash(row.Payload) == row.Hash {
+				found[row.Hash] = row.Payload
+			}
+		}
+	}
+	return found, nil
+}
+
+// pruneStateRecords deletes the records that neither the state row nor any kept state
+// version references
+func pruneStateRecords(tx *gorm.DB) error {
+	manifests := []json.RawMessage{}
+	var dbState DialogueState
+	if err := tx.Where("id = ?", 1).First(&dbState).Error; err != nil {
+		return fmt.Errorf("failed to read state for pruning records: %w", err)
+	}
+	if dbState.SchemaVersion >= goalRecordsSchemaVersion {
+		manifests = append(manifests, json.RawMessage(dbState.ActiveGoals), json.RawMessage(dbState.CompletedGoals))
+	}
+	var versions []StateVersion
+	if err := tx.Find(&versions).Error; err != nil {
+		return fmt.Errorf("failed to read state versions for pruning records: %w", err)
+	}
+	for _, version := range versions {
+		var snapshot stateSnapshot
+		if json.Unmarshal(version.Payload, &snapshot) == nil && snapshot.SchemaVersion >= goalRecordsSchemaVersion {
+			manifests = append(manifests, snapshot.ActiveGoals, snapshot.CompletedGoals)
+		}
+	}
+
+	goalRefs := make(map[string]bool)
+	for _, manifest := range manifests {
+		var entries []goalManifestEntry
+		if json.Unmarshal(manifest, &entries) != nil {
+			continue
+		}
+		for _, entry := range entries {
+			goalRefs[entry.Record] = true
+		}
+	}
+
+	// Actions are referenced through the goal records
+	actionRefs := make(map[string]bool)
+	var goalRecords []GoalRecord
+	if err := tx.Select("hash", "payload").Find(&goalRecords).Error; err != nil {
+		return fmt.Errorf("failed to read goal records for pruning: %w", err)
+	}
+	unusedGoals := []string{}
+	for _, record := range goalRecords {
+		if !goalRefs[record.Hash] {
+			unusedGoals = append(unusedGoals, record.Hash)
+			continue
+		}
+		var body goalRecordBody
+		if json.Unmarshal(record.Payload, &body) == nil {
+			for _, hash := range body.Actions {
+				actionRefs[hash] = true
+			}
+		}
+	}
+	var actionHashes []string
+	if err := tx.Model(&GoalActionRecord{}).Pluck("hash", &actionHashes).Error; err != nil {
+		return fmt.Errorf("failed to read action records for pruning: %w", err)
+	}
+	unusedActions := []string{}
+	for _, hash := range actionHashes {
+		if !actionRefs[hash] {
+			unusedActions = append(unusedActions, hash)
+		}
+	}
+
+	if err := deleteRecords(tx, &GoalRecord{}, unusedGoals); err != nil {
+		return err
+	}
+	return deleteRecords(tx, &GoalActionRecord{}, unusedActions)
+}
+
+// deleteRecords deletes the records of model's table with the given hashes
+func deleteRecords(tx *gorm.DB, model interface{}, hashes []string) error {
+	for start := 0; start < len(hashes); start += stateRecordBatch {
+		batch := hashes[start:min(start+stateRecordBatch, len(hashes))]
+		if err := tx.Where("hash IN ?", batch).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to prune state records: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// largeState returns a state of goals × actions, each action with a full-size result
+func largeState(goals, actions int) *InternalState {
+	state := &InternalState{CycleCount: 1, LastCycleTime: time.Now()}
+	for i := 0; i < goals; i++ {
+		goal := Goal{
+			ID:          fmt.Sprintf("goal_%d", i),
+			Description: fmt.Sprintf("Research topic number %d in depth", i),
+			Status:      GoalStatusActive,
+			Priority:    5,
+			Created:     time.Date(2026, 1, 1, 0, 0, i, 0, time.UTC),
+		}
+		for j := 0; j < actions; j++ {
+			goal.Actions = append(goal.Actions, Action{
+				ID:          fmt.Sprintf("act_%d_%d", i, j),
+				Tool:        ActionToolSearch,
+				Description: fmt.Sprintf("Search step %d", j),
+				Status:      ActionStatusCompleted,
+				Result:      strings.Repeat(fmt.Sprintf("finding %d.%d ", i, j), 40),
+				Metadata:    map[string]interface{}{"query": fmt.Sprintf("topic %d step %d", i, j)},
+			})
+		}
+		state.ActiveGoals = append(state.ActiveGoals, goal)
+	}
+	return state
+}
+
+func TestSaveStateWritesOnlyChangedRecords(t *testing.T) {
+	ctx := context.Background()
+	sm, _ := setupVersionedState(t)
+	if _, err := sm.LoadState(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	state := largeState(50, 20)
+	full, err := sm.saveState(ctx, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full.GoalsWritten != 50 || full.ActionsWritten != 1000 {
+		t.Fatalf("expected every record written on the first save, got %+v", full)
+	}
+
+	loaded, err := sm.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(truncateGoalsForStorage(state.ActiveGoals))
+	got, _ := json.Marshal(loaded.ActiveGoals)
+	if string(got) != string(want) {
+		t.Fatal("expected the goals loaded back as saved")
+	}
+
+	// One action changing status touches that action and its goal, nothing else
+	loaded.ActiveGoals[7].Actions[3].Status = ActionStatusBlocked
+	loaded.CycleCount++
+	delta, err := sm.saveState(ctx, loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta.GoalsWritten != 1 || delta.ActionsWritten != 1 || delta.GoalsUnchanged != 49 || delta.ActionsUnchanged != 999 {
+		t.Errorf("expected one goal and one action written, got %+v", delta)
+	}
+	if delta.Bytes*10 > full.Bytes {
+		t.Errorf("expected an order of magnitude less written, got %d bytes against %d", delta.Bytes, full.Bytes)
+	}
+	t.Logf("full save %d bytes, one changed action %d bytes", full.Bytes, delta.Bytes)
+
+	again, err := sm.LoadState(ctx)
+	if err != nil || again.ActiveGoals[7].Actions[3].Status != ActionStatusBlocked || again.CycleCount != 2 {
+		t.Fatalf("expected the change loaded back, got %+v (%v)", again.ActiveGoals[7].Actions[3], err)
+	}
+}
+
+func TestLegacyInlineStateMovesToRecords(t *testing.T) {
+	ctx := context.Background()
+	db := setupRecoveryDB(t)
+	db.Exec(`INSERT INTO growerai_dialogue_state (id, active_goals, cycle_count, schema_version)
+		VALUES (1, '[{"id": "goal_1", "description": "Learn Go", "actions": [{"id": "a1", "tool": "search"}]}]', 4, 1)`)
+	sm := NewStateManager(db)
+
+	state, err := sm.LoadState(ctx)
+	if err != nil || len(state.ActiveGoals) != 1 || len(state.ActiveGoals[0].Actions) != 1 {
+		t.Fatalf("expected the inline goals loaded, got %+v (%v)", state, err)
+	}
+	stats, err := sm.saveState(ctx, state)
+	if err != nil || stats.GoalsWritten != 1 || stats.ActionsWritten != 1 {
+		t.Fatalf("expected the goals written as records, got %+v (%v)", stats, err)
+	}
+
+	var row DialogueState
+	db.First(&row, 1)
+	var manifest []goalManifestEntry
+	if row.SchemaVersion != stateSchemaVersion || json.Unmarshal(row.ActiveGoals, &manifest) != nil || len(manifest) != 1 || manifest[0].Record == "" {
+		t.Fatalf("expected a manifest in the state row, got %s (version %d)", row.ActiveGoals, row.SchemaVersion)
+	}
+	reloaded, err := sm.LoadState(ctx)
+	if err != nil || !reflect.DeepEqual(reloaded.ActiveGoals[0].Actions, state.ActiveGoals[0].Actions) {
+		t.Errorf("expected the goal loaded back from records, got %+v (%v)", reloaded.ActiveGoals, err)
+	}
+}
+
+func TestMissingGoalRecordIsQuarantined(t *testing.T) {
+	ctx := context.Background()
+	db := setupRecoveryDB(t)
+	if err := InitializeDefaultState(db); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewStateManager(db)
+	if _, err := sm.saveState(ctx, largeState(2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec(`DELETE FROM growerai_dialogue_action_records WHERE hash IN (SELECT hash FROM growerai_dialogue_action_records LIMIT 1)`)
+
+	state, err := sm.LoadState(ctx)
+	if err != nil || len(state.ActiveGoals) != 0 {
+		t.Fatalf("expected the active goals reset, got %d goals (%v)", len(state.ActiveGoals), err)
+	}
+	var quarantined []StateQuarantine
+	db.Find(&quarantined)
+	if len(quarantined) != 1 || quarantined[0].Field != "active_goals" || !strings.Contains(quarantined[0].Reason, "missing or damaged") {
+		t.Fatalf("expected the manifest quarantined, got %+v", quarantined)
+	}
+	if again, err := sm.LoadState(ctx); err != nil || len(again.ActiveGoals) != 0 {
+		t.Errorf("expected a clean reload, got %+v (%v)", again, err)
+	}
+}
+
+func TestUnreferencedRecordsArePruned(t *testing.T) {
+	ctx := context.Background()
+	sm, db := setupVersionedState(t)
+	sm.SetStateVersions(2)
+
+	for cycle := 1; cycle <= 6; cycle++ {
+		state, err := sm.LoadState(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state.CycleCount = cycle
+		state.ActiveGoals = []Goal{{ID: "goal_1", Description: fmt.Sprintf("Goal at cycle %d", cycle),
+			Actions: []Action{{ID: "a1", Result: fmt.Sprintf("result %d", cycle)}}}}
+		if _, err := sm.saveState(ctx, state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only the two kept versions' goal and action remain
+	var goals, actions int64
+	db.Model(&GoalRecord{}).Count(&goals)
+	db.Model(&GoalActionRecord{}).Count(&actions)
+	if goals != 2 || actions != 2 {
+		t.Errorf("expected records of the 2 kept versions, got %d goals and %d actions", goals, actions)
+	}
+	if state, err := sm.LoadState(ctx); err != nil || state.ActiveGoals[0].Actions[0].Result != "result 6" {
+		t.Errorf("expected the latest state intact, got %+v (%v)", state, err)
+	}
+}
+
+// BenchmarkSaveStateOneChangedAction saves a state of 50 goals × 20 actions in which one
+// action changed since it was loaded
+func BenchmarkSaveStateOneChangedAction(b *testing.B) {
+	ctx := context.Background()
+	sm, _ := setupVersionedState(b)
+	full, err := sm.saveState(ctx, largeState(50, 20))
+	if err != nil {
+		b.Fatal(err)
+	}
+	state, err := sm.LoadState(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	bytes := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state.ActiveGoals[i%50].Actions[i%20].Result = fmt.Sprintf("changed %d", i)
+		stats, err := sm.saveState(ctx, state)
+		if err != nil {
+			b.Fatal(err)
+		}
+		bytes += stats.Bytes
+	}
+	b.ReportMetric(float64(bytes)/float64(b.N), "bytes/save")
+	b.ReportMetric(float64(full.Bytes), "full-bytes")
+}
@@ -0,0 +1,154 @@
+// internal/dialogue/state_recovery.go
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// stateSchemaVersion is the layout of the state's JSON fields that SaveState writes.
+// Bump it when Goal or another persisted type changes incompatibly and register a
+// migration from the previous version in stateMigrations.
+const stateSchemaVersion = 2
+
+// stateMigrations upgrades a state row from the version it is keyed by to the next one.
+// A row with no registered path to stateSchemaVersion is quarantined and recovered.
+var stateMigrations = map[int]func(*DialogueState) error{
+	// Rows saved before versioning already have the version 1 layout
+	0: func(*DialogueState) error { return nil },
+	// Version 1 holds its goals inline, which still decode; the next save writes them as
+	// records (goalRecordsSchemaVersion)
+	1: func(*DialogueState) error { return nil },
+}
+
+// StateQuarantine keeps a persisted state field that could not be loaded, so it can be
+// inspected or repaired by hand after the engine has started over without it
+type StateQuarantine struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Field         string    `gorm:"type:varchar(50);not null" json:"field"`
+	Blob          string    `gorm:"type:text;not null" json:"blob"`
+	Reason        string    `gorm:"type:text;not null" json:"reason"`
+	SchemaVersion int       `gorm:"not null;default:0" json:"schema_version"`
+	CycleCount    int       `gorm:"not null;default:0" json:"cycle_count"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (StateQuarantine) TableName() string {
+	return "growerai_dialogue_state_quarantine"
+}
+
+// stateField is one JSON column of the state row and where it decodes to
+type stateField struct {
+	column string
+	raw    datatypes.JSON
+	target interface{}
+	reset  func()
+}
+
+func stateFields(dbState *DialogueState, state *InternalState) []stateField {
+	return []stateField{
+		{"active_goals", dbState.ActiveGoals, &state.ActiveGoals, func() { state.ActiveGoals = []Goal{} }},
+		{"completed_goals", dbState.CompletedGoals, &state.CompletedGoals, func() { state.CompletedGoals = []Goal{} }},
+		{"knowledge_gaps", dbState.KnowledgeGaps, &state.KnowledgeGaps, func() { state.KnowledgeGaps = []string{} }},
+		{"recent_failures", dbState.RecentFailures, &state.RecentFailures, func() { state.RecentFailures = []string{} }},
+		{"patterns", dbState.Patterns, &state.Patterns, func() { state.Patterns = []string{} }},
+		{"focus_areas", dbState.FocusAreas, &state.FocusAreas, func() { state.FocusAreas = []FocusArea{} }},
+	}
+}
+
+// migrateDialogueState runs the migrations from the row's version to stateSchemaVersion
+func migrateDialogueState(dbState *DialogueState) error {
+	if dbState.SchemaVersion > stateSchemaVersion {
+		return fmt.Errorf("state schema version %d is newer than %d", dbState.SchemaVersion, stateSchemaVersion)
+	}
+	for dbState.SchemaVersion < stateSchemaVersion {
+		migrate, ok := stateMigrations[dbState.SchemaVersion]
+		if !ok {
+			return fmt.Errorf("no migration from state schema version %d", dbState.SchemaVersion)
+		}
+		if err := migrate(dbState); err != nil {
+			return fmt.Errorf("state migration from version %d failed: %w", dbState.SchemaVersion, err)
+		}
+		dbState.SchemaVersion++
+	}
+	return nil
+}
+
+// decodeDialogueState turns a state row into an InternalState. Fields that cannot be
+// decoded are reset and returned with the reason, for quarantine. A row that cannot be
+// migrated has every field quarantined, keeping whatever still decodes.
+func decodeDialogueState(dbState *DialogueState) (*InternalState, map[string]string) {
+	state := &InternalState{
+		LastCycleTime: dbState.LastCycleTime,
+		CycleCount:    dbState.CycleCount,
+	}
+	failed := map[string]string{}
+
+	migrationErr := migrateDialogueState(dbState)
+	for _, field := range stateFields(dbState, state) {
+		if len(field.raw) == 0 || string(field.raw) == "null" {
+			field.reset()
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.target); err != nil {
+			field.reset()
+			failed[field.column] = err.Error()
+		} else if migrationErr != nil {
+			failed[field.column] = migrationErr.Error()
+		}
+	}
+	return state, failed
+}
+
+// recoverState quarantines the fields that failed to load, takes the cycle count from
+// the metrics history if the row lost it, and writes the reset fields back so the next
+// load starts clean
+func (sm *StateManager) recoverState(ctx context.Context, dbState *DialogueState, state *InternalState, failed map[string]string) {
+	log.Printf("[Dialogue] ERROR: Persisted state is corrupt or unreadable (%d fields), recovering", len(failed))
+
+	db := sm.db.WithContext(ctx)
+	updates := map[string]interface{}{"schema_version": stateSchemaVersion}
+	for _, field := range stateFields(dbState, state) {
+		reason, ok := failed[field.column]
+		if !ok {
+			continue
+		}
+		log.Printf("[Dialogue] ERROR: Quarantining state field %s (%d bytes): %s", field.column, len(field.raw), reason)
+		entry := StateQuarantine{
+			Field:         field.column,
+			Blob:          string(field.raw),
+			Reason:        reason,
+			SchemaVersion: dbState.SchemaVersion,
+			CycleCount:    dbState.CycleCount,
+		}
+		if err := db.Create(&entry).Error; err != nil {
+			log.Printf("[Dialogue] ERROR: Failed to quarantine state field %s: %v", field.column, err)
+			continue
+		}
+		fresh, _ := json.Marshal(field.target)
+		updates[field.column] = datatypes.JSON(fresh)
+	}
+
+	var lastCycle int
+	if err := db.Model(&DialogueMetrics{}).Select("COALESCE(MAX(cycle_id), 0)").Scan(&lastCycle).Error; err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to read cycle count from metrics history: %v", err)
+	} else if lastCycle > state.CycleCount {
+		log.Printf("[Dialogue] Restoring cycle count %d from metrics history (state had %d)", lastCycle, state.CycleCount)
+		state.CycleCount = lastCycle
+		updates["cycle_count"] = lastCycle
+	}
+
+	if err := db.Model(&DialogueState{}).Where("id = ?", 1).Updates(updates).Error; err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to write recovered state: %v", err)
+	} else if err := resealState(db); err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to reseal recovered state: %v", err)
+	}
+	log.Printf("[Dialogue] Recovered state: %d active goals, %d completed goals kept, cycle count %d",
+		len(state.ActiveGoals), len(state.CompletedGoals), state.CycleCount)
+}
@@ -0,0 +1,99 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupRecoveryDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := setupProvenanceDB(t)
+	if err := db.AutoMigrate(&StateQuarantine{}, &DialogueMetrics{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestLoadStateQuarantinesTruncatedBlob(t *testing.T) {
+	ctx := context.Background()
+	db := setupRecoveryDB(t)
+	db.Exec(`INSERT INTO growerai_dialogue_state (id, active_goals, knowledge_gaps, cycle_count, schema_version)
+		VALUES (1, '[{"id": "goal_1", "description": "Learn Go"}, {"id": "goal_2", "desc', '["gap"]', 3, 1)`)
+	for i := 0; i < 7; i++ {
+		db.Create(&DialogueMetrics{StopReason: StopReasonNaturalStop})
+	}
+
+	sm := NewStateManager(db)
+	state, err := sm.LoadState(ctx)
+	if err != nil {
+		t.Fatalf("expected a corrupt state to be recovered, got %v", err)
+	}
+	if len(state.ActiveGoals) != 0 || len(state.KnowledgeGaps) != 1 || state.CycleCount != 7 {
+		t.Errorf("expected intact fields kept and the cycle count from metrics, got %+v", state)
+	}
+
+	var quarantined []StateQuarantine
+	db.Find(&quarantined)
+	if len(quarantined) != 1 || quarantined[0].Field != "active_goals" || quarantined[0].CycleCount != 3 {
+		t.Fatalf("expected the truncated blob quarantined, got %+v", quarantined)
+	}
+
+	// The reset field was written back, so the next load is clean
+	if again, err := sm.LoadState(ctx); err != nil || again.CycleCount != 7 {
+		t.Fatalf("unexpected reload %+v (%v)", again, err)
+	}
+	var count int64
+	db.Model(&StateQuarantine{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected nothing more quarantined on reload, got %d entries", count)
+	}
+}
+
+func TestLoadStateMigratesOrQuarantinesByVersion(t *testing.T) {
+	ctx := context.Background()
+	db := setupRecoveryDB(t)
+	goals := `[{"id": "goal_1", "description": "Learn Go"}]`
+
+	// Rows from before versioning load through the version 0 migration
+	db.Exec(`INSERT INTO growerai_dialogue_state (id, active_goals, cycle_count) VALUES (1, ?, 4)`, goals)
+	sm := NewStateManager(db)
+	state, err := sm.LoadState(ctx)
+	if err != nil || len(state.ActiveGoals) != 1 || state.CycleCount != 4 {
+		t.Fatalf("expected an unversioned row to load, got %+v (%v)", state, err)
+	}
+	var count int64
+	db.Model(&StateQuarantine{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected nothing quarantined for a migrated row, got %d", count)
+	}
+
+	// A row from a newer schema keeps what decodes but is backed up first
+	db.Exec(`UPDATE growerai_dialogue_state SET schema_version = ? WHERE id = 1`, stateSchemaVersion+1)
+	state, err = sm.LoadState(ctx)
+	if err != nil || len(state.ActiveGoals) != 1 {
+		t.Fatalf("expected known fields salvaged, got %+v (%v)", state, err)
+	}
+	var quarantined []StateQuarantine
+	db.Find(&quarantined)
+	if len(quarantined) == 0 || quarantined[0].SchemaVersion != stateSchemaVersion+1 {
+		t.Fatalf("expected the newer blobs quarantined, got %+v", quarantined)
+	}
+	var version int
+	db.Raw(`SELECT schema_version FROM growerai_dialogue_state WHERE id = 1`).Scan(&version)
+	if version != stateSchemaVersion {
+		t.Errorf("expected the recovered row stamped with version %d, got %d", stateSchemaVersion, version)
+	}
+}
+
+func TestMigrateDialogueStateNeedsAPath(t *testing.T) {
+	row := &DialogueState{SchemaVersion: -1}
+	if err := migrateDialogueState(row); err == nil {
+		t.Error("expected an error for a version with no migration")
+	}
+	row = &DialogueState{}
+	if err := migrateDialogueState(row); err != nil || row.SchemaVersion != stateSchemaVersion {
+		t.Errorf("expected version 0 migrated to %d, got %d (%v)", stateSchemaVersion, row.SchemaVersion, err)
+	}
+}
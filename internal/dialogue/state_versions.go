@@ -0,0 +1,222 @@
+// internal/dialogue/state_versions.go
+package dialogue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// DefaultStateVersions is how many saved states are kept to roll back to
+const DefaultStateVersions = 5
+
+// Steps of SaveState a failpoint can interrupt
+const (
+	stateStepVersionWritten = "version_written" // The new version row exists; the state still points at the old one
+	stateStepPointerFlipped = "pointer_flipped" // The state points at the new version; older versions are not yet pruned
+)
+
+// StateVersion is one saved state, checksummed so a damaged copy is detected on load.
+// Each save writes a new version and points the state row at it in the same
+// transaction; the last few are kept to roll back to.
+type StateVersion struct {
+	Version    int            `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Payload    datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"` // stateSnapshot
+	Checksum   string         `gorm:"type:char(64);not null" json:"checksum"`
+	CycleCount int            `gorm:"not null;default:0" json:"cycle_count"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (StateVersion) TableName() string {
+	return "growerai_dialogue_state_versions"
+}
+
+// stateSnapshot is the part of the state row a version holds and the checksum covers.
+// Ownership, migration flags and the adaptive state are left out: they are written
+// outside SaveState.
+type stateSnapshot struct {
+	ActiveGoals    json.RawMessage `json:"active_goals"`
+	CompletedGoals json.RawMessage `json:"completed_goals"`
+	KnowledgeGaps  json.RawMessage `json:"knowledge_gaps"`
+	RecentFailures json.RawMessage `json:"recent_failures"`
+	Patterns       json.RawMessage `json:"patterns"`
+	FocusAreas     json.RawMessage `json:"focus_areas"`
+	LastCycleTime  time.Time       `json:"last_cycle_time"`
+	CycleCount     int             `json:"cycle_count"`
+	SchemaVersion  int             `json:"schema_version"`
+}
+
+// snapshotOf reads the versioned part of a state row
+func snapshotOf(dbState *DialogueState) stateSnapshot {
+	return stateSnapshot{
+		ActiveGoals:    json.RawMessage(dbState.ActiveGoals),
+		CompletedGoals: json.RawMessage(dbState.CompletedGoals),
+		KnowledgeGaps:  json.RawMessage(dbState.KnowledgeGaps),
+		RecentFailures: json.RawMessage(dbState.RecentFailures),
+		Patterns:       json.RawMessage(dbState.Patterns),
+		FocusAreas:     json.RawMessage(dbState.FocusAreas),
+		LastCycleTime:  dbState.LastCycleTime,
+		CycleCount:     dbState.CycleCount,
+		SchemaVersion:  dbState.SchemaVersion,
+	}
+}
+
+// columns returns the state row updates that put the snapshot back
+func (s stateSnapshot) columns() map[string]interface{} {
+	return map[string]interface{}{
+		"active_goals":    datatypes.JSON(s.ActiveGoals),
+		"completed_goals": datatypes.JSON(s.CompletedGoals),
+		"knowledge_gaps":  datatypes.JSON(s.KnowledgeGaps),
+		"recent_failures": datatypes.JSON(s.RecentFailures),
+		"patterns":        datatypes.JSON(s.Patterns),
+		"focus_areas":     datatypes.JSON(s.FocusAreas),
+		"last_cycle_time": s.LastCycleTime,
+		"cycle_count":     s.CycleCount,
+		"schema_version":  s.SchemaVersion,
+	}
+}
+
+// checksum hashes the snapshot. JSON fields are hashed re-encoded, since jsonb stores
+// them with its own key order and spacing, and the time at the database's precision.
+// A field that is not valid JSON hashes as its raw bytes, so it never matches a clean copy.
+func (s stateSnapshot) checksum() string {
+	h := sha256.New()
+	for _, raw := range []json.RawMessage{s.ActiveGoals, s.CompletedGoals, s.KnowledgeGaps, s.RecentFailures, s.Patterns, s.FocusAreas} {
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			h.Write([]byte("invalid:"))
+			h.Write(raw)
+		} else {
+			canonical, _ := json.Marshal(v)
+			h.Write(canonical)
+		}
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(s.LastCycleTime.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(s.CycleCount) + ":" + strconv.Itoa(s.SchemaVersion)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SetStateVersions sets how many saved states are kept to roll back to (default 5)
+func (sm *StateManager) SetStateVersions(keep int) {
+	if keep <= 0 {
+		keep = DefaultStateVersions
+	}
+	sm.versions = keep
+}
+
+// stateVersionsKept returns the configured number of versions or the default
+func (sm *StateManager) stateVersionsKept() int {
+	if sm.versions <= 0 {
+		return DefaultStateVersions
+	}
+	return sm.versions
+}
+
+// failpoint lets tests interrupt SaveState between steps, as a crash would
+func (sm *StateManager) failpoint(step string) error {
+	if sm.failAt == nil {
+		return nil
+	}
+	return sm.failAt(step)
+}
+
+// writeStateVersion saves snapshot as the next version inside tx and returns the
+// version and its checksum
+func writeStateVersion(tx *gorm.DB, snapshot stateSnapshot) (int, string, error) {
+	var latest int
+	if err := tx.Model(&StateVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+		return 0, "", fmt.Errorf("failed to read latest state version: %w", err)
+	}
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to encode state version: %w", err)
+	}
+	version := StateVersion{
+		Version:    latest + 1,
+		Payload:    datatypes.JSON(payload),
+		Checksum:   snapshot.checksum(),
+		CycleCount: snapshot.CycleCount,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return 0, "", fmt.Errorf("failed to write state version: %w", err)
+	}
+	return version.Version, version.Checksum, nil
+}
+
+// pruneStateVersions deletes versions older than the last keep
+func pruneStateVersions(tx *gorm.DB, latest, keep int) error {
+	if err := tx.Where("version <= ?", latest-keep).Delete(&StateVersion{}).Error; err != nil {
+		return fmt.Errorf("failed to prune state versions: %w", err)
+	}
+	return nil
+}
+
+// resealState recomputes the checksum of the state row after it was changed outside SaveState
+func resealState(tx *gorm.DB) error {
+	var dbState DialogueState
+	if err := tx.Where("id = ?", 1).First(&dbState).Error; err != nil {
+		return err
+	}
+	return tx.Model(&DialogueState{}).Where("id = ?", 1).Update("state_checksum", snapshotOf(&dbState).checksum()).Error
+}
+
+// verifyState checks the loaded state row against its checksum. A row that fails it
+// was damaged after it was saved, and is replaced by the newest saved version that
+// still verifies, falling back through older ones. Rows saved before versioning have
+// no checksum and are trusted as they are.
+func (sm *StateManager) verifyState(ctx context.Context, dbState *DialogueState) {
+	if dbState.StateChecksum == "" || snapshotOf(dbState).checksum() == dbState.StateChecksum {
+		return
+	}
+	log.Printf("[Dialogue] ERROR: Persisted state fails its checksum (version %d, cycle %d), restoring a saved version",
+		dbState.StateVersion, dbState.CycleCount)
+
+	db := sm.db.WithContext(ctx)
+	var versions []StateVersion
+	if err := db.Order("version DESC").Find(&versions).Error; err != nil {
+		log.Printf("[Dialogue] ERROR: Failed to load state versions, loading the state as it is: %v", err)
+		return
+	}
+	for _, version := range versions {
+		var snapshot stateSnapshot
+		if err := json.Unmarshal(version.Payload, &snapshot); err != nil || snapshot.checksum() != version.Checksum {
+			log.Printf("[Dialogue] ERROR: State version %d (cycle %d) is damaged too, trying an older one", version.Version, version.CycleCount)
+			continue
+		}
+
+		updates := snapshot.columns()
+		updates["state_version"] = version.Version
+		updates["state_checksum"] = version.Checksum
+		if err := db.Model(&DialogueState{}).Where("id = ?", 1).Updates(updates).Error; err != nil {
+			log.Printf("[Dialogue] WARNING: Failed to write restored state back: %v", err)
+		}
+		dbState.ActiveGoals = datatypes.JSON(snapshot.ActiveGoals)
+		dbState.CompletedGoals = datatypes.JSON(snapshot.CompletedGoals)
+		dbState.KnowledgeGaps = datatypes.JSON(snapshot.KnowledgeGaps)
+		dbState.RecentFailures = datatypes.JSON(snapshot.RecentFailures)
+		dbState.Patterns = datatypes.JSON(snapshot.Patterns)
+		dbState.FocusAreas = datatypes.JSON(snapshot.FocusAreas)
+		dbState.LastCycleTime = snapshot.LastCycleTime
+		dbState.CycleCount = snapshot.CycleCount
+		dbState.SchemaVersion = snapshot.SchemaVersion
+		dbState.StateVersion, dbState.StateChecksum = version.Version, version.Checksum
+		log.Printf("[Dialogue] Restored state from version %d (cycle %d)", version.Version, version.CycleCount)
+		return
+	}
+	// Accept the row as it is, so the next load does not report it again
+	log.Printf("[Dialogue] ERROR: No intact state version to restore, loading the state as it is")
+	if err := resealState(db); err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to reseal state: %v", err)
+	}
+}
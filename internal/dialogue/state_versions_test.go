@@ -0,0 +1,128 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// setupVersionedState returns a state manager over an initialized state row
+func setupVersionedState(t testing.TB) (*StateManager, *gorm.DB) {
+	t.Helper()
+	db := setupProvenanceDB(t)
+	if err := InitializeDefaultState(db); err != nil {
+		t.Fatal(err)
+	}
+	return NewStateManager(db), db
+}
+
+// saveCycle saves a state at cycle with one active goal named after it
+func saveCycle(t *testing.T, sm *StateManager, cycle int) error {
+	t.Helper()
+	state := &InternalState{
+		CycleCount:  cycle,
+		ActiveGoals: []Goal{{ID: "goal_" + string(rune('0'+cycle)), Description: "Goal from a cycle", Status: GoalStatusActive}},
+	}
+	return sm.SaveState(context.Background(), state)
+}
+
+func loadedCycle(t *testing.T, sm *StateManager) (int, string) {
+	t.Helper()
+	state, err := sm.LoadState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.ActiveGoals) != 1 {
+		t.Fatalf("expected one active goal, got %+v", state.ActiveGoals)
+	}
+	return state.CycleCount, state.ActiveGoals[0].ID
+}
+
+func TestInterruptedSaveLosesOnlyThatCycle(t *testing.T) {
+	for _, step := range []string{stateStepVersionWritten, stateStepPointerFlipped} {
+		sm, db := setupVersionedState(t)
+		for cycle := 1; cycle <= 2; cycle++ {
+			if err := saveCycle(t, sm, cycle); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		crash := errors.New("crash")
+		sm.failAt = func(at string) error {
+			if at == step {
+				return crash
+			}
+			return nil
+		}
+		if err := saveCycle(t, sm, 3); !errors.Is(err, crash) {
+			t.Fatalf("%s: expected the save interrupted, got %v", step, err)
+		}
+		sm.failAt = nil
+
+		if cycle, goal := loadedCycle(t, sm); cycle != 2 || goal != "goal_2" {
+			t.Errorf("%s: expected cycle 2 intact, loaded cycle %d with %s", step, cycle, goal)
+		}
+		var versions int64
+		db.Model(&StateVersion{}).Count(&versions)
+		if versions != 2 {
+			t.Errorf("%s: expected the interrupted version rolled back, have %d versions", step, versions)
+		}
+
+		// The next cycle saves normally
+		if err := saveCycle(t, sm, 3); err != nil {
+			t.Fatal(err)
+		}
+		if cycle, _ := loadedCycle(t, sm); cycle != 3 {
+			t.Errorf("%s: expected cycle 3 after a clean save, got %d", step, cycle)
+		}
+	}
+}
+
+func TestLoadStateRestoresDamagedState(t *testing.T) {
+	sm, db := setupVersionedState(t)
+	for cycle := 1; cycle <= 2; cycle++ {
+		if err := saveCycle(t, sm, cycle); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A write that landed outside SaveState, leaving goals that do not match the checksum
+	db.Exec(`UPDATE growerai_dialogue_state SET active_goals = '[{"id": "goal_half"}]' WHERE id = 1`)
+	if cycle, goal := loadedCycle(t, sm); cycle != 2 || goal != "goal_2" {
+		t.Errorf("expected the latest version restored, loaded cycle %d with %s", cycle, goal)
+	}
+	// The restored state was written back and verifies on the next load
+	if cycle, goal := loadedCycle(t, sm); cycle != 2 || goal != "goal_2" {
+		t.Errorf("expected the restored state kept, loaded cycle %d with %s", cycle, goal)
+	}
+
+	// With the latest version damaged too, the one before it is used
+	db.Exec(`UPDATE growerai_dialogue_state SET cycle_count = 9 WHERE id = 1`)
+	db.Exec(`UPDATE growerai_dialogue_state_versions SET checksum = 'bad' WHERE version = 2`)
+	if cycle, goal := loadedCycle(t, sm); cycle != 1 || goal != "goal_1" {
+		t.Errorf("expected a fall back to version 1, loaded cycle %d with %s", cycle, goal)
+	}
+}
+
+func TestStateVersionsArePruned(t *testing.T) {
+	sm, db := setupVersionedState(t)
+	sm.SetStateVersions(2)
+	for cycle := 1; cycle <= 4; cycle++ {
+		if err := saveCycle(t, sm, cycle); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var versions []StateVersion
+	db.Order("version").Find(&versions)
+	if len(versions) != 2 || versions[0].Version != 3 || versions[0].CycleCount != 3 {
+		t.Errorf("expected versions 3 and 4 kept, got %+v", versions)
+	}
+
+	var row DialogueState
+	db.First(&row, 1)
+	if row.StateVersion != 4 || row.StateChecksum == "" {
+		t.Errorf("expected the state pointing at version 4 with a checksum, got %d %q", row.StateVersion, row.StateChecksum)
+	}
+}
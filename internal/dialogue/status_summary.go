@@ -0,0 +1,170 @@
+// internal/dialogue/status_summary.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go-llama/internal/memory"
+	"go-llama/internal/utils"
+)
+
+// Status summary sizes; the token budget is estimated at four characters per token
+const (
+	statusSummaryGoals       = 5 // Active goals listed, most recently pursued first
+	statusSummaryCompletions = 3 // Completed goals listed, most recent first
+	statusCharsPerToken      = 4
+	statusMinDigestChars     = 80 // A digest cut shorter than this is left out
+)
+
+// statusQuestionPhrases match a chat message asking what the system has been doing
+var statusQuestionPhrases = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bwhat (?:are|have) you (?:been )?(?:working on|researching|looking into|learning(?: about)?|exploring|studying|reading about|up to)\b`),
+	regexp.MustCompile(`(?i)\bwhat(?:'s| is) (?:on )?your (?:current )?(?:goals?|focus|research|agenda|mind)\b`),
+	regexp.MustCompile(`(?i)\bwhat (?:are )?your (?:current )?(?:goals|research topics)\b`),
+	regexp.MustCompile(`(?i)\bwhat did you (?:learn|research|find out|work on)\b`),
+	regexp.MustCompile(`(?i)\b(?:anything|what(?:'s)?) new (?:in|from) your (?:research|learning)\b`),
+}
+
+// DetectStatusQuestion reports whether a chat message asks what the system has been
+// working on, researching or learning
+func DetectStatusQuestion(content string) bool {
+	for _, pattern := range statusQuestionPhrases {
+		if pattern.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportStatusSummary returns a compact factual summary of active goals, recent
+// completions and the latest digest, within roughly maxTokens. Goals derived from any
+// user's personal memories are left out; it returns "" when there is nothing to report.
+func (e *Engine) ExportStatusSummary(ctx context.Context, maxTokens int) string {
+	return e.ExportStatusSummaryForUser(ctx, "", maxTokens)
+}
+
+// ExportStatusSummaryForUser is ExportStatusSummary for one user, who also sees goals
+// derived only from their own personal memories
+func (e *Engine) ExportStatusSummaryForUser(ctx context.Context, userID string, maxTokens int) string {
+	if e.stateManager == nil {
+		return ""
+	}
+	state, err := e.stateManager.LoadState(ctx)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: Failed to load state for status summary: %v", err)
+		return ""
+	}
+
+	var digest *memory.Memory
+	if e.storage != nil && e.embedder != nil {
+		digests, err := e.recentDigests(ctx, 1)
+		if err != nil {
+			log.Printf("[Dialogue] WARNING: Failed to load digest for status summary: %v", err)
+		} else if len(digests) > 0 && visibleToUser(memory.SourceUserIDsFromMetadata(digests[0].Metadata), userID) {
+			digest = &digests[0]
+		}
+	}
+	return buildStatusSummary(state, digest, userID, maxTokens)
+}
+
+// visibleToUser reports whether content drawn from sourceUserIDs' personal memories may
+// be shown to userID: only content drawn from no one's, or from theirs alone
+func visibleToUser(sourceUserIDs []string, userID string) bool {
+	for _, id := range sourceUserIDs {
+		if id != userID || userID == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// buildStatusSummary formats the goals and digest userID may see, adding lines until
+// the next one would exceed maxTokens
+func buildStatusSummary(state *InternalState, digest *memory.Memory, userID string, maxTokens int) string {
+	active := visibleGoals(state.ActiveGoals, userID, "")
+	completed := visibleGoals(state.CompletedGoals, userID, GoalStatusCompleted)
+	if len(active) == 0 && len(completed) == 0 && digest == nil {
+		return ""
+	}
+
+	budget := maxTokens * statusCharsPerToken
+	var b strings.Builder
+	add := func(line string) bool {
+		if b.Len()+len(line)+1 > budget {
+			return false
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		return true
+	}
+
+	if !add("YOUR CURRENT ACTIVITY (from your own records; describe only this, do not invent more):") {
+		return ""
+	}
+	// Questions come first: a goal stays paused until the user answers
+	if questions := awaitingAnswer(active); len(questions) > 0 && add("Questions for the user (a goal is paused until they answer):") {
+		for _, g := range questions {
+			if !add(fmt.Sprintf("- %s (goal %s: %s)", g.PendingUserQuestion.Question, g.ID, g.Description)) {
+				break
+			}
+		}
+	}
+	if len(active) > 0 && add("Working on:") {
+		for i, g := range active {
+			if i == statusSummaryGoals || !add(fmt.Sprintf("- %s (%.0f%% done)", g.Description, g.Progress*100)) {
+				break
+			}
+		}
+	}
+	if len(completed) > 0 && add("Recently completed:") {
+		for i, g := range completed {
+			line := "- " + g.Description
+			if g.Outcome != "" {
+				line += fmt.Sprintf(" (outcome: %s)", g.Outcome)
+			}
+			if i == statusSummaryCompletions || !add(line) {
+				break
+			}
+		}
+	}
+	if digest != nil {
+		// A digest is long, so it is cut to what is left of the budget rather than dropped
+		line := fmt.Sprintf("Latest digest (%s): %s", digest.CreatedAt.Format("2006-01-02"), digest.Content)
+		if room := budget - b.Len() - len("...") - 1; len(line) > room && room >= statusMinDigestChars {
+			line = strings.TrimSpace(utils.Prefix(line, room)) + "..."
+		}
+		add(line)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// awaitingAnswer returns the goals paused on a question for the user
+func awaitingAnswer(goals []Goal) []Goal {
+	waiting := []Goal{}
+	for _, g := range goals {
+		if g.Status == GoalStatusAwaitingUser && g.PendingUserQuestion != nil {
+			waiting = append(waiting, g)
+		}
+	}
+	return waiting
+}
+
+// visibleGoals returns the goals with status (any when empty) that userID may see, most
+// recently pursued first
+func visibleGoals(goals []Goal, userID, status string) []Goal {
+	visible := []Goal{}
+	for _, g := range goals {
+		if (status == "" || g.Status == status) && visibleToUser(goalSourceUserIDs(&g), userID) {
+			visible = append(visible, g)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].LastPursued.After(visible[j].LastPursued)
+	})
+	return visible
+}
@@ -0,0 +1,85 @@
+package dialogue
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+)
+
+func TestDetectStatusQuestion(t *testing.T) {
+	for _, msg := range []string{
+		"What have you been researching lately?",
+		"hey, what are you working on?",
+		"What's your current focus",
+		"what did you learn this week?",
+	} {
+		if !DetectStatusQuestion(msg) {
+			t.Errorf("expected %q detected as a status question", msg)
+		}
+	}
+	for _, msg := range []string{"What is a goroutine?", "Can you research bees for me?"} {
+		if DetectStatusQuestion(msg) {
+			t.Errorf("expected %q not detected", msg)
+		}
+	}
+}
+
+func TestStatusSummaryHidesOtherUsersGoals(t *testing.T) {
+	now := time.Now()
+	mine := Goal{Description: "Compare sourdough starters", LastPursued: now.Add(-time.Hour)}
+	tagGoalSources(&mine, []string{"7"})
+	theirs := Goal{Description: "Plan a trip to Lisbon", LastPursued: now}
+	tagGoalSources(&theirs, []string{"9"})
+	state := &InternalState{
+		ActiveGoals: []Goal{
+			{Description: "Learn Go generics", Progress: 0.4, LastPursued: now.Add(-2 * time.Hour)},
+			mine,
+			theirs,
+		},
+		CompletedGoals: []Goal{
+			{Description: "Survey bee navigation", Status: GoalStatusCompleted, Outcome: "good"},
+			{Description: "Abandoned idea", Status: "abandoned"},
+		},
+	}
+	digest := &memory.Memory{Content: "DIGEST [daily, 2026-10-14]: Read about generics.", CreatedAt: now}
+
+	summary := buildStatusSummary(state, digest, "7", 500)
+	for _, want := range []string{"- Compare sourdough starters (0% done)", "- Learn Go generics (40% done)", "- Survey bee navigation (outcome: good)", "Read about generics."} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("expected %q in summary:\n%s", want, summary)
+		}
+	}
+	for _, hidden := range []string{"Lisbon", "Abandoned"} {
+		if strings.Contains(summary, hidden) {
+			t.Errorf("expected %q left out of summary:\n%s", hidden, summary)
+		}
+	}
+	if strings.Index(summary, "sourdough") > strings.Index(summary, "generics (40%") {
+		t.Errorf("expected the most recently pursued goal first:\n%s", summary)
+	}
+
+	if summary := buildStatusSummary(state, nil, "", 500); strings.Contains(summary, "sourdough") || strings.Contains(summary, "Lisbon") {
+		t.Errorf("expected no personally derived goals without a user:\n%s", summary)
+	}
+}
+
+func TestStatusSummaryStaysWithinBudget(t *testing.T) {
+	state := &InternalState{}
+	for i := 0; i < 20; i++ {
+		state.ActiveGoals = append(state.ActiveGoals, Goal{Description: strings.Repeat("goal ", 10)})
+	}
+	digest := &memory.Memory{Content: strings.Repeat("digest ", 200), CreatedAt: time.Now()}
+
+	summary := buildStatusSummary(state, digest, "", 100)
+	if len(summary) > 100*statusCharsPerToken {
+		t.Errorf("expected at most %d chars, got %d", 100*statusCharsPerToken, len(summary))
+	}
+	if strings.Count(summary, "\n- ") > statusSummaryGoals {
+		t.Errorf("expected at most %d goals listed:\n%s", statusSummaryGoals, summary)
+	}
+	if buildStatusSummary(&InternalState{}, nil, "", 100) != "" {
+		t.Error("expected no summary with nothing to report")
+	}
+}
@@ -0,0 +1,66 @@
+// internal/dialogue/stop_reasons.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stopReasonWindow is how many recent cycles the logged stop reason histogram covers
+const stopReasonWindow = 20
+
+// budgetStopReason reports which cycle budget has run out, or "" while all remain. The
+// deadline is checked first since nothing more can run past it, then tokens, so a cycle
+// that exhausts both budgets on the same thought is attributed to the scarcer resource.
+func (e *Engine) budgetStopReason(ctx context.Context, thoughtCount, totalTokens int) string {
+	if ctx.Err() != nil {
+		return StopReasonTimeout
+	}
+	if e.maxTokensPerCycle > 0 && totalTokens >= e.maxTokensPerCycle {
+		return StopReasonMaxTokens
+	}
+	if e.maxThoughtsPerCycle > 0 && thoughtCount >= e.maxThoughtsPerCycle {
+		return StopReasonMaxThoughts
+	}
+	return ""
+}
+
+// RecentMetrics returns the last n cycles' metrics, newest first
+func (sm *StateManager) RecentMetrics(ctx context.Context, n int) ([]DialogueMetrics, error) {
+	var cycles []DialogueMetrics
+	if err := sm.db.WithContext(ctx).Order("cycle_id DESC").Limit(n).Find(&cycles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load cycle metrics: %w", err)
+	}
+	return cycles, nil
+}
+
+// StopReasonHistogram counts how often each stop reason ended the given cycles
+func StopReasonHistogram(cycles []DialogueMetrics) map[string]int {
+	histogram := make(map[string]int)
+	for _, c := range cycles {
+		histogram[c.StopReason]++
+	}
+	return histogram
+}
+
+// formatHistogram renders a histogram as "reason=count" pairs, most frequent first
+func formatHistogram(histogram map[string]int) string {
+	reasons := make([]string, 0, len(histogram))
+	for reason := range histogram {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if histogram[reasons[i]] != histogram[reasons[j]] {
+			return histogram[reasons[i]] > histogram[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s=%d", reason, histogram[reason])
+	}
+	return strings.Join(parts, " ")
+}
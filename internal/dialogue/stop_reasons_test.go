@@ -0,0 +1,55 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBudgetStopReason(t *testing.T) {
+	e := &Engine{maxThoughtsPerCycle: 3, maxTokensPerCycle: 1000}
+	ctx := context.Background()
+
+	cases := []struct {
+		thoughts, tokens int
+		want             string
+	}{
+		{1, 200, ""},
+		{3, 200, StopReasonMaxThoughts},
+		{1, 1000, StopReasonMaxTokens},
+		{3, 1500, StopReasonMaxTokens}, // Both exhausted: tokens are reported
+	}
+	for _, tc := range cases {
+		if got := e.budgetStopReason(ctx, tc.thoughts, tc.tokens); got != tc.want {
+			t.Errorf("%d thoughts, %d tokens: got %q, want %q", tc.thoughts, tc.tokens, got, tc.want)
+		}
+	}
+
+	expired, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := e.budgetStopReason(expired, 0, 0); got != StopReasonTimeout {
+		t.Errorf("expired context: got %q, want %q", got, StopReasonTimeout)
+	}
+
+	unlimited := &Engine{}
+	if got := unlimited.budgetStopReason(ctx, 100, 100000); got != "" {
+		t.Errorf("zero limits should not stop a cycle, got %q", got)
+	}
+}
+
+func TestStopReasonHistogram(t *testing.T) {
+	cycles := []DialogueMetrics{
+		{StopReason: StopReasonNaturalStop},
+		{StopReason: StopReasonMaxTokens},
+		{StopReason: StopReasonNaturalStop},
+		{StopReason: StopReasonTimeout},
+		{StopReason: StopReasonMaxTokens},
+		{StopReason: StopReasonNaturalStop},
+	}
+	histogram := StopReasonHistogram(cycles)
+	if histogram[StopReasonNaturalStop] != 3 || histogram[StopReasonMaxTokens] != 2 || histogram[StopReasonTimeout] != 1 {
+		t.Fatalf("unexpected histogram: %v", histogram)
+	}
+	if got, want := formatHistogram(histogram), "natural_stop=3 max_tokens=2 timeout=1"; got != want {
+		t.Errorf("formatHistogram = %q, want %q", got, want)
+	}
+}
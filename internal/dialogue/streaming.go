@@ -0,0 +1,91 @@
+// internal/dialogue/streaming.go
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-llama/internal/goal"
+)
+
+// StreamingConfig controls streaming of the long synthesis and deep reflection calls, so
+// the LLM client can stop them once they loop, run past max_tokens or hit the deadline
+type StreamingConfig struct {
+	Enabled           bool
+	MinSynthesisChars int // A cut-short synthesis at least this long is still stored
+}
+
+// Validate rejects a minimum that would keep empty syntheses
+func (c StreamingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.MinSynthesisChars <= 0 {
+		return fmt.Errorf("streaming.min_synthesis_chars must be positive, got %d", c.MinSynthesisChars)
+	}
+	return nil
+}
+
+// SetStreaming configures which calls are streamed and how much of a cut-short
+// synthesis is worth keeping
+func (e *Engine) SetStreaming(cfg StreamingConfig) {
+	e.streaming = cfg
+}
+
+// ErrTruncatedCompletion is returned, along with the partial content, when a streamed
+// completion was cut short. Callers decide whether the partial content is usable.
+var ErrTruncatedCompletion = errors.New("LLM completion was cut short")
+
+// streamedCallTypes are the calls long enough to be worth streaming
+var streamedCallTypes = map[LLMCallType]bool{
+	CallSynthesis:      true,
+	CallDeepReflection: true,
+}
+
+// streamCaller is implemented by LLM clients that stream a completion and return it
+// assembled as a non-streaming response body
+type streamCaller interface {
+	CallStream(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error)
+}
+
+// CallStream streams the call when the wrapped client can, resubmitting it while the
+// queue preempts it
+func (r *requeueingCaller) CallStream(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	if s, ok := r.caller.(streamCaller); ok {
+		return r.requeue(ctx, url, payload, s.CallStream)
+	}
+	return r.requeue(ctx, url, payload, r.caller.Call)
+}
+
+// sendCompletion streams the request when streaming is on for callType and the client
+// supports it, and makes a plain call otherwise
+func (e *Engine) sendCompletion(ctx context.Context, client goal.LLMCaller, callType LLMCallType, url string, reqBody map[string]interface{}) ([]byte, error) {
+	if e.streaming.Enabled && streamedCallTypes[callType] {
+		if s, ok := client.(streamCaller); ok {
+			return s.CallStream(ctx, url, reqBody)
+		}
+	}
+	return client.Call(ctx, url, reqBody)
+}
+
+// truncatedCompletion counts a cut-short completion and returns the error reporting it
+func (e *Engine) truncatedCompletion(callType LLMCallType, reason string, length int) error {
+	if e.modelRouter != nil {
+		e.modelRouter.RecordTruncated(callType)
+	}
+	log.Printf("[Dialogue] WARNING: %s completion cut short (%s) after %d chars", callType, reason, length)
+	return fmt.Errorf("%w (%s: %s)", ErrTruncatedCompletion, callType, reason)
+}
+
+// keepTruncatedSynthesis reports whether a cut-short synthesis is long enough to store.
+// Unlike structured reasoning, plain text is still usable without its ending.
+func (e *Engine) keepTruncatedSynthesis(synthesis string) bool {
+	if len(synthesis) < e.streaming.MinSynthesisChars {
+		log.Printf("[Dialogue] Discarding cut-short synthesis (%d of %d chars needed)", len(synthesis), e.streaming.MinSynthesisChars)
+		return false
+	}
+	log.Printf("[Dialogue] Keeping cut-short synthesis (%d chars)", len(synthesis))
+	return true
+}
@@ -0,0 +1,113 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// streamingCaller answers streamed calls with reply, cut short when truncated is set,
+// and counts which path each call took
+type streamingCaller struct {
+	reply     string
+	truncated bool
+	calls     int
+	streams   int
+}
+
+func (s *streamingCaller) body() ([]byte, error) {
+	finish := "stop"
+	if s.truncated {
+		finish = "repetition"
+	}
+	return json.Marshal(map[string]interface{}{
+		"choices":   []map[string]interface{}{{"message": map[string]string{"content": s.reply}, "finish_reason": finish}},
+		"usage":     map[string]int{"total_tokens": 10},
+		"truncated": s.truncated,
+	})
+}
+
+func (s *streamingCaller) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	s.calls++
+	return s.body()
+}
+
+func (s *streamingCaller) CallStream(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	s.streams++
+	return s.body()
+}
+
+func streamingEngine(caller interface{}, minChars int) *Engine {
+	e := &Engine{llmClient: caller, modelRouter: NewModelRouter("http://reasoning", "8b", "", "")}
+	e.SetStreaming(StreamingConfig{Enabled: true, MinSynthesisChars: minChars})
+	return e
+}
+
+func synthesisGoal() *Goal {
+	return &Goal{ResearchPlan: &ResearchPlan{
+		RootQuestion: "How do bees navigate?",
+		SubQuestions: []ResearchQuestion{{ID: "q1", Question: "Do bees use the sun?", Status: ResearchStatusCompleted, KeyFindings: "Yes, as a compass.", ConfidenceLevel: 0.8}},
+	}}
+}
+
+func TestOnlyLongCallsAreStreamed(t *testing.T) {
+	caller := &streamingCaller{reply: "Done."}
+	e := streamingEngine(caller, 10)
+
+	if _, _, err := e.callLLM(context.Background(), "Synthesize.", CallSynthesis); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := e.callLLM(context.Background(), "Evaluate.", CallEvaluation); err != nil {
+		t.Fatal(err)
+	}
+	if caller.streams != 1 || caller.calls != 1 {
+		t.Errorf("expected synthesis streamed and the evaluation called plainly, got %d streams, %d calls", caller.streams, caller.calls)
+	}
+
+	e.SetStreaming(StreamingConfig{})
+	e.callLLM(context.Background(), "Synthesize.", CallSynthesis)
+	if caller.streams != 1 {
+		t.Errorf("expected no streaming once disabled, got %d streams", caller.streams)
+	}
+}
+
+func TestTruncatedSynthesisKeptWhenLongEnough(t *testing.T) {
+	long := strings.Repeat("Bees navigate by the sun. ", 10)
+	e := streamingEngine(&streamingCaller{reply: long, truncated: true}, 100)
+
+	synthesis, _, err := e.synthesizeResearchFindings(context.Background(), synthesisGoal())
+	if err != nil || synthesis != strings.TrimSpace(long) {
+		t.Fatalf("expected the long partial synthesis kept, got %q, %v", synthesis, err)
+	}
+	if got := e.ModelRoutingStats().ByCallType["synthesis"].Truncated; got != 1 {
+		t.Errorf("expected one truncated synthesis counted, got %d", got)
+	}
+
+	e = streamingEngine(&streamingCaller{reply: "Bees navigate", truncated: true}, 100)
+	if _, _, err := e.synthesizeResearchFindings(context.Background(), synthesisGoal()); !errors.Is(err, ErrTruncatedCompletion) {
+		t.Errorf("expected a short partial synthesis to fail, got %v", err)
+	}
+}
+
+func TestStructuredReasoningRejectsTruncatedCompletion(t *testing.T) {
+	e := streamingEngine(&streamingCaller{reply: `(reasoning (reflection "Half a thou`, truncated: true}, 1)
+
+	_, _, err := e.callLLMWithPrincipleSet(context.Background(), "Reflect.", true, "", nil, CallDeepReflection)
+	if !errors.Is(err, ErrTruncatedCompletion) {
+		t.Fatalf("expected ErrTruncatedCompletion, got %v", err)
+	}
+}
+
+func TestRequeueingCallerStreamsWhenSupported(t *testing.T) {
+	streamer := &streamingCaller{reply: "ok"}
+	if _, err := (&requeueingCaller{caller: streamer}).CallStream(context.Background(), "http://llm", nil); err != nil || streamer.streams != 1 {
+		t.Errorf("expected the wrapped client to stream, got %d streams (%v)", streamer.streams, err)
+	}
+
+	plain := &scriptedCaller{errs: []error{preemptedErr{}}}
+	if _, err := (&requeueingCaller{caller: plain}).CallStream(context.Background(), "http://llm", nil); err != nil || plain.calls != 2 {
+		t.Errorf("expected a plain call with requeueing for a client that can't stream, got %d calls (%v)", plain.calls, err)
+	}
+}
@@ -0,0 +1,209 @@
+// internal/dialogue/synthesis_gate.go
+package dialogue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"go-llama/internal/tools"
+)
+
+// What the gate does with a synthesis scored below its minimum
+const (
+	SynthesisGateDiscount = "discount" // Store it with importance and trust scaled down
+	SynthesisGateRetry    = "retry"    // Replan the research once, then store as for discount
+
+	DefaultSynthesisMinScore = 0.6
+)
+
+// SynthesisGateConfig controls the quality review a research synthesis gets before it
+// is stored. A disabled gate stores every synthesis as before.
+type SynthesisGateConfig struct {
+	Enabled  bool
+	MinScore float64 // Review score (0-1) below which OnFail applies
+	OnFail   string  // SynthesisGateDiscount or SynthesisGateRetry
+}
+
+// Validate rejects a minimum score outside 0-1 and an unknown OnFail
+func (c SynthesisGateConfig) Validate() error {
+	if c.MinScore < 0 || c.MinScore > 1 {
+		return fmt.Errorf("synthesis_gate.min_score must be between 0 and 1, got %.2f", c.MinScore)
+	}
+	switch c.OnFail {
+	case "", SynthesisGateDiscount, SynthesisGateRetry:
+		return nil
+	}
+	return fmt.Errorf("synthesis_gate.on_fail must be %q or %q, got %q", SynthesisGateDiscount, SynthesisGateRetry, c.OnFail)
+}
+
+// SetSynthesisGate configures the review of research syntheses
+func (e *Engine) SetSynthesisGate(cfg SynthesisGateConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	e.synthesisGate = cfg
+	return nil
+}
+
+// synthesisGateConfig returns the configured gate with defaults filled in
+func (e *Engine) synthesisGateConfig() SynthesisGateConfig {
+	cfg := e.synthesisGate
+	if cfg.MinScore == 0 {
+		cfg.MinScore = DefaultSynthesisMinScore
+	}
+	if cfg.OnFail == "" {
+		cfg.OnFail = SynthesisGateDiscount
+	}
+	return cfg
+}
+
+// SynthesisReview is the quality gate's verdict on a research synthesis
+type SynthesisReview struct {
+	Specificity float64
+	Coverage    float64 // Of the root question
+	Consistency float64
+	Score       float64
+	Missing     []string // Aspects of the root question left unanswered
+}
+
+// Metadata returns the review as stored on the synthesis memory. Nested metadata keeps
+// only scalar values, so the missing aspects are joined with "; ".
+func (r *SynthesisReview) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"specificity": r.Specificity,
+		"coverage":    r.Coverage,
+		"consistency": r.Consistency,
+		"score":       r.Score,
+		"missing":     strings.Join(r.Missing, "; "),
+	}
+}
+
+// reviewSynthesis has the simple model score a synthesis of the goal's research
+func (e *Engine) reviewSynthesis(ctx context.Context, goal *Goal, synthesis string) (*SynthesisReview, int, error) {
+	var questions strings.Builder
+	for i, q := range goal.ResearchPlan.SubQuestions {
+		questions.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, q.Status, q.Question))
+	}
+	wrapped, _ := tools.WrapUntrusted("research synthesis built from web pages", synthesis)
+
+	response, tokens, err := e.callPrompt(ctx, PromptSynthesisReview, synthesisReviewPrompt{
+		RootQuestion: goal.ResearchPlan.RootQuestion,
+		Questions:    questions.String(),
+		Synthesis:    wrapped,
+	}, false, CallSynthesisReview)
+	if err != nil {
+		return nil, tokens, fmt.Errorf("synthesis review failed: %w", err)
+	}
+	review, err := parseSynthesisReview(response.RawResponse)
+	return review, tokens, err
+}
+
+// parseSynthesisReview reads the synthesis_review S-expression. Scores are clamped to
+// 0-1; a missing overall score is the mean of the three criteria.
+func parseSynthesisReview(rawResponse string) (*SynthesisReview, error) {
+	content := strings.TrimSpace(rawResponse)
+	content = strings.TrimPrefix(content, "```lisp")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	blocks := findBlocksRecursive(content, "synthesis_review")
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no synthesis_review block in response: %s", truncate(content, 100))
+	}
+	block := blocks[0]
+
+	score := func(field string) (float64, bool) {
+		value, err := strconv.ParseFloat(extractFieldContent(block, field), 64)
+		if err != nil {
+			return 0, false
+		}
+		return clampThreshold(value, 0, 1), true
+	}
+	review := &SynthesisReview{Missing: extractMultipleFieldContents(block, "aspect")}
+	var ok [3]bool
+	review.Specificity, ok[0] = score("specificity")
+	review.Coverage, ok[1] = score("coverage")
+	review.Consistency, ok[2] = score("consistency")
+	if overall, found := score("score"); found {
+		review.Score = overall
+	} else if ok[0] && ok[1] && ok[2] {
+		review.Score = (review.Specificity + review.Coverage + review.Consistency) / 3
+	} else {
+		return nil, fmt.Errorf("synthesis review has no score: %s", truncate(block, 100))
+	}
+	return review, nil
+}
+
+// synthesisStoreScores returns the importance and trust of a synthesis memory. Trust
+// follows how well-supported the answers were, and importance keeps a floor so even a
+// shaky synthesis outranks an ordinary memory, unless the review scored it below
+// minScore: then both are scaled by score/minScore.
+func synthesisStoreScores(plan *ResearchPlan, review *SynthesisReview, minScore float64) (importance, trust float64) {
+	importance, trust = 0.9, 0.8
+	if confidence, _, ok := researchConfidence(plan); ok {
+		trust = confidence
+		importance = 0.5 + 0.4*confidence
+	}
+	if review != nil && review.Score < minScore {
+		factor := review.Score / minScore
+		importance *= factor
+		trust *= factor
+	}
+	return importance, trust
+}
+
+// completeResearch synthesizes the goal's findings and stores them behind the quality
+// gate. A synthesis scored below the gate's minimum is stored with reduced importance
+// and trust or, when the gate retries, the research is replanned once around what the
+// synthesis missed and nothing is stored yet. It reports whether a synthesis was stored
+// and returns the tokens used.
+func (e *Engine) completeResearch(ctx context.Context, goal *Goal, metrics *CycleMetrics) (bool, int, error) {
+	ctx = withGoalID(ctx, goal.ID)
+	synthesis, tokens, err := e.synthesizeResearchFindings(ctx, goal)
+	if err != nil {
+		return false, tokens, err
+	}
+
+	gate := e.synthesisGateConfig()
+	var review *SynthesisReview
+	if gate.Enabled {
+		r, reviewTokens, err := e.reviewSynthesis(ctx, goal, synthesis)
+		tokens += reviewTokens
+		if err != nil {
+			// The gate is advisory; a failed review stores the synthesis as before
+			log.Printf("[Dialogue] WARNING: Storing synthesis for goal %s unreviewed: %v", goal.ID, err)
+		} else {
+			review = r
+			metrics.SynthesisReviews++
+		}
+	}
+
+	if review != nil && review.Score < gate.MinScore {
+		metrics.SynthesesBelowGate++
+		log.Printf("[Dialogue] Synthesis for goal %s scored %.2f (minimum %.2f), missing: %s",
+			goal.ID, review.Score, gate.MinScore, strings.Join(review.Missing, "; "))
+
+		if gate.OnFail == SynthesisGateRetry && !goal.SynthesisRetried && goal.ReplanCount < e.replanLimit() {
+			reason := fmt.Sprintf("The synthesis scored %.2f and left unanswered: %s", review.Score, strings.Join(review.Missing, "; "))
+			plan, replanTokens, err := e.replanGoal(ctx, goal, reason)
+			tokens += replanTokens
+			if err == nil {
+				applyReplan(goal, plan, reason)
+				e.recordReplan(ctx, goal)
+				goal.SynthesisRetried = true
+				metrics.Replans++
+				log.Printf("[Dialogue] Replanned goal %s to retry its synthesis", goal.ID)
+				return false, tokens, nil
+			}
+			log.Printf("[Dialogue] WARNING: Failed to replan goal %s after a weak synthesis, storing it discounted: %v", goal.ID, err)
+		}
+	}
+
+	if err := e.storeResearchSynthesis(ctx, goal, synthesis, review); err != nil {
+		return false, tokens, err
+	}
+	return true, tokens, nil
+}
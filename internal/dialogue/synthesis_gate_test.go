@@ -0,0 +1,85 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const weakSynthesisReview = `(synthesis_review (specificity 0.2) (coverage 0.4) (consistency 0.9) (score 0.3) (missing (aspect "How the waggle dance encodes distance") (aspect "Role of polarized light")))`
+
+func TestParseSynthesisReview(t *testing.T) {
+	review, err := parseSynthesisReview("```lisp\n" + weakSynthesisReview + "\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Score != 0.3 || review.Specificity != 0.2 || review.Consistency != 0.9 || len(review.Missing) != 2 || review.Missing[1] != "Role of polarized light" {
+		t.Errorf("unexpected review %+v", review)
+	}
+
+	// Without an overall score the criteria are averaged; scores are clamped to 0-1
+	review, err = parseSynthesisReview(`(synthesis_review (specificity 1.5) (coverage 0.5) (consistency 0.5))`)
+	if err != nil || review.Score != (1.0+0.5+0.5)/3 || len(review.Missing) != 0 {
+		t.Errorf("expected the mean of clamped criteria, got %+v, %v", review, err)
+	}
+	if _, err := parseSynthesisReview(`(synthesis_review (missing))`); err == nil {
+		t.Error("expected a review without scores rejected")
+	}
+}
+
+func TestSynthesisStoreScoresDiscountBelowGate(t *testing.T) {
+	near := func(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 }
+	plan := synthesisGoal().ResearchPlan
+	importance, trust := synthesisStoreScores(plan, nil, DefaultSynthesisMinScore)
+	if !near(importance, 0.82) || !near(trust, 0.8) {
+		t.Fatalf("expected unreviewed scores from research confidence, got %.2f/%.2f", importance, trust)
+	}
+
+	passed, passedTrust := synthesisStoreScores(plan, &SynthesisReview{Score: 0.7}, 0.6)
+	if passed != importance || passedTrust != trust {
+		t.Errorf("expected a passing review to leave scores alone, got %.2f/%.2f", passed, passedTrust)
+	}
+	weak, weakTrust := synthesisStoreScores(plan, &SynthesisReview{Score: 0.3}, 0.6)
+	if !near(weak, importance/2) || !near(weakTrust, trust/2) {
+		t.Errorf("expected scores halved at half the minimum, got %.2f/%.2f", weak, weakTrust)
+	}
+}
+
+func TestWeakSynthesisRetriesOnce(t *testing.T) {
+	e, caller := planEngine(t, "Bees may navigate somehow.", weakSynthesisReview, replannedPlan)
+	if err := e.SetSynthesisGate(SynthesisGateConfig{Enabled: true, MinScore: 0.6, OnFail: SynthesisGateRetry}); err != nil {
+		t.Fatal(err)
+	}
+	goal := synthesisGoal()
+	metrics := &CycleMetrics{}
+
+	stored, tokens, err := e.completeResearch(context.Background(), goal, metrics)
+	if err != nil || stored || tokens != 30 {
+		t.Fatalf("expected a replan instead of storing, got stored %v, %d tokens, %v", stored, tokens, err)
+	}
+	if len(caller.prompts) != 3 || !strings.Contains(caller.prompts[1], "Bees may navigate somehow.") {
+		t.Fatalf("expected synthesis, review and replan calls, got %d", len(caller.prompts))
+	}
+	if !strings.Contains(caller.prompts[2], "How the waggle dance encodes distance") {
+		t.Error("expected the replan told what the synthesis missed")
+	}
+	if !goal.SynthesisRetried || goal.ReplanCount != 1 || goal.ResearchPlan.RootQuestion != "How do honeybees find their way?" {
+		t.Errorf("expected the goal replanned once, got retried %v, count %d", goal.SynthesisRetried, goal.ReplanCount)
+	}
+	if metrics.SynthesisReviews != 1 || metrics.SynthesesBelowGate != 1 || metrics.Replans != 1 {
+		t.Errorf("expected the review counted, got %+v", metrics)
+	}
+}
+
+func TestSynthesisGateConfig(t *testing.T) {
+	e := &Engine{}
+	if cfg := e.synthesisGateConfig(); cfg.Enabled || cfg.MinScore != DefaultSynthesisMinScore || cfg.OnFail != SynthesisGateDiscount {
+		t.Errorf("expected an unconfigured gate off with defaults, got %+v", cfg)
+	}
+	if err := e.SetSynthesisGate(SynthesisGateConfig{Enabled: true, MinScore: 1.2}); err == nil {
+		t.Error("expected a minimum score above 1 rejected")
+	}
+	if err := e.SetSynthesisGate(SynthesisGateConfig{Enabled: true, OnFail: "drop"}); err == nil {
+		t.Error("expected an unknown on_fail rejected")
+	}
+}
@@ -0,0 +1,49 @@
+// internal/dialogue/token_budget.go
+package dialogue
+
+import (
+	"log"
+	"time"
+)
+
+// TokenBudget reports how much of the hosted LLM token budget is used. At the soft
+// limit calls move to the simple model; at the hard limit cycles pause.
+type TokenBudget interface {
+	OverSoftLimit() bool
+	OverHardLimit() bool
+}
+
+// SetTokenBudget makes the engine and its model router follow budget's limits
+func (e *Engine) SetTokenBudget(budget TokenBudget) {
+	e.tokenBudget = budget
+	if e.modelRouter != nil {
+		e.modelRouter.SetBudget(budget)
+	}
+}
+
+// PublishBudgetAlert reports a crossed budget limit to event subscribers
+func (e *Engine) PublishBudgetAlert(level, period string, used, limit int64) {
+	e.publishEvent(EventBudgetAlert, "", "", map[string]interface{}{
+		"level":  level,
+		"period": period,
+		"used":   used,
+		"limit":  limit,
+	})
+}
+
+// recordBudgetCycle counts and reports a tick paused at the hard budget limit
+func (e *Engine) recordBudgetCycle() {
+	const reason = "hosted LLM token budget exhausted"
+	now := time.Now()
+
+	e.skipMu.Lock()
+	e.skipStats.Total++
+	e.skipStats.Budget++
+	e.skipStats.LastReason = reason
+	e.skipStats.LastSkippedAt = &now
+	paused := e.skipStats.Budget
+	e.skipMu.Unlock()
+
+	log.Printf("[Dialogue] Skipping cycle: %s (%d paused for the budget so far)", reason, paused)
+	e.publishEvent(EventCycleSkipped, "", "", map[string]interface{}{"reason": reason})
+}
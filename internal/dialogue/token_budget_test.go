@@ -0,0 +1,63 @@
+package dialogue
+
+import (
+	"context"
+	"testing"
+)
+
+// fixedBudget is a TokenBudget at a set level
+type fixedBudget struct{ soft, hard bool }
+
+func (b *fixedBudget) OverSoftLimit() bool { return b.soft || b.hard }
+func (b *fixedBudget) OverHardLimit() bool { return b.hard }
+
+func TestSoftBudgetDowngradesReasoningCalls(t *testing.T) {
+	budget := &fixedBudget{}
+	r := NewModelRouter("http://reasoning", "70b", "http://simple", "8b")
+	r.SetBudget(budget)
+
+	if route := r.Route(CallSynthesis); route.Tier != ModelTierReasoning {
+		t.Fatalf("expected the reasoning model under budget, got %+v", route)
+	}
+	budget.soft = true
+	if route := r.Route(CallSynthesis); route.Tier != ModelTierSimple || route.Model != "8b" {
+		t.Errorf("expected synthesis downgraded over the soft limit, got %+v", route)
+	}
+	got := r.Stats().ByCallType["synthesis"]
+	if got.Reasoning != 1 || got.Simple != 1 || got.Downgraded != 1 {
+		t.Errorf("expected the downgrade counted, got %+v", got)
+	}
+
+	// Without a simple model there is nothing cheaper to route to
+	alone := NewModelRouter("http://reasoning", "70b", "", "")
+	alone.SetBudget(budget)
+	if route := alone.Route(CallSynthesis); route.Tier != ModelTierReasoning {
+		t.Errorf("expected the reasoning model without a simple model, got %+v", route)
+	}
+}
+
+func TestHardBudgetPausesCycles(t *testing.T) {
+	e := &Engine{events: NewEventBus()}
+	sub := e.events.Subscribe(8)
+	e.SetTokenBudget(&fixedBudget{hard: true})
+
+	// The state manager is never reached while the budget is spent
+	if err := e.RunDialogueCycle(context.Background()); err != nil {
+		t.Fatalf("expected a paused cycle to return nil, got %v", err)
+	}
+	if stats := e.SkippedCycles(); stats.Total != 1 || stats.Budget != 1 {
+		t.Errorf("expected the pause counted, got %+v", stats)
+	}
+	if e.cycleActive.Load() {
+		t.Error("expected the cycle lock released")
+	}
+
+	e.PublishBudgetAlert("hard", "daily", 210, 200)
+	var types []EventType
+	for len(types) < 2 {
+		types = append(types, (<-sub.Events()).Type)
+	}
+	if types[0] != EventCycleSkipped || types[1] != EventBudgetAlert {
+		t.Errorf("unexpected events %v", types)
+	}
+}
@@ -0,0 +1,356 @@
+// internal/dialogue/transcript.go
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// TranscriptConfig controls recording each cycle's model replies, so cmd/replay can
+// re-run the decisions taken from them against current code
+type TranscriptConfig struct {
+	Enabled    bool
+	KeepCycles int // Transcripts of cycles further back than this are deleted
+}
+
+// Validate rejects keeping no transcripts while recording them
+func (c TranscriptConfig) Validate() error {
+	if c.Enabled && c.KeepCycles <= 0 {
+		return fmt.Errorf("transcripts.keep_cycles must be positive, got %d", c.KeepCycles)
+	}
+	return nil
+}
+
+// TranscriptStep names the decision a recorded reply fed
+type TranscriptStep string
+
+const (
+	TranscriptStepReflection       TranscriptStep = "reflection"        // Learnings and proposed goals
+	TranscriptStepResearchPlan     TranscriptStep = "research_plan"     // A goal's research questions
+	TranscriptStepSearchEvaluation TranscriptStep = "search_evaluation" // The page chosen from search results
+	TranscriptStepParseEvaluation  TranscriptStep = "parse_evaluation"  // How well a parsed page answered
+)
+
+// transcriptSnapshotCompleted bounds the completed goals kept in a snapshot; duplicate
+// checks only look at the most recent ones
+const transcriptSnapshotCompleted = 10
+
+// TranscriptSnapshot is the state a cycle's decisions were made against
+type TranscriptSnapshot struct {
+	ActiveGoals    []Goal `json:"active_goals"`
+	CompletedGoals []Goal `json:"completed_goals"` // The most recent only
+}
+
+// TranscriptCall is one model reply a decision was taken from
+type TranscriptCall struct {
+	Seq      int                `json:"seq"`
+	Step     TranscriptStep     `json:"step"`
+	CallType LLMCallType        `json:"call_type"`
+	Prompt   string             `json:"prompt"`
+	Response string             `json:"response"`
+	Decision TranscriptDecision `json:"decision"` // What the code made of the reply when it was recorded
+}
+
+// TranscriptDecision is what one reply was turned into. Only the fields of its step are set.
+type TranscriptDecision struct {
+	ParseError    string   `json:"parse_error,omitempty"`
+	CreatedGoals  []string `json:"created_goals,omitempty"`
+	Learnings     []string `json:"learnings,omitempty"`
+	Questions     []string `json:"questions,omitempty"`
+	BestURL       string   `json:"best_url,omitempty"`
+	ShouldProceed bool     `json:"should_proceed,omitempty"`
+	Quality       string   `json:"quality,omitempty"`
+}
+
+// CycleTranscript is a recorded cycle: the state it started from and the replies its
+// decisions were taken from
+type CycleTranscript struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CycleID   int            `gorm:"not null;uniqueIndex" json:"cycle_id"`
+	Snapshot  datatypes.JSON `gorm:"type:jsonb;not null" json:"snapshot"` // TranscriptSnapshot
+	Calls     datatypes.JSON `gorm:"type:jsonb;not null" json:"calls"`    // []TranscriptCall
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (CycleTranscript) TableName() string {
+	return "growerai_cycle_transcripts"
+}
+
+// Decode unpacks the snapshot and calls
+func (t *CycleTranscript) Decode() (TranscriptSnapshot, []TranscriptCall, error) {
+	var snapshot TranscriptSnapshot
+	if err := json.Unmarshal(t.Snapshot, &snapshot); err != nil {
+		return snapshot, nil, fmt.Errorf("failed to decode snapshot of cycle %d: %w", t.CycleID, err)
+	}
+	var calls []TranscriptCall
+	if err := json.Unmarshal(t.Calls, &calls); err != nil {
+		return snapshot, nil, fmt.Errorf("failed to decode calls of cycle %d: %w", t.CycleID, err)
+	}
+	return snapshot, calls, nil
+}
+
+// LoadTranscript loads a cycle's transcript, or the latest one when cycleID is 0
+func LoadTranscript(ctx context.Context, db *gorm.DB, cycleID int) (*CycleTranscript, error) {
+	var transcript CycleTranscript
+	query := db.WithContext(ctx)
+	if cycleID > 0 {
+		query = query.Where("cycle_id = ?", cycleID)
+	} else {
+		query = query.Order("cycle_id DESC")
+	}
+	if err := query.First(&transcript).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("no transcript recorded for cycle %d", cycleID)
+		}
+		return nil, fmt.Errorf("failed to load transcript: %w", err)
+	}
+	return &transcript, nil
+}
+
+// SetTranscripts configures recording of cycle transcripts
+func (e *Engine) SetTranscripts(cfg TranscriptConfig) {
+	e.transcripts = cfg
+}
+
+// transcriptRecorder collects the running cycle's transcript
+type transcriptRecorder struct {
+	cycleID  int
+	snapshot []byte // Encoded at cycle start, before the cycle changes the goals
+	calls    []TranscriptCall
+}
+
+type transcriptStepKey struct{}
+
+// withTranscriptStep marks the model calls made with ctx as feeding step, so they are recorded
+func withTranscriptStep(ctx context.Context, step TranscriptStep) context.Context {
+	return context.WithValue(ctx, transcriptStepKey{}, step)
+}
+
+// beginTranscript starts recording the cycle, snapshotting the goals it starts with
+func (e *Engine) beginTranscript(cycleID int, state *InternalState) {
+	if !e.transcripts.Enabled || e.db == nil {
+		return
+	}
+	completed := state.CompletedGoals
+	if len(completed) > transcriptSnapshotCompleted {
+		completed = completed[len(completed)-transcriptSnapshotCompleted:]
+	}
+	snapshot, err := json.Marshal(TranscriptSnapshot{ActiveGoals: state.ActiveGoals, CompletedGoals: completed})
+	if err != nil {
+		log.Printf("[Transcript] WARNING: Failed to snapshot cycle #%d, not recording it: %v", cycleID, err)
+		return
+	}
+
+	e.transcriptMu.Lock()
+	defer e.transcriptMu.Unlock()
+	e.transcript = &transcriptRecorder{cycleID: cycleID, snapshot: snapshot}
+}
+
+// recordTranscriptCall adds a model reply to the cycle's transcript when the call was
+// marked with the step it feeds
+func (e *Engine) recordTranscriptCall(ctx context.Context, callType LLMCallType, prompt, response string) {
+	step, ok := ctx.Value(transcriptStepKey{}).(TranscriptStep)
+	if !ok {
+		return
+	}
+	e.transcriptMu.Lock()
+	defer e.transcriptMu.Unlock()
+	if e.transcript == nil {
+		return
+	}
+	e.transcript.calls = append(e.transcript.calls, TranscriptCall{
+		Seq:      len(e.transcript.calls) + 1,
+		Step:     step,
+		CallType: callType,
+		Prompt:   prompt,
+		Response: response,
+	})
+}
+
+// finishTranscript saves the cycle's transcript with what each reply was turned into,
+// and deletes transcripts older than the configured number of cycles
+func (e *Engine) finishTranscript(ctx context.Context) {
+	e.transcriptMu.Lock()
+	recorder := e.transcript
+	e.transcript = nil
+	e.transcriptMu.Unlock()
+	if recorder == nil {
+		return
+	}
+
+	var snapshot TranscriptSnapshot
+	if err := json.Unmarshal(recorder.snapshot, &snapshot); err != nil {
+		log.Printf("[Transcript] WARNING: Failed to read snapshot of cycle #%d: %v", recorder.cycleID, err)
+		return
+	}
+	interpreter := newReplayEngine(ReplayConfig{GoalDedup: e.goalDedup, GoalProposals: e.goalProposals})
+	for i := range recorder.calls {
+		recorder.calls[i].Decision = interpreter.interpretTranscriptCall(ctx, snapshot, recorder.calls[i])
+	}
+	calls, err := json.Marshal(recorder.calls)
+	if err != nil {
+		log.Printf("[Transcript] WARNING: Failed to encode cycle #%d: %v", recorder.cycleID, err)
+		return
+	}
+
+	transcript := CycleTranscript{CycleID: recorder.cycleID, Snapshot: datatypes.JSON(recorder.snapshot), Calls: datatypes.JSON(calls)}
+	if err := e.db.WithContext(ctx).Create(&transcript).Error; err != nil {
+		log.Printf("[Transcript] WARNING: Failed to save transcript of cycle #%d: %v", recorder.cycleID, err)
+		return
+	}
+	if err := e.db.WithContext(ctx).Where("cycle_id <= ?", recorder.cycleID-e.transcripts.KeepCycles).
+		Delete(&CycleTranscript{}).Error; err != nil {
+		log.Printf("[Transcript] WARNING: Failed to prune transcripts: %v", err)
+	}
+	log.Printf("[Transcript] Recorded %d model replies for cycle #%d", len(recorder.calls), recorder.cycleID)
+}
+
+// ReplayConfig is the configuration the replayed decisions are taken under
+type ReplayConfig struct {
+	GoalDedup     GoalDedupConfig
+	GoalProposals GoalProposalConfig
+}
+
+// ReplayResult compares what a recorded reply was turned into with what current code makes of it
+type ReplayResult struct {
+	Seq      int
+	Step     TranscriptStep
+	Recorded TranscriptDecision
+	Replayed TranscriptDecision
+	Changes  []string // Empty when the decision is unchanged
+}
+
+// newReplayEngine returns an engine that can only interpret replies: it has no model,
+// tools, embedder or database, so replaying calls nothing live. Duplicate goals are
+// scored on their text alone.
+func newReplayEngine(cfg ReplayConfig) *Engine {
+	return &Engine{goalDedup: cfg.GoalDedup, goalProposals: cfg.GoalProposals}
+}
+
+// ReplayTranscript re-runs the parsing and decisions of a recorded cycle against the
+// current code and reports how each decision differs from the recorded one
+func ReplayTranscript(ctx context.Context, transcript *CycleTranscript, cfg ReplayConfig) ([]ReplayResult, error) {
+	snapshot, calls, err := transcript.Decode()
+	if err != nil {
+		return nil, err
+	}
+	interpreter := newReplayEngine(cfg)
+	results := make([]ReplayResult, 0, len(calls))
+	for _, call := range calls {
+		replayed := interpreter.interpretTranscriptCall(ctx, snapshot, call)
+		results = append(results, ReplayResult{
+			Seq:      call.Seq,
+			Step:     call.Step,
+			Recorded: call.Decision,
+			Replayed: replayed,
+			Changes:  diffDecisions(call.Decision, replayed),
+		})
+	}
+	return results, nil
+}
+
+// interpretTranscriptCall turns a recorded reply into the decision the cycle takes from it
+func (e *Engine) interpretTranscriptCall(ctx context.Context, snapshot TranscriptSnapshot, call TranscriptCall) TranscriptDecision {
+	var decision TranscriptDecision
+	switch call.Step {
+	case TranscriptStepReflection:
+		reasoning, err := ParseReasoningSExpr(call.Response)
+		if err != nil {
+			decision.ParseError = err.Error()
+			return decision
+		}
+		for _, learning := range reasoning.Learnings.ToSlice() {
+			decision.Learnings = append(decision.Learnings, learning.What)
+		}
+		// A replay changes nothing, so its proposal counts are not recorded; the cycle's
+		// goal management phase counts the proposals it receives
+		state := &InternalState{ActiveGoals: snapshot.ActiveGoals, CompletedGoals: snapshot.CompletedGoals}
+		_, created := e.acceptGoalProposals(ctx, state, reasoning, &CycleMetrics{})
+		for _, goal := range created {
+			decision.CreatedGoals = append(decision.CreatedGoals, goal.Description)
+		}
+	case TranscriptStepResearchPlan:
+		plan, err := parseResearchPlanResponse(call.Response)
+		if err != nil {
+			decision.ParseError = err.Error()
+			return decision
+		}
+		for _, q := range plan.SubQuestions {
+			decision.Questions = append(decision.Questions, q.Question)
+		}
+	case TranscriptStepSearchEvaluation:
+		evaluation, err := e.parseSearchEvaluation(call.Response)
+		if err != nil {
+			decision.ParseError = err.Error()
+			return decision
+		}
+		decision.BestURL = evaluation.BestURL
+		decision.ShouldProceed = evaluation.ShouldProceed
+	case TranscriptStepParseEvaluation:
+		evaluation, err := e.parseParseEvaluation(call.Response)
+		if err != nil {
+			decision.ParseError = err.Error()
+			return decision
+		}
+		decision.Quality = evaluation.Quality
+	default:
+		decision.ParseError = fmt.Sprintf("unknown step %q", call.Step)
+	}
+	return decision
+}
+
+// diffDecisions describes how a replayed decision differs from the recorded one
+func diffDecisions(recorded, replayed TranscriptDecision) []string {
+	changes := []string{}
+	switch {
+	case recorded.ParseError == "" && replayed.ParseError != "":
+		changes = append(changes, "now fails to parse: "+replayed.ParseError)
+	case recorded.ParseError != "" && replayed.ParseError == "":
+		changes = append(changes, "now parses (failed with: "+recorded.ParseError+")")
+	}
+	changes = append(changes, diffLists("goal would now be created", "goal no longer created", recorded.CreatedGoals, replayed.CreatedGoals)...)
+	changes = append(changes, diffLists("learning now extracted", "learning no longer extracted", recorded.Learnings, replayed.Learnings)...)
+	changes = append(changes, diffLists("question added", "question removed", recorded.Questions, replayed.Questions)...)
+	if recorded.BestURL != replayed.BestURL {
+		changes = append(changes, fmt.Sprintf("best URL %q → %q", recorded.BestURL, replayed.BestURL))
+	}
+	if recorded.ShouldProceed != replayed.ShouldProceed {
+		changes = append(changes, fmt.Sprintf("should proceed %t → %t", recorded.ShouldProceed, replayed.ShouldProceed))
+	}
+	if recorded.Quality != replayed.Quality {
+		changes = append(changes, fmt.Sprintf("quality %q → %q", recorded.Quality, replayed.Quality))
+	}
+	return changes
+}
+
+// diffLists reports the items only in after as added and those only in before as removed
+func diffLists(added, removed string, before, after []string) []string {
+	in := func(list []string, item string) bool {
+		for _, candidate := range list {
+			if candidate == item {
+				return true
+			}
+		}
+		return false
+	}
+	changes := []string{}
+	for _, item := range after {
+		if !in(before, item) {
+			changes = append(changes, fmt.Sprintf("%s: %s", added, item))
+		}
+	}
+	for _, item := range before {
+		if !in(after, item) {
+			changes = append(changes, fmt.Sprintf("%s: %s", removed, item))
+		}
+	}
+	return changes
+}
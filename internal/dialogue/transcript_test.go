@@ -0,0 +1,134 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const transcriptReflection = `(reasoning (reflection "r")
+  (learnings (learning (what "Waggle dance papers answer faster than blogs") (confidence 0.8)))
+  (goals_to_create
+    (goal (description "Research how honeybees navigate") (priority 6))
+    (goal (description "Study how ant colonies divide their labour") (priority 5))))`
+
+func transcriptEngine(t *testing.T) *Engine {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&CycleTranscript{}); err != nil {
+		t.Fatal(err)
+	}
+	e := &Engine{db: db, goalDedup: DefaultGoalDedupConfig()}
+	e.SetTranscripts(TranscriptConfig{Enabled: true, KeepCycles: 2})
+	return e
+}
+
+func TestTranscriptRecordsTaggedCallsWithDecisions(t *testing.T) {
+	ctx := context.Background()
+	e := transcriptEngine(t)
+
+	state := &InternalState{ActiveGoals: []Goal{{ID: "goal_bees", Description: "Research how honeybees navigate", Status: GoalStatusActive}}}
+	e.beginTranscript(7, state)
+	// Goals the cycle adds later are not part of the snapshot
+	state.ActiveGoals = append(state.ActiveGoals, Goal{ID: "goal_ants", Description: "Study how ant colonies divide their labour"})
+
+	e.recordTranscriptCall(withTranscriptStep(ctx, TranscriptStepReflection), CallDeepReflection, "reflect", transcriptReflection)
+	e.recordTranscriptCall(ctx, CallEvaluation, "untagged", "(quality \"sufficient\")")
+	e.recordTranscriptCall(withTranscriptStep(ctx, TranscriptStepResearchPlan), CallPlanGeneration, "plan", "```lisp\n(root \"How do bees navigate?\") (q \"Do bees use the sun?\") (q \"What is the waggle dance?\")\n```")
+	e.recordTranscriptCall(withTranscriptStep(ctx, TranscriptStepParseEvaluation), CallEvaluation, "evaluate", `(quality "try_fallback") (reasoning "half of it")`)
+	e.finishTranscript(ctx)
+
+	transcript, err := LoadTranscript(ctx, e.db, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, calls, err := transcript.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transcript.CycleID != 7 || len(snapshot.ActiveGoals) != 1 {
+		t.Fatalf("expected cycle 7 with the one goal it started with, got %d with %d", transcript.CycleID, len(snapshot.ActiveGoals))
+	}
+	if len(calls) != 3 {
+		t.Fatalf("expected only the 3 tagged calls recorded, got %d", len(calls))
+	}
+	reflection := calls[0].Decision
+	if len(reflection.CreatedGoals) != 1 || reflection.CreatedGoals[0] != "Study how ant colonies divide their labour" {
+		t.Errorf("expected the duplicate of the active goal dropped, got %v", reflection.CreatedGoals)
+	}
+	if len(reflection.Learnings) != 1 {
+		t.Errorf("expected the learning recorded, got %v", reflection.Learnings)
+	}
+	if len(calls[1].Decision.Questions) != 2 || calls[2].Decision.Quality != "try_fallback" {
+		t.Errorf("expected the plan's questions and the parse quality, got %+v and %+v", calls[1].Decision, calls[2].Decision)
+	}
+
+	results, err := ReplayTranscript(ctx, transcript, ReplayConfig{GoalDedup: DefaultGoalDedupConfig()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if len(r.Changes) != 0 {
+			t.Errorf("expected an unchanged replay, #%d %s changed: %v", r.Seq, r.Step, r.Changes)
+		}
+	}
+}
+
+func TestReplayReportsChangedDecisions(t *testing.T) {
+	ctx := context.Background()
+	e := transcriptEngine(t)
+
+	e.beginTranscript(1, &InternalState{ActiveGoals: []Goal{{ID: "goal_bees", Description: "Research how honeybees navigate"}}})
+	e.recordTranscriptCall(withTranscriptStep(ctx, TranscriptStepReflection), CallDeepReflection, "reflect", transcriptReflection)
+	e.finishTranscript(ctx)
+	transcript, err := LoadTranscript(ctx, e.db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dedup threshold no pair reaches lets the duplicate through
+	results, err := ReplayTranscript(ctx, transcript, ReplayConfig{GoalDedup: GoalDedupConfig{StringWeight: 1, Threshold: 1.1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := strings.Join(results[0].Changes, "\n"); changes != "goal would now be created: Research how honeybees navigate" {
+		t.Errorf("expected the duplicate reported as now created, got:\n%s", changes)
+	}
+
+	// A longer minimum description rejects the other proposal
+	results, err = ReplayTranscript(ctx, transcript, ReplayConfig{
+		GoalDedup:     DefaultGoalDedupConfig(),
+		GoalProposals: GoalProposalConfig{MinDescriptionChars: 50},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := strings.Join(results[0].Changes, "\n"); changes != "goal no longer created: Study how ant colonies divide their labour" {
+		t.Errorf("expected the rejected proposal reported, got:\n%s", changes)
+	}
+}
+
+func TestTranscriptsArePruned(t *testing.T) {
+	ctx := context.Background()
+	e := transcriptEngine(t)
+	for cycle := 1; cycle <= 4; cycle++ {
+		e.beginTranscript(cycle, &InternalState{})
+		e.finishTranscript(ctx)
+	}
+	var kept []CycleTranscript
+	if err := e.db.Order("cycle_id").Find(&kept).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 || kept[0].CycleID != 3 {
+		t.Errorf("expected cycles 3 and 4 kept, got %v", kept)
+	}
+	if _, err := LoadTranscript(ctx, e.db, 1); err == nil {
+		t.Error("expected a pruned cycle not to load")
+	}
+}
@@ -1,6 +1,7 @@
 package dialogue
 
 import (
+    "math/rand"
     "time"
 )
 
@@ -11,13 +12,15 @@ type Goal struct {
     Source          string                  `json:"source"` // "user_failure", "knowledge_gap", "curiosity", "principle"
     Priority        int                     `json:"priority"` // 1-10
     Created         time.Time               `json:"created"`
+    Deadline        *time.Time              `json:"deadline,omitempty"` // Optional target completion time
     Progress        float64                 `json:"progress"` // 0.0 to 1.0
     Actions         []Action                `json:"actions"`
-    Status          string                  `json:"status"` // "active", "completed", "abandoned"
-    Outcome         string                  `json:"outcome,omitempty"` // "good", "bad", "neutral" (when completed)
+    Status          string                  `json:"status"` // "active", "awaiting_user", "completed", "abandoned"
+    Outcome         GoalOutcome             `json:"outcome,omitempty"` // Set by closeGoal when the goal ends
     ResearchPlan    *ResearchPlan           `json:"research_plan,omitempty"` // Multi-step investigation plan
     Metadata        map[string]interface{}  `json:"metadata,omitempty"` // Additional metadata for the goal
     FailureCount    int                     `json:"failure_count"` // Track consecutive failures before abandoning
+    LastActionFailed bool                   `json:"last_action_failed,omitempty"` // The latest action failed; the goal may still succeed
     Tier            string                  `json:"tier"` // "primary", "secondary", "tactical"
     SupportsGoals   []string                `json:"supports_goals,omitempty"` // IDs of primary goals this supports
     DependencyScore float64                 `json:"dependency_score"` // 0.0-1.0 confidence in dependency link
@@ -25,7 +28,11 @@ type Goal struct {
     LastPursued     time.Time               `json:"last_pursued"` // When this goal was last worked on
     LastAssessment  *PlanAssessment         `json:"last_assessment,omitempty"` // Result of last progress check
     ReplanCount     int                     `json:"replan_count"` // Number of times this goal has been replanned
+    LastReplanReason string                 `json:"last_replan_reason,omitempty"` // Why the plan was last replaced
+    SynthesisRetried bool                   `json:"synthesis_retried,omitempty"` // Research was replanned once after a weak synthesis
     SelfModGoal     *SelfModificationGoal   `json:"self_mod_goal,omitempty"` // Self-modification details if applicable
+    Notes           []GoalNote              `json:"notes,omitempty"` // Scratchpad kept across cycles, oldest first
+    PendingUserQuestion *UserQuestion       `json:"pending_user_question,omitempty"` // Set while awaiting_user
 }
 
 // SelfModificationGoal represents a deliberate attempt to modify thinking patterns
@@ -37,25 +44,52 @@ type SelfModificationGoal struct {
     TestActions       []Action `json:"test_actions"`         // Actions to validate the change
     BaselineComparison string  `json:"baseline_comparison"`  // What to compare against
     ValidationStatus  string   `json:"validation_status"`    // "pending", "testing", "validated", "failed"
+    Trial             *PrincipleTrialResult `json:"trial,omitempty"` // Empirical A/B comparison, if one ran
+}
+
+// PrincipleTrialBranch aggregates measured outcomes for one side of a principle trial
+type PrincipleTrialBranch struct {
+    Trials            int     `json:"trials"`              // Trials that produced a measurement
+    SearchConfidence  float64 `json:"search_confidence"`   // Mean search evaluation confidence
+    ParseQuality      float64 `json:"parse_quality"`       // Mean parse quality score (0.0-1.0)
+    ActionSuccessRate float64 `json:"action_success_rate"` // Fraction of test actions that succeeded
+    Score             float64 `json:"score"`               // Combined score used for the comparison
+}
+
+// PrincipleTrialResult records an empirical comparison of current vs proposed principles
+type PrincipleTrialResult struct {
+    Method    string               `json:"method"`    // "empirical" or "llm_only"
+    Baseline  PrincipleTrialBranch `json:"baseline"`  // Current principles
+    Candidate PrincipleTrialBranch `json:"candidate"` // Proposed principle injected
+    Margin    float64              `json:"margin"`    // Required lead for the candidate
+    Passed    bool                 `json:"passed"`
+    Reasoning string               `json:"reasoning"`
 }
 
 // PlanAssessment represents evaluation of progress after completing an action
 type PlanAssessment struct {
+    GoalID          string    `json:"goal_id,omitempty"` // Goal the assessment is of
     Timestamp       time.Time `json:"timestamp"`
     ProgressQuality string    `json:"progress_quality"` // "good", "partial", "poor"
     PlanValidity    string    `json:"plan_validity"`    // "valid", "needs_adjustment", "needs_replan"
     Reasoning       string    `json:"reasoning"`
     Recommendation  string    `json:"recommendation"`   // "continue", "adjust", "replan"
+    Adjustment      string    `json:"adjustment,omitempty"` // New query or URL for the next action, with "adjust"
+    CompletedActions int      `json:"completed_actions"` // Completed actions when assessed; a later one triggers the next assessment
 }
 
 // Action represents a step taken toward completing a goal
 type Action struct {
+    ID          string                 `json:"id,omitempty"` // Set at creation; older actions get one when they run
     Description string                 `json:"description"`
     Tool        string                 `json:"tool"` // "search", "web_parse", "sandbox", "memory_consolidation"
-    Status      string                 `json:"status"` // "pending", "in_progress", "completed"
-    Result      string                 `json:"result,omitempty"`
+    Status      string                 `json:"status"` // "pending", "in_progress", "completed", "blocked"
+    Result      string                 `json:"result,omitempty"` // Preview only when ResultRef is set
+    ResultRef   string                 `json:"result_ref,omitempty"` // Result store key of the full output
+    FailureKind string                 `json:"failure_kind,omitempty"` // tools.FailureKind* of a failed tool call
     Timestamp   time.Time              `json:"timestamp"`
     Metadata    map[string]interface{} `json:"metadata,omitempty"` // For passing extra params like purpose
+    DependsOn   []string               `json:"depends_on,omitempty"` // IDs of actions that must complete successfully first
 }
 
 // InternalState represents the system's working memory between dialogue cycles
@@ -65,18 +99,24 @@ type InternalState struct {
     KnowledgeGaps   []string `json:"knowledge_gaps"`
     RecentFailures  []string `json:"recent_failures"`
     Patterns        []string `json:"patterns"`
+    FocusAreas      []FocusArea `json:"focus_areas"` // From the last self-assessment, until they expire
     LastCycleTime   time.Time `json:"last_cycle_time"`
     CycleCount      int      `json:"cycle_count"`
+    owner           string   // Cycle lock token; SaveState refuses once another cycle claims the state
+    records         map[string]bool // Goal and action records the state was loaded from; SaveState writes only new ones
+    rng             *rand.Rand // Seeded per cycle; every random choice in the cycle draws from it
 }
 
 // ThoughtRecord logs an internal thought during a dialogue cycle
 type ThoughtRecord struct {
     CycleID     int       `json:"cycle_id"`
     ThoughtNum  int       `json:"thought_num"`
+    GoalID      string    `json:"goal_id,omitempty"` // Goal the thought concerned, if any
     Content     string    `json:"content"`
     TokensUsed  int       `json:"tokens_used"`
     Timestamp   time.Time `json:"timestamp"`
     ActionTaken bool      `json:"action_taken"` // Did this thought result in external action?
+    PromptTemplate string `json:"prompt_template,omitempty"` // name@hash of the template behind a structured call
 }
 
 // CycleMetrics tracks performance of a dialogue cycle
@@ -91,7 +131,46 @@ type CycleMetrics struct {
     GoalsCreated   int           `json:"goals_created"`
     GoalsCompleted int           `json:"goals_completed"`
     MemoriesStored int           `json:"memories_stored"`
-    StopReason     string        `json:"stop_reason"` // "max_thoughts", "max_time", "action_requirement", "natural_stop"
+    MemoriesMerged int           `json:"memories_merged"` // Learnings folded into an existing near-duplicate
+    SearchCacheHits   int        `json:"search_cache_hits"`
+    SearchCacheMisses int        `json:"search_cache_misses"`
+    PageCacheHits     int        `json:"page_cache_hits"` // Parses served from cache or by a 304
+    PageCacheMisses   int        `json:"page_cache_misses"`
+    SimpleModelCalls    int      `json:"simple_model_calls"` // LLM calls served by the simple model
+    ReasoningModelCalls int      `json:"reasoning_model_calls"`
+    EmptyCompletions    int      `json:"empty_completions"` // Completions that came back empty, including retries
+    ContextOverflows    int      `json:"context_overflows"` // Calls trimmed to fit the context window or rejected as too long
+    GoalValidationTokens int     `json:"goal_validation_tokens"` // Spent checking secondary goals support a primary
+    MemoryReuseHits     int      `json:"memory_reuse_hits"` // Parse actions answered from a research synthesis
+    SearchLedgerHits    int      `json:"search_ledger_hits"` // Searches answered by a recent identical search's chosen page
+    SearchLedgerMisses  int      `json:"search_ledger_misses"`
+    RepetitiveThoughts  int      `json:"repetitive_thoughts"` // Thoughts dropped as repeats of recent ones
+    PlanAdjustments     int      `json:"plan_adjustments"` // Next actions changed after a progress assessment
+    Replans             int      `json:"replans"` // Research plans replaced after a progress assessment
+    SynthesisReviews    int      `json:"synthesis_reviews"` // Research syntheses scored by the quality gate
+    SynthesesBelowGate  int      `json:"syntheses_below_gate"` // Of those, scored below the gate's minimum
+    GoalsMerged         int      `json:"goals_merged"` // Proposals folded into a near-duplicate sibling from the same response
+    GoalProposalsReceived           int `json:"goal_proposals_received"` // Goals the reflection proposed
+    GoalProposalsAccepted           int `json:"goal_proposals_accepted"`
+    GoalProposalsInvalid            int `json:"goal_proposals_invalid"` // Rejected for an unusable description
+    GoalProposalsDuplicateActive    int `json:"goal_proposals_duplicate_active"`
+    GoalProposalsDuplicateAbandoned int `json:"goal_proposals_duplicate_abandoned"`
+    GoalProposalsDowngraded         int `json:"goal_proposals_downgraded"` // Secondary goals made tactical for supporting no primary
+    GoalProposalsClamped            int `json:"goal_proposals_clamped"` // Accepted with a priority brought into range
+    GoalProposalsBackpressure       int `json:"goal_proposals_backpressure"` // Refused unchecked while the action backlog was full
+    PendingActionBacklog            int `json:"pending_action_backlog"` // Actions left across active goals
+    ReflectionNearDuplicates        int `json:"reflection_near_duplicates"` // Memories left out of the reflection context as near-copies
+    ReasoningDepth      string   `json:"reasoning_depth"` // Depth the reflection ran at, as chosen when the setting is "auto"
+    RandomSeed          int64    `json:"random_seed"` // Seed of the cycle's random choices, so it can be replayed
+    SearchThreshold     float64  `json:"search_threshold"` // Adaptive thresholds this cycle ran with
+    CollectiveThreshold float64  `json:"collective_threshold"`
+    GoalSimilarityThreshold float64 `json:"goal_similarity_threshold"`
+    PromptTemplates     map[string]int `json:"prompt_templates"` // Structured calls by template name@hash
+    StopReason     string        `json:"stop_reason"` // One of the StopReason constants
+    SkippedReason  string        `json:"skipped_reason,omitempty"` // Why LLM-heavy phases were skipped, e.g. SkippedReasonLLMOverloaded
+    ThoughtLimit   int           `json:"thought_limit"` // Budgets in force, so the binding one can be read off
+    TokenLimit     int           `json:"token_limit"`
+    DurationLimit  time.Duration `json:"duration_limit"`
 }
 
 // ActionPlanStep represents a step in a dynamic action plan
@@ -122,7 +201,10 @@ type ResearchQuestion struct {
     Priority        int      `json:"priority"`           // 1-10 importance
     Dependencies    []string `json:"dependencies"`       // Question IDs that must complete first
     SourcesFound    []string `json:"sources_found"`      // URLs discovered
+    SourceTitles    map[string]string `json:"source_titles,omitempty"` // Page title by URL, where known
     KeyFindings     string   `json:"key_findings"`       // Summary of findings
+    Category        string   `json:"category,omitempty"` // SearXNG category to search, e.g. "news"; empty searches all
+    Recency         string   `json:"recency,omitempty"`  // "day", "week", "month" or "year"; empty for any age
     ConfidenceLevel float64  `json:"confidence_level"`   // 0.0-1.0 confidence in answer
 }
 
@@ -134,6 +216,7 @@ const (
     GoalSourcePrinciple        = "principle"
     GoalSourceUserInterest     = "user_interest"
     GoalSourceSelfModification = "self_modification"
+    GoalSourceUserRequest      = "user_request" // Quick answer asked for directly (ResearchNow)
 )
 
 // GoalStatus constants
@@ -141,6 +224,7 @@ const (
     GoalStatusActive    = "active"
     GoalStatusCompleted = "completed"
     GoalStatusAbandoned = "abandoned"
+    GoalStatusAwaitingUser = "awaiting_user" // Paused on a question only the user can answer
 )
 
 // ActionStatus constants
@@ -148,6 +232,7 @@ const (
     ActionStatusPending    = "pending"
     ActionStatusInProgress = "in_progress"
     ActionStatusCompleted  = "completed"
+    ActionStatusBlocked    = "blocked" // A dependency failed; the action will not run
 )
 
 // ActionTool constants
@@ -161,8 +246,9 @@ const (
 
 // StopReason constants
 const (
-    StopReasonMaxThoughts       = "max_thoughts"
-    StopReasonMaxTime           = "max_time"
+    StopReasonMaxThoughts       = "max_thoughts" // Thought count reached maxThoughtsPerCycle
+    StopReasonMaxTokens         = "max_tokens"   // Tokens used reached maxTokensPerCycle
+    StopReasonTimeout           = "timeout"      // Cycle ran past maxDurationMinutes
     StopReasonActionRequirement = "action_requirement"
     StopReasonNaturalStop       = "natural_stop"
 )
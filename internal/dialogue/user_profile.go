@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 	
@@ -19,6 +20,7 @@ type UserProfile struct {
 	InteractionRate    float64           // Average messages per session
 	TechnicalLevel     float64           // 0.0-1.0, how technical user is
 	TopicPreferences   map[string]float64 // Topic -> interest score
+	SourceUserIDs      []string          // Users whose personal memories built this profile
 	LastUpdated        time.Time
 }
 
@@ -30,11 +32,14 @@ func (e *Engine) BuildUserProfile(ctx context.Context) (*UserProfile, error) {
 		return nil, err
 	}
 	
+	// Only read personal memories from users who opted in
+	allowedUsers, _ := e.personalMemoryConsent()
 	query := memory.RetrievalQuery{
 		Limit:             50, // Analyze more memories for better profile
 		MinScore:          0.2, // Lower threshold for broad coverage
 		IncludePersonal:   true,
 		IncludeCollective: false, // Only user interactions
+		AllowedUserIDs:    allowedUsers,
 	}
 	
 	results, err := e.storage.Search(ctx, query, embedding)
@@ -87,14 +92,13 @@ func (e *Engine) BuildUserProfile(ctx context.Context) (*UserProfile, error) {
 		topics = append(topics, topicScore{topic, score})
 	}
 	
-	// Sort by score
-	for i := 0; i < len(topics); i++ {
-		for j := i + 1; j < len(topics); j++ {
-			if topics[j].score > topics[i].score {
-				topics[i], topics[j] = topics[j], topics[i]
-			}
+	// Sort by score; ties by name, since map order is random
+	sort.SliceStable(topics, func(i, j int) bool {
+		if topics[i].score != topics[j].score {
+			return topics[i].score > topics[j].score
 		}
-	}
+		return topics[i].topic < topics[j].topic
+	})
 	
 	// Get top 10 topics
 	topTopics := []string{}
@@ -142,13 +146,12 @@ func (e *Engine) BuildUserProfile(ctx context.Context) (*UserProfile, error) {
 		hours = append(hours, hourCount{hour, count})
 	}
 	
-	for i := 0; i < len(hours); i++ {
-		for j := i + 1; j < len(hours); j++ {
-			if hours[j].count > hours[i].count {
-				hours[i], hours[j] = hours[j], hours[i]
-			}
+	sort.SliceStable(hours, func(i, j int) bool {
+		if hours[i].count != hours[j].count {
+			return hours[i].count > hours[j].count
 		}
-	}
+		return hours[i].hour < hours[j].hour
+	})
 	
 	topActiveHours := []int{}
 	for i := 0; i < len(hours) && i < 3; i++ {
@@ -190,6 +193,7 @@ func (e *Engine) BuildUserProfile(ctx context.Context) (*UserProfile, error) {
 		InteractionRate:  float64(len(results)) / 10.0, // Rough estimate
 		TechnicalLevel:   technicalLevel,
 		TopicPreferences: topicPreferences,
+		SourceUserIDs:    memory.PersonalSourceUserIDs(results),
 		LastUpdated:      time.Now(),
 	}
 	
@@ -200,14 +204,20 @@ func (e *Engine) BuildUserProfile(ctx context.Context) (*UserProfile, error) {
 }
 
 // GenerateUserAlignedGoal creates a goal based on user profile
-func (e *Engine) GenerateUserAlignedGoal(ctx context.Context, profile *UserProfile, avoidRecent []string) (Goal, error) {
+func (e *Engine) GenerateUserAlignedGoal(ctx context.Context, rng *rand.Rand, profile *UserProfile, avoidRecent []string) (Goal, error) {
 	if len(profile.TopTopics) == 0 {
 		return Goal{}, fmt.Errorf("no user topics available")
 	}
 	
+	// Topics users asked to stop researching are never picked
+	topTopics := withoutSuppressed(profile.TopTopics, e.suppressedTopics(ctx))
+	if len(topTopics) == 0 {
+		return Goal{}, fmt.Errorf("all user topics are suppressed")
+	}
+
 	// Filter out recently explored topics
 	availableTopics := []string{}
-	for _, topic := range profile.TopTopics {
+	for _, topic := range topTopics {
 		isRecent := false
 		for _, recent := range avoidRecent {
 			if strings.Contains(strings.ToLower(recent), topic) {
@@ -222,7 +232,7 @@ func (e *Engine) GenerateUserAlignedGoal(ctx context.Context, profile *UserProfi
 	
 	if len(availableTopics) == 0 {
 		// If all topics exhausted, use any topic
-		availableTopics = profile.TopTopics
+		availableTopics = topTopics
 	}
 	
 	// Pick highest-interest available topic
@@ -238,7 +248,7 @@ func (e *Engine) GenerateUserAlignedGoal(ctx context.Context, profile *UserProfi
 			fmt.Sprintf("Analyze performance optimization in %s", selectedTopic),
 			fmt.Sprintf("Explore architectural approaches to %s", selectedTopic),
 		}
-		description = variations[rand.Intn(len(variations))]
+		description = variations[rng.Intn(len(variations))]
 	} else if profile.TechnicalLevel < 0.3 {
 		// Non-technical user - use accessible framing
 		variations := []string{
@@ -247,7 +257,7 @@ func (e *Engine) GenerateUserAlignedGoal(ctx context.Context, profile *UserProfi
 			fmt.Sprintf("Explore real-world examples of %s", selectedTopic),
 			fmt.Sprintf("Discover how %s works in simple terms", selectedTopic),
 		}
-		description = variations[rand.Intn(len(variations))]
+		description = variations[rng.Intn(len(variations))]
 	} else {
 		// Balanced user
 		variations := []string{
@@ -256,7 +266,7 @@ func (e *Engine) GenerateUserAlignedGoal(ctx context.Context, profile *UserProfi
 			fmt.Sprintf("Investigate current trends in %s", selectedTopic),
 			fmt.Sprintf("Analyze key concepts in %s", selectedTopic),
 		}
-		description = variations[rand.Intn(len(variations))]
+		description = variations[rng.Intn(len(variations))]
 	}
 	
 	goal := Goal{
@@ -269,6 +279,7 @@ func (e *Engine) GenerateUserAlignedGoal(ctx context.Context, profile *UserProfi
 		Status:      GoalStatusActive,
 		Actions:     []Action{},
 	}
+	tagGoalSources(&goal, profile.SourceUserIDs)
 	
 	log.Printf("[UserProfile] Generated user-aligned goal: %s (technical_level=%.2f)",
 		truncate(description, 60), profile.TechnicalLevel)
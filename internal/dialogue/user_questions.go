@@ -0,0 +1,216 @@
+// internal/dialogue/user_questions.go
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-llama/internal/utils"
+)
+
+// DefaultUserQuestionTimeout is how long a goal waits for the user's answer before it
+// resumes on its own
+const DefaultUserQuestionTimeout = 24 * time.Hour
+
+// maxUserQuestionChars caps a question; longer ones are cut
+const maxUserQuestionChars = 300
+
+// Errors AnswerUserQuestion returns for an answer it cannot take
+var (
+	ErrGoalNotFound      = errors.New("goal not found")
+	ErrNoPendingQuestion = errors.New("goal has no question awaiting an answer")
+	ErrEmptyAnswer       = errors.New("answer is empty")
+)
+
+// UserQuestion is a question only the user can settle, raised while working on a goal.
+// The goal waits for the answer until the question expires.
+type UserQuestion struct {
+	Question string    `json:"question"`
+	Cycle    int       `json:"cycle"`
+	Asked    time.Time `json:"asked"`
+}
+
+// userQuestionInstruction lets a prompt ask the user instead of guessing
+const userQuestionInstruction = `If the goal hinges on a preference only the user can settle (e.g. which language or platform to focus on), ask instead of guessing; the goal waits for the answer:
+(user_question "...")`
+
+// GoalAnswer is the user's answer to a goal's question, kept until a cycle has applied
+// it to the goal and saved the state
+type GoalAnswer struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	GoalID    string     `gorm:"type:varchar(100);index;not null" json:"goal_id"`
+	Answer    string     `gorm:"type:text;not null" json:"answer"`
+	CreatedAt time.Time  `json:"created_at"`
+	AppliedAt *time.Time `gorm:"index" json:"applied_at,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (GoalAnswer) TableName() string {
+	return "growerai_goal_answers"
+}
+
+// SetUserQuestionTimeout sets how long a goal waits for an answer before resuming
+func (e *Engine) SetUserQuestionTimeout(timeout time.Duration) {
+	e.userQuestionTimeout = timeout
+}
+
+func (e *Engine) userQuestionTimeoutOrDefault() time.Duration {
+	if e.userQuestionTimeout <= 0 {
+		return DefaultUserQuestionTimeout
+	}
+	return e.userQuestionTimeout
+}
+
+// parseUserQuestion extracts the question of a (user_question "...") block in raw
+func parseUserQuestion(raw string) string {
+	question := strings.TrimSpace(extractFieldContent(raw, "user_question"))
+	if len(question) > maxUserQuestionChars {
+		question = strings.TrimSpace(utils.Prefix(question, maxUserQuestionChars))
+	}
+	return question
+}
+
+// askUser pauses the goal on the question in a model response, if there is one and the
+// goal is not already waiting. The question reaches the user through the event stream
+// and the chat status summary. It reports whether the goal now waits for an answer.
+func (e *Engine) askUser(ctx context.Context, goal *Goal, raw string) bool {
+	if goal == nil || goal.Status != GoalStatusActive {
+		return false
+	}
+	question := parseUserQuestion(raw)
+	if question == "" {
+		return false
+	}
+	goal.PendingUserQuestion = &UserQuestion{Question: question, Cycle: int(e.currentCycle.Load()), Asked: time.Now()}
+	goal.Status = GoalStatusAwaitingUser
+	log.Printf("[Dialogue] Goal %s waits for the user: %s", goal.ID, truncate(question, 100))
+	e.publishEvent(EventUserQuestion, goal.ID, "", map[string]interface{}{
+		"question":      question,
+		"description":   goal.Description,
+		"expires_at":    goal.PendingUserQuestion.Asked.Add(e.userQuestionTimeoutOrDefault()),
+		"awaiting_user": true,
+	})
+	e.recordGoalEvent(ctx, goal.ID, TimelineStatusChanged, question,
+		map[string]interface{}{"status": GoalStatusAwaitingUser})
+	return true
+}
+
+// resumeGoal returns a goal waiting for the user to active, noting how its question
+// was settled so later prompts build on it
+func (e *Engine) resumeGoal(ctx context.Context, goal *Goal, note string) {
+	goal.PendingUserQuestion = nil
+	goal.Status = GoalStatusActive
+	addGoalNotes(goal, []string{note}, int(e.currentCycle.Load()))
+	e.recordGoalEvent(ctx, goal.ID, TimelineStatusChanged, note, map[string]interface{}{"status": GoalStatusActive})
+}
+
+// AnswerUserQuestion records the user's answer to the question goalID waits on. The
+// next cycle adds it to the goal's notes and resumes the goal.
+func (e *Engine) AnswerUserQuestion(ctx context.Context, goalID, answer string) error {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return ErrEmptyAnswer
+	}
+	if e.stateManager == nil {
+		return fmt.Errorf("dialogue state not available")
+	}
+	state, err := e.stateManager.LoadState(ctx)
+	if err != nil {
+		return err
+	}
+	goal := findGoal(state.ActiveGoals, goalID)
+	if goal == nil {
+		return ErrGoalNotFound
+	}
+	if goal.Status != GoalStatusAwaitingUser || goal.PendingUserQuestion == nil {
+		return ErrNoPendingQuestion
+	}
+	if err := e.stateManager.SaveGoalAnswer(ctx, goalID, answer); err != nil {
+		return err
+	}
+	log.Printf("[Dialogue] User answered goal %s; it resumes next cycle", goalID)
+	return nil
+}
+
+// applyUserAnswers resumes the goals answered since the last cycle, with the answer as
+// a note. It returns the answers applied, to be marked once the state is saved.
+func (e *Engine) applyUserAnswers(ctx context.Context, state *InternalState) []uint {
+	answers, err := e.stateManager.PendingGoalAnswers(ctx)
+	if err != nil {
+		log.Printf("[Dialogue] WARNING: %v", err)
+		return nil
+	}
+	applied := make([]uint, 0, len(answers))
+	for _, answer := range answers {
+		applied = append(applied, answer.ID)
+		goal := findGoal(state.ActiveGoals, answer.GoalID)
+		if goal == nil || goal.Status != GoalStatusAwaitingUser || goal.PendingUserQuestion == nil {
+			log.Printf("[Dialogue] Dropping answer for goal %s: no longer waiting for one", answer.GoalID)
+			continue
+		}
+		question := goal.PendingUserQuestion.Question
+		e.resumeGoal(ctx, goal, fmt.Sprintf("User answered %q: %s", truncate(question, 80), answer.Answer))
+		log.Printf("[Dialogue] Goal %s resumed with the user's answer", goal.ID)
+	}
+	return applied
+}
+
+// expireUserQuestions resumes goals whose question went unanswered past the timeout
+func (e *Engine) expireUserQuestions(ctx context.Context, state *InternalState, now time.Time) {
+	timeout := e.userQuestionTimeoutOrDefault()
+	for i := range state.ActiveGoals {
+		goal := &state.ActiveGoals[i]
+		if goal.Status != GoalStatusAwaitingUser || goal.PendingUserQuestion == nil {
+			continue
+		}
+		if now.Sub(goal.PendingUserQuestion.Asked) < timeout {
+			continue
+		}
+		question := goal.PendingUserQuestion.Question
+		e.resumeGoal(ctx, goal, fmt.Sprintf("No answer from the user to %q after %s; proceed with best judgment",
+			truncate(question, 80), timeout.Round(time.Minute)))
+		log.Printf("[Dialogue] Question on goal %s expired unanswered, resuming", goal.ID)
+	}
+}
+
+// findGoal returns the goal with id, or nil
+func findGoal(goals []Goal, id string) *Goal {
+	for i := range goals {
+		if goals[i].ID == id {
+			return &goals[i]
+		}
+	}
+	return nil
+}
+
+// SaveGoalAnswer stores a user's answer for the next cycle to apply
+func (sm *StateManager) SaveGoalAnswer(ctx context.Context, goalID, answer string) error {
+	if err := sm.db.WithContext(ctx).Create(&GoalAnswer{GoalID: goalID, Answer: answer}).Error; err != nil {
+		return fmt.Errorf("failed to save goal answer: %w", err)
+	}
+	return nil
+}
+
+// PendingGoalAnswers returns the answers no cycle has applied yet, oldest first
+func (sm *StateManager) PendingGoalAnswers(ctx context.Context) ([]GoalAnswer, error) {
+	var answers []GoalAnswer
+	if err := sm.db.WithContext(ctx).Where("applied_at IS NULL").Order("id").Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("failed to load goal answers: %w", err)
+	}
+	return answers, nil
+}
+
+// MarkGoalAnswersApplied records that the answers are in a saved state
+func (sm *StateManager) MarkGoalAnswersApplied(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := sm.db.WithContext(ctx).Model(&GoalAnswer{}).Where("id IN ?", ids).Update("applied_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark goal answers applied: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseUserQuestion(t *testing.T) {
+	raw := `(assessment (progress_quality "fair") (recommendation "continue"))
+(user_question "Should I focus on Python or JavaScript?")`
+	if q := parseUserQuestion(raw); q != "Should I focus on Python or JavaScript?" {
+		t.Errorf("unexpected question %q", q)
+	}
+	if q := parseUserQuestion(`(assessment (reasoning "no question"))`); q != "" {
+		t.Errorf("expected no question, got %q", q)
+	}
+	if q := parseUserQuestion(`(user_question "` + strings.Repeat("a", maxUserQuestionChars+40) + `")`); len(q) != maxUserQuestionChars {
+		t.Errorf("expected a long question cut to %d chars, got %d", maxUserQuestionChars, len(q))
+	}
+}
+
+func TestAskUserPausesGoalUntilExpiry(t *testing.T) {
+	e := &Engine{events: NewEventBus()}
+	sub := e.events.Subscribe(4)
+	e.SetUserQuestionTimeout(time.Hour)
+	state := &InternalState{ActiveGoals: []Goal{{ID: "goal_1", Description: "Learn a language", Status: GoalStatusActive}}}
+	goal := &state.ActiveGoals[0]
+
+	if !e.askUser(context.Background(), goal, `(user_question "Python or JavaScript?")`) {
+		t.Fatal("expected the goal to wait for the user")
+	}
+	if goal.Status != GoalStatusAwaitingUser || goal.PendingUserQuestion.Question != "Python or JavaScript?" {
+		t.Fatalf("expected the goal paused on the question, got %+v", goal)
+	}
+	if event := <-sub.Events(); event.Type != EventUserQuestion || event.GoalID != "goal_1" || event.Data["awaiting_user"] != true {
+		t.Errorf("expected a user question event, got %+v", event)
+	}
+	if e.askUser(context.Background(), goal, `(user_question "Another one?")`) {
+		t.Error("expected a waiting goal not to ask again")
+	}
+
+	e.expireUserQuestions(context.Background(), state, goal.PendingUserQuestion.Asked.Add(30*time.Minute))
+	if goal.Status != GoalStatusAwaitingUser {
+		t.Fatal("expected the goal to keep waiting before the timeout")
+	}
+	e.expireUserQuestions(context.Background(), state, goal.PendingUserQuestion.Asked.Add(2*time.Hour))
+	if goal.Status != GoalStatusActive || goal.PendingUserQuestion != nil {
+		t.Fatalf("expected the goal resumed after the timeout, got %+v", goal)
+	}
+	if len(goal.Notes) != 1 || !strings.Contains(goal.Notes[0].Text, "proceed with best judgment") {
+		t.Errorf("expected a best-judgment note, got %+v", goal.Notes)
+	}
+}
+
+func TestAnswerResumesGoalNextCycle(t *testing.T) {
+	sm, db := setupVersionedState(t)
+	if err := db.AutoMigrate(&GoalAnswer{}); err != nil {
+		t.Fatal(err)
+	}
+	e := &Engine{stateManager: sm}
+	ctx := context.Background()
+
+	state, err := sm.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.ActiveGoals = []Goal{
+		{ID: "goal_1", Description: "Learn a language", Status: GoalStatusAwaitingUser,
+			PendingUserQuestion: &UserQuestion{Question: "Python or JavaScript?", Asked: time.Now()}},
+		{ID: "goal_2", Description: "Survey bees", Status: GoalStatusActive},
+	}
+	if err := sm.SaveState(ctx, state); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.AnswerUserQuestion(ctx, "goal_2", "Python"); !errors.Is(err, ErrNoPendingQuestion) {
+		t.Errorf("expected ErrNoPendingQuestion, got %v", err)
+	}
+	if err := e.AnswerUserQuestion(ctx, "goal_9", "Python"); !errors.Is(err, ErrGoalNotFound) {
+		t.Errorf("expected ErrGoalNotFound, got %v", err)
+	}
+	if err := e.AnswerUserQuestion(ctx, "goal_1", "  "); !errors.Is(err, ErrEmptyAnswer) {
+		t.Errorf("expected ErrEmptyAnswer, got %v", err)
+	}
+	if err := e.AnswerUserQuestion(ctx, "goal_1", "Python"); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := e.applyUserAnswers(ctx, state)
+	goal := &state.ActiveGoals[0]
+	if len(applied) != 1 || goal.Status != GoalStatusActive || goal.PendingUserQuestion != nil {
+		t.Fatalf("expected the goal resumed by the answer, got %+v (applied %v)", goal, applied)
+	}
+	if len(goal.Notes) != 1 || !strings.Contains(goal.Notes[0].Text, `User answered "Python or JavaScript?": Python`) {
+		t.Errorf("expected the answer as a note, got %+v", goal.Notes)
+	}
+
+	if err := sm.MarkGoalAnswersApplied(ctx, applied); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := sm.PendingGoalAnswers(ctx); err != nil || len(pending) != 0 {
+		t.Errorf("expected no pending answers once applied, got %d (%v)", len(pending), err)
+	}
+}
+
+func TestResearchAssessmentSkipsWaitingGoals(t *testing.T) {
+	e, caller := planEngine(t)
+	goal := searchFixtureGoal()
+	goal.Status = GoalStatusAwaitingUser
+	goal.PendingUserQuestion = &UserQuestion{Question: "Honey bees or bumblebees?", Asked: time.Now()}
+	state := &InternalState{ActiveGoals: []Goal{*goal}}
+
+	if assessed := e.assessResearchGoals(context.Background(), state, &CycleMetrics{}); assessed != 0 || len(caller.prompts) != 0 {
+		t.Errorf("expected no assessment of a waiting goal, got %d calls", len(caller.prompts))
+	}
+}
+
+func TestStatusSummaryListsQuestionsFirst(t *testing.T) {
+	state := &InternalState{ActiveGoals: []Goal{
+		{ID: "goal_1", Description: "Learn Go generics", Status: GoalStatusActive, LastPursued: time.Now()},
+		{ID: "goal_2", Description: "Learn a language", Status: GoalStatusAwaitingUser,
+			PendingUserQuestion: &UserQuestion{Question: "Python or JavaScript?"}},
+	}}
+
+	summary := buildStatusSummary(state, nil, "", 500)
+	want := "- Python or JavaScript? (goal goal_2: Learn a language)"
+	if !strings.Contains(summary, want) {
+		t.Fatalf("expected %q in summary:\n%s", want, summary)
+	}
+	if strings.Index(summary, want) > strings.Index(summary, "Working on:") {
+		t.Errorf("expected questions before the goal list:\n%s", summary)
+	}
+}
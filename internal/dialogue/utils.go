@@ -2,8 +2,11 @@ package dialogue
 
 import (
     "math/rand"
+    "sort"
     "strings"
     "time"
+
+    "go-llama/internal/utils"
 )
 
 // extractGoalTopic extracts the topic from a goal content string based on a trigger phrase
@@ -85,29 +88,32 @@ func min(a, b int) int {
     return b
 }
 
-// sortGoalsByPriority sorts a slice of goals by Priority in descending order
+// goalOrderLess orders goals by Priority descending, then oldest first, then by ID, so
+// goals of equal priority keep the same order from cycle to cycle
+func goalOrderLess(a, b *Goal) bool {
+    if a.Priority != b.Priority {
+        return a.Priority > b.Priority
+    }
+    if !a.Created.Equal(b.Created) {
+        return a.Created.Before(b.Created)
+    }
+    return a.ID < b.ID
+}
+
+// sortGoalsByPriority returns a copy of goals in goalOrderLess order
 func sortGoalsByPriority(goals []Goal) []Goal {
-    // Simple bubble sort by priority (descending)
     sorted := make([]Goal, len(goals))
     copy(sorted, goals)
-    
-    for i := 0; i < len(sorted); i++ {
-        for j := i + 1; j < len(sorted); j++ {
-            if sorted[j].Priority > sorted[i].Priority {
-                sorted[i], sorted[j] = sorted[j], sorted[i]
-            }
-        }
-    }
-    
+    sort.SliceStable(sorted, func(i, j int) bool {
+        return goalOrderLess(&sorted[i], &sorted[j])
+    })
     return sorted
 }
 
-// truncate truncates a string to maxLen and appends "..."
+// truncate truncates a string to maxLen bytes, without splitting a character, and
+// appends "..."
 func truncate(s string, maxLen int) string {
-    if len(s) <= maxLen {
-        return s
-    }
-    return s[:maxLen] + "..."
+    return utils.Truncate(s, maxLen)
 }
 
 // hasPendingActions checks if a goal has any pending actions
@@ -121,20 +127,17 @@ func hasPendingActions(goal *Goal) bool {
 }
 
 // generateJitter creates a random time duration within a +/- window of minutes
-func generateJitter(windowMinutes int) time.Duration {
+func generateJitter(rng *rand.Rand, windowMinutes int) time.Duration {
     if windowMinutes <= 0 {
         return 0
     }
     
     // Random value between -windowMinutes and +windowMinutes
-    jitterMinutes := rand.Intn(windowMinutes*2+1) - windowMinutes
+    jitterMinutes := rng.Intn(windowMinutes*2+1) - windowMinutes
     return time.Duration(jitterMinutes) * time.Minute
 }
 
 // truncateResponse truncates a string for logging
 func truncateResponse(s string, maxLen int) string {
-    if len(s) <= maxLen {
-        return s
-    }
-    return s[:maxLen] + "... (truncated)"
+    return utils.TruncateWith(s, maxLen, "... (truncated)")
 }
@@ -0,0 +1,34 @@
+package dialogue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortGoalsByPriorityBreaksTiesDeterministically(t *testing.T) {
+	now := time.Now()
+	goals := []Goal{
+		{ID: "b", Priority: 5, Created: now},
+		{ID: "low", Priority: 2, Created: now.Add(-time.Hour)},
+		{ID: "a", Priority: 5, Created: now},
+		{ID: "old", Priority: 5, Created: now.Add(-time.Hour)},
+		{ID: "high", Priority: 9, Created: now},
+	}
+	want := []string{"high", "old", "a", "b", "low"}
+
+	reversed := make([]Goal, len(goals))
+	for i := range goals {
+		reversed[len(goals)-1-i] = goals[i]
+	}
+	for _, input := range [][]Goal{goals, reversed} {
+		sorted := sortGoalsByPriority(input)
+		for i, id := range want {
+			if sorted[i].ID != id {
+				t.Fatalf("expected order %v whatever the input order, got %s at %d", want, sorted[i].ID, i)
+			}
+		}
+	}
+	if goals[0].ID != "b" {
+		t.Errorf("expected the input left unsorted, got %s first", goals[0].ID)
+	}
+}
@@ -5,14 +5,17 @@ import (
 	"context"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 )
 
 // Worker manages the background dialogue scheduling
 type Worker struct {
 	engine              *Engine
+	mu                  sync.Mutex // Guards the interval, which config reloads can change
 	baseIntervalMinutes int
 	jitterWindowMinutes int
+	rng                 *rand.Rand // Jitter source; only the schedule loop uses it
 	stopChan            chan struct{}
 }
 
@@ -26,6 +29,7 @@ func NewWorker(
 		engine:              engine,
 		baseIntervalMinutes: baseIntervalMinutes,
 		jitterWindowMinutes: jitterWindowMinutes,
+		rng:                 newRand(time.Now().UnixNano()),
 		stopChan:            make(chan struct{}),
 	}
 }
@@ -35,9 +39,6 @@ func (w *Worker) Start() {
 	log.Printf("[DialogueWorker] Starting dialogue worker (base interval: %d minutes, jitter: ±%d minutes)",
 		w.baseIntervalMinutes, w.jitterWindowMinutes)
 	
-	// Seed random number generator for jitter
-	rand.Seed(time.Now().UnixNano())
-	
 	// Run first cycle immediately
 	w.runCycleSafely()
 	
@@ -55,8 +56,9 @@ func (w *Worker) Stop() {
 func (w *Worker) scheduleLoop() {
 	for {
 		// Calculate next run time with jitter
-		baseInterval := time.Duration(w.baseIntervalMinutes) * time.Minute
-		jitter := generateJitter(w.jitterWindowMinutes)
+		baseMinutes, jitterMinutes := w.interval()
+		baseInterval := time.Duration(baseMinutes) * time.Minute
+		jitter := generateJitter(w.rng, jitterMinutes)
 		nextInterval := baseInterval + jitter
 		
 		log.Printf("[DialogueWorker] Next cycle in %s (base: %s, jitter: %s)",
@@ -75,6 +77,25 @@ func (w *Worker) scheduleLoop() {
 	}
 }
 
+// SetInterval changes the schedule; the wait already in progress keeps its old length
+func (w *Worker) SetInterval(baseIntervalMinutes, jitterWindowMinutes int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.baseIntervalMinutes == baseIntervalMinutes && w.jitterWindowMinutes == jitterWindowMinutes {
+		return
+	}
+	w.baseIntervalMinutes = baseIntervalMinutes
+	w.jitterWindowMinutes = jitterWindowMinutes
+	log.Printf("[DialogueWorker] Interval changed (base interval: %d minutes, jitter: ±%d minutes), applies from the next cycle",
+		baseIntervalMinutes, jitterWindowMinutes)
+}
+
+func (w *Worker) interval() (int, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.baseIntervalMinutes, w.jitterWindowMinutes
+}
+
 // runCycleSafely runs a dialogue cycle with panic recovery
 func (w *Worker) runCycleSafely() {
 	defer func() {
@@ -0,0 +1,30 @@
+package goal
+
+import (
+    "context"
+    "errors"
+)
+
+// ErrInsufficientTime is returned by an ActionExecutor that declined to start an action
+// because too little of the cycle remains. The sub-goal stays pending for the next cycle
+// and is not counted as a failure.
+var ErrInsufficientTime = errors.New("insufficient cycle time remaining for action")
+
+type actionContextKey struct{}
+
+// ActionContext identifies the goal and sub-goal an executor call belongs to
+type ActionContext struct {
+    GoalID    string
+    SubGoalID string
+}
+
+// WithActionContext attaches goal identifiers to a context passed to the ActionExecutor
+func WithActionContext(ctx context.Context, goalID, subGoalID string) context.Context {
+    return context.WithValue(ctx, actionContextKey{}, ActionContext{GoalID: goalID, SubGoalID: subGoalID})
+}
+
+// ActionContextFrom returns the goal identifiers attached by WithActionContext
+func ActionContextFrom(ctx context.Context) (ActionContext, bool) {
+    ac, ok := ctx.Value(actionContextKey{}).(ActionContext)
+    return ac, ok
+}
@@ -0,0 +1,64 @@
+package goal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OverdueReport is the pending question raised when a goal passes its deadline
+type OverdueReport struct {
+	Deadline     time.Time `json:"deadline"`
+	OverdueSince time.Time `json:"overdue_since"`
+	Progress     float64   `json:"progress_percentage"`
+	Question     string    `json:"question"`
+}
+
+// deadlineLayouts are the formats accepted for deadlines, most specific first
+var deadlineLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDeadline parses an RFC3339 timestamp or a plain date. A plain date means the end
+// of that day in local time. An empty string means no deadline.
+func ParseDeadline(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range deadlineLayouts {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		return &t, nil
+	}
+	return nil, fmt.Errorf("invalid deadline %q: use RFC3339 or YYYY-MM-DD", s)
+}
+
+// IsPastDeadline reports whether the goal has a deadline that has passed
+func (g *Goal) IsPastDeadline(now time.Time) bool {
+	return g.Deadline != nil && now.After(*g.Deadline)
+}
+
+// newOverdueReport builds the question put to the user when a goal misses its deadline
+func newOverdueReport(g *Goal, now time.Time) *OverdueReport {
+	question := fmt.Sprintf("Goal %q passed its deadline of %s at %.0f%% progress. Should I keep working on it?",
+		g.Title, g.Deadline.Format("2006-01-02 15:04"), g.ProgressPercentage)
+	if g.Origin == OriginAI {
+		question = fmt.Sprintf("Self-directed goal %q passed its deadline of %s at %.0f%% progress and will be archived.",
+			g.Title, g.Deadline.Format("2006-01-02 15:04"), g.ProgressPercentage)
+	}
+	return &OverdueReport{
+		Deadline:     *g.Deadline,
+		OverdueSince: now,
+		Progress:     g.ProgressPercentage,
+		Question:     question,
+	}
+}
@@ -0,0 +1,149 @@
+package goal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeadlineBoostCurve(t *testing.T) {
+	calc := NewCalculator(&PriorityConfig{
+		DeadlineWindow:        100 * time.Hour,
+		DeadlineMaxBoost:      40,
+		DeadlineCurveExponent: 2.0,
+	})
+	now := time.Now()
+
+	cases := []struct {
+		name      string
+		remaining time.Duration
+		want      int
+	}{
+		{"outside window", 150 * time.Hour, 0},
+		{"window start", 100 * time.Hour, 0},
+		{"halfway", 50 * time.Hour, 10},  // 40 * 0.5^2
+		{"near due", 10 * time.Hour, 32}, // 40 * 0.9^2 = 32.4
+		{"past due", -time.Hour, 40},
+	}
+	for _, tc := range cases {
+		deadline := now.Add(tc.remaining)
+		g := &Goal{CurrentPriority: 50, Deadline: &deadline}
+		if got := calc.DeadlineBoost(g, now); got != tc.want {
+			t.Errorf("%s: boost = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	if got := calc.DeadlineBoost(&Goal{CurrentPriority: 50}, now); got != 0 {
+		t.Errorf("no deadline: boost = %d, want 0", got)
+	}
+}
+
+func TestEffectivePriorityCapsAt100(t *testing.T) {
+	calc := NewCalculator(nil)
+	now := time.Now()
+	deadline := now.Add(-time.Minute)
+	g := &Goal{CurrentPriority: 90, Deadline: &deadline}
+
+	if got := calc.EffectivePriority(g, now); got != 100 {
+		t.Errorf("EffectivePriority = %d, want 100", got)
+	}
+}
+
+func TestRankGoalsPrefersApproachingDeadline(t *testing.T) {
+	calc := NewCalculator(nil)
+	selector := NewGoalSelector(calc)
+	soon := time.Now().Add(2 * time.Hour)
+
+	relaxed := &Goal{ID: "relaxed", CurrentPriority: 60, TimeScore: 10}
+	urgent := &Goal{ID: "urgent", CurrentPriority: 50, TimeScore: 10, Deadline: &soon}
+
+	ranked := selector.RankGoals([]*Goal{relaxed, urgent})
+	if ranked[0].ID != "urgent" {
+		t.Errorf("expected goal with approaching deadline first, got %s", ranked[0].ID)
+	}
+}
+
+func TestRankGoalsBreaksTiesByAgeThenID(t *testing.T) {
+	selector := NewGoalSelector(NewCalculator(nil))
+	now := time.Now()
+
+	b := &Goal{ID: "b", CurrentPriority: 50, TimeScore: 10, CreationTime: now}
+	a := &Goal{ID: "a", CurrentPriority: 50, TimeScore: 10, CreationTime: now}
+	old := &Goal{ID: "old", CurrentPriority: 50, TimeScore: 10, CreationTime: now.Add(-time.Hour)}
+
+	for _, queue := range [][]*Goal{{b, a, old}, {old, a, b}, {a, old, b}} {
+		ranked := selector.RankGoals(queue)
+		if ranked[0].ID != "old" || ranked[1].ID != "a" || ranked[2].ID != "b" {
+			t.Errorf("expected old, a, b for equal scores, got %s, %s, %s", ranked[0].ID, ranked[1].ID, ranked[2].ID)
+		}
+	}
+}
+
+func TestRankGoalsShortestJobFirst(t *testing.T) {
+	selector := NewGoalSelector(NewCalculator(nil))
+	steps := func(pending, done int) []SubGoal {
+		var sgs []SubGoal
+		for i := 0; i < pending; i++ {
+			sgs = append(sgs, SubGoal{Status: SubGoalPending})
+		}
+		for i := 0; i < done; i++ {
+			sgs = append(sgs, SubGoal{Status: SubGoalCompleted})
+		}
+		return sgs
+	}
+	long := &Goal{ID: "long", CurrentPriority: 90, TimeScore: 10, SubGoals: steps(5, 0)}
+	short := &Goal{ID: "short", CurrentPriority: 40, TimeScore: 10, SubGoals: steps(1, 4)}
+	unplanned := &Goal{ID: "unplanned", CurrentPriority: 100, TimeScore: 10}
+
+	if ranked := selector.RankGoals([]*Goal{short, long, unplanned}); ranked[0].ID != "unplanned" {
+		t.Errorf("expected the score to rank by default, got %s first", ranked[0].ID)
+	}
+	selector.ShortestJobFirst = true
+	ranked := selector.RankGoals([]*Goal{unplanned, long, short})
+	if ranked[0].ID != "short" || ranked[1].ID != "long" || ranked[2].ID != "unplanned" {
+		t.Errorf("expected short, long, unplanned, got %s, %s, %s", ranked[0].ID, ranked[1].ID, ranked[2].ID)
+	}
+}
+
+func TestParseDeadline(t *testing.T) {
+	if d, err := ParseDeadline(""); err != nil || d != nil {
+		t.Fatalf("empty deadline: got %v, %v", d, err)
+	}
+
+	d, err := ParseDeadline("2026-03-01")
+	if err != nil {
+		t.Fatalf("date: %v", err)
+	}
+	if d.Day() != 1 || d.Hour() != 23 || d.Minute() != 59 {
+		t.Errorf("plain date should mean end of day, got %s", d)
+	}
+
+	d, err = ParseDeadline("2026-03-01T09:30:00Z")
+	if err != nil {
+		t.Fatalf("RFC3339: %v", err)
+	}
+	if !d.Equal(time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("RFC3339 parsed as %s", d)
+	}
+
+	if _, err := ParseDeadline("next tuesday"); err == nil {
+		t.Error("expected error for unparseable deadline")
+	}
+}
+
+func TestOverdueTransitions(t *testing.T) {
+	sm := NewStateManager()
+	for _, from := range []GoalState{StateQueued, StateActive} {
+		g := &Goal{State: from}
+		if err := sm.Transition(g, StateOverdue); err != nil {
+			t.Errorf("%s -> OVERDUE: %v", from, err)
+		}
+	}
+
+	g := &Goal{State: StateOverdue}
+	if err := sm.Transition(g, StateActive); err == nil {
+		t.Error("OVERDUE -> ACTIVE should go through the queue")
+	}
+	if err := sm.Transition(g, StateQueued); err != nil {
+		t.Errorf("OVERDUE -> QUEUED: %v", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
     "fmt"
     "log"
     "strings"
+
+    "go-llama/internal/utils"
 )
 
 // DerivationEngine analyzes context to propose new goals.
@@ -33,8 +35,9 @@ type DerivationResult struct {
     SkippedReason []string
 }
 
-// AnalyzeMemories inspects recent memories and derives potential goals.
-func (d *DerivationEngine) AnalyzeMemories(ctx context.Context, limit int) (*DerivationResult, error) {
+// AnalyzeMemories inspects recent memories and derives potential goals. Focus areas the
+// system chose in its last self-assessment are offered as a steer.
+func (d *DerivationEngine) AnalyzeMemories(ctx context.Context, limit int, focusAreas []string) (*DerivationResult, error) {
     log.Printf("[Derivation] Analyzing recent memories for goal derivation...")
 
     // 1. Define search context
@@ -56,11 +59,16 @@ func (d *DerivationEngine) AnalyzeMemories(ctx context.Context, limit int) (*Der
     for _, content := range contents {
         // Clean content for prompt
         cleanContent := strings.ReplaceAll(content, "\n", " ")
-        if len(cleanContent) > 300 {
-            cleanContent = cleanContent[:300] + "..."
-        }
+        cleanContent = utils.Truncate(cleanContent, 300)
         contextBuilder.WriteString(fmt.Sprintf("- %s\n", cleanContent))
     }
+    if len(focusAreas) > 0 {
+        contextBuilder.WriteString("\nYou previously decided to focus on:\n")
+        for _, area := range focusAreas {
+            contextBuilder.WriteString(fmt.Sprintf("- %s\n", area))
+        }
+        contextBuilder.WriteString("Prefer goals that advance these areas where the memories support it.\n")
+    }
 
     prompt := fmt.Sprintf(`Analyze the following recent system reflections and memories. Identify potential new goals for the system to pursue.
 
@@ -6,6 +6,8 @@ import (
     "log"
 	"fmt"
 	"time"
+
+	"go-llama/internal/utils"
 )
 
 // EdgeCaseHandler manages exceptional scenarios in goal pursuit.
@@ -112,8 +114,5 @@ func generateUniqueSubGoalID(g *Goal) string {
 }
 
 func truncate(s string, maxLen int) string {
-    if len(s) <= maxLen {
-        return s
-    }
-    return s[:maxLen] + "..."
+    return utils.Truncate(s, maxLen)
 }
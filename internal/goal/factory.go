@@ -4,6 +4,8 @@ import (
     "math/rand"
     "time"
 
+    "go-llama/internal/utils"
+
     "github.com/google/uuid"
 )
 
@@ -130,7 +132,7 @@ func (f *Factory) generateBasePriority(origin GoalOrigin) int {
 // Note: In Phase 3, this will be enhanced by LLM summarization
 func extractTitle(description string) string {
     if len(description) > 60 {
-        return description[:57] + "..."
+        return utils.Prefix(description, 57) + "..."
     }
     return description
 }
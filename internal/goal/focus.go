@@ -0,0 +1,117 @@
+package goal
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+)
+
+// FocusConfig controls how strongly the areas the system chose to focus on in its
+// last self-assessment pull matching goals forward
+type FocusConfig struct {
+	PriorityBonus int     // Added to the effective priority of a matching goal
+	MinSimilarity float64 // Cosine similarity a goal needs to match a focus area
+}
+
+// focusTracker holds the current focus areas and the embeddings used to match goals
+// against them. Goal embeddings are cached by ID and only recomputed when the
+// description changes.
+type focusTracker struct {
+	mu       sync.Mutex
+	cfg      FocusConfig
+	areas    []string
+	areaVecs [][]float32
+	goalVecs map[string]cachedGoalVector
+}
+
+type cachedGoalVector struct {
+	description string
+	vector      []float32
+}
+
+// SetFocusConfig configures the focus bonus. A zero bonus turns it off.
+func (o *Orchestrator) SetFocusConfig(cfg FocusConfig) {
+	o.focus.mu.Lock()
+	defer o.focus.mu.Unlock()
+	o.focus.cfg = cfg
+}
+
+// SetFocusAreas replaces the focus areas. They are embedded at the start of the next
+// cycle, so an unchanged list costs nothing.
+func (o *Orchestrator) SetFocusAreas(areas []string) {
+	o.focus.mu.Lock()
+	defer o.focus.mu.Unlock()
+	if slices.Equal(o.focus.areas, areas) {
+		return
+	}
+	o.focus.areas = append([]string(nil), areas...)
+	o.focus.areaVecs = nil
+}
+
+// FocusAreas returns the current focus areas
+func (o *Orchestrator) FocusAreas() []string {
+	o.focus.mu.Lock()
+	defer o.focus.mu.Unlock()
+	return append([]string(nil), o.focus.areas...)
+}
+
+// applyFocus works out the focus bonus of each goal and hands it to the calculator.
+// Goals without a match, or every goal when focus is off, get no bonus.
+func (o *Orchestrator) applyFocus(ctx context.Context, goals []*Goal) {
+	o.focus.mu.Lock()
+	defer o.focus.mu.Unlock()
+
+	f := &o.focus
+	if f.cfg.PriorityBonus <= 0 || len(f.areas) == 0 || o.embedder == nil {
+		o.Calculator.SetFocusBonuses(nil)
+		return
+	}
+	if f.areaVecs == nil {
+		vecs := make([][]float32, 0, len(f.areas))
+		for _, area := range f.areas {
+			vec, err := o.embedder.Embed(ctx, area)
+			if err != nil {
+				// Retried next cycle; no bonus until every area is embedded
+				log.Printf("[Orchestrator] WARNING: Failed to embed focus area %q: %v", area, err)
+				o.Calculator.SetFocusBonuses(nil)
+				return
+			}
+			vecs = append(vecs, vec)
+		}
+		f.areaVecs = vecs
+	}
+	if f.goalVecs == nil {
+		f.goalVecs = make(map[string]cachedGoalVector)
+	}
+
+	bonuses := make(map[string]int)
+	seen := make(map[string]bool, len(goals))
+	for _, g := range goals {
+		seen[g.ID] = true
+		cached, ok := f.goalVecs[g.ID]
+		if !ok || cached.description != g.Description {
+			vec, err := o.embedder.Embed(ctx, g.Description)
+			if err != nil {
+				log.Printf("[Orchestrator] WARNING: Failed to embed goal %s for focus matching: %v", g.ID, err)
+				continue
+			}
+			cached = cachedGoalVector{description: g.Description, vector: vec}
+			f.goalVecs[g.ID] = cached
+		}
+		for i, areaVec := range f.areaVecs {
+			if cosineSimilarity(cached.vector, areaVec) >= f.cfg.MinSimilarity {
+				bonuses[g.ID] = f.cfg.PriorityBonus
+				log.Printf("[Orchestrator] Goal %s matches focus area %q (+%d priority)", g.ID, f.areas[i], f.cfg.PriorityBonus)
+				break
+			}
+		}
+	}
+	// Goals that left the queue no longer need their embedding
+	for id := range f.goalVecs {
+		if !seen[id] {
+			delete(f.goalVecs, id)
+		}
+	}
+	o.Calculator.SetFocusBonuses(bonuses)
+}
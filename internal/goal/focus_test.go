@@ -0,0 +1,59 @@
+package goal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// topicEmbedder embeds text onto one axis per topic word it mentions
+type topicEmbedder struct {
+	topics []string
+	calls  int
+}
+
+func (e *topicEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.calls++
+	vec := make([]float32, len(e.topics)+1)
+	vec[len(e.topics)] = 0.1
+	for i, topic := range e.topics {
+		if strings.Contains(strings.ToLower(text), topic) {
+			vec[i] = 1
+		}
+	}
+	return vec, nil
+}
+
+func TestFocusBonusFavoursMatchingGoals(t *testing.T) {
+	embedder := &topicEmbedder{topics: []string{"rust", "gardening"}}
+	calc := NewCalculator(nil)
+	o := &Orchestrator{Calculator: calc, Selector: NewGoalSelector(calc), embedder: embedder}
+	o.SetFocusConfig(FocusConfig{PriorityBonus: 15, MinSimilarity: 0.8})
+	o.SetFocusAreas([]string{"Rust ownership"})
+
+	gardening := &Goal{ID: "gardening", Description: "Learn about gardening", CurrentPriority: 60, TimeScore: 10}
+	rust := &Goal{ID: "rust", Description: "Practice Rust borrowing", CurrentPriority: 50, TimeScore: 10}
+	goals := []*Goal{gardening, rust}
+
+	o.applyFocus(context.Background(), goals)
+	if calc.FocusBonus(rust) != 15 || calc.FocusBonus(gardening) != 0 {
+		t.Fatalf("expected only the matching goal boosted, got rust=%d gardening=%d", calc.FocusBonus(rust), calc.FocusBonus(gardening))
+	}
+	if next := o.Selector.SelectNextGoal(goals); next.ID != "rust" {
+		t.Errorf("expected the focus bonus to win selection, got %s", next.ID)
+	}
+
+	// Goal embeddings are cached between cycles
+	calls := embedder.calls
+	o.applyFocus(context.Background(), goals)
+	if embedder.calls != calls {
+		t.Errorf("expected cached embeddings reused, got %d more calls", embedder.calls-calls)
+	}
+
+	// Clearing the focus removes the bonus
+	o.SetFocusAreas(nil)
+	o.applyFocus(context.Background(), goals)
+	if calc.FocusBonus(rust) != 0 {
+		t.Errorf("expected no bonus without focus areas, got %d", calc.FocusBonus(rust))
+	}
+}
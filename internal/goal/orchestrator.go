@@ -2,11 +2,14 @@ package goal
 
 import (
     "context"
+    "errors"
     "log"
 	"fmt"
 	"time"
 	"strings"
     "sync"
+
+    "go-llama/internal/tools"
 )
 
 // ActionExecutor is the interface bridging the Goal system to the Tool system in the Dialogue Engine
@@ -14,6 +17,11 @@ type ActionExecutor interface {
     ExecuteToolAction(ctx context.Context, tool string, params map[string]interface{}) (string, error)
 }
 
+// OverdueNotifier is told when a goal passes its deadline so the miss can be reflected on
+type OverdueNotifier interface {
+    GoalOverdue(ctx context.Context, g *Goal)
+}
+
 // Orchestrator manages the autonomous goal cycle
 type Orchestrator struct {
     mu sync.Mutex
@@ -46,11 +54,14 @@ type Orchestrator struct {
     
     // Performance Optimization
     cycleCounter     int
+    reasoningPaused  bool // Main LLM backed up: run planned tool steps only
 
     // Bridges
     Executor       ActionExecutor // Implemented by Dialogue Engine
+    Overdue        OverdueNotifier // Implemented by Dialogue Engine
     availableTools []string       // List of tools from Dialogue Engine
     embedder       Embedder       // Embedder for semantic operations
+    focus          focusTracker   // Focus areas from the last self-assessment
 }
 
 // SetAvailableTools updates the list of tools available for goal validation
@@ -125,12 +136,26 @@ func (o *Orchestrator) GetActiveGoal(ctx context.Context) (*Goal, error) {
     if len(goals) == 0 {
         return nil, nil
     }
+    goals[0].EffectivePriority = o.Calculator.EffectivePriority(goals[0], time.Now())
     return goals[0], nil
 }
 
-// GetQueuedGoals returns all goals in QUEUED state.
+// GetQueuedGoals returns all goals in QUEUED state, in selection order.
 func (o *Orchestrator) GetQueuedGoals(ctx context.Context) ([]*Goal, error) {
-    return o.Repo.GetByState(ctx, StateQueued)
+    goals, err := o.Repo.GetByState(ctx, StateQueued)
+    if err != nil {
+        return nil, err
+    }
+    now := time.Now()
+    for _, g := range goals {
+        g.EffectivePriority = o.Calculator.EffectivePriority(g, now)
+    }
+    return o.Selector.RankGoals(goals), nil
+}
+
+// GetOverdueGoals returns goals that passed their deadline and await a decision.
+func (o *Orchestrator) GetOverdueGoals(ctx context.Context) ([]*Goal, error) {
+    return o.Repo.GetByState(ctx, StateOverdue)
 }
 
 // GetGoalDetails returns a specific goal by ID.
@@ -166,11 +191,90 @@ func (o *Orchestrator) PrioritizeGoal(ctx context.Context, id string, boost int)
     return o.Repo.Store(ctx, g)
 }
 
+// SetGoalDeadline sets or clears (nil) a goal's deadline. An overdue goal given a
+// future deadline, or none, returns to the queue.
+func (o *Orchestrator) SetGoalDeadline(ctx context.Context, id string, deadline *time.Time) error {
+    g, err := o.Repo.Get(ctx, id)
+    if err != nil {
+        return err
+    }
+    if g.State == StateCompleted || g.State == StateArchived {
+        return fmt.Errorf("goal %s is %s", id, g.State)
+    }
+
+    g.Deadline = deadline
+    if g.State == StateOverdue && !g.IsPastDeadline(time.Now()) {
+        if err := o.StateManager.Transition(g, StateQueued); err != nil {
+            return err
+        }
+        g.OverdueReport = nil
+    }
+    g.EffectivePriority = o.Calculator.EffectivePriority(g, time.Now())
+    return o.Repo.Store(ctx, g)
+}
+
+// ResolveOverdueGoal answers an overdue goal's pending report. Continuing re-queues it
+// with the given deadline (nil for none); otherwise it is archived.
+func (o *Orchestrator) ResolveOverdueGoal(ctx context.Context, id string, continueGoal bool, deadline *time.Time) error {
+    g, err := o.Repo.Get(ctx, id)
+    if err != nil {
+        return err
+    }
+    if g.State != StateOverdue {
+        return fmt.Errorf("goal %s is not overdue (state %s)", id, g.State)
+    }
+
+    if !continueGoal {
+        if err := o.StateManager.Transition(g, StateArchived); err != nil {
+            return err
+        }
+        g.ArchiveReason = ArchiveUserCancelled
+        return o.Repo.Store(ctx, g)
+    }
+
+    if deadline != nil && !deadline.After(time.Now()) {
+        return fmt.Errorf("new deadline %s is not in the future", deadline.Format(time.RFC3339))
+    }
+    if err := o.StateManager.Transition(g, StateQueued); err != nil {
+        return err
+    }
+    g.Deadline = deadline
+    g.OverdueReport = nil
+    o.Logger.LogGoalDecision("OVERDUE_CONTINUED", "User chose to continue overdue goal", []string{g.ID})
+    return o.Repo.Store(ctx, g)
+}
+
 // SetExecutor connects the orchestrator to the Dialogue Engine's tool execution
 func (o *Orchestrator) SetExecutor(exec ActionExecutor) {
     o.Executor = exec
 }
 
+// SetOverdueNotifier connects the orchestrator to the Dialogue Engine's reflection notes
+func (o *Orchestrator) SetOverdueNotifier(n OverdueNotifier) {
+    o.Overdue = n
+}
+
+// SetReasoningPaused stops cycles from calling the main LLM while it is overloaded. A
+// paused cycle derives no proposals, plans and reviews nothing and defers practice
+// steps, but still executes sub-goals that are already planned.
+func (o *Orchestrator) SetReasoningPaused(paused bool) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    o.reasoningPaused = paused
+}
+
+// SetShortestJobFirst has goal selection prefer queued goals with the fewest sub-goals
+// left, to work through a backlog
+func (o *Orchestrator) SetShortestJobFirst(enabled bool) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    if o.Selector == nil || o.Selector.ShortestJobFirst == enabled {
+        return
+    }
+    o.Selector.ShortestJobFirst = enabled
+    log.Printf("[Orchestrator] Shortest job first selection: %v", enabled)
+}
+
 // ExecuteCycle runs one full iteration of the autonomous goal system
 func (o *Orchestrator) ExecuteCycle(ctx context.Context) error {
     o.mu.Lock()
@@ -184,9 +288,9 @@ func (o *Orchestrator) ExecuteCycle(ctx context.Context) error {
 
     // 0. Derivation Phase: Generate new proposals from recent memories
     // Optimization: Run derivation periodically (e.g., every 5 cycles) to save resources
-    if o.DerivationEngine != nil && o.cycleCounter % 5 == 0 {
+    if o.DerivationEngine != nil && o.cycleCounter % 5 == 0 && !o.reasoningPaused {
         o.Logger.LogGoalDecision("DERIVATION_START", "Analyzing memories for new proposals", nil)
-        proposals, err := o.DerivationEngine.AnalyzeMemories(ctx, 5)
+        proposals, err := o.DerivationEngine.AnalyzeMemories(ctx, 5, o.FocusAreas())
         if err != nil {
             o.Logger.LogError("Derivation", err, nil)
         } else {
@@ -223,6 +327,23 @@ func (o *Orchestrator) ExecuteCycle(ctx context.Context) error {
         return err
     }
 
+    overdueGoals, err := o.Repo.GetByState(ctx, StateOverdue)
+    if err != nil {
+        return err
+    }
+
+    // 0b. Deadline Check: goals past their deadline stop competing for selection
+    now := time.Now()
+    o.checkDeadlines(ctx, activeGoals, now)
+    o.checkDeadlines(ctx, queuedGoals, now)
+    stillActive := make([]*Goal, 0, len(activeGoals))
+    for _, g := range activeGoals {
+        if g.State == StateActive {
+            stillActive = append(stillActive, g)
+        }
+    }
+    activeGoals = stillActive
+
     // 1. Process Proposals
     // Pass queuedGoals and availableTools to avoid re-fetching inside validation checks
     if err := o.processValidationQueue(ctx, proposedGoals, queuedGoals, o.availableTools); err != nil {
@@ -231,7 +352,7 @@ func (o *Orchestrator) ExecuteCycle(ctx context.Context) error {
 
     // 2. Priority Maintenance
     // Pass queuedGoals to avoid re-fetching for decay logic
-    if err := o.applyPriorityMaintenance(ctx, queuedGoals); err != nil {
+    if err := o.applyPriorityMaintenance(ctx, queuedGoals, overdueGoals); err != nil {
         o.Logger.LogError("MaintenancePhase", err, nil)
     }
 
@@ -244,6 +365,10 @@ func (o *Orchestrator) ExecuteCycle(ctx context.Context) error {
         }
     }
 
+    // Goals matching the current focus areas get a small priority bonus for selection
+    // and switching decisions
+    o.applyFocus(ctx, append(append([]*Goal{}, activeGoals...), validQueued...))
+
     // 3. Goal Selection
     var activeGoal *Goal
     if len(activeGoals) > 0 {
@@ -502,9 +627,51 @@ func (o *Orchestrator) processValidationQueue(ctx context.Context, proposed []*G
     return nil
 }
 
-// Refactored to accept pre-fetched list
-func (o *Orchestrator) applyPriorityMaintenance(ctx context.Context, queued []*Goal) error {
+// checkDeadlines moves goals past their deadline to OVERDUE with a pending report and
+// tells the notifier, so a missed deadline is reflected on rather than silently archived.
+func (o *Orchestrator) checkDeadlines(ctx context.Context, goals []*Goal, now time.Time) {
+    for _, g := range goals {
+        if g.State != StateQueued && g.State != StateActive {
+            continue
+        }
+        if !g.IsPastDeadline(now) {
+            continue
+        }
+        if err := o.StateManager.Transition(g, StateOverdue); err != nil {
+            o.Logger.LogError("DeadlineCheck", err, map[string]interface{}{"goal_id": g.ID})
+            continue
+        }
+        g.OverdueReport = newOverdueReport(g, now)
+        o.Logger.LogGoalDecision("GOAL_OVERDUE", g.OverdueReport.Question, []string{g.ID})
+        if err := o.Repo.Store(ctx, g); err != nil {
+            o.Logger.LogError("StoreOverdueGoal", err, map[string]interface{}{"goal_id": g.ID})
+        }
+        if o.Overdue != nil {
+            o.Overdue.GoalOverdue(ctx, g)
+        }
+    }
+}
+
+// Refactored to accept pre-fetched lists. Overdue user goals are never archived here;
+// they wait for the user to answer their report. Overdue AI goals are archived once
+// their report has been raised.
+func (o *Orchestrator) applyPriorityMaintenance(ctx context.Context, queued []*Goal, overdue []*Goal) error {
+    for _, g := range overdue {
+        if g.Origin == OriginUser {
+            continue
+        }
+        if err := o.StateManager.Transition(g, StateArchived); err != nil {
+            continue
+        }
+        g.ArchiveReason = ArchiveDeadlineMissed
+        o.Repo.Store(ctx, g)
+    }
+
+    now := time.Now()
     for _, g := range queued {
+        if g.State != StateQueued {
+            continue // Became overdue this cycle
+        }
         oldP := g.CurrentPriority
         // Applying 1 cycle decay for this tick
         o.Calculator.ApplyDecay(g, 1)
@@ -514,7 +681,9 @@ func (o *Orchestrator) applyPriorityMaintenance(ctx context.Context, queued []*G
              o.Logger.LogPriorityChange(g.ID, oldP, g.CurrentPriority, "Cycle Decay")
         }
 
-        if g.CurrentPriority < 10 {
+        // An approaching deadline keeps a goal alive even as its base priority decays
+        g.EffectivePriority = o.Calculator.EffectivePriority(g, now)
+        if g.EffectivePriority < 10 {
             o.StateManager.Transition(g, StateArchived)
             g.ArchiveReason = ArchivePriorityDecay
             // State transition logged by listener
@@ -539,6 +708,11 @@ func (o *Orchestrator) executeActiveGoal(ctx context.Context, g *Goal, queued []
     }
     
     needsReview := o.Monitor.DetectStagnation(g)
+    if needsReview && o.reasoningPaused {
+        // Reviews may replan; the stagnation stays counted for the next full cycle
+        log.Printf("[Orchestrator] Deferring review of %s while reasoning is paused", g.ID)
+        needsReview = false
+    }
     
     if needsReview {
         o.StateManager.Transition(g, StateReviewing)
@@ -596,6 +770,10 @@ case "DEMOTE":
     }
 
     // 2. Plan Execution (Ensure Tree exists)
+    if len(g.SubGoals) == 0 && o.reasoningPaused {
+        log.Printf("[Orchestrator] Not planning %s while reasoning is paused", g.ID)
+        return nil
+    }
     if len(g.SubGoals) == 0 {
         // No plan yet - invoke TreeBuilder (Intelligence Layer)
         if o.TreeBuilder != nil {
@@ -623,6 +801,9 @@ case "DEMOTE":
          return nil
     }
 
+    // Dependents of failed steps would wait forever; skip them with the reason
+    blockFailedDependents(g)
+
     // Find next pending subgoal whose dependencies are met
     var activeSG *SubGoal
     for i := range g.SubGoals {
@@ -654,6 +835,11 @@ case "DEMOTE":
         }
     }
 
+    if activeSG.ActionType == ActionPractice && o.reasoningPaused {
+        log.Printf("[Orchestrator] Deferring practice step %s while reasoning is paused", activeSG.ID)
+        return nil
+    }
+
     // === EXECUTION PHASE ===
     activeSG.Status = SubGoalActive
     log.Printf("[Orchestrator] Executing SubGoal: %s", activeSG.Description)
@@ -688,18 +874,37 @@ case "DEMOTE":
     }
 
     // --- DYNAMIC PARAMETER RESOLUTION (Heuristic: Search -> Parse) ---
-    // If we are parsing a URL, check if the previous step was a Search.
+    // If we are parsing a URL, check if the step it depends on (or, without a declared
+    // dependency, the previous step) was a Search.
     // If so, we ALWAYS prefer the URL from the search results over the planner's hallucination.
     if activeSG.ToolName == "web_parse_unified" {
-        // Find the most recent completed subgoal
+        lastSubGoal := searchDependency(g, activeSG)
+        if lastSubGoal == nil {
+            // Find the most recent completed subgoal
+            for i := len(g.SubGoals) - 1; i >= 0; i-- {
+                if g.SubGoals[i].Status == SubGoalCompleted && g.SubGoals[i].Outcome != "" {
+                    lastSubGoal = &g.SubGoals[i]
+                    break
+                }
+            }
+        }
         var lastResult string
-        var lastSubGoal *SubGoal
-        for i := len(g.SubGoals) - 1; i >= 0; i-- {
-            if g.SubGoals[i].Status == SubGoalCompleted && g.SubGoals[i].Outcome != "" {
-                lastResult = g.SubGoals[i].Outcome
-                lastSubGoal = &g.SubGoals[i]
-                break
+        if lastSubGoal != nil {
+            lastResult = lastSubGoal.Outcome
+        }
+
+        // Tell the parse what it is for: the question its search asked and the goal
+        // it serves, so extraction targets them instead of a generic purpose
+        if activeSG.Params == nil { activeSG.Params = make(map[string]interface{}) }
+        if _, ok := activeSG.Params["goal_description"]; !ok {
+            activeSG.Params["goal_description"] = g.Description
+        }
+        if _, ok := activeSG.Params["question"]; !ok && lastSubGoal != nil && lastSubGoal.ToolName == "search" {
+            question := lastSubGoal.Description
+            if query, ok := lastSubGoal.Params["query"].(string); ok && query != "" {
+                question = query
             }
+            activeSG.Params["question"] = question
         }
 
         // Heuristic: If previous step used 'search' tool, we extract the URL from those results
@@ -707,10 +912,8 @@ case "DEMOTE":
             log.Printf("[Orchestrator] Detecting Search->Parse chain. Validating URL via Small LLM...")
             
             // Truncate context to prevent overloading small LLM
-            contextContent := lastResult
-            if len(contextContent) > 2000 {
-                contextContent = contextContent[:2000] + "..."
-            }
+            contextContent := truncate(lastResult, 2000)
+            contextContent, _ = tools.WrapUntrusted("search results", contextContent)
 
             extractPrompt := fmt.Sprintf(`Analyze the search results below. Extract the single most relevant URL that matches the objective: "%s".
             If the results are irrelevant or no good URL exists, return "NONE".
@@ -783,10 +986,15 @@ case "DEMOTE":
             params["query"] = activeSG.Description
         }
 
-        result, err := o.Executor.ExecuteToolAction(ctx, toolName, params)
+        result, err := o.Executor.ExecuteToolAction(WithActionContext(ctx, g.ID, activeSG.ID), toolName, params)
         duration := time.Since(start)
 
-        if err != nil {
+        if errors.Is(err, ErrInsufficientTime) {
+            // Not a failure: the action never started, so retry it next cycle
+            activeSG.Status = SubGoalPending
+            log.Printf("[Orchestrator] Deferred SubGoal to next cycle: %v", err)
+            o.Logger.LogSubGoalExecution(activeSG.ID, "DEFERRED: "+err.Error(), duration)
+        } else if err != nil {
             activeSG.Status = SubGoalFailed
             activeSG.FailureReason = err.Error()
             o.Logger.LogSubGoalExecution(activeSG.ID, "FAILED: "+err.Error(), duration)
@@ -818,6 +1026,47 @@ case "DEMOTE":
 }
 
 // areDependenciesMet checks if all prerequisite sub-goals are completed.
+// blockFailedDependents marks pending sub-goals whose dependencies failed or were
+// skipped as skipped, recording which dependency blocked them
+func blockFailedDependents(g *Goal) {
+    for changed := true; changed; {
+        changed = false
+        status := make(map[string]SubGoalStatus, len(g.SubGoals))
+        for _, sg := range g.SubGoals {
+            status[sg.ID] = sg.Status
+        }
+        for i := range g.SubGoals {
+            sg := &g.SubGoals[i]
+            if sg.Status != SubGoalPending {
+                continue
+            }
+            for _, depID := range sg.Dependencies {
+                if s := status[depID]; s == SubGoalFailed || s == SubGoalSkipped {
+                    sg.Status = SubGoalSkipped
+                    sg.FailureReason = fmt.Sprintf("Blocked: depends on sub-goal %s, which was %s", depID, strings.ToLower(string(s)))
+                    log.Printf("[Orchestrator] Sub-goal %s of %s blocked by %s (%s)", sg.ID, g.ID, depID, s)
+                    changed = true
+                    break
+                }
+            }
+        }
+    }
+}
+
+// searchDependency returns the completed search sub-goal sg declares as a dependency,
+// so a parse step reads its own search's results however the steps were ordered
+func searchDependency(g *Goal, sg *SubGoal) *SubGoal {
+    for i := len(sg.Dependencies) - 1; i >= 0; i-- {
+        for j := range g.SubGoals {
+            dep := &g.SubGoals[j]
+            if dep.ID == sg.Dependencies[i] && dep.ToolName == "search" && dep.Status == SubGoalCompleted && dep.Outcome != "" {
+                return dep
+            }
+        }
+    }
+    return nil
+}
+
 func (o *Orchestrator) areDependenciesMet(g *Goal, dependencies []string) bool {
     if len(dependencies) == 0 {
         return true
@@ -0,0 +1,133 @@
+package goal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBlockFailedDependents(t *testing.T) {
+	g := &Goal{ID: "g1", SubGoals: []SubGoal{
+		{ID: "1.2", ToolName: "web_parse_unified", Status: SubGoalPending, Dependencies: []string{"1.1"}},
+		{ID: "1.1", ToolName: "search", Status: SubGoalFailed},
+		{ID: "1.3", Status: SubGoalPending, Dependencies: []string{"1.2"}},
+		{ID: "2.1", ToolName: "search", Status: SubGoalPending},
+	}}
+	blockFailedDependents(g)
+
+	for _, id := range []string{"1.2", "1.3"} {
+		for _, sg := range g.SubGoals {
+			if sg.ID == id && (sg.Status != SubGoalSkipped || !strings.HasPrefix(sg.FailureReason, "Blocked:")) {
+				t.Errorf("expected %s blocked, got %s %q", id, sg.Status, sg.FailureReason)
+			}
+		}
+	}
+	if g.SubGoals[3].Status != SubGoalPending {
+		t.Errorf("expected the independent sub-goal left pending, got %s", g.SubGoals[3].Status)
+	}
+}
+
+func TestSearchDependencyPrefersDeclaredSearch(t *testing.T) {
+	g := &Goal{SubGoals: []SubGoal{
+		{ID: "1", ToolName: "search", Status: SubGoalCompleted, Outcome: "https://example.com/bees"},
+		{ID: "2", ToolName: "search", Status: SubGoalCompleted, Outcome: "https://example.com/ants"},
+		{ID: "3", ToolName: "web_parse_unified", Status: SubGoalPending, Dependencies: []string{"1"}},
+	}}
+	if dep := searchDependency(g, &g.SubGoals[2]); dep == nil || dep.ID != "1" {
+		t.Errorf("expected the parse's own search, got %+v", dep)
+	}
+	if dep := searchDependency(g, &SubGoal{ToolName: "web_parse_unified"}); dep != nil {
+		t.Errorf("expected no dependency without a declared one, got %+v", dep)
+	}
+}
+
+// memoryRepo keeps goals in a map
+type memoryRepo struct{ goals map[string]*Goal }
+
+func (r *memoryRepo) Store(ctx context.Context, g *Goal) error {
+	r.goals[g.ID] = g
+	return nil
+}
+
+func (r *memoryRepo) GetByState(ctx context.Context, state GoalState) ([]*Goal, error) {
+	var goals []*Goal
+	for _, g := range r.goals {
+		if g.State == state {
+			goals = append(goals, g)
+		}
+	}
+	return goals, nil
+}
+
+func (r *memoryRepo) Get(ctx context.Context, id string) (*Goal, error) { return r.goals[id], nil }
+
+func (r *memoryRepo) SearchSimilar(ctx context.Context, embedding []float32, limit int) ([]*Goal, error) {
+	return nil, nil
+}
+
+// countingLLM fails every call, counting them
+type countingLLM struct{ calls int }
+
+func (l *countingLLM) GenerateJSON(ctx context.Context, prompt string, target interface{}) error {
+	l.calls++
+	return context.DeadlineExceeded
+}
+
+func (l *countingLLM) GenerateText(ctx context.Context, prompt string) (string, error) {
+	l.calls++
+	return "", context.DeadlineExceeded
+}
+
+type recordingExecutor struct{ tools []string }
+
+func (e *recordingExecutor) ExecuteToolAction(ctx context.Context, tool string, params map[string]interface{}) (string, error) {
+	e.tools = append(e.tools, tool)
+	return "done", nil
+}
+
+func TestReasoningPausedRunsOnlyPlannedSteps(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryRepo{goals: map[string]*Goal{}}
+	llm := &countingLLM{}
+	calc := NewCalculator(nil)
+	selector := NewGoalSelector(calc)
+	monitor := NewProgressMonitor()
+	o := NewOrchestrator(repo, nil, NewFactory(nil), NewStateManager(), selector,
+		NewReviewProcessor(selector, calc, monitor), calc, monitor,
+		NewDerivationEngine(llm, nil, nil, nil), NewTreeBuilder(llm), nil, nil, nil, llm)
+	exec := &recordingExecutor{}
+	o.SetExecutor(exec)
+	o.SetAvailableTools([]string{"search"})
+	o.SetReasoningPaused(true)
+
+	// Unplanned: a full cycle would ask the main LLM to decompose it
+	repo.Store(ctx, &Goal{ID: "unplanned", State: StateActive})
+	o.cycleCounter = 4 // The next cycle would derive proposals
+	if err := o.ExecuteCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if llm.calls != 0 || len(repo.goals["unplanned"].SubGoals) != 0 {
+		t.Fatalf("expected no main LLM calls while paused, got %d", llm.calls)
+	}
+
+	// Planned: the pending tool step still runs
+	repo.goals["unplanned"].State = StateArchived
+	repo.Store(ctx, &Goal{ID: "planned", State: StateActive, SubGoals: []SubGoal{
+		{ID: "1", Description: "bees", ToolName: "search", ActionType: ActionResearch, Status: SubGoalPending},
+		{ID: "2", Description: "practise", ActionType: ActionPractice, Status: SubGoalPending, Dependencies: []string{"1"}},
+	}})
+	if err := o.ExecuteCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(exec.tools) != 1 || repo.goals["planned"].SubGoals[0].Status != SubGoalCompleted {
+		t.Fatalf("expected the planned search executed, got %v", exec.tools)
+	}
+
+	// Practice needs the main LLM, so it waits
+	if err := o.ExecuteCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if llm.calls != 0 || repo.goals["planned"].SubGoals[1].Status != SubGoalPending {
+		t.Errorf("expected the practice step deferred, got %s after %d calls", repo.goals["planned"].SubGoals[1].Status, llm.calls)
+	}
+}
@@ -3,11 +3,16 @@ package goal
 import (
     "math"
 	"math/rand"
+	"sync"
+	"time"
 )
 
 // Calculator handles priority and scoring logic
 type Calculator struct {
     config *PriorityConfig
+
+    focusMu    sync.RWMutex
+    focusBonus map[string]int // Goal ID -> bonus for matching a focus area
 }
 
 // NewCalculator creates a new priority calculator
@@ -58,16 +63,73 @@ func (c *Calculator) ApplyStrengthening(g *Goal) {
     g.ProposalCount++
 }
 
+// SetDeadlineEscalation configures how priority rises as a deadline approaches.
+// Non-positive values keep the current setting.
+func (c *Calculator) SetDeadlineEscalation(window time.Duration, maxBoost int, exponent float64) {
+    if window > 0 {
+        c.config.DeadlineWindow = window
+    }
+    if maxBoost > 0 {
+        c.config.DeadlineMaxBoost = maxBoost
+    }
+    if exponent > 0 {
+        c.config.DeadlineCurveExponent = exponent
+    }
+}
+
+// DeadlineBoost returns the priority added for an approaching deadline.
+// Formula: MaxBoost * (1 - remaining/window) ^ CurveExponent, full boost once past due
+func (c *Calculator) DeadlineBoost(g *Goal, now time.Time) int {
+    if g.Deadline == nil || c.config.DeadlineMaxBoost <= 0 || c.config.DeadlineWindow <= 0 {
+        return 0
+    }
+
+    remaining := g.Deadline.Sub(now)
+    if remaining >= c.config.DeadlineWindow {
+        return 0
+    }
+    urgency := 1.0
+    if remaining > 0 {
+        urgency = 1.0 - float64(remaining)/float64(c.config.DeadlineWindow)
+    }
+    return int(math.Round(float64(c.config.DeadlineMaxBoost) * math.Pow(urgency, c.config.DeadlineCurveExponent)))
+}
+
+// SetFocusBonuses replaces the per-goal bonuses for matching a focus area
+func (c *Calculator) SetFocusBonuses(bonuses map[string]int) {
+    c.focusMu.Lock()
+    defer c.focusMu.Unlock()
+    c.focusBonus = bonuses
+}
+
+// FocusBonus returns the priority added because a goal matches a focus area
+func (c *Calculator) FocusBonus(g *Goal) int {
+    c.focusMu.RLock()
+    defer c.focusMu.RUnlock()
+    return c.focusBonus[g.ID]
+}
+
+// EffectivePriority is the current priority plus deadline escalation and any focus
+// bonus, capped at 100
+func (c *Calculator) EffectivePriority(g *Goal, now time.Time) int {
+    p := g.CurrentPriority + c.DeadlineBoost(g, now) + c.FocusBonus(g)
+    if p > 100 {
+        p = 100
+    }
+    return p
+}
+
 // CalculateSelectionScore calculates the score used to rank goals for selection
-// Formula: Priority / (TimeScore ^ Exponent)
+// Formula: EffectivePriority / (TimeScore ^ Exponent)
 func (c *Calculator) CalculateSelectionScore(g *Goal) float64 {
+    priority := c.EffectivePriority(g, time.Now())
     if g.TimeScore == 0 {
         // Avoid division by zero; if effort is unknown, assume 1
-        return float64(g.CurrentPriority)
+        return float64(priority)
     }
 
     ts := float64(g.TimeScore)
-    p := float64(g.CurrentPriority)
+    p := float64(priority)
     exp := c.config.SelectionExponent
 
     return p / math.Pow(ts, exp)
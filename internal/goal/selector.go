@@ -1,10 +1,17 @@
 package goal
 
-import "sort"
+import (
+    "math"
+    "sort"
+)
 
 // GoalSelector ranks and selects goals based on priority and effort.
 type GoalSelector struct {
     Calculator *Calculator
+
+    // ShortestJobFirst ranks goals with fewer sub-goals left ahead of the score, so a
+    // backlog is worked down. Goals not yet planned rank after all planned ones.
+    ShortestJobFirst bool
 }
 
 // NewGoalSelector creates a new selector with a priority calculator.
@@ -26,21 +33,54 @@ func (s *GoalSelector) SelectNextGoal(queuedGoals []*Goal) *Goal {
     return ranked[0]
 }
 
-// RankGoals sorts goals by SelectionScore in descending order.
+// RankGoals sorts goals by SelectionScore in descending order, after the sub-goals left
+// with ShortestJobFirst. Equal scores go oldest first, then by ID, so the same queue
+// always ranks the same way.
 func (s *GoalSelector) RankGoals(goals []*Goal) []*Goal {
     // Create a slice for sorting to avoid mutating order unexpectedly
     sorted := make([]*Goal, len(goals))
     copy(sorted, goals)
 
-    sort.Slice(sorted, func(i, j int) bool {
-        scoreI := s.Calculator.CalculateSelectionScore(sorted[i])
-        scoreJ := s.Calculator.CalculateSelectionScore(sorted[j])
-        return scoreI > scoreJ // Descending order
+    // Scores depend on the time, so each goal is scored once for the whole sort
+    scores := make(map[*Goal]float64, len(sorted))
+    for _, g := range sorted {
+        scores[g] = s.Calculator.CalculateSelectionScore(g)
+    }
+
+    sort.SliceStable(sorted, func(i, j int) bool {
+        a, b := sorted[i], sorted[j]
+        if s.ShortestJobFirst {
+            if ra, rb := remainingSubGoals(a), remainingSubGoals(b); ra != rb {
+                return ra < rb
+            }
+        }
+        if scores[a] != scores[b] {
+            return scores[a] > scores[b] // Descending order
+        }
+        if !a.CreationTime.Equal(b.CreationTime) {
+            return a.CreationTime.Before(b.CreationTime)
+        }
+        return a.ID < b.ID
     })
 
     return sorted
 }
 
+// remainingSubGoals counts the sub-goals a goal has yet to finish; a goal with no plan
+// counts as longer than any planned one
+func remainingSubGoals(g *Goal) int {
+    if len(g.SubGoals) == 0 {
+        return math.MaxInt
+    }
+    remaining := 0
+    for _, sg := range g.SubGoals {
+        if sg.Status == SubGoalPending || sg.Status == SubGoalActive {
+            remaining++
+        }
+    }
+    return remaining
+}
+
 // CompareForReview compares an active goal against queued goals during a review.
 // Returns the active goal score (with bonus) and the best queued goal.
 func (s *GoalSelector) CompareForReview(activeGoal *Goal, queuedGoals []*Goal) (activeScore float64, bestQueuedGoal *Goal) {
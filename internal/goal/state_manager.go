@@ -35,9 +35,11 @@ var validTransitions = map[GoalState]map[GoalState]bool{
     StateQueued: {
         StateActive:   true,
         StateArchived: true, // Priority decay
+        StateOverdue:  true,
     },
     StateActive: {
         StateReviewing: true,
+        StateOverdue:   true,
         StatePaused:    true,
         StateCompleted: true,
         StateArchived:  true,
@@ -51,6 +53,10 @@ var validTransitions = map[GoalState]map[GoalState]bool{
     StatePaused: {
         StateQueued: true,
     },
+    StateOverdue: {
+        StateQueued:   true, // Continued or given a new deadline
+        StateArchived: true,
+    },
     StateCompleted: {}, // Terminal state
     StateArchived: {
         StateQueued: true, // Revival process
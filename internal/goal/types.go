@@ -16,6 +16,7 @@ const (
     StatePaused      GoalState = "PAUSED"
     StateCompleted   GoalState = "COMPLETED"
     StateArchived    GoalState = "ARCHIVED"
+    StateOverdue     GoalState = "OVERDUE" // Deadline passed, awaiting a decision
 )
 
 // GoalOrigin defines who created the goal
@@ -45,6 +46,7 @@ const (
     ArchivePriorityDecay ArchiveReason = "PRIORITY_DECAY"
     ArchiveDuplicate     ArchiveReason = "DUPLICATE"
     ArchiveValidationFailed ArchiveReason = "VALIDATION_FAILED"
+    ArchiveDeadlineMissed   ArchiveReason = "DEADLINE_MISSED"
 )
 
 // SkillProficiency defines the level of a skill
@@ -92,6 +94,9 @@ type Goal struct {
     LastPriorityCalculation time.Time `json:"last_priority_calculation"`
     ProposalCount          int        `json:"proposal_count"`
     LastProposedTimestamp  time.Time  `json:"last_proposed_timestamp"`
+    Deadline               *time.Time `json:"deadline,omitempty"`
+    EffectivePriority      int        `json:"effective_priority"` // CurrentPriority plus deadline escalation
+    OverdueReport          *OverdueReport `json:"overdue_report,omitempty"` // Pending question while OVERDUE
 
     // Progress
     State                  GoalState  `json:"state"`
@@ -251,6 +256,12 @@ type PriorityConfig struct {
     StrengtheningMax    int     `json:"strengthening_max"`
     SelectionExponent   float64 `json:"selection_exponent"`
     ProgressBonusFactor float64 `json:"progress_bonus_factor"`
+
+    // Deadline escalation: priority rises over the window before a deadline,
+    // following (elapsed fraction of window)^exponent up to the max boost
+    DeadlineWindow        time.Duration `json:"deadline_window"`
+    DeadlineMaxBoost      int           `json:"deadline_max_boost"`
+    DeadlineCurveExponent float64       `json:"deadline_curve_exponent"`
 }
 
 // DefaultPriorityConfig returns the default priority configuration
@@ -266,5 +277,8 @@ func DefaultPriorityConfig() *PriorityConfig {
         StrengtheningMax:    15,
         SelectionExponent:   0.7,
         ProgressBonusFactor: 0.5,
+        DeadlineWindow:        72 * time.Hour,
+        DeadlineMaxBoost:      40,
+        DeadlineCurveExponent: 2.0,
     }
 }
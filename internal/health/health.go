@@ -0,0 +1,213 @@
+// internal/health/health.go
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Dependency names used by the server's checks
+const (
+	DependencyPostgres       = "postgres"
+	DependencyRedis          = "redis"
+	DependencyQdrant         = "qdrant"
+	DependencyEmbeddings     = "embeddings"
+	DependencyReasoningModel = "reasoning_model"
+	DependencySearXNG        = "searxng"
+)
+
+// Check probes one downstream dependency
+type Check struct {
+	Name     string
+	Required bool // The server is not ready while a required dependency is down
+	Probe    func(ctx context.Context) error
+}
+
+// DependencyStatus is the outcome of the latest check of one dependency. LastError
+// survives recovery so an intermittent failure stays visible.
+type DependencyStatus struct {
+	Name        string     `json:"name"`
+	Required    bool       `json:"required"`
+	Healthy     bool       `json:"healthy"`
+	LatencyMs   int64      `json:"latency_ms"`
+	CheckedAt   time.Time  `json:"checked_at"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// WorkerStatus is a background worker's most recent run. Workers do not affect
+// readiness; a failed run is retried on the worker's next tick.
+type WorkerStatus struct {
+	Name         string        `json:"name"`
+	Running      bool          `json:"running"`
+	Runs         int           `json:"runs"`
+	Panics       int           `json:"panics"` // Runs that panicked and were recovered
+	LastStarted  *time.Time    `json:"last_started,omitempty"`
+	LastFinished *time.Time    `json:"last_finished,omitempty"`
+	LastDuration time.Duration `json:"last_duration_ns"`
+	LastOutcome  string        `json:"last_outcome,omitempty"` // What the last run did, or why it skipped
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+// Snapshot is the result of checking every registered dependency
+type Snapshot struct {
+	Ready        bool               `json:"ready"` // Every required dependency is healthy
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	Workers      []WorkerStatus     `json:"workers,omitempty"`
+	Models       map[string]string  `json:"models,omitempty"` // Model role -> model name in use
+}
+
+// Unhealthy names the dependencies among names that are registered and down. Names
+// that were never registered are not reported, so a disabled component never blocks.
+func (s Snapshot) Unhealthy(names ...string) []string {
+	var down []string
+	for _, name := range names {
+		for _, dep := range s.Dependencies {
+			if dep.Name == name && !dep.Healthy {
+				down = append(down, name)
+				break
+			}
+		}
+	}
+	return down
+}
+
+// Checker runs dependency checks concurrently, each under its own timeout, and caches
+// the latest snapshot
+type Checker struct {
+	mu         sync.Mutex
+	checks     []Check
+	workers    []func() WorkerStatus
+	models     func() map[string]string
+	timeout    time.Duration
+	maxAge     time.Duration
+	last       Snapshot
+	lastErrors map[string]DependencyStatus // Only LastError and LastErrorAt are used
+	running    chan struct{}               // Closed when the in-flight run finishes
+}
+
+// NewChecker creates a checker. timeout bounds each check; maxAge is how long Cached
+// reuses a snapshot.
+func NewChecker(timeout, maxAge time.Duration) *Checker {
+	return &Checker{
+		timeout:    timeout,
+		maxAge:     maxAge,
+		lastErrors: make(map[string]DependencyStatus),
+	}
+}
+
+// Register adds a dependency check
+func (c *Checker) Register(check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check)
+}
+
+// RegisterWorker adds a background worker whose status each snapshot reports
+func (c *Checker) RegisterWorker(status func() WorkerStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.workers = append(c.workers, status)
+}
+
+// SetModels reports the model each role resolved to in every snapshot
+func (c *Checker) SetModels(models func() map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.models = models
+}
+
+// Check probes every dependency now and caches the result. Concurrent callers share
+// one run.
+func (c *Checker) Check(ctx context.Context) Snapshot {
+	c.mu.Lock()
+	if c.running != nil {
+		running := c.running
+		c.mu.Unlock()
+		select {
+		case <-running:
+		case <-ctx.Done():
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.last
+	}
+	running := make(chan struct{})
+	c.running = running
+	checks := append([]Check(nil), c.checks...)
+	workers := append([]func() WorkerStatus(nil), c.workers...)
+	models := c.models
+	c.mu.Unlock()
+
+	statuses := make([]DependencyStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			statuses[i] = c.probe(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	snap := Snapshot{Ready: true, CheckedAt: time.Now(), Dependencies: statuses}
+	for _, status := range workers {
+		snap.Workers = append(snap.Workers, status())
+	}
+	if models != nil {
+		snap.Models = models()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range snap.Dependencies {
+		dep := &snap.Dependencies[i]
+		if dep.LastError != "" {
+			c.lastErrors[dep.Name] = *dep
+		} else if prev, ok := c.lastErrors[dep.Name]; ok {
+			dep.LastError, dep.LastErrorAt = prev.LastError, prev.LastErrorAt
+		}
+		if dep.Required && !dep.Healthy {
+			snap.Ready = false
+		}
+	}
+	c.last = snap
+	c.running = nil
+	close(running)
+	return snap
+}
+
+// Cached returns the latest snapshot, checking again when it is older than maxAge
+func (c *Checker) Cached(ctx context.Context) Snapshot {
+	c.mu.Lock()
+	snap := c.last
+	c.mu.Unlock()
+	if !snap.CheckedAt.IsZero() && time.Since(snap.CheckedAt) < c.maxAge {
+		return snap
+	}
+	return c.Check(ctx)
+}
+
+// probe runs one check under the per-check timeout
+func (c *Checker) probe(ctx context.Context, check Check) DependencyStatus {
+	probeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(probeCtx)
+	status := DependencyStatus{
+		Name:      check.Name,
+		Required:  check.Required,
+		Healthy:   err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		failedAt := status.CheckedAt
+		status.LastError = err.Error()
+		status.LastErrorAt = &failedAt
+	}
+	return status
+}
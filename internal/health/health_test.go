@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckerReadinessFollowsRequiredDependencies(t *testing.T) {
+	checker := NewChecker(time.Second, time.Minute)
+	checker.Register(Check{Name: "db", Required: true, Probe: func(ctx context.Context) error { return nil }})
+	checker.Register(Check{Name: "search", Probe: func(ctx context.Context) error { return errors.New("down") }})
+
+	snap := checker.Check(context.Background())
+	if !snap.Ready {
+		t.Fatal("expected ready when only an optional dependency is down")
+	}
+	if down := snap.Unhealthy("db", "search", "unregistered"); len(down) != 1 || down[0] != "search" {
+		t.Errorf("expected only search unhealthy, got %v", down)
+	}
+
+	checker.Register(Check{Name: "cache", Required: true, Probe: func(ctx context.Context) error { return errors.New("refused") }})
+	if snap := checker.Check(context.Background()); snap.Ready {
+		t.Error("expected not ready when a required dependency is down")
+	}
+}
+
+func TestCheckerTimesOutSlowChecks(t *testing.T) {
+	checker := NewChecker(20*time.Millisecond, time.Minute)
+	checker.Register(Check{Name: "slow", Required: true, Probe: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	start := time.Now()
+	snap := checker.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("check was not bounded by its timeout, took %s", elapsed)
+	}
+	if snap.Ready || snap.Dependencies[0].LastError == "" {
+		t.Errorf("expected timed-out check to be unhealthy with an error, got %+v", snap.Dependencies[0])
+	}
+}
+
+func TestCheckerKeepsLastErrorAfterRecovery(t *testing.T) {
+	fail := true
+	checker := NewChecker(time.Second, 0)
+	checker.Register(Check{Name: "db", Required: true, Probe: func(ctx context.Context) error {
+		if fail {
+			return errors.New("connection refused")
+		}
+		return nil
+	}})
+
+	checker.Check(context.Background())
+	fail = false
+	snap := checker.Cached(context.Background()) // maxAge 0 forces a fresh check
+
+	dep := snap.Dependencies[0]
+	if !dep.Healthy || dep.LastError != "connection refused" || dep.LastErrorAt == nil {
+		t.Errorf("expected healthy with the earlier error kept, got %+v", dep)
+	}
+}
+
+func TestCachedReusesFreshSnapshot(t *testing.T) {
+	calls := 0
+	checker := NewChecker(time.Second, time.Minute)
+	checker.Register(Check{Name: "db", Probe: func(ctx context.Context) error {
+		calls++
+		return nil
+	}})
+
+	checker.Cached(context.Background())
+	checker.Cached(context.Background())
+	if calls != 1 {
+		t.Errorf("expected one probe within maxAge, got %d", calls)
+	}
+}
+
+func TestSnapshotReportsWorkersWithoutAffectingReadiness(t *testing.T) {
+	checker := NewChecker(time.Second, time.Minute)
+	checker.Register(Check{Name: "db", Required: true, Probe: func(ctx context.Context) error { return nil }})
+	checker.RegisterWorker(func() WorkerStatus { return WorkerStatus{Name: "compression", Runs: 3, LastError: "panic: boom"} })
+
+	snap := checker.Check(context.Background())
+	if !snap.Ready {
+		t.Error("expected a failing worker not to affect readiness")
+	}
+	if len(snap.Workers) != 1 || snap.Workers[0].Name != "compression" || snap.Workers[0].Runs != 3 {
+		t.Errorf("expected the worker's status in the snapshot, got %+v", snap.Workers)
+	}
+}
@@ -0,0 +1,189 @@
+// internal/httpclient/httpclient.go
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Config sets the limits, proxying and TLS trust of the shared transport
+type Config struct {
+	MaxIdleConnsPerHost int           // Idle connections kept for reuse per host
+	MaxConnsPerHost     int           // Connections per host, including in use; 0 is unlimited
+	IdleConnTimeout     time.Duration // How long an idle connection is kept
+
+	ProxyURL           string        // Empty uses HTTP_PROXY/HTTPS_PROXY/NO_PROXY from the environment
+	NoProxy            []string      // Hosts reached directly; same patterns as Destination.Host
+	Destinations       []Destination // Per-host proxy overrides, checked first
+	CABundle           string        // PEM file trusted in addition to the system roots
+	InsecureSkipVerify bool          // Skips certificate verification everywhere; logged loudly
+}
+
+// DefaultConfig is used until Configure is called
+var DefaultConfig = Config{
+	MaxIdleConnsPerHost: 10,
+	MaxConnsPerHost:     64,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// Stats are transport-level counters since the factory was created
+type Stats struct {
+	InFlight        int64 `json:"in_flight"`   // Requests sent whose response has not arrived
+	Requests        int64 `json:"requests"`    // Requests sent
+	Errors          int64 `json:"errors"`      // Requests that failed before a response
+	Dials           int64 `json:"dials"`       // New connections opened; requests - dials is roughly reuse
+	DialErrors      int64 `json:"dial_errors"` // Connections that could not be opened
+	MaxConnsPerHost int   `json:"max_conns_per_host"`
+}
+
+// Factory builds clients that share one pooled transport, so connections are reused
+// across components and no single host can take more than MaxConnsPerHost of them
+type Factory struct {
+	config    Config
+	transport *http.Transport
+	proxies   *proxySelector
+	tls       *tls.Config
+	dialer    *net.Dialer
+
+	inFlight   atomic.Int64
+	requests   atomic.Int64
+	errors     atomic.Int64
+	dials      atomic.Int64
+	dialErrors atomic.Int64
+}
+
+// NewFactory creates a factory with its own transport
+func NewFactory(config Config) (*Factory, error) {
+	proxies, err := newProxySelector(config)
+	if err != nil {
+		return nil, err
+	}
+	tlsConf, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Factory{
+		config:  config,
+		proxies: proxies,
+		tls:     tlsConf,
+		dialer:  &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	f.transport = &http.Transport{
+		Proxy:                 proxies.proxyForRequest,
+		DialContext:           f.dial,
+		TLSClientConfig:       f.TLSConfig(),
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return f, nil
+}
+
+// dial opens a counted connection, checked by the control set with WithDialControl
+func (f *Factory) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	f.dials.Add(1)
+	dialer := f.dialer
+	if control, ok := ctx.Value(dialControlKey{}).(func(string, string, syscall.RawConn) error); ok {
+		guarded := *f.dialer
+		guarded.Control = control
+		dialer = &guarded
+	}
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		f.dialErrors.Add(1)
+	}
+	return conn, err
+}
+
+type dialControlKey struct{}
+
+// WithDialControl has connections opened for requests under ctx checked by control once
+// their address is resolved, as net.Dialer.Control does. Pooled connections are reused
+// without it.
+func WithDialControl(ctx context.Context, control func(network, address string, c syscall.RawConn) error) context.Context {
+	return context.WithValue(ctx, dialControlKey{}, control)
+}
+
+// RoundTrip sends a request over the shared transport, counting it
+func (f *Factory) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests.Add(1)
+	f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+
+	resp, err := f.transport.RoundTrip(req)
+	if err != nil {
+		f.errors.Add(1)
+	}
+	return resp, err
+}
+
+// Client returns a client with the given overall timeout; 0 means no timeout, for
+// streaming responses
+func (f *Factory) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: f, Timeout: timeout}
+}
+
+// Stats returns the transport's counters
+func (f *Factory) Stats() Stats {
+	return Stats{
+		InFlight:        f.inFlight.Load(),
+		Requests:        f.requests.Load(),
+		Errors:          f.errors.Load(),
+		Dials:           f.dials.Load(),
+		DialErrors:      f.dialErrors.Load(),
+		MaxConnsPerHost: f.config.MaxConnsPerHost,
+	}
+}
+
+// CloseIdleConnections closes the pooled connections not in use
+func (f *Factory) CloseIdleConnections() {
+	f.transport.CloseIdleConnections()
+}
+
+var defaultFactory atomic.Pointer[Factory]
+
+func init() {
+	f, _ := NewFactory(DefaultConfig)
+	defaultFactory.Store(f)
+}
+
+// Configure replaces the default factory. Clients from New pick up the new transport
+// on their next request; the old one's idle connections are closed.
+func Configure(config Config) error {
+	f, err := NewFactory(config)
+	if err != nil {
+		return err
+	}
+	if old := defaultFactory.Swap(f); old != nil {
+		old.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Default returns the factory clients from New use
+func Default() *Factory {
+	return defaultFactory.Load()
+}
+
+// defaultTransport sends each request through the current default factory
+type defaultTransport struct{}
+
+func (defaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return Default().RoundTrip(req)
+}
+
+// New returns a client on the shared default transport with the given overall timeout;
+// 0 means no timeout, for streaming responses
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: defaultTransport{}, Timeout: timeout}
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFactoryCountsRequestsAndReusesConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	f, err := NewFactory(DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := f.Client(5 * time.Second)
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	stats := f.Stats()
+	if stats.Requests != 3 || stats.InFlight != 0 || stats.Errors != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.Dials != 1 {
+		t.Errorf("expected one pooled connection reused, got %d dials", stats.Dials)
+	}
+}
+
+func TestFactoryCapsConnectionsPerHost(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	f, err := NewFactory(Config{MaxIdleConnsPerHost: 2, MaxConnsPerHost: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := f.Client(5 * time.Second)
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			done <- err
+		}()
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for f.Stats().InFlight < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if dials := f.Stats().Dials; dials != 1 {
+		t.Errorf("expected the second request to wait for the one allowed connection, got %d dials", dials)
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestConfigureSwitchesDefaultTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := New(5 * time.Second)
+	if err := Configure(DefaultConfig); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if got := Default().Stats().Requests; got != 1 {
+		t.Errorf("expected an existing client to use the new default transport, got %d requests", got)
+	}
+}
+
+func TestNewFactoryRejectsInvalidProxy(t *testing.T) {
+	if _, err := NewFactory(Config{ProxyURL: "not a url"}); err == nil {
+		t.Error("expected an error for an invalid proxy URL")
+	}
+	if _, err := NewFactory(Config{ProxyURL: "http://proxy.internal:3128"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
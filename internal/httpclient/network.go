@@ -0,0 +1,255 @@
+// internal/httpclient/network.go
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Destination overrides the proxy for requests to matching hosts. Host is a hostname
+// ("llama.local"), a domain and its subdomains ("corp.example" or ".corp.example"),
+// a CIDR range ("10.0.0.0/8") or any of those with a port ("llama.local:8080").
+type Destination struct {
+	Host     string
+	ProxyURL string // Empty connects directly
+}
+
+// proxyRule is a parsed Destination
+type proxyRule struct {
+	host  string
+	proxy *url.URL
+}
+
+// proxySelector picks the proxy for a request: the first matching destination, then
+// no proxy for no_proxy hosts, then the configured proxy, then the environment
+type proxySelector struct {
+	rules   []proxyRule
+	noProxy []string
+	proxy   *url.URL
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid proxy URL %q (want http:// or https://host:port)", raw)
+	}
+	return u, nil
+}
+
+func newProxySelector(config Config) (*proxySelector, error) {
+	s := &proxySelector{noProxy: config.NoProxy}
+	if config.ProxyURL != "" {
+		proxy, err := parseProxyURL(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		s.proxy = proxy
+	}
+	for _, d := range config.Destinations {
+		if strings.TrimSpace(d.Host) == "" {
+			return nil, fmt.Errorf("destination override without a host")
+		}
+		rule := proxyRule{host: d.Host}
+		if d.ProxyURL != "" {
+			proxy, err := parseProxyURL(d.ProxyURL)
+			if err != nil {
+				return nil, fmt.Errorf("destination %s: %w", d.Host, err)
+			}
+			rule.proxy = proxy
+		}
+		s.rules = append(s.rules, rule)
+	}
+	return s, nil
+}
+
+// proxyFor returns the proxy for target, or nil to connect directly
+func (s *proxySelector) proxyFor(target *url.URL) (*url.URL, error) {
+	host, port := target.Hostname(), target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "https" {
+			port = "443"
+		}
+	}
+	for _, rule := range s.rules {
+		if matchHost(rule.host, host, port) {
+			return rule.proxy, nil
+		}
+	}
+	for _, pattern := range s.noProxy {
+		if matchHost(pattern, host, port) {
+			return nil, nil
+		}
+	}
+	if s.proxy != nil {
+		return s.proxy, nil
+	}
+	return http.ProxyFromEnvironment(&http.Request{URL: target})
+}
+
+// ProxyFor returns the proxy the transport uses for target, or nil when it connects
+// directly
+func (f *Factory) ProxyFor(target *url.URL) (*url.URL, error) {
+	return f.proxies.proxyFor(target)
+}
+
+func (s *proxySelector) proxyForRequest(req *http.Request) (*url.URL, error) {
+	return s.proxyFor(req.URL)
+}
+
+// matchHost reports whether host:port matches a destination or no_proxy pattern
+func matchHost(pattern, host, port string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	host = strings.ToLower(host)
+	if pattern == "*" {
+		return true
+	}
+	if strings.Contains(pattern, "/") {
+		_, network, err := net.ParseCIDR(pattern)
+		ip := net.ParseIP(host)
+		return err == nil && ip != nil && network.Contains(ip)
+	}
+	if h, p, err := net.SplitHostPort(pattern); err == nil {
+		if p != port {
+			return false
+		}
+		pattern = h
+	}
+	pattern = strings.TrimPrefix(pattern, ".")
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// tlsConfig builds the TLS settings for outbound connections, or nil for Go's defaults
+func tlsConfig(config Config) (*tls.Config, error) {
+	if config.CABundle == "" && !config.InsecureSkipVerify {
+		return nil, nil
+	}
+	tlsConf := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if config.CABundle != "" {
+		pem, err := os.ReadFile(config.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", config.CABundle)
+		}
+		tlsConf.RootCAs = pool
+	}
+	if config.InsecureSkipVerify {
+		log.Printf("[HTTP] WARNING: ********************************************************")
+		log.Printf("[HTTP] WARNING: TLS certificate verification is DISABLED for all outbound")
+		log.Printf("[HTTP] WARNING: requests (network.insecure_skip_verify). Any host can be")
+		log.Printf("[HTTP] WARNING: impersonated. Use network.ca_bundle for an internal CA instead.")
+		log.Printf("[HTTP] WARNING: ********************************************************")
+	}
+	return tlsConf, nil
+}
+
+// TLSConfig returns a copy of the factory's TLS settings, or nil when Go's defaults
+// apply, for clients that do not go through its transport
+func (f *Factory) TLSConfig() *tls.Config {
+	if f.tls == nil {
+		return nil
+	}
+	return f.tls.Clone()
+}
+
+// DialContext opens a TCP connection to addr (host:port) the way the transport would
+// reach an https URL there: directly, or tunnelled with CONNECT through the selected
+// proxy. It suits gRPC's context dialer.
+func (f *Factory) DialContext(ctx context.Context, addr string) (net.Conn, error) {
+	proxy, err := f.proxies.proxyFor(&url.URL{Scheme: "https", Host: addr})
+	if err != nil {
+		return nil, err
+	}
+	if proxy == nil {
+		return f.dial(ctx, "tcp", addr)
+	}
+
+	proxyAddr := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), map[string]string{"http": "80", "https": "443"}[proxy.Scheme])
+	}
+	conn, err := f.dial(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s unreachable: %w", proxy.Host, err)
+	}
+	if proxy.Scheme == "https" {
+		tlsConf := f.TLSConfig()
+		if tlsConf == nil {
+			tlsConf = &tls.Config{}
+		}
+		tlsConf.ServerName = proxy.Hostname()
+		tlsConn := tls.Client(conn, tlsConf)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("proxy %s TLS handshake failed: %w", proxy.Host, err)
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	connect := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		connect.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := connect.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s CONNECT failed: %w", proxy.Host, err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, connect)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s CONNECT failed: %w", proxy.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused CONNECT to %s: %s", proxy.Host, addr, resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn returns bytes read past the CONNECT response before reading the conn
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// DialContext dials addr through the default factory; see Factory.DialContext
+func DialContext(ctx context.Context, addr string) (net.Conn, error) {
+	return Default().DialContext(ctx, addr)
+}
@@ -0,0 +1,225 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testProxy is a forward proxy that handles plain requests and CONNECT tunnels,
+// counting what passes through it
+type testProxy struct {
+	*httptest.Server
+	forwarded atomic.Int64
+	tunnels   atomic.Int64
+}
+
+func newTestProxy(t *testing.T) *testProxy {
+	p := &testProxy{}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			p.tunnels.Add(1)
+			upstream, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				upstream.Close()
+				return
+			}
+			conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			go func() {
+				io.Copy(upstream, buf)
+				upstream.Close()
+			}()
+			io.Copy(conn, upstream)
+			conn.Close()
+			return
+		}
+		p.forwarded.Add(1)
+		req, _ := http.NewRequest(r.Method, r.URL.String(), r.Body)
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func newTarget(t *testing.T) *httptest.Server {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(target.Close)
+	return target
+}
+
+func get(t *testing.T, f *Factory, target string) {
+	t.Helper()
+	resp, err := f.Client(5 * time.Second).Get(target)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Fatalf("expected the target's response, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestProxyRoutesRequests(t *testing.T) {
+	proxy := newTestProxy(t)
+	target := newTarget(t)
+	targetURL, _ := url.Parse(target.URL)
+
+	cases := []struct {
+		name    string
+		config  Config
+		proxied bool
+	}{
+		{"proxy", Config{ProxyURL: proxy.URL}, true},
+		{"no_proxy", Config{ProxyURL: proxy.URL, NoProxy: []string{"127.0.0.0/8"}}, false},
+		{"direct destination", Config{ProxyURL: proxy.URL, Destinations: []Destination{{Host: targetURL.Host}}}, false},
+		{"proxied destination", Config{NoProxy: []string{"*"}, Destinations: []Destination{{Host: targetURL.Host, ProxyURL: proxy.URL}}}, true},
+	}
+	for _, c := range cases {
+		before := proxy.forwarded.Load()
+		f, err := NewFactory(c.config)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		get(t, f, target.URL)
+		if proxied := proxy.forwarded.Load() > before; proxied != c.proxied {
+			t.Errorf("%s: expected proxied=%v, got %v", c.name, c.proxied, proxied)
+		}
+	}
+}
+
+func TestCABundleTrustsInternalCA(t *testing.T) {
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	f, err := NewFactory(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Client(5 * time.Second).Get(target.URL); err == nil {
+		t.Fatal("expected an unknown CA to be rejected")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: target.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err = NewFactory(Config{CABundle: bundle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get(t, f, target.URL)
+
+	// The tunnel through the proxy carries the same TLS trust
+	proxy := newTestProxy(t)
+	f, err = NewFactory(Config{CABundle: bundle, ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get(t, f, target.URL)
+	if proxy.tunnels.Load() != 1 {
+		t.Errorf("expected the https request tunnelled through the proxy, got %d tunnels", proxy.tunnels.Load())
+	}
+}
+
+func TestDialContextTunnelsThroughProxy(t *testing.T) {
+	proxy := newTestProxy(t)
+	target := newTarget(t)
+	targetURL, _ := url.Parse(target.URL)
+
+	for _, c := range []struct {
+		name      string
+		config    Config
+		tunnelled bool
+	}{
+		{"proxy", Config{ProxyURL: proxy.URL}, true},
+		{"direct destination", Config{ProxyURL: proxy.URL, Destinations: []Destination{{Host: targetURL.Hostname()}}}, false},
+	} {
+		before := proxy.tunnels.Load()
+		f, err := NewFactory(c.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := f.DialContext(context.Background(), targetURL.Host)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, target.URL, nil)
+		req.Close = true
+		if err := req.Write(conn); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected the target's response, got %v", c.name, err)
+		}
+		conn.Close()
+		if tunnelled := proxy.tunnels.Load() > before; tunnelled != c.tunnelled {
+			t.Errorf("%s: expected tunnelled=%v, got %v", c.name, c.tunnelled, tunnelled)
+		}
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	cases := []struct {
+		pattern, host, port string
+		want                bool
+	}{
+		{"llama.local", "llama.local", "8080", true},
+		{"corp.example", "api.corp.example", "443", true},
+		{".corp.example", "corp.example", "443", true},
+		{"corp.example", "notcorp.example", "443", false},
+		{"llama.local:8080", "llama.local", "8080", true},
+		{"llama.local:8080", "llama.local", "443", false},
+		{"10.0.0.0/8", "10.1.2.3", "80", true},
+		{"10.0.0.0/8", "192.168.1.4", "80", false},
+		{"*", "anything", "80", true},
+	}
+	for _, c := range cases {
+		if got := matchHost(c.pattern, c.host, c.port); got != c.want {
+			t.Errorf("%s vs %s:%s: expected %v, got %v", c.pattern, c.host, c.port, c.want, got)
+		}
+	}
+}
+
+func TestNewFactoryRejectsBadNetworkConfig(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(bundle, []byte("not a certificate"), 0o600)
+	for name, config := range map[string]Config{
+		"missing bundle":      {CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		"bundle without PEM":  {CABundle: bundle},
+		"destination no host": {Destinations: []Destination{{ProxyURL: "http://proxy:3128"}}},
+		"bad destination":     {Destinations: []Destination{{Host: "x", ProxyURL: "socks5://proxy"}}},
+	} {
+		if _, err := NewFactory(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
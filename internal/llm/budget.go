@@ -0,0 +1,314 @@
+// internal/llm/budget.go
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Budget levels, from no limit reached to the hard limit
+const (
+	BudgetOK   = "ok"
+	BudgetSoft = "soft"
+	BudgetHard = "hard"
+)
+
+// ErrBudgetExceeded is returned for a background call to a hosted provider once the
+// hard limit is reached. Critical (chat) calls are exempt.
+var ErrBudgetExceeded = errors.New("hosted LLM token budget exceeded")
+
+const usageDayLayout = "2006-01-02"
+
+// LLMUsage is one day's token use for a provider and model. Days are UTC.
+type LLMUsage struct {
+	Day       string    `gorm:"type:varchar(10);primaryKey" json:"day"`
+	Provider  string    `gorm:"type:varchar(50);primaryKey" json:"provider"`
+	Model     string    `gorm:"type:varchar(200);primaryKey" json:"model"`
+	Tokens    int64     `gorm:"not null;default:0" json:"tokens"`
+	Calls     int64     `gorm:"not null;default:0" json:"calls"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (LLMUsage) TableName() string {
+	return "growerai_llm_usage"
+}
+
+// BudgetConfig sets token limits on hosted providers. Zero disables a limit; local
+// openai-compatible tokens are recorded but never count against them.
+type BudgetConfig struct {
+	DailySoftTokens   int64
+	DailyHardTokens   int64
+	MonthlySoftTokens int64
+	MonthlyHardTokens int64
+}
+
+// BudgetAlert describes a limit crossed by recorded usage
+type BudgetAlert struct {
+	Level  string `json:"level"`  // BudgetSoft or BudgetHard
+	Period string `json:"period"` // "daily" or "monthly"
+	Used   int64  `json:"used"`
+	Limit  int64  `json:"limit"`
+}
+
+// BudgetPeriod is the hosted usage and limits of one period
+type BudgetPeriod struct {
+	Used      int64 `json:"used"`
+	SoftLimit int64 `json:"soft_limit"`
+	HardLimit int64 `json:"hard_limit"`
+}
+
+// BudgetStatus reports the budget level and today's usage per provider and model
+type BudgetStatus struct {
+	Level   string       `json:"level"`
+	Daily   BudgetPeriod `json:"daily"`
+	Monthly BudgetPeriod `json:"monthly"`
+	Today   []LLMUsage   `json:"today"`
+}
+
+// BudgetTracker accumulates token usage in Postgres and compares hosted usage with the
+// configured limits. Totals for the current day and month are kept in memory and
+// reloaded from the table at startup.
+type BudgetTracker struct {
+	db  *gorm.DB
+	now func() time.Time
+
+	mu      sync.Mutex
+	cfg     BudgetConfig
+	day     string // Day the counters below belong to
+	daily   int64
+	monthly int64
+	level   string
+	onAlert func(BudgetAlert)
+}
+
+// NewBudgetTracker creates a tracker; call Load before recording
+func NewBudgetTracker(db *gorm.DB, cfg BudgetConfig) *BudgetTracker {
+	return &BudgetTracker{db: db, cfg: cfg, now: time.Now, level: BudgetOK}
+}
+
+// OnAlert sets a function called whenever usage raises the budget level
+func (t *BudgetTracker) OnAlert(fn func(BudgetAlert)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onAlert = fn
+}
+
+// Load reads this day's and month's hosted usage, so limits survive restarts
+func (t *BudgetTracker) Load(ctx context.Context) error {
+	now := t.now().UTC()
+	day := now.Format(usageDayLayout)
+	monthStart := now.Format("2006-01") + "-01"
+
+	var daily, monthly int64
+	query := t.db.WithContext(ctx).Model(&LLMUsage{}).Select("COALESCE(SUM(tokens), 0)").
+		Where("provider <> ?", ProviderOpenAICompatible)
+	if err := query.Session(&gorm.Session{}).Where("day = ?", day).Scan(&daily).Error; err != nil {
+		return fmt.Errorf("failed to load daily LLM usage: %w", err)
+	}
+	if err := query.Session(&gorm.Session{}).Where("day >= ?", monthStart).Scan(&monthly).Error; err != nil {
+		return fmt.Errorf("failed to load monthly LLM usage: %w", err)
+	}
+
+	t.mu.Lock()
+	t.day, t.daily, t.monthly = day, daily, monthly
+	t.level, _ = t.levelLocked()
+	level := t.level
+	t.mu.Unlock()
+
+	if level != BudgetOK {
+		log.Printf("[LLM Budget] Starting at the %s limit (today: %d tokens, this month: %d)", level, daily, monthly)
+	}
+	return nil
+}
+
+// SetLimits replaces the limits, e.g. on a config reload
+func (t *BudgetTracker) SetLimits(cfg BudgetConfig) {
+	t.mu.Lock()
+	t.cfg = cfg
+	alert, fn := t.updateLevelLocked()
+	t.mu.Unlock()
+	t.alert(alert, fn)
+}
+
+// Record adds a call's tokens for a provider and model. Hosted tokens count against
+// the limits even when the row cannot be written.
+func (t *BudgetTracker) Record(ctx context.Context, provider, model string, tokens int) error {
+	now := t.now().UTC()
+	row := LLMUsage{Day: now.Format(usageDayLayout), Provider: provider, Model: model, Tokens: int64(tokens), Calls: 1, UpdatedAt: now}
+	err := t.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "provider"}, {Name: "model"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"tokens":     gorm.Expr("growerai_llm_usage.tokens + ?", tokens),
+			"calls":      gorm.Expr("growerai_llm_usage.calls + 1"),
+			"updated_at": now,
+		}),
+	}).Create(&row).Error
+
+	if provider != ProviderOpenAICompatible {
+		t.mu.Lock()
+		t.rolloverLocked(now)
+		t.daily += int64(tokens)
+		t.monthly += int64(tokens)
+		alert, fn := t.updateLevelLocked()
+		t.mu.Unlock()
+		t.alert(alert, fn)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to record LLM usage: %w", err)
+	}
+	return nil
+}
+
+// Level returns BudgetOK, BudgetSoft or BudgetHard for the current day and month.
+// A nil tracker is always BudgetOK.
+func (t *BudgetTracker) Level() string {
+	if t == nil {
+		return BudgetOK
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rolloverLocked(t.now().UTC())
+	t.updateLevelLocked()
+	return t.level
+}
+
+// OverSoftLimit reports whether calls should move to cheaper models
+func (t *BudgetTracker) OverSoftLimit() bool {
+	return t.Level() != BudgetOK
+}
+
+// OverHardLimit reports whether idle work on hosted providers should stop
+func (t *BudgetTracker) OverHardLimit() bool {
+	return t.Level() == BudgetHard
+}
+
+// Status reports the level, both periods and today's usage rows
+func (t *BudgetTracker) Status(ctx context.Context) (BudgetStatus, error) {
+	level := t.Level()
+	t.mu.Lock()
+	status := BudgetStatus{
+		Level:   level,
+		Daily:   BudgetPeriod{Used: t.daily, SoftLimit: t.cfg.DailySoftTokens, HardLimit: t.cfg.DailyHardTokens},
+		Monthly: BudgetPeriod{Used: t.monthly, SoftLimit: t.cfg.MonthlySoftTokens, HardLimit: t.cfg.MonthlyHardTokens},
+	}
+	day := t.day
+	t.mu.Unlock()
+
+	if err := t.db.WithContext(ctx).Where("day = ?", day).Order("tokens DESC").Find(&status.Today).Error; err != nil {
+		return status, fmt.Errorf("failed to load LLM usage: %w", err)
+	}
+	return status, nil
+}
+
+// rolloverLocked resets the counters when the day or month has changed
+func (t *BudgetTracker) rolloverLocked(now time.Time) {
+	day := now.Format(usageDayLayout)
+	if day == t.day {
+		return
+	}
+	if t.day == "" || day[:7] != t.day[:7] {
+		t.monthly = 0
+	}
+	t.day = day
+	t.daily = 0
+}
+
+// levelLocked returns the level and, above BudgetOK, the limit that set it. Hard limits
+// are checked first, daily before monthly.
+func (t *BudgetTracker) levelLocked() (string, *BudgetAlert) {
+	for _, c := range []BudgetAlert{
+		{Level: BudgetHard, Period: "daily", Used: t.daily, Limit: t.cfg.DailyHardTokens},
+		{Level: BudgetHard, Period: "monthly", Used: t.monthly, Limit: t.cfg.MonthlyHardTokens},
+		{Level: BudgetSoft, Period: "daily", Used: t.daily, Limit: t.cfg.DailySoftTokens},
+		{Level: BudgetSoft, Period: "monthly", Used: t.monthly, Limit: t.cfg.MonthlySoftTokens},
+	} {
+		if c.Limit > 0 && c.Used >= c.Limit {
+			return c.Level, &c
+		}
+	}
+	return BudgetOK, nil
+}
+
+// updateLevelLocked recomputes the level and returns an alert when it went up. A level
+// that drops after a rollover or a raised limit is only logged.
+func (t *BudgetTracker) updateLevelLocked() (*BudgetAlert, func(BudgetAlert)) {
+	level, alert := t.levelLocked()
+	previous := t.level
+	t.level = level
+	if budgetRank(level) > budgetRank(previous) {
+		return alert, t.onAlert
+	}
+	if budgetRank(level) < budgetRank(previous) {
+		log.Printf("[LLM Budget] Budget level back to %s from %s", level, previous)
+	}
+	return nil, nil
+}
+
+// alert logs a crossed limit and notifies the alert function outside the lock
+func (t *BudgetTracker) alert(alert *BudgetAlert, fn func(BudgetAlert)) {
+	if alert == nil {
+		return
+	}
+	log.Printf("[LLM Budget] WARNING: %s %s limit reached: %d of %d hosted tokens used",
+		alert.Period, alert.Level, alert.Used, alert.Limit)
+	if fn != nil {
+		fn(*alert)
+	}
+}
+
+func budgetRank(level string) int {
+	switch level {
+	case BudgetSoft:
+		return 1
+	case BudgetHard:
+		return 2
+	}
+	return 0
+}
+
+// SetBudget makes the manager record every client call's tokens with budget
+func (m *Manager) SetBudget(budget *BudgetTracker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budget = budget
+}
+
+// Budget returns the budget tracker, or nil when budgets are disabled
+func (m *Manager) Budget() *BudgetTracker {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.budget
+}
+
+// recordUsage records the usage.total_tokens of a chat completion response
+func (m *Manager) recordUsage(provider string, payload map[string]interface{}, body []byte) {
+	budget := m.Budget()
+	if budget == nil {
+		return
+	}
+	var resp struct {
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Usage.TotalTokens <= 0 {
+		return
+	}
+	model, _ := payload["model"].(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := budget.Record(ctx, provider, model, resp.Usage.TotalTokens); err != nil {
+		log.Printf("[LLM Budget] WARNING: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newBudgetDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&LLMUsage{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestBudget(db *gorm.DB, cfg BudgetConfig, now *time.Time) *BudgetTracker {
+	tracker := NewBudgetTracker(db, cfg)
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func TestBudgetTrackerLevelsAlertsAndRestarts(t *testing.T) {
+	db := newBudgetDB(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC)
+	cfg := BudgetConfig{DailySoftTokens: 100, DailyHardTokens: 200, MonthlyHardTokens: 1000}
+
+	tracker := newTestBudget(db, cfg, &now)
+	if err := tracker.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var alerts []BudgetAlert
+	tracker.OnAlert(func(a BudgetAlert) { alerts = append(alerts, a) })
+
+	tracker.Record(ctx, ProviderOpenAICompatible, "local", 500)
+	if tracker.Level() != BudgetOK {
+		t.Fatal("expected local tokens not to count against the budget")
+	}
+	tracker.Record(ctx, ProviderAnthropic, "claude", 60)
+	tracker.Record(ctx, ProviderAnthropic, "claude", 60)
+	if !tracker.OverSoftLimit() || tracker.OverHardLimit() {
+		t.Fatalf("expected the soft limit at 120 tokens, level %s", tracker.Level())
+	}
+	tracker.Record(ctx, ProviderOpenAI, "gpt", 90)
+	if !tracker.OverHardLimit() {
+		t.Fatalf("expected the hard limit at 210 tokens, level %s", tracker.Level())
+	}
+	tracker.Record(ctx, ProviderOpenAI, "gpt", 10)
+	if len(alerts) != 2 || alerts[0].Level != BudgetSoft || alerts[1].Level != BudgetHard || alerts[1].Period != "daily" {
+		t.Fatalf("expected one alert per crossed limit, got %+v", alerts)
+	}
+
+	var row LLMUsage
+	db.Where("provider = ? AND model = ?", ProviderAnthropic, "claude").First(&row)
+	if row.Tokens != 120 || row.Calls != 2 || row.Day != "2026-03-31" {
+		t.Errorf("expected calls accumulated in one row per day, got %+v", row)
+	}
+
+	// A restart picks up where the day left off
+	restarted := newTestBudget(db, cfg, &now)
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted.OverHardLimit() {
+		t.Error("expected the hard limit to survive a restart")
+	}
+	status, err := restarted.Status(ctx)
+	if err != nil || status.Daily.Used != 220 || len(status.Today) != 3 {
+		t.Errorf("unexpected status %+v (%v)", status, err)
+	}
+
+	// The daily limits lift at midnight UTC; the new month resets the monthly total
+	now = now.Add(3 * time.Hour)
+	if restarted.Level() != BudgetOK {
+		t.Errorf("expected a new day to lift the daily limit, level %s", restarted.Level())
+	}
+	if status, _ := restarted.Status(ctx); status.Monthly.Used != 0 || len(status.Today) != 0 {
+		t.Errorf("expected empty usage on the first of the month, got %+v", status)
+	}
+}
+
+func TestBackgroundHostedCallsStopAtHardLimit(t *testing.T) {
+	fake := &fakeProvider{status: http.StatusOK, body: `{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":40,"output_tokens":20}}`}
+	srv := fake.server()
+	defer srv.Close()
+
+	m := newTestManager(false)
+	defer m.Stop()
+	provider, _ := NewProvider(ProviderConfig{Name: ProviderAnthropic, URL: srv.URL, APIKey: "k"}, nil)
+	hostedURL := srv.URL + "/v1/chat/completions"
+	m.SetProvider(hostedURL, provider)
+
+	now := time.Now().UTC()
+	budget := newTestBudget(newBudgetDB(t), BudgetConfig{DailyHardTokens: 100}, &now)
+	budget.Load(context.Background())
+	m.SetBudget(budget)
+
+	background := NewClient(m, PriorityBackground, 5*time.Second)
+	critical := NewClient(m, PriorityCritical, 5*time.Second)
+	for i := 0; i < 2; i++ {
+		if _, err := background.Call(context.Background(), hostedURL, testPayload()); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if !budget.OverHardLimit() {
+		t.Fatal("expected each call's total tokens recorded")
+	}
+	if _, err := background.Call(context.Background(), hostedURL, testPayload()); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected background calls refused at the hard limit, got %v", err)
+	}
+	if _, err := critical.Call(context.Background(), hostedURL, testPayload()); err != nil {
+		t.Errorf("expected chat to be exempt from the budget, got %v", err)
+	}
+}
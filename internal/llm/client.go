@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"go-llama/internal/tools"
 )
 
 // Client wraps the queue for easy integration
 type Client struct {
-	manager  *Manager
-	priority Priority
-	timeout  time.Duration
+	manager     *Manager
+	priority    Priority
+	timeout     time.Duration
+	preemptible bool
 }
 
 // NewClient creates a new queue client
@@ -23,8 +26,48 @@ func NewClient(manager *Manager, priority Priority, timeout time.Duration) *Clie
 	}
 }
 
-// Call submits a non-streaming request
+// SetPreemptible lets the manager cancel this client's background requests in favour of
+// critical ones. Callers must resubmit on ErrPreempted.
+func (c *Client) SetPreemptible(enabled bool) {
+	c.preemptible = enabled
+}
+
+// QueueStats reports the load this client's next queued request would wait behind, so
+// background callers can hold off while the queue is backed up
+func (c *Client) QueueStats() QueueStats {
+	return c.manager.queueStats(c.priority)
+}
+
+// Call submits a non-streaming request, or sends it to the hosted provider set for url,
+// and records the tokens used. Background calls to a hosted provider fail with
+// ErrBudgetExceeded once the hard budget limit is reached.
 func (c *Client) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	provider := c.manager.provider(url)
+	if provider == nil {
+		body, err := c.submit(ctx, url, payload)
+		if err == nil {
+			c.manager.recordUsage(ProviderOpenAICompatible, payload, body)
+		}
+		return body, err
+	}
+
+	if c.priority == PriorityBackground && c.manager.Budget().OverHardLimit() {
+		return nil, fmt.Errorf("%w: %s call refused", ErrBudgetExceeded, provider.Name())
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	body, err := provider.Complete(ctx, payload)
+	if err == nil {
+		c.manager.recordUsage(provider.Name(), payload, body)
+	}
+	return body, err
+}
+
+// submit queues a non-streaming request for the local model server
+func (c *Client) submit(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
 	respCh := make(chan *Response, 1)
 	errCh := make(chan error, 1)
 
@@ -35,10 +78,12 @@ func (c *Client) Call(ctx context.Context, url string, payload map[string]interf
 		URL:         url,
 		Payload:     payload,
 		IsStreaming: false,
+		Preemptible: c.preemptible && c.priority == PriorityBackground,
 		ResponseCh:  respCh,
 		ErrorCh:     errCh,
 		SubmitTime:  time.Now(),
 		Timeout:     c.timeout,
+		TraceID:     tools.TraceID(ctx),
 	}
 
 	if err := c.manager.Submit(req); err != nil {
@@ -48,7 +93,7 @@ func (c *Client) Call(ctx context.Context, url string, payload map[string]interf
 	select {
 	case resp := <-respCh:
 		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("LLM returned status %d", resp.StatusCode)
+			return nil, statusError(resp.StatusCode, resp.Body)
 		}
 		return resp.Body, nil
 	case err := <-errCh:
@@ -60,36 +105,16 @@ func (c *Client) Call(ctx context.Context, url string, payload map[string]interf
 
 // CallStreaming submits a streaming request and returns the HTTP response
 func (c *Client) CallStreaming(ctx context.Context, url string, payload map[string]interface{}) (*http.Response, chan struct{}, error) {
-	respCh := make(chan *Response, 1)
-	errCh := make(chan error, 1)
-
-	req := &Request{
-		ID:          fmt.Sprintf("%d_stream_%d", c.priority, time.Now().UnixNano()),
-		Priority:    c.priority,
-		Context:     ctx,
-		URL:         url,
-		Payload:     payload,
-		IsStreaming: true,
-		ResponseCh:  respCh,
-		DoneCh: make(chan struct{}),
-		ErrorCh:     errCh,
-		SubmitTime:  time.Now(),
-		Timeout:     c.timeout,
+	if provider := c.manager.provider(url); provider != nil {
+		return nil, nil, fmt.Errorf("streaming is not supported for the %s provider", provider.Name())
 	}
 
-    if err := c.manager.Submit(req); err != nil {
-        return nil, nil, fmt.Errorf("failed to submit: %w", err)
-    }
-
-    select {
-    case resp := <-respCh:
-        if resp.StatusCode != http.StatusOK {
-            return nil, req.DoneCh, fmt.Errorf("LLM returned status %d", resp.StatusCode)
-        }
-        return resp.HTTPResp, req.DoneCh, nil
-    case err := <-errCh:
-        return nil, req.DoneCh, err
-    case <-ctx.Done():
-        return nil, req.DoneCh, ctx.Err()
-    }
+	req, resp, err := c.submitStream(ctx, url, payload, false)
+	if req == nil {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, req.DoneCh, err
+	}
+	return resp.HTTPResp, req.DoneCh, nil
 }
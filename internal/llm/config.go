@@ -14,6 +14,17 @@ type Config struct {
 	// Timeouts
 	CriticalTimeout   time.Duration // Shorter timeout for user requests
 	BackgroundTimeout time.Duration // Longer timeout for background
+
+	// Preemption: when a critical request is waiting and every slot is busy, cancel the
+	// newest preemptible background request that started less than PreemptWindow ago
+	// (likely still in prompt processing) and hand it back to its caller to resubmit
+	PreemptBackground bool
+	PreemptWindow     time.Duration
+
+	// Streamed completions are cut short once the last StreamRepeatWindow characters
+	// occur StreamRepeatLimit times, a sign the model is looping (0 turns the check off)
+	StreamRepeatWindow int
+	StreamRepeatLimit  int
 }
 
 // DefaultConfig returns sensible defaults
@@ -24,5 +35,8 @@ func DefaultConfig() *Config {
 		BackgroundQueueSize: 100,                // Large buffer
 		CriticalTimeout:     360 * time.Second,
 		BackgroundTimeout:   360 * time.Second,
+		PreemptWindow:       5 * time.Second,
+		StreamRepeatWindow:  defaultRepeatWindow,
+		StreamRepeatLimit:   8,
 	}
 }
@@ -0,0 +1,38 @@
+// internal/llm/health.go
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go-llama/internal/httpclient"
+)
+
+// PingModel checks that an OpenAI-compatible model server answers its /v1/models
+// endpoint. endpointURL may be a base URL or a full chat/completions URL.
+func PingModel(ctx context.Context, endpointURL string) error {
+	baseURL := endpointURL
+	for _, suffix := range []string{"/v1/chat/completions", "/v1/completions", "/v1/embeddings"} {
+		if strings.HasSuffix(baseURL, suffix) {
+			baseURL = strings.TrimSuffix(baseURL, suffix)
+			break
+		}
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v1/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := httpclient.New(0).Do(req)
+	if err != nil {
+		return fmt.Errorf("model server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("model server returned status %d", resp.StatusCode)
+	}
+	return nil
+}
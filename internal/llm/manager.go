@@ -11,6 +11,7 @@ import (
     "sync"
     "time"
 
+    "go-llama/internal/httpclient"
     "go-llama/internal/tools"
 )
 
@@ -24,8 +25,13 @@ type Manager struct {
 
     circuitBreaker *tools.CircuitBreaker
 
-    mu      sync.RWMutex
-    metrics Metrics
+    mu       sync.RWMutex
+    metrics  Metrics
+    inFlight map[*Request]*inFlightRequest
+    held     int // Background requests put back by the dispatcher for a critical one
+
+    providers map[string]Provider // Hosted providers by chat URL; their requests skip the queue
+    budget    *BudgetTracker      // Optional; records each call's tokens
 
     stopCh chan struct{}
     wg     sync.WaitGroup
@@ -33,6 +39,9 @@ type Manager struct {
     config *Config
 }
 
+// recentWaitSmoothing weights each start 1/N in a lane's recent wait average
+const recentWaitSmoothing = 5
+
 // NewManager creates a new queue manager
 func NewManager(config *Config, circuitBreaker *tools.CircuitBreaker) *Manager {
     m := &Manager{
@@ -47,18 +56,43 @@ func NewManager(config *Config, circuitBreaker *tools.CircuitBreaker) *Manager {
                 PriorityBackground: 0,
             },
         },
-        stopCh: make(chan struct{}),
-        config: config,
+        inFlight:  make(map[*Request]*inFlightRequest),
+        providers: make(map[string]Provider),
+        stopCh:    make(chan struct{}),
+        config:   config,
     }
 
     // Start dispatcher
     m.wg.Add(1)
     go m.dispatcher()
 
-    log.Printf("[LLM Queue] Started with %d concurrent slots", config.MaxConcurrent)
+    log.Printf("[LLM Queue] Started with %d concurrent slots (preempt background: %v)",
+        config.MaxConcurrent, config.PreemptBackground)
     return m
 }
 
+// inFlightRequest tracks a running request so it can be preempted
+type inFlightRequest struct {
+    started   time.Time
+    cancel    context.CancelFunc
+    preempted bool
+}
+
+// SetProvider sends clients' calls to chatURL straight to a hosted provider. They do
+// not wait for a queue slot, since the slots limit load on the local model server.
+func (m *Manager) SetProvider(chatURL string, provider Provider) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.providers[chatURL] = provider
+}
+
+// provider returns the hosted provider for url, or nil when it is served by the queue
+func (m *Manager) provider(url string) Provider {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return m.providers[url]
+}
+
 // Submit adds a request to the queue (non-blocking with drop behavior)
 func (m *Manager) Submit(req *Request) error {
     var queue chan *Request
@@ -106,48 +140,53 @@ func (m *Manager) Submit(req *Request) error {
 func (m *Manager) dispatcher() {
     defer m.wg.Done()
 
-    for {
-        // Step 1: Wait for a request to appear in either queue.
-        // We use a select to ensure we immediately service Critical if both are available.
-        var req *Request
-        var isCritical bool
+    // Background requests taken off the queue but put back for a critical request.
+    // They are served before the background queue so they keep their place.
+    var held []*Request
 
-        select {
-        case <-m.stopCh:
+    for {
+        // Step 1: Pick the next request, critical first.
+        req := m.nextRequest(&held)
+        if req == nil {
             return
-        case req = <-m.criticalQueue:
-            isCritical = true
-        case req = <-m.backgroundQueue:
-            isCritical = false
-            // PREEMPTION CHECK:
-            // Even though we pulled a background request, we must double-check if a Critical
-            // request arrived in the nanoseconds between the select above picking background
-            // and now. If so, we swap them.
-            select {
-            case critReq := <-m.criticalQueue:
-                // Put background back
-                m.backgroundQueue <- req
-                req = critReq
-                isCritical = true
-            default:
-                // No critical request, proceed with background
-            }
         }
 
         // Step 2: Wait for a processing slot (semaphore).
         // CRITICAL FIX: This select allows us to shut down even if we are blocked waiting for a slot.
-        select {
-        case <-m.stopCh:
-            // If we are stopping, we put the request back so it's not lost (optional, or just drop it).
-            // Given we are shutting down, dropping is usually acceptable, but putting back is cleaner.
-            if isCritical {
-                m.criticalQueue <- req
-            } else {
-                m.backgroundQueue <- req
+        // A background request has not started inference yet, so it steps aside for any
+        // critical request that arrives while it waits.
+        for acquired := false; !acquired; {
+            select {
+            case m.semaphore <- struct{}{}:
+                acquired = true
+                continue
+            default:
+            }
+
+            if req.Priority == PriorityCritical && m.config.PreemptBackground {
+                m.preemptBackground()
+            }
+
+            var critical chan *Request // nil blocks forever, so critical requests never yield
+            if req.Priority != PriorityCritical {
+                critical = m.criticalQueue
+            }
+
+            select {
+            case <-m.stopCh:
+                // Shutting down: waiting callers give up through their own contexts
+                return
+            case m.semaphore <- struct{}{}:
+                acquired = true
+            case critReq := <-critical:
+                held = append([]*Request{req}, held...)
+                m.mu.Lock()
+                m.held = len(held)
+                m.metrics.BackgroundYielded++
+                m.mu.Unlock()
+                log.Printf("[LLM Queue] Background request %s yielded to critical request %s", req.ID, critReq.ID)
+                req = critReq
             }
-            return
-        case m.semaphore <- struct{}{}:
-            // Slot acquired, proceed
         }
 
         // Step 3: Process the request
@@ -156,22 +195,148 @@ func (m *Manager) dispatcher() {
     }
 }
 
+// nextRequest returns the next request to dispatch, or nil when stopping
+func (m *Manager) nextRequest(held *[]*Request) *Request {
+    setHeld := func() {
+        m.mu.Lock()
+        m.held = len(*held)
+        m.mu.Unlock()
+    }
+
+    select {
+    case req := <-m.criticalQueue:
+        return req
+    default:
+    }
+    if len(*held) > 0 {
+        req := (*held)[0]
+        *held = (*held)[1:]
+        setHeld()
+        return req
+    }
+
+    select {
+    case <-m.stopCh:
+        return nil
+    case req := <-m.criticalQueue:
+        return req
+    case req := <-m.backgroundQueue:
+        // PREEMPTION CHECK:
+        // Even though we pulled a background request, we must double-check if a Critical
+        // request arrived in the nanoseconds between the select above picking background
+        // and now. If so, we swap them.
+        select {
+        case critReq := <-m.criticalQueue:
+            *held = append(*held, req)
+            setHeld()
+            return critReq
+        default:
+            return req
+        }
+    }
+}
+
+// preemptBackground cancels the newest preemptible background request that started within
+// the preemption window, freeing its slot for a waiting critical request
+func (m *Manager) preemptBackground() bool {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var victim *Request
+    var victimState *inFlightRequest
+    for req, state := range m.inFlight {
+        if !req.Preemptible || state.preempted || time.Since(state.started) > m.config.PreemptWindow {
+            continue
+        }
+        if victimState == nil || state.started.After(victimState.started) {
+            victim, victimState = req, state
+        }
+    }
+    if victim == nil {
+        return false
+    }
+
+    victimState.preempted = true
+    victimState.cancel()
+    m.metrics.BackgroundPreempted++
+    log.Printf("[LLM Queue] Preempted background request %s after %s for a critical request",
+        victim.ID, time.Since(victimState.started).Round(time.Millisecond))
+    return true
+}
+
+// startInFlight records a request as running and how long it waited for a slot
+func (m *Manager) startInFlight(req *Request, cancel context.CancelFunc) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    m.inFlight[req] = &inFlightRequest{started: time.Now(), cancel: cancel}
+    wait := time.Since(req.SubmitTime)
+    if req.Priority == PriorityCritical {
+        m.metrics.CriticalWait += wait
+        m.metrics.CriticalRecentWait = movingWait(m.metrics.CriticalRecentWait, wait)
+    } else {
+        m.metrics.BackgroundWait += wait
+        m.metrics.BackgroundRecentWait = movingWait(m.metrics.BackgroundRecentWait, wait)
+    }
+}
+
+// movingWait folds wait into an exponential moving average, so the latest few starts
+// dominate and an overload from hours ago no longer shows
+func movingWait(avg, wait time.Duration) time.Duration {
+    if avg == 0 {
+        return wait
+    }
+    return avg + (wait-avg)/recentWaitSmoothing
+}
+
+// queueStats reports what a request submitted now at priority would wait behind. Critical
+// requests are dispatched first, so background ones queue behind both lanes. With nothing
+// queued and a slot free a new request starts at once, so the recent wait reads zero.
+func (m *Manager) queueStats(priority Priority) QueueStats {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    stats := QueueStats{Depth: len(m.criticalQueue), InFlight: len(m.inFlight), RecentWait: m.metrics.CriticalRecentWait}
+    if priority != PriorityCritical {
+        stats.Depth += len(m.backgroundQueue) + m.held
+        stats.RecentWait = m.metrics.BackgroundRecentWait
+    }
+    if stats.Depth == 0 && stats.InFlight < m.maxConcurrent {
+        stats.RecentWait = 0
+    }
+    return stats
+}
+
+// wasPreempted reports whether a running request was cancelled by preemptBackground
+func (m *Manager) wasPreempted(req *Request) bool {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    state, ok := m.inFlight[req]
+    return ok && state.preempted
+}
+
 // processRequest executes the actual LLM call
 func (m *Manager) processRequest(req *Request) {
     defer func() {
-        <-m.semaphore // Release slot
-        m.wg.Done()
-
         m.mu.Lock()
+        delete(m.inFlight, req) // Before the slot is released, so it can't be preempted again
         if req.Priority == PriorityCritical {
             m.metrics.CriticalProcessed++
         } else {
             m.metrics.BackgroundProcessed++
         }
         m.mu.Unlock()
+
+        <-m.semaphore // Release slot
+        m.wg.Done()
     }()
 
     startTime := time.Now()
+    if trace := tools.TraceFromContext(req.Context); trace != nil {
+        trace.Record(tools.SpanLLMQueueWait, req.SubmitTime, startTime.Sub(req.SubmitTime))
+        defer func() { trace.Record(tools.SpanLLMRequest, startTime, time.Since(startTime)) }()
+    }
 
     // Check if context already cancelled
     if req.Context.Err() != nil {
@@ -181,6 +346,7 @@ func (m *Manager) processRequest(req *Request) {
 
     // Apply timeout
     ctx, cancel := context.WithTimeout(req.Context, req.Timeout)
+    m.startInFlight(req, cancel)
     
     // CRITICAL FIX: For streaming, don't cancel context until after response is sent
     if !req.IsStreaming {
@@ -193,8 +359,12 @@ func (m *Manager) processRequest(req *Request) {
         if req.IsStreaming {
             cancel() // Clean up on error
         }
-        log.Printf("[LLM Queue] Request %s failed after %s: %v",
-            req.ID, time.Since(startTime), err)
+        if m.wasPreempted(req) {
+            req.ErrorCh <- &preemptedError{id: req.ID}
+            return
+        }
+        log.Printf("[LLM Queue] Request %s (trace %s) failed after %s: %v",
+            req.ID, req.TraceID, time.Since(startTime), err)
         req.ErrorCh <- err
         return
     }
@@ -220,8 +390,8 @@ func (m *Manager) processRequest(req *Request) {
             }
         }
 
-        log.Printf("[LLM Queue] Request %s completed in %s",
-            req.ID, time.Since(startTime))
+        log.Printf("[LLM Queue] Request %s (trace %s) completed in %s, queued %s",
+            req.ID, req.TraceID, time.Since(startTime), startTime.Sub(req.SubmitTime))
 
     case <-ctx.Done():
         if req.IsStreaming {
@@ -252,18 +422,13 @@ func (m *Manager) executeHTTPRequest(ctx context.Context, req *Request) (*Respon
         return nil, fmt.Errorf("failed to create request: %w", err)
     }
     httpReq.Header.Set("Content-Type", "application/json")
-
-    // Execute with timeout
-    client := &http.Client{
-        Timeout: req.Timeout,
-        Transport: &http.Transport{
-            ResponseHeaderTimeout: req.Timeout,
-            IdleConnTimeout:       req.Timeout,
-            MaxIdleConns:          10,
-            DisableKeepAlives:     false,
-        },
+    if req.TraceID != "" {
+        httpReq.Header.Set(tools.TraceHeader, req.TraceID)
     }
 
+    // Execute with timeout over the shared pooled transport
+    client := httpclient.New(req.Timeout)
+
     httpResp, err := client.Do(httpReq)
     if err != nil {
         // Record failure in circuit breaker
@@ -306,11 +471,55 @@ func (m *Manager) GetMetrics() Metrics {
     defer m.mu.RUnlock()
 
     metrics := m.metrics
-    metrics.CurrentQueueDepth[PriorityCritical] = len(m.criticalQueue)
-    metrics.CurrentQueueDepth[PriorityBackground] = len(m.backgroundQueue)
+    metrics.CurrentQueueDepth = map[Priority]int{
+        PriorityCritical:   len(m.criticalQueue),
+        PriorityBackground: len(m.backgroundQueue) + m.held,
+    }
     return metrics
 }
 
+// QueueMetrics returns depth, throughput and wait time per priority lane
+func (m *Manager) QueueMetrics() QueueMetrics {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    qm := QueueMetrics{
+        MaxConcurrent:     m.maxConcurrent,
+        PreemptBackground: m.config.PreemptBackground,
+        Critical: LaneMetrics{
+            Queued:    len(m.criticalQueue),
+            Enqueued:  m.metrics.CriticalEnqueued,
+            Processed: m.metrics.CriticalProcessed,
+            Dropped:   m.metrics.CriticalDropped,
+        },
+        Background: LaneMetrics{
+            Queued:    len(m.backgroundQueue) + m.held,
+            Enqueued:  m.metrics.BackgroundEnqueued,
+            Processed: m.metrics.BackgroundProcessed,
+            Dropped:   m.metrics.BackgroundDropped,
+            Yielded:   m.metrics.BackgroundYielded,
+            Preempted: m.metrics.BackgroundPreempted,
+        },
+    }
+    for req := range m.inFlight {
+        if req.Priority == PriorityCritical {
+            qm.Critical.InFlight++
+        } else {
+            qm.Background.InFlight++
+        }
+    }
+    // Every processed request started once, plus those still running
+    if started := m.metrics.CriticalProcessed + int64(qm.Critical.InFlight); started > 0 {
+        qm.Critical.AvgWaitMs = float64(m.metrics.CriticalWait.Milliseconds()) / float64(started)
+    }
+    if started := m.metrics.BackgroundProcessed + int64(qm.Background.InFlight); started > 0 {
+        qm.Background.AvgWaitMs = float64(m.metrics.BackgroundWait.Milliseconds()) / float64(started)
+    }
+    qm.Critical.RecentWaitMs = float64(m.metrics.CriticalRecentWait.Milliseconds())
+    qm.Background.RecentWaitMs = float64(m.metrics.BackgroundRecentWait.Milliseconds())
+    return qm
+}
+
 // Stop gracefully shuts down the queue
 func (m *Manager) Stop() {
     close(m.stopCh)
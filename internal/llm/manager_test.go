@@ -0,0 +1,277 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go-llama/internal/tools"
+)
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// modelServer answers with the request's model name; models listed in block wait for
+// the channel to close or the request to be cancelled
+func modelServer(block map[string]chan struct{}) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var served []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if ch, ok := block[payload.Model]; ok {
+			select {
+			case <-ch:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		mu.Lock()
+		served = append(served, payload.Model)
+		mu.Unlock()
+		w.Write([]byte(`{"model":"` + payload.Model + `"}`))
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, served...)
+	}
+}
+
+func newTestManager(preempt bool) *Manager {
+	cfg := DefaultConfig()
+	cfg.MaxConcurrent = 1
+	cfg.CriticalTimeout = 5 * time.Second
+	cfg.BackgroundTimeout = 5 * time.Second
+	cfg.PreemptBackground = preempt
+	return NewManager(cfg, nil)
+}
+
+func TestWaitingBackgroundYieldsToCritical(t *testing.T) {
+	release := make(chan struct{})
+	srv, served := modelServer(map[string]chan struct{}{"first": release})
+	defer srv.Close()
+
+	m := newTestManager(false)
+	defer m.Stop()
+	background := NewClient(m, PriorityBackground, 5*time.Second)
+	critical := NewClient(m, PriorityCritical, 5*time.Second)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	call := func(c *Client, model string) {
+		defer wg.Done()
+		if _, err := c.Call(ctx, srv.URL, map[string]interface{}{"model": model}); err != nil {
+			t.Errorf("%s: %v", model, err)
+		}
+	}
+
+	wg.Add(1)
+	go call(background, "first")
+	waitFor(t, "first request to start", func() bool { return m.QueueMetrics().Background.InFlight == 1 })
+
+	// The dispatcher takes the second background request and waits for the slot
+	wg.Add(1)
+	go call(background, "second")
+	waitFor(t, "second request to be dequeued", func() bool {
+		return m.QueueMetrics().Background.Enqueued == 2 && len(m.backgroundQueue) == 0
+	})
+
+	wg.Add(1)
+	go call(critical, "chat")
+	waitFor(t, "background to yield", func() bool { return m.QueueMetrics().Background.Yielded == 1 })
+	if depth := m.QueueMetrics().Background.Queued; depth != 1 {
+		t.Errorf("expected the yielded request to count as queued, got %d", depth)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if got := served(); len(got) != 3 || got[1] != "chat" || got[2] != "second" {
+		t.Errorf("expected chat to be served before the waiting background request, got %v", got)
+	}
+}
+
+func TestPreemptBackground(t *testing.T) {
+	srv, served := modelServer(map[string]chan struct{}{"reflection": make(chan struct{})})
+	defer srv.Close()
+
+	m := newTestManager(true)
+	defer m.Stop()
+	background := NewClient(m, PriorityBackground, 5*time.Second)
+	background.SetPreemptible(true)
+	critical := NewClient(m, PriorityCritical, 5*time.Second)
+	ctx := context.Background()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := background.Call(ctx, srv.URL, map[string]interface{}{"model": "reflection"})
+		errCh <- err
+	}()
+	waitFor(t, "background request to start", func() bool { return m.QueueMetrics().Background.InFlight == 1 })
+
+	if _, err := critical.Call(ctx, srv.URL, map[string]interface{}{"model": "chat"}); err != nil {
+		t.Fatalf("critical call failed: %v", err)
+	}
+
+	err := <-errCh
+	if !errors.Is(err, ErrPreempted) {
+		t.Fatalf("expected ErrPreempted, got %v", err)
+	}
+	if p, ok := err.(interface{ Preempted() bool }); !ok || !p.Preempted() {
+		t.Error("expected the error to expose Preempted()")
+	}
+
+	metrics := m.QueueMetrics()
+	if metrics.Background.Preempted != 1 || metrics.Critical.Processed != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+	if got := served(); len(got) != 1 || got[0] != "chat" {
+		t.Errorf("expected only chat to be served, got %v", got)
+	}
+}
+
+func TestQueueStats(t *testing.T) {
+	release := make(chan struct{})
+	srv, _ := modelServer(map[string]chan struct{}{"chat": release})
+	defer srv.Close()
+
+	m := newTestManager(false)
+	defer m.Stop()
+	background := NewClient(m, PriorityBackground, 5*time.Second)
+	critical := NewClient(m, PriorityCritical, 5*time.Second)
+	ctx := context.Background()
+
+	if stats := background.QueueStats(); stats != (QueueStats{}) {
+		t.Fatalf("expected an idle queue, got %+v", stats)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		critical.Call(ctx, srv.URL, map[string]interface{}{"model": "chat"})
+	}()
+	waitFor(t, "chat request to start", func() bool { return m.QueueMetrics().Critical.InFlight == 1 })
+	for i := 0; i < 3; i++ {
+		go func() {
+			defer wg.Done()
+			background.Call(ctx, srv.URL, map[string]interface{}{"model": "reflection"})
+		}()
+	}
+	// The dispatcher holds one while it waits for the slot; the others stay queued
+	waitFor(t, "background requests to queue", func() bool {
+		return m.QueueMetrics().Background.Enqueued == 3 && len(m.backgroundQueue) == 2
+	})
+
+	if stats := background.QueueStats(); stats.Depth != 2 || stats.InFlight != 1 {
+		t.Errorf("expected background behind 2 queued and 1 running, got %+v", stats)
+	}
+	if stats := critical.QueueStats(); stats.Depth != 0 || stats.InFlight != 1 {
+		t.Errorf("expected critical ahead of the background queue, got %+v", stats)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if recent := m.QueueMetrics().Background.RecentWaitMs; recent < 20 {
+		t.Errorf("expected the background wait recorded, got %.0fms", recent)
+	}
+	if stats := background.QueueStats(); stats.RecentWait != 0 {
+		t.Errorf("expected no wait once the queue drained, got %s", stats.RecentWait)
+	}
+}
+
+func TestNonPreemptibleBackgroundIsNotCancelled(t *testing.T) {
+	release := make(chan struct{})
+	srv, _ := modelServer(map[string]chan struct{}{"tagging": release})
+	defer srv.Close()
+
+	m := newTestManager(true)
+	defer m.Stop()
+	background := NewClient(m, PriorityBackground, 5*time.Second)
+	ctx := context.Background()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := background.Call(ctx, srv.URL, map[string]interface{}{"model": "tagging"})
+		errCh <- err
+	}()
+	waitFor(t, "background request to start", func() bool { return m.QueueMetrics().Background.InFlight == 1 })
+
+	go NewClient(m, PriorityCritical, 5*time.Second).Call(ctx, srv.URL, map[string]interface{}{"model": "chat"})
+	waitFor(t, "critical request to be dispatched", func() bool { return m.QueueMetrics().Critical.Enqueued == 1 && len(m.criticalQueue) == 0 })
+	close(release)
+
+	if err := <-errCh; err != nil {
+		t.Errorf("expected the non-preemptible request to complete, got %v", err)
+	}
+	if m.QueueMetrics().Background.Preempted != 0 {
+		t.Error("expected no preemption")
+	}
+}
+
+func TestQueuedCallCarriesTrace(t *testing.T) {
+	var seen string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get(tools.TraceHeader)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	m := newTestManager(false)
+	defer m.Stop()
+
+	trace := tools.NewTrace()
+	client := NewClient(m, PriorityBackground, 5*time.Second)
+	if _, err := client.Call(tools.WithTrace(context.Background(), trace), srv.URL, map[string]interface{}{"model": "m"}); err != nil {
+		t.Fatal(err)
+	}
+	if seen != trace.ID {
+		t.Errorf("expected the model server to see trace %s, got %q", trace.ID, seen)
+	}
+	waitFor(t, "queue spans", func() bool { return len(trace.Spans()) == 2 })
+	if spans := trace.Spans(); spans[0].Name != tools.SpanLLMQueueWait || spans[1].Name != tools.SpanLLMRequest {
+		t.Errorf("expected queue wait then request spans, got %+v", spans)
+	}
+}
+
+func TestQueuedCallReportsContextOverflow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/overflow" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":400,"message":"the request exceeds the available context size, try increasing it","type":"exceed_context_size_error"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"invalid grammar"}}`))
+	}))
+	defer srv.Close()
+	m := newTestManager(false)
+	defer m.Stop()
+
+	client := NewClient(m, PriorityBackground, 5*time.Second)
+	_, err := client.Call(context.Background(), srv.URL+"/overflow", map[string]interface{}{"model": "m"})
+	if p, ok := err.(interface{ ContextOverflow() bool }); !errors.Is(err, ErrContextOverflow) || !ok || !p.ContextOverflow() {
+		t.Errorf("expected a context overflow error, got %v", err)
+	}
+	if _, err := client.Call(context.Background(), srv.URL+"/other", map[string]interface{}{"model": "m"}); err == nil || errors.Is(err, ErrContextOverflow) {
+		t.Errorf("expected a plain status error, got %v", err)
+	}
+}
@@ -0,0 +1,461 @@
+// internal/llm/provider.go
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go-llama/internal/httpclient"
+	"go-llama/internal/tools"
+	"go-llama/internal/utils"
+)
+
+// Provider names accepted in ProviderConfig.Name
+const (
+	ProviderOpenAICompatible = "openai-compatible" // Local llama.cpp-style server; normally served by the queue
+	ProviderOpenAI           = "openai"
+	ProviderAnthropic        = "anthropic"
+)
+
+// DefaultMaxOutputTokens caps max_tokens for hosted providers when no cap is configured.
+// Callers size max_tokens from the model's context, which hosted APIs reject as a
+// response limit.
+const DefaultMaxOutputTokens = 4096
+
+const (
+	defaultOpenAIURL    = "https://api.openai.com"
+	defaultAnthropicURL = "https://api.anthropic.com"
+	anthropicVersion    = "2023-06-01"
+)
+
+// Errors returned by providers for a response with an error status. Match them with
+// errors.Is; ProviderError carries the status and the provider's own message.
+var (
+	ErrProviderAuth        = errors.New("provider rejected the credentials")
+	ErrProviderRateLimited = errors.New("provider rate limit exceeded")
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	ErrProviderRequest     = errors.New("provider rejected the request")
+)
+
+// ProviderError reports an error response from a provider
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Type       string // Error type from the response body, e.g. "rate_limit_error"
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	msg := fmt.Sprintf("%s returned status %d", e.Provider, e.StatusCode)
+	if e.Type != "" {
+		msg += " (" + e.Type + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is matches ErrContextOverflow when the provider's message reports the prompt did
+// not fit; Unwrap still gives the status sentinel
+func (e *ProviderError) Is(target error) bool {
+	return target == ErrContextOverflow && e.ContextOverflow()
+}
+
+// ContextOverflow reports whether the request was rejected for not fitting in the
+// model's context window
+func (e *ProviderError) ContextOverflow() bool {
+	return isContextOverflowMessage(e.Type + " " + e.Message)
+}
+
+// Unwrap maps the status to one of the ErrProvider sentinels. 529 is Anthropic's
+// "overloaded" status.
+func (e *ProviderError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrProviderAuth
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrProviderRateLimited
+	case e.StatusCode >= 500:
+		return ErrProviderUnavailable
+	}
+	return ErrProviderRequest
+}
+
+// Provider sends chat completion requests to a model API. Payloads and responses use
+// the OpenAI chat completion shape callers already build and parse, so a provider
+// translates both ways and reports token usage as usage.total_tokens.
+type Provider interface {
+	Name() string
+	Complete(ctx context.Context, payload map[string]interface{}) ([]byte, error)
+	Ping(ctx context.Context) error
+}
+
+// ProviderConfig selects and authenticates a provider
+type ProviderConfig struct {
+	Name            string // One of the Provider constants; empty is openai-compatible
+	URL             string // Base URL; hosted providers default to their public API
+	APIKey          string
+	Organization    string // OpenAI only: sent as OpenAI-Organization
+	MaxOutputTokens int    // Hosted only: caps max_tokens (default DefaultMaxOutputTokens)
+}
+
+// NewProvider creates the provider named by cfg. A nil client uses the shared
+// transport with no timeout; callers bound each request with its context.
+func NewProvider(cfg ProviderConfig, client *http.Client) (Provider, error) {
+	if client == nil {
+		client = httpclient.New(0)
+	}
+	if cfg.MaxOutputTokens <= 0 {
+		cfg.MaxOutputTokens = DefaultMaxOutputTokens
+	}
+
+	switch cfg.Name {
+	case "", ProviderOpenAICompatible:
+		if cfg.URL == "" {
+			return nil, errors.New("openai-compatible provider needs a URL")
+		}
+		return &openAIProvider{name: ProviderOpenAICompatible, cfg: cfg, client: client, compatible: true}, nil
+	case ProviderOpenAI:
+		if cfg.APIKey == "" {
+			return nil, errors.New("openai provider needs an API key")
+		}
+		if cfg.URL == "" {
+			cfg.URL = defaultOpenAIURL
+		}
+		return &openAIProvider{name: ProviderOpenAI, cfg: cfg, client: client}, nil
+	case ProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, errors.New("anthropic provider needs an API key")
+		}
+		if cfg.URL == "" {
+			cfg.URL = defaultAnthropicURL
+		}
+		return &anthropicProvider{cfg: cfg, client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown provider %q", cfg.Name)
+}
+
+// baseURL strips any endpoint path from a configured URL, like PingModel does
+func baseURL(url string) string {
+	for _, suffix := range []string{"/v1/chat/completions", "/v1/completions", "/v1/messages"} {
+		if strings.HasSuffix(url, suffix) {
+			url = strings.TrimSuffix(url, suffix)
+			break
+		}
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// chatCompletion is the OpenAI chat completion response every provider returns
+type chatCompletion struct {
+	ID      string       `json:"id,omitempty"`
+	Object  string       `json:"object"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   chatUsage    `json:"usage"`
+}
+
+type chatChoice struct {
+	Index        int         `json:"index"`
+	Message      chatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// doJSON posts body to url and returns the response body, or a ProviderError for an
+// error status
+func doJSON(ctx context.Context, client *http.Client, provider, method, url string, body interface{}, header http.Header) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	tools.SetTraceHeader(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, providerError(provider, resp.StatusCode, data)
+	}
+	return data, nil
+}
+
+// providerError builds a ProviderError from an error body. OpenAI and Anthropic both
+// nest the details under "error" with "type" and "message".
+func providerError(provider string, status int, body []byte) *ProviderError {
+	perr := &ProviderError{Provider: provider, StatusCode: status}
+	var parsed struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error.Message != "" {
+		perr.Type = parsed.Error.Type
+		perr.Message = parsed.Error.Message
+	} else if text := strings.TrimSpace(string(body)); text != "" {
+		perr.Message = utils.Prefix(text, 200)
+	}
+	return perr
+}
+
+// intValue reads a numeric payload value, which callers set as int or float64
+func intValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// outputTokens returns the payload's max_tokens capped at max, or max when unset
+func outputTokens(payload map[string]interface{}, max int) int {
+	if n, ok := intValue(payload["max_tokens"]); ok && n > 0 && n < max {
+		return n
+	}
+	return max
+}
+
+// localOnlyParams are llama.cpp sampling fields hosted APIs reject
+var localOnlyParams = []string{"repeat_penalty", "top_k", "min_p", "n_predict", "cache_prompt"}
+
+// openAIProvider calls the OpenAI chat completions API, or any compatible server
+type openAIProvider struct {
+	name       string
+	cfg        ProviderConfig
+	client     *http.Client
+	compatible bool // Pass the payload through untouched and authenticate only if a key is set
+}
+
+func (p *openAIProvider) Name() string { return p.name }
+
+func (p *openAIProvider) header() http.Header {
+	header := http.Header{}
+	if p.cfg.APIKey != "" {
+		header.Set("Authorization", "Bearer "+p.cfg.APIKey)
+	}
+	if p.cfg.Organization != "" && !p.compatible {
+		header.Set("OpenAI-Organization", p.cfg.Organization)
+	}
+	return header
+}
+
+// request maps a payload for the hosted API: max_tokens becomes max_completion_tokens
+// within the output cap, and local-only sampling fields are dropped
+func (p *openAIProvider) request(payload map[string]interface{}) map[string]interface{} {
+	if p.compatible {
+		return payload
+	}
+	req := make(map[string]interface{}, len(payload))
+	for key, value := range payload {
+		req[key] = value
+	}
+	for _, key := range localOnlyParams {
+		delete(req, key)
+	}
+	delete(req, "max_tokens")
+	req["max_completion_tokens"] = outputTokens(payload, p.cfg.MaxOutputTokens)
+	req["stream"] = false
+	return req
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, payload map[string]interface{}) ([]byte, error) {
+	url := baseURL(p.cfg.URL) + "/v1/chat/completions"
+	body, err := doJSON(ctx, p.client, p.name, http.MethodPost, url, p.request(payload), p.header())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp chatCompletion
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", p.name, err)
+	}
+	if resp.Usage.TotalTokens == 0 {
+		resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+	}
+	return json.Marshal(resp)
+}
+
+func (p *openAIProvider) Ping(ctx context.Context) error {
+	_, err := doJSON(ctx, p.client, p.name, http.MethodGet, baseURL(p.cfg.URL)+"/v1/models", nil, p.header())
+	return err
+}
+
+// anthropicProvider calls the Anthropic messages API
+type anthropicProvider struct {
+	cfg    ProviderConfig
+	client *http.Client
+}
+
+func (p *anthropicProvider) Name() string { return ProviderAnthropic }
+
+func (p *anthropicProvider) header() http.Header {
+	header := http.Header{}
+	header.Set("x-api-key", p.cfg.APIKey)
+	header.Set("anthropic-version", anthropicVersion)
+	return header
+}
+
+// anthropicStopReasons maps stop_reason to the OpenAI finish_reason callers expect
+var anthropicStopReasons = map[string]string{
+	"end_turn":      "stop",
+	"stop_sequence": "stop",
+	"max_tokens":    "length",
+	"tool_use":      "tool_calls",
+	"refusal":       "content_filter",
+}
+
+// request maps a chat completion payload to a messages request. System messages move
+// to the system field, consecutive messages from one role are merged, temperature is
+// clamped to Anthropic's 0-1 range and stop becomes stop_sequences.
+func (p *anthropicProvider) request(payload map[string]interface{}) (map[string]interface{}, error) {
+	var messages []chatMessage
+	raw, err := json.Marshal(payload["messages"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	if err := json.Unmarshal(raw, &messages); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+
+	system := []string{}
+	turns := []chatMessage{}
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			system = append(system, msg.Content)
+			continue
+		}
+		if n := len(turns); n > 0 && turns[n-1].Role == msg.Role {
+			turns[n-1].Content += "\n\n" + msg.Content
+			continue
+		}
+		turns = append(turns, msg)
+	}
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("%w: anthropic needs at least one user message", ErrProviderRequest)
+	}
+
+	req := map[string]interface{}{
+		"model":      payload["model"],
+		"messages":   turns,
+		"max_tokens": outputTokens(payload, p.cfg.MaxOutputTokens),
+	}
+	if len(system) > 0 {
+		req["system"] = strings.Join(system, "\n\n")
+	}
+	if t, ok := payload["temperature"].(float64); ok {
+		if t > 1 {
+			t = 1
+		}
+		req["temperature"] = t
+	}
+	if topP, ok := payload["top_p"]; ok {
+		req["top_p"] = topP
+	}
+	switch stop := payload["stop"].(type) {
+	case string:
+		req["stop_sequences"] = []string{stop}
+	case []string, []interface{}:
+		req["stop_sequences"] = stop
+	}
+	return req, nil
+}
+
+func (p *anthropicProvider) Complete(ctx context.Context, payload map[string]interface{}) ([]byte, error) {
+	req, err := p.request(payload)
+	if err != nil {
+		return nil, err
+	}
+	body, err := doJSON(ctx, p.client, ProviderAnthropic, http.MethodPost, baseURL(p.cfg.URL)+"/v1/messages", req, p.header())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		ID      string `json:"id"`
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse anthropic response: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	finish, ok := anthropicStopReasons[resp.StopReason]
+	if !ok {
+		finish = resp.StopReason
+	}
+	return json.Marshal(chatCompletion{
+		ID:     resp.ID,
+		Object: "chat.completion",
+		Model:  resp.Model,
+		Choices: []chatChoice{{
+			Message:      chatMessage{Role: "assistant", Content: text.String()},
+			FinishReason: finish,
+		}},
+		Usage: chatUsage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	})
+}
+
+func (p *anthropicProvider) Ping(ctx context.Context) error {
+	_, err := doJSON(ctx, p.client, ProviderAnthropic, http.MethodGet, baseURL(p.cfg.URL)+"/v1/models", nil, p.header())
+	return err
+}
@@ -0,0 +1,238 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeProvider records one request and answers with a fixed status and body
+type fakeProvider struct {
+	status  int
+	body    string
+	header  http.Header
+	path    string
+	request map[string]interface{}
+}
+
+func (f *fakeProvider) server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.header = r.Header.Clone()
+		f.path = r.URL.Path
+		f.request = nil
+		json.NewDecoder(r.Body).Decode(&f.request)
+		w.WriteHeader(f.status)
+		w.Write([]byte(f.body))
+	}))
+}
+
+// completion parses a normalized response into the shape callers read
+func completion(t *testing.T, body []byte) chatCompletion {
+	t.Helper()
+	var resp chatCompletion
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("expected an OpenAI-shaped response: %v", err)
+	}
+	if len(resp.Choices) != 1 {
+		t.Fatalf("expected one choice, got %s", body)
+	}
+	return resp
+}
+
+func testPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"model": "test-model",
+		"messages": []map[string]string{
+			{"role": "system", "content": "You are terse."},
+			{"role": "user", "content": "Hello"},
+		},
+		"temperature":    1.4,
+		"max_tokens":     131072,
+		"repeat_penalty": 1.1,
+		"stop":           "END",
+	}
+}
+
+func TestOpenAIProviderAuthAndRequestMapping(t *testing.T) {
+	fake := &fakeProvider{status: http.StatusOK, body: `{"id":"c1","model":"gpt-4o","choices":[{"index":0,
+		"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],
+		"usage":{"prompt_tokens":12,"completion_tokens":3}}`}
+	srv := fake.server()
+	defer srv.Close()
+
+	p, err := NewProvider(ProviderConfig{Name: ProviderOpenAI, URL: srv.URL, APIKey: "sk-test", Organization: "org-1", MaxOutputTokens: 2048}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := p.Complete(context.Background(), testPayload())
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	if fake.path != "/v1/chat/completions" || fake.header.Get("Authorization") != "Bearer sk-test" || fake.header.Get("OpenAI-Organization") != "org-1" {
+		t.Errorf("unexpected request %s with headers %v", fake.path, fake.header)
+	}
+	if _, ok := fake.request["repeat_penalty"]; ok {
+		t.Error("expected local-only sampling fields dropped")
+	}
+	if _, ok := fake.request["max_tokens"]; ok || fake.request["max_completion_tokens"] != 2048.0 {
+		t.Errorf("expected max_tokens capped and sent as max_completion_tokens, got %v", fake.request)
+	}
+
+	resp := completion(t, body)
+	if resp.Choices[0].Message.Content != "Hi" || resp.Usage.TotalTokens != 15 {
+		t.Errorf("expected content and total tokens normalized, got %+v", resp)
+	}
+}
+
+func TestAnthropicProviderAuthAndNormalization(t *testing.T) {
+	fake := &fakeProvider{status: http.StatusOK, body: `{"id":"msg_1","model":"claude-test","type":"message",
+		"content":[{"type":"text","text":"Hello "},{"type":"text","text":"there"}],
+		"stop_reason":"max_tokens","usage":{"input_tokens":20,"output_tokens":7}}`}
+	srv := fake.server()
+	defer srv.Close()
+
+	p, err := NewProvider(ProviderConfig{Name: ProviderAnthropic, URL: srv.URL + "/v1/chat/completions", APIKey: "sk-ant"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := p.Complete(context.Background(), testPayload())
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	if fake.path != "/v1/messages" || fake.header.Get("x-api-key") != "sk-ant" || fake.header.Get("anthropic-version") != anthropicVersion {
+		t.Errorf("unexpected request %s with headers %v", fake.path, fake.header)
+	}
+	if fake.header.Get("Authorization") != "" {
+		t.Error("expected no bearer token for anthropic")
+	}
+	if fake.request["system"] != "You are terse." || len(fake.request["messages"].([]interface{})) != 1 {
+		t.Errorf("expected the system prompt moved out of messages, got %v", fake.request)
+	}
+	if fake.request["max_tokens"] != float64(DefaultMaxOutputTokens) || fake.request["temperature"] != 1.0 {
+		t.Errorf("expected max_tokens capped and temperature clamped, got %v", fake.request)
+	}
+	if stop, _ := fake.request["stop_sequences"].([]interface{}); len(stop) != 1 || stop[0] != "END" {
+		t.Errorf("expected stop mapped to stop_sequences, got %v", fake.request["stop_sequences"])
+	}
+
+	resp := completion(t, body)
+	if resp.Choices[0].Message.Content != "Hello there" || resp.Choices[0].FinishReason != "length" {
+		t.Errorf("expected joined text and a length finish reason, got %+v", resp.Choices[0])
+	}
+	if resp.Usage.PromptTokens != 20 || resp.Usage.CompletionTokens != 7 || resp.Usage.TotalTokens != 27 {
+		t.Errorf("expected usage mapped from input and output tokens, got %+v", resp.Usage)
+	}
+}
+
+func TestOpenAICompatibleProviderPassesPayloadThrough(t *testing.T) {
+	fake := &fakeProvider{status: http.StatusOK, body: `{"choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"total_tokens":5}}`}
+	srv := fake.server()
+	defer srv.Close()
+
+	p, err := NewProvider(ProviderConfig{URL: srv.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Complete(context.Background(), testPayload()); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if fake.header.Get("Authorization") != "" || fake.request["repeat_penalty"] != 1.1 || fake.request["max_tokens"] != 131072.0 {
+		t.Errorf("expected the payload passed through without auth, got %v %v", fake.header, fake.request)
+	}
+}
+
+func TestProviderErrorMapping(t *testing.T) {
+	for _, tc := range []struct {
+		provider string
+		status   int
+		body     string
+		want     error
+		wantType string
+	}{
+		{ProviderOpenAI, 401, `{"error":{"message":"Incorrect API key","type":"invalid_request_error"}}`, ErrProviderAuth, "invalid_request_error"},
+		{ProviderOpenAI, 429, `{"error":{"message":"Rate limit reached","type":"requests"}}`, ErrProviderRateLimited, "requests"},
+		{ProviderOpenAI, 400, `{"error":{"message":"bad model","type":"invalid_request_error"}}`, ErrProviderRequest, "invalid_request_error"},
+		{ProviderAnthropic, 403, `{"type":"error","error":{"type":"permission_error","message":"denied"}}`, ErrProviderAuth, "permission_error"},
+		{ProviderAnthropic, 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, ErrProviderUnavailable, "overloaded_error"},
+		{ProviderAnthropic, 502, `<html>bad gateway</html>`, ErrProviderUnavailable, ""},
+	} {
+		fake := &fakeProvider{status: tc.status, body: tc.body}
+		srv := fake.server()
+		p, err := NewProvider(ProviderConfig{Name: tc.provider, URL: srv.URL, APIKey: "k"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = p.Complete(context.Background(), testPayload())
+		srv.Close()
+
+		var perr *ProviderError
+		if !errors.Is(err, tc.want) || !errors.As(err, &perr) {
+			t.Errorf("%s %d: expected %v, got %v", tc.provider, tc.status, tc.want, err)
+			continue
+		}
+		if perr.Provider != tc.provider || perr.StatusCode != tc.status || perr.Type != tc.wantType || perr.Message == "" {
+			t.Errorf("%s %d: unexpected error details %+v", tc.provider, tc.status, perr)
+		}
+	}
+}
+
+func TestProviderErrorContextOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		provider string
+		body     string
+		want     bool
+	}{
+		{ProviderOpenAI, `{"error":{"message":"This model's maximum context length is 8192 tokens","type":"invalid_request_error","code":"context_length_exceeded"}}`, true},
+		{ProviderAnthropic, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, true},
+		{ProviderOpenAI, `{"error":{"message":"bad model","type":"invalid_request_error"}}`, false},
+	} {
+		err := providerError(tc.provider, http.StatusBadRequest, []byte(tc.body))
+		if got := errors.Is(err, ErrContextOverflow); got != tc.want {
+			t.Errorf("%s: expected overflow=%v, got %v for %v", tc.provider, tc.want, got, err)
+		}
+		if !errors.Is(err, ErrProviderRequest) {
+			t.Errorf("%s: expected the status sentinel to still match, got %v", tc.provider, err)
+		}
+	}
+}
+
+func TestHostedCallsBypassQueue(t *testing.T) {
+	fake := &fakeProvider{status: http.StatusOK, body: `{"content":[{"type":"text","text":"hosted"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`}
+	srv := fake.server()
+	defer srv.Close()
+	local, _ := modelServer(nil)
+	defer local.Close()
+
+	m := newTestManager(false)
+	defer m.Stop()
+	provider, err := NewProvider(ProviderConfig{Name: ProviderAnthropic, URL: srv.URL, APIKey: "k"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostedURL := srv.URL + "/v1/chat/completions"
+	m.SetProvider(hostedURL, provider)
+
+	client := NewClient(m, PriorityBackground, 5*time.Second)
+	body, err := client.Call(context.Background(), hostedURL, testPayload())
+	if err != nil {
+		t.Fatalf("hosted call: %v", err)
+	}
+	if resp := completion(t, body); resp.Choices[0].Message.Content != "hosted" || resp.Choices[0].FinishReason != "stop" {
+		t.Errorf("unexpected hosted response %+v", resp)
+	}
+	if _, _, err := client.CallStreaming(context.Background(), hostedURL, testPayload()); err == nil {
+		t.Error("expected streaming to a hosted provider to be refused")
+	}
+	if _, err := client.Call(context.Background(), local.URL, map[string]interface{}{"model": "local"}); err != nil {
+		t.Fatalf("local call: %v", err)
+	}
+	if metrics := m.GetMetrics(); metrics.BackgroundEnqueued != 1 {
+		t.Errorf("expected only the local call queued, got %d", metrics.BackgroundEnqueued)
+	}
+}
@@ -0,0 +1,301 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-llama/internal/tools"
+)
+
+// Finish reasons CallStream reports for a completion it cut short
+const (
+	FinishLength     = "length"     // Reached the payload's max_tokens
+	FinishRepetition = "repetition" // The output started looping on the same text
+	FinishDeadline   = "deadline"   // The request timed out mid-stream
+)
+
+// defaultRepeatWindow is the repetition window used when the config leaves it unset
+const defaultRepeatWindow = 40
+
+// streamedCompletion is the non-streaming chat completion CallStream assembles from the
+// streamed chunks, plus whether the content was cut short
+type streamedCompletion struct {
+	Model   string           `json:"model,omitempty"`
+	Choices []streamedChoice `json:"choices"`
+	Usage   streamUsage      `json:"usage"`
+	// Truncated is set when the content is partial; FinishReason says why
+	Truncated bool `json:"truncated"`
+}
+
+type streamedChoice struct {
+	Index   int `json:"index"`
+	Message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"message"`
+	FinishReason string `json:"finish_reason"`
+}
+
+type streamUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// streamChunk is one server-sent event of a streamed chat completion
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *streamUsage `json:"usage"`
+}
+
+// CallStream submits a streaming request and assembles the streamed tokens into a
+// non-streaming chat completion body, so callers parse it as they would Call's. The
+// stream is cut short at the payload's max_tokens, once the output repeats the same
+// window of text StreamRepeatLimit times, or when the request times out; the partial
+// content is then returned with "truncated": true and the finish reason saying why.
+// Hosted providers do not stream, so calls to them go through Call.
+func (c *Client) CallStream(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	if c.manager.provider(url) != nil {
+		return c.Call(ctx, url, payload)
+	}
+
+	streamPayload := make(map[string]interface{}, len(payload)+2)
+	for k, v := range payload {
+		streamPayload[k] = v
+	}
+	streamPayload["stream"] = true
+	streamPayload["stream_options"] = map[string]interface{}{"include_usage": true}
+
+	req, resp, err := c.submitStream(ctx, url, streamPayload, c.preemptible && c.priority == PriorityBackground)
+	if req == nil {
+		return nil, err
+	}
+	defer close(req.DoneCh)
+	if resp != nil {
+		if resp.CancelFunc != nil {
+			defer resp.CancelFunc()
+		}
+		defer resp.HTTPResp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	acc := newStreamAccumulator(payload, c.manager.config)
+	startTime := time.Now()
+	scanner := bufio.NewScanner(resp.HTTPResp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" || acc.add([]byte(data)) {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil && !acc.stopped() {
+		if c.manager.wasPreempted(req) {
+			return nil, &preemptedError{id: req.ID}
+		}
+		var timeout interface{ Timeout() bool }
+		if acc.content.Len() == 0 || !errors.As(err, &timeout) || !timeout.Timeout() {
+			return nil, fmt.Errorf("failed to read stream: %w", err)
+		}
+		acc.cut(FinishDeadline)
+	}
+
+	completion := acc.completion()
+	if completion.Truncated {
+		log.Printf("[LLM Queue] Stream %s cut short (%s) after %d tokens in %s",
+			req.ID, completion.Choices[0].FinishReason, completion.Usage.CompletionTokens, time.Since(startTime))
+	}
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode streamed completion: %w", err)
+	}
+	c.manager.recordUsage(ProviderOpenAICompatible, payload, body)
+	return body, nil
+}
+
+// submitStream queues a streaming request. The returned request is nil if it could not
+// be submitted; otherwise the caller must close its DoneCh to release the queue slot.
+func (c *Client) submitStream(ctx context.Context, url string, payload map[string]interface{}, preemptible bool) (*Request, *Response, error) {
+	respCh := make(chan *Response, 1)
+	errCh := make(chan error, 1)
+
+	req := &Request{
+		ID:          fmt.Sprintf("%d_stream_%d", c.priority, time.Now().UnixNano()),
+		Priority:    c.priority,
+		Context:     ctx,
+		URL:         url,
+		Payload:     payload,
+		IsStreaming: true,
+		Preemptible: preemptible,
+		ResponseCh:  respCh,
+		DoneCh:      make(chan struct{}),
+		ErrorCh:     errCh,
+		SubmitTime:  time.Now(),
+		Timeout:     c.timeout,
+		TraceID:     tools.TraceID(ctx),
+	}
+
+	if err := c.manager.Submit(req); err != nil {
+		return nil, nil, fmt.Errorf("failed to submit: %w", err)
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(resp.HTTPResp.Body, 4096))
+			return req, resp, statusError(resp.StatusCode, body)
+		}
+		return req, resp, nil
+	case err := <-errCh:
+		return req, nil, err
+	case <-ctx.Done():
+		return req, nil, ctx.Err()
+	}
+}
+
+// streamAccumulator collects streamed content and decides when to stop reading
+type streamAccumulator struct {
+	model        string
+	promptChars  int
+	maxTokens    int
+	repeatWindow int
+	repeatLimit  int
+
+	content      strings.Builder
+	tokens       int // Content chunks received; llama.cpp sends one token per chunk
+	checkedAt    int // Content length at the last repetition check
+	usage        *streamUsage
+	finishReason string
+	truncated    bool
+}
+
+func newStreamAccumulator(payload map[string]interface{}, cfg *Config) *streamAccumulator {
+	acc := &streamAccumulator{repeatWindow: defaultRepeatWindow}
+	acc.model, _ = payload["model"].(string)
+	switch v := payload["max_tokens"].(type) {
+	case int:
+		acc.maxTokens = v
+	case float64:
+		acc.maxTokens = int(v)
+	}
+	if messages, ok := payload["messages"].([]map[string]string); ok {
+		for _, m := range messages {
+			acc.promptChars += len(m["content"])
+		}
+	}
+	if cfg != nil {
+		if cfg.StreamRepeatWindow > 0 {
+			acc.repeatWindow = cfg.StreamRepeatWindow
+		}
+		acc.repeatLimit = cfg.StreamRepeatLimit
+	}
+	return acc
+}
+
+// add takes one event's data and reports whether reading should stop
+func (a *streamAccumulator) add(data []byte) bool {
+	var chunk streamChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return false
+	}
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+	for _, choice := range chunk.Choices {
+		if choice.Delta.Content != "" {
+			a.content.WriteString(choice.Delta.Content)
+			a.tokens++
+		}
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			a.finishReason = *choice.FinishReason
+			// The server stopping at max_tokens cut the content short just the same
+			a.truncated = a.finishReason == FinishLength
+		}
+	}
+
+	if a.maxTokens > 0 && a.tokens >= a.maxTokens {
+		a.cut(FinishLength)
+		return true
+	}
+	if a.repeating() {
+		a.cut(FinishRepetition)
+		return true
+	}
+	return false
+}
+
+// repeating reports whether the latest window of content already occurs repeatLimit
+// times. It is checked every half window, which is enough to catch a loop promptly. On
+// a match the content is cut back to the end of the window's first occurrence.
+func (a *streamAccumulator) repeating() bool {
+	if a.repeatLimit <= 1 {
+		return false
+	}
+	text := a.content.String()
+	if len(text)-a.checkedAt < a.repeatWindow/2 || len(text) < a.repeatWindow*a.repeatLimit {
+		return false
+	}
+	a.checkedAt = len(text)
+
+	window := text[len(text)-a.repeatWindow:]
+	if strings.TrimSpace(window) == "" || strings.Count(text, window) < a.repeatLimit {
+		return false
+	}
+	kept := text[:strings.Index(text, window)+len(window)]
+	a.content.Reset()
+	a.content.WriteString(kept)
+	return true
+}
+
+// cut marks the content as cut short for reason
+func (a *streamAccumulator) cut(reason string) {
+	a.finishReason = reason
+	a.truncated = true
+}
+
+// stopped reports whether reading stopped on purpose rather than on an error
+func (a *streamAccumulator) stopped() bool {
+	return a.truncated && a.finishReason != FinishDeadline
+}
+
+// completion assembles the content read so far. Without the usage the server reports
+// at the end of the stream, prompt tokens are estimated at four characters each.
+func (a *streamAccumulator) completion() streamedCompletion {
+	choice := streamedChoice{FinishReason: a.finishReason}
+	choice.Message.Role = "assistant"
+	choice.Message.Content = a.content.String()
+	if choice.FinishReason == "" {
+		choice.FinishReason = "stop"
+	}
+
+	usage := streamUsage{PromptTokens: a.promptChars / 4, CompletionTokens: a.tokens}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	if a.usage != nil && a.usage.TotalTokens > 0 {
+		usage = *a.usage
+	}
+	return streamedCompletion{
+		Model:     a.model,
+		Choices:   []streamedChoice{choice},
+		Usage:     usage,
+		Truncated: a.truncated,
+	}
+}
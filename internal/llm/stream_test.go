@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamServer sends each token as a server-sent event, then the usage and [DONE]. With
+// loop set it repeats the tokens until the client disconnects; with hang set it stops
+// sending after the tokens and waits for the client to give up.
+func streamServer(tokens []string, loop, hang bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["stream"] != true {
+			http.Error(w, "expected a streaming request", http.StatusBadRequest)
+			return
+		}
+		flusher := w.(http.Flusher)
+		send := func(data string) bool {
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return false
+			}
+			flusher.Flush()
+			return r.Context().Err() == nil
+		}
+		delta := func(content string) string {
+			b, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": content}}},
+			})
+			return string(b)
+		}
+
+		for {
+			for _, tok := range tokens {
+				if !send(delta(tok)) {
+					return
+				}
+			}
+			if !loop {
+				break
+			}
+		}
+		if hang {
+			<-r.Context().Done()
+			return
+		}
+		send(`{"choices":[{"delta":{},"finish_reason":"stop"}]}`)
+		send(`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`)
+		send("[DONE]")
+	}))
+}
+
+func streamPayload(maxTokens int) map[string]interface{} {
+	return map[string]interface{}{
+		"model":      "8b",
+		"messages":   []map[string]string{{"role": "user", "content": "Synthesize the findings."}},
+		"max_tokens": maxTokens,
+	}
+}
+
+func decodeStreamed(t *testing.T, body []byte) streamedCompletion {
+	t.Helper()
+	var c streamedCompletion
+	if err := json.Unmarshal(body, &c); err != nil || len(c.Choices) != 1 {
+		t.Fatalf("expected one assembled choice, got %s (%v)", body, err)
+	}
+	return c
+}
+
+func TestCallStreamAssemblesCompletion(t *testing.T) {
+	srv := streamServer([]string{"The ", "answer ", "is 42."}, false, false)
+	defer srv.Close()
+	m := newTestManager(false)
+	defer m.Stop()
+
+	body, err := NewClient(m, PriorityBackground, 5*time.Second).CallStream(context.Background(), srv.URL, streamPayload(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := decodeStreamed(t, body)
+	if c.Choices[0].Message.Content != "The answer is 42." || c.Truncated || c.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected the full untruncated content, got %+v", c)
+	}
+	if c.Usage.TotalTokens != 15 {
+		t.Errorf("expected the server's usage, got %+v", c.Usage)
+	}
+}
+
+func TestCallStreamStopsAtMaxTokens(t *testing.T) {
+	srv := streamServer([]string{"one ", "two ", "three ", "four ", "five ", "six "}, false, false)
+	defer srv.Close()
+	m := newTestManager(false)
+	defer m.Stop()
+
+	body, err := NewClient(m, PriorityBackground, 5*time.Second).CallStream(context.Background(), srv.URL, streamPayload(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := decodeStreamed(t, body)
+	if c.Choices[0].Message.Content != "one two three four " || !c.Truncated || c.Choices[0].FinishReason != FinishLength {
+		t.Errorf("expected four tokens cut at max_tokens, got %+v", c)
+	}
+	// No usage arrives from a cut stream, so it is estimated
+	if c.Usage.CompletionTokens != 4 || c.Usage.PromptTokens != len("Synthesize the findings.")/4 {
+		t.Errorf("expected estimated usage, got %+v", c.Usage)
+	}
+}
+
+func TestCallStreamAbortsRepetitionLoop(t *testing.T) {
+	srv := streamServer([]string{"Intro. ", "The same sentence keeps coming back again. "}, true, false)
+	defer srv.Close()
+	m := newTestManager(false)
+	defer m.Stop()
+
+	body, err := NewClient(m, PriorityBackground, 5*time.Second).CallStream(context.Background(), srv.URL, streamPayload(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := decodeStreamed(t, body)
+	content := c.Choices[0].Message.Content
+	if !c.Truncated || c.Choices[0].FinishReason != FinishRepetition {
+		t.Fatalf("expected the loop to be cut as repetition, got %+v", c)
+	}
+	if !strings.HasPrefix(content, "Intro. The same sentence") || len(content) > 200 {
+		t.Errorf("expected the content cut back near the start of the loop, got %d chars: %q", len(content), content)
+	}
+}
+
+func TestCallStreamKeepsPartialContentAtDeadline(t *testing.T) {
+	srv := streamServer([]string{"A partial ", "answer."}, false, true)
+	defer srv.Close()
+	m := newTestManager(false)
+	defer m.Stop()
+
+	body, err := NewClient(m, PriorityBackground, 300*time.Millisecond).CallStream(context.Background(), srv.URL, streamPayload(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := decodeStreamed(t, body)
+	if c.Choices[0].Message.Content != "A partial answer." || !c.Truncated || c.Choices[0].FinishReason != FinishDeadline {
+		t.Errorf("expected the partial content flagged at the deadline, got %+v", c)
+	}
+}
+
+func TestRepetitionCheckIgnoresWhitespace(t *testing.T) {
+	acc := newStreamAccumulator(streamPayload(0), &Config{StreamRepeatWindow: 4, StreamRepeatLimit: 3})
+	for i := 0; i < 20; i++ {
+		if acc.add([]byte(`{"choices":[{"delta":{"content":"    "}}]}`)) {
+			t.Fatalf("expected whitespace padding not to count as a loop, stopped after %d chunks", i+1)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -25,6 +28,8 @@ type Request struct {
 	Payload     map[string]interface{}
 	IsStreaming bool
 	DoneCh		chan struct{}
+	Preemptible bool // Background only: may be cancelled to free a slot for a critical request
+	TraceID     string // Trace of the action the call was made for, sent as X-Trace-Id; may be empty
 
 	// Response handling
 	ResponseCh chan<- *Response
@@ -42,13 +47,103 @@ type Response struct {
 	CancelFunc context.CancelFunc // For streaming: allows caller to clean up context
 }
 
+// ErrPreempted is returned for a background request that was cancelled so a critical
+// request could use its slot. Nothing was produced; the caller should resubmit.
+var ErrPreempted = errors.New("preempted by a critical request")
+
+// preemptedError marks preemption for callers that avoid importing this package
+// (dialogue checks for a Preempted() method instead of ErrPreempted)
+type preemptedError struct{ id string }
+
+func (e *preemptedError) Error() string        { return "request " + e.id + " " + ErrPreempted.Error() }
+func (e *preemptedError) Is(target error) bool { return target == ErrPreempted }
+func (e *preemptedError) Preempted() bool      { return true }
+
+// ErrContextOverflow is returned when the server rejects a request because the prompt
+// plus max_tokens does not fit in the model's context window
+var ErrContextOverflow = errors.New("request exceeds the model's context window")
+
+// contextOverflowMarkers are phrases llama.cpp, OpenAI and Anthropic use in
+// context-length errors
+var contextOverflowMarkers = []string{
+	"exceed_context_size",
+	"exceeds the available context size",
+	"context_length_exceeded",
+	"maximum context length",
+	"prompt is too long",
+}
+
+// isContextOverflowMessage reports whether an error body describes a context overflow
+func isContextOverflowMessage(body string) bool {
+	body = strings.ToLower(body)
+	for _, marker := range contextOverflowMarkers {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// contextOverflowError marks a context overflow for callers that avoid importing this
+// package (dialogue checks for a ContextOverflow() method instead of ErrContextOverflow)
+type contextOverflowError struct{ status int }
+
+func (e *contextOverflowError) Error() string {
+	return fmt.Sprintf("LLM returned status %d: %s", e.status, ErrContextOverflow)
+}
+func (e *contextOverflowError) Is(target error) bool { return target == ErrContextOverflow }
+func (e *contextOverflowError) ContextOverflow() bool { return true }
+
+// statusError reports a non-200 response from the local model server, as a context
+// overflow when the body says so
+func statusError(status int, body []byte) error {
+	if isContextOverflowMessage(string(body)) {
+		return &contextOverflowError{status: status}
+	}
+	return fmt.Errorf("LLM returned status %d", status)
+}
+
 // Metrics tracks queue performance
 type Metrics struct {
-	CriticalEnqueued    int64
-	CriticalProcessed   int64
-	CriticalDropped     int64
-	BackgroundEnqueued  int64
-	BackgroundProcessed int64
-	BackgroundDropped   int64
-	CurrentQueueDepth   map[Priority]int
+	CriticalEnqueued     int64
+	CriticalProcessed    int64
+	CriticalDropped      int64
+	BackgroundEnqueued   int64
+	BackgroundProcessed  int64
+	BackgroundDropped    int64
+	BackgroundYielded    int64         // Dispatched background requests put back for a critical one
+	BackgroundPreempted  int64         // In-flight background requests cancelled for a critical one
+	CriticalWait         time.Duration // Total submit-to-start time
+	BackgroundWait       time.Duration
+	CriticalRecentWait   time.Duration // Moving average of the latest submit-to-start times
+	BackgroundRecentWait time.Duration
+	CurrentQueueDepth    map[Priority]int
+}
+
+// LaneMetrics describes one priority lane
+type LaneMetrics struct {
+	Queued       int     `json:"queued"` // Waiting for a slot
+	InFlight     int     `json:"in_flight"`
+	Enqueued     int64   `json:"enqueued"`
+	Processed    int64   `json:"processed"`
+	Dropped      int64   `json:"dropped"`
+	Yielded      int64   `json:"yielded,omitempty"`
+	Preempted    int64   `json:"preempted,omitempty"`
+	AvgWaitMs    float64 `json:"avg_wait_ms"`    // Mean submit-to-start time of started requests
+	RecentWaitMs float64 `json:"recent_wait_ms"` // Moving average weighted to the latest starts
+}
+
+// QueueStats is the load a client's next request would queue behind
+type QueueStats struct {
+	Depth      int           `json:"depth"`       // Requests that would start first
+	InFlight   int           `json:"in_flight"`   // Requests holding a slot
+	RecentWait time.Duration `json:"recent_wait"` // Recent submit-to-start time in this lane
+}
+
+// QueueMetrics reports both lanes
+type QueueMetrics struct {
+	MaxConcurrent     int         `json:"max_concurrent"`
+	PreemptBackground bool        `json:"preempt_background"`
+	Critical          LaneMetrics `json:"critical"`
+	Background        LaneMetrics `json:"background"`
 }
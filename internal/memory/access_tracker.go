@@ -0,0 +1,205 @@
+// internal/memory/access_tracker.go
+package memory
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Access counting modes
+const (
+	AccessCountEveryHit = "every_hit" // Each retrieval increments access_count
+	AccessCountHourly   = "hourly"    // At most one increment per memory per hour
+)
+
+// accessCoalesceWindow is how long an hourly-mode memory waits before counting again
+const accessCoalesceWindow = time.Hour
+
+// AccessTrackerConfig controls how retrievals are recorded
+type AccessTrackerConfig struct {
+	Mode          string        // AccessCountEveryHit (default) or AccessCountHourly
+	FlushInterval time.Duration // How often batched touches are written (default 10s)
+}
+
+// accessUpdater applies a batched touch to one memory
+type accessUpdater interface {
+	AddAccess(ctx context.Context, memoryID string, increment int, accessedAt time.Time) error
+}
+
+// pendingAccess accumulates retrievals of one memory between flushes
+type pendingAccess struct {
+	hits     int
+	lastSeen time.Time
+}
+
+// AccessTracker records memory retrievals off the search path and writes access_count
+// and last_accessed_at in the background, coalescing repeated hits between flushes.
+type AccessTracker struct {
+	updater accessUpdater
+	config  AccessTrackerConfig
+
+	mu          sync.Mutex
+	pending     map[string]*pendingAccess
+	lastCounted map[string]time.Time // Hourly mode: when each memory last incremented
+
+	flushed  atomic.Int64 // Memories updated
+	failed   atomic.Int64
+	stopChan chan struct{}
+	stopOnce sync.Once
+	doneChan chan struct{}
+}
+
+// AccessTrackerStats reports background update outcomes
+type AccessTrackerStats struct {
+	Pending int   `json:"pending"`
+	Flushed int64 `json:"flushed"`
+	Failed  int64 `json:"failed"`
+}
+
+// NewAccessTracker creates a tracker writing through the given storage
+func NewAccessTracker(storage *Storage, config AccessTrackerConfig) *AccessTracker {
+	return newAccessTracker(storage, config)
+}
+
+func newAccessTracker(updater accessUpdater, config AccessTrackerConfig) *AccessTracker {
+	if config.Mode != AccessCountHourly {
+		config.Mode = AccessCountEveryHit
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	return &AccessTracker{
+		updater:     updater,
+		config:      config,
+		pending:     make(map[string]*pendingAccess),
+		lastCounted: make(map[string]time.Time),
+		stopChan:    make(chan struct{}),
+		doneChan:    make(chan struct{}),
+	}
+}
+
+// Start runs the flush loop until Stop; pending touches are flushed on the way out
+func (t *AccessTracker) Start() {
+	ticker := time.NewTicker(t.config.FlushInterval)
+	defer ticker.Stop()
+	defer close(t.doneChan)
+
+	for {
+		select {
+		case <-ticker.C:
+			t.Flush(context.Background())
+		case <-t.stopChan:
+			t.Flush(context.Background())
+			return
+		}
+	}
+}
+
+// Stop ends the flush loop and waits for the final flush
+func (t *AccessTracker) Stop() {
+	t.stopOnce.Do(func() { close(t.stopChan) })
+	<-t.doneChan
+}
+
+// Record notes that memories were retrieved. It never blocks on storage.
+func (t *AccessTracker) Record(memoryIDs []string) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, id := range memoryIDs {
+		if id == "" {
+			continue
+		}
+		p, ok := t.pending[id]
+		if !ok {
+			p = &pendingAccess{}
+			t.pending[id] = p
+		}
+		p.hits++
+		p.lastSeen = now
+	}
+}
+
+// Flush writes all pending touches and returns how many memories were updated
+func (t *AccessTracker) Flush(ctx context.Context) int {
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[string]*pendingAccess)
+	now := time.Now()
+	increments := make(map[string]int, len(batch))
+	for id, p := range batch {
+		increments[id] = t.incrementFor(id, p, now)
+	}
+	t.pruneLastCounted(now)
+	t.mu.Unlock()
+
+	updated := 0
+	for id, p := range batch {
+		if err := t.updater.AddAccess(ctx, id, increments[id], p.lastSeen); err != nil {
+			t.failed.Add(1)
+			log.Printf("[AccessTracker] WARNING: Failed to update access metadata for %s: %v", id, err)
+			continue
+		}
+		updated++
+	}
+	t.flushed.Add(int64(updated))
+	return updated
+}
+
+// incrementFor applies the counting mode. Caller must hold t.mu.
+func (t *AccessTracker) incrementFor(id string, p *pendingAccess, now time.Time) int {
+	if t.config.Mode != AccessCountHourly {
+		return p.hits
+	}
+	if last, ok := t.lastCounted[id]; ok && now.Sub(last) < accessCoalesceWindow {
+		return 0 // Still refresh last_accessed_at, but don't count again
+	}
+	t.lastCounted[id] = now
+	return 1
+}
+
+// pruneLastCounted forgets memories outside the coalescing window. Caller must hold t.mu.
+func (t *AccessTracker) pruneLastCounted(now time.Time) {
+	for id, last := range t.lastCounted {
+		if now.Sub(last) >= accessCoalesceWindow {
+			delete(t.lastCounted, id)
+		}
+	}
+}
+
+// Stats returns pending and completed update counts
+func (t *AccessTracker) Stats() AccessTrackerStats {
+	t.mu.Lock()
+	pending := len(t.pending)
+	t.mu.Unlock()
+	return AccessTrackerStats{Pending: pending, Flushed: t.flushed.Load(), Failed: t.failed.Load()}
+}
+
+// installedAccessTracker is shared by every Storage instance, including the short-lived
+// ones API handlers create per request, so hits coalesce process-wide
+var installedAccessTracker atomic.Pointer[AccessTracker]
+
+// InstallAccessTracker makes Storage.Search record retrievals through t (nil disables)
+func InstallAccessTracker(t *AccessTracker) {
+	installedAccessTracker.Store(t)
+}
+
+// RecordAccess hands retrieved memory IDs to the installed tracker. Search does this
+// itself; callers only need it for memories fetched another way (e.g. linked memories).
+// It returns false when no tracker is installed.
+func RecordAccess(results []RetrievalResult) bool {
+	tracker := installedAccessTracker.Load()
+	if tracker == nil {
+		return false
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Memory.ID
+	}
+	tracker.Record(ids)
+	return true
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeAccessUpdater struct {
+	mu      sync.Mutex
+	counts  map[string]int
+	touched map[string]time.Time
+	updates chan string
+}
+
+func newFakeAccessUpdater() *fakeAccessUpdater {
+	return &fakeAccessUpdater{counts: map[string]int{}, touched: map[string]time.Time{}, updates: make(chan string, 100)}
+}
+
+func (f *fakeAccessUpdater) AddAccess(ctx context.Context, memoryID string, increment int, accessedAt time.Time) error {
+	f.mu.Lock()
+	f.counts[memoryID] += increment
+	f.touched[memoryID] = accessedAt
+	f.mu.Unlock()
+	f.updates <- memoryID
+	return nil
+}
+
+func (f *fakeAccessUpdater) count(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[id]
+}
+
+func TestAccessTrackerAsyncUpdateLands(t *testing.T) {
+	updater := newFakeAccessUpdater()
+	tracker := newAccessTracker(updater, AccessTrackerConfig{FlushInterval: 10 * time.Millisecond})
+	go tracker.Start()
+	defer tracker.Stop()
+
+	InstallAccessTracker(tracker)
+	defer InstallAccessTracker(nil)
+
+	results := []RetrievalResult{{Memory: Memory{ID: "a"}}, {Memory: Memory{ID: "b"}}}
+	before := time.Now()
+	if !RecordAccess(results) || !RecordAccess(results[:1]) {
+		t.Fatal("expected the installed tracker to accept the retrieval")
+	}
+
+	landed := map[string]bool{}
+	timeout := time.After(2 * time.Second)
+	for len(landed) < 2 {
+		select {
+		case id := <-updater.updates:
+			landed[id] = true
+		case <-timeout:
+			t.Fatalf("async access update did not land, got %v", landed)
+		}
+	}
+
+	if updater.count("a") != 2 || updater.count("b") != 1 {
+		t.Errorf("expected every hit to count (a=2, b=1), got a=%d b=%d", updater.count("a"), updater.count("b"))
+	}
+	updater.mu.Lock()
+	touched := updater.touched["a"]
+	updater.mu.Unlock()
+	if touched.Before(before) {
+		t.Errorf("expected last_accessed_at to be refreshed, got %v", touched)
+	}
+}
+
+func TestAccessTrackerHourlyCoalescing(t *testing.T) {
+	updater := newFakeAccessUpdater()
+	tracker := newAccessTracker(updater, AccessTrackerConfig{Mode: AccessCountHourly})
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		tracker.Record([]string{"m"})
+	}
+	tracker.Flush(ctx)
+	tracker.Record([]string{"m"})
+	if updated := tracker.Flush(ctx); updated != 1 {
+		t.Fatalf("expected the timestamp-only touch to be written, got %d updates", updated)
+	}
+
+	if got := updater.count("m"); got != 1 {
+		t.Errorf("expected 11 retrievals within the hour to count once, got %d", got)
+	}
+	if stats := tracker.Stats(); stats.Pending != 0 || stats.Flushed != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRecordAccessWithoutTracker(t *testing.T) {
+	InstallAccessTracker(nil)
+	if RecordAccess([]RetrievalResult{{Memory: Memory{ID: "x"}}}) {
+		t.Error("expected RecordAccess to report no tracker")
+	}
+}
@@ -0,0 +1,189 @@
+// internal/memory/compression_preview.go
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	previewPageSize      = 200   // Memories fetched per scroll page
+	previewSampleSize    = 10    // Sample entries returned per transition
+	previewMaxScanned    = 20000 // Hard cap on memories scanned per transition
+	previewContentLength = 80    // Characters of content shown per sample
+)
+
+// PreviewSample is a lightweight view of a memory that would be compressed
+type PreviewSample struct {
+	ID          string    `json:"id"`
+	Content     string    `json:"content"` // First 80 characters
+	CreatedAt   time.Time `json:"created_at"`
+	AdjustedAge float64   `json:"adjusted_age_days,omitempty"` // Age-rule simulation only
+	Score       float64   `json:"score,omitempty"`             // Live policy only; higher compresses first
+}
+
+// LivePreview describes what the next compression pass would do to one tier under
+// the worker's space-based limits
+type LivePreview struct {
+	From       MemoryTier      `json:"from"`
+	To         MemoryTier      `json:"to"`
+	Count      int             `json:"count"`      // Memories in the source tier
+	Limit      int             `json:"limit"`      // The tier's share of the total limit
+	Trigger    int             `json:"trigger"`    // Count at which the pass compresses the tier
+	Target     int             `json:"target"`     // Count the pass compresses the tier down to
+	Triggered  bool            `json:"triggered"`  // The pass would compress this tier
+	Candidates int             `json:"candidates"` // Memories the pass would select
+	Sample     []PreviewSample `json:"sample"`     // Most compressible first
+}
+
+// TransitionPreview describes what one tier transition would do under the given
+// age-based rules
+type TransitionPreview struct {
+	From      MemoryTier      `json:"from"`
+	To        MemoryTier      `json:"to"`
+	AgeDays   int             `json:"age_days"`
+	Scanned   int             `json:"scanned"`   // Memories older than AgeDays in the source tier
+	Count     int             `json:"count"`     // Memories whose adjusted age meets the threshold
+	Truncated bool            `json:"truncated"` // Scan stopped at the safety cap
+	Sample    []PreviewSample `json:"sample"`
+}
+
+// CompressionPreview is a dry run of compression. Live is what the next pass would
+// compress under the space-based policy the worker runs. Rules and Transitions simulate
+// the deprecated age-based tier rules, which no pass runs any more, so thresholds can
+// still be compared against live data.
+type CompressionPreview struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	Live        []LivePreview       `json:"live"`
+	Rules       TierRules           `json:"age_rules"`
+	Transitions []TransitionPreview `json:"age_rule_transitions"`
+}
+
+// TierRules returns the age thresholds the worker was configured with
+func (w *DecayWorker) TierRules() TierRules {
+	return w.tierRules
+}
+
+// Preview reports, per tier transition, which memories the next pass would compress
+// and which would move under the given age rules, without mutating anything. The live
+// selection fetches what the pass itself would, at most 1000 memories a tier; the age
+// rules stream memories page by page so the whole collection is never held in memory.
+func (w *DecayWorker) Preview(ctx context.Context, rules TierRules) (*CompressionPreview, error) {
+	live, err := w.previewLive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preview := &CompressionPreview{
+		GeneratedAt: time.Now(),
+		Live:        live,
+		Rules:       rules,
+		Transitions: []TransitionPreview{},
+	}
+
+	transitions := []struct {
+		from, to MemoryTier
+		ageDays  int
+	}{
+		{TierRecent, TierMedium, rules.RecentToMediumDays},
+		{TierMedium, TierLong, rules.MediumToLongDays},
+		{TierLong, TierAncient, rules.LongToAncientDays},
+	}
+
+	for _, t := range transitions {
+		if t.ageDays <= 0 {
+			continue // Transition disabled
+		}
+
+		tp := TransitionPreview{
+			From:    t.from,
+			To:      t.to,
+			AgeDays: t.ageDays,
+			Sample:  []PreviewSample{},
+		}
+
+		err := w.storage.ScrollMemoriesOlderThan(ctx, t.from, t.ageDays, previewPageSize, func(page []Memory) bool {
+			for i := range page {
+				tp.Scanned++
+
+				adjustedAge := w.calculateAdjustedAge(&page[i], t.ageDays)
+				if adjustedAge < float64(t.ageDays) {
+					continue
+				}
+
+				tp.Count++
+				if len(tp.Sample) < previewSampleSize {
+					tp.Sample = append(tp.Sample, PreviewSample{
+						ID:          page[i].ID,
+						Content:     truncate(page[i].Content, previewContentLength),
+						CreatedAt:   page[i].CreatedAt,
+						AdjustedAge: adjustedAge,
+					})
+				}
+			}
+
+			if tp.Scanned >= previewMaxScanned {
+				tp.Truncated = true
+				return false
+			}
+			return ctx.Err() == nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("preview of %s -> %s failed: %w", t.from, t.to, err)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		preview.Transitions = append(preview.Transitions, tp)
+	}
+
+	return preview, nil
+}
+
+// previewLive runs the space-based pass's checks and candidate selection without
+// compressing anything
+func (w *DecayWorker) previewLive(ctx context.Context) ([]LivePreview, error) {
+	tierCounts, err := w.storage.GetTierCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tier counts: %w", err)
+	}
+	tierLimits := w.tierLimits()
+
+	transitions := []struct{ from, to MemoryTier }{
+		{TierRecent, TierMedium},
+		{TierMedium, TierLong},
+		{TierLong, TierAncient},
+	}
+	live := []LivePreview{}
+	for _, t := range transitions {
+		limit := tierLimits[t.from]
+		lp := LivePreview{
+			From:    t.from,
+			To:      t.to,
+			Count:   tierCounts[t.from],
+			Limit:   limit,
+			Trigger: int(float64(limit) * w.storageLimits.CompressionTrigger),
+			Target:  int(float64(limit) * compressionTargetRatio),
+			Sample:  []PreviewSample{},
+		}
+		lp.Triggered = lp.Count >= lp.Trigger
+		if lp.Triggered {
+			candidates, err := w.selectMemoriesForCompression(ctx, t.from, lp.Count, limit, lp.Target)
+			if err != nil {
+				return nil, fmt.Errorf("preview of %s -> %s failed: %w", t.from, t.to, err)
+			}
+			lp.Candidates = len(candidates)
+			for i := 0; i < len(candidates) && i < previewSampleSize; i++ {
+				lp.Sample = append(lp.Sample, PreviewSample{
+					ID:        candidates[i].ID,
+					Content:   truncate(candidates[i].Content, previewContentLength),
+					CreatedAt: candidates[i].CreatedAt,
+					Score:     w.compressionScore(&candidates[i]),
+				})
+			}
+		}
+		live = append(live, lp)
+	}
+	return live, nil
+}
@@ -0,0 +1,145 @@
+// internal/memory/compression_report.go
+package memory
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// ErrCompressionInProgress is returned when a compression pass is already running
+var ErrCompressionInProgress = errors.New("compression pass already in progress")
+
+// Compression trigger sources
+const (
+	CompressionTriggerScheduled = "scheduled"
+	CompressionTriggerManual    = "manual"
+)
+
+// TierTransitionReport summarises compression for one tier transition
+type TierTransitionReport struct {
+	From         MemoryTier `json:"from"`
+	To           MemoryTier `json:"to"`
+	Count        int        `json:"count"`        // Memories in the source tier before compression
+	Limit        int        `json:"limit"`        // Space allocation for the source tier
+	Candidates   int        `json:"candidates"`   // Memories selected for compression
+	Compressions int        `json:"compressions"` // Compress/CompressCluster calls that succeeded
+	Clustered    int        `json:"clustered"`    // Memories merged as part of a cluster
+}
+
+// CompressionReport is the structured result of one DecayWorker pass
+type CompressionReport struct {
+	Trigger          string                 `json:"trigger"` // "scheduled" or "manual"
+	StartedAt        time.Time              `json:"started_at"`
+	FinishedAt       time.Time              `json:"finished_at"`
+	Duration         time.Duration          `json:"duration_ns"`
+	MemoriesExamined int                    `json:"memories_examined"`
+	Transitions      []TierTransitionReport `json:"transitions"`
+	CompressorTokens int64                  `json:"compressor_tokens"`
+	Eviction         *EvictionReport        `json:"eviction,omitempty"`     // Nil when no collective cap is set
+	StatsBefore      *MemoryStats           `json:"stats_before,omitempty"` // Nil when the stats could not be gathered
+	StatsAfter       *MemoryStats           `json:"stats_after,omitempty"`
+	Errors           []string               `json:"errors,omitempty"`
+}
+
+// TotalCompressions sums compressions across all tier transitions
+func (r *CompressionReport) TotalCompressions() int {
+	total := 0
+	for _, t := range r.Transitions {
+		total += t.Compressions
+	}
+	return total
+}
+
+// addError records a non-fatal phase error
+func (r *CompressionReport) addError(phase string, err error) {
+	r.Errors = append(r.Errors, phase+": "+err.Error())
+}
+
+// Log writes the report as a compact multi-line summary
+func (r *CompressionReport) Log() {
+	log.Printf("[DecayWorker] Compression report (%s): examined=%d, compressions=%d, compressor_tokens=%d, took %s",
+		r.Trigger, r.MemoriesExamined, r.TotalCompressions(), r.CompressorTokens, r.Duration.Round(time.Second))
+	for _, t := range r.Transitions {
+		log.Printf("[DecayWorker]   %s -> %s: %d/%d, candidates=%d, compressions=%d, clustered=%d",
+			t.From, t.To, t.Count, t.Limit, t.Candidates, t.Compressions, t.Clustered)
+	}
+	if r.Eviction != nil {
+		log.Printf("[DecayWorker]   eviction: %d/%d collective, evicted=%d (%s), protected=%s",
+			r.Eviction.CountBefore, r.Eviction.Cap, r.Eviction.Evicted,
+			formatCategoryCounts(r.Eviction.EvictedByCategory), formatCategoryCounts(r.Eviction.ProtectedByCategory))
+	}
+	if r.StatsBefore != nil && r.StatsAfter != nil {
+		log.Printf("[DecayWorker]   memories: %d -> %d (recent %d -> %d, medium %d -> %d, long %d -> %d, ancient %d -> %d)",
+			r.StatsBefore.Total, r.StatsAfter.Total,
+			r.StatsBefore.ByTier[TierRecent], r.StatsAfter.ByTier[TierRecent],
+			r.StatsBefore.ByTier[TierMedium], r.StatsAfter.ByTier[TierMedium],
+			r.StatsBefore.ByTier[TierLong], r.StatsAfter.ByTier[TierLong],
+			r.StatsBefore.ByTier[TierAncient], r.StatsAfter.ByTier[TierAncient])
+	}
+	if len(r.Errors) > 0 {
+		log.Printf("[DecayWorker]   errors: %s", strings.Join(r.Errors, "; "))
+	}
+}
+
+// RunOnce executes one full compression pass synchronously and returns its report.
+// It shares the scheduled loop's lock, so it fails fast with ErrCompressionInProgress
+// rather than running concurrently with a scheduled pass.
+func (w *DecayWorker) RunOnce(ctx context.Context) (*CompressionReport, error) {
+	return w.runPass(ctx, CompressionTriggerManual)
+}
+
+// MemoryStats returns the collection's stats, gathered at most once a minute
+func (w *DecayWorker) MemoryStats(ctx context.Context) (*MemoryStats, error) {
+	return w.statsCache.Get(ctx)
+}
+
+// LastReport returns the report from the most recent completed pass (nil if none yet)
+func (w *DecayWorker) LastReport() *CompressionReport {
+	w.reportMu.RLock()
+	defer w.reportMu.RUnlock()
+	return w.lastReport
+}
+
+// runPass guards a compression cycle with the worker lock and records its report
+func (w *DecayWorker) runPass(ctx context.Context, trigger string) (*CompressionReport, error) {
+	if !w.runMu.TryLock() {
+		return nil, ErrCompressionInProgress
+	}
+	defer w.runMu.Unlock()
+
+	report := &CompressionReport{
+		Trigger:     trigger,
+		StartedAt:   time.Now(),
+		Transitions: []TierTransitionReport{},
+	}
+
+	// Stats either side of the pass quantify what it changed
+	if stats, err := w.storage.Stats(ctx); err != nil {
+		report.addError("stats_before", err)
+	} else {
+		report.StatsBefore = stats
+	}
+
+	tokensBefore := w.compressor.TokensUsed()
+	w.runCompressionCycle(ctx, report)
+	report.CompressorTokens = w.compressor.TokensUsed() - tokensBefore
+
+	if stats, err := w.storage.Stats(ctx); err != nil {
+		report.addError("stats_after", err)
+	} else {
+		report.StatsAfter = stats
+		w.statsCache.Put(stats)
+	}
+
+	report.FinishedAt = time.Now()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt)
+
+	w.reportMu.Lock()
+	w.lastReport = report
+	w.reportMu.Unlock()
+
+	return report, nil
+}
@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunOnceRefusesWhileAPassRuns(t *testing.T) {
+	w := &DecayWorker{}
+
+	// A scheduled pass holds the lock for its whole run
+	w.runMu.Lock()
+	report, err := w.RunOnce(context.Background())
+	w.runMu.Unlock()
+
+	if !errors.Is(err, ErrCompressionInProgress) || report != nil {
+		t.Fatalf("expected ErrCompressionInProgress, got %+v, %v", report, err)
+	}
+	if w.LastReport() != nil {
+		t.Error("expected no report recorded for a refused pass")
+	}
+}
+
+func TestCompressionReportTotalsTransitions(t *testing.T) {
+	report := &CompressionReport{Transitions: []TierTransitionReport{
+		{From: TierRecent, To: TierMedium, Compressions: 3},
+		{From: TierMedium, To: TierLong, Compressions: 1},
+		{From: TierLong, To: TierAncient},
+	}}
+	if got := report.TotalCompressions(); got != 4 {
+		t.Errorf("expected 4 compressions, got %d", got)
+	}
+
+	report.addError("stats_before", errors.New("qdrant unavailable"))
+	if len(report.Errors) != 1 || report.Errors[0] != "stats_before: qdrant unavailable" {
+		t.Errorf("expected the phase-prefixed error, got %v", report.Errors)
+	}
+}
@@ -8,9 +8,11 @@ import (
     "log"
     "net/http"
     "strings"
+    "sync/atomic"
     "time"
 
     "go-llama/internal/config"
+    "go-llama/internal/httpclient"
 )
 
 // Compressor handles LLM-based memory compression
@@ -21,6 +23,7 @@ type Compressor struct {
 	embedder  *Embedder
 	linker    *Linker
 	llmClient interface{} // Queue client
+	tokensUsed atomic.Int64 // Cumulative tokens reported by the LLM
 }
 
 // NewCompressor creates a new compressor instance
@@ -28,7 +31,7 @@ func NewCompressor(modelURL, modelName string, embedder *Embedder, linker *Linke
 	return &Compressor{
 		modelURL:  modelURL,
 		modelName: modelName,
-		client:    &http.Client{Timeout: 60 * time.Second},
+		client:    httpclient.New(60 * time.Second),
 		embedder:  embedder,
 		linker:    linker,
 		llmClient: llmClient,
@@ -266,6 +269,11 @@ func (c *Compressor) aggregateOutcomeTags(cluster []Memory) string {
 	return result
 }
 
+// TokensUsed returns the cumulative tokens spent by this compressor
+func (c *Compressor) TokensUsed() int64 {
+	return c.tokensUsed.Load()
+}
+
 // callLLM sends a request to the compression LLM via queue
 func (c *Compressor) callLLM(ctx context.Context, prompt string) (string, error) {
 	// Use queue if available, otherwise fall back to direct HTTP
@@ -305,11 +313,15 @@ func (c *Compressor) callLLM(ctx context.Context, prompt string) (string, error)
 						Content string `json:"content"`
 					} `json:"message"`
 				} `json:"choices"`
+				Usage struct {
+					TotalTokens int `json:"total_tokens"`
+				} `json:"usage"`
 			}
 			
 			if err := json.Unmarshal(body, &result); err != nil {
 				return "", fmt.Errorf("failed to decode response: %w", err)
 			}
+			c.tokensUsed.Add(int64(result.Usage.TotalTokens))
 			
 			if len(result.Choices) == 0 {
 				return "", fmt.Errorf("no choices returned from LLM")
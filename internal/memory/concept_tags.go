@@ -0,0 +1,62 @@
+// internal/memory/concept_tags.go
+package memory
+
+import (
+	"strings"
+)
+
+// Concept tag bounds enforced on every stored memory
+const (
+	MinConceptTagChars = 2
+	MaxConceptTagChars = 40
+	MaxConceptTags     = 10
+)
+
+// conceptStopWords are words that say nothing about a memory's topic. They turned up as
+// tags when tags were cut from the first word of a question.
+var conceptStopWords = map[string]bool{
+	"what": true, "how": true, "why": true, "when": true, "where": true, "which": true,
+	"who": true, "whom": true, "whose": true, "does": true, "do": true, "did": true,
+	"is": true, "are": true, "was": true, "were": true, "can": true, "could": true,
+	"should": true, "would": true, "will": true, "may": true, "might": true, "has": true,
+	"have": true, "had": true, "the": true, "and": true, "or": true, "but": true,
+	"for": true, "with": true, "from": true, "into": true, "about": true, "this": true,
+	"that": true, "these": true, "those": true, "there": true, "their": true, "its": true,
+	"it": true, "an": true, "of": true, "to": true, "in": true, "on": true, "at": true,
+	"by": true, "be": true, "as": true, "if": true, "not": true, "no": true, "yes": true,
+	"some": true, "any": true, "all": true, "more": true, "most": true, "other": true,
+	"such": true, "than": true, "then": true, "also": true, "just": true, "very": true,
+}
+
+// ValidateConceptTags normalises tags for storage: lowercased and trimmed of
+// punctuation, stop words and tags outside the length bounds dropped, duplicates
+// removed case-insensitively (the first is kept) and at most MaxConceptTags returned
+func ValidateConceptTags(tags []string) []string {
+	valid := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Trim(strings.TrimSpace(tag), "?,.!;:\"'()[]"))
+		if len(tag) < MinConceptTagChars || len(tag) > MaxConceptTagChars || conceptStopWords[tag] || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		valid = append(valid, tag)
+		if len(valid) == MaxConceptTags {
+			break
+		}
+	}
+	return valid
+}
+
+// conceptTagsChanged reports whether validation would alter tags
+func conceptTagsChanged(tags, valid []string) bool {
+	if len(tags) != len(valid) {
+		return true
+	}
+	for i := range tags {
+		if tags[i] != valid[i] {
+			return true
+		}
+	}
+	return false
+}
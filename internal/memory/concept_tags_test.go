@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateConceptTagsDropsStopWords(t *testing.T) {
+	got := ValidateConceptTags([]string{"What", "how", "does", "photosynthesis", "Why?"})
+	if !reflect.DeepEqual(got, []string{"photosynthesis"}) {
+		t.Errorf("expected only the topic to survive, got %v", got)
+	}
+}
+
+func TestValidateConceptTagsDedupesCaseInsensitively(t *testing.T) {
+	got := ValidateConceptTags([]string{"Golang", "golang", " GOLANG. ", "memory"})
+	if !reflect.DeepEqual(got, []string{"golang", "memory"}) {
+		t.Errorf("expected one golang tag, got %v", got)
+	}
+}
+
+func TestValidateConceptTagsEnforcesBounds(t *testing.T) {
+	long := strings.Repeat("x", MaxConceptTagChars+1)
+	got := ValidateConceptTags([]string{"a", long, "ok"})
+	if !reflect.DeepEqual(got, []string{"ok"}) {
+		t.Errorf("expected tags outside the length bounds dropped, got %v", got)
+	}
+
+	many := make([]string, 0, MaxConceptTags+5)
+	for i := 0; i < MaxConceptTags+5; i++ {
+		many = append(many, "tag"+string(rune('a'+i)))
+	}
+	if got := ValidateConceptTags(many); len(got) != MaxConceptTags {
+		t.Errorf("expected at most %d tags, got %d", MaxConceptTags, len(got))
+	}
+}
+
+func TestConceptTagsChanged(t *testing.T) {
+	tags := []string{"golang", "memory"}
+	if conceptTagsChanged(tags, ValidateConceptTags(tags)) {
+		t.Error("expected valid tags to be left alone")
+	}
+	tags = []string{"What", "golang"}
+	if !conceptTagsChanged(tags, ValidateConceptTags(tags)) {
+		t.Error("expected a stop word to count as a change")
+	}
+}
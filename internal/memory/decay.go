@@ -7,9 +7,10 @@ import (
 	"log"
 	"math"
 	"sort"
+	"sync"
 	"time"
 
-	"gorm.io/gorm"
+	"go-llama/internal/health"
 )
 
 // TaggerQueueInterface defines the interface for async tagging
@@ -20,8 +21,9 @@ type TaggerQueueInterface interface {
 
 // StorageLimits defines space-based compression configuration
 type StorageLimits struct {
-	MaxTotalMemories   int
-	TierAllocation     TierAllocation
+	MaxTotalMemories      int
+	MaxCollectiveMemories int // Evict beyond this many collective memories (0 = unlimited)
+	TierAllocation        TierAllocation
 	CompressionTrigger float64
 	AllowTierOverflow  bool
 }
@@ -41,22 +43,13 @@ type CompressionWeights struct {
 	Access     float64
 }
 
-// DecayWorker manages the background compression and principle evolution process
+// DecayWorker manages the background compression process. Principle evolution runs
+// separately in PrincipleWorker.
 type DecayWorker struct {
-    storage                *Storage
-    compressor             *Compressor
-    embedder               *Embedder
+    *WorkerDeps
     taggerQueue            TaggerQueueInterface
     linker                 *Linker
-    db                     *gorm.DB   // Database handle (used for migration status)
-    llmURL                 string     // LLM URL for principle generation (Main/Complex)
-    llmModel               string     // LLM model name for principle generation (Main/Complex)
-    llmClient              interface{} // LLM queue client for principle generation
-    llmSmallURL            string     // LLM URL for simple evaluations (Fast/Light)
-    llmSmallModel          string     // LLM model name for simple evaluations
     scheduleHours          int
-    minRatingThreshold     float64
-    extractionLimit        int        // Max memories to analyze for principles
     tierRules              TierRules  // DEPRECATED: kept for backwards compatibility
     mergeWindows           MergeWindows
     importanceMod          float64    // DEPRECATED: kept for backwards compatibility
@@ -68,6 +61,12 @@ type DecayWorker struct {
     
     stopChan               chan struct{}
     migrationComplete      bool       // One-time memory_id migration flag (in-memory only, check DB on start)
+
+    runMu                  sync.Mutex         // Serialises scheduled and manual passes
+    reportMu               sync.RWMutex       // Protects lastReport
+    lastReport             *CompressionReport // Result of the most recent pass
+    statsCache             *StatsCache        // Memory stats for the admin dashboard
+    runs                   *runTracker        // Scheduled passes, for the health endpoint
 }
 
 // TierRules defines age thresholds for tier transitions
@@ -86,20 +85,10 @@ type MergeWindows struct {
 
 // NewDecayWorker creates a new background compression worker
 func NewDecayWorker(
-    storage *Storage,
-    compressor *Compressor,
-    embedder *Embedder,
+    deps *WorkerDeps,
     taggerQueue TaggerQueueInterface,
     linker *Linker,
-    db *gorm.DB,
-    llmURL string,
-    llmModel string,
-    llmSmallURL string, // ADD THIS
-    llmSmallModel string, // ADD THIS
-    llmClient interface{}, // NEW: LLM queue client
     scheduleHours int,
-    minRatingThreshold float64,
-    extractionLimit int,
     tierRules TierRules,           // DEPRECATED: kept for backwards compatibility
     mergeWindows MergeWindows,
     importanceMod float64,          // DEPRECATED: kept for backwards compatibility
@@ -108,20 +97,10 @@ func NewDecayWorker(
     compressionWeights CompressionWeights, // NEW: compression scoring weights
 ) *DecayWorker {
     return &DecayWorker{
-        storage:            storage,
-        compressor:         compressor,
-        embedder:           embedder,
+        WorkerDeps:         deps,
         taggerQueue:        taggerQueue,
         linker:             linker,
-        db:                 db,
-        llmURL:             llmURL,
-        llmModel:           llmModel,
-		llmSmallURL:        llmSmallURL,        // ADD THIS
-        llmSmallModel:      llmSmallModel,      // ADD THIS
-        llmClient:          llmClient, // NEW: Store LLM client
         scheduleHours:      scheduleHours,
-        minRatingThreshold: minRatingThreshold,
-        extractionLimit:    extractionLimit,
         tierRules:          tierRules,        // DEPRECATED
         mergeWindows:       mergeWindows,
         importanceMod:      importanceMod,    // DEPRECATED
@@ -130,24 +109,25 @@ func NewDecayWorker(
         compressionWeights: compressionWeights,
         stopChan:           make(chan struct{}),
         migrationComplete:  false, // Will check DB on first cycle
+        statsCache:         NewStatsCache(deps.storage),
+        runs:               newRunTracker("compression", "DecayWorker"),
     }
 }
 
 // Start begins the background compression loop
 func (w *DecayWorker) Start() {
     log.Printf("[DecayWorker] Starting compression worker (runs every %d hours)", w.scheduleHours)
-    log.Printf("[DecayWorker] Principle evolution will run during every compression cycle")
 
     ticker := time.NewTicker(time.Duration(w.scheduleHours) * time.Hour)
 	defer ticker.Stop()
 
 	// Run immediately on start
-	w.runCompressionCycle()
+	w.runScheduledPass()
 
 	for {
 		select {
 		case <-ticker.C:
-			w.runCompressionCycle()
+			w.runScheduledPass()
 		case <-w.stopChan:
 			log.Printf("[DecayWorker] Stopping compression worker")
 			return
@@ -160,11 +140,30 @@ func (w *DecayWorker) Stop() {
 	close(w.stopChan)
 }
 
+// Status returns the last scheduled pass's status for the health endpoint
+func (w *DecayWorker) Status() health.WorkerStatus {
+	return w.runs.Status()
+}
+
+// runScheduledPass runs one pass from the ticker loop and logs its report. A panic
+// is recovered and recorded; the next tick runs as usual.
+func (w *DecayWorker) runScheduledPass() {
+	w.runs.run(func() (string, error) {
+		report, err := w.runPass(context.Background(), CompressionTriggerScheduled)
+		if err != nil {
+			log.Printf("[DecayWorker] Skipping scheduled cycle: %v", err)
+			return "skipped", err
+		}
+		report.Log()
+		return fmt.Sprintf("%d memories examined, %d compressions, %d phase errors",
+			report.MemoriesExamined, report.TotalCompressions(), len(report.Errors)), nil
+	})
+}
+
 // runCompressionCycle performs one full compression cycle (space-based)
-func (w *DecayWorker) runCompressionCycle() {
+func (w *DecayWorker) runCompressionCycle(ctx context.Context, report *CompressionReport) {
 	log.Printf("[DecayWorker] Starting compression cycle at %s", time.Now().Format(time.RFC3339))
 	startTime := time.Now()
-	ctx := context.Background()
 	
 	// PHASE 0: One-time migration (check DB status, run if needed)
 	if !w.migrationComplete {
@@ -233,6 +232,34 @@ func (w *DecayWorker) runCompressionCycle() {
 	} else {
 		log.Println("[DecayWorker] ✓ is_collective migration already completed (from DB), skipping")
 	}
+
+	// PHASE 0.6: One-time source_kind backfill migration
+	var sourceKindState struct {
+		MigrationSourceKindComplete bool
+	}
+	err = w.db.Table("growerai_dialogue_state").
+		Select("migration_source_kind_complete").
+		Where("id = ?", 1).
+		First(&sourceKindState).Error
+
+	if err != nil {
+		log.Printf("[DecayWorker] WARNING: Could not check source_kind migration status: %v", err)
+	} else if !sourceKindState.MigrationSourceKindComplete {
+		log.Println("[DecayWorker] PHASE 0.6: Running one-time source_kind backfill migration...")
+		if err := w.storage.BackfillSourceKind(ctx); err != nil {
+			log.Printf("[DecayWorker] ERROR in source_kind migration: %v", err)
+		} else {
+			err := w.db.Table("growerai_dialogue_state").
+				Where("id = ?", 1).
+				Update("migration_source_kind_complete", true).Error
+
+			if err != nil {
+				log.Printf("[DecayWorker] WARNING: Failed to persist source_kind migration status: %v", err)
+			} else {
+				log.Println("[DecayWorker] ✓ source_kind backfill complete and persisted to DB")
+			}
+		}
+	}
 	
 	// PHASE 1: Enqueue untagged memories for async tagging
 	log.Println("[DecayWorker] PHASE 1: Tagging untagged memories...")
@@ -262,39 +289,60 @@ func (w *DecayWorker) runCompressionCycle() {
 	
 	// PHASE 2: Space-based compression
 	log.Println("[DecayWorker] PHASE 2: Space-based compression check...")
-	if err := w.runSpaceBasedCompression(ctx); err != nil {
+	if err := w.runSpaceBasedCompression(ctx, report); err != nil {
 		log.Printf("[DecayWorker] ERROR in compression phase: %v", err)
+		report.addError("compression", err)
 	}
 	
+	// PHASE 2.5: Collective memory cap
+	log.Println("[DecayWorker] PHASE 2.5: Enforcing collective memory cap...")
+	eviction, err := w.enforceCollectiveCap(ctx)
+	if err != nil {
+		log.Printf("[DecayWorker] ERROR in eviction phase: %v", err)
+		report.addError("eviction", err)
+	}
+	report.Eviction = eviction
+	
 	// PHASE 3: Prune weak links
 	log.Println("[DecayWorker] PHASE 3: Pruning weak links...")
 	if err := w.pruneWeakLinksPhase(ctx); err != nil {
 		log.Printf("[DecayWorker] ERROR in link pruning phase: %v", err)
+		report.addError("link_pruning", err)
 	}
 	
 	// PHASE 4: Recalculate trust scores
 	log.Println("[DecayWorker] PHASE 4: Recalculating trust scores...")
 	if err := w.recalculateTrustScores(ctx); err != nil {
 		log.Printf("[DecayWorker] ERROR in trust recalculation phase: %v", err)
+		report.addError("trust", err)
 	}
     // PHASE 4.5: Semantic deduplication (consolidate duplicate memories)
     log.Println("[DecayWorker] PHASE 4.5: Consolidating duplicate memories...")
     if err := w.consolidateDuplicatesPhase(ctx); err != nil {
         log.Printf("[DecayWorker] ERROR in consolidation phase: %v", err)
-    }
-    
-    // PHASE 5: Evolve principles (runs every compression cycle)
-    log.Println("[DecayWorker] PHASE 5: Evolving principles...")
-    if err := w.evolvePrinciplesPhase(ctx); err != nil {
-        log.Printf("[DecayWorker] ERROR in principle evolution phase: %v", err)
+        report.addError("consolidation", err)
     }
 	
 	duration := time.Since(startTime)
 	log.Printf("[DecayWorker] Compression cycle complete (took %s)", duration.Round(time.Second))
 }
 
+// compressionTargetRatio is the share of its limit a compressed tier is brought down to
+const compressionTargetRatio = 0.80
+
+// tierLimits allocates MaxTotalMemories between the tiers
+func (w *DecayWorker) tierLimits() map[MemoryTier]int {
+	limits := w.storageLimits
+	return map[MemoryTier]int{
+		TierRecent:  int(float64(limits.MaxTotalMemories) * limits.TierAllocation.Recent),
+		TierMedium:  int(float64(limits.MaxTotalMemories) * limits.TierAllocation.Medium),
+		TierLong:    int(float64(limits.MaxTotalMemories) * limits.TierAllocation.Long),
+		TierAncient: int(float64(limits.MaxTotalMemories) * limits.TierAllocation.Ancient),
+	}
+}
+
 // runSpaceBasedCompression checks each tier's space usage and compresses if needed
-func (w *DecayWorker) runSpaceBasedCompression(ctx context.Context) error {
+func (w *DecayWorker) runSpaceBasedCompression(ctx context.Context, report *CompressionReport) error {
 	// Get current memory counts per tier
 	tierCounts, err := w.storage.GetTierCounts(ctx)
 	if err != nil {
@@ -314,12 +362,7 @@ func (w *DecayWorker) runSpaceBasedCompression(ctx context.Context) error {
 		tierCounts[TierAncient])
 	
 	// Calculate tier limits
-	tierLimits := map[MemoryTier]int{
-		TierRecent:  int(float64(w.storageLimits.MaxTotalMemories) * w.storageLimits.TierAllocation.Recent),
-		TierMedium:  int(float64(w.storageLimits.MaxTotalMemories) * w.storageLimits.TierAllocation.Medium),
-		TierLong:    int(float64(w.storageLimits.MaxTotalMemories) * w.storageLimits.TierAllocation.Long),
-		TierAncient: int(float64(w.storageLimits.MaxTotalMemories) * w.storageLimits.TierAllocation.Ancient),
-	}
+	tierLimits := w.tierLimits()
 	
 	log.Printf("[DecayWorker] Tier limits: Recent=%d, Medium=%d, Long=%d, Ancient=%d",
 		tierLimits[TierRecent], tierLimits[TierMedium], tierLimits[TierLong], tierLimits[TierAncient])
@@ -342,6 +385,14 @@ func (w *DecayWorker) runSpaceBasedCompression(ctx context.Context) error {
 		tierLimit := tierLimits[currentTier]
 		triggerThreshold := int(float64(tierLimit) * w.storageLimits.CompressionTrigger)
 		
+		report.Transitions = append(report.Transitions, TierTransitionReport{
+			From:  currentTier,
+			To:    targetTier,
+			Count: currentCount,
+			Limit: tierLimit,
+		})
+		transition := &report.Transitions[len(report.Transitions)-1]
+		
 		// Check if compression is needed
 		if currentCount < triggerThreshold {
 			log.Printf("[DecayWorker] Tier %s: %d/%d (%.1f%%) - below trigger threshold (%d), skipping",
@@ -355,7 +406,7 @@ func (w *DecayWorker) runSpaceBasedCompression(ctx context.Context) error {
 			float64(currentCount)/float64(tierLimit)*100, triggerThreshold, targetTier)
 		
 		// Calculate target count (compress down to 80% of limit for breathing room)
-		targetCount := int(float64(tierLimit) * 0.80)
+		targetCount := int(float64(tierLimit) * compressionTargetRatio)
 		
 		// Select memories for compression based on scoring
 		candidates, err := w.selectMemoriesForCompression(ctx, currentTier, currentCount, tierLimit, targetCount)
@@ -368,9 +419,13 @@ func (w *DecayWorker) runSpaceBasedCompression(ctx context.Context) error {
 			log.Printf("[DecayWorker] No candidates selected for compression in tier %s", currentTier)
 			continue
 		}
+		transition.Candidates = len(candidates)
+		report.MemoriesExamined += len(candidates)
 		
 		// Compress candidates using cluster-based approach
 		compressed, clustered := w.compressMemoriesWithClusters(ctx, candidates, targetTier)
+		transition.Compressions = compressed
+		transition.Clustered = clustered
 		
 		log.Printf("[DecayWorker] %s -> %s complete: %d compressions (%d memories in clusters)",
 			currentTier, targetTier, compressed, clustered)
@@ -501,34 +556,6 @@ func (w *DecayWorker) compressMemoriesWithClusters(ctx context.Context, candidat
 	return compressed, clustered
 }
 
-// evolvePrinciplesPhase runs the principle and identity evolution process
-func (w *DecayWorker) evolvePrinciplesPhase(ctx context.Context) error {
-	// Sub-phase A: Evolve system identity (slot 0)
-	log.Printf("[DecayWorker] Sub-phase A: Identity evolution...")
-		if err := EvolveIdentity(w.db, w.storage, w.embedder, w.llmURL, w.llmModel, w.llmClient); err != nil {
-		log.Printf("[DecayWorker] ERROR evolving identity: %v", err)
-		// Non-fatal, continue to principle evolution
-	}
-	
-	// Sub-phase B: Extract principle candidates from memory patterns
-	log.Printf("[DecayWorker] Sub-phase B: Principle extraction...")
-	candidates, err := ExtractPrinciples(w.db, w.storage, w.embedder, w.minRatingThreshold, w.extractionLimit, w.llmURL, w.llmModel, w.llmClient)
-	if err != nil {
-		return err
-	}
-
-	if len(candidates) == 0 {
-		log.Printf("[DecayWorker] No principle candidates found")
-		return nil
-	}
-
-	log.Printf("[DecayWorker] Found %d principle candidates", len(candidates))
-
-    // Sub-phase C: Evolve principles (update slots 4-10 with best candidates)
-    log.Printf("[DecayWorker] Sub-phase C: Principle evolution...")
-    return EvolvePrinciples(w.db, w.storage, w.embedder, candidates, w.minRatingThreshold, w.llmURL, w.llmSmallURL, w.llmSmallModel, w.llmClient)
-}
-
 // compressTierWithClusters finds and compresses memories using cluster-based approach
 func (w *DecayWorker) compressTierWithClusters(ctx context.Context, fromTier, toTier MemoryTier, baseAgeDays int, mergeWindowDays int) {
 	log.Printf("[DecayWorker] Processing %s -> %s (base age: %d days, merge window: %d days)",
@@ -688,8 +715,11 @@ func (w *DecayWorker) calculateAdjustedAge(memory *Memory, baseAgeDays int) floa
 	// accessMod of 1.5 means each access adds 1.5x protection
 	accessFactor := 1.0 + (math.Log1p(float64(memory.AccessCount)) * w.accessMod)
 
+	// Recency modifier: a memory retrieved lately is still in use, so it ages slower still
+	recencyFactor := 1.0 + (accessRecency(memory, time.Now()) * w.accessMod)
+
 	// Combined modifier
-	protectionFactor := importanceFactor * accessFactor
+	protectionFactor := importanceFactor * accessFactor * recencyFactor
 
 	// Adjusted age = real age / protection factor
 	// Higher protection = lower adjusted age = less likely to compress
@@ -698,6 +728,22 @@ func (w *DecayWorker) calculateAdjustedAge(memory *Memory, baseAgeDays int) floa
 	return adjustedAge
 }
 
+// accessRecencyHalfLifeDays controls how quickly the benefit of a recent retrieval fades
+const accessRecencyHalfLifeDays = 14.0
+
+// accessRecency is 1.0 for a memory retrieved just now, halving every 14 days. Memories
+// never retrieved score 0 (their last_accessed_at is just the creation time).
+func accessRecency(memory *Memory, now time.Time) float64 {
+	if memory.AccessCount == 0 || memory.LastAccessedAt.IsZero() {
+		return 0
+	}
+	days := now.Sub(memory.LastAccessedAt).Hours() / 24.0
+	if days < 0 {
+		days = 0
+	}
+	return math.Pow(0.5, days/accessRecencyHalfLifeDays)
+}
+
 // calculateCompressionScore computes a score for prioritizing memories for compression
 // Higher score = more likely to be compressed
 // Factors: age (older = higher), importance (lower = higher), access (less = higher)
@@ -715,7 +761,9 @@ func (w *DecayWorker) calculateCompressionScore(memory *Memory, weights struct{
 	// 3. Access component (inverted: low access = high score)
 	// Use logarithmic scale to handle high access counts
 	// Formula: 1 / (1 + log(1 + access_count))
+	// Recent retrieval halves it at most, so frequently *and* recently used memories stay
 	accessComponent := 1.0 / (1.0 + math.Log1p(float64(memory.AccessCount)))
+	accessComponent *= 1.0 - 0.5*accessRecency(memory, now)
 	
 	// Weighted sum
 	score := (weights.Age * normalizedAge) +
@@ -725,6 +773,15 @@ func (w *DecayWorker) calculateCompressionScore(memory *Memory, weights struct{
 	return score
 }
 
+// compressionScore scores a memory with the worker's configured weights
+func (w *DecayWorker) compressionScore(memory *Memory) float64 {
+	return w.calculateCompressionScore(memory, struct{ Age, Importance, Access float64 }{
+		Age:        w.compressionWeights.Age,
+		Importance: w.compressionWeights.Importance,
+		Access:     w.compressionWeights.Access,
+	})
+}
+
 // selectMemoriesForCompression chooses which memories to compress based on space limits
 // Returns memories sorted by compression score (highest first)
 func (w *DecayWorker) selectMemoriesForCompression(
@@ -772,12 +829,7 @@ func (w *DecayWorker) selectMemoriesForCompression(
 	
 	scored := make([]scoredMemory, len(memories))
 	for i, mem := range memories {
-		score := w.calculateCompressionScore(&mem, struct{ Age, Importance, Access float64 }{
-			Age:        w.compressionWeights.Age,
-			Importance: w.compressionWeights.Importance,
-			Access:     w.compressionWeights.Access,
-		})
-		scored[i] = scoredMemory{memory: mem, score: score}
+		scored[i] = scoredMemory{memory: mem, score: w.compressionScore(&mem)}
 	}
 	
 	// Sort by score (highest first = most compressible)
@@ -980,6 +1032,7 @@ func (w *DecayWorker) recalculateTrustScores(ctx context.Context) error {
 			// Process each memory
 			for i := range newMemories {
 				mem := &newMemories[i]
+				w.cleanConceptTags(ctx, mem)
 				
 				// Skip if no validations yet
 				if mem.ValidationCount == 0 {
@@ -1039,6 +1092,21 @@ func (w *DecayWorker) recalculateTrustScores(ctx context.Context) error {
 	return nil
 }
 
+// cleanConceptTags rewrites tags stored before they were validated (e.g. "what" or
+// "how" cut from research questions) when the trust pass comes across them
+func (w *DecayWorker) cleanConceptTags(ctx context.Context, mem *Memory) {
+	valid := ValidateConceptTags(mem.ConceptTags)
+	if !conceptTagsChanged(mem.ConceptTags, valid) {
+		return
+	}
+	if err := w.storage.UpdateConceptTags(ctx, mem.ID, valid, ""); err != nil {
+		log.Printf("[TrustCalc] WARNING: Failed to clean concept tags of memory %s: %v", mem.ID, err)
+		return
+	}
+	log.Printf("[TrustCalc]   Memory %s: concept tags %v -> %v", mem.ID, mem.ConceptTags, valid)
+	mem.ConceptTags = valid
+}
+
 // abs returns absolute value of a float64
 func abs(x float64) float64 {
 	if x < 0 {
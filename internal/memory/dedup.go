@@ -0,0 +1,158 @@
+// internal/memory/dedup.go
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// DefaultDedupThreshold is the cosine similarity above which a new collective
+// memory is considered a near-duplicate of an existing one
+const DefaultDedupThreshold = 0.93
+
+// dedupCandidateLimit is how many nearest neighbours are checked before insert
+const dedupCandidateLimit = 5
+
+// StoreDecision records what StoreWithDedup did with a memory
+type StoreDecision string
+
+const (
+	StoreDecisionInserted StoreDecision = "inserted" // New point written
+	StoreDecisionMerged   StoreDecision = "merged"   // Folded into an existing near-duplicate
+)
+
+// StoreResult describes the outcome of a deduplicating store
+type StoreResult struct {
+	Decision   StoreDecision `json:"decision"`
+	MemoryID   string        `json:"memory_id"`  // ID of the inserted memory, or of the existing memory it was merged into
+	Similarity float64       `json:"similarity"` // Similarity to the matched memory (0 when inserted)
+}
+
+// StoreWithDedup stores a collective memory unless a near-identical collective
+// memory already exists. On a hit the existing memory is reinforced instead:
+// its ValidationCount is bumped, LastAccessedAt refreshed and concept tags merged.
+// A threshold <= 0 uses DefaultDedupThreshold; a threshold > 1 disables the check.
+func (s *Storage) StoreWithDedup(ctx context.Context, memory *Memory, threshold float64) (*StoreResult, error) {
+	if threshold <= 0 {
+		threshold = DefaultDedupThreshold
+	}
+
+	// Only collective memories are deduplicated - personal memories belong to one user
+	if memory.IsCollective && threshold <= 1.0 && len(memory.Embedding) > 0 {
+		candidates, err := s.findDedupCandidates(ctx, memory.Embedding)
+		if err != nil {
+			// Dedup is best-effort: fall through to a normal insert
+			log.Printf("[Storage] WARNING: Dedup lookup failed, inserting without check: %v", err)
+		} else if existing, similarity := findNearDuplicate(candidates, memory.Embedding, threshold); existing != nil {
+			mergeDuplicateInto(existing, memory, time.Now())
+
+			if err := s.UpdateMemory(ctx, existing); err != nil {
+				return nil, fmt.Errorf("failed to merge into existing memory %s: %w", existing.ID, err)
+			}
+
+			return &StoreResult{
+				Decision:   StoreDecisionMerged,
+				MemoryID:   existing.ID,
+				Similarity: similarity,
+			}, nil
+		}
+	}
+
+	if err := s.Store(ctx, memory); err != nil {
+		return nil, err
+	}
+
+	return &StoreResult{
+		Decision: StoreDecisionInserted,
+		MemoryID: memory.ID,
+	}, nil
+}
+
+// findDedupCandidates returns the nearest collective memories (with vectors)
+func (s *Storage) findDedupCandidates(ctx context.Context, embedding []float32) ([]Memory, error) {
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			{
+				ConditionOneOf: &qdrant.Condition_Field{
+					Field: &qdrant.FieldCondition{
+						Key: "is_collective",
+						Match: &qdrant.Match{
+							MatchValue: &qdrant.Match_Boolean{Boolean: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	searchResult, err := s.Client.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: s.CollectionName,
+		Query:          qdrant.NewQuery(embedding...),
+		Filter:         filter,
+		Limit:          uint64Ptr(dedupCandidateLimit),
+		WithPayload:    qdrant.NewWithPayload(true),
+		WithVectors: &qdrant.WithVectorsSelector{
+			SelectorOptions: &qdrant.WithVectorsSelector_Enable{
+				Enable: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dedup search failed: %w", err)
+	}
+
+	memories := make([]Memory, 0, len(searchResult))
+	for _, point := range searchResult {
+		memory := s.pointToMemory(point)
+		if vectors := point.Vectors.GetVector(); vectors != nil {
+			memory.Embedding = vectors.Data
+		}
+		memories = append(memories, memory)
+	}
+
+	return memories, nil
+}
+
+// findNearDuplicate returns the most similar candidate at or above threshold.
+// Similarity is recomputed locally so the decision does not depend on the
+// score semantics of the vector store.
+func findNearDuplicate(candidates []Memory, embedding []float32, threshold float64) (*Memory, float64) {
+	var best *Memory
+	bestScore := 0.0
+
+	for i := range candidates {
+		score := cosineSimilarity(candidates[i].Embedding, embedding)
+		if score >= threshold && score > bestScore {
+			best = &candidates[i]
+			bestScore = score
+		}
+	}
+
+	return best, bestScore
+}
+
+// mergeDuplicateInto reinforces an existing memory with a near-duplicate
+func mergeDuplicateInto(existing *Memory, incoming *Memory, now time.Time) {
+	existing.ValidationCount++
+	existing.LastAccessedAt = now
+
+	seen := make(map[string]bool, len(existing.ConceptTags))
+	for _, tag := range existing.ConceptTags {
+		seen[tag] = true
+	}
+	for _, tag := range incoming.ConceptTags {
+		if !seen[tag] {
+			existing.ConceptTags = append(existing.ConceptTags, tag)
+			seen[tag] = true
+		}
+	}
+
+	// Keep the stronger signal of the two
+	if incoming.ImportanceScore > existing.ImportanceScore {
+		existing.ImportanceScore = incoming.ImportanceScore
+	}
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// unitVectorAt returns a 2-D unit vector whose cosine similarity to (1, 0) equals sim
+func unitVectorAt(sim float64) []float32 {
+	return []float32{float32(sim), float32(math.Sqrt(1 - sim*sim))}
+}
+
+func TestFindNearDuplicate_Threshold(t *testing.T) {
+	query := []float32{1, 0}
+
+	tests := []struct {
+		name      string
+		sim       float64
+		wantMatch bool
+	}{
+		{"well below threshold", 0.80, false},
+		{"just below threshold", 0.92, false},
+		{"just above threshold", 0.94, true},
+		{"identical", 1.0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []Memory{{ID: "a", Embedding: unitVectorAt(tt.sim)}}
+			got, score := findNearDuplicate(candidates, query, DefaultDedupThreshold)
+			if (got != nil) != tt.wantMatch {
+				t.Fatalf("sim %.2f: match = %v, want %v", tt.sim, got != nil, tt.wantMatch)
+			}
+			if got != nil && math.Abs(score-tt.sim) > 1e-4 {
+				t.Errorf("score = %.4f, want %.4f", score, tt.sim)
+			}
+		})
+	}
+}
+
+func TestFindNearDuplicate_PicksMostSimilar(t *testing.T) {
+	candidates := []Memory{
+		{ID: "close", Embedding: unitVectorAt(0.95)},
+		{ID: "closest", Embedding: unitVectorAt(0.99)},
+		{ID: "far", Embedding: unitVectorAt(0.50)},
+	}
+
+	got, _ := findNearDuplicate(candidates, []float32{1, 0}, DefaultDedupThreshold)
+	if got == nil || got.ID != "closest" {
+		t.Fatalf("expected 'closest', got %+v", got)
+	}
+}
+
+func TestMergeDuplicateInto(t *testing.T) {
+	now := time.Now()
+	existing := &Memory{
+		ID:              "existing",
+		ValidationCount: 2,
+		ImportanceScore: 0.5,
+		ConceptTags:     []string{"learning", "strategy"},
+		LastAccessedAt:  now.Add(-48 * time.Hour),
+	}
+	incoming := &Memory{
+		ImportanceScore: 0.8,
+		ConceptTags:     []string{"learning", "search"},
+	}
+
+	mergeDuplicateInto(existing, incoming, now)
+
+	if existing.ValidationCount != 3 {
+		t.Errorf("ValidationCount = %d, want 3", existing.ValidationCount)
+	}
+	if !existing.LastAccessedAt.Equal(now) {
+		t.Errorf("LastAccessedAt not refreshed")
+	}
+	if existing.ImportanceScore != 0.8 {
+		t.Errorf("ImportanceScore = %.2f, want 0.80", existing.ImportanceScore)
+	}
+	want := []string{"learning", "strategy", "search"}
+	if len(existing.ConceptTags) != len(want) {
+		t.Fatalf("ConceptTags = %v, want %v", existing.ConceptTags, want)
+	}
+	for i := range want {
+		if existing.ConceptTags[i] != want[i] {
+			t.Errorf("ConceptTags = %v, want %v", existing.ConceptTags, want)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 	"io"
 	"net/http"
+
+	"go-llama/internal/httpclient"
 )
 
 // Embedder generates vector embeddings from text
@@ -21,9 +23,7 @@ type Embedder struct {
 func NewEmbedder(apiURL string) *Embedder {
 	return &Embedder{
 		apiURL: apiURL,
-		client: &http.Client{
-			Timeout: 15 * time.Second, // Reasonable timeout for embedding generation
-		},
+		client: httpclient.New(15 * time.Second), // Reasonable timeout for embedding generation
 	}
 }
 
@@ -73,3 +73,74 @@ func (e *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
 
 	return result.Data[0].Embedding, nil
 }
+
+// Ping checks that the embedding server answers with a non-empty embedding
+func (e *Embedder) Ping(ctx context.Context) error {
+	embedding, err := e.Embed(ctx, "ping")
+	if err != nil {
+		return err
+	}
+	if len(embedding) == 0 {
+		return fmt.Errorf("empty embedding returned")
+	}
+	return nil
+}
+
+// EmbedBatch converts several texts to embeddings in one request. The result is in
+// the order of texts.
+func (e *Embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	reqBody := map[string]interface{}{
+		"input": texts,
+		"model": "text-embedding-ada-002",
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Data))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) || embeddings[item.Index] != nil {
+			return nil, fmt.Errorf("invalid embedding index %d", item.Index)
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,298 @@
+// internal/memory/eviction.go
+package memory
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	evictionPageSize   = 500 // Memories fetched per scroll page
+	evictionSampleSize = 20  // Candidates listed in a report
+)
+
+// Protected categories are never evicted automatically
+const (
+	EvictionProtectedPrinciples   = "principles"    // Principles tier or principle-tagged memories
+	EvictionProtectedDigest       = "daily_digest"  // Periodic digests used by reflection
+	EvictionProtectedUserResearch = "user_research" // Research syntheses for goals a user asked for
+)
+
+// digestConceptTag matches dialogue.DigestTag (memory cannot import dialogue)
+const digestConceptTag = "daily_digest"
+
+// Eviction score weights; higher scores are evicted first
+const (
+	evictionWeightTier   = 0.40
+	evictionWeightTrust  = 0.25
+	evictionWeightAccess = 0.20
+	evictionWeightAge    = 0.15
+)
+
+// evictionTierScore ranks tiers so ancient memories go first
+var evictionTierScore = map[MemoryTier]float64{
+	TierAncient: 1.0,
+	TierLong:    0.66,
+	TierMedium:  0.33,
+	TierRecent:  0.0,
+}
+
+// EvictionCandidate is a lightweight view of a memory chosen for eviction
+type EvictionCandidate struct {
+	ID          string     `json:"id"`
+	Content     string     `json:"content"` // First 80 characters
+	Tier        MemoryTier `json:"tier"`
+	Category    string     `json:"category"`
+	TrustScore  float64    `json:"trust_score"`
+	AccessCount int        `json:"access_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Score       float64    `json:"score"`
+}
+
+// EvictionReport describes one collective memory cap check. In a dry run Evicted and
+// EvictedByCategory describe what would be deleted.
+type EvictionReport struct {
+	Cap                 int                 `json:"cap"`
+	CountBefore         int                 `json:"count_before"`
+	Excess              int                 `json:"excess"`
+	Evicted             int                 `json:"evicted"`
+	EvictedByCategory   map[string]int      `json:"evicted_by_category"`
+	ProtectedByCategory map[string]int      `json:"protected_by_category"` // Protected memories skipped
+	DryRun              bool                `json:"dry_run"`
+	Sample              []EvictionCandidate `json:"sample"` // Highest-scoring candidates first
+}
+
+// protectedCategory returns the protected category of a memory, or "" if it may be evicted
+func protectedCategory(mem *Memory) string {
+	if mem.Tier == TierPrinciples {
+		return EvictionProtectedPrinciples
+	}
+	for _, tag := range mem.ConceptTags {
+		switch tag {
+		case "principle", "principles":
+			return EvictionProtectedPrinciples
+		case digestConceptTag:
+			return EvictionProtectedDigest
+		}
+	}
+	// User-requested goals carry their requester, which the synthesis inherits
+	if researchType, _ := mem.Metadata["research_type"].(string); researchType == "synthesis" {
+		if len(SourceUserIDsFromMetadata(mem.Metadata)) > 0 {
+			return EvictionProtectedUserResearch
+		}
+	}
+	return ""
+}
+
+// evictionCategory groups evictable memories by their primary concept tag
+func evictionCategory(mem *Memory) string {
+	if len(mem.ConceptTags) > 0 && mem.ConceptTags[0] != "" {
+		return mem.ConceptTags[0]
+	}
+	return "untagged"
+}
+
+// evictionScore combines tier (ancient first), low trust, low access and age into 0.0-1.0
+func evictionScore(mem *Memory, now time.Time) float64 {
+	trust := mem.TrustScore
+	if trust < 0 {
+		trust = 0
+	} else if trust > 1 {
+		trust = 1
+	}
+	access := 1.0 / float64(1+mem.AccessCount)
+	age := now.Sub(mem.CreatedAt).Hours() / 24 / 365
+	if age < 0 {
+		age = 0
+	} else if age > 1 {
+		age = 1
+	}
+
+	return evictionWeightTier*evictionTierScore[mem.Tier] +
+		evictionWeightTrust*(1-trust) +
+		evictionWeightAccess*access +
+		evictionWeightAge*age
+}
+
+// candidateHeap is a min-heap on score, so the weakest of the kept candidates is on top
+type candidateHeap []EvictionCandidate
+
+func (h candidateHeap) Len() int            { return len(h) }
+func (h candidateHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h candidateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x interface{}) { *h = append(*h, x.(EvictionCandidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// evictionSelector keeps the limit highest-scoring evictable memories seen so far and
+// counts protected ones. Only limit candidates are held regardless of collection size.
+type evictionSelector struct {
+	limit     int
+	now       time.Time
+	kept      candidateHeap
+	protected map[string]int
+}
+
+func newEvictionSelector(limit int, now time.Time) *evictionSelector {
+	return &evictionSelector{limit: limit, now: now, protected: map[string]int{}}
+}
+
+func (s *evictionSelector) add(mem *Memory) {
+	if category := protectedCategory(mem); category != "" {
+		s.protected[category]++
+		return
+	}
+	if s.limit <= 0 {
+		return
+	}
+
+	candidate := EvictionCandidate{
+		ID:          mem.ID,
+		Content:     truncate(mem.Content, previewContentLength),
+		Tier:        mem.Tier,
+		Category:    evictionCategory(mem),
+		TrustScore:  mem.TrustScore,
+		AccessCount: mem.AccessCount,
+		CreatedAt:   mem.CreatedAt,
+		Score:       evictionScore(mem, s.now),
+	}
+	if len(s.kept) < s.limit {
+		heap.Push(&s.kept, candidate)
+	} else if candidate.Score > s.kept[0].Score {
+		s.kept[0] = candidate
+		heap.Fix(&s.kept, 0)
+	}
+}
+
+// selected returns the kept candidates, highest score first
+func (s *evictionSelector) selected() []EvictionCandidate {
+	out := append([]EvictionCandidate{}, s.kept...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// CollectiveCap returns the configured collective memory ceiling (0 = unlimited)
+func (w *DecayWorker) CollectiveCap() int {
+	return w.storageLimits.MaxCollectiveMemories
+}
+
+// PreviewEviction reports which collective memories would be evicted under the given
+// cap without deleting anything
+func (w *DecayWorker) PreviewEviction(ctx context.Context, ceiling int) (*EvictionReport, error) {
+	report, _, err := w.planEviction(ctx, ceiling)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = true
+	return report, nil
+}
+
+// planEviction counts collective memories and, when over cap, selects the excess
+func (w *DecayWorker) planEviction(ctx context.Context, ceiling int) (*EvictionReport, []EvictionCandidate, error) {
+	report := &EvictionReport{
+		Cap:                 ceiling,
+		EvictedByCategory:   map[string]int{},
+		ProtectedByCategory: map[string]int{},
+		Sample:              []EvictionCandidate{},
+	}
+
+	count, err := w.storage.CountCollectiveMemories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.CountBefore = count
+	if ceiling <= 0 || count <= ceiling {
+		return report, nil, nil
+	}
+	report.Excess = count - ceiling
+
+	selector := newEvictionSelector(report.Excess, time.Now())
+	err = w.storage.ScrollCollectiveMemories(ctx, evictionPageSize, func(page []Memory) bool {
+		for i := range page {
+			selector.add(&page[i])
+		}
+		return ctx.Err() == nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("eviction scan failed: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	candidates := selector.selected()
+	report.ProtectedByCategory = selector.protected
+	report.Evicted = len(candidates)
+	for i, c := range candidates {
+		report.EvictedByCategory[c.Category]++
+		if i < evictionSampleSize {
+			report.Sample = append(report.Sample, c)
+		}
+	}
+	return report, candidates, nil
+}
+
+// enforceCollectiveCap evicts the lowest-value collective memories until the count is
+// back under the configured cap. Protected categories are never touched.
+func (w *DecayWorker) enforceCollectiveCap(ctx context.Context) (*EvictionReport, error) {
+	ceiling := w.storageLimits.MaxCollectiveMemories
+	if ceiling <= 0 {
+		return nil, nil
+	}
+
+	report, candidates, err := w.planEviction(ctx, ceiling)
+	if err != nil {
+		return nil, err
+	}
+	if report.Excess == 0 {
+		log.Printf("[DecayWorker] Collective memories: %d/%d - under cap, no eviction", report.CountBefore, ceiling)
+		return report, nil
+	}
+
+	report.Evicted = 0
+	report.EvictedByCategory = map[string]int{}
+	for _, c := range candidates {
+		if err := w.storage.DeleteMemory(ctx, c.ID); err != nil {
+			log.Printf("[DecayWorker] WARNING: Failed to evict memory %s: %v", c.ID, err)
+			continue
+		}
+		report.Evicted++
+		report.EvictedByCategory[c.Category]++
+	}
+
+	log.Printf("[DecayWorker] Collective memories: %d/%d - evicted %d of %d excess (%s); protected: %s",
+		report.CountBefore, ceiling, report.Evicted, report.Excess,
+		formatCategoryCounts(report.EvictedByCategory), formatCategoryCounts(report.ProtectedByCategory))
+	if report.Evicted < report.Excess {
+		log.Printf("[DecayWorker] WARNING: Still %d over the collective cap (protected memories are never evicted)",
+			report.Excess-report.Evicted)
+	}
+	return report, nil
+}
+
+// formatCategoryCounts renders counts as "a=1, b=2" in key order
+func formatCategoryCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, counts[k]))
+	}
+	return strings.Join(parts, ", ")
+}
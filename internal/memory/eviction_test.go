@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProtectedCategory(t *testing.T) {
+	cases := []struct {
+		name string
+		mem  Memory
+		want string
+	}{
+		{"principles tier", Memory{Tier: TierPrinciples}, EvictionProtectedPrinciples},
+		{"principle tag", Memory{Tier: TierAncient, ConceptTags: []string{"learning", "principle"}}, EvictionProtectedPrinciples},
+		{"digest", Memory{Tier: TierLong, ConceptTags: []string{"daily_digest", "learning"}}, EvictionProtectedDigest},
+		{"user research", Memory{Tier: TierAncient, Metadata: map[string]interface{}{
+			"research_type": "synthesis", MetadataSourceUserIDs: "7",
+		}}, EvictionProtectedUserResearch},
+		{"autonomous research", Memory{Tier: TierAncient, Metadata: map[string]interface{}{"research_type": "synthesis"}}, ""},
+		{"plain learning", Memory{Tier: TierAncient, ConceptTags: []string{"learning"}}, ""},
+	}
+	for _, tc := range cases {
+		if got := protectedCategory(&tc.mem); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEvictionScoreOrdering(t *testing.T) {
+	now := time.Now()
+	old := now.AddDate(-2, 0, 0)
+
+	ancientWeak := Memory{Tier: TierAncient, TrustScore: 0.2, AccessCount: 0, CreatedAt: old}
+	ancientStrong := Memory{Tier: TierAncient, TrustScore: 0.9, AccessCount: 40, CreatedAt: old}
+	recentWeak := Memory{Tier: TierRecent, TrustScore: 0.2, AccessCount: 0, CreatedAt: now}
+
+	if evictionScore(&ancientWeak, now) <= evictionScore(&ancientStrong, now) {
+		t.Error("expected low trust, rarely accessed memory to score higher than a trusted one")
+	}
+	if evictionScore(&ancientStrong, now) <= evictionScore(&recentWeak, now) {
+		t.Error("expected ancient tier to outrank a fresh recent memory")
+	}
+}
+
+func TestEvictionSelectorKeepsHighestScores(t *testing.T) {
+	now := time.Now()
+	selector := newEvictionSelector(2, now)
+
+	memories := []Memory{
+		{ID: "recent", Tier: TierRecent, TrustScore: 0.9, AccessCount: 10, CreatedAt: now, ConceptTags: []string{"search"}},
+		{ID: "ancient", Tier: TierAncient, TrustScore: 0.1, CreatedAt: now.AddDate(-1, 0, 0), ConceptTags: []string{"learning"}},
+		{ID: "digest", Tier: TierAncient, TrustScore: 0.0, CreatedAt: now.AddDate(-3, 0, 0), ConceptTags: []string{"daily_digest"}},
+		{ID: "long", Tier: TierLong, TrustScore: 0.3, CreatedAt: now.AddDate(0, -6, 0)},
+		{ID: "medium", Tier: TierMedium, TrustScore: 0.8, AccessCount: 3, CreatedAt: now.AddDate(0, -1, 0)},
+	}
+	for i := range memories {
+		selector.add(&memories[i])
+	}
+
+	selected := selector.selected()
+	if len(selected) != 2 || selected[0].ID != "ancient" || selected[1].ID != "long" {
+		t.Fatalf("expected [ancient long], got %+v", selected)
+	}
+	if selected[1].Category != "untagged" || selected[0].Category != "learning" {
+		t.Errorf("unexpected categories %q, %q", selected[0].Category, selected[1].Category)
+	}
+	if selector.protected[EvictionProtectedDigest] != 1 {
+		t.Errorf("expected the digest to be counted as protected, got %v", selector.protected)
+	}
+}
@@ -0,0 +1,368 @@
+// internal/memory/importer.go
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxImportLineBytes bounds one JSONL record; longer lines fail instead of stalling the reader
+const maxImportLineBytes = 1 << 20
+
+// ImportRecord is one line of a memory import file
+type ImportRecord struct {
+	Content      string    `json:"content"`
+	CreatedAt    time.Time `json:"created_at"`
+	UserID       string    `json:"user_id,omitempty"`
+	IsCollective bool      `json:"is_collective"`
+	Tags         []string  `json:"tags,omitempty"`
+	Importance   float64   `json:"importance,omitempty"` // 0-1; 0 uses 0.5
+}
+
+// Validate checks a record before anything is embedded or written
+func (r *ImportRecord) Validate(now time.Time) error {
+	if strings.TrimSpace(r.Content) == "" {
+		return errors.New("content is required")
+	}
+	if r.CreatedAt.IsZero() {
+		return errors.New("created_at is required")
+	}
+	if r.CreatedAt.After(now) {
+		return fmt.Errorf("created_at %s is in the future", r.CreatedAt.Format(time.RFC3339))
+	}
+	if !r.IsCollective && strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required for personal memories")
+	}
+	if r.Importance < 0 || r.Importance > 1 {
+		return fmt.Errorf("importance %.2f is outside 0-1", r.Importance)
+	}
+	return nil
+}
+
+// TierFor returns the tier a memory created at createdAt has reached by now. A zero
+// threshold disables that transition.
+func (r TierRules) TierFor(createdAt, now time.Time) MemoryTier {
+	ageDays := int(now.Sub(createdAt).Hours() / 24)
+	switch {
+	case r.LongToAncientDays > 0 && ageDays >= r.LongToAncientDays:
+		return TierAncient
+	case r.MediumToLongDays > 0 && ageDays >= r.MediumToLongDays:
+		return TierLong
+	case r.RecentToMediumDays > 0 && ageDays >= r.RecentToMediumDays:
+		return TierMedium
+	default:
+		return TierRecent
+	}
+}
+
+// ImportConfig controls a memory import
+type ImportConfig struct {
+	BatchSize      int       // Records embedded per request (and per checkpoint)
+	PerMinute      int       // Maximum records embedded per minute, bounding load on the embedder
+	DedupThreshold float64   // Passed to StoreWithDedup; <= 0 uses DefaultDedupThreshold
+	TierRules      TierRules // Assigns tiers from created_at
+	DryRun         bool      // Validate and report without embedding or writing
+	CheckpointPath string    // File recording the last imported line; "" disables resuming
+}
+
+// ImportCheckpoint is the persisted progress of an import, so a rerun resumes after
+// the last completed batch
+type ImportCheckpoint struct {
+	Line      int       `json:"line"` // Last line of the last completed batch
+	Imported  int       `json:"imported"`
+	Merged    int       `json:"merged"`
+	Failed    int       `json:"failed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ImportError is a record that could not be imported
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportReport is the result of one import run. Counts cover this run only; the
+// checkpoint keeps the totals of a resumed import.
+type ImportReport struct {
+	DryRun    bool               `json:"dry_run"`
+	ResumedAt int                `json:"resumed_at,omitempty"` // Line the run continued after
+	Lines     int                `json:"lines"`                // Non-blank lines read this run
+	Valid     int                `json:"valid"`
+	Imported  int                `json:"imported"` // Inserted as new memories
+	Merged    int                `json:"merged"`   // Folded into an existing near-duplicate
+	Failed    int                `json:"failed"`
+	Tiers     map[MemoryTier]int `json:"tiers"`
+	Duration  time.Duration      `json:"duration_ns"`
+	Errors    []ImportError      `json:"errors,omitempty"`
+}
+
+// importStore and importEmbedder are the parts of Storage and Embedder the importer uses
+type importStore interface {
+	StoreWithDedup(ctx context.Context, memory *Memory, threshold float64) (*StoreResult, error)
+}
+
+type importEmbedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Importer loads legacy memories and conversation history from JSONL into the store
+type Importer struct {
+	store    importStore
+	embedder importEmbedder
+	config   ImportConfig
+	now      func() time.Time
+}
+
+// NewImporter creates an importer. Storage and embedder may be nil for a dry run.
+func NewImporter(storage *Storage, embedder *Embedder, config ImportConfig) *Importer {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 32
+	}
+	if config.PerMinute <= 0 {
+		config.PerMinute = 600
+	}
+	imp := &Importer{config: config, now: time.Now}
+	if storage != nil {
+		imp.store = storage
+	}
+	if embedder != nil {
+		imp.embedder = embedder
+	}
+	return imp
+}
+
+// pendingRecord is a valid record waiting for its batch to be embedded
+type pendingRecord struct {
+	line   int
+	record ImportRecord
+}
+
+// Import reads JSONL records from r, skipping lines up to the checkpoint, and stores
+// them in batches. Invalid lines and failed records are reported, not fatal; an error
+// is only returned when reading or checkpointing fails or ctx is cancelled.
+func (imp *Importer) Import(ctx context.Context, r io.Reader) (*ImportReport, error) {
+	start := time.Now()
+	report := &ImportReport{DryRun: imp.config.DryRun, Tiers: map[MemoryTier]int{}}
+	if !imp.config.DryRun && (imp.store == nil || imp.embedder == nil) {
+		return nil, errors.New("storage and embedder are required unless dry-running")
+	}
+
+	checkpoint, err := imp.loadCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint.Line > 0 {
+		report.ResumedAt = checkpoint.Line
+		log.Printf("[Import] Resuming after line %d (%d imported, %d merged, %d failed so far)",
+			checkpoint.Line, checkpoint.Imported, checkpoint.Merged, checkpoint.Failed)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineBytes)
+	now := imp.now()
+	batch := make([]pendingRecord, 0, imp.config.BatchSize)
+	line := 0
+	var lastBatch time.Time
+
+	flush := func() error {
+		if len(batch) > 0 && !imp.config.DryRun {
+			if wait := imp.batchInterval(len(batch)) - time.Since(lastBatch); wait > 0 && !lastBatch.IsZero() {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			lastBatch = time.Now()
+			imp.storeBatch(ctx, batch, now, report, checkpoint)
+		}
+		batch = batch[:0]
+
+		// Every line up to here was attempted, so a rerun continues after it
+		checkpoint.Line = line
+		if err := imp.saveCheckpoint(checkpoint); err != nil {
+			return err
+		}
+		log.Printf("[Import] Progress: line %d, %d valid, %d imported, %d merged, %d failed",
+			line, report.Valid, report.Imported, report.Merged, report.Failed)
+		return nil
+	}
+
+	for scanner.Scan() {
+		line++
+		if line <= checkpoint.Line {
+			continue
+		}
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		report.Lines++
+
+		var record ImportRecord
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			imp.recordFailure(report, checkpoint, line, fmt.Errorf("invalid JSON: %w", err))
+			continue
+		}
+		if err := record.Validate(now); err != nil {
+			imp.recordFailure(report, checkpoint, line, err)
+			continue
+		}
+		report.Valid++
+		report.Tiers[imp.config.TierRules.TierFor(record.CreatedAt, now)]++
+
+		batch = append(batch, pendingRecord{line: line, record: record})
+		if len(batch) >= imp.config.BatchSize {
+			if err := flush(); err != nil {
+				return imp.finish(report, start), err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return imp.finish(report, start), fmt.Errorf("failed to read line %d: %w", line+1, err)
+	}
+	if err := flush(); err != nil {
+		return imp.finish(report, start), err
+	}
+	return imp.finish(report, start), nil
+}
+
+// batchInterval is the minimum time between batches of size n at the configured rate
+func (imp *Importer) batchInterval(n int) time.Duration {
+	return time.Minute * time.Duration(n) / time.Duration(imp.config.PerMinute)
+}
+
+// storeBatch embeds a batch in one request, falling back to one request per record
+// if the batch fails, and stores each record with dedup
+func (imp *Importer) storeBatch(ctx context.Context, batch []pendingRecord, now time.Time, report *ImportReport, checkpoint *ImportCheckpoint) {
+	texts := make([]string, len(batch))
+	for i, p := range batch {
+		texts[i] = p.record.Content
+	}
+	embeddings, err := imp.embedder.EmbedBatch(ctx, texts)
+	if err != nil {
+		log.Printf("[Import] WARNING: Batch embedding failed, embedding records one by one: %v", err)
+		embeddings = nil
+	}
+
+	for i, p := range batch {
+		var embedding []float32
+		if embeddings != nil {
+			embedding = embeddings[i]
+		} else if embedding, err = imp.embedder.Embed(ctx, p.record.Content); err != nil {
+			imp.recordFailure(report, checkpoint, p.line, fmt.Errorf("failed to embed: %w", err))
+			continue
+		}
+
+		mem := imp.toMemory(p.record, embedding, now)
+		result, err := imp.store.StoreWithDedup(ctx, mem, imp.config.DedupThreshold)
+		if err != nil {
+			imp.recordFailure(report, checkpoint, p.line, err)
+			continue
+		}
+		if result.Decision == StoreDecisionMerged {
+			report.Merged++
+			checkpoint.Merged++
+		} else {
+			report.Imported++
+			checkpoint.Imported++
+		}
+	}
+}
+
+// toMemory builds the memory for a record, aged into its tier
+func (imp *Importer) toMemory(record ImportRecord, embedding []float32, now time.Time) *Memory {
+	importance := record.Importance
+	if importance == 0 {
+		importance = 0.5
+	}
+	mem := &Memory{
+		Content:         record.Content,
+		Tier:            imp.config.TierRules.TierFor(record.CreatedAt, now),
+		IsCollective:    record.IsCollective,
+		SourceKind:      SourceUserConversation,
+		CreatedAt:       record.CreatedAt,
+		LastAccessedAt:  record.CreatedAt,
+		ImportanceScore: importance,
+		Embedding:       embedding,
+		OutcomeTag:      "neutral",
+		TrustScore:      0.5,
+		ConceptTags:     record.Tags,
+		Metadata: map[string]interface{}{
+			"imported_at": now.Format(time.RFC3339),
+		},
+	}
+	if !record.IsCollective {
+		userID := record.UserID
+		mem.UserID = &userID
+	}
+	return mem
+}
+
+func (imp *Importer) recordFailure(report *ImportReport, checkpoint *ImportCheckpoint, line int, err error) {
+	report.Failed++
+	checkpoint.Failed++
+	report.Errors = append(report.Errors, ImportError{Line: line, Error: err.Error()})
+}
+
+// finish completes and logs the report
+func (imp *Importer) finish(report *ImportReport, start time.Time) *ImportReport {
+	report.Duration = time.Since(start)
+	mode := "Import"
+	if report.DryRun {
+		mode = "Dry run"
+	}
+	log.Printf("[Import] %s finished: lines=%d, valid=%d, imported=%d, merged=%d, failed=%d, took %s",
+		mode, report.Lines, report.Valid, report.Imported, report.Merged, report.Failed, report.Duration.Round(time.Second))
+	return report
+}
+
+// loadCheckpoint returns the saved checkpoint, or an empty one when there is none.
+// A dry run still reads it, so it previews what a resumed import would do.
+func (imp *Importer) loadCheckpoint() (*ImportCheckpoint, error) {
+	checkpoint := &ImportCheckpoint{}
+	if imp.config.CheckpointPath == "" {
+		return checkpoint, nil
+	}
+	data, err := os.ReadFile(imp.config.CheckpointPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse import checkpoint %s: %w", imp.config.CheckpointPath, err)
+	}
+	return checkpoint, nil
+}
+
+// saveCheckpoint writes the checkpoint through a temporary file, so an interrupted
+// write never leaves a truncated checkpoint. Dry runs write nothing.
+func (imp *Importer) saveCheckpoint(checkpoint *ImportCheckpoint) error {
+	if imp.config.CheckpointPath == "" || imp.config.DryRun {
+		return nil
+	}
+	checkpoint.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal import checkpoint: %w", err)
+	}
+	tmp := imp.config.CheckpointPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write import checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, imp.config.CheckpointPath); err != nil {
+		return fmt.Errorf("failed to save import checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeImportStore records stored memories; contents in merge are reported as merged
+type fakeImportStore struct {
+	stored []*Memory
+	merge  map[string]bool
+}
+
+func (s *fakeImportStore) StoreWithDedup(ctx context.Context, mem *Memory, threshold float64) (*StoreResult, error) {
+	s.stored = append(s.stored, mem)
+	if s.merge[mem.Content] {
+		return &StoreResult{Decision: StoreDecisionMerged, MemoryID: "existing"}, nil
+	}
+	return &StoreResult{Decision: StoreDecisionInserted, MemoryID: "new"}, nil
+}
+
+// fakeImportEmbedder fails batches when batchErr is set and single texts listed in fail
+type fakeImportEmbedder struct {
+	batchErr error
+	fail     map[string]bool
+	batches  int
+}
+
+func (e *fakeImportEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if e.fail[text] {
+		return nil, errors.New("embedding server error")
+	}
+	return []float32{1}, nil
+}
+
+func (e *fakeImportEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.batches++
+	if e.batchErr != nil {
+		return nil, e.batchErr
+	}
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i] = []float32{1}
+	}
+	return out, nil
+}
+
+var importNow = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func testImporter(config ImportConfig, store *fakeImportStore, embedder *fakeImportEmbedder) *Importer {
+	config.PerMinute = 1 << 30 // No waiting between batches
+	imp := NewImporter(nil, nil, config)
+	imp.now = func() time.Time { return importNow }
+	if store != nil {
+		imp.store = store
+	}
+	if embedder != nil {
+		imp.embedder = embedder
+	}
+	return imp
+}
+
+func importLine(content string, daysOld int, collective bool) string {
+	record := ImportRecord{
+		Content:      content,
+		CreatedAt:    importNow.AddDate(0, 0, -daysOld),
+		IsCollective: collective,
+	}
+	if !collective {
+		record.UserID = "7"
+	}
+	data, _ := json.Marshal(record)
+	return string(data)
+}
+
+func TestImportRecordValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		record ImportRecord
+		valid  bool
+	}{
+		{"personal", ImportRecord{Content: "hi", CreatedAt: importNow, UserID: "7"}, true},
+		{"collective", ImportRecord{Content: "hi", CreatedAt: importNow, IsCollective: true}, true},
+		{"no content", ImportRecord{Content: "  ", CreatedAt: importNow, IsCollective: true}, false},
+		{"no created_at", ImportRecord{Content: "hi", IsCollective: true}, false},
+		{"future", ImportRecord{Content: "hi", CreatedAt: importNow.Add(time.Hour), IsCollective: true}, false},
+		{"personal without user", ImportRecord{Content: "hi", CreatedAt: importNow}, false},
+		{"importance out of range", ImportRecord{Content: "hi", CreatedAt: importNow, IsCollective: true, Importance: 1.5}, false},
+	}
+	for _, c := range cases {
+		if err := c.record.Validate(importNow); (err == nil) != c.valid {
+			t.Errorf("%s: expected valid=%v, got %v", c.name, c.valid, err)
+		}
+	}
+}
+
+func TestTierRulesTierFor(t *testing.T) {
+	rules := TierRules{RecentToMediumDays: 7, MediumToLongDays: 30, LongToAncientDays: 180}
+	cases := map[int]MemoryTier{0: TierRecent, 6: TierRecent, 7: TierMedium, 29: TierMedium, 30: TierLong, 400: TierAncient}
+	for days, want := range cases {
+		if got := rules.TierFor(importNow.AddDate(0, 0, -days), importNow); got != want {
+			t.Errorf("%d days: expected %s, got %s", days, want, got)
+		}
+	}
+	if got := (TierRules{RecentToMediumDays: 7}).TierFor(importNow.AddDate(-2, 0, 0), importNow); got != TierMedium {
+		t.Errorf("expected disabled thresholds to be skipped, got %s", got)
+	}
+}
+
+func TestImportDryRunValidatesWithoutWriting(t *testing.T) {
+	input := strings.Join([]string{
+		importLine("recent chat", 1, false),
+		"",
+		"{not json",
+		importLine("old fact", 400, true),
+		`{"content": "no date", "is_collective": true}`,
+	}, "\n")
+	imp := testImporter(ImportConfig{
+		DryRun:    true,
+		TierRules: TierRules{RecentToMediumDays: 7, MediumToLongDays: 30, LongToAncientDays: 180},
+	}, nil, nil)
+
+	report, err := imp.Import(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Lines != 4 || report.Valid != 2 || report.Failed != 2 || report.Imported != 0 {
+		t.Errorf("unexpected counts %+v", report)
+	}
+	if report.Tiers[TierRecent] != 1 || report.Tiers[TierAncient] != 1 {
+		t.Errorf("unexpected tiers %v", report.Tiers)
+	}
+	if len(report.Errors) != 2 || report.Errors[0].Line != 3 || report.Errors[1].Line != 5 {
+		t.Errorf("expected errors for lines 3 and 5, got %+v", report.Errors)
+	}
+}
+
+func TestImportStoresBatchesWithDedup(t *testing.T) {
+	store := &fakeImportStore{merge: map[string]bool{"known fact": true}}
+	embedder := &fakeImportEmbedder{}
+	imp := testImporter(ImportConfig{BatchSize: 2, TierRules: TierRules{RecentToMediumDays: 7}}, store, embedder)
+
+	input := strings.Join([]string{
+		importLine("personal note", 10, false),
+		importLine("known fact", 1, true),
+		importLine("new fact", 1, true),
+	}, "\n")
+	report, err := imp.Import(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Imported != 2 || report.Merged != 1 || report.Failed != 0 {
+		t.Errorf("unexpected counts %+v", report)
+	}
+	if embedder.batches != 2 {
+		t.Errorf("expected 2 embedding batches, got %d", embedder.batches)
+	}
+	personal := store.stored[0]
+	if personal.UserID == nil || *personal.UserID != "7" || personal.IsCollective {
+		t.Errorf("expected a personal memory for user 7, got %+v", personal)
+	}
+	if personal.Tier != TierMedium || !personal.CreatedAt.Equal(importNow.AddDate(0, 0, -10)) || personal.ImportanceScore != 0.5 {
+		t.Errorf("expected the memory aged from created_at with default importance, got %+v", personal)
+	}
+}
+
+func TestImportFallsBackToSingleEmbeds(t *testing.T) {
+	store := &fakeImportStore{}
+	embedder := &fakeImportEmbedder{batchErr: errors.New("batch unsupported"), fail: map[string]bool{"bad": true}}
+	imp := testImporter(ImportConfig{BatchSize: 10}, store, embedder)
+
+	input := importLine("good", 1, true) + "\n" + importLine("bad", 1, true)
+	report, err := imp.Import(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Imported != 1 || report.Failed != 1 || len(report.Errors) != 1 || report.Errors[0].Line != 2 {
+		t.Errorf("expected one record imported and line 2 failed, got %+v", report)
+	}
+}
+
+func TestImportResumesFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	input := strings.Join([]string{
+		importLine("one", 1, true),
+		importLine("two", 1, true),
+		importLine("three", 1, true),
+	}, "\n")
+
+	first := &fakeImportStore{}
+	imp := testImporter(ImportConfig{BatchSize: 2, CheckpointPath: path}, first, &fakeImportEmbedder{})
+	if _, err := imp.Import(context.Background(), strings.NewReader(input)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected a checkpoint file: %v", err)
+	}
+	var checkpoint ImportCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil || checkpoint.Line != 3 || checkpoint.Imported != 3 {
+		t.Fatalf("unexpected checkpoint %+v (%v)", checkpoint, err)
+	}
+
+	// Lines appended after a completed import are the only ones read on the next run
+	second := &fakeImportStore{}
+	imp = testImporter(ImportConfig{BatchSize: 2, CheckpointPath: path}, second, &fakeImportEmbedder{})
+	report, err := imp.Import(context.Background(), strings.NewReader(input+"\n"+importLine("four", 1, true)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.ResumedAt != 3 || len(second.stored) != 1 || second.stored[0].Content != "four" {
+		t.Errorf("expected only line 4 imported after resuming, got %+v", report)
+	}
+}
+
+func TestImportRequiresStoreUnlessDryRun(t *testing.T) {
+	if _, err := NewImporter(nil, nil, ImportConfig{}).Import(context.Background(), strings.NewReader("")); err == nil {
+		t.Error("expected an error without storage and embedder")
+	}
+}
+
+func TestEmbedBatchOrdersByIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Input) != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [2]}, {"index": 0, "embedding": [1]}]}`))
+	}))
+	defer server.Close()
+
+	embeddings, err := NewEmbedder(server.URL).EmbedBatch(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 1 || embeddings[1][0] != 2 {
+		t.Errorf("expected embeddings in input order, got %v", embeddings)
+	}
+}
@@ -0,0 +1,90 @@
+// internal/memory/outcome_feedback.go
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// OutcomeSignal is user feedback on a chat reply, applied to the memories the reply used
+type OutcomeSignal string
+
+const (
+	SignalThumbsUp   OutcomeSignal = "thumbs_up"
+	SignalThumbsDown OutcomeSignal = "thumbs_down"
+	SignalCorrection OutcomeSignal = "correction" // The user corrected the reply in their next message
+)
+
+// outcomeSignalTrust is how far each signal moves a memory's trust score
+var outcomeSignalTrust = map[OutcomeSignal]float64{
+	SignalThumbsUp:   0.1,
+	SignalThumbsDown: -0.2,
+	SignalCorrection: -0.15,
+}
+
+// FeedbackEligible reports whether feedback on a reply revises this memory. Only the
+// system's own knowledge is revised; the user's chat history is what they said, not
+// a claim that can turn out wrong.
+func FeedbackEligible(mem *Memory) bool {
+	if mem.ID == "" {
+		return false
+	}
+	kind := mem.SourceKind
+	if kind == "" {
+		kind = InferSourceKind(*mem)
+	}
+	return kind != SourceUserConversation
+}
+
+// ApplyOutcomeSignal returns the outcome tag and trust score a memory should have after
+// a signal. A thumbs-down or correction marks it bad; a thumbs-up marks it good, except
+// that a bad memory only recovers to neutral.
+func ApplyOutcomeSignal(mem *Memory, signal OutcomeSignal) (string, float64) {
+	trust := mem.TrustScore + outcomeSignalTrust[signal]
+	if trust < 0 {
+		trust = 0
+	}
+	if trust > 1 {
+		trust = 1
+	}
+
+	switch signal {
+	case SignalThumbsUp:
+		if mem.GetOutcomeTag() == OutcomeBad {
+			return string(OutcomeNeutral), trust
+		}
+		return string(OutcomeGood), trust
+	default:
+		return string(OutcomeBad), trust
+	}
+}
+
+// ApplyOutcomeFeedback applies a signal to each eligible memory among memoryIDs and
+// returns how many were updated. Memories that no longer exist are skipped.
+func (s *Storage) ApplyOutcomeFeedback(ctx context.Context, memoryIDs []string, signal OutcomeSignal) (int, error) {
+	if _, ok := outcomeSignalTrust[signal]; !ok {
+		return 0, fmt.Errorf("unknown outcome signal %q", signal)
+	}
+	if len(memoryIDs) == 0 {
+		return 0, nil
+	}
+	memories, err := s.GetMemoriesByIDs(ctx, memoryIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for id, mem := range memories {
+		if !FeedbackEligible(mem) {
+			continue
+		}
+		tag, trust := ApplyOutcomeSignal(mem, signal)
+		if err := s.UpdateOutcome(ctx, id, tag, trust); err != nil {
+			log.Printf("[Storage] WARNING: Failed to apply %s to memory %s: %v", signal, id, err)
+			continue
+		}
+		updated++
+	}
+	return updated, nil
+}
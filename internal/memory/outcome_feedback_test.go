@@ -0,0 +1,45 @@
+package memory
+
+import "testing"
+
+func TestFeedbackEligible(t *testing.T) {
+	userID := "7"
+	cases := []struct {
+		name string
+		mem  Memory
+		want bool
+	}{
+		{"no id", Memory{Content: "CORE IDENTITY"}, false},
+		{"research synthesis", Memory{ID: "a", SourceKind: SourceResearchSynthesis}, true},
+		{"user conversation", Memory{ID: "b", SourceKind: SourceUserConversation}, false},
+		{"inferred user conversation", Memory{ID: "c", UserID: &userID}, false},
+	}
+	for _, c := range cases {
+		if got := FeedbackEligible(&c.mem); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestApplyOutcomeSignal(t *testing.T) {
+	cases := []struct {
+		name      string
+		mem       Memory
+		signal    OutcomeSignal
+		wantTag   OutcomeTag
+		wantTrust float64
+	}{
+		{"thumbs up", Memory{TrustScore: 0.5}, SignalThumbsUp, OutcomeGood, 0.6},
+		{"thumbs up on bad only recovers to neutral", Memory{TrustScore: 0.3, OutcomeTag: "bad"}, SignalThumbsUp, OutcomeNeutral, 0.4},
+		{"thumbs down", Memory{TrustScore: 0.5, OutcomeTag: "good"}, SignalThumbsDown, OutcomeBad, 0.3},
+		{"correction", Memory{TrustScore: 0.5}, SignalCorrection, OutcomeBad, 0.35},
+		{"trust floor", Memory{TrustScore: 0.1}, SignalThumbsDown, OutcomeBad, 0},
+		{"trust ceiling", Memory{TrustScore: 0.95}, SignalThumbsUp, OutcomeGood, 1},
+	}
+	for _, c := range cases {
+		tag, trust := ApplyOutcomeSignal(&c.mem, c.signal)
+		if OutcomeTag(tag) != c.wantTag || trust < c.wantTrust-1e-9 || trust > c.wantTrust+1e-9 {
+			t.Errorf("%s: expected %s/%.2f, got %s/%.2f", c.name, c.wantTag, c.wantTrust, tag, trust)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// internal/memory/personal_scope.go
+package memory
+
+import (
+	"sort"
+	"strings"
+)
+
+// MetadataSourceUserIDs is the metadata key listing (comma-separated) the users whose
+// personal memories a derived memory (synthesis, learning) was built from
+const MetadataSourceUserIDs = "source_user_ids"
+
+// PersonalSourceUserIDs returns the distinct, sorted owners of the personal
+// memories in a result set. Collective memories contribute nothing.
+func PersonalSourceUserIDs(results []RetrievalResult) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, r := range results {
+		if r.Memory.IsCollective || r.Memory.UserID == nil || *r.Memory.UserID == "" {
+			continue
+		}
+		if !seen[*r.Memory.UserID] {
+			seen[*r.Memory.UserID] = true
+			ids = append(ids, *r.Memory.UserID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ApplyPersonalProvenance records which users a derived memory came from and sets its
+// visibility accordingly. The memory stays collective only if every source user has
+// consented to collective sharing. Otherwise it becomes personal: owned by the source
+// user when there is exactly one, or owned by nobody (invisible to user-scoped
+// retrieval) when several users contributed.
+func ApplyPersonalProvenance(mem *Memory, sourceUserIDs []string, sharingConsent map[string]bool) {
+	if len(sourceUserIDs) == 0 {
+		return
+	}
+
+	if mem.Metadata == nil {
+		mem.Metadata = map[string]interface{}{}
+	}
+	mem.Metadata[MetadataSourceUserIDs] = strings.Join(sourceUserIDs, ",")
+
+	for _, id := range sourceUserIDs {
+		if !sharingConsent[id] {
+			mem.IsCollective = false
+			if len(sourceUserIDs) == 1 {
+				owner := sourceUserIDs[0]
+				mem.UserID = &owner
+			} else {
+				mem.UserID = nil
+			}
+			return
+		}
+	}
+}
+
+// SourceUserIDsFromMetadata parses the source user list written by ApplyPersonalProvenance
+func SourceUserIDsFromMetadata(metadata map[string]interface{}) []string {
+	raw, _ := metadata[MetadataSourceUserIDs].(string)
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+func strPtr(s string) *string { return &s }
+
+// userIDsInFilter collects every user_id keyword a filter can match
+func userIDsInFilter(f *qdrant.Filter) []string {
+	if f == nil {
+		return nil
+	}
+	ids := []string{}
+	for _, conds := range [][]*qdrant.Condition{f.Must, f.Should} {
+		for _, c := range conds {
+			field := c.GetField()
+			if field == nil || field.Key != "user_id" {
+				continue
+			}
+			if kw := field.GetMatch().GetKeyword(); kw != "" {
+				ids = append(ids, kw)
+			}
+			if kws := field.GetMatch().GetKeywords(); kws != nil {
+				ids = append(ids, kws.Strings...)
+			}
+		}
+	}
+	return ids
+}
+
+func TestBuildSearchFilterRestrictsToAllowedUsers(t *testing.T) {
+	filter, none := buildSearchFilter(RetrievalQuery{
+		IncludePersonal: true,
+		AllowedUserIDs:  []string{"1", "3"},
+	})
+	if none {
+		t.Fatal("expected a filter for a non-empty allow-list")
+	}
+	got := userIDsInFilter(filter)
+	if len(got) != 2 || got[0] != "1" || got[1] != "3" {
+		t.Errorf("expected user_id in [1 3], got %v", got)
+	}
+}
+
+func TestBuildSearchFilterEmptyAllowList(t *testing.T) {
+	// Personal-only with nobody allowed must match nothing, not everything
+	if _, none := buildSearchFilter(RetrievalQuery{IncludePersonal: true, AllowedUserIDs: []string{}}); !none {
+		t.Error("expected personal-only search with empty allow-list to match nothing")
+	}
+
+	// With collective included, it degrades to collective-only
+	filter, none := buildSearchFilter(RetrievalQuery{
+		IncludePersonal:   true,
+		IncludeCollective: true,
+		AllowedUserIDs:    []string{},
+	})
+	if none || filter == nil {
+		t.Fatal("expected collective filter")
+	}
+	if ids := userIDsInFilter(filter); len(ids) != 0 {
+		t.Errorf("expected no personal scope, got %v", ids)
+	}
+}
+
+func TestBuildSearchFilterUserOutsideAllowList(t *testing.T) {
+	_, none := buildSearchFilter(RetrievalQuery{
+		UserID:          strPtr("2"),
+		IncludePersonal: true,
+		AllowedUserIDs:  []string{"1"},
+	})
+	if !none {
+		t.Error("expected a non-consenting user's personal search to match nothing")
+	}
+}
+
+func TestApplyPersonalProvenanceNeverCollectiveWithoutConsent(t *testing.T) {
+	consent := map[string]bool{"1": true, "2": false}
+
+	cases := []struct {
+		name       string
+		sources    []string
+		collective bool
+		owner      *string
+	}{
+		{"all consenting", []string{"1"}, true, nil},
+		{"single non-consenting", []string{"2"}, false, strPtr("2")},
+		{"mixed sources", []string{"1", "2"}, false, nil},
+		{"unknown user", []string{"9"}, false, strPtr("9")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mem := &Memory{IsCollective: true}
+			ApplyPersonalProvenance(mem, tc.sources, consent)
+
+			if mem.IsCollective != tc.collective {
+				t.Errorf("IsCollective = %v, want %v", mem.IsCollective, tc.collective)
+			}
+			if (mem.UserID == nil) != (tc.owner == nil) || (mem.UserID != nil && *mem.UserID != *tc.owner) {
+				t.Errorf("unexpected owner %v", mem.UserID)
+			}
+			got := SourceUserIDsFromMetadata(mem.Metadata)
+			if len(got) != len(tc.sources) {
+				t.Errorf("expected sources %v recorded, got %v", tc.sources, got)
+			}
+		})
+	}
+}
+
+func TestPersonalSourceUserIDs(t *testing.T) {
+	results := []RetrievalResult{
+		{Memory: Memory{UserID: strPtr("2")}},
+		{Memory: Memory{UserID: strPtr("1")}},
+		{Memory: Memory{UserID: strPtr("2")}},
+		{Memory: Memory{IsCollective: true, UserID: strPtr("5")}},
+	}
+	got := PersonalSourceUserIDs(results)
+	if len(got) != 2 || got[0] != "1" || got[1] != "2" {
+		t.Errorf("expected [1 2], got %v", got)
+	}
+}
@@ -0,0 +1,224 @@
+// internal/memory/principle_history.go
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Slots that self-modification may touch. Slot 0 (identity) evolves through
+// EvolveIdentity and slots 1-3 are admin-owned.
+const (
+	MinModifiablePrincipleSlot = 4
+	MaxModifiablePrincipleSlot = 10
+)
+
+// Principle history change sources
+const (
+	PrincipleChangeSelfModification = "self_modification"
+	PrincipleChangeRollback         = "rollback"
+)
+
+var (
+	// ErrProtectedPrincipleSlot is returned for any attempt to modify admin slots 1-3
+	ErrProtectedPrincipleSlot = errors.New("principle slot is admin-protected and cannot be modified")
+	// ErrPrincipleVersionNotFound is returned when a rollback targets an unknown version
+	ErrPrincipleVersionNotFound = errors.New("principle version not found")
+)
+
+// PrincipleHistory records one content change to a principle slot.
+// Versions are numbered per slot starting at 1; version N's NewContent is the
+// slot's content after that change.
+type PrincipleHistory struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	Slot                int       `gorm:"not null;uniqueIndex:idx_principle_history_slot_version" json:"slot"`
+	Version             int       `gorm:"not null;uniqueIndex:idx_principle_history_slot_version" json:"version"`
+	OldContent          string    `gorm:"type:text" json:"old_content"`
+	NewContent          string    `gorm:"type:text" json:"new_content"`
+	Justification       string    `gorm:"type:text" json:"justification"`
+	SourceGoalID        string    `gorm:"index" json:"source_goal_id,omitempty"`
+	ValidationReasoning string    `gorm:"type:text" json:"validation_reasoning"`
+	Source              string    `gorm:"not null;default:'self_modification'" json:"source"` // "self_modification" or "rollback"
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (PrincipleHistory) TableName() string {
+	return "growerai_principle_history"
+}
+
+// PrincipleChange describes a validated modification to apply to a principle slot
+type PrincipleChange struct {
+	Slot                int
+	NewContent          string
+	Justification       string
+	SourceGoalID        string
+	ValidationReasoning string
+}
+
+// IsProtectedPrincipleSlot reports whether a slot is admin-owned (1-3)
+func IsProtectedPrincipleSlot(slot int) bool {
+	return slot >= 1 && slot <= 3
+}
+
+// validateModifiableSlot rejects any slot outside the self-modifiable range
+func validateModifiableSlot(slot int) error {
+	if IsProtectedPrincipleSlot(slot) {
+		return fmt.Errorf("slot %d: %w", slot, ErrProtectedPrincipleSlot)
+	}
+	if slot < MinModifiablePrincipleSlot || slot > MaxModifiablePrincipleSlot {
+		return fmt.Errorf("invalid slot number: %d (must be %d-%d)", slot, MinModifiablePrincipleSlot, MaxModifiablePrincipleSlot)
+	}
+	return nil
+}
+
+// ApplyPrincipleModification updates a principle slot and writes its history row
+// in a single transaction. Admin slots 1-3 are always rejected.
+func ApplyPrincipleModification(db *gorm.DB, change PrincipleChange) (*PrincipleHistory, error) {
+	if err := validateModifiableSlot(change.Slot); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(change.NewContent) == "" {
+		return nil, fmt.Errorf("new content for slot %d is empty", change.Slot)
+	}
+
+	var entry *PrincipleHistory
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		entry, err = writePrincipleChange(tx, change, PrincipleChangeSelfModification)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RollbackPrinciple restores a slot to the content it held at toVersion.
+// toVersion 0 restores the content that preceded the first recorded change.
+// The rollback itself is recorded as a new history version.
+func RollbackPrinciple(db *gorm.DB, slot int, toVersion int) (*PrincipleHistory, error) {
+	if err := validateModifiableSlot(slot); err != nil {
+		return nil, err
+	}
+	if toVersion < 0 {
+		return nil, fmt.Errorf("invalid version: %d", toVersion)
+	}
+
+	var entry *PrincipleHistory
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var target PrincipleHistory
+		lookup := toVersion
+		if toVersion == 0 {
+			lookup = 1
+		}
+		if err := tx.Where("slot = ? AND version = ?", slot, lookup).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("slot %d version %d: %w", slot, toVersion, ErrPrincipleVersionNotFound)
+			}
+			return fmt.Errorf("failed to load principle history: %w", err)
+		}
+
+		content := target.NewContent
+		if toVersion == 0 {
+			content = target.OldContent
+		}
+
+		var err error
+		entry, err = writePrincipleChange(tx, PrincipleChange{
+			Slot:          slot,
+			NewContent:    content,
+			Justification: fmt.Sprintf("Rollback to version %d", toVersion),
+		}, PrincipleChangeRollback)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ListPrincipleHistory returns history rows newest first. A negative slot lists all
+// slots; limit <= 0 returns every row.
+func ListPrincipleHistory(db *gorm.DB, slot int, limit int) ([]PrincipleHistory, error) {
+	query := db.Order("created_at DESC, id DESC")
+	if slot >= 0 {
+		query = query.Where("slot = ?", slot)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var entries []PrincipleHistory
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load principle history: %w", err)
+	}
+	return entries, nil
+}
+
+// LastSelfModification returns the dialogue engine's latest principle change, or
+// nil if there is none. Rollbacks are admin actions and do not count.
+func LastSelfModification(db *gorm.DB) (*PrincipleHistory, error) {
+	var entry PrincipleHistory
+	err := db.Where("source = ?", PrincipleChangeSelfModification).
+		Order("created_at DESC, id DESC").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load principle history: %w", err)
+	}
+	return &entry, nil
+}
+
+// writePrincipleChange updates the slot content and appends the next history version.
+// Must be called inside a transaction.
+func writePrincipleChange(tx *gorm.DB, change PrincipleChange, source string) (*PrincipleHistory, error) {
+	var principle Principle
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("slot = ?", change.Slot).First(&principle).Error; err != nil {
+		return nil, fmt.Errorf("failed to find principle slot %d: %w", change.Slot, err)
+	}
+
+	// Defence in depth: the stored flag is authoritative even if slot ranges change
+	if principle.IsAdmin {
+		return nil, fmt.Errorf("slot %d: %w", change.Slot, ErrProtectedPrincipleSlot)
+	}
+
+	var lastVersion int
+	if err := tx.Model(&PrincipleHistory{}).
+		Where("slot = ?", change.Slot).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&lastVersion).Error; err != nil {
+		return nil, fmt.Errorf("failed to read principle version: %w", err)
+	}
+
+	updates := map[string]interface{}{
+		"content":    change.NewContent,
+		"updated_at": time.Now(),
+	}
+	if err := tx.Model(&Principle{}).Where("slot = ?", change.Slot).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update principle: %w", err)
+	}
+
+	entry := &PrincipleHistory{
+		Slot:                change.Slot,
+		Version:             lastVersion + 1,
+		OldContent:          principle.Content,
+		NewContent:          change.NewContent,
+		Justification:       change.Justification,
+		SourceGoalID:        change.SourceGoalID,
+		ValidationReasoning: change.ValidationReasoning,
+		Source:              source,
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to record principle history: %w", err)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPrincipleHistoryDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&Principle{}, &PrincipleHistory{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	for slot := 1; slot <= 10; slot++ {
+		p := Principle{Slot: slot, Content: "original", IsAdmin: slot <= 3}
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("failed to seed slot %d: %v", slot, err)
+		}
+	}
+	return db
+}
+
+func principleContent(t *testing.T, db *gorm.DB, slot int) string {
+	t.Helper()
+	var p Principle
+	if err := db.Where("slot = ?", slot).First(&p).Error; err != nil {
+		t.Fatalf("failed to load slot %d: %v", slot, err)
+	}
+	return p.Content
+}
+
+func TestApplyPrincipleModificationRejectsAdminSlots(t *testing.T) {
+	db := setupPrincipleHistoryDB(t)
+
+	for slot := 1; slot <= 3; slot++ {
+		_, err := ApplyPrincipleModification(db, PrincipleChange{Slot: slot, NewContent: "changed"})
+		if !errors.Is(err, ErrProtectedPrincipleSlot) {
+			t.Errorf("slot %d: expected ErrProtectedPrincipleSlot, got %v", slot, err)
+		}
+		if got := principleContent(t, db, slot); got != "original" {
+			t.Errorf("slot %d: content changed to %q", slot, got)
+		}
+	}
+
+	var count int64
+	db.Model(&PrincipleHistory{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no history rows, got %d", count)
+	}
+}
+
+func TestApplyAndRollbackPrinciple(t *testing.T) {
+	db := setupPrincipleHistoryDB(t)
+
+	first, err := ApplyPrincipleModification(db, PrincipleChange{
+		Slot: 5, NewContent: "v1", Justification: "why", SourceGoalID: "goal_1", ValidationReasoning: "ok",
+	})
+	if err != nil {
+		t.Fatalf("apply v1: %v", err)
+	}
+	if first.Version != 1 || first.OldContent != "original" || first.SourceGoalID != "goal_1" {
+		t.Errorf("unexpected first entry: %+v", first)
+	}
+
+	if _, err := ApplyPrincipleModification(db, PrincipleChange{Slot: 5, NewContent: "v2"}); err != nil {
+		t.Fatalf("apply v2: %v", err)
+	}
+	if got := principleContent(t, db, 5); got != "v2" {
+		t.Fatalf("expected v2, got %q", got)
+	}
+
+	rb, err := RollbackPrinciple(db, 5, 1)
+	if err != nil {
+		t.Fatalf("rollback to 1: %v", err)
+	}
+	if rb.Version != 3 || rb.Source != PrincipleChangeRollback || rb.OldContent != "v2" {
+		t.Errorf("unexpected rollback entry: %+v", rb)
+	}
+	if got := principleContent(t, db, 5); got != "v1" {
+		t.Errorf("expected v1 after rollback, got %q", got)
+	}
+
+	if _, err := RollbackPrinciple(db, 5, 0); err != nil {
+		t.Fatalf("rollback to 0: %v", err)
+	}
+	if got := principleContent(t, db, 5); got != "original" {
+		t.Errorf("expected original after rollback to 0, got %q", got)
+	}
+
+	if _, err := RollbackPrinciple(db, 5, 42); !errors.Is(err, ErrPrincipleVersionNotFound) {
+		t.Errorf("expected ErrPrincipleVersionNotFound, got %v", err)
+	}
+	if _, err := RollbackPrinciple(db, 2, 1); !errors.Is(err, ErrProtectedPrincipleSlot) {
+		t.Errorf("expected ErrProtectedPrincipleSlot, got %v", err)
+	}
+
+	history, err := ListPrincipleHistory(db, 5, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(history) != 4 || history[0].Version != 4 {
+		t.Errorf("expected 4 rows newest first, got %d (first version %d)", len(history), history[0].Version)
+	}
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-llama/internal/health"
+)
+
+// PrincipleWorker evolves the identity and principle slots 4-10 on its own schedule,
+// apart from compression. It stands down while the dialogue engine has recently
+// changed a principle itself, so the two do not fight over the same slots.
+type PrincipleWorker struct {
+	*WorkerDeps
+	scheduleHours      int
+	minRatingThreshold float64
+	extractionLimit    int           // Max memories to analyze for principles
+	dialogueCooldown   time.Duration // Skip runs this soon after a dialogue change (0 never skips)
+	stopChan           chan struct{}
+	runs               *runTracker
+	now                func() time.Time
+}
+
+// NewPrincipleWorker creates a principle evolution worker sharing deps with the
+// compression worker
+func NewPrincipleWorker(deps *WorkerDeps, scheduleHours int, minRatingThreshold float64, extractionLimit int, dialogueCooldown time.Duration) *PrincipleWorker {
+	return &PrincipleWorker{
+		WorkerDeps:         deps,
+		scheduleHours:      scheduleHours,
+		minRatingThreshold: minRatingThreshold,
+		extractionLimit:    extractionLimit,
+		dialogueCooldown:   dialogueCooldown,
+		stopChan:           make(chan struct{}),
+		runs:               newRunTracker("principle_evolution", "PrincipleWorker"),
+		now:                time.Now,
+	}
+}
+
+// Start begins the principle evolution loop
+func (w *PrincipleWorker) Start() {
+	log.Printf("[PrincipleWorker] Starting principle evolution worker (runs every %d hours, dialogue cooldown %s)",
+		w.scheduleHours, w.dialogueCooldown)
+
+	ticker := time.NewTicker(time.Duration(w.scheduleHours) * time.Hour)
+	defer ticker.Stop()
+
+	// Run immediately on start
+	w.runScheduledPass()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.runScheduledPass()
+		case <-w.stopChan:
+			log.Printf("[PrincipleWorker] Stopping principle evolution worker")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the worker
+func (w *PrincipleWorker) Stop() {
+	close(w.stopChan)
+}
+
+// Status returns the last run's status for the health endpoint
+func (w *PrincipleWorker) Status() health.WorkerStatus {
+	return w.runs.Status()
+}
+
+// runScheduledPass runs one evolution pass from the ticker loop. A panic is recovered
+// and recorded; the next tick runs as usual.
+func (w *PrincipleWorker) runScheduledPass() {
+	w.runs.run(func() (string, error) {
+		return w.evolve(context.Background())
+	})
+}
+
+// evolve runs one evolution pass unless the dialogue engine changed a principle within
+// the cooldown, and describes what it did
+func (w *PrincipleWorker) evolve(ctx context.Context) (string, error) {
+	if w.dialogueCooldown > 0 {
+		last, err := LastSelfModification(w.db)
+		if err != nil {
+			return "", err
+		}
+		if last != nil {
+			if age := w.now().Sub(last.CreatedAt); age < w.dialogueCooldown {
+				log.Printf("[PrincipleWorker] Skipping: the dialogue engine changed slot %d %s ago (cooldown %s)",
+					last.Slot, age.Round(time.Minute), w.dialogueCooldown)
+				return fmt.Sprintf("skipped: dialogue engine changed slot %d %s ago", last.Slot, age.Round(time.Minute)), nil
+			}
+		}
+	}
+
+	log.Printf("[PrincipleWorker] Starting principle evolution at %s", w.now().Format(time.RFC3339))
+	changed, err := w.evolvePrinciplesPhase(ctx)
+	if err != nil {
+		log.Printf("[PrincipleWorker] ERROR in principle evolution: %v", err)
+	}
+	log.Printf("[PrincipleWorker] Principle evolution complete: %d slot(s) changed", changed)
+	return fmt.Sprintf("%d principle slot(s) changed", changed), err
+}
+
+// evolvePrinciplesPhase runs the principle and identity evolution process
+// Returns the number of principle slots (including identity) whose content changed
+func (w *PrincipleWorker) evolvePrinciplesPhase(ctx context.Context) (int, error) {
+	before, _ := LoadPrinciples(w.db)
+
+	// Sub-phase A: Evolve system identity (slot 0)
+	log.Printf("[PrincipleWorker] Sub-phase A: Identity evolution...")
+	if err := EvolveIdentity(w.db, w.storage, w.embedder, w.llmURL, w.llmModel, w.llmClient); err != nil {
+		log.Printf("[PrincipleWorker] ERROR evolving identity: %v", err)
+		// Non-fatal, continue to principle evolution
+	}
+
+	// Sub-phase B: Extract principle candidates from memory patterns
+	log.Printf("[PrincipleWorker] Sub-phase B: Principle extraction...")
+	candidates, err := ExtractPrinciples(w.db, w.storage, w.embedder, w.minRatingThreshold, w.extractionLimit, w.llmURL, w.llmModel, w.llmClient)
+	if err != nil {
+		return w.countChangedPrinciples(before), err
+	}
+
+	if len(candidates) == 0 {
+		log.Printf("[PrincipleWorker] No principle candidates found")
+		return w.countChangedPrinciples(before), nil
+	}
+
+	log.Printf("[PrincipleWorker] Found %d principle candidates", len(candidates))
+
+	// Sub-phase C: Evolve principles (update slots 4-10 with best candidates)
+	log.Printf("[PrincipleWorker] Sub-phase C: Principle evolution...")
+	err = EvolvePrinciples(w.db, w.storage, w.embedder, candidates, w.minRatingThreshold, w.llmURL, w.llmSmallURL, w.llmSmallModel, w.llmClient)
+	return w.countChangedPrinciples(before), err
+}
+
+// countChangedPrinciples compares the current principles against a snapshot
+func (w *PrincipleWorker) countChangedPrinciples(before []Principle) int {
+	after, err := LoadPrinciples(w.db)
+	if err != nil {
+		return 0
+	}
+
+	previous := make(map[int]string, len(before))
+	for _, p := range before {
+		previous[p.Slot] = p.Content
+	}
+
+	changed := 0
+	for _, p := range after {
+		if content, ok := previous[p.Slot]; !ok || content != p.Content {
+			changed++
+		}
+	}
+	return changed
+}
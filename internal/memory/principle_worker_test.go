@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrincipleWorkerStandsDownAfterDialogueChange(t *testing.T) {
+	db := setupPrincipleHistoryDB(t)
+	change, err := ApplyPrincipleModification(db, PrincipleChange{Slot: 5, NewContent: "changed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RollbackPrinciple(db, 5, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := LastSelfModification(db)
+	if err != nil || last == nil || last.ID != change.ID {
+		t.Fatalf("expected the dialogue change, not the rollback, got %+v (%v)", last, err)
+	}
+
+	w := NewPrincipleWorker(NewWorkerDeps(nil, nil, nil, db, "", "", "", "", nil), 24, 0.75, 10, 12*time.Hour)
+	w.now = func() time.Time { return change.CreatedAt.Add(time.Hour) }
+	outcome, err := w.evolve(context.Background())
+	if err != nil || !strings.HasPrefix(outcome, "skipped: dialogue engine changed slot 5") {
+		t.Errorf("expected the run skipped within the cooldown, got %q (%v)", outcome, err)
+	}
+}
+
+func TestLastSelfModificationWithoutChanges(t *testing.T) {
+	db := setupPrincipleHistoryDB(t)
+	if last, err := LastSelfModification(db); err != nil || last != nil {
+		t.Errorf("expected no change, got %+v (%v)", last, err)
+	}
+}
+
+func TestRunTrackerRecoversPanics(t *testing.T) {
+	runs := newRunTracker("test", "TestWorker")
+
+	runs.run(func() (string, error) { panic("boom") })
+	status := runs.Status()
+	if status.Running || status.Runs != 1 || status.Panics != 1 || status.LastError != "panic: boom" {
+		t.Fatalf("expected the panic recorded, got %+v", status)
+	}
+
+	runs.run(func() (string, error) { return "3 things done", nil })
+	status = runs.Status()
+	if status.Runs != 2 || status.Panics != 1 || status.LastError != "" || status.LastOutcome != "3 things done" {
+		t.Errorf("expected the next run recorded normally, got %+v", status)
+	}
+	if status.LastFinished == nil || status.LastStarted == nil || status.LastFinished.Before(*status.LastStarted) {
+		t.Errorf("expected start and finish times, got %+v", status)
+	}
+}
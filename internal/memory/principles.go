@@ -16,6 +16,9 @@ import (
 	
 	"github.com/qdrant/go-client/qdrant"
 	"gorm.io/gorm"
+
+	"go-llama/internal/httpclient"
+	"go-llama/internal/utils"
 )
 
 // Principle represents the system's identity and principles
@@ -427,12 +430,7 @@ func ExtractPrinciples(db *gorm.DB, storage *Storage, embedder *Embedder, minRat
 
 // generatePrincipleFromContrast asks the LLM to find the rule separating success from failure
 func generatePrincipleFromContrast(ctx context.Context, llmURL string, llmModel string, goodContent string, badContent string, llmClient interface{}) (string, float64, error) {
-    truncateContent := func(s string, max int) string {
-        if len(s) <= max {
-            return s
-        }
-        return s[:max] + "..."
-    }
+    truncateContent := utils.Truncate
 
     // STREAMLINED PROMPT:
     // Removed "Reasoning" (dead code). Focused purely on extraction.
@@ -1252,7 +1250,7 @@ Respond with ONLY the principle text (10-25 words), nothing else.`,
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := httpclient.New(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
@@ -1391,7 +1389,7 @@ Respond with ONLY the principle text (10-25 words), nothing else.`,
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := httpclient.New(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -1434,10 +1432,7 @@ func min(a, b int) int {
 
 // truncate helper function
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
+	return utils.Truncate(s, maxLen)
 }
 
 // --- ROBUST S-EXPRESSION PARSING HELPERS ---
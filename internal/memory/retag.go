@@ -0,0 +1,292 @@
+// internal/memory/retag.go
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrRetagInProgress is returned when a retag run is already going
+var ErrRetagInProgress = errors.New("retag run already in progress")
+
+// maxRetagReportErrors caps the failures kept in a report; the count is always exact
+const maxRetagReportErrors = 20
+
+// RetagConfig controls the concept tag backfill
+type RetagConfig struct {
+	BatchSize int // Memories fetched per page (and per checkpoint)
+	PerMinute int // Maximum memories retagged per minute, bounding load on the LLM
+}
+
+// RetagCheckpoint is the persisted progress of a retag pass for one tagger version,
+// so a restart resumes after the last completed page
+type RetagCheckpoint struct {
+	TaggerVersion string     `gorm:"primaryKey" json:"tagger_version"`
+	NextOffset    string     `json:"next_offset"` // Qdrant point ID the next page starts at; "" from the start
+	Examined      int        `json:"examined"`
+	Retagged      int        `json:"retagged"`
+	Failed        int        `json:"failed"`
+	StartedAt     time.Time  `json:"started_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (RetagCheckpoint) TableName() string {
+	return "growerai_retag_checkpoints"
+}
+
+// RetagReport is the result of one retag run. Counts cover the whole pass, including
+// pages completed before a restart.
+type RetagReport struct {
+	TaggerVersion string        `json:"tagger_version"`
+	Resumed       bool          `json:"resumed"` // Continued a pass interrupted by a restart
+	StartedAt     time.Time     `json:"started_at"`
+	FinishedAt    time.Time     `json:"finished_at"`
+	Duration      time.Duration `json:"duration_ns"`
+	Completed     bool          `json:"completed"` // False when the run was stopped early
+	Examined      int           `json:"examined"`
+	Retagged      int           `json:"retagged"`
+	Failed        int           `json:"failed"`
+	Errors        []string      `json:"errors,omitempty"`
+}
+
+// RetagStatus reports whether a run is going and how far the current pass has got
+type RetagStatus struct {
+	Running       bool             `json:"running"`
+	TaggerVersion string           `json:"tagger_version"`
+	Checkpoint    *RetagCheckpoint `json:"checkpoint,omitempty"`
+	LastReport    *RetagReport     `json:"last_report,omitempty"`
+}
+
+// RetagWorker re-extracts concept tags for memories tagged by an older tagger version,
+// or never tagged, after the tagging prompt or model changes
+type RetagWorker struct {
+	storage *Storage
+	tagger  *Tagger
+	db      *gorm.DB
+	config  RetagConfig
+
+	runMu      sync.Mutex // Held for the duration of a run
+	reportMu   sync.RWMutex
+	running    bool
+	lastReport *RetagReport
+}
+
+// NewRetagWorker creates a retag worker
+func NewRetagWorker(storage *Storage, tagger *Tagger, db *gorm.DB, config RetagConfig) *RetagWorker {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.PerMinute <= 0 {
+		config.PerMinute = 30
+	}
+	return &RetagWorker{
+		storage: storage,
+		tagger:  tagger,
+		db:      db,
+		config:  config,
+	}
+}
+
+// Start launches a run in the background, failing fast with ErrRetagInProgress
+func (w *RetagWorker) Start(ctx context.Context) error {
+	if !w.runMu.TryLock() {
+		return ErrRetagInProgress
+	}
+	go func() {
+		defer w.runMu.Unlock()
+		if _, err := w.run(ctx); err != nil {
+			log.Printf("[Retag] ERROR: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Run retags synchronously and returns the report
+func (w *RetagWorker) Run(ctx context.Context) (*RetagReport, error) {
+	if !w.runMu.TryLock() {
+		return nil, ErrRetagInProgress
+	}
+	defer w.runMu.Unlock()
+	return w.run(ctx)
+}
+
+// Status reports the running state, the current pass's checkpoint and the last report
+func (w *RetagWorker) Status(ctx context.Context) RetagStatus {
+	w.reportMu.RLock()
+	status := RetagStatus{
+		Running:       w.running,
+		TaggerVersion: w.tagger.Version(),
+		LastReport:    w.lastReport,
+	}
+	w.reportMu.RUnlock()
+
+	if checkpoint, err := w.loadCheckpoint(ctx, status.TaggerVersion); err == nil {
+		status.Checkpoint = checkpoint
+	}
+	return status
+}
+
+// run pages through stale memories from the checkpoint, retagging each page and then
+// saving progress. The caller holds runMu.
+func (w *RetagWorker) run(ctx context.Context) (*RetagReport, error) {
+	w.setRunning(true)
+	defer w.setRunning(false)
+
+	version := w.tagger.Version()
+	checkpoint, err := w.loadCheckpoint(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	report := &RetagReport{TaggerVersion: version, StartedAt: time.Now()}
+	if checkpoint == nil || checkpoint.CompletedAt != nil {
+		// Fresh pass; after a completed one this only finds memories that failed or
+		// were written without the tagger since
+		checkpoint = &RetagCheckpoint{TaggerVersion: version, StartedAt: report.StartedAt}
+	} else {
+		report.Resumed = true
+		log.Printf("[Retag] Resuming %s pass started %s (%d retagged, %d failed so far)",
+			version, checkpoint.StartedAt.Format(time.RFC3339), checkpoint.Retagged, checkpoint.Failed)
+	}
+	log.Printf("[Retag] Retagging memories not tagged by %s (batch: %d, rate: %d/min)",
+		version, w.config.BatchSize, w.config.PerMinute)
+
+	interval := time.Minute / time.Duration(w.config.PerMinute)
+	var lastCall time.Time
+	for {
+		page, next, err := w.storage.ScrollStaleTags(ctx, version, checkpoint.NextOffset, w.config.BatchSize)
+		if err != nil {
+			w.finish(report, checkpoint)
+			return report, err
+		}
+
+		for i := range page {
+			mem := &page[i]
+			if wait := interval - time.Since(lastCall); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					w.finish(report, checkpoint)
+					return report, ctx.Err()
+				}
+			}
+			lastCall = time.Now()
+
+			checkpoint.Examined++
+			if err := w.retagMemory(ctx, mem, version); err != nil {
+				checkpoint.Failed++
+				if len(report.Errors) < maxRetagReportErrors {
+					report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", mem.ID, err))
+				}
+				log.Printf("[Retag] WARNING: Failed to retag memory %s: %v", mem.ID, err)
+				continue
+			}
+			checkpoint.Retagged++
+		}
+
+		// A page is only checkpointed once every memory in it was attempted, so an
+		// interrupted page is redone on resume
+		checkpoint.NextOffset = next
+		if next == "" {
+			now := time.Now()
+			checkpoint.CompletedAt = &now
+		}
+		if err := w.saveCheckpoint(ctx, checkpoint); err != nil {
+			log.Printf("[Retag] WARNING: %v", err)
+		}
+		log.Printf("[Retag] Progress: %d examined, %d retagged, %d failed",
+			checkpoint.Examined, checkpoint.Retagged, checkpoint.Failed)
+
+		if next == "" {
+			report.Completed = true
+			w.finish(report, checkpoint)
+			return report, nil
+		}
+	}
+}
+
+// retagMemory re-extracts concepts for one memory. Tags from an older tagger are
+// replaced; tags the writer chose itself (no tagger version recorded, e.g. "learning"
+// on reflections) are kept and the extracted concepts added to them.
+func (w *RetagWorker) retagMemory(ctx context.Context, mem *Memory, version string) error {
+	concepts, err := w.tagger.extractConcepts(ctx, mem.Content)
+	if err != nil {
+		return err
+	}
+
+	tags := concepts
+	if _, taggerSet := mem.Metadata[MetadataTaggerVersion]; !taggerSet && len(mem.ConceptTags) > 0 {
+		tags = mergeConceptTags(mem.ConceptTags, concepts)
+	}
+	return w.storage.UpdateConceptTags(ctx, mem.ID, tags, version)
+}
+
+// mergeConceptTags appends the new tags not already present, keeping existing order
+func mergeConceptTags(existing, added []string) []string {
+	merged := append([]string(nil), existing...)
+	seen := make(map[string]bool, len(existing))
+	for _, tag := range existing {
+		seen[tag] = true
+	}
+	for _, tag := range added {
+		if !seen[tag] {
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// finish completes, logs and keeps the report
+func (w *RetagWorker) finish(report *RetagReport, checkpoint *RetagCheckpoint) {
+	report.FinishedAt = time.Now()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt)
+	report.Examined = checkpoint.Examined
+	report.Retagged = checkpoint.Retagged
+	report.Failed = checkpoint.Failed
+
+	state := "complete"
+	if !report.Completed {
+		state = "stopped"
+	}
+	log.Printf("[Retag] Retag %s for %s: examined=%d, retagged=%d, failed=%d, took %s",
+		state, report.TaggerVersion, report.Examined, report.Retagged, report.Failed, report.Duration.Round(time.Second))
+
+	w.reportMu.Lock()
+	w.lastReport = report
+	w.reportMu.Unlock()
+}
+
+func (w *RetagWorker) setRunning(running bool) {
+	w.reportMu.Lock()
+	w.running = running
+	w.reportMu.Unlock()
+}
+
+// loadCheckpoint returns the checkpoint for a tagger version, or nil if none exists
+func (w *RetagWorker) loadCheckpoint(ctx context.Context, version string) (*RetagCheckpoint, error) {
+	var checkpoint RetagCheckpoint
+	err := w.db.WithContext(ctx).Where("tagger_version = ?", version).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retag checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+func (w *RetagWorker) saveCheckpoint(ctx context.Context, checkpoint *RetagCheckpoint) error {
+	checkpoint.UpdatedAt = time.Now()
+	if err := w.db.WithContext(ctx).Save(checkpoint).Error; err != nil {
+		return fmt.Errorf("failed to save retag checkpoint: %w", err)
+	}
+	return nil
+}
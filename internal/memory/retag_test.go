@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTaggerVersionChangesWithModel(t *testing.T) {
+	a := NewTagger("http://llm", "model-a", 10, nil, nil)
+	b := NewTagger("http://llm", "model-b", 10, nil, nil)
+	if a.Version() == b.Version() {
+		t.Errorf("expected a model switch to change the tagger version, both %q", a.Version())
+	}
+
+	mem := &Memory{}
+	a.markTagged(mem)
+	if mem.Metadata[MetadataTaggerVersion] != a.Version() {
+		t.Errorf("expected tagged memory to record %q, got %v", a.Version(), mem.Metadata)
+	}
+}
+
+func TestMergeConceptTagsKeepsWriterTags(t *testing.T) {
+	merged := mergeConceptTags([]string{"learning", "strategy"}, []string{"python", "learning"})
+	want := []string{"learning", "strategy", "python"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("expected %v, got %v", want, merged)
+	}
+}
@@ -0,0 +1,57 @@
+package memory
+
+import "testing"
+
+func TestInferSourceKind(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		mem  Memory
+		want string
+	}{
+		{"digest metadata", Memory{IsCollective: true, Metadata: map[string]interface{}{"digest_date": "2026-03-01"}}, SourceDigest},
+		{"digest tag", Memory{IsCollective: true, ConceptTags: []string{"learning", "daily_digest"}}, SourceDigest},
+		{"synthesis", Memory{IsCollective: true, Metadata: map[string]interface{}{"research_type": "synthesis", MetadataSourceUserIDs: "4"}}, SourceResearchSynthesis},
+		{"principles tier", Memory{Tier: TierPrinciples}, SourcePrincipleRelated},
+		{"principle tag", Memory{IsCollective: true, ConceptTags: []string{"principle"}}, SourcePrincipleRelated},
+		{"feedback", Memory{ConceptTags: []string{"user_feedback", "personality"}}, SourceUserConversation},
+		{"personal turn", Memory{UserID: strPtr("7"), Metadata: map[string]interface{}{"chat_id": 3}}, SourceUserConversation},
+		{"chat reflection", Memory{IsCollective: true, Metadata: map[string]interface{}{MetadataSourceUserIDs: "7"}}, SourceUserConversation},
+		{"learning", Memory{IsCollective: true, ConceptTags: []string{"learning", "strategy"}}, SourceDialogueLearning},
+	} {
+		if got := InferSourceKind(tc.mem); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestBuildSearchFilterSourceKinds(t *testing.T) {
+	filter, _ := buildSearchFilter(RetrievalQuery{
+		IncludeCollective: true,
+		SourceKinds:       []string{SourceResearchSynthesis, SourceDigest},
+	})
+	var kinds []string
+	for _, c := range filter.GetMust() {
+		if field := c.GetField(); field != nil && field.Key == "source_kind" {
+			kinds = field.GetMatch().GetKeywords().GetStrings()
+		}
+	}
+	if len(kinds) != 2 || kinds[0] != SourceResearchSynthesis || kinds[1] != SourceDigest {
+		t.Errorf("expected a must source_kind filter, got %v", kinds)
+	}
+
+	filter, _ = buildSearchFilter(RetrievalQuery{IncludeCollective: true})
+	for _, c := range filter.GetMust() {
+		if field := c.GetField(); field != nil && field.Key == "source_kind" {
+			t.Error("expected no source_kind filter without source kinds")
+		}
+	}
+}
+
+func TestValidateSourceKinds(t *testing.T) {
+	if err := ValidateSourceKinds(SourceKinds); err != nil {
+		t.Errorf("expected every source kind valid: %v", err)
+	}
+	if err := ValidateSourceKinds([]string{SourceDigest, "web"}); err == nil {
+		t.Error("expected an unknown source kind rejected")
+	}
+}
@@ -0,0 +1,35 @@
+// internal/memory/source_pages.go
+package memory
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SourcePageKey reduces a page URL to lower-case host and path, so the same page matches
+// whatever scheme, "www." prefix, query, fragment or trailing slash a link used. It
+// returns "" for anything that is not an absolute http(s) URL.
+func SourcePageKey(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	path := strings.TrimRight(u.Path, "/")
+	return host + path
+}
+
+// SourcePageKeys returns the distinct non-empty page keys of urls, in order
+func SourcePageKeys(urls []string) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, u := range urls {
+		key := SourcePageKey(u)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSourcePageKey(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://en.wikipedia.org/wiki/Go_%28programming_language%29": "en.wikipedia.org/wiki/Go_(programming_language)",
+		"http://WWW.Example.com/docs/?page=2#intro":                   "example.com/docs",
+		"https://example.com":                                         "example.com",
+		"ftp://example.com/file":                                      "",
+		"not a url":                                                   "",
+	} {
+		if got := SourcePageKey(raw); got != want {
+			t.Errorf("%q: expected %q, got %q", raw, want, got)
+		}
+	}
+	keys := SourcePageKeys([]string{"https://example.com/a/", "http://www.example.com/a", "mailto:x@example.com", "https://example.com/b"})
+	if len(keys) != 2 || keys[0] != "example.com/a" || keys[1] != "example.com/b" {
+		t.Errorf("expected distinct keys in order, got %v", keys)
+	}
+}
+
+func TestBuildSearchFilterSourcePagesAndAge(t *testing.T) {
+	after := time.Unix(1700000000, 0)
+	filter, _ := buildSearchFilter(RetrievalQuery{
+		IncludeCollective: true,
+		SourcePages:       []string{"example.com/a"},
+		CreatedAfter:      after,
+	})
+	var pages []string
+	var since float64
+	for _, c := range filter.GetMust() {
+		field := c.GetField()
+		if field == nil {
+			continue
+		}
+		switch field.Key {
+		case "source_pages":
+			pages = field.GetMatch().GetKeywords().GetStrings()
+		case "created_at":
+			since = field.GetRange().GetGt()
+		}
+	}
+	if len(pages) != 1 || pages[0] != "example.com/a" || since != float64(after.Unix()) {
+		t.Errorf("expected source_pages and created_at filters, got %v and %v", pages, since)
+	}
+}
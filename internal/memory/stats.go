@@ -0,0 +1,228 @@
+// internal/memory/stats.go
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// Stats defaults, used by the admin endpoint and compression reports
+const (
+	DefaultStatsTopTags      = 20
+	DefaultStatsTrustBuckets = 10
+	DefaultStatsDays         = 30
+	memoryStatsCacheTTL      = 60 * time.Second
+)
+
+// TagCount is how many memories carry a concept tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// HistogramBucket counts memories with a value in [Min, Max); the last bucket includes Max
+type HistogramBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// DayCount is how many memories were created on one UTC day
+type DayCount struct {
+	Day   string `json:"day"` // YYYY-MM-DD
+	Count int    `json:"count"`
+}
+
+// MemoryStats summarises the collection for dashboards and compression reports
+type MemoryStats struct {
+	Total          int                `json:"total"`
+	ByTier         map[MemoryTier]int `json:"by_tier"`
+	TopConceptTags []TagCount         `json:"top_concept_tags"`
+	TrustHistogram []HistogramBucket  `json:"trust_histogram"`
+	BySourceKind   map[string]int     `json:"by_source_kind"`
+	CreatedPerDay  []DayCount         `json:"created_per_day"` // Oldest day first
+	GeneratedAt    time.Time          `json:"generated_at"`
+}
+
+// facetCounts counts memories per value of an indexed keyword field with Qdrant's facet
+// API, so the values are counted server-side
+func (s *Storage) facetCounts(ctx context.Context, key string, limit int) ([]*qdrant.FacetHit, error) {
+	hits, err := s.Client.Facet(ctx, &qdrant.FacetCounts{
+		CollectionName: s.CollectionName,
+		Key:            key,
+		Limit:          PtrOf(uint64(limit)),
+		Exact:          boolPtr(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memories by %s: %w", key, err)
+	}
+	return hits, nil
+}
+
+// CountByTier returns the number of memories in each tier, with every tier present
+func (s *Storage) CountByTier(ctx context.Context) (map[MemoryTier]int, error) {
+	counts := map[MemoryTier]int{TierRecent: 0, TierMedium: 0, TierLong: 0, TierAncient: 0}
+	hits, err := s.facetCounts(ctx, "tier", len(counts)+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range hits {
+		counts[MemoryTier(hit.GetValue().GetStringValue())] = int(hit.GetCount())
+	}
+	return counts, nil
+}
+
+// TopConceptTags returns the n concept tags carried by the most memories, most common first
+func (s *Storage) TopConceptTags(ctx context.Context, n int) ([]TagCount, error) {
+	hits, err := s.facetCounts(ctx, "concept_tags", n)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]TagCount, 0, len(hits))
+	for _, hit := range hits {
+		tags = append(tags, TagCount{Tag: hit.GetValue().GetStringValue(), Count: int(hit.GetCount())})
+	}
+	return tags, nil
+}
+
+// CountBySourceKind returns the number of memories of each source kind, with every
+// known kind present
+func (s *Storage) CountBySourceKind(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int, len(SourceKinds))
+	for _, kind := range SourceKinds {
+		counts[kind] = 0
+	}
+	hits, err := s.facetCounts(ctx, "source_kind", len(SourceKinds)+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range hits {
+		counts[hit.GetValue().GetStringValue()] = int(hit.GetCount())
+	}
+	return counts, nil
+}
+
+// countRange counts memories whose numeric field lies in [min, max), or [min, max] when
+// inclusive is set
+func (s *Storage) countRange(ctx context.Context, key string, min, max float64, inclusive bool) (int, error) {
+	r := &qdrant.Range{Gte: floatPtr(min), Lt: floatPtr(max)}
+	if inclusive {
+		r = &qdrant.Range{Gte: floatPtr(min), Lte: floatPtr(max)}
+	}
+	count, err := s.Client.Count(ctx, &qdrant.CountPoints{
+		CollectionName: s.CollectionName,
+		Filter:         &qdrant.Filter{Must: []*qdrant.Condition{qdrant.NewRange(key, r)}},
+		Exact:          boolPtr(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count memories by %s: %w", key, err)
+	}
+	return int(count), nil
+}
+
+// TrustScoreHistogram splits trust scores (0-1) into equal buckets, one count request each
+func (s *Storage) TrustScoreHistogram(ctx context.Context, buckets int) ([]HistogramBucket, error) {
+	if buckets <= 0 {
+		buckets = DefaultStatsTrustBuckets
+	}
+	histogram := make([]HistogramBucket, buckets)
+	width := 1.0 / float64(buckets)
+	for i := range histogram {
+		min, max := float64(i)*width, float64(i+1)*width
+		if i == buckets-1 {
+			max = 1
+		}
+		count, err := s.countRange(ctx, "trust_score", min, max, i == buckets-1)
+		if err != nil {
+			return nil, err
+		}
+		histogram[i] = HistogramBucket{Min: min, Max: max, Count: count}
+	}
+	return histogram, nil
+}
+
+// CreatedPerDay counts memories created on each of the last n UTC days, today included,
+// oldest first
+func (s *Storage) CreatedPerDay(ctx context.Context, n int) ([]DayCount, error) {
+	if n <= 0 {
+		n = DefaultStatsDays
+	}
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := make([]DayCount, n)
+	for i := range days {
+		day := today.AddDate(0, 0, i-n+1)
+		count, err := s.countRange(ctx, "created_at", float64(day.Unix()), float64(day.AddDate(0, 0, 1).Unix()), false)
+		if err != nil {
+			return nil, err
+		}
+		days[i] = DayCount{Day: day.Format("2006-01-02"), Count: count}
+	}
+	return days, nil
+}
+
+// Stats gathers every aggregate with the default sizes. Each is a count or facet
+// request; no memories are downloaded.
+func (s *Storage) Stats(ctx context.Context) (*MemoryStats, error) {
+	stats := &MemoryStats{GeneratedAt: time.Now()}
+	var err error
+	if stats.Total, err = s.GetTotalMemoryCount(ctx); err != nil {
+		return nil, err
+	}
+	if stats.ByTier, err = s.CountByTier(ctx); err != nil {
+		return nil, err
+	}
+	if stats.TopConceptTags, err = s.TopConceptTags(ctx, DefaultStatsTopTags); err != nil {
+		return nil, err
+	}
+	if stats.TrustHistogram, err = s.TrustScoreHistogram(ctx, DefaultStatsTrustBuckets); err != nil {
+		return nil, err
+	}
+	if stats.BySourceKind, err = s.CountBySourceKind(ctx); err != nil {
+		return nil, err
+	}
+	if stats.CreatedPerDay, err = s.CreatedPerDay(ctx, DefaultStatsDays); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// StatsCache serves Storage.Stats for a short while, so dashboards can poll cheaply
+type StatsCache struct {
+	storage *Storage
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	stats *MemoryStats
+}
+
+// NewStatsCache caches storage's stats for 60 seconds
+func NewStatsCache(storage *Storage) *StatsCache {
+	return &StatsCache{storage: storage, ttl: memoryStatsCacheTTL}
+}
+
+// Get returns the cached stats, gathering them again once they are older than the TTL
+func (c *StatsCache) Get(ctx context.Context) (*MemoryStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats != nil && time.Since(c.stats.GeneratedAt) < c.ttl {
+		return c.stats, nil
+	}
+	stats, err := c.storage.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.stats = stats
+	return stats, nil
+}
+
+// Put replaces the cached stats with ones gathered elsewhere, such as after a compression pass
+func (c *StatsCache) Put(stats *MemoryStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = stats
+}
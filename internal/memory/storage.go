@@ -3,7 +3,9 @@ package memory
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"sort"
 	"sync"
 	"strings"
@@ -13,8 +15,35 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-llama/internal/httpclient"
+)
+
+// Storage errors callers can branch on with errors.Is
+var (
+	ErrNotFound           = errors.New("memory not found")          // No memory has the requested ID
+	ErrStorageUnavailable = errors.New("memory storage unavailable") // Qdrant unreachable or not answering in time
 )
 
+// unavailable marks Qdrant transport failures with ErrStorageUnavailable so an outage
+// can be told apart from a rejected request
+func unavailable(err error) error {
+	if err == nil || errors.Is(err, ErrStorageUnavailable) {
+		return err
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %w", ErrStorageUnavailable, err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrStorageUnavailable, err)
+	}
+	return err
+}
+
 // Storage handles all vector database operations
 type Storage struct {
 	Client         *qdrant.Client // Public for principle extraction
@@ -24,7 +53,8 @@ type Storage struct {
 
 // NewStorage creates a new storage instance
 func NewStorage(qdrantURL string, collectionName string, apiKey string) (*Storage, error) {
-	// Strip http:// or https:// prefix and any port
+	// Strip http:// or https:// prefix and any port; https connects with TLS
+	useTLS := strings.HasPrefix(qdrantURL, "https://")
 	qdrantURL = strings.TrimPrefix(qdrantURL, "http://")
 	qdrantURL = strings.TrimPrefix(qdrantURL, "https://")
 	
@@ -34,16 +64,30 @@ func NewStorage(qdrantURL string, collectionName string, apiKey string) (*Storag
 		host = qdrantURL[:idx]
 	}
 	
+	// Dial through the shared transport's proxy selection and trust its CA bundle. gRPC
+	// hands the dialer a resolved IP; the configured host is dialled instead so no_proxy
+	// and destination overrides can match it by name.
+	dialAddr := net.JoinHostPort(host, "6334")
 	client, err := qdrant.NewClient(&qdrant.Config{
-		Host:   host,
-		Port:   6334,  // gRPC port
-		APIKey: apiKey,
-		UseTLS: false,
+		Host:      host,
+		Port:      6334, // gRPC port
+		APIKey:    apiKey,
+		UseTLS:    useTLS,
+		TLSConfig: httpclient.Default().TLSConfig(),
+		GrpcOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return httpclient.DialContext(ctx, dialAddr)
+		})},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Qdrant client: %w", err)
 	}
 
+	return NewStorageFromClient(client, collectionName)
+}
+
+// NewStorageFromClient creates a storage instance on an existing Qdrant client, such
+// as one connected to a test server, and ensures the collection and its indexes exist
+func NewStorageFromClient(client *qdrant.Client, collectionName string) (*Storage, error) {
 	s := &Storage{
 		Client:         client,
 		CollectionName: collectionName,
@@ -57,6 +101,18 @@ func NewStorage(qdrantURL string, collectionName string, apiKey string) (*Storag
 	return s, nil
 }
 
+// Ping checks that Qdrant is reachable and the memory collection exists
+func (s *Storage) Ping(ctx context.Context) error {
+	exists, err := s.Client.CollectionExists(ctx, s.CollectionName)
+	if err != nil {
+		return fmt.Errorf("qdrant unreachable: %w", unavailable(err))
+	}
+	if !exists {
+		return fmt.Errorf("collection %s does not exist", s.CollectionName)
+	}
+	return nil
+}
+
 // ensureCollection creates the collection if it doesn't exist and ensures indexes are correct
 func (s *Storage) ensureCollection(ctx context.Context) error {
 	// Lock to prevent concurrent initialization
@@ -98,6 +154,8 @@ func (s *Storage) ensureCollection(ctx context.Context) error {
 		{"outcome_tag", qdrant.PayloadSchemaType_Keyword},
 		{"trust_score", qdrant.PayloadSchemaType_Float},
 		{"concept_tags", qdrant.PayloadSchemaType_Keyword},
+		{"source_kind", qdrant.PayloadSchemaType_Keyword},
+		{"source_pages", qdrant.PayloadSchemaType_Keyword},
 	}
 
 	// Get current collection info to check existing indexes
@@ -222,7 +280,7 @@ func (s *Storage) WaitForCollection(ctx context.Context, timeout time.Duration)
 			}
 			
 			// Check if it's just empty (which is fine) vs actually missing
-			if strings.Contains(err.Error(), "doesn't exist") {
+			if status.Code(err) == codes.NotFound {
 				continue // Still waiting
 			}
 			
@@ -283,6 +341,14 @@ func (s *Storage) Store(ctx context.Context, memory *Memory) error {
 		}
 	}
 
+	// Writers stamp the source kind; infer it for any that don't
+	if memory.SourceKind == "" {
+		memory.SourceKind = InferSourceKind(*memory)
+	} else if err := ValidateSourceKinds([]string{memory.SourceKind}); err != nil {
+		return err
+	}
+	memory.ConceptTags = ValidateConceptTags(memory.ConceptTags)
+
 	// Convert string slices to Qdrant ListValue
 	relatedMemoriesValues := make([]*qdrant.Value, len(memory.RelatedMemories))
 	for i, rm := range memory.RelatedMemories {
@@ -294,6 +360,11 @@ func (s *Storage) Store(ctx context.Context, memory *Memory) error {
 		conceptTagsValues[i] = qdrant.NewValueString(ct)
 	}
 
+	sourcePagesValues := make([]*qdrant.Value, len(memory.SourcePages))
+	for i, page := range memory.SourcePages {
+		sourcePagesValues[i] = qdrant.NewValueString(page)
+	}
+
 	// Convert metadata map to Qdrant struct value
 	metadataStruct := make(map[string]*qdrant.Value)
 	for k, v := range memory.Metadata {
@@ -350,6 +421,8 @@ func (s *Storage) Store(ctx context.Context, memory *Memory) error {
 		"access_count":     qdrant.NewValueInt(int64(memory.AccessCount)),
 		"importance_score": qdrant.NewValueDouble(memory.ImportanceScore),
 		"memory_id":        qdrant.NewValueString(memory.ID),
+		"source_kind":      qdrant.NewValueString(memory.SourceKind),
+		"source_pages":     &qdrant.Value{Kind: &qdrant.Value_ListValue{ListValue: &qdrant.ListValue{Values: sourcePagesValues}}},
 		"metadata":         &qdrant.Value{Kind: &qdrant.Value_StructValue{StructValue: &qdrant.Struct{Fields: metadataStruct}}},
 		
 		// Phase 4: Good/Bad Tagging
@@ -380,7 +453,7 @@ func (s *Storage) Store(ctx context.Context, memory *Memory) error {
 		Points:         []*qdrant.PointStruct{point},
 	})
 
-	return err
+	return unavailable(err)
 }
 
 // Search performs semantic search for relevant memories
@@ -388,14 +461,68 @@ func (s *Storage) Search(ctx context.Context, query RetrievalQuery, queryEmbeddi
 	log.Printf("[Storage] Search called - Limit: %d, MinScore: %.2f, IncludePersonal: %v, IncludeCollective: %v", 
 		query.Limit, query.MinScore, query.IncludePersonal, query.IncludeCollective)
 	
+	filter, matchesNothing := buildSearchFilter(query)
+	if matchesNothing {
+		log.Printf("[Storage] Personal-only search with no allowed users - returning no results")
+		return []RetrievalResult{}, nil
+	}
+
+	// Perform search
+	searchResult, err := s.Client.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: s.CollectionName,
+		Query:          qdrant.NewQuery(queryEmbedding...),
+		Filter:         filter,
+		Limit:          uint64Ptr(uint64(query.Limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+		WithVectors:    qdrant.NewWithVectors(query.WithVectors),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", unavailable(err))
+	}
+
+	// Convert results
+	results := make([]RetrievalResult, 0, len(searchResult))
+	for _, point := range searchResult {
+		if float64(point.Score) < query.MinScore {
+			continue
+		}
+
+		memory := s.pointToMemory(point)
+		results = append(results, RetrievalResult{
+			Memory: memory,
+			Score:  float64(point.Score),
+		})
+	}
+
+	// Apply trust-weighted reranking if bias is configured
+	if len(results) > 0 && query.GoodBehaviorBias > 0 {
+		results = applyTrustWeighting(results, query.GoodBehaviorBias)
+	}
+
+	// Access metadata is written in the background so retrieval stays fast
+	RecordAccess(results)
+
+	return results, nil
+}
+
+// buildSearchFilter translates a RetrievalQuery into a Qdrant filter. matchesNothing is
+// true when the query is personal-only but its allow-list admits no users.
+func buildSearchFilter(query RetrievalQuery) (filter *qdrant.Filter, matchesNothing bool) {
 	// Build filter with OR logic for personal vs collective
 	var must []*qdrant.Condition
 	var should []*qdrant.Condition
 
+	// Personal scope: an explicit allow-list takes precedence over the single UserID
+	personal := personalScopeCondition(query)
+	if query.IncludePersonal && !query.IncludeCollective && query.AllowedUserIDs != nil && personal == nil {
+		return nil, true
+	}
+
 	// Personal vs Collective: Use OR logic (should) instead of AND (must)
-	if query.IncludePersonal && query.UserID != nil {
-		should = append(should, qdrant.NewMatch("user_id", *query.UserID))
-		log.Printf("[Storage] Added user_id to OR filter: %s", *query.UserID)
+	if query.IncludePersonal && personal != nil {
+		should = append(should, personal)
+		log.Printf("[Storage] Added personal scope to OR filter")
 	}
 
 	if query.IncludeCollective {
@@ -434,8 +561,8 @@ func (s *Storage) Search(ctx context.Context, query RetrievalQuery, queryEmbeddi
 	}
 	
 	// If ONLY personal requested (no collective), use must instead of should
-	if query.IncludePersonal && !query.IncludeCollective && query.UserID != nil {
-		must = append(must, qdrant.NewMatch("user_id", *query.UserID))
+	if query.IncludePersonal && !query.IncludeCollective && personal != nil {
+		must = append(must, personal)
 		should = nil // Clear should, use must for exclusive personal
 		log.Printf("[Storage] Using exclusive personal filter (must)")
 	}
@@ -451,12 +578,33 @@ func (s *Storage) Search(ctx context.Context, query RetrievalQuery, queryEmbeddi
 		must = append(must, qdrant.NewMatch("outcome_tag", string(*query.OutcomeFilter)))
 		log.Printf("[Storage] Added outcome_tag filter: %s", *query.OutcomeFilter)
 	}
+
+	if len(query.SourceKinds) > 0 {
+		must = append(must, qdrant.NewMatchKeywords("source_kind", query.SourceKinds...))
+		log.Printf("[Storage] Added source_kind filter: %v", query.SourceKinds)
+	}
+
+	if len(query.SourcePages) > 0 {
+		must = append(must, qdrant.NewMatchKeywords("source_pages", query.SourcePages...))
+		log.Printf("[Storage] Added source_pages filter: %v", query.SourcePages)
+	}
+
+	if !query.CreatedAfter.IsZero() {
+		must = append(must, &qdrant.Condition{
+			ConditionOneOf: &qdrant.Condition_Field{
+				Field: &qdrant.FieldCondition{
+					Key: "created_at",
+					Range: &qdrant.Range{
+						Gt: floatPtr(float64(query.CreatedAfter.Unix())),
+					},
+				},
+			},
+		})
+		log.Printf("[Storage] Added created_at filter: after %s", query.CreatedAfter.Format(time.RFC3339))
+	}
 	
 	log.Printf("[Storage] Filter - Must conditions: %d, Should conditions: %d", len(must), len(should))
 
-	// Build filter based on conditions
-	var filter *qdrant.Filter
-	
 	// Build final filter combining must and should conditions
 	if len(query.ConceptTags) > 0 {
 		// Add concept tag conditions to should (match any tag)
@@ -494,39 +642,33 @@ func (s *Storage) Search(ctx context.Context, query RetrievalQuery, queryEmbeddi
 		log.Printf("[Storage] No filters applied - searching all memories")
 	}
 
-	// Perform search
-	searchResult, err := s.Client.Query(ctx, &qdrant.QueryPoints{
-		CollectionName: s.CollectionName,
-		Query:          qdrant.NewQuery(queryEmbedding...),
-		Filter:         filter,
-		Limit:          uint64Ptr(uint64(query.Limit)),
-		WithPayload:    qdrant.NewWithPayload(true),
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
+	return filter, false
+}
 
-	// Convert results
-	results := make([]RetrievalResult, 0, len(searchResult))
-	for _, point := range searchResult {
-		if float64(point.Score) < query.MinScore {
-			continue
+// personalScopeCondition returns the user_id condition for personal memories, or nil
+// when no personal memories are in scope
+func personalScopeCondition(query RetrievalQuery) *qdrant.Condition {
+	if query.AllowedUserIDs != nil {
+		allowed := query.AllowedUserIDs
+		if query.UserID != nil {
+			// Narrow to the requesting user, but only if they are allowed
+			allowed = nil
+			for _, id := range query.AllowedUserIDs {
+				if id == *query.UserID {
+					allowed = []string{id}
+					break
+				}
+			}
 		}
-
-		memory := s.pointToMemory(point)
-		results = append(results, RetrievalResult{
-			Memory: memory,
-			Score:  float64(point.Score),
-		})
+		if len(allowed) == 0 {
+			return nil
+		}
+		return qdrant.NewMatchKeywords("user_id", allowed...)
 	}
-
-	// Apply trust-weighted reranking if bias is configured
-	if len(results) > 0 && query.GoodBehaviorBias > 0 {
-		results = applyTrustWeighting(results, query.GoodBehaviorBias)
+	if query.UserID != nil {
+		return qdrant.NewMatch("user_id", *query.UserID)
 	}
-
-	return results, nil
+	return nil
 }
 
 // applyTrustWeighting adjusts retrieval scores based on trust, outcome, and validation
@@ -606,6 +748,8 @@ func (s *Storage) pointToMemoryFromRetrieved(point *qdrant.RetrievedPoint) Memor
 		LastAccessedAt:  time.Unix(getIntFromPayload(payload, "last_accessed_at"), 0),
 		AccessCount:     int(getIntFromPayload(payload, "access_count")),
 		ImportanceScore: getFloatFromPayload(payload, "importance_score"),
+		SourceKind:      getStringFromPayload(payload, "source_kind"),
+		SourcePages:     getStringSliceFromPayload(payload, "source_pages"),
 		Metadata:        getMetadataFromPayload(payload, "metadata"),
 		
 		// Phase 4: Good/Bad Tagging
@@ -647,6 +791,8 @@ func (s *Storage) pointToMemory(point *qdrant.ScoredPoint) Memory {
 		LastAccessedAt:  time.Unix(getIntFromPayload(payload, "last_accessed_at"), 0),
 		AccessCount:     int(getIntFromPayload(payload, "access_count")),
 		ImportanceScore: getFloatFromPayload(payload, "importance_score"),
+		SourceKind:      getStringFromPayload(payload, "source_kind"),
+		SourcePages:     getStringSliceFromPayload(payload, "source_pages"),
 		Metadata:        getMetadataFromPayload(payload, "metadata"),
 		
 		// Phase 4: Good/Bad Tagging
@@ -665,6 +811,10 @@ func (s *Storage) pointToMemory(point *qdrant.ScoredPoint) Memory {
 	if userID := getStringFromPayload(payload, "user_id"); userID != "" {
 		memory.UserID = &userID
 	}
+	// Only present when the search asked for vectors
+	if vectors := point.Vectors.GetVector(); vectors != nil {
+		memory.Embedding = vectors.Data
+	}
 
 	return memory
 }
@@ -808,6 +958,60 @@ func (s *Storage) FindMemoriesForCompression(ctx context.Context, currentTier Me
 	return memories, nil
 }
 
+// ScrollMemoriesOlderThan pages through memories in a tier created more than ageDays ago,
+// calling fn with each page (payload only, no vectors). Iteration stops when fn returns false.
+func (s *Storage) ScrollMemoriesOlderThan(ctx context.Context, tier MemoryTier, ageDays int, pageSize int, fn func([]Memory) bool) error {
+	cutoffTime := time.Now().AddDate(0, 0, -ageDays).Unix()
+
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatch("tier", string(tier)),
+			&qdrant.Condition{
+				ConditionOneOf: &qdrant.Condition_Field{
+					Field: &qdrant.FieldCondition{
+						Key: "created_at",
+						Range: &qdrant.Range{
+							Lt: floatPtr(float64(cutoffTime)),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var offset *qdrant.PointId
+	for {
+		points, nextOffset, err := s.Client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: s.CollectionName,
+			Filter:         filter,
+			Limit:          uint32Ptr(uint32(pageSize)),
+			Offset:         offset,
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors: &qdrant.WithVectorsSelector{
+				SelectorOptions: &qdrant.WithVectorsSelector_Enable{
+					Enable: false,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("scroll failed: %w", err)
+		}
+
+		page := make([]Memory, 0, len(points))
+		for _, point := range points {
+			page = append(page, s.pointToMemoryFromScroll(point))
+		}
+
+		if len(page) > 0 && !fn(page) {
+			return nil
+		}
+		if nextOffset == nil {
+			return nil
+		}
+		offset = nextOffset
+	}
+}
+
 // UpdateMemory updates an existing memory in the database
 func (s *Storage) UpdateMemory(ctx context.Context, memory *Memory) error {
 	// Validate outcome tag if provided
@@ -816,6 +1020,14 @@ func (s *Storage) UpdateMemory(ctx context.Context, memory *Memory) error {
 			return fmt.Errorf("invalid outcome tag: %w", err)
 		}
 	}
+
+	// Writers stamp the source kind; infer it for any that don't
+	if memory.SourceKind == "" {
+		memory.SourceKind = InferSourceKind(*memory)
+	} else if err := ValidateSourceKinds([]string{memory.SourceKind}); err != nil {
+		return err
+	}
+	memory.ConceptTags = ValidateConceptTags(memory.ConceptTags)
 	
 	// Convert string slices to Qdrant ListValue
 	relatedMemoriesValues := make([]*qdrant.Value, len(memory.RelatedMemories))
@@ -828,6 +1040,11 @@ func (s *Storage) UpdateMemory(ctx context.Context, memory *Memory) error {
 		conceptTagsValues[i] = qdrant.NewValueString(ct)
 	}
 
+	sourcePagesValues := make([]*qdrant.Value, len(memory.SourcePages))
+	for i, page := range memory.SourcePages {
+		sourcePagesValues[i] = qdrant.NewValueString(page)
+	}
+
 	// Convert metadata map to Qdrant struct value
 	metadataStruct := make(map[string]*qdrant.Value)
 	for k, v := range memory.Metadata {
@@ -884,6 +1101,8 @@ func (s *Storage) UpdateMemory(ctx context.Context, memory *Memory) error {
 		"access_count":     qdrant.NewValueInt(int64(memory.AccessCount)),
 		"importance_score": qdrant.NewValueDouble(memory.ImportanceScore),
 		"memory_id":        qdrant.NewValueString(memory.ID),
+		"source_kind":      qdrant.NewValueString(memory.SourceKind),
+		"source_pages":     &qdrant.Value{Kind: &qdrant.Value_ListValue{ListValue: &qdrant.ListValue{Values: sourcePagesValues}}},
 		"metadata":         &qdrant.Value{Kind: &qdrant.Value_StructValue{StructValue: &qdrant.Struct{Fields: metadataStruct}}},
 		
 		// Phase 4: Good/Bad Tagging
@@ -938,6 +1157,8 @@ func (s *Storage) pointToMemoryFromScroll(point *qdrant.RetrievedPoint) Memory {
 		LastAccessedAt:  time.Unix(getIntFromPayload(payload, "last_accessed_at"), 0),
 		AccessCount:     int(getIntFromPayload(payload, "access_count")),
 		ImportanceScore: getFloatFromPayload(payload, "importance_score"),
+		SourceKind:      getStringFromPayload(payload, "source_kind"),
+		SourcePages:     getStringSliceFromPayload(payload, "source_pages"),
 		Metadata:        getMetadataFromPayload(payload, "metadata"),
 		
 		// Phase 4: Good/Bad Tagging
@@ -1076,11 +1297,11 @@ func (s *Storage) IncrementValidationCount(ctx context.Context, memoryID string)
 	})
 	
 	if err != nil {
-		return fmt.Errorf("failed to find memory: %w", err)
+		return fmt.Errorf("failed to find memory: %w", unavailable(err))
 	}
 	
 	if len(scrollResult) == 0 {
-		return fmt.Errorf("memory not found: %s", memoryID)
+		return fmt.Errorf("%w: %s", ErrNotFound, memoryID)
 	}
 	
 	point := scrollResult[0]
@@ -1109,8 +1330,14 @@ func (s *Storage) IncrementValidationCount(ctx context.Context, memoryID string)
 }
 
 // Phase 4: UpdateAccessMetadata increments access count and updates timestamp
-// Optimized version using SetPayload to avoid reading full memory + embedding
 func (s *Storage) UpdateAccessMetadata(ctx context.Context, memoryID string) error {
+	return s.AddAccess(ctx, memoryID, 1, time.Now())
+}
+
+// AddAccess adds increment to the access count and sets last_accessed_at (batched touches
+// from AccessTracker may carry several hits, or none when only the timestamp changes)
+// Optimized version using SetPayload to avoid reading full memory + embedding
+func (s *Storage) AddAccess(ctx context.Context, memoryID string, increment int, accessedAt time.Time) error {
 	// Step 1: Get current access count (lightweight read - payload only, no vectors)
 	filter := &qdrant.Filter{
 		Must: []*qdrant.Condition{
@@ -1131,11 +1358,11 @@ func (s *Storage) UpdateAccessMetadata(ctx context.Context, memoryID string) err
 	})
 	
 	if err != nil {
-		return fmt.Errorf("failed to find memory: %w", err)
+		return fmt.Errorf("failed to find memory: %w", unavailable(err))
 	}
 	
 	if len(scrollResult) == 0 {
-		return fmt.Errorf("memory not found: %s", memoryID)
+		return fmt.Errorf("%w: %s", ErrNotFound, memoryID)
 	}
 	
 	point := scrollResult[0]
@@ -1145,8 +1372,8 @@ func (s *Storage) UpdateAccessMetadata(ctx context.Context, memoryID string) err
 	_, err = s.Client.SetPayload(ctx, &qdrant.SetPayloadPoints{
 		CollectionName: s.CollectionName,
 		Payload: map[string]*qdrant.Value{
-			"access_count":     qdrant.NewValueInt(currentAccessCount + 1),
-			"last_accessed_at": qdrant.NewValueInt(time.Now().Unix()),
+			"access_count":     qdrant.NewValueInt(currentAccessCount + int64(increment)),
+			"last_accessed_at": qdrant.NewValueInt(accessedAt.Unix()),
 		},
 		PointsSelector: &qdrant.PointsSelector{
 			PointsSelectorOneOf: &qdrant.PointsSelector_Points{
@@ -1193,11 +1420,11 @@ func (s *Storage) UpdateLinks(ctx context.Context, memoryID string, relatedMemor
 	})
 	
 	if err != nil {
-		return fmt.Errorf("failed to find memory: %w", err)
+		return fmt.Errorf("failed to find memory: %w", unavailable(err))
 	}
 	
 	if len(scrollResult) == 0 {
-		return fmt.Errorf("memory not found: %s", memoryID)
+		return fmt.Errorf("%w: %s", ErrNotFound, memoryID)
 	}
 	
 	// Update only the related_memories field
@@ -1249,11 +1476,11 @@ func (s *Storage) UpdateTrustScore(ctx context.Context, memoryID string, trustSc
 	})
 	
 	if err != nil {
-		return fmt.Errorf("failed to find memory: %w", err)
+		return fmt.Errorf("failed to find memory: %w", unavailable(err))
 	}
 	
 	if len(scrollResult) == 0 {
-		return fmt.Errorf("memory not found: %s", memoryID)
+		return fmt.Errorf("%w: %s", ErrNotFound, memoryID)
 	}
 	
 	// Update only the trust_score field
@@ -1278,6 +1505,57 @@ func (s *Storage) UpdateTrustScore(ctx context.Context, memoryID string, trustSc
 	return nil
 }
 
+// UpdateOutcome updates only the outcome_tag and trust_score fields for a memory, for
+// feedback that revises a memory after it was written
+func (s *Storage) UpdateOutcome(ctx context.Context, memoryID string, outcomeTag string, trustScore float64) error {
+	if err := ValidateOutcomeTag(outcomeTag); err != nil {
+		return err
+	}
+
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatch("memory_id", memoryID),
+		},
+	}
+
+	scrollResult, err := s.Client.Scroll(ctx, &qdrant.ScrollPoints{
+		CollectionName: s.CollectionName,
+		Filter:         filter,
+		Limit:          uint32Ptr(1),
+		WithPayload:    qdrant.NewWithPayload(false),
+		WithVectors: &qdrant.WithVectorsSelector{
+			SelectorOptions: &qdrant.WithVectorsSelector_Enable{
+				Enable: false,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find memory: %w", unavailable(err))
+	}
+	if len(scrollResult) == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, memoryID)
+	}
+
+	_, err = s.Client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: s.CollectionName,
+		Payload: map[string]*qdrant.Value{
+			"outcome_tag": qdrant.NewValueString(outcomeTag),
+			"trust_score": qdrant.NewValueDouble(trustScore),
+		},
+		PointsSelector: &qdrant.PointsSelector{
+			PointsSelectorOneOf: &qdrant.PointsSelector_Points{
+				Points: &qdrant.PointsIdsList{
+					Ids: []*qdrant.PointId{scrollResult[0].Id},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update outcome: %w", err)
+	}
+	return nil
+}
+
 // UpdateCoOccurrence updates only the co-occurrence tracking metadata for a memory
 // Optimized version using SetPayload to avoid reading full memory + embedding
 func (s *Storage) UpdateCoOccurrence(ctx context.Context, memoryID string, coRetrievalCounts map[string]int, coRetrievalLast map[string]int64) error {
@@ -1312,11 +1590,11 @@ func (s *Storage) UpdateCoOccurrence(ctx context.Context, memoryID string, coRet
 	})
 	
 	if err != nil {
-		return fmt.Errorf("failed to find memory: %w", err)
+		return fmt.Errorf("failed to find memory: %w", unavailable(err))
 	}
 	
 	if len(scrollResult) == 0 {
-		return fmt.Errorf("memory not found: %s", memoryID)
+		return fmt.Errorf("%w: %s", ErrNotFound, memoryID)
 	}
 	
 	// Update metadata with co-occurrence data
@@ -1403,6 +1681,92 @@ return memories, nil
 
 }
 
+// ScrollStaleTags returns one page of memories whose concept tags were not set by the
+// given tagger version (payload only), and the offset of the next page ("" when done).
+// Pass the previous offset to resume.
+func (s *Storage) ScrollStaleTags(ctx context.Context, version string, offset string, pageSize int) ([]Memory, string, error) {
+	var start *qdrant.PointId
+	if offset != "" {
+		start = qdrant.NewIDUUID(offset)
+	}
+
+	points, nextOffset, err := s.Client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+		CollectionName: s.CollectionName,
+		Filter: &qdrant.Filter{
+			MustNot: []*qdrant.Condition{
+				qdrant.NewMatch("metadata."+MetadataTaggerVersion, version),
+			},
+		},
+		Limit:       uint32Ptr(uint32(pageSize)),
+		Offset:      start,
+		WithPayload: qdrant.NewWithPayload(true),
+		WithVectors: &qdrant.WithVectorsSelector{
+			SelectorOptions: &qdrant.WithVectorsSelector_Enable{
+				Enable: false,
+			},
+		},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scroll stale-tagged memories: %w", err)
+	}
+
+	page := make([]Memory, 0, len(points))
+	for _, point := range points {
+		page = append(page, s.pointToMemoryFromScroll(point))
+	}
+	next := ""
+	if nextOffset != nil {
+		next = nextOffset.GetUuid()
+	}
+	return page, next, nil
+}
+
+// UpdateConceptTags replaces a memory's concept tags and records the tagger version,
+// leaving the embedding and all other payload untouched. An empty version leaves the
+// recorded version as it is, for tags cleaned rather than re-extracted.
+func (s *Storage) UpdateConceptTags(ctx context.Context, memoryID string, tags []string, version string) error {
+	tags = ValidateConceptTags(tags)
+	selector := &qdrant.PointsSelector{
+		PointsSelectorOneOf: &qdrant.PointsSelector_Points{
+			Points: &qdrant.PointsIdsList{
+				Ids: []*qdrant.PointId{qdrant.NewIDUUID(memoryID)},
+			},
+		},
+	}
+
+	values := make([]*qdrant.Value, len(tags))
+	for i, tag := range tags {
+		values[i] = qdrant.NewValueString(tag)
+	}
+	_, err := s.Client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: s.CollectionName,
+		Payload: map[string]*qdrant.Value{
+			"concept_tags": {Kind: &qdrant.Value_ListValue{ListValue: &qdrant.ListValue{Values: values}}},
+		},
+		PointsSelector: selector,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update concept tags: %w", err)
+	}
+	if version == "" {
+		return nil
+	}
+
+	// Set inside the metadata object so its other keys are kept
+	_, err = s.Client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: s.CollectionName,
+		Payload: map[string]*qdrant.Value{
+			MetadataTaggerVersion: qdrant.NewValueString(version),
+		},
+		PointsSelector: selector,
+		Key:            qdrant.PtrOf("metadata"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record tagger version: %w", err)
+	}
+	return nil
+}
+
 // GetMemoriesByIDs retrieves multiple memories by their IDs in a single batch operation
 // Returns a map of memoryID -> Memory for fast lookup
 // Missing IDs are not included in the result (no error)
@@ -1460,11 +1824,11 @@ func (s *Storage) GetMemoryByID(ctx context.Context, memoryID string) (*Memory,
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve memory: %w", err)
+		return nil, fmt.Errorf("failed to retrieve memory: %w", unavailable(err))
 	}
 
 	if len(scrollResult) == 0 {
-		return nil, fmt.Errorf("memory not found: %s", memoryID)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, memoryID)
 	}
 
 	memory := s.pointToMemoryFromScroll(scrollResult[0])
@@ -1647,6 +2011,66 @@ func (s *Storage) GetTotalMemoryCount(ctx context.Context) (int, error) {
 	return int(count), nil
 }
 
+// CountCollectiveMemories returns the number of memories shared across users
+func (s *Storage) CountCollectiveMemories(ctx context.Context) (int, error) {
+	count, err := s.Client.Count(ctx, &qdrant.CountPoints{
+		CollectionName: s.CollectionName,
+		Filter: &qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewMatchBool("is_collective", true),
+			},
+		},
+	})
+	
+	if err != nil {
+		return 0, fmt.Errorf("failed to count collective memories: %w", err)
+	}
+	
+	return int(count), nil
+}
+
+// ScrollCollectiveMemories pages through all collective memories, calling fn with each
+// page (payload only, no vectors). Iteration stops when fn returns false.
+func (s *Storage) ScrollCollectiveMemories(ctx context.Context, pageSize int, fn func([]Memory) bool) error {
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatchBool("is_collective", true),
+		},
+	}
+
+	var offset *qdrant.PointId
+	for {
+		points, nextOffset, err := s.Client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: s.CollectionName,
+			Filter:         filter,
+			Limit:          uint32Ptr(uint32(pageSize)),
+			Offset:         offset,
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors: &qdrant.WithVectorsSelector{
+				SelectorOptions: &qdrant.WithVectorsSelector_Enable{
+					Enable: false,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("scroll failed: %w", err)
+		}
+
+		page := make([]Memory, 0, len(points))
+		for _, point := range points {
+			page = append(page, s.pointToMemoryFromScroll(point))
+		}
+
+		if len(page) > 0 && !fn(page) {
+			return nil
+		}
+		if nextOffset == nil {
+			return nil
+		}
+		offset = nextOffset
+	}
+}
+
 // PtrOf is a generic helper to create a pointer to a value
 func PtrOf[T any](v T) *T {
 	return &v
@@ -1783,3 +2207,60 @@ func (s *Storage) BackfillIsCollective(ctx context.Context) error {
 	log.Printf("[Storage] is_collective backfill complete: %d/%d memories updated", backfilledCount, totalProcessed)
 	return nil
 }
+
+// BackfillSourceKind sets source_kind on memories stored before the field existed,
+// inferring it from their tier, concept tags and metadata
+func (s *Storage) BackfillSourceKind(ctx context.Context) error {
+	log.Printf("[Storage] Starting source_kind backfill migration...")
+
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{qdrant.NewIsEmpty("source_kind")},
+	}
+
+	counts := make(map[string]int)
+	backfilledCount := 0
+	var offset *qdrant.PointId
+	for {
+		points, nextOffset, err := s.Client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: s.CollectionName,
+			Filter:         filter,
+			Limit:          uint32Ptr(100),
+			Offset:         offset,
+			WithPayload:    qdrant.NewWithPayload(true),
+		})
+		if err != nil {
+			return fmt.Errorf("scroll failed: %w", err)
+		}
+
+		for _, point := range points {
+			kind := InferSourceKind(s.pointToMemoryFromScroll(point))
+			_, err := s.Client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+				CollectionName: s.CollectionName,
+				Payload: map[string]*qdrant.Value{
+					"source_kind": qdrant.NewValueString(kind),
+				},
+				PointsSelector: &qdrant.PointsSelector{
+					PointsSelectorOneOf: &qdrant.PointsSelector_Points{
+						Points: &qdrant.PointsIdsList{
+							Ids: []*qdrant.PointId{point.Id},
+						},
+					},
+				},
+			})
+			if err != nil {
+				log.Printf("[Storage] WARNING: Failed to set source_kind for point: %v", err)
+				continue
+			}
+			counts[kind]++
+			backfilledCount++
+		}
+
+		if nextOffset == nil {
+			break
+		}
+		offset = nextOffset
+	}
+
+	log.Printf("[Storage] source_kind backfill complete: %d memories updated %v", backfilledCount, counts)
+	return nil
+}
@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnavailableMarksTransportFailures(t *testing.T) {
+	for _, err := range []error{
+		status.Error(codes.Unavailable, "connection refused"),
+		status.Error(codes.DeadlineExceeded, "slow"),
+		fmt.Errorf("query: %w", context.DeadlineExceeded),
+	} {
+		if got := unavailable(err); !errors.Is(got, ErrStorageUnavailable) || !errors.Is(got, err) {
+			t.Errorf("expected %v marked unavailable and kept, got %v", err, got)
+		}
+	}
+	if got := unavailable(status.Error(codes.InvalidArgument, "bad vector")); errors.Is(got, ErrStorageUnavailable) {
+		t.Errorf("expected a rejected request left unmarked, got %v", got)
+	}
+	if unavailable(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+}
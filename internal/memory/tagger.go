@@ -25,6 +25,14 @@ const (
 	taggerRetryDelay  = 5 * time.Second
 )
 
+// taggerPromptVersion identifies the tagging prompts; bump it whenever they change so
+// existing memories are picked up by the retag job
+const taggerPromptVersion = 1
+
+// MetadataTaggerVersion is the memory metadata key recording which tagger version last
+// set the memory's concept tags
+const MetadataTaggerVersion = "tagger_version"
+
 // Tagger handles background tagging of memories
 type Tagger struct {
 	llmURL    string
@@ -45,6 +53,20 @@ func NewTagger(llmURL, llmModel string, batchSize int, embedder *Embedder, llmCl
 	}
 }
 
+// Version identifies the prompts and model producing concept tags; a change of either
+// makes existing tags stale
+func (t *Tagger) Version() string {
+	return fmt.Sprintf("v%d/%s", taggerPromptVersion, t.llmModel)
+}
+
+// markTagged records the tagger version that produced a memory's concept tags
+func (t *Tagger) markTagged(mem *Memory) {
+	if mem.Metadata == nil {
+		mem.Metadata = make(map[string]interface{})
+	}
+	mem.Metadata[MetadataTaggerVersion] = t.Version()
+}
+
 // TagMemories processes untagged memories and updates them with outcome tags and concepts
 func (t *Tagger) TagMemories(ctx context.Context, storage *Storage) error {
 	log.Println("[Tagger] Starting tagging cycle...")
@@ -87,6 +109,7 @@ func (t *Tagger) TagMemories(ctx context.Context, storage *Storage) error {
 		mem.TrustScore = 0.5 // Initial neutral trust
 		mem.ConceptTags = concepts
 		mem.ValidationCount = 1 // First validation
+		t.markTagged(mem)
 		
 		// CRITICAL: If embedding is missing, regenerate it
 		if len(mem.Embedding) == 0 {
@@ -258,6 +281,16 @@ Example:
     return nil, fmt.Errorf("queue client required for outcome analysis")
 }
 
+// ExtractConcepts returns validated concept tags for content, for writers that tag a
+// memory themselves rather than leaving it to the tagger queue
+func (t *Tagger) ExtractConcepts(ctx context.Context, content string) ([]string, error) {
+	concepts, err := t.extractConcepts(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateConceptTags(concepts), nil
+}
+
 // extractConcepts uses LLM to extract key semantic concepts from a memory
 // Includes retry logic with exponential backoff for timeout resilience
 func (t *Tagger) extractConcepts(ctx context.Context, content string) ([]string, error) {
@@ -162,6 +162,7 @@ func (tq *TaggerQueue) processMemory(ctx context.Context, memID string) error {
 	mem.TrustScore = 0.5
 	mem.ConceptTags = concepts
 	mem.ValidationCount = 1
+	tq.tagger.markTagged(mem)
 	
 	// Regenerate embedding if missing
 	if len(mem.Embedding) == 0 {
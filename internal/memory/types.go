@@ -3,6 +3,7 @@ package memory
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -38,6 +39,73 @@ func ValidateOutcomeTag(tag string) error {
 	}
 }
 
+// Source kinds record what created a memory, so retrieval can include or exclude
+// the system's own knowledge
+const (
+	SourceUserConversation  = "user_conversation"  // Chat turns, feedback and chat reflections
+	SourceDialogueLearning  = "dialogue_learning"  // Learnings and reflections from dialogue cycles
+	SourceResearchSynthesis = "research_synthesis" // Research goal syntheses
+	SourceDigest            = "digest"             // Daily and weekly digests
+	SourcePrincipleRelated  = "principle_related"  // Memories about the principles
+)
+
+// SourceKinds lists every valid source kind
+var SourceKinds = []string{
+	SourceUserConversation,
+	SourceDialogueLearning,
+	SourceResearchSynthesis,
+	SourceDigest,
+	SourcePrincipleRelated,
+}
+
+// ValidateSourceKinds checks that every kind is a known source kind
+func ValidateSourceKinds(kinds []string) error {
+	for _, kind := range kinds {
+		valid := false
+		for _, known := range SourceKinds {
+			if kind == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid source kind: %q (must be one of %s)", kind, strings.Join(SourceKinds, ", "))
+		}
+	}
+	return nil
+}
+
+// InferSourceKind derives a source kind from a memory's tier, concept tags and
+// metadata, for writers that don't set one and memories stored before the field existed
+func InferSourceKind(mem Memory) string {
+	if _, ok := mem.Metadata["digest_date"]; ok {
+		return SourceDigest
+	}
+	if researchType, _ := mem.Metadata["research_type"].(string); researchType == "synthesis" {
+		return SourceResearchSynthesis
+	}
+	if mem.Tier == TierPrinciples {
+		return SourcePrincipleRelated
+	}
+	for _, tag := range mem.ConceptTags {
+		switch tag {
+		case digestConceptTag:
+			return SourceDigest
+		case "principle", "principles":
+			return SourcePrincipleRelated
+		case "user_feedback":
+			return SourceUserConversation
+		}
+	}
+	if mem.UserID != nil || len(SourceUserIDsFromMetadata(mem.Metadata)) > 0 {
+		return SourceUserConversation
+	}
+	if _, ok := mem.Metadata["chat_id"]; ok {
+		return SourceUserConversation
+	}
+	return SourceDialogueLearning
+}
+
 // Memory represents a stored memory chunk with metadata
 type Memory struct {
 	ID              string                 `json:"id"`
@@ -50,6 +118,8 @@ type Memory struct {
 	LastAccessedAt  time.Time              `json:"last_accessed_at"`
 	AccessCount     int                    `json:"access_count"`
 	ImportanceScore float64                `json:"importance_score"`
+	SourceKind      string                 `json:"source_kind"` // What created the memory, see SourceKinds
+	SourcePages     []string               `json:"source_pages,omitempty"` // SourcePageKey of each page the memory drew on
 	Metadata        map[string]interface{} `json:"metadata"`
 	Embedding       []float32              `json:"-"` // Not serialized
 
@@ -98,6 +168,9 @@ type RetrievalQuery struct {
 	Query             string
 	UserID            *string
 	IncludePersonal   bool
+	// AllowedUserIDs restricts personal memories to these owners when non-nil.
+	// An empty (non-nil) list admits no personal memories at all.
+	AllowedUserIDs    []string
 	IncludeCollective bool
 	Tier              *MemoryTier
 	Limit             int
@@ -106,7 +179,11 @@ type RetrievalQuery struct {
 	// Phase 4 enhancements: filtering by outcome and concepts
 	OutcomeFilter    *OutcomeTag  // Filter by good/bad/neutral
 	ConceptTags      []string     // Filter by semantic tags
+	SourceKinds      []string     // Filter by source kind (empty = all)
+	SourcePages      []string     // Filter to memories drawing on any of these SourcePageKeys (empty = all)
+	CreatedAfter     time.Time    // Filter to memories created after this (zero = any age)
 	GoodBehaviorBias float64      // 0.0-1.0: Weight good memories higher (from config)
+	WithVectors      bool         // Return each memory's embedding in Memory.Embedding
 }
 
 // RetrievalResult represents a retrieved memory with relevance score
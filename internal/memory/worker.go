@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go-llama/internal/health"
+
+	"gorm.io/gorm"
+)
+
+// WorkerDeps are the dependencies the compression and principle workers share
+type WorkerDeps struct {
+	storage       *Storage
+	compressor    *Compressor
+	embedder      *Embedder
+	db            *gorm.DB    // Database handle (migration status, principles)
+	llmURL        string      // LLM URL for principle generation (Main/Complex)
+	llmModel      string      // LLM model name for principle generation (Main/Complex)
+	llmSmallURL   string      // LLM URL for simple evaluations (Fast/Light)
+	llmSmallModel string      // LLM model name for simple evaluations
+	llmClient     interface{} // LLM queue client for principle generation
+}
+
+// NewWorkerDeps bundles the dependencies shared by DecayWorker and PrincipleWorker
+func NewWorkerDeps(
+	storage *Storage,
+	compressor *Compressor,
+	embedder *Embedder,
+	db *gorm.DB,
+	llmURL string,
+	llmModel string,
+	llmSmallURL string,
+	llmSmallModel string,
+	llmClient interface{},
+) *WorkerDeps {
+	return &WorkerDeps{
+		storage:       storage,
+		compressor:    compressor,
+		embedder:      embedder,
+		db:            db,
+		llmURL:        llmURL,
+		llmModel:      llmModel,
+		llmSmallURL:   llmSmallURL,
+		llmSmallModel: llmSmallModel,
+		llmClient:     llmClient,
+	}
+}
+
+// runTracker records a worker's runs for the health endpoint and recovers a panicking
+// run, so one bad pass does not stop the worker's loop
+type runTracker struct {
+	logPrefix string
+	mu        sync.Mutex
+	status    health.WorkerStatus
+}
+
+func newRunTracker(name, logPrefix string) *runTracker {
+	return &runTracker{logPrefix: logPrefix, status: health.WorkerStatus{Name: name}}
+}
+
+// run executes fn, which reports what it did, and records the outcome
+func (t *runTracker) run(fn func() (outcome string, err error)) {
+	started := time.Now()
+	t.mu.Lock()
+	t.status.Running = true
+	t.status.LastStarted = &started
+	t.mu.Unlock()
+
+	var outcome string
+	var err error
+	defer func() {
+		recovered := recover()
+		finished := time.Now()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.status.Running = false
+		t.status.Runs++
+		t.status.LastFinished = &finished
+		t.status.LastDuration = finished.Sub(started)
+		t.status.LastOutcome = outcome
+		t.status.LastError = ""
+		if err != nil {
+			t.status.LastError = err.Error()
+		}
+		if recovered != nil {
+			t.status.Panics++
+			t.status.LastError = fmt.Sprintf("panic: %v", recovered)
+			log.Printf("[%s] PANIC recovered, continuing next tick: %v\n%s", t.logPrefix, recovered, debug.Stack())
+		}
+	}()
+	outcome, err = fn()
+}
+
+// Status returns a copy of the latest run's status
+func (t *runTracker) Status() health.WorkerStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
@@ -1,6 +1,8 @@
 package redisdb
 
 import (
+	"context"
+
 	"github.com/redis/go-redis/v9"
 	"go-llama/internal/config"
 )
@@ -12,3 +14,8 @@ func NewClient(cfg *config.Config) *redis.Client {
 		DB:       cfg.Redis.DB,
 	})
 }
+
+// Ping checks that Redis answers
+func Ping(ctx context.Context, rdb *redis.Client) error {
+	return rdb.Ping(ctx).Err()
+}
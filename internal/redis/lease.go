@@ -0,0 +1,48 @@
+package redisdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Lease is a lock on a Redis key, held under a token until released or its TTL lapses.
+// Renew and Release only act while the key still holds the caller's token.
+type Lease struct {
+	rdb *redis.Client
+	key string
+}
+
+var renewLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+var releaseLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// NewLease returns a lease on key
+func NewLease(rdb *redis.Client, key string) *Lease {
+	return &Lease{rdb: rdb, key: key}
+}
+
+// Acquire takes the lease for ttl if no one holds it
+func (l *Lease) Acquire(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	return l.rdb.SetNX(ctx, l.key, token, ttl).Result()
+}
+
+// Renew extends the lease to ttl; false means it expired or was taken over
+func (l *Lease) Renew(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	n, err := renewLease.Run(ctx, l.rdb, []string{l.key}, token, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// Release frees the lease if token still holds it
+func (l *Lease) Release(ctx context.Context, token string) error {
+	return releaseLease.Run(ctx, l.rdb, []string{l.key}, token).Err()
+}
@@ -0,0 +1,32 @@
+package redisdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateCounter counts hits on a key over fixed windows, shared by every server on
+// the same Redis
+type RateCounter struct {
+	rdb *redis.Client
+}
+
+// The window starts on the first hit, so the counter and its expiry are set together
+var hitWindow = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n`)
+
+// NewRateCounter returns a counter over rdb
+func NewRateCounter(rdb *redis.Client) *RateCounter {
+	return &RateCounter{rdb: rdb}
+}
+
+// Hit records one hit on key and returns the hits in its current window
+func (r *RateCounter) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return hitWindow.Run(ctx, r.rdb, []string{key}, window.Milliseconds()).Int64()
+}
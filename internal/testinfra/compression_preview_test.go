@@ -0,0 +1,136 @@
+package testinfra_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCompressionPreviewLeavesStoreUnchanged(t *testing.T) {
+	ctx := context.Background()
+	const previewCollection = "compression_preview"
+	client, err := fakeQdrant.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	storage, err := memory.NewStorageFromClient(client, previewCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	memories := []memory.Memory{}
+	// Twelve recent memories past the 7-day rule, more than one sample's worth
+	for i := 0; i < 12; i++ {
+		memories = append(memories, memory.Memory{Tier: memory.TierRecent, CreatedAt: now.AddDate(0, 0, -10-i),
+			Content: fmt.Sprintf("Observation %02d: %s", i, strings.Repeat("bees orient by the sun and polarized light ", 3))})
+	}
+	// Two recent memories too young to scan
+	for i := 0; i < 2; i++ {
+		memories = append(memories, memory.Memory{Tier: memory.TierRecent, CreatedAt: now.AddDate(0, 0, -2), Content: "a fresh note"})
+	}
+	// Three old medium memories; importance protects two of them from the 30-day rule
+	for i, importance := range []float64{0, 1, 1} {
+		memories = append(memories, memory.Memory{Tier: memory.TierMedium, CreatedAt: now.AddDate(0, 0, -40),
+			ImportanceScore: importance, Content: fmt.Sprintf("medium note %d", i)})
+	}
+	for i := range memories {
+		memories[i].Embedding = statsEmbedding(i)
+		if err := storage.Store(ctx, &memories[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := fakeQdrant.Points(previewCollection)
+
+	// Pages of five stream the twelve old recent memories as 5, 5 and 2
+	var pages []int
+	err = storage.ScrollMemoriesOlderThan(ctx, memory.TierRecent, 7, 5, func(page []memory.Memory) bool {
+		pages = append(pages, len(page))
+		return true
+	})
+	if err != nil || len(pages) != 3 || pages[0] != 5 || pages[1] != 5 || pages[2] != 2 {
+		t.Fatalf("expected pages of 5, 5 and 2, got %v (%v)", pages, err)
+	}
+	pages = nil
+	err = storage.ScrollMemoriesOlderThan(ctx, memory.TierRecent, 7, 5, func(page []memory.Memory) bool {
+		pages = append(pages, len(page))
+		return false
+	})
+	if err != nil || len(pages) != 1 {
+		t.Errorf("expected the scroll to stop after the first page, got %v (%v)", pages, err)
+	}
+
+	// A recent tier of 10 compresses from 7 memories down to 8; a medium tier of 6 from 4
+	limits := memory.StorageLimits{
+		MaxTotalMemories:   20,
+		TierAllocation:     memory.TierAllocation{Recent: 0.5, Medium: 0.3, Long: 0.1, Ancient: 0.1},
+		CompressionTrigger: 0.7,
+	}
+	worker := memory.NewDecayWorker(memory.NewWorkerDeps(storage, nil, nil, nil, "", "", "", "", nil), nil, nil, 24,
+		memory.TierRules{}, memory.MergeWindows{}, 1.0, 0, limits, memory.CompressionWeights{Age: 1})
+	preview, err := worker.Preview(ctx, memory.TierRules{RecentToMediumDays: 7, MediumToLongDays: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The live policy compresses the fourteen recent memories down to 8, oldest first
+	if len(preview.Live) != 3 {
+		t.Fatalf("expected three live transitions, got %+v", preview.Live)
+	}
+	live := preview.Live[0]
+	if live.From != memory.TierRecent || live.Count != 14 || live.Limit != 10 || live.Trigger != 7 || live.Target != 8 ||
+		!live.Triggered || live.Candidates != 6 || len(live.Sample) != 6 {
+		t.Fatalf("expected six recent memories selected, got %+v", live)
+	}
+	if !strings.HasPrefix(live.Sample[0].Content, "Observation 11") || live.Sample[0].Score < live.Sample[5].Score {
+		t.Errorf("expected the oldest memory first, got %+v", live.Sample)
+	}
+	if medium := preview.Live[1]; medium.Count != 3 || medium.Trigger != 4 || medium.Triggered || medium.Candidates != 0 {
+		t.Errorf("expected the medium tier under its trigger, got %+v", medium)
+	}
+
+	// The age-rule simulation skips the long -> ancient transition, which has no rule
+	if len(preview.Transitions) != 2 {
+		t.Fatalf("expected two transitions, got %+v", preview.Transitions)
+	}
+
+	recent := preview.Transitions[0]
+	if recent.From != memory.TierRecent || recent.Scanned != 12 || recent.Count != 12 || recent.Truncated {
+		t.Errorf("expected the twelve old recent memories counted, got %+v", recent)
+	}
+	if len(recent.Sample) != 10 {
+		t.Fatalf("expected the sample capped at 10, got %d", len(recent.Sample))
+	}
+	for _, s := range recent.Sample {
+		if len(s.Content) != 83 || !strings.HasPrefix(s.Content, "Observation ") || !strings.HasSuffix(s.Content, "...") {
+			t.Errorf("expected 80 characters of content and a marker, got %q", s.Content)
+		}
+		if s.ID == "" || s.AdjustedAge < 10 {
+			t.Errorf("expected the sample's id and age, got %+v", s)
+		}
+	}
+
+	medium := preview.Transitions[1]
+	if medium.From != memory.TierMedium || medium.Scanned != 3 || medium.Count != 1 || len(medium.Sample) != 1 ||
+		medium.Sample[0].Content != "medium note 0" {
+		t.Errorf("expected only the unprotected medium memory counted, got %+v", medium)
+	}
+
+	// A dry run writes nothing
+	after := fakeQdrant.Points(previewCollection)
+	if len(after) != len(before) {
+		t.Fatalf("expected %d memories after the preview, got %d", len(before), len(after))
+	}
+	for i := range before {
+		if !proto.Equal(before[i], after[i]) {
+			t.Errorf("memory %v changed: %v -> %v", before[i].GetId(), before[i].GetPayload(), after[i].GetPayload())
+		}
+	}
+}
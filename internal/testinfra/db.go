@@ -0,0 +1,36 @@
+// internal/testinfra/db.go
+package testinfra
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var dbCounter atomic.Int64
+
+// OpenDB opens a fresh in-memory SQLite database, migrates models into it and runs
+// the DDL statements after them. Each call gets its own database, shared by every
+// connection in the pool, so background workers see what the test wrote. Tables whose
+// columns default to NOW(), which SQLite rejects, are created with ddl instead.
+func OpenDB(models []interface{}, ddl ...string) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("file:testinfra%d?mode=memory&cache=shared", dbCounter.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory sqlite: %w", err)
+	}
+	if len(models) > 0 {
+		if err := db.AutoMigrate(models...); err != nil {
+			return nil, fmt.Errorf("failed to migrate: %w", err)
+		}
+	}
+	for _, stmt := range ddl {
+		if err := db.Exec(stmt).Error; err != nil {
+			return nil, fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+	return db, nil
+}
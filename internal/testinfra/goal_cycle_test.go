@@ -0,0 +1,334 @@
+package testinfra_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-llama/internal/dialogue"
+	"go-llama/internal/llm"
+	"go-llama/internal/memory"
+	"go-llama/internal/testinfra"
+	"go-llama/internal/tools"
+
+	"gorm.io/gorm"
+)
+
+// goalCycleEngine builds a dialogue engine over the fakes whose cycles run every
+// phase, the state manager its goals are seeded and read back through, and its database
+func goalCycleEngine(t *testing.T, metaLearning bool) (*dialogue.Engine, *dialogue.StateManager, *gorm.DB) {
+	t.Helper()
+	fakeQdrant.Reset()
+	fakeLLM.Reset()
+	t.Cleanup(fakeLLM.Reset)
+
+	db, err := testinfra.OpenDB([]interface{}{
+		&memory.Principle{}, &memory.PrincipleHistory{}, &dialogue.DialogueMetrics{}, &dialogue.GoalArchive{},
+		&dialogue.ActionResult{}, &dialogue.StateVersion{},
+		&dialogue.GoalRecord{}, &dialogue.GoalActionRecord{}, &dialogue.GoalAnswer{}, &dialogue.DialogueGoalEvent{},
+	}, dialogueDDL...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := fakeQdrant.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	storage, err := memory.NewStorageFromClient(client, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedder := memory.NewEmbedder(fakeLLM.EmbeddingsURL())
+
+	manager := llm.NewManager(llm.DefaultConfig(), nil)
+	t.Cleanup(manager.Stop)
+	llmClient := llm.NewClient(manager, llm.PriorityBackground, 30*time.Second)
+
+	registry := tools.NewContextualRegistry(tools.NewRegistry(), map[string]tools.ToolConfig{})
+	stateManager := dialogue.NewStateManager(db)
+	engine := dialogue.NewEngine(
+		storage, embedder, stateManager, registry, db,
+		fakeLLM.ChatURL(), "fake", 8192, llmClient, fakeLLM.ChatURL(), "fake",
+		20000, 5, 5, 3, 24, "moderate",
+		false, metaLearning, false, false, false, nil,
+	)
+	return engine, stateManager, db
+}
+
+// seedGoals saves goals as the active goals the next cycle loads
+func seedGoals(t *testing.T, stateManager *dialogue.StateManager, goals ...dialogue.Goal) {
+	t.Helper()
+	ctx := context.Background()
+	state, err := stateManager.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.ActiveGoals = goals
+	if err := stateManager.SaveState(ctx, state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findGoal(goals []dialogue.Goal, id string) *dialogue.Goal {
+	for i := range goals {
+		if goals[i].ID == id {
+			return &goals[i]
+		}
+	}
+	return nil
+}
+
+func TestCycleSettlesAFinishedPrimaryAndItsSecondaries(t *testing.T) {
+	ctx := context.Background()
+	engine, stateManager, _ := goalCycleEngine(t, false)
+	seedGoals(t, stateManager,
+		dialogue.Goal{ID: "goal_overwinter", Description: "Understand how honeybee colonies survive the winter",
+			Tier: dialogue.GoalTierPrimary, Status: dialogue.GoalStatusCompleted, Progress: 1},
+		dialogue.Goal{ID: "goal_cluster", Description: "Research the temperature inside a winter bee cluster",
+			Tier: dialogue.GoalTierSecondary, Status: dialogue.GoalStatusActive,
+			SupportsGoals: []string{"goal_overwinter"}, DependencyScore: 0.8},
+	)
+	fakeLLM.Script("Analyze recent activity", reflectionReply("honeybees"))
+
+	if err := engine.RunDialogueCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	state, err := stateManager.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findGoal(state.ActiveGoals, "goal_overwinter") != nil || findGoal(state.CompletedGoals, "goal_overwinter") == nil {
+		t.Errorf("expected the finished primary moved to the completed goals, got active %+v", state.ActiveGoals)
+	}
+	// With no other primary to support, the secondary stands on its own
+	cluster := findGoal(state.ActiveGoals, "goal_cluster")
+	if cluster == nil || cluster.Tier != dialogue.GoalTierPrimary || len(cluster.SupportsGoals) != 0 {
+		t.Errorf("expected the secondary promoted to primary, got %+v", cluster)
+	}
+}
+
+func TestCycleCommitsAValidatedSelfModification(t *testing.T) {
+	ctx := context.Background()
+	engine, stateManager, db := goalCycleEngine(t, true)
+	// Slot 0 is a zero primary key, which Create would let the database assign
+	if err := db.Exec(`INSERT INTO growerai_principles (slot, content, is_admin) VALUES (0, 'GrowerAI', false)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	principles := []memory.Principle{}
+	for slot := 1; slot <= 10; slot++ {
+		principles = append(principles, memory.Principle{Slot: slot, Content: fmt.Sprintf("Principle %d", slot), IsAdmin: slot <= 3})
+	}
+	principles[4].Content = "Answer quickly"
+	if err := db.Create(&principles).Error; err != nil {
+		t.Fatal(err)
+	}
+	const proposed = "Prefer primary sources over summaries"
+	seedGoals(t, stateManager, dialogue.Goal{
+		ID: "goal_selfmod", Description: "Modify principle 5 to favour primary sources",
+		Tier: dialogue.GoalTierTactical, Status: dialogue.GoalStatusActive,
+		SelfModGoal: &dialogue.SelfModificationGoal{
+			TargetSlot: 5, CurrentPrinciple: "Answer quickly", ProposedPrinciple: proposed,
+			Justification: "Summaries led to shallow research", ValidationStatus: "pending",
+		},
+	})
+	fakeLLM.Script("Analyze recent activity", reflectionReply("principles"))
+	fakeLLM.Script("Validate a proposed principle modification",
+		`(validation (is_valid true) (reasoning "primary sources deepen research"))`)
+
+	if err := engine.RunDialogueCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	state, err := stateManager.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goal := findGoal(state.ActiveGoals, "goal_selfmod")
+	if goal == nil || goal.Status != dialogue.GoalStatusCompleted || goal.SelfModGoal.ValidationStatus != "validated" {
+		t.Fatalf("expected the self-modification validated and closed, got %+v", goal)
+	}
+	// No search tool is registered, so the trial falls back to the model's judgement
+	if trial := goal.SelfModGoal.Trial; trial == nil || trial.Method != dialogue.PrincipleTrialMethodLLMOnly || !trial.Passed {
+		t.Errorf("expected a passed llm_only trial recorded, got %+v", trial)
+	}
+
+	principles, err = memory.LoadPrinciples(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []memory.PrincipleHistory
+	if err := db.Where("slot = ?", 5).Find(&history).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range principles {
+		if p.Slot == 5 && p.Content != proposed {
+			t.Errorf("expected slot 5 rewritten, got %q", p.Content)
+		}
+	}
+	if len(history) != 1 || history[0].SourceGoalID != "goal_selfmod" {
+		t.Errorf("expected one history entry from the goal, got %+v", history)
+	}
+}
+
+// proposingReply is a reflection proposing the given goal expressions
+func proposingReply(goals ...string) string {
+	return `(reasoning
+  (reflection "Cycle on goals: the active research is on track and new questions came up")
+  (goals_to_create ` + strings.Join(goals, "\n    ") + `))`
+}
+
+func TestCycleCountsTheReflectionsGoalProposals(t *testing.T) {
+	ctx := context.Background()
+	engine, stateManager, _ := goalCycleEngine(t, false)
+	seedGoals(t, stateManager, dialogue.Goal{ID: "goal_bees", Description: "Research how honey bees navigate by the sun",
+		Tier: dialogue.GoalTierTactical, Status: dialogue.GoalStatusActive, Priority: 4})
+	fakeLLM.Script("Analyze recent activity", proposingReply(
+		`(goal (description "Research how honey bees navigate by the sun") (priority 4))`,
+		`(goal (description "Learn Go") (priority 4))`,
+		`(goal (description "Understand volcanic eruption forecasting methods") (priority 4))`,
+	))
+
+	if err := engine.RunDialogueCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := stateManager.RecentMetrics(ctx, 1)
+	if err != nil || len(metrics) != 1 {
+		t.Fatalf("expected the cycle's metrics, got %d (%v)", len(metrics), err)
+	}
+	m := metrics[0]
+	got := []int{m.GoalProposalsReceived, m.GoalProposalsAccepted, m.GoalProposalsInvalid, m.GoalProposalsDuplicateActive}
+	if want := []int{3, 1, 1, 1}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected received, accepted, invalid and duplicate active %v, got %v", want, got)
+	}
+	state, err := stateManager.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.ActiveGoals) != 2 || state.ActiveGoals[1].Description != "Understand volcanic eruption forecasting methods" {
+		t.Errorf("expected the accepted proposal added to the active goals, got %+v", state.ActiveGoals)
+	}
+}
+
+func TestCycleRefusesProposalsWhileTheBacklogIsFull(t *testing.T) {
+	ctx := context.Background()
+	engine, stateManager, _ := goalCycleEngine(t, false)
+	// One action a day, and a day's backlog allowed
+	if err := engine.SetBackpressure(dialogue.BackpressureConfig{Enabled: true, CycleInterval: 24 * time.Hour, BacklogMultiple: 1}); err != nil {
+		t.Fatal(err)
+	}
+	pending := []dialogue.Action{
+		{Description: "honey bee navigation", Tool: dialogue.ActionToolSearch, Status: dialogue.ActionStatusPending},
+		{Description: "waggle dance angles", Tool: dialogue.ActionToolSearch, Status: dialogue.ActionStatusPending},
+	}
+	seedGoals(t, stateManager, dialogue.Goal{ID: "goal_bees", Description: "Research how honey bees navigate by the sun",
+		Tier: dialogue.GoalTierTactical, Status: dialogue.GoalStatusActive, Priority: 4, Actions: pending})
+	fakeLLM.Script("Analyze recent activity", proposingReply(
+		`(goal (description "Understand volcanic eruption forecasting methods") (priority 4))`,
+		`(goal (description "Study the history of the printing press") (priority 4))`,
+	))
+
+	if err := engine.RunDialogueCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := stateManager.RecentMetrics(ctx, 1)
+	if err != nil || len(metrics) != 1 {
+		t.Fatalf("expected the cycle's metrics, got %d (%v)", len(metrics), err)
+	}
+	if m := metrics[0]; m.GoalProposalsBackpressure != 2 || m.GoalProposalsReceived != 0 || m.PendingActionBacklog != 2 {
+		t.Errorf("expected both proposals refused unchecked with 2 actions pending, got %+v", m)
+	}
+	state, err := stateManager.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.ActiveGoals) != 1 {
+		t.Errorf("expected no goals added while the backlog is full, got %+v", state.ActiveGoals)
+	}
+}
+
+func TestCycleValidatesSecondaryProposalsInOneCall(t *testing.T) {
+	ctx := context.Background()
+	engine, stateManager, _ := goalCycleEngine(t, false)
+	seedGoals(t, stateManager, dialogue.Goal{ID: "goal_overwinter", Description: "Understand how honeybee colonies survive the winter",
+		Tier: dialogue.GoalTierPrimary, Status: dialogue.GoalStatusActive, Priority: 9})
+	fakeLLM.Script("Analyze recent activity", proposingReply(
+		`(goal (description "Research the temperature inside a winter bee cluster") (priority 6))`,
+		`(goal (description "Study how honeybees store honey for the cold months") (priority 6))`,
+	))
+	const batchMarker = "Evaluate, for EACH secondary goal below"
+	fakeLLM.Script(batchMarker, `(goal_support_validations
+  (validation (index 1) (supports_goal_id "goal_overwinter") (confidence 0.9) (reasoning "cluster heat keeps the colony alive") (is_valid true))
+  (validation (index 2) (supports_goal_id "goal_overwinter") (confidence 0.8) (reasoning "stores feed the winter cluster") (is_valid true)))`)
+
+	if err := engine.RunDialogueCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	batches, singles := 0, 0
+	for _, req := range fakeLLM.Requests() {
+		switch prompt := req.Prompt(); {
+		case strings.Contains(prompt, batchMarker):
+			batches++
+		case strings.Contains(prompt, "Evaluate if this SECONDARY goal"):
+			singles++
+		}
+	}
+	if batches != 1 || singles != 0 {
+		t.Errorf("expected one batched validation and no single ones, got %d and %d", batches, singles)
+	}
+
+	metrics, err := stateManager.RecentMetrics(ctx, 1)
+	if err != nil || len(metrics) != 1 || metrics[0].GoalValidationTokens == 0 {
+		t.Errorf("expected the validation's tokens recorded, got %+v (%v)", metrics, err)
+	}
+	state, err := stateManager.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondaries := 0
+	for _, goal := range state.ActiveGoals {
+		if goal.Tier == dialogue.GoalTierSecondary && len(goal.SupportsGoals) == 1 && goal.SupportsGoals[0] == "goal_overwinter" {
+			secondaries++
+		}
+	}
+	if secondaries != 2 {
+		t.Errorf("expected both proposals linked to the primary, got %+v", state.ActiveGoals)
+	}
+}
+
+func TestCycleMergesSiblingProposals(t *testing.T) {
+	ctx := context.Background()
+	engine, stateManager, _ := goalCycleEngine(t, false)
+	seedGoals(t, stateManager, dialogue.Goal{ID: "goal_bees", Description: "Research how honey bees navigate by the sun",
+		Tier: dialogue.GoalTierTactical, Status: dialogue.GoalStatusActive, Priority: 4})
+	fakeLLM.Script("Analyze recent activity", proposingReply(
+		`(goal (description "Research the Rust ownership and borrowing rules for safe concurrent code") (priority 3))`,
+		`(goal (description "Research the Rust ownership and borrowing rules for safe code") (priority 4))`,
+	))
+
+	if err := engine.RunDialogueCycle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	state, err := stateManager.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rust := []dialogue.Goal{}
+	for _, goal := range state.ActiveGoals {
+		if strings.Contains(goal.Description, "Rust") {
+			rust = append(rust, goal)
+		}
+	}
+	if len(rust) != 1 {
+		t.Fatalf("expected the siblings merged into one goal, got %+v", rust)
+	}
+	if kept := rust[0]; kept.Description != "Research the Rust ownership and borrowing rules for safe code" || len(kept.Notes) != 1 ||
+		!strings.Contains(kept.Notes[0].Text, "also cover: concurrent") {
+		t.Errorf("expected the higher-priority sibling kept with the other noted, got %+v", kept)
+	}
+	metrics, err := stateManager.RecentMetrics(ctx, 1)
+	if err != nil || len(metrics) != 1 || metrics[0].GoalsMerged != 1 {
+		t.Errorf("expected one merge counted, got %+v (%v)", metrics, err)
+	}
+}
@@ -0,0 +1,299 @@
+// internal/testinfra/integration_test.go
+package testinfra_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go-llama/internal/dialogue"
+	"go-llama/internal/llm"
+	"go-llama/internal/memory"
+	"go-llama/internal/testinfra"
+	"go-llama/internal/tools"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+const collection = "memories"
+
+var (
+	fakeQdrant *testinfra.FakeQdrant
+	fakeLLM    *testinfra.FakeLLM
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	fakeQdrant, err = testinfra.NewFakeQdrant()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start fake qdrant: %v\n", err)
+		os.Exit(1)
+	}
+	fakeLLM = testinfra.NewFakeLLM()
+
+	code := m.Run()
+
+	fakeLLM.Close()
+	fakeQdrant.Close()
+	os.Exit(code)
+}
+
+// The dialogue tables that default to NOW(), which sqlite rejects
+var dialogueDDL = []string{
+	`CREATE TABLE growerai_dialogue_state (id integer PRIMARY KEY, active_goals text NOT NULL DEFAULT '[]',
+		completed_goals text NOT NULL DEFAULT '[]', knowledge_gaps text NOT NULL DEFAULT '[]',
+		recent_failures text NOT NULL DEFAULT '[]', patterns text NOT NULL DEFAULT '[]',
+		focus_areas text NOT NULL DEFAULT '[]',
+		last_cycle_time datetime, cycle_count integer NOT NULL DEFAULT 0,
+		migration_memory_id_complete boolean NOT NULL DEFAULT false,
+		migration_is_collective_complete boolean NOT NULL DEFAULT false,
+		migration_source_kind_complete boolean NOT NULL DEFAULT false,
+		adaptive_state text, cycle_owner text, schema_version integer NOT NULL DEFAULT 0,
+		state_version integer NOT NULL DEFAULT 0, state_checksum text,
+		created_at datetime, updated_at datetime)`,
+	`CREATE TABLE growerai_dialogue_actions (id integer PRIMARY KEY AUTOINCREMENT, cycle_id integer NOT NULL,
+		goal_id text, action_id text, tool text NOT NULL, input text NOT NULL DEFAULT '',
+		output text NOT NULL DEFAULT '', success boolean NOT NULL DEFAULT false, error text,
+		duration_ms integer NOT NULL DEFAULT 0, "timestamp" datetime NOT NULL)`,
+	`CREATE TABLE growerai_dialogue_thoughts (id integer PRIMARY KEY AUTOINCREMENT,
+		cycle_id integer NOT NULL, thought_num integer NOT NULL DEFAULT 0, goal_id text, content text NOT NULL,
+		tokens_used integer NOT NULL DEFAULT 0, action_taken boolean NOT NULL DEFAULT false,
+		prompt_template text, "timestamp" datetime NOT NULL)`,
+}
+
+// reflectionReply is a structured reflection with one learning, distinct per cycle so
+// each counts as novel
+func reflectionReply(topic string) string {
+	return fmt.Sprintf(`(reasoning
+  (reflection "Cycle on %[1]s: searches about %[1]s returned useful sources and the plan held up")
+  (insights "%[1]s sources were reliable")
+  (learnings
+    (learning
+      (what "Searching %[1]s documentation first saves follow-up parses")
+      (context "research on %[1]s")
+      (confidence 0.8)
+      (category "research"))))`, topic)
+}
+
+func TestDialogueCyclesWriteFilterableMemories(t *testing.T) {
+	ctx := context.Background()
+	fakeQdrant.Reset()
+	fakeLLM.Reset()
+	t.Cleanup(fakeLLM.Reset)
+
+	db, err := testinfra.OpenDB([]interface{}{
+		&memory.Principle{}, &dialogue.DialogueMetrics{}, &dialogue.GoalArchive{}, &dialogue.ActionResult{}, &dialogue.StateVersion{},
+		&dialogue.GoalRecord{}, &dialogue.GoalActionRecord{}, &dialogue.GoalAnswer{}, &dialogue.DialogueGoalEvent{},
+	}, dialogueDDL...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := fakeQdrant.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	storage, err := memory.NewStorageFromClient(client, collection)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	embedder := memory.NewEmbedder(fakeLLM.EmbeddingsURL())
+
+	manager := llm.NewManager(llm.DefaultConfig(), nil)
+	defer manager.Stop()
+	llmClient := llm.NewClient(manager, llm.PriorityBackground, 30*time.Second)
+
+	compressor := memory.NewCompressor(fakeLLM.ChatURL(), "fake", embedder, memory.NewLinker(storage, 0.8, 5), llmClient)
+	tagger := memory.NewTagger(fakeLLM.ChatURL(), "fake", 10, embedder, llmClient)
+
+	registry := tools.NewContextualRegistry(tools.NewRegistry(), map[string]tools.ToolConfig{})
+	stateManager := dialogue.NewStateManager(db)
+	engine := dialogue.NewEngine(
+		storage, embedder, stateManager, registry, db,
+		fakeLLM.ChatURL(), "fake", 8192, llmClient, fakeLLM.ChatURL(), "fake",
+		20000, 5, 5, 3, 24, "moderate",
+		false, false, false, true, false, nil,
+	)
+	engine.SetConceptTagger(tagger)
+
+	fakeLLM.Script("Analyze recent activity", reflectionReply("qdrant"), reflectionReply("sqlite"))
+	fakeLLM.Script("Analyze this conversation", `(outcome "good" (confidence 0.8) (reason "useful"))`)
+	fakeLLM.Script("Extract 3-5 key concepts", `(concepts "research" "search")`)
+	fakeLLM.Script("Summarize the following memory in exactly 100 words", "A condensed reflection on research cycles.")
+
+	for i := 0; i < 2; i++ {
+		if err := engine.RunDialogueCycle(ctx); err != nil {
+			t.Fatalf("cycle %d failed: %v", i+1, err)
+		}
+	}
+
+	// Every memory the cycles wrote is collective dialogue learning, stamped with its cycle
+	points := fakeQdrant.Points(collection)
+	if len(points) == 0 {
+		t.Fatal("expected the cycles to write memories")
+	}
+	reflections := map[int64]string{}
+	learnings := 0
+	for _, p := range points {
+		payload := p.GetPayload()
+		if !payload["is_collective"].GetBoolValue() {
+			t.Errorf("memory %v is not collective: %v", p.GetId(), payload)
+		}
+		if got := payload["source_kind"].GetStringValue(); got != string(memory.SourceDialogueLearning) {
+			t.Errorf("memory %v has source_kind %q", p.GetId(), got)
+		}
+		metadata := payload["metadata"].GetStructValue().GetFields()
+		switch metadata["type"].GetStringValue() {
+		case "reflection":
+			if got := payload["tier"].GetStringValue(); got != string(memory.TierRecent) {
+				t.Errorf("reflection %v has tier %q", p.GetId(), got)
+			}
+			cycle, ok := cycleID(metadata[dialogue.MetadataCycleID])
+			if !ok {
+				t.Errorf("reflection %v has no cycle id: %v", p.GetId(), metadata)
+			}
+			reflections[cycle] = p.GetId().GetUuid()
+		default:
+			if hasValue(payload["concept_tags"], "learning") {
+				learnings++
+			}
+		}
+	}
+	if len(reflections) != 2 || reflections[1] == "" || reflections[2] == "" {
+		t.Fatalf("expected one reflection from each of cycles 1 and 2, got %v", reflections)
+	}
+	if learnings == 0 {
+		t.Error("expected the cycles to store learnings tagged \"learning\"")
+	}
+
+	// Storage's filtered reads find them through the fake's payload filters
+	collective, err := storage.CountCollectiveMemories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collective != len(points) {
+		t.Errorf("expected %d collective memories, counted %d", len(points), collective)
+	}
+	recent, err := storage.CountMemoriesByTier(ctx, memory.TierRecent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recent != len(points) {
+		t.Errorf("expected %d recent memories, counted %d", len(points), recent)
+	}
+	query, err := embedder.Embed(ctx, "research documentation searches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagged, err := storage.Search(ctx, memory.RetrievalQuery{
+		Limit: 10, IncludeCollective: true, ConceptTags: []string{"learning"},
+	}, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != learnings {
+		t.Errorf("expected a concept-tag search to find %d learnings, found %d", learnings, len(tagged))
+	}
+	for _, r := range tagged {
+		if !hasTag(r.Memory.ConceptTags, "learning") {
+			t.Errorf("concept-tag search returned untagged memory %s", r.Memory.ID)
+		}
+		if len(r.Memory.Embedding) != 0 {
+			t.Errorf("expected no embedding unless asked for, got %d dimensions", len(r.Memory.Embedding))
+		}
+	}
+	withVectors, err := storage.Search(ctx, memory.RetrievalQuery{
+		Limit: 10, IncludeCollective: true, ConceptTags: []string{"learning"}, WithVectors: true,
+	}, query)
+	if err != nil || len(withVectors) == 0 || len(withVectors[0].Memory.Embedding) != len(query) {
+		t.Errorf("expected searches asking for vectors to return embeddings, got %d results (%v)", len(withVectors), err)
+	}
+	personal, err := storage.Search(ctx, memory.RetrievalQuery{
+		Limit: 10, IncludePersonal: true, UserID: stringPtr("someone"),
+	}, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(personal) != 0 {
+		t.Errorf("expected a personal search to skip collective memories, found %d", len(personal))
+	}
+
+	metrics, err := stateManager.RecentMetrics(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 2 {
+		t.Errorf("expected metrics for 2 cycles, got %d", len(metrics))
+	}
+
+	// The tagger settles every memory still without an outcome
+	if err := tagger.TagMemories(ctx, storage); err != nil {
+		t.Fatalf("tagging failed: %v", err)
+	}
+	untagged, err := storage.FindUntaggedMemories(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(untagged) != 0 {
+		t.Errorf("expected every memory tagged, %d remain", len(untagged))
+	}
+
+	// Compressing a reflection moves it to the medium tier
+	mem, err := storage.GetMemoryByID(ctx, reflections[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := compressor.Compress(ctx, mem, memory.TierMedium)
+	if err != nil {
+		t.Fatalf("compression failed: %v", err)
+	}
+	if compressed.Embedding, err = embedder.Embed(ctx, compressed.Content); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.UpdateMemory(ctx, compressed); err != nil {
+		t.Fatal(err)
+	}
+	medium, err := storage.CountMemoriesByTier(ctx, memory.TierMedium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if medium != 1 {
+		t.Errorf("expected 1 medium memory after compression, counted %d", medium)
+	}
+}
+
+// cycleID reads a cycle id, which a JSON round trip may have made a double
+func cycleID(v *qdrant.Value) (int64, bool) {
+	switch kind := v.GetKind().(type) {
+	case *qdrant.Value_IntegerValue:
+		return kind.IntegerValue, true
+	case *qdrant.Value_DoubleValue:
+		return int64(kind.DoubleValue), true
+	}
+	return 0, false
+}
+
+func hasValue(v *qdrant.Value, s string) bool {
+	for _, item := range v.GetListValue().GetValues() {
+		if item.GetStringValue() == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,248 @@
+// internal/testinfra/llm.go
+package testinfra
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// EmbeddingDims is the size of the fake's embeddings, the size Storage requires
+const EmbeddingDims = 384
+
+// Paths the fake LLM serves
+const (
+	ChatCompletionsPath = "/v1/chat/completions"
+	EmbeddingsPath      = "/v1/embeddings"
+)
+
+// ChatMessage is one message of a completion request
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CompletionRequest is a chat completion request the fake received
+type CompletionRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+}
+
+// Prompt is the content of the last message, which carries the instructions
+func (r CompletionRequest) Prompt() string {
+	if len(r.Messages) == 0 {
+		return ""
+	}
+	return r.Messages[len(r.Messages)-1].Content
+}
+
+// script answers prompts containing a marker with its replies in turn, repeating
+// the last one once they run out
+type script struct {
+	marker  string
+	replies []string
+	served  int
+}
+
+// FakeLLM is an OpenAI-compatible server for the chat completions and embeddings the
+// engine, compressor, tagger and embedder call. Completions are scripted: the first
+// script whose marker appears in the request's prompt answers it, and a prompt no
+// script matches gets the fallback reply. Embeddings are deterministic bags of words,
+// so texts sharing words are similar and the same text always embeds the same way.
+type FakeLLM struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	scripts  []*script
+	fallback string
+	requests []CompletionRequest
+	embedded []string
+}
+
+// NewFakeLLM starts a fake LLM server
+func NewFakeLLM() *FakeLLM {
+	f := &FakeLLM{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(ChatCompletionsPath, f.handleCompletion)
+	mux.HandleFunc(EmbeddingsPath, f.handleEmbeddings)
+	f.server = httptest.NewServer(mux)
+	return f
+}
+
+// URL is the server's base URL
+func (f *FakeLLM) URL() string {
+	return f.server.URL
+}
+
+// ChatURL is the chat completions endpoint
+func (f *FakeLLM) ChatURL() string {
+	return f.server.URL + ChatCompletionsPath
+}
+
+// EmbeddingsURL is the embeddings endpoint
+func (f *FakeLLM) EmbeddingsURL() string {
+	return f.server.URL + EmbeddingsPath
+}
+
+// Close stops the server
+func (f *FakeLLM) Close() {
+	f.server.Close()
+}
+
+// Script answers prompts containing marker with replies, one per request in order.
+// The last reply repeats once the others are used. Scripts are tried in the order
+// they were added.
+func (f *FakeLLM) Script(marker string, replies ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts = append(f.scripts, &script{marker: marker, replies: replies})
+}
+
+// SetFallback sets the reply to prompts no script matches (empty by default)
+func (f *FakeLLM) SetFallback(reply string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fallback = reply
+}
+
+// Reset forgets scripts, the fallback and recorded requests
+func (f *FakeLLM) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts, f.fallback, f.requests, f.embedded = nil, "", nil, nil
+}
+
+// Requests returns the completion requests received so far
+func (f *FakeLLM) Requests() []CompletionRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]CompletionRequest(nil), f.requests...)
+}
+
+// Embedded returns the texts embedded so far
+func (f *FakeLLM) Embedded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.embedded...)
+}
+
+// reply picks the answer to a request and records it
+func (f *FakeLLM) reply(req CompletionRequest) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+
+	prompt := req.Prompt()
+	for _, s := range f.scripts {
+		if !strings.Contains(prompt, s.marker) || len(s.replies) == 0 {
+			continue
+		}
+		i := s.served
+		if i >= len(s.replies) {
+			i = len(s.replies) - 1
+		}
+		s.served++
+		return s.replies[i]
+	}
+	return f.fallback
+}
+
+func (f *FakeLLM) handleCompletion(w http.ResponseWriter, r *http.Request) {
+	var req CompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	content := f.reply(req)
+
+	promptTokens := 0
+	for _, m := range req.Messages {
+		promptTokens += estimateTokens(m.Content)
+	}
+	completionTokens := estimateTokens(content)
+	writeJSON(w, map[string]interface{}{
+		"object": "chat.completion",
+		"model":  req.Model,
+		"choices": []map[string]interface{}{{
+			"index":         0,
+			"message":       map[string]string{"role": "assistant", "content": content},
+			"finish_reason": "stop",
+		}},
+		"usage": map[string]int{
+			"prompt_tokens":     promptTokens,
+			"completion_tokens": completionTokens,
+			"total_tokens":      promptTokens + completionTokens,
+		},
+	})
+}
+
+func (f *FakeLLM) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Input json.RawMessage `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var inputs []string
+	if err := json.Unmarshal(req.Input, &inputs); err != nil {
+		var single string
+		if err := json.Unmarshal(req.Input, &single); err != nil {
+			http.Error(w, "input must be a string or an array of strings", http.StatusBadRequest)
+			return
+		}
+		inputs = []string{single}
+	}
+
+	f.mu.Lock()
+	f.embedded = append(f.embedded, inputs...)
+	f.mu.Unlock()
+
+	data := make([]map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		data[i] = map[string]interface{}{"object": "embedding", "index": i, "embedding": Embed(input)}
+	}
+	writeJSON(w, map[string]interface{}{"object": "list", "data": data})
+}
+
+// Embed is the fake's embedding of text: each word adds to one hashed dimension and
+// the vector is normalised. Text without words embeds to a fixed unit vector.
+func Embed(text string) []float32 {
+	vector := make([]float32, EmbeddingDims)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%EmbeddingDims]++
+	}
+	if len(words) == 0 {
+		vector[0] = 1
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
+
+// estimateTokens approximates a token count at four characters a token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,127 @@
+package testinfra_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-llama/internal/memory"
+)
+
+func statsEmbedding(seed int) []float32 {
+	embedding := make([]float32, 384)
+	embedding[seed%384] = 1
+	return embedding
+}
+
+func TestMemoryStatsAggregates(t *testing.T) {
+	ctx := context.Background()
+	client, err := fakeQdrant.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	storage, err := memory.NewStorageFromClient(client, "memory_stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	memories := []memory.Memory{
+		{Tier: memory.TierRecent, TrustScore: 0.05, ConceptTags: []string{"go", "qdrant"}, SourceKind: memory.SourceDialogueLearning, CreatedAt: now},
+		{Tier: memory.TierRecent, TrustScore: 0.55, ConceptTags: []string{"go"}, SourceKind: memory.SourceDialogueLearning, CreatedAt: now},
+		{Tier: memory.TierRecent, TrustScore: 1.0, ConceptTags: []string{"go", "go"}, SourceKind: memory.SourceResearchSynthesis, CreatedAt: now.AddDate(0, 0, -1)},
+		{Tier: memory.TierMedium, TrustScore: 0.5, ConceptTags: []string{"sqlite"}, SourceKind: memory.SourceUserConversation, CreatedAt: now.AddDate(0, 0, -3)},
+		{Tier: memory.TierAncient, TrustScore: 0.95, ConceptTags: []string{"qdrant"}, SourceKind: memory.SourceDigest, CreatedAt: now.AddDate(0, 0, -90)},
+	}
+	for i := range memories {
+		memories[i].Content = "memory for stats"
+		memories[i].Embedding = statsEmbedding(i)
+		if err := storage.Store(ctx, &memories[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := storage.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 5 {
+		t.Errorf("expected 5 memories, got %d", stats.Total)
+	}
+	if stats.ByTier[memory.TierRecent] != 3 || stats.ByTier[memory.TierMedium] != 1 || stats.ByTier[memory.TierLong] != 0 || stats.ByTier[memory.TierAncient] != 1 {
+		t.Errorf("unexpected tier counts %v", stats.ByTier)
+	}
+
+	// A memory counts once per tag, however often it repeats it
+	if len(stats.TopConceptTags) != 3 || stats.TopConceptTags[0] != (memory.TagCount{Tag: "go", Count: 3}) ||
+		stats.TopConceptTags[1] != (memory.TagCount{Tag: "qdrant", Count: 2}) {
+		t.Errorf("unexpected top tags %v", stats.TopConceptTags)
+	}
+	if top, err := storage.TopConceptTags(ctx, 1); err != nil || len(top) != 1 || top[0].Tag != "go" {
+		t.Errorf("expected only the top tag, got %v (%v)", top, err)
+	}
+
+	if stats.BySourceKind[memory.SourceDialogueLearning] != 2 || stats.BySourceKind[memory.SourcePrincipleRelated] != 0 {
+		t.Errorf("unexpected source kind counts %v", stats.BySourceKind)
+	}
+
+	// 0.5 and 0.55 share a bucket; 0.95 and 1.0 fall in the last, which includes 1
+	histogram := stats.TrustHistogram
+	if len(histogram) != memory.DefaultStatsTrustBuckets {
+		t.Fatalf("expected %d buckets, got %d", memory.DefaultStatsTrustBuckets, len(histogram))
+	}
+	if histogram[0].Count != 1 || histogram[5].Count != 2 || histogram[9].Count != 2 || histogram[9].Max != 1 {
+		t.Errorf("unexpected trust histogram %+v", histogram)
+	}
+
+	days := stats.CreatedPerDay
+	if len(days) != memory.DefaultStatsDays || days[len(days)-1].Day != now.Format("2006-01-02") {
+		t.Fatalf("expected %d days ending today, got %v", memory.DefaultStatsDays, days)
+	}
+	if days[len(days)-1].Count != 2 || days[len(days)-2].Count != 1 || days[len(days)-4].Count != 1 {
+		t.Errorf("unexpected daily counts %v", days[len(days)-4:])
+	}
+	total := 0
+	for _, day := range days {
+		total += day.Count
+	}
+	if total != 4 {
+		t.Errorf("expected the 90-day-old memory outside the window, counted %d", total)
+	}
+}
+
+func TestMemoryStatsCache(t *testing.T) {
+	ctx := context.Background()
+	client, err := fakeQdrant.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	storage, err := memory.NewStorageFromClient(client, "memory_stats_cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := memory.NewStatsCache(storage)
+
+	first, err := cache.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem := memory.Memory{Content: "new", Tier: memory.TierRecent, Embedding: statsEmbedding(1), CreatedAt: time.Now()}
+	if err := storage.Store(ctx, &mem); err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := cache.Get(ctx); cached != first || cached.Total != 0 {
+		t.Errorf("expected the cached stats within the TTL, got total %d", cached.Total)
+	}
+
+	fresh, err := storage.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Put(fresh)
+	if cached, _ := cache.Get(ctx); cached.Total != 1 {
+		t.Errorf("expected the stats put in the cache, got total %d", cached.Total)
+	}
+}
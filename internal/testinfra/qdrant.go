@@ -0,0 +1,692 @@
+// internal/testinfra/qdrant.go
+package testinfra
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// FakeQdrantVersion is the server version the fake reports to health checks
+const FakeQdrantVersion = "1.16.2"
+
+// fakePoint is one stored point
+type fakePoint struct {
+	id      *qdrant.PointId
+	vector  []float32
+	payload map[string]*qdrant.Value
+}
+
+// fakeCollection is one collection's points and payload indexes
+type fakeCollection struct {
+	size   uint64
+	schema map[string]qdrant.PayloadSchemaType
+	points map[string]*fakePoint
+}
+
+// FakeQdrant is an in-memory Qdrant served over gRPC, the API Storage uses. It covers
+// the calls the memory, goal and skill stores make: collections and payload indexes,
+// upsert, delete, get, scroll, count, facet counts, payload updates and nearest-neighbour
+// queries by cosine similarity. Filters are evaluated the way Qdrant does (a condition on an
+// array field matches if any element does, dotted keys reach into nested objects), so
+// a wrong field name or match type finds nothing here as it would in production. Any
+// request it does not implement fails with codes.Unimplemented rather than being
+// silently ignored.
+type FakeQdrant struct {
+	mu          sync.RWMutex
+	collections map[string]*fakeCollection
+
+	server   *grpc.Server
+	listener net.Listener
+}
+
+// The gRPC services share the fake's collections
+type collectionsService struct {
+	qdrant.UnimplementedCollectionsServer
+	*FakeQdrant
+}
+
+type pointsService struct {
+	qdrant.UnimplementedPointsServer
+	*FakeQdrant
+}
+
+type healthService struct {
+	qdrant.UnimplementedQdrantServer
+}
+
+// NewFakeQdrant starts a fake Qdrant on a free local port
+func NewFakeQdrant() (*FakeQdrant, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	f := &FakeQdrant{
+		collections: make(map[string]*fakeCollection),
+		server:      grpc.NewServer(),
+		listener:    listener,
+	}
+	qdrant.RegisterCollectionsServer(f.server, collectionsService{FakeQdrant: f})
+	qdrant.RegisterPointsServer(f.server, pointsService{FakeQdrant: f})
+	qdrant.RegisterQdrantServer(f.server, healthService{})
+	go f.server.Serve(listener)
+	return f, nil
+}
+
+// Addr is the host:port the fake listens on
+func (f *FakeQdrant) Addr() string {
+	return f.listener.Addr().String()
+}
+
+// Client returns a Qdrant client connected to the fake
+func (f *FakeQdrant) Client() (*qdrant.Client, error) {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return qdrant.NewClient(&qdrant.Config{
+		Host:                   addr.IP.String(),
+		Port:                   addr.Port,
+		PoolSize:               1,
+		SkipCompatibilityCheck: true,
+	})
+}
+
+// Close stops the server
+func (f *FakeQdrant) Close() {
+	f.server.Stop()
+}
+
+// Points returns copies of every point in a collection, in scroll order, with payload
+// and vectors, so a test can check exactly what was written
+func (f *FakeQdrant) Points(collection string) []*qdrant.RetrievedPoint {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	c, ok := f.collections[collection]
+	if !ok {
+		return nil
+	}
+	points := make([]*qdrant.RetrievedPoint, 0, len(c.points))
+	for _, p := range c.sorted() {
+		points = append(points, p.retrieved(qdrant.NewWithPayload(true), qdrant.NewWithVectors(true)))
+	}
+	return points
+}
+
+// Reset drops every collection
+func (f *FakeQdrant) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.collections = make(map[string]*fakeCollection)
+}
+
+// HealthCheck reports the fake's version
+func (f healthService) HealthCheck(ctx context.Context, req *qdrant.HealthCheckRequest) (*qdrant.HealthCheckReply, error) {
+	return &qdrant.HealthCheckReply{Title: "qdrant - fake", Version: FakeQdrantVersion}, nil
+}
+
+// CollectionExists reports whether the collection was created
+func (f collectionsService) CollectionExists(ctx context.Context, req *qdrant.CollectionExistsRequest) (*qdrant.CollectionExistsResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.collections[req.GetCollectionName()]
+	return &qdrant.CollectionExistsResponse{Result: &qdrant.CollectionExists{Exists: ok}}, nil
+}
+
+// List names the collections
+func (f collectionsService) List(ctx context.Context, req *qdrant.ListCollectionsRequest) (*qdrant.ListCollectionsResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	names := make([]string, 0, len(f.collections))
+	for name := range f.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	resp := &qdrant.ListCollectionsResponse{}
+	for _, name := range names {
+		resp.Collections = append(resp.Collections, &qdrant.CollectionDescription{Name: name})
+	}
+	return resp, nil
+}
+
+// Create creates a collection of single unnamed dense vectors
+func (f collectionsService) Create(ctx context.Context, req *qdrant.CreateCollection) (*qdrant.CollectionOperationResponse, error) {
+	params := req.GetVectorsConfig().GetParams()
+	if params == nil || params.GetSize() == 0 {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant supports only a single unnamed dense vector")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.collections[req.GetCollectionName()]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "Collection `%s` already exists!", req.GetCollectionName())
+	}
+	f.collections[req.GetCollectionName()] = &fakeCollection{
+		size:   params.GetSize(),
+		schema: make(map[string]qdrant.PayloadSchemaType),
+		points: make(map[string]*fakePoint),
+	}
+	return &qdrant.CollectionOperationResponse{Result: true}, nil
+}
+
+// Delete drops a collection
+func (f collectionsService) Delete(ctx context.Context, req *qdrant.DeleteCollection) (*qdrant.CollectionOperationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.collections[req.GetCollectionName()]
+	delete(f.collections, req.GetCollectionName())
+	return &qdrant.CollectionOperationResponse{Result: ok}, nil
+}
+
+// Get describes a collection: its point count and payload indexes
+func (f collectionsService) Get(ctx context.Context, req *qdrant.GetCollectionInfoRequest) (*qdrant.GetCollectionInfoResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	schema := make(map[string]*qdrant.PayloadSchemaInfo, len(c.schema))
+	for field, typ := range c.schema {
+		schema[field] = &qdrant.PayloadSchemaInfo{DataType: typ}
+	}
+	count := uint64(len(c.points))
+	return &qdrant.GetCollectionInfoResponse{Result: &qdrant.CollectionInfo{
+		Status:        qdrant.CollectionStatus_Green,
+		PayloadSchema: schema,
+		PointsCount:   &count,
+	}}, nil
+}
+
+// CreateFieldIndex records a payload index
+func (f pointsService) CreateFieldIndex(ctx context.Context, req *qdrant.CreateFieldIndexCollection) (*qdrant.PointsOperationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	// FieldType counts from keyword = 0, PayloadSchemaType from unknown = 0
+	c.schema[req.GetFieldName()] = qdrant.PayloadSchemaType(req.GetFieldType() + 1)
+	return completed(), nil
+}
+
+// DeleteFieldIndex removes a payload index
+func (f pointsService) DeleteFieldIndex(ctx context.Context, req *qdrant.DeleteFieldIndexCollection) (*qdrant.PointsOperationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	delete(c.schema, req.GetFieldName())
+	return completed(), nil
+}
+
+// Upsert stores points, replacing any with the same ID
+func (f pointsService) Upsert(ctx context.Context, req *qdrant.UpsertPoints) (*qdrant.PointsOperationResponse, error) {
+	if req.GetUpdateFilter() != nil {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant does not support conditional upserts")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate every point before storing any, as Qdrant rejects the whole batch
+	points := make([]*fakePoint, 0, len(req.GetPoints()))
+	for _, p := range req.GetPoints() {
+		vector := denseVector(p.GetVectors().GetVector())
+		if uint64(len(vector)) != c.size {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"Wrong input: Vector dimension error: expected dim: %d, got %d", c.size, len(vector))
+		}
+		if pointKey(p.GetId()) == "" {
+			return nil, status.Error(codes.InvalidArgument, "Wrong input: point without an ID")
+		}
+		points = append(points, &fakePoint{
+			id:      proto.Clone(p.GetId()).(*qdrant.PointId),
+			vector:  append([]float32(nil), vector...),
+			payload: clonePayload(p.GetPayload()),
+		})
+	}
+	for _, p := range points {
+		c.points[pointKey(p.id)] = p
+	}
+	return completed(), nil
+}
+
+// Delete removes the selected points
+func (f pointsService) Delete(ctx context.Context, req *qdrant.DeletePoints) (*qdrant.PointsOperationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	selected, err := c.selectPoints(req.GetPoints())
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range selected {
+		delete(c.points, pointKey(p.id))
+	}
+	return completed(), nil
+}
+
+// Get returns the points with the given IDs that exist, in the order asked for
+func (f pointsService) Get(ctx context.Context, req *qdrant.GetPoints) (*qdrant.GetResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	resp := &qdrant.GetResponse{}
+	for _, id := range req.GetIds() {
+		if p, ok := c.points[pointKey(id)]; ok {
+			resp.Result = append(resp.Result, p.retrieved(req.GetWithPayload(), req.GetWithVectors()))
+		}
+	}
+	return resp, nil
+}
+
+// Scroll pages through matching points in ID order
+func (f pointsService) Scroll(ctx context.Context, req *qdrant.ScrollPoints) (*qdrant.ScrollResponse, error) {
+	if req.GetOrderBy() != nil {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant does not support order_by")
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	limit := 10
+	if req.Limit != nil {
+		limit = int(req.GetLimit())
+	}
+	offset := pointKey(req.GetOffset())
+
+	resp := &qdrant.ScrollResponse{}
+	for _, p := range c.sorted() {
+		if offset != "" && pointKey(p.id) < offset {
+			continue
+		}
+		ok, err := matchFilter(req.GetFilter(), p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if len(resp.Result) == limit {
+			resp.NextPageOffset = proto.Clone(p.id).(*qdrant.PointId)
+			break
+		}
+		resp.Result = append(resp.Result, p.retrieved(req.GetWithPayload(), req.GetWithVectors()))
+	}
+	return resp, nil
+}
+
+// Count counts matching points
+func (f pointsService) Count(ctx context.Context, req *qdrant.CountPoints) (*qdrant.CountResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	var count uint64
+	for _, p := range c.points {
+		ok, err := matchFilter(req.GetFilter(), p)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			count++
+		}
+	}
+	return &qdrant.CountResponse{Result: &qdrant.CountResult{Count: count}}, nil
+}
+
+// Facet counts the matching points holding each value of an indexed keyword, integer
+// or bool field, most common first. As in Qdrant, a point counts once per distinct
+// value of an array field, and a field without a payload index cannot be faceted.
+func (f pointsService) Facet(ctx context.Context, req *qdrant.FacetCounts) (*qdrant.FacetResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	switch c.schema[req.GetKey()] {
+	case qdrant.PayloadSchemaType_Keyword, qdrant.PayloadSchemaType_Integer, qdrant.PayloadSchemaType_Bool:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Bad request: Index required but not found for \"%s\" of one of the following types: [keyword, integer, bool]", req.GetKey())
+	}
+
+	type facet struct {
+		value *qdrant.FacetValue
+		count uint64
+	}
+	counts := map[string]*facet{}
+	for _, p := range c.sorted() {
+		ok, err := matchFilter(req.GetFilter(), p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, v := range scalars(lookup(p.payload, req.GetKey())) {
+			value, key := facetValue(v)
+			if value == nil || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if counts[key] == nil {
+				counts[key] = &facet{value: value}
+			}
+			counts[key].count++
+		}
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]].count != counts[keys[j]].count {
+			return counts[keys[i]].count > counts[keys[j]].count
+		}
+		return keys[i] < keys[j]
+	})
+	limit := 10
+	if req.Limit != nil {
+		limit = int(req.GetLimit())
+	}
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	resp := &qdrant.FacetResponse{}
+	for _, key := range keys {
+		resp.Hits = append(resp.Hits, &qdrant.FacetHit{Value: counts[key].value, Count: counts[key].count})
+	}
+	return resp, nil
+}
+
+// facetValue converts a payload value to a facet value and a key that sorts values of
+// one type in order; other types have no facet value
+func facetValue(v *qdrant.Value) (*qdrant.FacetValue, string) {
+	switch kind := v.GetKind().(type) {
+	case *qdrant.Value_StringValue:
+		return &qdrant.FacetValue{Variant: &qdrant.FacetValue_StringValue{StringValue: kind.StringValue}}, "s:" + kind.StringValue
+	case *qdrant.Value_IntegerValue:
+		return &qdrant.FacetValue{Variant: &qdrant.FacetValue_IntegerValue{IntegerValue: kind.IntegerValue}}, fmt.Sprintf("i:%020d", kind.IntegerValue)
+	case *qdrant.Value_BoolValue:
+		return &qdrant.FacetValue{Variant: &qdrant.FacetValue_BoolValue{BoolValue: kind.BoolValue}}, fmt.Sprintf("b:%t", kind.BoolValue)
+	}
+	return nil, ""
+}
+
+// Query returns the matching points nearest a dense vector by cosine similarity
+func (f pointsService) Query(ctx context.Context, req *qdrant.QueryPoints) (*qdrant.QueryResponse, error) {
+	if len(req.GetPrefetch()) > 0 || req.Using != nil {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant does not support prefetch or named vectors")
+	}
+	nearest := req.GetQuery().GetNearest()
+	if nearest == nil {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant supports only nearest queries")
+	}
+	query := nearest.GetDense().GetData()
+	if len(query) == 0 {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant supports only dense query vectors")
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	c, err := f.collection(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(query)) != c.size {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Wrong input: Vector dimension error: expected dim: %d, got %d", c.size, len(query))
+	}
+
+	type scored struct {
+		point *fakePoint
+		score float32
+	}
+	var matches []scored
+	for _, p := range c.sorted() {
+		ok, err := matchFilter(req.GetFilter(), p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		score := cosine(query, p.vector)
+		if req.ScoreThreshold != nil && score < req.GetScoreThreshold() {
+			continue
+		}
+		matches = append(matches, scored{point: p, score: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	limit := 10
+	if req.Limit != nil {
+		limit = int(req.GetLimit())
+	}
+	start := int(req.GetOffset())
+	if start > len(matches) {
+		start = len(matches)
+	}
+	matches = matches[start:]
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	resp := &qdrant.QueryResponse{}
+	for _, m := range matches {
+		retrieved := m.point.retrieved(req.GetWithPayload(), req.GetWithVectors())
+		resp.Result = append(resp.Result, &qdrant.ScoredPoint{
+			Id:      retrieved.Id,
+			Payload: retrieved.Payload,
+			Vectors: retrieved.Vectors,
+			Score:   m.score,
+		})
+	}
+	return resp, nil
+}
+
+// SetPayload merges keys into the selected points' payloads
+func (f pointsService) SetPayload(ctx context.Context, req *qdrant.SetPayloadPoints) (*qdrant.PointsOperationResponse, error) {
+	if req.Key != nil {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant does not support setting a nested payload key")
+	}
+	return f.updatePayload(req.GetCollectionName(), req.GetPointsSelector(), func(p *fakePoint) {
+		for key, value := range req.GetPayload() {
+			p.payload[key] = proto.Clone(value).(*qdrant.Value)
+		}
+	})
+}
+
+// OverwritePayload replaces the selected points' payloads
+func (f pointsService) OverwritePayload(ctx context.Context, req *qdrant.SetPayloadPoints) (*qdrant.PointsOperationResponse, error) {
+	if req.Key != nil {
+		return nil, status.Error(codes.Unimplemented, "fake qdrant does not support setting a nested payload key")
+	}
+	return f.updatePayload(req.GetCollectionName(), req.GetPointsSelector(), func(p *fakePoint) {
+		p.payload = clonePayload(req.GetPayload())
+	})
+}
+
+// DeletePayload removes keys from the selected points' payloads
+func (f pointsService) DeletePayload(ctx context.Context, req *qdrant.DeletePayloadPoints) (*qdrant.PointsOperationResponse, error) {
+	return f.updatePayload(req.GetCollectionName(), req.GetPointsSelector(), func(p *fakePoint) {
+		for _, key := range req.GetKeys() {
+			delete(p.payload, key)
+		}
+	})
+}
+
+// updatePayload applies update to each selected point
+func (f pointsService) updatePayload(collection string, selector *qdrant.PointsSelector, update func(*fakePoint)) (*qdrant.PointsOperationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.collection(collection)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := c.selectPoints(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range selected {
+		update(p)
+	}
+	return completed(), nil
+}
+
+// collection returns a collection, or the NotFound error Qdrant gives for a missing one
+func (f *FakeQdrant) collection(name string) (*fakeCollection, error) {
+	c, ok := f.collections[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Collection `%s` doesn't exist!", name)
+	}
+	return c, nil
+}
+
+// sorted returns the collection's points in ID order, the order Qdrant scrolls in
+func (c *fakeCollection) sorted() []*fakePoint {
+	keys := make([]string, 0, len(c.points))
+	for key := range c.points {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	points := make([]*fakePoint, len(keys))
+	for i, key := range keys {
+		points[i] = c.points[key]
+	}
+	return points
+}
+
+// selectPoints resolves a selector by IDs or by filter
+func (c *fakeCollection) selectPoints(selector *qdrant.PointsSelector) ([]*fakePoint, error) {
+	if ids := selector.GetPoints(); ids != nil {
+		var selected []*fakePoint
+		for _, id := range ids.GetIds() {
+			if p, ok := c.points[pointKey(id)]; ok {
+				selected = append(selected, p)
+			}
+		}
+		return selected, nil
+	}
+	if filter := selector.GetFilter(); filter != nil {
+		var selected []*fakePoint
+		for _, p := range c.sorted() {
+			ok, err := matchFilter(filter, p)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				selected = append(selected, p)
+			}
+		}
+		return selected, nil
+	}
+	return nil, status.Error(codes.InvalidArgument, "Wrong input: empty points selector")
+}
+
+// retrieved copies the point with the payload and vector the request asked for
+func (p *fakePoint) retrieved(withPayload *qdrant.WithPayloadSelector, withVectors *qdrant.WithVectorsSelector) *qdrant.RetrievedPoint {
+	out := &qdrant.RetrievedPoint{Id: proto.Clone(p.id).(*qdrant.PointId)}
+	if withPayload.GetEnable() {
+		out.Payload = clonePayload(p.payload)
+	} else if include := withPayload.GetInclude(); include != nil {
+		out.Payload = make(map[string]*qdrant.Value)
+		for _, key := range include.GetFields() {
+			if value, ok := p.payload[key]; ok {
+				out.Payload[key] = proto.Clone(value).(*qdrant.Value)
+			}
+		}
+	} else if exclude := withPayload.GetExclude(); exclude != nil {
+		out.Payload = clonePayload(p.payload)
+		for _, key := range exclude.GetFields() {
+			delete(out.Payload, key)
+		}
+	}
+	if withVectors.GetEnable() {
+		vector := append([]float32(nil), p.vector...)
+		// Qdrant still fills the deprecated data field, which Storage reads
+		out.Vectors = &qdrant.VectorsOutput{VectorsOptions: &qdrant.VectorsOutput_Vector{Vector: &qdrant.VectorOutput{
+			Data:   vector,
+			Vector: &qdrant.VectorOutput_Dense{Dense: &qdrant.DenseVector{Data: vector}},
+		}}}
+	}
+	return out
+}
+
+// completed is the response to a write that was applied
+func completed() *qdrant.PointsOperationResponse {
+	return &qdrant.PointsOperationResponse{Result: &qdrant.UpdateResult{Status: qdrant.UpdateStatus_Completed}}
+}
+
+// pointKey is a point ID as a map key ("" for none). Numeric IDs sort before UUIDs.
+func pointKey(id *qdrant.PointId) string {
+	switch v := id.GetPointIdOptions().(type) {
+	case *qdrant.PointId_Num:
+		return fmt.Sprintf("0:%020d", v.Num)
+	case *qdrant.PointId_Uuid:
+		return "1:" + v.Uuid
+	}
+	return ""
+}
+
+// denseVector reads a dense vector from either of the fields clients set
+func denseVector(v *qdrant.Vector) []float32 {
+	if dense := v.GetDense(); dense != nil {
+		return dense.GetData()
+	}
+	return v.GetData()
+}
+
+// clonePayload deep-copies a payload so callers cannot change stored points
+func clonePayload(payload map[string]*qdrant.Value) map[string]*qdrant.Value {
+	out := make(map[string]*qdrant.Value, len(payload))
+	for key, value := range payload {
+		out[key] = proto.Clone(value).(*qdrant.Value)
+	}
+	return out
+}
+
+// cosine is the cosine similarity of two vectors of the same length
+func cosine(a, b []float32) float32 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
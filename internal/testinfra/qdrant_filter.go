@@ -0,0 +1,253 @@
+// internal/testinfra/qdrant_filter.go
+package testinfra
+
+import (
+	"strings"
+
+	"github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// matchFilter reports whether a point passes a filter: every must condition, no
+// must_not condition and, when there are should conditions, at least one of them (or
+// min_should of them)
+func matchFilter(filter *qdrant.Filter, p *fakePoint) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	for _, c := range filter.GetMust() {
+		ok, err := matchCondition(c, p)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	for _, c := range filter.GetMustNot() {
+		ok, err := matchCondition(c, p)
+		if err != nil || ok {
+			return false, err
+		}
+	}
+
+	should := filter.GetShould()
+	need := 0
+	if len(should) > 0 {
+		need = 1
+	}
+	if min := filter.GetMinShould(); min != nil {
+		should = append(should, min.GetConditions()...)
+		need = int(min.GetMinCount())
+	}
+	matched := 0
+	for _, c := range should {
+		ok, err := matchCondition(c, p)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			matched++
+		}
+	}
+	return matched >= need, nil
+}
+
+// matchCondition evaluates one condition against a point
+func matchCondition(c *qdrant.Condition, p *fakePoint) (bool, error) {
+	switch cond := c.GetConditionOneOf().(type) {
+	case *qdrant.Condition_Field:
+		return matchField(cond.Field, lookup(p.payload, cond.Field.GetKey()))
+	case *qdrant.Condition_IsEmpty:
+		return isEmpty(lookup(p.payload, cond.IsEmpty.GetKey())), nil
+	case *qdrant.Condition_IsNull:
+		values := lookup(p.payload, cond.IsNull.GetKey())
+		return len(values) == 1 && isNull(values[0]), nil
+	case *qdrant.Condition_HasId:
+		key := pointKey(p.id)
+		for _, id := range cond.HasId.GetHasId() {
+			if pointKey(id) == key {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *qdrant.Condition_Filter:
+		return matchFilter(cond.Filter, p)
+	}
+	return false, status.Errorf(codes.Unimplemented, "fake qdrant does not support condition %T", c.GetConditionOneOf())
+}
+
+// matchField evaluates a field condition against the values found at its key. As in
+// Qdrant, a condition on an array matches if any element matches.
+func matchField(fc *qdrant.FieldCondition, values []*qdrant.Value) (bool, error) {
+	switch {
+	case fc.GetMatch() != nil:
+		return matchValue(fc.GetMatch(), scalars(values))
+	case fc.GetRange() != nil:
+		r := fc.GetRange()
+		for _, v := range scalars(values) {
+			n, ok := number(v)
+			if !ok {
+				continue
+			}
+			if (r.Lt != nil && !(n < r.GetLt())) || (r.Lte != nil && !(n <= r.GetLte())) ||
+				(r.Gt != nil && !(n > r.GetGt())) || (r.Gte != nil && !(n >= r.GetGte())) {
+				continue
+			}
+			return true, nil
+		}
+		return false, nil
+	case fc.IsEmpty != nil:
+		return isEmpty(values) == fc.GetIsEmpty(), nil
+	}
+	return false, status.Errorf(codes.Unimplemented, "fake qdrant does not support the field condition on %q", fc.GetKey())
+}
+
+// matchValue evaluates a match against scalar values
+func matchValue(m *qdrant.Match, values []*qdrant.Value) (bool, error) {
+	switch mv := m.GetMatchValue().(type) {
+	case *qdrant.Match_Keyword:
+		return anyValue(values, func(v *qdrant.Value) bool { return isString(v) && v.GetStringValue() == mv.Keyword }), nil
+	case *qdrant.Match_Keywords:
+		return anyValue(values, func(v *qdrant.Value) bool {
+			return isString(v) && contains(mv.Keywords.GetStrings(), v.GetStringValue())
+		}), nil
+	case *qdrant.Match_ExceptKeywords:
+		return anyValue(values, func(v *qdrant.Value) bool {
+			return isString(v) && !contains(mv.ExceptKeywords.GetStrings(), v.GetStringValue())
+		}), nil
+	case *qdrant.Match_Text:
+		return anyValue(values, func(v *qdrant.Value) bool { return isString(v) && strings.Contains(v.GetStringValue(), mv.Text) }), nil
+	case *qdrant.Match_Integer:
+		return anyValue(values, func(v *qdrant.Value) bool { return isInteger(v) && v.GetIntegerValue() == mv.Integer }), nil
+	case *qdrant.Match_Integers:
+		return anyValue(values, func(v *qdrant.Value) bool {
+			return isInteger(v) && containsInt(mv.Integers.GetIntegers(), v.GetIntegerValue())
+		}), nil
+	case *qdrant.Match_ExceptIntegers:
+		return anyValue(values, func(v *qdrant.Value) bool {
+			return isInteger(v) && !containsInt(mv.ExceptIntegers.GetIntegers(), v.GetIntegerValue())
+		}), nil
+	case *qdrant.Match_Boolean:
+		return anyValue(values, func(v *qdrant.Value) bool { return isBool(v) && v.GetBoolValue() == mv.Boolean }), nil
+	}
+	return false, status.Errorf(codes.Unimplemented, "fake qdrant does not support match %T", m.GetMatchValue())
+}
+
+// lookup returns the values at a dotted key, descending into nested objects and
+// through arrays of objects. A missing key has no values.
+func lookup(payload map[string]*qdrant.Value, key string) []*qdrant.Value {
+	parts := strings.Split(key, ".")
+	values := []*qdrant.Value{}
+	if v, ok := payload[parts[0]]; ok {
+		values = append(values, v)
+	}
+	for _, part := range parts[1:] {
+		var next []*qdrant.Value
+		for _, v := range values {
+			for _, obj := range objects(v) {
+				if child, ok := obj.GetFields()[part]; ok {
+					next = append(next, child)
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// objects returns a value's object, or the objects in an array value
+func objects(v *qdrant.Value) []*qdrant.Struct {
+	if s := v.GetStructValue(); s != nil {
+		return []*qdrant.Struct{s}
+	}
+	var out []*qdrant.Struct
+	for _, item := range v.GetListValue().GetValues() {
+		if s := item.GetStructValue(); s != nil {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// scalars flattens arrays, so each element is matched on its own
+func scalars(values []*qdrant.Value) []*qdrant.Value {
+	var out []*qdrant.Value
+	for _, v := range values {
+		if list := v.GetListValue(); list != nil {
+			out = append(out, list.GetValues()...)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// isEmpty is Qdrant's is_empty: the key is missing, null or an empty array
+func isEmpty(values []*qdrant.Value) bool {
+	for _, v := range values {
+		if isNull(v) {
+			continue
+		}
+		if list := v.GetListValue(); list != nil && len(list.GetValues()) == 0 {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func anyValue(values []*qdrant.Value, match func(*qdrant.Value) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func number(v *qdrant.Value) (float64, bool) {
+	switch kind := v.GetKind().(type) {
+	case *qdrant.Value_IntegerValue:
+		return float64(kind.IntegerValue), true
+	case *qdrant.Value_DoubleValue:
+		return kind.DoubleValue, true
+	}
+	return 0, false
+}
+
+func isNull(v *qdrant.Value) bool {
+	_, ok := v.GetKind().(*qdrant.Value_NullValue)
+	return ok || v.GetKind() == nil
+}
+
+func isString(v *qdrant.Value) bool {
+	_, ok := v.GetKind().(*qdrant.Value_StringValue)
+	return ok
+}
+
+func isInteger(v *qdrant.Value) bool {
+	_, ok := v.GetKind().(*qdrant.Value_IntegerValue)
+	return ok
+}
+
+func isBool(v *qdrant.Value) bool {
+	_, ok := v.GetKind().(*qdrant.Value_BoolValue)
+	return ok
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int64, n int64) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package testinfra_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-llama/internal/dialogue"
+	"go-llama/internal/memory"
+)
+
+func TestSynthesisReviewSurvivesStorage(t *testing.T) {
+	ctx := context.Background()
+	client, err := fakeQdrant.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	storage, err := memory.NewStorageFromClient(client, "synthesis_review")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	review := &dialogue.SynthesisReview{Specificity: 0.2, Coverage: 0.4, Consistency: 0.9, Score: 0.3,
+		Missing: []string{"How the waggle dance encodes distance", "Role of polarized light"}}
+	mem := &memory.Memory{
+		Content:    "Bees navigate using the sun and landmarks.",
+		Tier:       memory.TierRecent,
+		SourceKind: memory.SourceResearchSynthesis,
+		CreatedAt:  time.Now().UTC(),
+		Embedding:  statsEmbedding(7),
+		Metadata:   map[string]interface{}{"research_type": "synthesis", "synthesis_review": review.Metadata()},
+	}
+	if err := storage.Store(ctx, mem); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := storage.GetMemoryByID(ctx, mem.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := stored.Metadata["synthesis_review"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the review stored, got %v", stored.Metadata)
+	}
+	if got["missing"] != "How the waggle dance encodes distance; Role of polarized light" || got["score"] != 0.3 {
+		t.Errorf("expected the missing aspects and score kept, got %v", got)
+	}
+}
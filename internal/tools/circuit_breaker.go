@@ -3,7 +3,6 @@ package tools
 import (
 	"errors"
 	"log"
-	"strings"
 	"sync"
 	"time"
 )
@@ -235,21 +234,19 @@ func (cb *CircuitBreaker) Stats() map[string]interface{} {
 	}
 }
 
-// isClientError determines if an error represents a client-side 4xx error
-// or a bad request, which should not trip the circuit breaker.
+// isClientError determines if an error represents a client-side 4xx error,
+// a bad request or a refused target, none of which should trip the circuit breaker.
 func isClientError(err error) bool {
     if err == nil {
         return false
     }
-    errStr := strings.ToLower(err.Error())
-    // Common patterns for HTTP 4xx errors and bad inputs
-    return strings.Contains(errStr, "http 40") || 
-           strings.Contains(errStr, "403 forbidden") || 
-           strings.Contains(errStr, "404 not found") || 
-           strings.Contains(errStr, "400 bad request") || 
-           strings.Contains(errStr, "401 unauthorized") || 
-           strings.Contains(errStr, "missing query") || 
-           strings.Contains(errStr, "bad request")
+    return IsClientStatus(err) ||
+           errors.Is(err, errMissingQuery) ||
+           errors.Is(err, ErrRobotsBlocked) ||
+           errors.Is(err, ErrDomainBlocked) ||
+           errors.Is(err, ErrUnsupportedContent) ||
+           errors.Is(err, ErrPageTooLarge) ||
+           errors.Is(err, ErrOutputTooLarge)
 }
 
 // LogStats logs current statistics
@@ -3,14 +3,18 @@ package tools
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 )
 
 // ContextualRegistry wraps Registry with context-aware execution
 type ContextualRegistry struct {
 	registry *Registry
+	mu       sync.RWMutex
 	configs  map[string]ToolConfig
+	disabled map[string]bool // Tools switched off by a config reload
 }
 
 // NewContextualRegistry creates a context-aware tool registry
@@ -26,7 +30,10 @@ func NewContextualRegistry(registry *Registry, configs map[string]ToolConfig) *C
 // - Limited results (3-5)
 // - Fast failure is better than slow success
 func (cr *ContextualRegistry) ExecuteInteractive(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error) {
-	config, exists := cr.configs[toolName]
+	config, exists, disabled := cr.config(toolName)
+	if disabled {
+		return disabledResult(toolName)
+	}
 	if !exists {
 		config = ToolConfig{
 			TimeoutInteractive:    5 * time.Second,
@@ -46,7 +53,7 @@ func (cr *ContextualRegistry) ExecuteInteractive(ctx context.Context, toolName s
 	log.Printf("[ContextualRegistry] ExecuteInteractive: tool=%s, timeout=%s, context=user_interaction", 
 		toolName, config.TimeoutInteractive)
 
-	return cr.registry.Execute(ctx, toolName, params, execCtx)
+	return cr.execute(ctx, toolName, params, execCtx)
 }
 
 // ExecuteIdle runs a tool in idle exploration mode
@@ -54,7 +61,10 @@ func (cr *ContextualRegistry) ExecuteInteractive(ctx context.Context, toolName s
 // - More results (10-20)
 // - Thoroughness over speed
 func (cr *ContextualRegistry) ExecuteIdle(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error) {
-	config, exists := cr.configs[toolName]
+	config, exists, disabled := cr.config(toolName)
+	if disabled {
+		return disabledResult(toolName)
+	}
 	if !exists {
 		config = ToolConfig{
 			TimeoutIdle:    240 * time.Second,
@@ -71,10 +81,61 @@ func (cr *ContextualRegistry) ExecuteIdle(ctx context.Context, toolName string,
 		MaxResults:    config.MaxResultsIdle,
 	}
 
-	log.Printf("[ContextualRegistry] ExecuteIdle: tool=%s, timeout=%s, context=idle_exploration", 
-		toolName, config.TimeoutIdle)
+	log.Printf("[ContextualRegistry] ExecuteIdle: tool=%s, timeout=%s, context=idle_exploration, trace=%s", 
+		toolName, config.TimeoutIdle, TraceID(ctx))
+
+	return cr.execute(ctx, toolName, params, execCtx)
+}
+
+// execute runs the tool, timing it as the total span of the context's trace and
+// recording the trace's spans on the result
+func (cr *ContextualRegistry) execute(ctx context.Context, toolName string, params map[string]interface{}, execCtx ExecutionContext) (*ToolResult, error) {
+	end := StartSpan(ctx, SpanTotal)
+	result, err := cr.registry.Execute(ctx, toolName, params, execCtx)
+	end()
+	recordTrace(ctx, result)
+	return result, err
+}
+
+// IdleTimeout returns the per-attempt timeout ExecuteIdle applies to a tool
+func (cr *ContextualRegistry) IdleTimeout(toolName string) time.Duration {
+	config, exists, _ := cr.config(toolName)
+	if !exists {
+		return 240 * time.Second // ExecuteIdle's default config
+	}
+	if config.TimeoutIdle == 0 {
+		return 60 * time.Second // Registry.Execute's idle default
+	}
+	return config.TimeoutIdle
+}
+
+// UpdateConfig replaces a tool's timeouts, limits and enabled flag for later executions.
+// Disabling a tool refuses new calls; enabling one that was never registered has no effect.
+func (cr *ContextualRegistry) UpdateConfig(toolName string, config ToolConfig) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.configs == nil {
+		cr.configs = make(map[string]ToolConfig)
+	}
+	if cr.disabled == nil {
+		cr.disabled = make(map[string]bool)
+	}
+	cr.configs[toolName] = config
+	cr.disabled[toolName] = !config.Enabled
+}
+
+// config returns a tool's current config and whether a reload has disabled it
+func (cr *ContextualRegistry) config(toolName string) (ToolConfig, bool, bool) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	config, exists := cr.configs[toolName]
+	return config, exists, cr.disabled[toolName]
+}
 
-	return cr.registry.Execute(ctx, toolName, params, execCtx)
+// disabledResult refuses a call to a tool switched off in config
+func disabledResult(toolName string) (*ToolResult, error) {
+	err := fmt.Errorf("tool %s is disabled", toolName)
+	return &ToolResult{Success: false, Error: err.Error()}, err
 }
 
 // GetRegistry returns the underlying registry
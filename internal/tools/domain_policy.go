@@ -0,0 +1,279 @@
+// internal/tools/domain_policy.go
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+
+	"go-llama/internal/httpclient"
+)
+
+// ErrDomainBlocked is returned (wrapped in DomainBlockedError) for refused fetch targets
+var ErrDomainBlocked = errors.New("fetch target blocked by domain policy")
+
+// DomainBlockedError names the refused host and why
+type DomainBlockedError struct {
+	Host   string
+	Reason string
+}
+
+func (e *DomainBlockedError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", ErrDomainBlocked.Error(), e.Host, e.Reason)
+}
+
+func (e *DomainBlockedError) Unwrap() error {
+	return ErrDomainBlocked
+}
+
+// Domain policy modes
+const (
+	DomainPolicyOff       = "off"       // Any public host may be fetched
+	DomainPolicyAllowlist = "allowlist" // Only hosts matching a pattern
+	DomainPolicyBlocklist = "blocklist" // Any public host except those matching a pattern
+)
+
+// DomainPolicyConfig is the reloadable part of a policy. Patterns match a host and its
+// subdomains ("example.com"), subdomains only ("*.example.com") or a TLD (".gov").
+type DomainPolicyConfig struct {
+	Mode     string   `json:"mode"`
+	Patterns []string `json:"patterns"`
+}
+
+// DomainPolicy decides which hosts the web parser may fetch. Loopback, private
+// (RFC 1918 / RFC 4193), link-local and other non-public addresses are always refused,
+// both for IP literals in the URL and for whatever a hostname resolves to when the
+// request is sent and, for direct connections, at dial time.
+type DomainPolicy struct {
+	mu     sync.RWMutex
+	config DomainPolicyConfig
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error) // nil uses the system resolver
+}
+
+// NewDomainPolicy creates a policy; an empty mode means "off"
+func NewDomainPolicy(config DomainPolicyConfig) (*DomainPolicy, error) {
+	p := &DomainPolicy{}
+	if err := p.Update(config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update replaces the mode and patterns atomically
+func (p *DomainPolicy) Update(config DomainPolicyConfig) error {
+	mode := strings.ToLower(strings.TrimSpace(config.Mode))
+	if mode == "" {
+		mode = DomainPolicyOff
+	}
+	switch mode {
+	case DomainPolicyOff, DomainPolicyAllowlist, DomainPolicyBlocklist:
+	default:
+		return fmt.Errorf("unknown domain policy mode %q", config.Mode)
+	}
+
+	patterns := make([]string, 0, len(config.Patterns))
+	for _, pattern := range config.Patterns {
+		if pattern = normalizeHost(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	p.mu.Lock()
+	p.config = DomainPolicyConfig{Mode: mode, Patterns: patterns}
+	p.mu.Unlock()
+	return nil
+}
+
+// Config returns the active mode and patterns
+func (p *DomainPolicy) Config() DomainPolicyConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return DomainPolicyConfig{Mode: p.config.Mode, Patterns: append([]string{}, p.config.Patterns...)}
+}
+
+// CheckURL validates a fetch target without any network access. Hostnames that
+// resolve to private addresses are caught by the transport (see Transport).
+func (p *DomainPolicy) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return &DomainBlockedError{Host: u.Host, Reason: "scheme " + u.Scheme + " not allowed"}
+	}
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return &DomainBlockedError{Host: u.Host, Reason: "missing host"}
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if err := checkPublicIP(ip); err != nil {
+			return &DomainBlockedError{Host: host, Reason: err.Error()}
+		}
+	} else if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return &DomainBlockedError{Host: host, Reason: "localhost"}
+	}
+
+	p.mu.RLock()
+	mode, patterns := p.config.Mode, p.config.Patterns
+	p.mu.RUnlock()
+
+	switch mode {
+	case DomainPolicyAllowlist:
+		if !matchesAnyHostPattern(host, patterns) {
+			return &DomainBlockedError{Host: host, Reason: "not on allowlist"}
+		}
+	case DomainPolicyBlocklist:
+		if matchesAnyHostPattern(host, patterns) {
+			return &DomainBlockedError{Host: host, Reason: "on blocklist"}
+		}
+	}
+	return nil
+}
+
+// Allowed reports whether CheckURL accepts the URL
+func (p *DomainPolicy) Allowed(rawURL string) bool {
+	return p.CheckURL(rawURL) == nil
+}
+
+// dialControl refuses connections to non-public addresses after DNS resolution, which
+// also defeats hostnames (or redirects, or DNS rebinding) that point at the LAN
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return &DomainBlockedError{Host: host, Reason: "unresolved address"}
+	}
+	if err := checkPublicIP(ip); err != nil {
+		return &DomainBlockedError{Host: host, Reason: err.Error()}
+	}
+	return nil
+}
+
+// checkResolved refuses a hostname that resolves to a non-public address. A name the
+// local resolver cannot look up passes: a proxy may still reach it, and a direct dial
+// fails or is checked by dialControl.
+func (p *DomainPolicy) checkResolved(ctx context.Context, host string) error {
+	host = normalizeHost(host)
+	if net.ParseIP(host) != nil {
+		return nil // IP literals are checked by CheckURL
+	}
+	lookup := p.lookup
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupIPAddr
+	}
+	addrs, err := lookup(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if err := checkPublicIP(addr.IP); err != nil {
+			return &DomainBlockedError{Host: host, Reason: "resolves to " + err.Error()}
+		}
+	}
+	return nil
+}
+
+// Transport returns a round tripper that enforces the policy on every request, then
+// sends it over the shared httpclient transport, keeping its connection pool, limits,
+// proxies and TLS trust. The resolved target is checked up front, since a proxied
+// dial only sees the proxy's address and a pooled connection needs no dial at all;
+// direct dials are checked again against DNS rebinding.
+func (p *DomainPolicy) Transport() http.RoundTripper {
+	return policyTransport{policy: p}
+}
+
+type policyTransport struct {
+	policy *DomainPolicy
+}
+
+func (t policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := t.policy.CheckURL(req.URL.String())
+	if err == nil {
+		err = t.policy.checkResolved(req.Context(), req.URL.Hostname())
+	}
+	factory := httpclient.Default()
+	var proxy *url.URL
+	if err == nil {
+		proxy, err = factory.ProxyFor(req.URL)
+	}
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	if proxy == nil {
+		req = req.WithContext(httpclient.WithDialControl(req.Context(), dialControl))
+	}
+	return factory.RoundTrip(req)
+}
+
+// cgnatRange is the RFC 6598 shared address space, often used for internal services
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func checkPublicIP(ip net.IP) error {
+	switch {
+	case ip.IsLoopback():
+		return errors.New("loopback address")
+	case ip.IsPrivate():
+		return errors.New("private address")
+	case ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast():
+		return errors.New("link-local address")
+	case ip.IsUnspecified(), ip.IsMulticast(), ip.IsInterfaceLocalMulticast():
+		return errors.New("non-unicast address")
+	case cgnatRange.Contains(ip):
+		return errors.New("shared address space")
+	}
+	return nil
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func matchesAnyHostPattern(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchesHostPattern(host, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHostPattern: "example.com" matches it and subdomains, "*.example.com" and
+// ".example.com" match subdomains only (so ".gov" blocks a TLD)
+func matchesHostPattern(host, pattern string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		pattern = suffix
+	}
+	if strings.HasPrefix(pattern, ".") {
+		return strings.HasSuffix(host, pattern)
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// domainBlockedResult is the typed failure returned for refused fetch targets
+func domainBlockedResult(url string, err error) *ToolResult {
+	metadata := map[string]interface{}{"url": url}
+	var blocked *DomainBlockedError
+	if errors.As(err, &blocked) {
+		metadata["blocked_host"] = blocked.Host
+		metadata["blocked_reason"] = blocked.Reason
+	}
+	return &ToolResult{
+		Success:     false,
+		Error:       err.Error(),
+		Metadata:    metadata,
+		FailureKind: FailureKindDomainBlocked,
+	}
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go-llama/internal/httpclient"
+)
+
+func TestDomainPolicyModes(t *testing.T) {
+	cases := []struct {
+		mode     string
+		patterns []string
+		url      string
+		allowed  bool
+	}{
+		{DomainPolicyOff, nil, "https://example.com/page", true},
+		{DomainPolicyAllowlist, []string{"wikipedia.org"}, "https://en.wikipedia.org/wiki/Go", true},
+		{DomainPolicyAllowlist, []string{"wikipedia.org"}, "https://notwikipedia.org/", false},
+		{DomainPolicyAllowlist, []string{"*.example.com"}, "https://example.com/", false},
+		{DomainPolicyAllowlist, []string{"*.example.com"}, "https://docs.example.com/", true},
+		{DomainPolicyBlocklist, []string{"Spam.COM."}, "https://www.spam.com/x", false},
+		{DomainPolicyBlocklist, []string{".gov"}, "https://data.gov/", false},
+		{DomainPolicyBlocklist, []string{".gov"}, "https://example.org/", true},
+	}
+	for _, tc := range cases {
+		policy, err := NewDomainPolicy(DomainPolicyConfig{Mode: tc.mode, Patterns: tc.patterns})
+		if err != nil {
+			t.Fatalf("NewDomainPolicy(%s): %v", tc.mode, err)
+		}
+		if got := policy.Allowed(tc.url); got != tc.allowed {
+			t.Errorf("%s %v: Allowed(%s) = %v, want %v", tc.mode, tc.patterns, tc.url, got, tc.allowed)
+		}
+	}
+
+	if _, err := NewDomainPolicy(DomainPolicyConfig{Mode: "sometimes"}); err == nil {
+		t.Error("expected unknown mode to be rejected")
+	}
+}
+
+func TestDomainPolicyRefusesPrivateTargets(t *testing.T) {
+	policy, _ := NewDomainPolicy(DomainPolicyConfig{Mode: DomainPolicyAllowlist, Patterns: []string{"localhost", "10.0.0.5"}})
+
+	for _, target := range []string{
+		"http://127.0.0.1:8080/",
+		"http://localhost/admin",
+		"http://10.0.0.5/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/",
+		"http://[fd00::1]/",
+		"http://100.64.0.1/",
+		"file:///etc/passwd",
+	} {
+		err := policy.CheckURL(target)
+		if !errors.Is(err, ErrDomainBlocked) {
+			t.Errorf("expected %s to be refused even when allowlisted, got %v", target, err)
+		}
+	}
+
+	// Hostnames resolving to the LAN are caught at dial time
+	if err := dialControl("tcp", "192.168.0.10:443", nil); !errors.Is(err, ErrDomainBlocked) {
+		t.Errorf("expected dialer to refuse private address, got %v", err)
+	}
+	if err := dialControl("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("expected dialer to allow public address, got %v", err)
+	}
+}
+
+func TestWebParserDomainBlockedFailure(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(clutteredPage))
+	}))
+	defer srv.Close()
+
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+	policy, _ := NewDomainPolicy(DomainPolicyConfig{})
+	tool.SetDomainPolicy(policy)
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"url": srv.URL})
+	if err == nil || result.Success || result.FailureKind != FailureKindDomainBlocked {
+		t.Fatalf("expected typed domain_blocked failure, got %+v (err %v)", result, err)
+	}
+	if result.Metadata["blocked_reason"] != "loopback address" {
+		t.Errorf("unexpected blocked_reason %v", result.Metadata["blocked_reason"])
+	}
+
+	// The transport refuses the connection even when the URL check is bypassed
+	if _, err := tool.httpClient.Get(srv.URL); !errors.Is(err, ErrDomainBlocked) {
+		t.Errorf("expected transport to refuse loopback, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("expected no request to reach the server, got %d", requests.Load())
+	}
+}
+
+func TestWebParserDomainPolicyUsesSharedProxy(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>via proxy " + r.URL.Host + "</body></html>"))
+	}))
+	defer proxy.Close()
+	if err := httpclient.Configure(httpclient.Config{ProxyURL: proxy.URL}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { httpclient.Configure(httpclient.DefaultConfig) })
+
+	policy, _ := NewDomainPolicy(DomainPolicyConfig{})
+	policy.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		if host == "intranet.example" {
+			return []net.IPAddr{{IP: net.ParseIP("10.1.2.3")}}, nil
+		}
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	}
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+	tool.SetDomainPolicy(policy)
+
+	resp, err := tool.httpClient.Get("http://public.example/page")
+	if err != nil {
+		t.Fatalf("expected the request sent through the proxy, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if proxied.Load() != 1 || string(body) != "<html><body>via proxy public.example</body></html>" {
+		t.Errorf("expected the proxy to serve the page, got %q (%d proxied)", body, proxied.Load())
+	}
+
+	// The proxy hides the target from the dialer, so its resolved address is checked first
+	if _, err := tool.httpClient.Get("http://intranet.example/"); !errors.Is(err, ErrDomainBlocked) {
+		t.Errorf("expected a host resolving to the LAN refused, got %v", err)
+	}
+	if proxied.Load() != 1 {
+		t.Errorf("expected the refused request to never reach the proxy, got %d", proxied.Load())
+	}
+}
+
+func TestDomainPolicyKeepsSharedPool(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer proxy.Close()
+	if err := httpclient.Configure(httpclient.Config{ProxyURL: proxy.URL, MaxIdleConnsPerHost: 2, MaxConnsPerHost: 4}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { httpclient.Configure(httpclient.DefaultConfig) })
+
+	policy, _ := NewDomainPolicy(DomainPolicyConfig{})
+	policy.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	}
+	summarizer := NewSummarizer(&fakeSummaryLLM{reply: "unused"}, SummarizerConfig{})
+	summarizer.SetDomainPolicy(policy)
+
+	for i := 0; i < 3; i++ {
+		resp, err := summarizer.parser.httpClient.Get("http://public.example/page")
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	stats := httpclient.Default().Stats()
+	if stats.Requests != 3 || stats.Dials != 1 || stats.MaxConnsPerHost != 4 {
+		t.Errorf("expected three counted requests over one pooled connection, got %+v", stats)
+	}
+}
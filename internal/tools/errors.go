@@ -0,0 +1,115 @@
+// internal/tools/errors.go
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Errors returned by fetching tools. Callers match them with errors.Is and errors.As;
+// the message text is for logs only.
+var (
+	ErrPageTooLarge   = errors.New("page too large")
+	ErrHTTPStatus     = errors.New("unexpected HTTP status")
+	ErrTimeout        = errors.New("request timed out")
+	ErrRobotsBlocked  = errors.New("fetch disallowed by robots.txt")
+	ErrWrongLanguage  = errors.New("page not in the target language")
+	ErrOutputTooLarge = errors.New("tool output too large")
+)
+
+// errMissingQuery is returned by the search tool when it is called without a query
+var errMissingQuery = errors.New("missing query parameter")
+
+// HTTPStatusError reports a response with an unexpected status code
+type HTTPStatusError struct {
+	Code int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.Code)
+}
+
+// Unwrap lets callers match with errors.Is(err, ErrHTTPStatus)
+func (e *HTTPStatusError) Unwrap() error {
+	return ErrHTTPStatus
+}
+
+// WrongLanguageError reports a page skipped for its language
+type WrongLanguageError struct {
+	Detected string
+	Target   string
+}
+
+func (e *WrongLanguageError) Error() string {
+	return fmt.Sprintf("page is in %s, not %s", LanguageName(e.Detected), LanguageName(e.Target))
+}
+
+// Unwrap lets callers match with errors.Is(err, ErrWrongLanguage)
+func (e *WrongLanguageError) Unwrap() error {
+	return ErrWrongLanguage
+}
+
+// OutputTooLargeError reports a tool output over the hard ceiling of OutputLimits
+type OutputTooLargeError struct {
+	Tool  string
+	Size  int
+	Limit int
+}
+
+func (e *OutputTooLargeError) Error() string {
+	return fmt.Sprintf("%s returned %d bytes, over the %d byte ceiling", e.Tool, e.Size, e.Limit)
+}
+
+// Unwrap lets callers match with errors.Is(err, ErrOutputTooLarge)
+func (e *OutputTooLargeError) Unwrap() error {
+	return ErrOutputTooLarge
+}
+
+// IsClientStatus reports whether err carries a 4xx status other than 429. Such a page
+// will fail again however often it is retried, but the server itself is healthy.
+func IsClientStatus(err error) bool {
+	var status *HTTPStatusError
+	return errors.As(err, &status) &&
+		status.Code >= 400 && status.Code < 500 && status.Code != http.StatusTooManyRequests
+}
+
+// wrapTimeout marks deadline and network timeout errors with ErrTimeout
+func wrapTimeout(err error) error {
+	if err == nil || errors.Is(err, ErrTimeout) {
+		return err
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// FailureKindOf returns the ToolResult failure kind for err, or "" for failures
+// without one
+func FailureKindOf(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrUnsupportedContent):
+		return FailureKindUnsupportedContent
+	case errors.Is(err, ErrDomainBlocked):
+		return FailureKindDomainBlocked
+	case errors.Is(err, ErrRobotsBlocked):
+		return FailureKindRobotsBlocked
+	case errors.Is(err, ErrPageTooLarge):
+		return FailureKindPageTooLarge
+	case errors.Is(err, ErrWrongLanguage):
+		return FailureKindWrongLanguage
+	case errors.Is(err, ErrOutputTooLarge):
+		return FailureKindOutputTooLarge
+	case errors.Is(err, ErrHTTPStatus):
+		return FailureKindHTTPStatus
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return FailureKindTimeout
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebParserReturnsTypedErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/huge":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("x", 1024*1024+1)))
+		case "/slow":
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte("late"))
+		}
+	}))
+	defer srv.Close()
+	tool := NewWebParserUnifiedTool("test", "http://llm", "small", 1, ToolConfig{}, nil, 1000)
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"url": srv.URL + "/missing"})
+	var status *HTTPStatusError
+	if !errors.As(err, &status) || status.Code != http.StatusNotFound {
+		t.Fatalf("expected an HTTPStatusError with 404, got %v", err)
+	}
+	if result.FailureKind != FailureKindHTTPStatus || result.Metadata["status_code"] != 404 {
+		t.Errorf("expected the status in the result, got %q %v", result.FailureKind, result.Metadata)
+	}
+
+	result, err = tool.Execute(context.Background(), map[string]interface{}{"url": srv.URL + "/huge"})
+	if !errors.Is(err, ErrPageTooLarge) || result.FailureKind != FailureKindPageTooLarge {
+		t.Errorf("expected ErrPageTooLarge, got %v (%q)", err, result.FailureKind)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err = tool.Execute(ctx, map[string]interface{}{"url": srv.URL + "/slow"})
+	if !errors.Is(err, ErrTimeout) || result.FailureKind != FailureKindTimeout {
+		t.Errorf("expected ErrTimeout, got %v (%q)", err, result.FailureKind)
+	}
+}
+
+func TestCircuitBreakerIgnoresTypedClientErrors(t *testing.T) {
+	cb := NewCircuitBreaker(1, time.Minute)
+	for _, err := range []error{
+		fmt.Errorf("whatever the wording: %w", &HTTPStatusError{Code: http.StatusForbidden}),
+		fmt.Errorf("fetch: %w", ErrRobotsBlocked),
+		fmt.Errorf("fetch: %w", ErrPageTooLarge),
+		errMissingQuery,
+	} {
+		cb.Call(func() error { return err })
+		if cb.IsOpen() {
+			t.Fatalf("client error %v tripped the breaker", err)
+		}
+	}
+
+	// Message text alone no longer counts as a client error
+	cb.Call(func() error { return errors.New("HTTP 404 bad request") })
+	if !cb.IsOpen() {
+		t.Error("expected an untyped error to trip the breaker")
+	}
+
+	limited := NewCircuitBreaker(1, time.Minute)
+	limited.Call(func() error { return &HTTPStatusError{Code: http.StatusTooManyRequests} })
+	if !limited.IsOpen() {
+		t.Error("expected rate limiting to trip the breaker")
+	}
+}
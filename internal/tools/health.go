@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-llama/internal/utils"
+)
+
+// Health tracking defaults
+const (
+	DefaultHealthWindow    = 20              // Executions the success rate and duration cover
+	DefaultHealthThreshold = 3               // Consecutive failures that open a tool's circuit
+	DefaultHealthCooldown  = 5 * time.Minute // How long an open circuit stays open
+)
+
+// ToolHealth is a tool's recent record, as seen by a HealthTracker
+type ToolHealth struct {
+	Tool                string        `json:"tool"`
+	State               CircuitState  `json:"state"`
+	Executions          int           `json:"executions"` // In the window, at most its size
+	SuccessRate         float64       `json:"success_rate"`
+	AvgDuration         time.Duration `json:"avg_duration_ns"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	LastFailure         time.Time     `json:"last_failure,omitempty"`
+	LastError           string        `json:"last_error,omitempty"`
+}
+
+// Unhealthy reports whether the tool should be avoided for now
+func (h ToolHealth) Unhealthy() bool {
+	return h.State == StateOpen
+}
+
+// Summary describes the tool's health in one short line for an LLM prompt
+func (h ToolHealth) Summary() string {
+	status := "healthy"
+	switch {
+	case h.State == StateOpen:
+		status = fmt.Sprintf("UNHEALTHY, circuit open after %d consecutive failures", h.ConsecutiveFailures)
+	case h.State == StateHalfOpen:
+		status = "recovering, next run is a test"
+	case h.SuccessRate < 0.5:
+		status = "degraded"
+	}
+	return fmt.Sprintf("%s (%.0f%% of last %d runs succeeded, avg %s)",
+		status, h.SuccessRate*100, h.Executions, h.AvgDuration.Round(100*time.Millisecond))
+}
+
+// outcome is one recorded execution
+type outcome struct {
+	failed   bool
+	duration time.Duration
+}
+
+// toolRecord is a tool's window of outcomes and its health as of the last one
+type toolRecord struct {
+	window   []outcome // Ring buffer
+	next     int
+	failures int // Consecutive
+	lastFail time.Time
+	lastErr  string
+	health   ToolHealth
+}
+
+// HealthTracker records every tool execution through its Middleware and keeps each
+// tool's health up to date, so reading it costs no more than a copy. Cancelled calls
+// and client errors (bad requests, refused targets) say nothing about the tool and are
+// not recorded.
+type HealthTracker struct {
+	mu        sync.RWMutex
+	window    int
+	threshold int
+	cooldown  time.Duration
+	tools     map[string]*toolRecord
+	now       func() time.Time
+}
+
+// NewHealthTracker creates a tracker over the last window executions of each tool.
+// A tool's circuit opens after threshold consecutive failures and half-opens once
+// cooldown has passed since the last one. Zero values take the defaults.
+func NewHealthTracker(window, threshold int, cooldown time.Duration) *HealthTracker {
+	if window <= 0 {
+		window = DefaultHealthWindow
+	}
+	if threshold <= 0 {
+		threshold = DefaultHealthThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultHealthCooldown
+	}
+	return &HealthTracker{
+		window:    window,
+		threshold: threshold,
+		cooldown:  cooldown,
+		tools:     make(map[string]*toolRecord),
+		now:       time.Now,
+	}
+}
+
+// Middleware records each execution's outcome and duration
+func (t *HealthTracker) Middleware() Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error) {
+			start := t.now()
+			result, err := next(ctx, toolName, params)
+			if errors.Is(err, context.Canceled) || isClientError(err) {
+				return result, err
+			}
+			failed := err != nil || result == nil || !result.Success
+			message := ""
+			if err != nil {
+				message = err.Error()
+			} else if failed && result != nil {
+				message = result.Error
+			}
+			t.Record(toolName, failed, t.now().Sub(start), message)
+			return result, err
+		}
+	}
+}
+
+// Record adds one execution of tool
+func (t *HealthTracker) Record(tool string, failed bool, duration time.Duration, errMessage string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rec, ok := t.tools[tool]
+	if !ok {
+		rec = &toolRecord{window: make([]outcome, 0, t.window)}
+		t.tools[tool] = rec
+	}
+	if len(rec.window) < t.window {
+		rec.window = append(rec.window, outcome{failed: failed, duration: duration})
+	} else {
+		rec.window[rec.next] = outcome{failed: failed, duration: duration}
+		rec.next = (rec.next + 1) % t.window
+	}
+	if failed {
+		rec.failures++
+		rec.lastFail = t.now()
+		rec.lastErr = errMessage
+	} else {
+		rec.failures = 0
+	}
+
+	succeeded := 0
+	var total time.Duration
+	for _, o := range rec.window {
+		if !o.failed {
+			succeeded++
+		}
+		total += o.duration
+	}
+	rec.health = ToolHealth{
+		Tool:                tool,
+		Executions:          len(rec.window),
+		SuccessRate:         float64(succeeded) / float64(len(rec.window)),
+		AvgDuration:         total / time.Duration(len(rec.window)),
+		ConsecutiveFailures: rec.failures,
+		LastFailure:         rec.lastFail,
+		LastError:           truncateError(rec.lastErr),
+	}
+}
+
+// Health returns a tool's health. ok is false if it has not run since the tracker started.
+func (t *HealthTracker) Health(tool string) (ToolHealth, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rec, ok := t.tools[tool]
+	if !ok {
+		return ToolHealth{}, false
+	}
+	health := rec.health
+	health.State = t.state(rec)
+	return health, true
+}
+
+// Snapshot returns the health of every tool that has run
+func (t *HealthTracker) Snapshot() map[string]ToolHealth {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snapshot := make(map[string]ToolHealth, len(t.tools))
+	for name, rec := range t.tools {
+		health := rec.health
+		health.State = t.state(rec)
+		snapshot[name] = health
+	}
+	return snapshot
+}
+
+// state is the tool's circuit state now; it depends on the time since the last failure
+func (t *HealthTracker) state(rec *toolRecord) CircuitState {
+	if rec.failures < t.threshold {
+		return StateClosed
+	}
+	if t.now().Sub(rec.lastFail) < t.cooldown {
+		return StateOpen
+	}
+	return StateHalfOpen
+}
+
+// truncateError keeps error messages short enough to report
+func truncateError(message string) string {
+	const maxLen = 120
+	return utils.Truncate(message, maxLen)
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingTool fails every call with err
+type failingTool struct {
+	echoTool
+	err error
+}
+
+func (t *failingTool) Execute(ctx context.Context, params map[string]interface{}) (*ToolResult, error) {
+	return nil, t.err
+}
+
+func TestHealthTrackerOpensAfterConsecutiveFailures(t *testing.T) {
+	tracker := NewHealthTracker(4, 3, time.Minute)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	r := NewRegistry()
+	r.Use(tracker.Middleware())
+	r.Register(&echoTool{name: "parse"})
+	r.Register(&failingTool{echoTool: echoTool{name: "search"}, err: errors.New("connection refused")})
+	r.Register(&failingTool{echoTool: echoTool{name: "fetch"}, err: ErrRobotsBlocked})
+
+	if _, ok := tracker.Health("search"); ok {
+		t.Error("expected no health for a tool that has not run")
+	}
+	for i := 0; i < 3; i++ {
+		r.Execute(context.Background(), "search", nil, ExecutionContext{IsInteractive: true})
+		r.Execute(context.Background(), "parse", nil, ExecutionContext{IsInteractive: true})
+		r.Execute(context.Background(), "fetch", nil, ExecutionContext{IsInteractive: true})
+	}
+
+	search, _ := tracker.Health("search")
+	if !search.Unhealthy() || search.SuccessRate != 0 || search.LastError != "connection refused" {
+		t.Errorf("expected search open after 3 failures, got %+v", search)
+	}
+	if !strings.HasPrefix(search.Summary(), "UNHEALTHY") {
+		t.Errorf("expected the summary to flag search, got %q", search.Summary())
+	}
+	if parse, _ := tracker.Health("parse"); parse.State != StateClosed || parse.SuccessRate != 1 || parse.Executions != 3 {
+		t.Errorf("expected parse healthy, got %+v", parse)
+	}
+	if _, ok := tracker.Health("fetch"); ok {
+		t.Error("expected client errors not to count against the tool")
+	}
+
+	// Once the cooldown passes the next run is a test, and a success closes it
+	now = now.Add(2 * time.Minute)
+	if search, _ := tracker.Health("search"); search.State != StateHalfOpen {
+		t.Errorf("expected half-open after the cooldown, got %s", search.State)
+	}
+	tracker.Record("search", false, time.Second, "")
+	if search, _ := tracker.Health("search"); search.State != StateClosed || search.SuccessRate != 0.25 {
+		t.Errorf("expected closed with 1 of 4 recent runs succeeded, got %+v", search)
+	}
+}
+
+func TestHealthTrackerWindowIsBounded(t *testing.T) {
+	tracker := NewHealthTracker(3, 0, 0)
+	tracker.Record("search", true, 4*time.Second, "timeout")
+	for i := 0; i < 3; i++ {
+		tracker.Record("search", false, time.Second, "")
+	}
+	health, _ := tracker.Health("search")
+	if health.Executions != 3 || health.SuccessRate != 1 || health.AvgDuration != time.Second {
+		t.Errorf("expected only the last 3 runs counted, got %+v", health)
+	}
+	if len(tracker.Snapshot()) != 1 {
+		t.Errorf("expected one tool in the snapshot, got %v", tracker.Snapshot())
+	}
+}
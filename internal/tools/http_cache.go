@@ -0,0 +1,252 @@
+// internal/tools/http_cache.go
+package tools
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// httpCacheKeyPrefix namespaces cached page bodies in Redis
+const httpCacheKeyPrefix = "growerai:http_cache:"
+
+// Cache outcomes reported in ToolResult metadata under "http_cache"
+const (
+	HTTPCacheMiss        = "miss"        // Downloaded in full
+	HTTPCacheHit         = "hit"         // Reused without contacting the server
+	HTTPCacheRevalidated = "revalidated" // Server answered 304 Not Modified
+)
+
+// HTTPCacheConfig controls the page body cache
+type HTTPCacheConfig struct {
+	MaxBytes    int64         // Total body budget (default 64MB)
+	ReuseWindow time.Duration // Serve without revalidating for this long (default 10m)
+	TTL         time.Duration // Keep entries for conditional requests this long (default 24h)
+}
+
+// HTTPCacheStats reports how often downloads were avoided
+type HTTPCacheStats struct {
+	Hits        int64 `json:"hits"`
+	Revalidated int64 `json:"revalidated"`
+	Misses      int64 `json:"misses"`
+	Entries     int   `json:"entries"`
+	Bytes       int64 `json:"bytes"`
+}
+
+// CachedPage is a stored response body with its validators
+type CachedPage struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Body         []byte    `json:"body"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+// Header rebuilds the response headers the parser relies on
+func (p *CachedPage) Header() http.Header {
+	header := http.Header{}
+	if p.ContentType != "" {
+		header.Set("Content-Type", p.ContentType)
+	}
+	if p.LastModified != "" {
+		header.Set("Last-Modified", p.LastModified)
+	}
+	if p.ETag != "" {
+		header.Set("ETag", p.ETag)
+	}
+	return header
+}
+
+// HTTPCache keeps downloaded page bodies within a byte budget (LRU) so repeated
+// parses of the same URL reuse the body or revalidate it with a conditional request.
+// Entries are written through to Redis when a client is supplied.
+type HTTPCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Front = most recently used
+	bytes   int64
+	config  HTTPCacheConfig
+	rdb     *redis.Client
+
+	hits        atomic.Int64
+	revalidated atomic.Int64
+	misses      atomic.Int64
+}
+
+// NewHTTPCache creates a page cache. rdb may be nil for memory-only caching.
+func NewHTTPCache(config HTTPCacheConfig, rdb *redis.Client) *HTTPCache {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 64 * 1024 * 1024
+	}
+	if config.ReuseWindow <= 0 {
+		config.ReuseWindow = 10 * time.Minute
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	return &HTTPCache{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		config:  config,
+		rdb:     rdb,
+	}
+}
+
+// Lookup returns the stored page for a URL, or nil if none is held or it has expired
+func (c *HTTPCache) Lookup(ctx context.Context, url string) *CachedPage {
+	now := time.Now()
+
+	c.mu.Lock()
+	if elem, ok := c.entries[url]; ok {
+		page := elem.Value.(*CachedPage)
+		if now.Sub(page.StoredAt) < c.config.TTL {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			return page
+		}
+		c.removeElement(elem)
+	}
+	c.mu.Unlock()
+
+	page := c.getRedis(ctx, url)
+	if page == nil || now.Sub(page.StoredAt) >= c.config.TTL {
+		return nil
+	}
+	c.mu.Lock()
+	c.insert(page)
+	c.mu.Unlock()
+	return page
+}
+
+// Fresh reports whether a page can be reused without asking the server
+func (c *HTTPCache) Fresh(page *CachedPage) bool {
+	return time.Since(page.StoredAt) < c.config.ReuseWindow
+}
+
+// Store saves a downloaded body, evicting least recently used pages to stay in budget
+func (c *HTTPCache) Store(ctx context.Context, page *CachedPage) {
+	if page == nil || int64(len(page.Body)) > c.config.MaxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	evicted := c.insert(page)
+	c.mu.Unlock()
+
+	c.setRedis(ctx, page)
+	for _, url := range evicted {
+		c.deleteRedis(ctx, url)
+	}
+}
+
+// Refresh restarts the reuse window after a 304 confirmed the body is current
+func (c *HTTPCache) Refresh(ctx context.Context, page *CachedPage) {
+	refreshed := *page
+	refreshed.StoredAt = time.Now()
+	c.Store(ctx, &refreshed)
+}
+
+// Record counts a fetch outcome for Stats
+func (c *HTTPCache) Record(outcome string) {
+	switch outcome {
+	case HTTPCacheHit:
+		c.hits.Add(1)
+	case HTTPCacheRevalidated:
+		c.revalidated.Add(1)
+	default:
+		c.misses.Add(1)
+	}
+}
+
+// insert adds or replaces a page and returns the URLs evicted. Caller must hold c.mu.
+func (c *HTTPCache) insert(page *CachedPage) []string {
+	if elem, ok := c.entries[page.URL]; ok {
+		c.removeElement(elem)
+	}
+	c.entries[page.URL] = c.lru.PushFront(page)
+	c.bytes += int64(len(page.Body))
+
+	evicted := []string{}
+	for c.bytes > c.config.MaxBytes && c.lru.Len() > 1 {
+		oldest := c.lru.Back()
+		evicted = append(evicted, oldest.Value.(*CachedPage).URL)
+		c.removeElement(oldest)
+	}
+	return evicted
+}
+
+// removeElement drops a page from memory. Caller must hold c.mu.
+func (c *HTTPCache) removeElement(elem *list.Element) {
+	page := elem.Value.(*CachedPage)
+	c.lru.Remove(elem)
+	delete(c.entries, page.URL)
+	c.bytes -= int64(len(page.Body))
+}
+
+func (c *HTTPCache) getRedis(ctx context.Context, url string) *CachedPage {
+	if c.rdb == nil {
+		return nil
+	}
+	rctx, cancel := context.WithTimeout(ctx, searchCacheRedisTimeout)
+	defer cancel()
+
+	raw, err := c.rdb.Get(rctx, httpCacheKeyPrefix+url).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("[HTTPCache] Redis read failed, using memory only: %v", err)
+		}
+		return nil
+	}
+	var page CachedPage
+	if err := json.Unmarshal(raw, &page); err != nil || page.URL == "" {
+		return nil
+	}
+	return &page
+}
+
+func (c *HTTPCache) setRedis(ctx context.Context, page *CachedPage) {
+	if c.rdb == nil {
+		return
+	}
+	raw, err := json.Marshal(page)
+	if err != nil {
+		return
+	}
+	rctx, cancel := context.WithTimeout(ctx, searchCacheRedisTimeout)
+	defer cancel()
+
+	if err := c.rdb.Set(rctx, httpCacheKeyPrefix+page.URL, raw, c.config.TTL).Err(); err != nil {
+		log.Printf("[HTTPCache] Redis write failed, using memory only: %v", err)
+	}
+}
+
+func (c *HTTPCache) deleteRedis(ctx context.Context, url string) {
+	if c.rdb == nil {
+		return
+	}
+	rctx, cancel := context.WithTimeout(ctx, searchCacheRedisTimeout)
+	defer cancel()
+	c.rdb.Del(rctx, httpCacheKeyPrefix+url)
+}
+
+// Stats returns outcome counters and current memory usage
+func (c *HTTPCache) Stats() HTTPCacheStats {
+	c.mu.Lock()
+	entries, bytes := c.lru.Len(), c.bytes
+	c.mu.Unlock()
+	return HTTPCacheStats{
+		Hits:        c.hits.Load(),
+		Revalidated: c.revalidated.Load(),
+		Misses:      c.misses.Load(),
+		Entries:     entries,
+		Bytes:       bytes,
+	}
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebParserHTTPCache(t *testing.T) {
+	var full, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(clutteredPage))
+	}))
+	defer srv.Close()
+
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+	cache := NewHTTPCache(HTTPCacheConfig{ReuseWindow: time.Hour}, nil)
+	tool.SetHTTPCache(cache)
+
+	first := parsePage(t, tool, srv.URL, map[string]interface{}{})
+	second := parsePage(t, tool, srv.URL, map[string]interface{}{})
+	if first.Metadata["http_cache"] != HTTPCacheMiss || second.Metadata["http_cache"] != HTTPCacheHit {
+		t.Errorf("expected miss then hit, got %v then %v", first.Metadata["http_cache"], second.Metadata["http_cache"])
+	}
+
+	// Outside the reuse window the body is revalidated with a conditional request
+	cache.config.ReuseWindow = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	third := parsePage(t, tool, srv.URL, map[string]interface{}{})
+	if third.Metadata["http_cache"] != HTTPCacheRevalidated || third.Metadata["from_cache"] != true {
+		t.Errorf("expected revalidated cached body, got %v", third.Metadata["http_cache"])
+	}
+
+	if full.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("expected 1 full download and 1 304, got %d and %d", full.Load(), notModified.Load())
+	}
+	if stats := tool.HTTPCacheStats(); stats.Hits != 1 || stats.Revalidated != 1 || stats.Misses != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHTTPCacheByteBudget(t *testing.T) {
+	ctx := context.Background()
+	cache := NewHTTPCache(HTTPCacheConfig{MaxBytes: 10}, nil)
+
+	cache.Store(ctx, &CachedPage{URL: "a", Body: []byte("123456"), StoredAt: time.Now()})
+	cache.Store(ctx, &CachedPage{URL: "b", Body: []byte("123456"), StoredAt: time.Now()})
+	cache.Store(ctx, &CachedPage{URL: "huge", Body: make([]byte, 11), StoredAt: time.Now()})
+
+	if cache.Lookup(ctx, "a") != nil {
+		t.Error("expected oldest page evicted to stay within budget")
+	}
+	if cache.Lookup(ctx, "b") == nil || cache.Lookup(ctx, "huge") != nil {
+		t.Error("expected b kept and the oversized page never stored")
+	}
+	if stats := cache.Stats(); stats.Bytes != 6 {
+		t.Errorf("expected 6 bytes held, got %d", stats.Bytes)
+	}
+}
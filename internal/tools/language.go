@@ -0,0 +1,339 @@
+// internal/tools/language.go
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go-llama/internal/config"
+)
+
+// LanguageUnknown is reported for text too short or too mixed to call
+const LanguageUnknown = "und"
+
+// Language policies: what the web parser does with a page that is not in the target language
+const (
+	LanguagePolicyKeep      = "keep"      // Return it as it is
+	LanguagePolicySkip      = "skip"      // Fail the parse with FailureKindWrongLanguage so a fallback URL is tried
+	LanguagePolicyTranslate = "translate" // Translate the extracted content with the translation model
+)
+
+const (
+	languageSampleRunes   = 4000 // Text read from the start of a page
+	languageMinLetters    = 60   // Fewer letters than this is LanguageUnknown
+	languageProfileSize   = 300  // Trigrams kept per profile
+	minLanguageConfidence = 0.05 // Below this a detection is not acted on
+)
+
+// LanguageDetection is the detected language of a text
+type LanguageDetection struct {
+	Code       string  `json:"code"`       // ISO 639-1 code, or LanguageUnknown
+	Confidence float64 `json:"confidence"` // 0-1; margin over the next closest language
+}
+
+// Reliable reports whether the detection is certain enough to act on
+func (d LanguageDetection) Reliable() bool {
+	return d.Code != LanguageUnknown && d.Confidence >= minLanguageConfidence
+}
+
+// LanguageConfig sets the language pages are expected in and the policy for others
+type LanguageConfig struct {
+	Target         string // ISO 639-1 code; empty disables the policy
+	Policy         string // One of the LanguagePolicy* constants; empty is LanguagePolicyKeep
+	TranslateURL   string // Model that translates under LanguagePolicyTranslate
+	TranslateModel string
+}
+
+// NormalizeLanguagePolicy returns the policy constant for s, or "" if unknown
+func NormalizeLanguagePolicy(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", LanguagePolicyKeep:
+		return LanguagePolicyKeep
+	case LanguagePolicySkip:
+		return LanguagePolicySkip
+	case LanguagePolicyTranslate:
+		return LanguagePolicyTranslate
+	}
+	return ""
+}
+
+var languageNames = map[string]string{
+	"en": "English", "de": "German", "fr": "French", "es": "Spanish", "it": "Italian",
+	"pt": "Portuguese", "nl": "Dutch", "ru": "Russian", "el": "Greek", "ar": "Arabic",
+	"he": "Hebrew", "zh": "Chinese", "ja": "Japanese", "ko": "Korean", "th": "Thai", "hi": "Hindi",
+}
+
+// LanguageName returns the English name of a language code, or the code itself
+func LanguageName(code string) string {
+	if name, ok := languageNames[strings.ToLower(code)]; ok {
+		return name
+	}
+	return code
+}
+
+// Scripts that identify a language on their own. Japanese is checked before Chinese,
+// since Japanese text mixes kana with Han characters.
+var languageScripts = []struct {
+	code  string
+	table *unicode.RangeTable
+}{
+	{"ru", unicode.Cyrillic},
+	{"el", unicode.Greek},
+	{"ar", unicode.Arabic},
+	{"he", unicode.Hebrew},
+	{"ko", unicode.Hangul},
+	{"th", unicode.Thai},
+	{"hi", unicode.Devanagari},
+}
+
+// Sample text the Latin-script profiles are built from. Common words and endings are
+// what set the languages apart, so ordinary prose works better than word lists.
+var languageSamples = map[string]string{
+	"en": `The history of the city goes back more than a thousand years, and most of the old town was built
+		during the period when trade along the river made its merchants wealthy. Today the streets are full of
+		visitors who come to see the churches, the market and the bridges. Researchers have found that the
+		population grew quickly after the railway arrived, which changed the way people worked and where they
+		lived. The results of the study show that this was one of the most important changes in the region,
+		and that it still shapes the economy. If you want to understand why the town looks the way it does,
+		you should start with the river and the people who used it. They were not only traders but also
+		farmers, builders and teachers, and their work is what made the place what it is today.`,
+	"de": `Die Geschichte der Stadt reicht mehr als tausend Jahre zurück, und der größte Teil der Altstadt
+		wurde in der Zeit gebaut, in der der Handel auf dem Fluss die Kaufleute reich gemacht hat. Heute sind
+		die Straßen voller Besucher, die die Kirchen, den Markt und die Brücken sehen wollen. Forscher haben
+		herausgefunden, dass die Bevölkerung nach der Ankunft der Eisenbahn schnell gewachsen ist, was die Art
+		und Weise verändert hat, wie die Menschen gearbeitet und wo sie gewohnt haben. Die Ergebnisse der
+		Untersuchung zeigen, dass dies eine der wichtigsten Veränderungen in der Region war und dass sie die
+		Wirtschaft noch immer prägt. Wer verstehen will, warum die Stadt so aussieht, sollte mit dem Fluss und
+		den Menschen beginnen, die ihn genutzt haben. Sie waren nicht nur Händler, sondern auch Bauern.`,
+	"fr": `L'histoire de la ville remonte à plus de mille ans, et la plus grande partie de la vieille ville a
+		été construite à l'époque où le commerce sur le fleuve a enrichi ses marchands. Aujourd'hui, les rues
+		sont pleines de visiteurs qui viennent voir les églises, le marché et les ponts. Les chercheurs ont
+		découvert que la population a augmenté rapidement après l'arrivée du chemin de fer, ce qui a changé la
+		manière dont les gens travaillaient et l'endroit où ils vivaient. Les résultats de l'étude montrent que
+		c'était l'un des changements les plus importants de la région et qu'il façonne encore l'économie. Si
+		vous voulez comprendre pourquoi la ville ressemble à ce qu'elle est, il faut commencer par le fleuve et
+		par les gens qui l'utilisaient. Ils n'étaient pas seulement des marchands, mais aussi des paysans.`,
+	"es": `La historia de la ciudad se remonta a más de mil años, y la mayor parte del casco antiguo se
+		construyó en la época en que el comercio a lo largo del río hizo ricos a sus mercaderes. Hoy las calles
+		están llenas de visitantes que vienen a ver las iglesias, el mercado y los puentes. Los investigadores
+		descubrieron que la población creció rápidamente después de la llegada del ferrocarril, lo que cambió
+		la forma en que la gente trabajaba y el lugar donde vivía. Los resultados del estudio muestran que fue
+		uno de los cambios más importantes de la región y que todavía da forma a la economía. Si quieres
+		entender por qué la ciudad tiene este aspecto, debes empezar por el río y por las personas que lo
+		usaban. No eran solo comerciantes, sino también campesinos, constructores y maestros.`,
+	"it": `La storia della città risale a più di mille anni fa, e la maggior parte del centro storico fu
+		costruita nel periodo in cui il commercio lungo il fiume rese ricchi i suoi mercanti. Oggi le strade
+		sono piene di visitatori che vengono a vedere le chiese, il mercato e i ponti. I ricercatori hanno
+		scoperto che la popolazione è cresciuta rapidamente dopo l'arrivo della ferrovia, il che ha cambiato il
+		modo in cui le persone lavoravano e il luogo in cui vivevano. I risultati dello studio mostrano che
+		questo è stato uno dei cambiamenti più importanti della regione e che influenza ancora l'economia. Se
+		vuoi capire perché la città ha questo aspetto, devi cominciare dal fiume e dalle persone che lo
+		usavano. Non erano soltanto mercanti, ma anche contadini, costruttori e maestri.`,
+	"pt": `A história da cidade remonta a mais de mil anos, e a maior parte da cidade velha foi construída na
+		época em que o comércio ao longo do rio tornou ricos os seus mercadores. Hoje as ruas estão cheias de
+		visitantes que vêm ver as igrejas, o mercado e as pontes. Os pesquisadores descobriram que a população
+		cresceu rapidamente depois da chegada da ferrovia, o que mudou a forma como as pessoas trabalhavam e o
+		lugar onde moravam. Os resultados do estudo mostram que esta foi uma das mudanças mais importantes da
+		região e que ainda molda a economia. Se você quer entender por que a cidade tem essa aparência, deve
+		começar pelo rio e pelas pessoas que o usavam. Eles não eram apenas comerciantes, mas também
+		agricultores, construtores e professores, e foi o trabalho deles que fez da cidade o que ela é hoje.`,
+	"nl": `De geschiedenis van de stad gaat meer dan duizend jaar terug, en het grootste deel van de oude
+		binnenstad werd gebouwd in de tijd dat de handel op de rivier de kooplieden rijk maakte. Vandaag zijn
+		de straten vol bezoekers die de kerken, de markt en de bruggen komen bekijken. Onderzoekers hebben
+		ontdekt dat de bevolking snel groeide na de komst van de spoorweg, wat de manier veranderde waarop
+		mensen werkten en waar ze woonden. De resultaten van het onderzoek laten zien dat dit een van de
+		belangrijkste veranderingen in de regio was en dat het de economie nog steeds vormt. Wie wil begrijpen
+		waarom de stad er zo uitziet, moet beginnen bij de rivier en bij de mensen die hem gebruikten. Zij
+		waren niet alleen handelaars, maar ook boeren, bouwers en leraren.`,
+}
+
+// languageProfiles holds the trigram ranks of each sample, built once at startup
+var languageProfiles = buildLanguageProfiles()
+
+func buildLanguageProfiles() map[string]map[string]int {
+	profiles := make(map[string]map[string]int, len(languageSamples))
+	for code, sample := range languageSamples {
+		profiles[code] = trigramRanks(sample)
+	}
+	return profiles
+}
+
+// trigramRanks returns the rank of each of the most frequent letter trigrams in text.
+// Words are padded with spaces so beginnings and endings count as their own trigrams.
+func trigramRanks(text string) map[string]int {
+	counts := map[string]int{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+	trigrams := make([]string, 0, len(counts))
+	for trigram := range counts {
+		trigrams = append(trigrams, trigram)
+	}
+	sort.Slice(trigrams, func(i, j int) bool {
+		if counts[trigrams[i]] != counts[trigrams[j]] {
+			return counts[trigrams[i]] > counts[trigrams[j]]
+		}
+		return trigrams[i] < trigrams[j]
+	})
+	if len(trigrams) > languageProfileSize {
+		trigrams = trigrams[:languageProfileSize]
+	}
+	ranks := make(map[string]int, len(trigrams))
+	for i, trigram := range trigrams {
+		ranks[trigram] = i
+	}
+	return ranks
+}
+
+// DetectLanguage identifies the language of text from the start of it. Non-Latin
+// scripts decide on their own; Latin text is matched against trigram profiles by the
+// distance between trigram ranks.
+func DetectLanguage(text string) LanguageDetection {
+	runes := []rune(text)
+	if len(runes) > languageSampleRunes {
+		runes = runes[:languageSampleRunes]
+	}
+	sample := string(runes)
+
+	letters, latin, kana, han := 0, 0, 0, 0
+	scripts := make([]int, len(languageScripts))
+	for _, r := range sample {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		default:
+			for i, script := range languageScripts {
+				if unicode.Is(script.table, r) {
+					scripts[i]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 {
+		return LanguageDetection{Code: LanguageUnknown}
+	}
+	share := func(n int) float64 { return float64(n) / float64(letters) }
+
+	// Ideographic text packs a word into a letter or two, so it needs fewer of them
+	switch {
+	case kana > 0 && share(kana+han) > 0.5:
+		return LanguageDetection{Code: "ja", Confidence: share(kana + han)}
+	case share(han) > 0.5:
+		return LanguageDetection{Code: "zh", Confidence: share(han)}
+	}
+	if letters < languageMinLetters {
+		return LanguageDetection{Code: LanguageUnknown}
+	}
+	for i, script := range languageScripts {
+		if share(scripts[i]) > 0.5 {
+			return LanguageDetection{Code: script.code, Confidence: share(scripts[i])}
+		}
+	}
+	if share(latin) <= 0.5 {
+		return LanguageDetection{Code: LanguageUnknown}
+	}
+
+	ranks := trigramRanks(sample)
+	best, runnerUp := "", ""
+	bestDistance, runnerUpDistance := -1, -1
+	for code, profile := range languageProfiles {
+		distance := 0
+		for trigram, rank := range ranks {
+			if profileRank, ok := profile[trigram]; ok {
+				distance += abs(rank - profileRank)
+			} else {
+				distance += languageProfileSize
+			}
+		}
+		switch {
+		case bestDistance < 0 || distance < bestDistance || (distance == bestDistance && code < best):
+			runnerUp, runnerUpDistance = best, bestDistance
+			best, bestDistance = code, distance
+		case runnerUpDistance < 0 || distance < runnerUpDistance:
+			runnerUp, runnerUpDistance = code, distance
+		}
+	}
+	if runnerUp == "" || runnerUpDistance == 0 {
+		return LanguageDetection{Code: best}
+	}
+	return LanguageDetection{Code: best, Confidence: float64(runnerUpDistance-bestDistance) / float64(runnerUpDistance)}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// translateText asks the translation model to translate text from one language to
+// another, returning the translation and the tokens it cost
+func translateText(ctx context.Context, llmClient interface{}, cfg LanguageConfig, text, from string) (string, int, error) {
+	type LLMCaller interface {
+		Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error)
+	}
+	client, ok := llmClient.(LLMCaller)
+	if !ok || cfg.TranslateURL == "" {
+		return "", 0, fmt.Errorf("no translation model configured")
+	}
+
+	prompt := fmt.Sprintf(`Translate the following text from %s to %s.
+Keep headings, lists, numbers, names, quotes and URLs. Do not summarize, explain or add anything.
+
+%s`, LanguageName(from), LanguageName(cfg.Target), text)
+	payload := map[string]interface{}{
+		"model": cfg.TranslateModel,
+		"messages": []map[string]string{
+			{"role": "system", "content": "You are a translator. Output only the translation."},
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0.1,
+		"stream":      false,
+	}
+
+	endTranslate := StartSpan(ctx, SpanLLMTranslate)
+	body, err := client.Call(ctx, config.GetChatURL(cfg.TranslateURL), payload)
+	endTranslate()
+	if err != nil {
+		return "", 0, fmt.Errorf("translation call failed: %w", err)
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", 0, fmt.Errorf("failed to decode translation: %w", err)
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", 0, fmt.Errorf("translation model returned nothing")
+	}
+	translated := strings.TrimSpace(resp.Choices[0].Message.Content)
+	tokens := resp.Usage.TotalTokens
+	if tokens == 0 {
+		tokens = (len(prompt) + len(translated)) / 4
+	}
+	return translated, tokens, nil
+}
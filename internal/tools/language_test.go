@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Fixture pages on one topic in three languages, written apart from the profile samples
+var languagePages = map[string]string{
+	"en": `<html><head><title>Growing tomatoes indoors</title></head><body><article><h1>Growing tomatoes indoors</h1>
+<p>Tomatoes need at least eight hours of strong light every day, so most indoor growers use lamps during the winter months.</p>
+<p>Water the plants when the top of the soil feels dry, and keep the temperature steady to stop the fruit from splitting.</p>
+<p>A small fan helps the flowers pollinate and makes the stems stronger as the plants grow taller.</p></article></body></html>`,
+	"de": `<html><head><title>Tomaten in der Wohnung</title></head><body><article><h1>Tomaten in der Wohnung anbauen</h1>
+<p>Tomaten brauchen jeden Tag mindestens acht Stunden helles Licht, deshalb verwenden die meisten Gärtner im Winter Lampen.</p>
+<p>Gießen Sie die Pflanzen, wenn sich die Erde oben trocken anfühlt, und halten Sie die Temperatur gleichmäßig, damit die Früchte nicht platzen.</p>
+<p>Ein kleiner Ventilator hilft bei der Bestäubung der Blüten und macht die Stängel stärker, während die Pflanzen wachsen.</p></article></body></html>`,
+	"fr": `<html><head><title>Cultiver des tomates chez soi</title></head><body><article><h1>Cultiver des tomates à l'intérieur</h1>
+<p>Les tomates ont besoin d'au moins huit heures de lumière forte chaque jour, c'est pourquoi la plupart des jardiniers utilisent des lampes en hiver.</p>
+<p>Arrosez les plantes lorsque le dessus de la terre est sec, et gardez une température stable pour éviter que les fruits ne se fendent.</p>
+<p>Un petit ventilateur aide à la pollinisation des fleurs et rend les tiges plus solides pendant que les plantes grandissent.</p></article></body></html>`,
+}
+
+func languageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := languagePages[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDetectLanguage(t *testing.T) {
+	for code, page := range languagePages {
+		detected := DetectLanguage(rawPageText([]byte(page)))
+		if detected.Code != code || !detected.Reliable() {
+			t.Errorf("expected %s detected reliably, got %+v", code, detected)
+		}
+	}
+	for text, want := range map[string]string{
+		"Помидоры нуждаются как минимум в восьми часах яркого света каждый день, поэтому зимой используют лампы.": "ru",
+		"トマトは毎日少なくとも八時間の強い光が必要です。": "ja",
+		"Too short to tell.": LanguageUnknown,
+	} {
+		if detected := DetectLanguage(text); detected.Code != want {
+			t.Errorf("expected %s for %q, got %+v", want, text, detected)
+		}
+	}
+}
+
+func TestWebParserLanguagePolicy(t *testing.T) {
+	srv := languageServer(t)
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+
+	// Keep (the default) only reports the language
+	for code := range languagePages {
+		result := parsePage(t, tool, srv.URL+"/"+code, map[string]interface{}{})
+		if result.Metadata["language"] != code {
+			t.Errorf("expected language %s in metadata, got %v", code, result.Metadata["language"])
+		}
+	}
+
+	tool.SetLanguage(LanguageConfig{Target: "en", Policy: LanguagePolicySkip})
+	parsePage(t, tool, srv.URL+"/en", map[string]interface{}{})
+	for _, code := range []string{"de", "fr"} {
+		result, err := tool.Execute(context.Background(), map[string]interface{}{"url": srv.URL + "/" + code})
+		if !errors.Is(err, ErrWrongLanguage) || result.FailureKind != FailureKindWrongLanguage {
+			t.Errorf("expected the %s page skipped as wrong_language, got %v (%+v)", code, err, result)
+		}
+		if FailureKindOf(err) != FailureKindWrongLanguage {
+			t.Errorf("expected the error to map to wrong_language, got %q", FailureKindOf(err))
+		}
+	}
+}
+
+func TestWebParserTranslatesForeignPages(t *testing.T) {
+	srv := languageServer(t)
+	llm := &fakeSummaryLLM{reply: "Tomatoes need at least eight hours of bright light every day.", tokens: 240}
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, llm, 6000)
+	tool.SetLanguage(LanguageConfig{Target: "en", Policy: LanguagePolicyTranslate, TranslateURL: "http://simple:8080", TranslateModel: "simple-model"})
+
+	result := parsePage(t, tool, srv.URL+"/de", map[string]interface{}{})
+	if !strings.Contains(result.Output, "bright light") || strings.Contains(result.Output, "Pflanzen") {
+		t.Errorf("expected the translated content in place of the original:\n%s", result.Output)
+	}
+	if result.TokensUsed != 240 || result.Metadata["translation_tokens"] != 240 || result.Metadata["translated_from"] != "de" {
+		t.Errorf("expected the translation accounted, got %d tokens and %v", result.TokensUsed, result.Metadata)
+	}
+	if llm.payload["model"] != "simple-model" || !strings.Contains(llm.userPrompt(), "from German to English") {
+		t.Errorf("expected the translation model asked for German to English, got %v: %s", llm.payload["model"], llm.userPrompt())
+	}
+
+	// English pages cost nothing
+	llm.payload = nil
+	if result := parsePage(t, tool, srv.URL+"/en", map[string]interface{}{}); result.TokensUsed != 0 || llm.payload != nil {
+		t.Errorf("expected no translation of an English page, got %d tokens", result.TokensUsed)
+	}
+}
@@ -0,0 +1,110 @@
+// internal/tools/output_limit.go
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go-llama/internal/utils"
+)
+
+// Defaults for OutputLimits
+const (
+	DefaultMaxOutputBytes     = 64 * 1024
+	DefaultHardMaxOutputBytes = 4 * 1024 * 1024
+)
+
+// Metadata keys set on a result whose output was cut to its tool's limit
+const (
+	MetaOutputTruncated     = "output_truncated"
+	MetaOriginalOutputBytes = "original_output_bytes"
+)
+
+// outputElisionMarker replaces the middle of a truncated output
+const outputElisionMarker = "\n\n[... %d bytes elided ...]\n\n"
+
+// outputHeadShare is the part of a truncated output's room given to its head; the
+// rest keeps the tail
+const outputHeadShare = 2.0 / 3
+
+// OutputLimits caps the text a tool execution returns, so one large page cannot
+// flood action results, prompts and the saved state
+type OutputLimits struct {
+	MaxBytes     int            // Longer outputs keep their head and tail around an elision marker
+	PerTool      map[string]int // MaxBytes by tool name
+	HardMaxBytes int            // Outputs over this are refused with an OutputTooLargeError
+}
+
+// maxBytes returns the truncation limit for tool
+func (l OutputLimits) maxBytes(tool string) int {
+	if limit := l.PerTool[tool]; limit > 0 {
+		return limit
+	}
+	if l.MaxBytes > 0 {
+		return l.MaxBytes
+	}
+	return DefaultMaxOutputBytes
+}
+
+func (l OutputLimits) hardMaxBytes() int {
+	if l.HardMaxBytes > 0 {
+		return l.HardMaxBytes
+	}
+	return DefaultHardMaxOutputBytes
+}
+
+// LimitOutput returns middleware enforcing limits on every tool's output. An output
+// over the tool's limit is cut to it, with the original size recorded in metadata; one
+// over the hard ceiling fails the call. Zero values take the defaults.
+func LimitOutput(limits OutputLimits) Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error) {
+			result, err := next(ctx, toolName, params)
+			if result == nil {
+				return result, err
+			}
+			size := len(result.Output)
+			if hard := limits.hardMaxBytes(); size > hard {
+				tooLarge := &OutputTooLargeError{Tool: toolName, Size: size, Limit: hard}
+				log.Printf("[ToolRegistry] Refusing output of tool '%s': %v", toolName, tooLarge)
+				result.Output = ""
+				result.Success = false
+				result.Error = tooLarge.Error()
+				result.FailureKind = FailureKindOutputTooLarge
+				setMetadata(result, MetaOriginalOutputBytes, size)
+				return result, tooLarge
+			}
+			if limit := limits.maxBytes(toolName); size > limit {
+				result.Output = truncateHeadTail(result.Output, limit)
+				setMetadata(result, MetaOutputTruncated, true)
+				setMetadata(result, MetaOriginalOutputBytes, size)
+				log.Printf("[ToolRegistry] Truncated output of tool '%s' from %d to %d bytes", toolName, size, len(result.Output))
+			}
+			return result, err
+		}
+	}
+}
+
+// truncateHeadTail cuts s to at most limit bytes, keeping its head and tail around a
+// marker giving the bytes left out. Cuts do not split characters.
+func truncateHeadTail(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	// The marker for the whole length is at least as long as the one finally used
+	room := limit - len(fmt.Sprintf(outputElisionMarker, len(s)))
+	if room <= 0 {
+		return utils.Prefix(s, limit)
+	}
+	head := utils.Prefix(s, int(float64(room)*outputHeadShare))
+	tail := utils.Suffix(s, room-len(head))
+	return head + fmt.Sprintf(outputElisionMarker, len(s)-len(head)-len(tail)) + tail
+}
+
+func setMetadata(result *ToolResult, key string, value interface{}) {
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata[key] = value
+}
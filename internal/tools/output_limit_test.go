@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// limited runs output through LimitOutput as the named tool's result
+func limited(limits OutputLimits, tool, output string) (*ToolResult, error) {
+	execute := LimitOutput(limits)(func(ctx context.Context, _ string, _ map[string]interface{}) (*ToolResult, error) {
+		return &ToolResult{Success: true, Output: output}, nil
+	})
+	return execute(context.Background(), tool, nil)
+}
+
+func TestLimitOutputKeepsOutputAtTheLimit(t *testing.T) {
+	output := strings.Repeat("a", 100)
+	result, err := limited(OutputLimits{MaxBytes: 100, HardMaxBytes: 1000}, "search", output)
+	if err != nil || result.Output != output {
+		t.Fatalf("expected the output untouched at exactly the limit, got %d bytes (%v)", len(result.Output), err)
+	}
+	if _, ok := result.Metadata[MetaOutputTruncated]; ok {
+		t.Error("expected no truncation flag")
+	}
+}
+
+func TestLimitOutputKeepsHeadAndTail(t *testing.T) {
+	output := "HEAD" + strings.Repeat("é", 500) + "TAIL"
+	limits := OutputLimits{MaxBytes: 200, PerTool: map[string]int{"web_parse_unified": 400}, HardMaxBytes: 5000}
+
+	result, err := limited(limits, "search", output)
+	if err != nil || !result.Success {
+		t.Fatalf("expected a truncated success, got %+v (%v)", result, err)
+	}
+	if len(result.Output) > 200 || !utf8.ValidString(result.Output) {
+		t.Errorf("expected valid UTF-8 within 200 bytes, got %d bytes", len(result.Output))
+	}
+	if !strings.HasPrefix(result.Output, "HEAD") || !strings.HasSuffix(result.Output, "TAIL") || !strings.Contains(result.Output, "bytes elided") {
+		t.Errorf("expected head, tail and an elision marker, got %q", result.Output)
+	}
+	if result.Metadata[MetaOutputTruncated] != true || result.Metadata[MetaOriginalOutputBytes] != len(output) {
+		t.Errorf("expected the truncation recorded in metadata, got %v", result.Metadata)
+	}
+
+	if result, _ := limited(limits, "web_parse_unified", output); len(result.Output) <= 200 || len(result.Output) > 400 {
+		t.Errorf("expected the per-tool limit of 400 bytes, got %d", len(result.Output))
+	}
+}
+
+func TestLimitOutputRefusesOverCeiling(t *testing.T) {
+	result, err := limited(OutputLimits{MaxBytes: 100, HardMaxBytes: 1000}, "web_parse_unified", strings.Repeat("a", 1001))
+	var tooLarge *OutputTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Size != 1001 || tooLarge.Limit != 1000 {
+		t.Fatalf("expected an OutputTooLargeError, got %v", err)
+	}
+	if !errors.Is(err, ErrOutputTooLarge) || FailureKindOf(err) != FailureKindOutputTooLarge {
+		t.Errorf("expected the error to match ErrOutputTooLarge, got %v", err)
+	}
+	if result.Success || result.Output != "" || result.FailureKind != FailureKindOutputTooLarge {
+		t.Errorf("expected a failed result without output, got %+v", result)
+	}
+	if !isClientError(err) {
+		t.Error("expected a refused output not to count against the tool's health")
+	}
+}
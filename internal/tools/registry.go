@@ -3,17 +3,22 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
+	"sort"
 	"sync"
 	"time"
+
+	"go-llama/internal/utils"
 )
 
-// Registry manages all available tools
+// Registry manages all available tools. Tools may be registered and unregistered while
+// others are executing; a running call finishes with the tool it started with.
 type Registry struct {
-	tools map[string]Tool
-	mu    sync.RWMutex
+	tools      map[string]Tool
+	middleware []Middleware
+	mu         sync.RWMutex
 }
 
 // NewRegistry creates a new tool registry
@@ -38,6 +43,26 @@ func (r *Registry) Register(tool Tool) error {
 	return nil
 }
 
+// Unregister removes a tool. Calls already running are not interrupted.
+func (r *Registry) Unregister(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[name]; !exists {
+		return fmt.Errorf("tool not found: %s", name)
+	}
+	delete(r.tools, name)
+	log.Printf("[ToolRegistry] Unregistered tool: %s", name)
+	return nil
+}
+
+// Use adds middleware around every later tool execution. The first added is outermost.
+func (r *Registry) Use(mw Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw)
+}
+
 // Get retrieves a tool by name
 func (r *Registry) Get(name string) (Tool, error) {
 	r.mu.RLock()
@@ -54,7 +79,7 @@ func (r *Registry) Get(name string) (Tool, error) {
 // Execute runs a tool with the given parameters and context
 // Includes retry logic for timeout failures in idle mode
 func (r *Registry) Execute(ctx context.Context, toolName string, params map[string]interface{}, execCtx ExecutionContext) (*ToolResult, error) {
-	tool, err := r.Get(toolName)
+	execute, err := r.executor(toolName)
 	if err != nil {
 		return nil, err
 	}
@@ -97,23 +122,58 @@ func (r *Registry) Execute(ctx context.Context, toolName string, params map[stri
 
 		// Execute tool
 		startTime := time.Now()
-		result, err := tool.Execute(timeoutCtx, params)
+		result, err := execute(timeoutCtx, toolName, params)
 		duration := time.Since(startTime)
 		cancel() // Clean up context immediately
 
 		if err != nil {
 			lastErr = err
-			lastResult = &ToolResult{
-				Success:  false,
-				Error:    err.Error(),
-				Duration: duration,
+			// Keep the tool's own result when it has one so failure kind and metadata survive
+			if result != nil {
+				result.Success = false
+				if result.Error == "" {
+					result.Error = err.Error()
+				}
+				result.Duration = duration
+				lastResult = result
+			} else {
+				lastResult = &ToolResult{
+					Success:     false,
+					Error:       err.Error(),
+					Duration:    duration,
+					FailureKind: FailureKindOf(err),
+				}
+			}
+
+			// Unsupported content will not change on retry
+			if result != nil && result.FailureKind == FailureKindUnsupportedContent {
+				log.Printf("[ToolRegistry] Tool '%s' rejected unsupported content: %v", toolName, err)
+				return lastResult, err
+			}
+
+			// Neither will a domain policy refusal
+			if result != nil && result.FailureKind == FailureKindDomainBlocked {
+				log.Printf("[ToolRegistry] Tool '%s' refused by domain policy: %v", toolName, err)
+				return lastResult, err
+			}
+
+			// Nor the language of a page
+			if result != nil && result.FailureKind == FailureKindWrongLanguage {
+				log.Printf("[ToolRegistry] Tool '%s' skipped a page for its language: %v", toolName, err)
+				return lastResult, err
 			}
 			
 			// Check if this was a timeout
 			isTimeout := timeoutCtx.Err() == context.DeadlineExceeded ||
-			            strings.Contains(strings.ToLower(err.Error()), "timeout") ||
-			            strings.Contains(strings.ToLower(err.Error()), "deadline exceeded")
+			            errors.Is(err, ErrTimeout) ||
+			            errors.Is(err, context.DeadlineExceeded)
 			
+			// A retry cannot succeed once the caller's own deadline has passed
+			if isTimeout && attempt < maxRetries && ctx.Err() != nil {
+				log.Printf("[ToolRegistry] Tool '%s' stopped by caller deadline after %s, not retrying", toolName, duration)
+				return lastResult, err
+			}
+
 			if isTimeout && attempt < maxRetries {
 				log.Printf("[ToolRegistry] Tool '%s' timed out after %s, will retry with extended timeout", 
 					toolName, duration)
@@ -145,6 +205,25 @@ func (r *Registry) Execute(ctx context.Context, toolName string, params map[stri
 	return lastResult, lastErr
 }
 
+// executor returns the tool's Execute wrapped in the registry's middleware, fixed at
+// the time of the call
+func (r *Registry) executor(name string) (ExecuteFunc, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, exists := r.tools[name]
+	if !exists {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
+	execute := func(ctx context.Context, _ string, params map[string]interface{}) (*ToolResult, error) {
+		return tool.Execute(ctx, params)
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		execute = r.middleware[i](execute)
+	}
+	return execute, nil
+}
+
 // List returns all registered tool names and descriptions
 func (r *Registry) List() map[string]string {
 	r.mu.RLock()
@@ -157,10 +236,41 @@ func (r *Registry) List() map[string]string {
 	return list
 }
 
+// ListDetailed returns the registered tools' metadata sorted by Order, then name
+func (r *Registry) ListDetailed() []ToolInfo {
+	r.mu.RLock()
+	infos := make([]ToolInfo, 0, len(r.tools))
+	for _, tool := range r.tools {
+		infos = append(infos, describe(tool))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Order != infos[j].Order {
+			return infos[i].Order < infos[j].Order
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// describe returns a tool's metadata; its name always comes from Name()
+func describe(tool Tool) ToolInfo {
+	info := ToolInfo{IdleAllowed: true}
+	if d, ok := tool.(Describer); ok {
+		info = d.Info()
+	}
+	info.Name = tool.Name()
+	if info.Description == "" {
+		info.Description = tool.Description()
+	}
+	return info
+}
+
 // RecordUsage logs tool usage for learning (could be extended to store in DB)
 func (r *Registry) RecordUsage(usage *ToolUsage) {
 	log.Printf("[ToolRegistry] Usage: %s in %s context → %s (outcome: %s)", 
-		usage.ToolName, usage.Context, usage.Result.Output[:min(50, len(usage.Result.Output))], usage.Outcome)
+		usage.ToolName, usage.Context, utils.Prefix(usage.Result.Output, 50), usage.Outcome)
 	
 	if usage.Learning != "" {
 		log.Printf("[ToolRegistry] Learning: %s", usage.Learning)
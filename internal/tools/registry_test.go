@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// echoTool succeeds with its name as output
+type echoTool struct {
+	name string
+	info *ToolInfo
+}
+
+func (t *echoTool) Name() string        { return t.name }
+func (t *echoTool) Description() string { return "echoes " + t.name }
+func (t *echoTool) RequiresAuth() bool  { return false }
+
+func (t *echoTool) Execute(ctx context.Context, params map[string]interface{}) (*ToolResult, error) {
+	return &ToolResult{Success: true, Output: t.name}, nil
+}
+
+// describedTool adds metadata to echoTool
+type describedTool struct{ echoTool }
+
+func (t *describedTool) Info() ToolInfo { return *t.info }
+
+func TestRegistryConcurrentRegisterAndExecute(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(&echoTool{name: "stable"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var failures atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("tool_%d", i)
+			if err := r.Register(&echoTool{name: name}); err != nil {
+				failures.Add(1)
+			}
+			r.ListDetailed()
+			if err := r.Unregister(name); err != nil {
+				failures.Add(1)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			result, err := r.Execute(context.Background(), "stable", nil, ExecutionContext{IsInteractive: true})
+			if err != nil || result.Output != "stable" {
+				failures.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := failures.Load(); n != 0 {
+		t.Fatalf("expected every register, unregister and execute to succeed, got %d failures", n)
+	}
+	if infos := r.ListDetailed(); len(infos) != 1 || infos[0].Name != "stable" {
+		t.Errorf("expected only the stable tool left, got %+v", infos)
+	}
+	if err := r.Unregister("tool_0"); err == nil {
+		t.Error("expected unregistering a missing tool to fail")
+	}
+}
+
+func TestRegistryMiddlewareWrapsExecute(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&echoTool{name: "search"})
+
+	var order []string
+	trace := func(label string) Middleware {
+		return func(next ExecuteFunc) ExecuteFunc {
+			return func(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error) {
+				order = append(order, label+" "+toolName)
+				return next(ctx, toolName, params)
+			}
+		}
+	}
+	r.Use(trace("outer"))
+	r.Use(trace("inner"))
+	r.Use(func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error) {
+			result, err := next(ctx, toolName, params)
+			if result != nil {
+				result.Output = strings.ToUpper(result.Output)
+			}
+			return result, err
+		}
+	})
+
+	result, err := r.Execute(context.Background(), "search", nil, ExecutionContext{IsInteractive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "SEARCH" {
+		t.Errorf("expected the middleware to see the result, got %q", result.Output)
+	}
+	if strings.Join(order, ", ") != "outer search, inner search" {
+		t.Errorf("expected middleware applied in the order added, got %v", order)
+	}
+}
+
+func TestListDetailedOrder(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&describedTool{echoTool{name: "parse", info: &ToolInfo{Order: 20, CostHint: CostHigh, ExpectedDuration: time.Second}}})
+	r.Register(&describedTool{echoTool{name: "search", info: &ToolInfo{Description: "Search the web", Order: 10,
+		Parameters: []ToolParameter{{Name: "query", Type: "string", Required: true}, {Name: "max_results", Type: "int"}}}}})
+	r.Register(&echoTool{name: "plain"})
+
+	infos := r.ListDetailed()
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
+	}
+	if strings.Join(names, ",") != "plain,search,parse" {
+		t.Fatalf("expected tools sorted by order then name, got %v", names)
+	}
+	if infos[0].Description != "echoes plain" || !infos[0].IdleAllowed {
+		t.Errorf("expected a plain tool listed with its description and allowed idle, got %+v", infos[0])
+	}
+	if infos[1].Description != "Search the web" || infos[1].ParameterSummary() != "query (string, required), max_results (int)" {
+		t.Errorf("unexpected search info %+v", infos[1])
+	}
+	if infos[2].Description != "echoes parse" || infos[2].IdleAllowed || infos[2].CostHint != CostHigh {
+		t.Errorf("expected the parser's own metadata with its description filled in, got %+v", infos[2])
+	}
+}
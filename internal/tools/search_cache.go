@@ -0,0 +1,239 @@
+// internal/tools/search_cache.go
+package tools
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// searchCacheKeyPrefix namespaces search cache entries in Redis
+const searchCacheKeyPrefix = "growerai:search_cache:"
+
+// searchCacheRedisTimeout bounds each Redis call so a slow Redis never stalls a search
+const searchCacheRedisTimeout = 500 * time.Millisecond
+
+// SearchCacheConfig controls search result caching
+type SearchCacheConfig struct {
+	TTL        time.Duration // How long a result stays fresh (default 6h)
+	MaxEntries int           // LRU bound (default 500)
+}
+
+// SearchCacheStats reports cache usefulness
+type SearchCacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+}
+
+// cachedSearch is what the cache stores per key
+type cachedSearch struct {
+	Response *SearchResponse `json:"response"`
+	Instance string          `json:"instance"`
+	StoredAt time.Time       `json:"stored_at"`
+}
+
+type searchCacheEntry struct {
+	key   string
+	value cachedSearch
+}
+
+// SearchCache is an LRU cache of search responses keyed by normalized query.
+// Entries live in memory; when a Redis client is supplied they are also written
+// through to Redis so they survive restarts. Redis errors fall back to memory only.
+type SearchCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Front = most recently used
+	config  SearchCacheConfig
+	rdb     *redis.Client
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewSearchCache creates a search cache. rdb may be nil for memory-only caching.
+func NewSearchCache(config SearchCacheConfig, rdb *redis.Client) *SearchCache {
+	if config.TTL <= 0 {
+		config.TTL = 6 * time.Hour
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 500
+	}
+	return &SearchCache{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		config:  config,
+		rdb:     rdb,
+	}
+}
+
+// NormalizeSearchQuery lowercases a query, strips surrounding punctuation from each
+// term, and sorts the distinct terms so reordered or re-punctuated queries share a key
+func NormalizeSearchQuery(query string) string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		term = strings.Trim(term, `.,;:!?"'()[]{}`)
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, " ")
+}
+
+// searchCacheKey includes the result limit so a small cached page never answers a larger
+// request, and any filters so a filtered search never answers an unfiltered one
+func searchCacheKey(query string, maxResults int, filters SearchFilters) string {
+	key := fmt.Sprintf("%s|%d", NormalizeSearchQuery(query), maxResults)
+	if filters != (SearchFilters{}) {
+		key += fmt.Sprintf("|%s|%s", filters.Category, filters.TimeRange)
+	}
+	return key
+}
+
+// Get returns a fresh cached response and the instance that originally served it
+func (c *SearchCache) Get(ctx context.Context, query string, maxResults int, filters SearchFilters) (*SearchResponse, string, bool) {
+	key := searchCacheKey(query, maxResults, filters)
+	now := time.Now()
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*searchCacheEntry)
+		if now.Sub(entry.value.StoredAt) < c.config.TTL {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return entry.value.Response, entry.value.Instance, true
+		}
+		c.removeElement(elem)
+	}
+	c.mu.Unlock()
+
+	if value, ok := c.getRedis(ctx, key); ok && now.Sub(value.StoredAt) < c.config.TTL {
+		c.mu.Lock()
+		c.insert(key, value)
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return value.Response, value.Instance, true
+	}
+
+	c.misses.Add(1)
+	return nil, "", false
+}
+
+// Put stores a response, evicting the least recently used entry when full
+func (c *SearchCache) Put(ctx context.Context, query string, maxResults int, filters SearchFilters, response *SearchResponse, instance string) {
+	if response == nil {
+		return
+	}
+	key := searchCacheKey(query, maxResults, filters)
+	value := cachedSearch{Response: response, Instance: instance, StoredAt: time.Now()}
+
+	c.mu.Lock()
+	evicted := c.insert(key, value)
+	c.mu.Unlock()
+
+	c.setRedis(ctx, key, value)
+	for _, k := range evicted {
+		c.deleteRedis(ctx, k)
+	}
+}
+
+// insert adds or refreshes an entry and returns the keys evicted to stay in bounds.
+// Caller must hold c.mu.
+func (c *SearchCache) insert(key string, value cachedSearch) []string {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*searchCacheEntry).value = value
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.lru.PushFront(&searchCacheEntry{key: key, value: value})
+
+	evicted := []string{}
+	for c.lru.Len() > c.config.MaxEntries {
+		oldest := c.lru.Back()
+		evicted = append(evicted, oldest.Value.(*searchCacheEntry).key)
+		c.removeElement(oldest)
+	}
+	return evicted
+}
+
+// removeElement drops an entry from memory. Caller must hold c.mu.
+func (c *SearchCache) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*searchCacheEntry).key)
+}
+
+func (c *SearchCache) getRedis(ctx context.Context, key string) (cachedSearch, bool) {
+	var value cachedSearch
+	if c.rdb == nil {
+		return value, false
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, searchCacheRedisTimeout)
+	defer cancel()
+
+	raw, err := c.rdb.Get(rctx, searchCacheKeyPrefix+key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("[SearchCache] Redis read failed, using memory only: %v", err)
+		}
+		return value, false
+	}
+	if err := json.Unmarshal(raw, &value); err != nil || value.Response == nil {
+		return value, false
+	}
+	return value, true
+}
+
+func (c *SearchCache) setRedis(ctx context.Context, key string, value cachedSearch) {
+	if c.rdb == nil {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, searchCacheRedisTimeout)
+	defer cancel()
+
+	if err := c.rdb.Set(rctx, searchCacheKeyPrefix+key, raw, c.config.TTL).Err(); err != nil {
+		log.Printf("[SearchCache] Redis write failed, using memory only: %v", err)
+	}
+}
+
+func (c *SearchCache) deleteRedis(ctx context.Context, key string) {
+	if c.rdb == nil {
+		return
+	}
+	rctx, cancel := context.WithTimeout(ctx, searchCacheRedisTimeout)
+	defer cancel()
+	c.rdb.Del(rctx, searchCacheKeyPrefix+key)
+}
+
+// Stats returns hit/miss counters and the in-memory entry count
+func (c *SearchCache) Stats() SearchCacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+	return SearchCacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	a := NormalizeSearchQuery("Go  Generics, tutorial?")
+	b := NormalizeSearchQuery("tutorial go generics")
+	if a != b || a != "generics go tutorial" {
+		t.Errorf("expected equal normalized queries, got %q and %q", a, b)
+	}
+}
+
+func TestSearchCacheLRUAndTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := NewSearchCache(SearchCacheConfig{TTL: time.Hour, MaxEntries: 2}, nil)
+	resp := &SearchResponse{Query: "q"}
+
+	cache.Put(ctx, "one", 5, SearchFilters{}, resp, "a")
+	cache.Put(ctx, "two", 5, SearchFilters{}, resp, "a")
+	cache.Get(ctx, "one", 5, SearchFilters{}) // "two" is now least recently used
+	cache.Put(ctx, "three", 5, SearchFilters{}, resp, "a")
+
+	if _, _, ok := cache.Get(ctx, "two", 5, SearchFilters{}); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if _, instance, ok := cache.Get(ctx, "ONE", 5, SearchFilters{}); !ok || instance != "a" {
+		t.Error("expected normalized lookup to hit")
+	}
+	if _, _, ok := cache.Get(ctx, "one", 10, SearchFilters{}); ok {
+		t.Error("expected a different result limit to miss")
+	}
+
+	// Expire entries by ageing them past the TTL
+	for _, elem := range cache.entries {
+		elem.Value.(*searchCacheEntry).value.StoredAt = time.Now().Add(-2 * time.Hour)
+	}
+	if _, _, ok := cache.Get(ctx, "three", 5, SearchFilters{}); ok {
+		t.Error("expected stale entry to miss")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf("expected 2 hits / 3 misses, got %+v", stats)
+	}
+}
+
+func TestSearXNGToolCachesAndBypasses(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"query":"q","number_of_results":1,"results":[{"title":"t","url":"http://example.com","content":"c"}]}`))
+	}))
+	defer srv.Close()
+
+	tool := NewSearXNGTool(srv.URL, ToolConfig{MaxResultsIdle: 5})
+	tool.SetCache(NewSearchCache(SearchCacheConfig{}, nil))
+
+	first, err := tool.Execute(context.Background(), map[string]interface{}{"query": "golang testing"})
+	if err != nil || first.Metadata["cache_hit"] != false {
+		t.Fatalf("expected uncached first search, got %v / %v", err, first.Metadata["cache_hit"])
+	}
+
+	second, _ := tool.Execute(context.Background(), map[string]interface{}{"query": "Testing golang"})
+	if second.Metadata["cache_hit"] != true || second.Metadata["instance"] != srv.URL {
+		t.Errorf("expected cache hit naming the original instance, got %v", second.Metadata)
+	}
+
+	third, _ := tool.Execute(context.Background(), map[string]interface{}{"query": "golang testing", "bypass_cache": true})
+	if third.Metadata["cache_hit"] != false {
+		t.Error("expected bypass_cache to skip the cache")
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 upstream calls, got %d", calls.Load())
+	}
+	if stats := tool.CacheStats(); stats.Hits != 1 {
+		t.Errorf("expected 1 cache hit, got %+v", stats)
+	}
+}
+
+func TestSearXNGToolSendsFilters(t *testing.T) {
+	var queries []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		w.Write([]byte(`{"query":"q","number_of_results":1,"results":[{"title":"t","url":"http://example.com","content":"c"}]}`))
+	}))
+	defer srv.Close()
+
+	tool := NewSearXNGTool(srv.URL, ToolConfig{MaxResultsIdle: 5})
+	tool.SetCache(NewSearchCache(SearchCacheConfig{}, nil))
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"query": "rust async", "category": "News", "time_range": "week"})
+	if err != nil || result.Metadata["category"] != "news" || result.Metadata["time_range"] != "week" {
+		t.Fatalf("expected a filtered search, got %v / %v", err, result.Metadata)
+	}
+	if q := queries[0]; q.Get("categories") != "news" || q.Get("time_range") != "week" {
+		t.Errorf("expected filters sent to SearXNG, got %v", q)
+	}
+
+	// An unfiltered search of the same query is not answered from the filtered one
+	plain, _ := tool.Execute(context.Background(), map[string]interface{}{"query": "rust async"})
+	if plain.Metadata["cache_hit"] != false || queries[1].Has("categories") || queries[1].Has("time_range") {
+		t.Errorf("expected an unfiltered upstream search, got %v / %v", plain.Metadata, queries[1])
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"query": "rust async", "time_range": "decade"}); err == nil {
+		t.Error("expected an unknown time range rejected")
+	}
+}
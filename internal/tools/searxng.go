@@ -3,19 +3,109 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
+
+	"go-llama/internal/utils"
 )
 
+// MetaSearchResults is the ToolResult.Metadata key holding []StructuredSearchResult
+const MetaSearchResults = "results"
+
+// StructuredSearchResult is one ranked search hit for programmatic consumers. The
+// formatted Output is for humans and LLMs; code should read these instead.
+type StructuredSearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+	Engine  string `json:"engine,omitempty"`
+	Rank    int    `json:"rank"` // 1-based position in the result list
+}
+
+// DecodeSearchResults reads structured results from metadata, accepting both the typed
+// slice and the generic form it takes after a JSON round trip (e.g. persisted state)
+func DecodeSearchResults(raw interface{}) []StructuredSearchResult {
+	switch v := raw.(type) {
+	case nil:
+		return nil
+	case []StructuredSearchResult:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var results []StructuredSearchResult
+		if err := json.Unmarshal(data, &results); err != nil {
+			return nil
+		}
+		return results
+	}
+}
+
+// SearchCategories are the SearXNG categories the search tool accepts
+var SearchCategories = []string{"general", "news", "science", "it", "files", "images", "videos", "music", "map", "social media"}
+
+// SearchTimeRanges are the SearXNG time ranges the search tool accepts
+var SearchTimeRanges = []string{"day", "week", "month", "year"}
+
+// NormalizeSearchCategory returns category in the form SearXNG expects, or "" when it
+// is not a known category
+func NormalizeSearchCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	for _, known := range SearchCategories {
+		if category == known {
+			return known
+		}
+	}
+	return ""
+}
+
+// NormalizeSearchTimeRange returns timeRange in the form SearXNG expects, or "" when it
+// is not a known time range
+func NormalizeSearchTimeRange(timeRange string) string {
+	timeRange = strings.ToLower(strings.TrimSpace(timeRange))
+	for _, known := range SearchTimeRanges {
+		if timeRange == known {
+			return known
+		}
+	}
+	return ""
+}
+
+// searchFiltersFromParams reads the optional "category" and "time_range" params,
+// rejecting values SearXNG would not understand
+func searchFiltersFromParams(params map[string]interface{}) (SearchFilters, error) {
+	var filters SearchFilters
+	if raw, _ := params["category"].(string); strings.TrimSpace(raw) != "" {
+		if filters.Category = NormalizeSearchCategory(raw); filters.Category == "" {
+			return filters, fmt.Errorf("invalid 'category' parameter %q (want one of: %s)", raw, strings.Join(SearchCategories, ", "))
+		}
+	}
+	if raw, _ := params["time_range"].(string); strings.TrimSpace(raw) != "" {
+		if filters.TimeRange = NormalizeSearchTimeRange(raw); filters.TimeRange == "" {
+			return filters, fmt.Errorf("invalid 'time_range' parameter %q (want one of: %s)", raw, strings.Join(SearchTimeRanges, ", "))
+		}
+	}
+	return filters, nil
+}
+
 // SearXNGTool implements the Tool interface for web searching
 type SearXNGTool struct {
-	client *SearXNGClient
+	pool   *SearXNGPool
+	cache  *SearchCache // Optional; nil disables caching
 	config ToolConfig
 }
 
-// NewSearXNGTool creates a new SearXNG search tool
+// NewSearXNGTool creates a new SearXNG search tool backed by a single instance
 func NewSearXNGTool(baseURL string, config ToolConfig) *SearXNGTool {
+	return NewSearXNGToolWithInstances([]SearXNGInstance{{URL: baseURL, Weight: 1}}, SearXNGPoolConfig{}, config)
+}
+
+// NewSearXNGToolWithInstances creates a search tool that fails over across instances
+func NewSearXNGToolWithInstances(instances []SearXNGInstance, poolConfig SearXNGPoolConfig, config ToolConfig) *SearXNGTool {
 	// Use idle timeout for client (longer timeout)
 	timeout := config.TimeoutIdle
 	if timeout == 0 {
@@ -23,11 +113,29 @@ func NewSearXNGTool(baseURL string, config ToolConfig) *SearXNGTool {
 	}
 
 	return &SearXNGTool{
-		client: NewSearXNGClient(baseURL, timeout),
+		pool:   NewSearXNGPool(instances, timeout, poolConfig),
 		config: config,
 	}
 }
 
+// SetCache enables result caching for repeated queries
+func (t *SearXNGTool) SetCache(cache *SearchCache) {
+	t.cache = cache
+}
+
+// Ping reports whether any configured SearXNG instance is up
+func (t *SearXNGTool) Ping(ctx context.Context) error {
+	return t.pool.Ping(ctx)
+}
+
+// CacheStats reports cache hits and misses (zero when caching is disabled)
+func (t *SearXNGTool) CacheStats() SearchCacheStats {
+	if t.cache == nil {
+		return SearchCacheStats{}
+	}
+	return t.cache.Stats()
+}
+
 // Name returns the tool identifier
 func (t *SearXNGTool) Name() string {
 	return ToolNameSearch
@@ -38,6 +146,23 @@ func (t *SearXNGTool) Description() string {
 	return "Search the web using SearXNG meta-search engine"
 }
 
+// Info describes the search tool for the tool list
+func (t *SearXNGTool) Info() ToolInfo {
+	return ToolInfo{
+		Description: t.Description(),
+		Parameters: []ToolParameter{
+			{Name: "query", Type: "string", Description: "Search query", Required: true},
+			{Name: "max_results", Type: "int", Description: "Maximum number of results"},
+			{Name: "category", Type: "string", Description: "Restrict to a category: " + strings.Join(SearchCategories, ", ")},
+			{Name: "time_range", Type: "string", Description: "Only results from the past day, week, month or year"},
+		},
+		IdleAllowed:      true,
+		ExpectedDuration: 3 * time.Second,
+		CostHint:         CostMedium,
+		Order:            10,
+	}
+}
+
 // RequiresAuth returns false (no auth needed for search)
 func (t *SearXNGTool) RequiresAuth() bool {
 	return false
@@ -48,6 +173,9 @@ func (t *SearXNGTool) RequiresAuth() bool {
 //   - "query" (string): search query
 //   - "max_results" (int, optional): max number of results
 //   - "is_interactive" (bool, optional): execution context
+//   - "bypass_cache" (bool, optional): skip cached results and fetch fresh ones
+//   - "category" (string, optional): SearXNG category, e.g. "news" or "science"
+//   - "time_range" (string, optional): "day", "week", "month" or "year"
 func (t *SearXNGTool) Execute(ctx context.Context, params map[string]interface{}) (*ToolResult, error) {
 	startTime := time.Now()
 
@@ -58,7 +186,16 @@ func (t *SearXNGTool) Execute(ctx context.Context, params map[string]interface{}
 			Success:  false,
 			Error:    "missing or invalid 'query' parameter",
 			Duration: time.Since(startTime),
-		}, fmt.Errorf("missing query parameter")
+		}, errMissingQuery
+	}
+
+	filters, err := searchFiltersFromParams(params)
+	if err != nil {
+		return &ToolResult{
+			Success:  false,
+			Error:    err.Error(),
+			Duration: time.Since(startTime),
+		}, err
 	}
 
 	// Determine max results based on context
@@ -72,14 +209,29 @@ func (t *SearXNGTool) Execute(ctx context.Context, params map[string]interface{}
 		maxResults = mr
 	}
 
-	// Perform search
-	response, err := t.client.Search(ctx, query, maxResults)
-	if err != nil {
-		return &ToolResult{
-			Success:  false,
-			Error:    err.Error(),
-			Duration: time.Since(startTime),
-		}, err
+	bypassCache, _ := params["bypass_cache"].(bool)
+
+	var response *SearchResponse
+	var instance string
+	cacheHit := false
+	if t.cache != nil && !bypassCache {
+		response, instance, cacheHit = t.cache.Get(ctx, query, maxResults, filters)
+	}
+
+	if !cacheHit {
+		// Perform search (fails over across instances)
+		var err error
+		response, instance, err = t.pool.Search(ctx, query, maxResults, filters)
+		if err != nil {
+			return &ToolResult{
+				Success:  false,
+				Error:    err.Error(),
+				Duration: time.Since(startTime),
+			}, err
+		}
+		if t.cache != nil {
+			t.cache.Put(ctx, query, maxResults, filters, response, instance)
+		}
 	}
 
 	// Format output
@@ -91,6 +243,15 @@ func (t *SearXNGTool) Execute(ctx context.Context, params map[string]interface{}
 		"total_results":     response.NumberOfResults,
 		"returned_results":  len(response.Results),
 		"sources":           t.extractSources(response),
+		MetaSearchResults:   t.structuredResults(response),
+		"instance":          instance,
+		"cache_hit":         cacheHit,
+	}
+	if filters.Category != "" {
+		metadata["category"] = filters.Category
+	}
+	if filters.TimeRange != "" {
+		metadata["time_range"] = filters.TimeRange
 	}
 
 	return &ToolResult{
@@ -113,10 +274,7 @@ func (t *SearXNGTool) formatSearchResults(response *SearchResponse) string {
 		builder.WriteString(fmt.Sprintf("    URL: %s\n", result.URL))
 		
 		// Truncate content to ~200 chars
-		content := result.Content
-		if len(content) > 200 {
-			content = content[:200] + "..."
-		}
+		content := utils.Truncate(result.Content, 200)
 		builder.WriteString(fmt.Sprintf("    %s\n\n", content))
 	}
 
@@ -131,3 +289,18 @@ func (t *SearXNGTool) extractSources(response *SearchResponse) []string {
 	}
 	return sources
 }
+
+// structuredResults converts the response into ranked results for metadata
+func (t *SearXNGTool) structuredResults(response *SearchResponse) []StructuredSearchResult {
+	results := make([]StructuredSearchResult, 0, len(response.Results))
+	for i, result := range response.Results {
+		results = append(results, StructuredSearchResult{
+			Title:   result.Title,
+			URL:     result.URL,
+			Snippet: result.Content,
+			Engine:  result.Engine,
+			Rank:    i + 1,
+		})
+	}
+	return results
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"go-llama/internal/httpclient"
 )
 
 // SearXNGClient handles communication with SearXNG search engine
@@ -21,9 +23,7 @@ type SearXNGClient struct {
 func NewSearXNGClient(baseURL string, timeout time.Duration) *SearXNGClient {
 	return &SearXNGClient{
 		BaseURL: baseURL,
-		HTTPClient: &http.Client{
-			Timeout: timeout,
-		},
+		HTTPClient: httpclient.New(timeout),
 	}
 }
 
@@ -43,8 +43,38 @@ type SearchResponse struct {
 	Results        []SearchResult `json:"results"`
 }
 
+// SearchFilters narrows a search to SearXNG categories and a time range. The zero value
+// searches everything, as SearXNG does by default.
+type SearchFilters struct {
+	Category  string // A SearXNG category such as "news", "science" or "it"
+	TimeRange string // "day", "week", "month" or "year"
+}
+
+// Ping checks the instance's /healthz endpoint
+func (c *SearXNGClient) Ping(ctx context.Context) error {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	u = u.ResolveReference(&url.URL{Path: "/healthz"})
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("health request failed: %w", wrapTimeout(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SearXNG returned %w", &HTTPStatusError{Code: resp.StatusCode})
+	}
+	return nil
+}
+
 // Search performs a search query against SearXNG
-func (c *SearXNGClient) Search(ctx context.Context, query string, maxResults int) (*SearchResponse, error) {
+func (c *SearXNGClient) Search(ctx context.Context, query string, maxResults int, filters SearchFilters) (*SearchResponse, error) {
 	// Build search URL
 	u, err := url.Parse(c.BaseURL)
 	if err != nil {
@@ -54,6 +84,12 @@ func (c *SearXNGClient) Search(ctx context.Context, query string, maxResults int
 	q := u.Query()
 	q.Set("q", query)
 	q.Set("format", "json")
+	if filters.Category != "" {
+		q.Set("categories", filters.Category)
+	}
+	if filters.TimeRange != "" {
+		q.Set("time_range", filters.TimeRange)
+	}
 	u.RawQuery = q.Encode()
 
 	// Create request
@@ -61,17 +97,19 @@ func (c *SearXNGClient) Search(ctx context.Context, query string, maxResults int
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	SetTraceHeader(req)
+	defer StartSpan(ctx, SpanFetch)()
 
 	// Execute request
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("search request failed: %w", err)
+		return nil, fmt.Errorf("search request failed: %w", wrapTimeout(err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("SearXNG returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("SearXNG returned %w: %s", &HTTPStatusError{Code: resp.StatusCode}, string(body))
 	}
 
 	// Parse response
@@ -0,0 +1,216 @@
+// internal/tools/searxng_pool.go
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoSearXNGInstances is returned when a pool has no instances configured
+var ErrNoSearXNGInstances = errors.New("no SearXNG instances configured")
+
+// SearXNGInstance is one search backend with its round-robin weight
+type SearXNGInstance struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"` // Relative share of queries (<= 0 treated as 1)
+}
+
+// SearXNGPoolConfig controls failover and health tracking
+type SearXNGPoolConfig struct {
+	QueryTimeout    time.Duration // Per-instance attempt timeout (0 = no extra limit)
+	UnhealthyAfter  int           // Consecutive failures before an instance is skipped
+	ReprobeInterval time.Duration // How long an unhealthy instance is skipped before retrying
+}
+
+// searxngMember tracks one instance's client and health
+type searxngMember struct {
+	url                 string
+	client              *SearXNGClient
+	consecutiveFailures int
+	healthy             bool
+	lastAttempt         time.Time
+}
+
+// SearXNGPool distributes queries across instances with weighted round-robin and
+// fails over to the next instance within a single search. Unhealthy instances are
+// re-probed with a live query once ReprobeInterval has elapsed.
+type SearXNGPool struct {
+	mu       sync.Mutex
+	members  []*searxngMember
+	schedule []int // Member indices repeated by weight
+	cursor   int
+	config   SearXNGPoolConfig
+}
+
+// NewSearXNGPool creates a pool. httpTimeout bounds each HTTP request as before;
+// QueryTimeout additionally bounds each attempt so failover happens promptly.
+func NewSearXNGPool(instances []SearXNGInstance, httpTimeout time.Duration, config SearXNGPoolConfig) *SearXNGPool {
+	if config.UnhealthyAfter <= 0 {
+		config.UnhealthyAfter = 3
+	}
+	if config.ReprobeInterval <= 0 {
+		config.ReprobeInterval = 60 * time.Second
+	}
+
+	p := &SearXNGPool{config: config}
+	for _, inst := range instances {
+		if strings.TrimSpace(inst.URL) == "" {
+			continue
+		}
+		idx := len(p.members)
+		p.members = append(p.members, &searxngMember{
+			url:     inst.URL,
+			client:  NewSearXNGClient(inst.URL, httpTimeout),
+			healthy: true,
+		})
+		weight := inst.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for i := 0; i < weight; i++ {
+			p.schedule = append(p.schedule, idx)
+		}
+	}
+	return p
+}
+
+// attemptOrder returns the members to try for the next query: healthy ones in
+// round-robin order, then unhealthy ones due for a re-probe. If nothing qualifies,
+// every instance is tried rather than failing without an attempt.
+func (p *SearXNGPool) attemptOrder(now time.Time) []*searxngMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.schedule) == 0 {
+		return nil
+	}
+
+	start := p.cursor % len(p.schedule)
+	p.cursor++
+
+	seen := make(map[int]bool, len(p.members))
+	var healthy, probes []*searxngMember
+	for i := 0; i < len(p.schedule); i++ {
+		idx := p.schedule[(start+i)%len(p.schedule)]
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		m := p.members[idx]
+		if m.healthy {
+			healthy = append(healthy, m)
+		} else if now.Sub(m.lastAttempt) >= p.config.ReprobeInterval {
+			probes = append(probes, m)
+		}
+	}
+
+	order := append(healthy, probes...)
+	if len(order) == 0 {
+		order = append(order, p.members...)
+	}
+	return order
+}
+
+// record updates an instance's health after an attempt
+func (p *SearXNGPool) record(m *searxngMember, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m.lastAttempt = time.Now()
+	if err == nil {
+		if !m.healthy {
+			log.Printf("[SearXNG] Instance %s recovered", m.url)
+		}
+		m.consecutiveFailures = 0
+		m.healthy = true
+		return
+	}
+
+	m.consecutiveFailures++
+	if m.healthy && m.consecutiveFailures >= p.config.UnhealthyAfter {
+		m.healthy = false
+		log.Printf("[SearXNG] Instance %s marked unhealthy after %d consecutive failures", m.url, m.consecutiveFailures)
+	}
+}
+
+// Search runs the query against instances in turn until one succeeds. It returns the
+// response and the URL of the instance that served it.
+func (p *SearXNGPool) Search(ctx context.Context, query string, maxResults int, filters SearchFilters) (*SearchResponse, string, error) {
+	order := p.attemptOrder(time.Now())
+	if len(order) == 0 {
+		return nil, "", ErrNoSearXNGInstances
+	}
+
+	var failures []string
+	for _, m := range order {
+		if ctx.Err() != nil {
+			break
+		}
+
+		attemptCtx := ctx
+		cancel := func() {}
+		if p.config.QueryTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, p.config.QueryTimeout)
+		}
+		response, err := m.client.Search(attemptCtx, query, maxResults, filters)
+		cancel()
+
+		// Caller cancellation says nothing about the instance's health
+		if err != nil && ctx.Err() != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", m.url, err))
+			break
+		}
+
+		p.record(m, err)
+		if err == nil {
+			return response, m.url, nil
+		}
+
+		log.Printf("[SearXNG] Instance %s failed, trying next: %v", m.url, err)
+		failures = append(failures, fmt.Sprintf("%s: %v", m.url, err))
+	}
+
+	if ctx.Err() != nil {
+		return nil, "", fmt.Errorf("search cancelled after %d attempt(s): %w", len(failures), ctx.Err())
+	}
+	return nil, "", fmt.Errorf("all SearXNG instances failed: %s", strings.Join(failures, "; "))
+}
+
+// Healthy reports the number of instances currently considered healthy
+func (p *SearXNGPool) Healthy() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	count := 0
+	for _, m := range p.members {
+		if m.healthy {
+			count++
+		}
+	}
+	return count
+}
+
+// Ping succeeds when at least one instance answers its health endpoint. Instance health
+// used for failover is left to live queries.
+func (p *SearXNGPool) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	members := append([]*searxngMember(nil), p.members...)
+	p.mu.Unlock()
+	if len(members) == 0 {
+		return ErrNoSearXNGInstances
+	}
+
+	var failures []string
+	for _, m := range members {
+		if err := m.client.Ping(ctx); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", m.url, err))
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("no SearXNG instance healthy: %s", strings.Join(failures, "; "))
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func searxngServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"query":"q","number_of_results":1,"results":[{"title":"t","url":"http://example.com","content":"c"}]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSearXNGPoolFailsOver(t *testing.T) {
+	bad := searxngServer(t, http.StatusBadGateway)
+	good := searxngServer(t, http.StatusOK)
+
+	pool := NewSearXNGPool([]SearXNGInstance{{URL: bad.URL}, {URL: good.URL}}, 5*time.Second, SearXNGPoolConfig{UnhealthyAfter: 2})
+
+	// Round-robin alternates the starting instance, so four searches hit the bad one twice
+	for i := 0; i < 4; i++ {
+		resp, used, err := pool.Search(context.Background(), "q", 5, SearchFilters{})
+		if err != nil {
+			t.Fatalf("search %d: expected failover to succeed, got %v", i, err)
+		}
+		if used != good.URL || len(resp.Results) != 1 {
+			t.Errorf("search %d: expected result from %s, got %s (%d results)", i, good.URL, used, len(resp.Results))
+		}
+	}
+
+	if pool.Healthy() != 1 {
+		t.Errorf("expected failing instance to be marked unhealthy, %d healthy", pool.Healthy())
+	}
+}
+
+func TestSearXNGPoolSkipsUnhealthyUntilReprobe(t *testing.T) {
+	bad := searxngServer(t, http.StatusInternalServerError)
+	good := searxngServer(t, http.StatusOK)
+
+	pool := NewSearXNGPool([]SearXNGInstance{{URL: bad.URL}, {URL: good.URL}}, 5*time.Second, SearXNGPoolConfig{
+		UnhealthyAfter:  1,
+		ReprobeInterval: time.Hour,
+	})
+	pool.members[0].healthy = false
+	pool.members[0].lastAttempt = time.Now()
+
+	order := pool.attemptOrder(time.Now())
+	if len(order) != 1 || order[0].url != good.URL {
+		t.Fatalf("expected only the healthy instance, got %d", len(order))
+	}
+
+	order = pool.attemptOrder(time.Now().Add(2 * time.Hour))
+	if len(order) != 2 || order[1].url != bad.URL {
+		t.Fatalf("expected unhealthy instance to be re-probed last, got %d", len(order))
+	}
+}
+
+func TestSearXNGPoolAllFail(t *testing.T) {
+	bad := searxngServer(t, http.StatusServiceUnavailable)
+	pool := NewSearXNGPool([]SearXNGInstance{{URL: bad.URL, Weight: 2}}, 5*time.Second, SearXNGPoolConfig{})
+
+	if _, _, err := pool.Search(context.Background(), "q", 5, SearchFilters{}); err == nil {
+		t.Fatal("expected error when every instance fails")
+	}
+	if _, _, err := NewSearXNGPool(nil, time.Second, SearXNGPoolConfig{}).Search(context.Background(), "q", 5, SearchFilters{}); err != ErrNoSearXNGInstances {
+		t.Errorf("expected ErrNoSearXNGInstances, got %v", err)
+	}
+}
+
+func TestSearXNGToolReturnsStructuredResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"query":"q","number_of_results":2,"results":[
+			{"title":"First","url":"http://a.example","content":"alpha","engine":"duckduckgo"},
+			{"title":"Second","url":"http://b.example","content":"beta","engine":"brave"}]}`))
+	}))
+	defer srv.Close()
+
+	result, err := NewSearXNGTool(srv.URL, ToolConfig{MaxResultsIdle: 5}).Execute(context.Background(), map[string]interface{}{"query": "q"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	results := DecodeSearchResults(result.Metadata[MetaSearchResults])
+	if len(results) != 2 {
+		t.Fatalf("expected 2 structured results, got %v", result.Metadata[MetaSearchResults])
+	}
+	want := StructuredSearchResult{Title: "Second", URL: "http://b.example", Snippet: "beta", Engine: "brave", Rank: 2}
+	if results[1] != want {
+		t.Errorf("expected %+v, got %+v", want, results[1])
+	}
+
+	// Persisted action metadata comes back as generic JSON values
+	generic := []interface{}{map[string]interface{}{"title": "First", "url": "http://a.example", "rank": float64(1)}}
+	if decoded := DecodeSearchResults(generic); len(decoded) != 1 || decoded[0].URL != "http://a.example" || decoded[0].Rank != 1 {
+		t.Errorf("expected generic metadata to decode, got %+v", decoded)
+	}
+}
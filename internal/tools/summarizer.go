@@ -0,0 +1,192 @@
+// internal/tools/summarizer.go
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-llama/internal/config"
+	"go-llama/internal/utils"
+)
+
+// SummarizerLLM is the part of the LLM client the summarizer needs
+type SummarizerLLM interface {
+	Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error)
+}
+
+// SummarizerConfig selects the model and fetch behaviour for a Summarizer
+type SummarizerConfig struct {
+	LLMURL        string
+	Model         string
+	FetchTimeout  time.Duration
+	UserAgent     string
+	MaxPageSizeMB int
+	MaxInputChars int                   // Page text sent to the model; longer pages are truncated
+	Sampling      config.SamplingConfig // Overrides; temperature defaults to 0.2
+}
+
+// SummarizeOptions shape a single summary
+type SummarizeOptions struct {
+	MaxWords int    // Upper bound on summary length; 0 uses the default
+	Focus    string // Goal or question the summary should concentrate on
+	Language string // Output language; empty keeps the page's language
+}
+
+// Summary is the structured result of summarizing a page
+type Summary struct {
+	URL           string        `json:"url"`
+	Text          string        `json:"text"`
+	SourceTitle   string        `json:"source_title"`
+	FetchDuration time.Duration `json:"fetch_duration"`
+	TokensUsed    int           `json:"tokens_used"`
+}
+
+const (
+	defaultSummaryWords        = 150
+	defaultSummarizerInputSize = 12000
+)
+
+// Summarizer fetches a page, extracts its text and condenses it with an LLM
+type Summarizer struct {
+	llm          SummarizerLLM
+	parser       *WebParserClient
+	config       SummarizerConfig
+	domainPolicy *DomainPolicy // Optional; nil leaves fetch targets unchecked
+}
+
+// NewSummarizer creates a summarizer. Zero config values fall back to the web parser defaults.
+func NewSummarizer(llmClient SummarizerLLM, cfg SummarizerConfig) *Summarizer {
+	if cfg.FetchTimeout == 0 {
+		cfg.FetchTimeout = 30 * time.Second
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "GrowerAI-Summarizer/1.0"
+	}
+	if cfg.MaxPageSizeMB == 0 {
+		cfg.MaxPageSizeMB = 5
+	}
+	if cfg.MaxInputChars == 0 {
+		cfg.MaxInputChars = defaultSummarizerInputSize
+	}
+	return &Summarizer{
+		llm:    llmClient,
+		parser: NewWebParserClient(cfg.FetchTimeout, cfg.UserAgent, cfg.MaxPageSizeMB),
+		config: cfg,
+	}
+}
+
+// applySampling writes the configured sampling overrides into a request
+func (s *Summarizer) applySampling(payload map[string]interface{}) {
+	sampling := s.config.Sampling
+	if sampling.Temperature != nil {
+		payload["temperature"] = *sampling.Temperature
+	}
+	if sampling.TopP > 0 {
+		payload["top_p"] = sampling.TopP
+	}
+	if sampling.MaxTokens > 0 {
+		payload["max_tokens"] = sampling.MaxTokens
+	}
+	if sampling.RepeatPenalty > 0 {
+		payload["repeat_penalty"] = sampling.RepeatPenalty
+	}
+}
+
+// SetDomainPolicy applies the web parser's domain policy to summarizer fetches, which
+// stay on the shared connection pool
+func (s *Summarizer) SetDomainPolicy(policy *DomainPolicy) {
+	s.domainPolicy = policy
+	s.parser.httpClient.Transport = policy.Transport()
+}
+
+// Summarize fetches url and returns an LLM summary shaped by opts
+func (s *Summarizer) Summarize(ctx context.Context, url string, opts SummarizeOptions) (Summary, error) {
+	summary := Summary{URL: url}
+	if s.llm == nil {
+		return summary, fmt.Errorf("summarizer has no LLM client")
+	}
+	if s.domainPolicy != nil {
+		if err := s.domainPolicy.CheckURL(url); err != nil {
+			return summary, err
+		}
+	}
+
+	start := time.Now()
+	page, err := s.parser.FetchAndParse(ctx, url)
+	summary.FetchDuration = time.Since(start)
+	if err != nil {
+		return summary, err
+	}
+	summary.SourceTitle = page.Title
+	if strings.TrimSpace(page.CleanText) == "" {
+		return summary, fmt.Errorf("no text content found at %s", url)
+	}
+
+	text := page.CleanText
+	if len(text) > s.config.MaxInputChars {
+		text = utils.Prefix(text, s.config.MaxInputChars)
+	}
+
+	payload := map[string]interface{}{
+		"model": s.config.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": "You summarize web pages accurately and concisely. Output only the summary."},
+			{"role": "user", "content": summaryPrompt(page.Title, text, opts)},
+		},
+		"temperature": 0.2,
+		"stream":      false,
+	}
+	s.applySampling(payload)
+	endSummarize := StartSpan(ctx, SpanLLMSummarize)
+	body, err := s.llm.Call(ctx, config.GetChatURL(s.config.LLMURL), payload)
+	endSummarize()
+	if err != nil {
+		return summary, fmt.Errorf("LLM call failed: %w", err)
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return summary, fmt.Errorf("failed to decode LLM response: %w", err)
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return summary, fmt.Errorf("LLM returned an empty summary")
+	}
+
+	summary.Text = strings.TrimSpace(resp.Choices[0].Message.Content)
+	summary.TokensUsed = resp.Usage.TotalTokens
+	if summary.TokensUsed == 0 {
+		summary.TokensUsed = s.parser.EstimateTokens(text) + s.parser.EstimateTokens(summary.Text)
+	}
+	return summary, nil
+}
+
+// summaryPrompt builds the user prompt for one page
+func summaryPrompt(title, text string, opts SummarizeOptions) string {
+	maxWords := opts.MaxWords
+	if maxWords <= 0 {
+		maxWords = defaultSummaryWords
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Summarize the following page in at most %d words.\n", maxWords))
+	if opts.Focus != "" {
+		sb.WriteString(fmt.Sprintf("Concentrate on information relevant to: %s\n", opts.Focus))
+	}
+	if opts.Language != "" {
+		sb.WriteString(fmt.Sprintf("Write the summary in %s.\n", opts.Language))
+	}
+	sb.WriteString(fmt.Sprintf("\nTitle: %s\n\n%s", title, text))
+	return sb.String()
+}
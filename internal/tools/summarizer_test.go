@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-llama/internal/config"
+)
+
+// fakeSummaryLLM records the last payload and answers with a fixed chat completion
+type fakeSummaryLLM struct {
+	url     string
+	payload map[string]interface{}
+	reply   string
+	tokens  int
+}
+
+func (f *fakeSummaryLLM) Call(ctx context.Context, url string, payload map[string]interface{}) ([]byte, error) {
+	f.url = url
+	f.payload = payload
+	resp := map[string]interface{}{
+		"choices": []map[string]interface{}{
+			{"message": map[string]string{"content": f.reply}},
+		},
+	}
+	if f.tokens > 0 {
+		resp["usage"] = map[string]int{"total_tokens": f.tokens}
+	}
+	return json.Marshal(resp)
+}
+
+func (f *fakeSummaryLLM) userPrompt() string {
+	messages := f.payload["messages"].([]map[string]string)
+	return messages[len(messages)-1]["content"]
+}
+
+const summarizerPage = `<html><head><title>Prime Ministers</title></head>
+<body><article><h1>Prime Ministers</h1><p>Robert Walpole is regarded as the first prime minister.</p></article></body></html>`
+
+func newSummarizerServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(summarizerPage))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSummarizerSummarize(t *testing.T) {
+	srv := newSummarizerServer(t)
+	llm := &fakeSummaryLLM{reply: "  Walpole was the first prime minister.  ", tokens: 321}
+	s := NewSummarizer(llm, SummarizerConfig{LLMURL: "http://llm:8080", Model: "small"})
+
+	summary, err := s.Summarize(context.Background(), srv.URL+"/page", SummarizeOptions{
+		MaxWords: 40,
+		Focus:    "who was first",
+		Language: "French",
+	})
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+
+	if summary.Text != "Walpole was the first prime minister." {
+		t.Errorf("Text = %q", summary.Text)
+	}
+	if summary.SourceTitle != "Prime Ministers" {
+		t.Errorf("SourceTitle = %q", summary.SourceTitle)
+	}
+	if summary.TokensUsed != 321 {
+		t.Errorf("TokensUsed = %d, want usage from the response", summary.TokensUsed)
+	}
+	if summary.FetchDuration <= 0 {
+		t.Error("FetchDuration not recorded")
+	}
+
+	if llm.payload["model"] != "small" {
+		t.Errorf("model = %v", llm.payload["model"])
+	}
+	if llm.payload["temperature"] != 0.2 {
+		t.Errorf("temperature = %v, want the 0.2 default", llm.payload["temperature"])
+	}
+	if _, ok := llm.payload["max_tokens"]; ok {
+		t.Error("max_tokens sent without a sampling override")
+	}
+	if !strings.HasSuffix(llm.url, "/v1/chat/completions") {
+		t.Errorf("LLM URL = %q", llm.url)
+	}
+	prompt := llm.userPrompt()
+	for _, want := range []string{"at most 40 words", "who was first", "in French", "Robert Walpole"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestSummarizerSamplingOverrides(t *testing.T) {
+	srv := newSummarizerServer(t)
+	llm := &fakeSummaryLLM{reply: "Summary."}
+	temperature := 0.0
+	s := NewSummarizer(llm, SummarizerConfig{Sampling: config.SamplingConfig{Temperature: &temperature, MaxTokens: 256, RepeatPenalty: 1.1}})
+
+	if _, err := s.Summarize(context.Background(), srv.URL+"/page", SummarizeOptions{}); err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if llm.payload["temperature"] != 0.0 || llm.payload["max_tokens"] != 256 || llm.payload["repeat_penalty"] != 1.1 {
+		t.Errorf("sampling overrides not applied: %v", llm.payload)
+	}
+	if _, ok := llm.payload["top_p"]; ok {
+		t.Error("top_p sent without an override")
+	}
+}
+
+func TestSummarizerEstimatesTokensWithoutUsage(t *testing.T) {
+	srv := newSummarizerServer(t)
+	s := NewSummarizer(&fakeSummaryLLM{reply: "Short summary."}, SummarizerConfig{})
+
+	summary, err := s.Summarize(context.Background(), srv.URL, SummarizeOptions{})
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if summary.TokensUsed == 0 {
+		t.Error("expected estimated token count when the response has no usage")
+	}
+}
+
+func TestSummarizerErrors(t *testing.T) {
+	srv := newSummarizerServer(t)
+
+	s := NewSummarizer(&fakeSummaryLLM{reply: "unused"}, SummarizerConfig{})
+	if _, err := s.Summarize(context.Background(), srv.URL+"/missing", SummarizeOptions{}); err == nil {
+		t.Error("expected fetch error for 404")
+	}
+
+	s = NewSummarizer(&fakeSummaryLLM{reply: "   "}, SummarizerConfig{})
+	if _, err := s.Summarize(context.Background(), srv.URL, SummarizeOptions{}); err == nil {
+		t.Error("expected error for empty LLM reply")
+	}
+
+	s = NewSummarizer(nil, SummarizerConfig{})
+	if _, err := s.Summarize(context.Background(), srv.URL, SummarizeOptions{}); err == nil {
+		t.Error("expected error without an LLM client")
+	}
+
+	policy, _ := NewDomainPolicy(DomainPolicyConfig{})
+	s = NewSummarizer(&fakeSummaryLLM{reply: "unused"}, SummarizerConfig{})
+	s.SetDomainPolicy(policy)
+	if _, err := s.Summarize(context.Background(), srv.URL, SummarizeOptions{}); err == nil {
+		t.Error("expected domain policy to refuse a loopback target")
+	}
+}
@@ -0,0 +1,260 @@
+// internal/tools/trace.go
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-llama/internal/httpclient"
+)
+
+// TraceHeader carries an action's trace ID on the HTTP requests made for it, so the
+// web servers' and model server's logs can be matched to the action
+const TraceHeader = "X-Trace-Id"
+
+// Keys under which a trace is recorded in ToolResult and action metadata
+const (
+	MetaTraceID    = "trace_id"
+	MetaTraceSpans = "trace_spans"
+)
+
+// Span names recorded by the tools and the LLM queue
+const (
+	SpanFetch        = "fetch"
+	SpanExtraction   = "extraction"
+	SpanLLMSelect    = "llm_select"
+	SpanLLMSummarize = "llm_summarize"
+	SpanLLMTranslate = "llm_translate"
+	SpanLLMQueueWait = "llm_queue_wait"
+	SpanLLMRequest   = "llm_request"
+	SpanTotal        = "total"  // A tool run through ContextualRegistry, retries included
+	SpanAction       = "action" // A dialogue action, tool run and evaluation included
+)
+
+// Span is one timed step of a traced action
+type Span struct {
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Trace collects the spans of one action. It is safe for concurrent use, since LLM
+// queue spans are recorded from the dispatcher's goroutines.
+type Trace struct {
+	ID      string
+	started time.Time
+
+	mu    sync.Mutex
+	spans []Span
+}
+
+type traceKey struct{}
+
+// NewTrace starts a trace with a random 128-bit ID, hex-encoded as OTLP expects
+func NewTrace() *Trace {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		// Fall back to the clock; the ID only has to be unique enough to grep for
+		return &Trace{ID: fmt.Sprintf("%032x", time.Now().UnixNano()), started: time.Now()}
+	}
+	return &Trace{ID: hex.EncodeToString(id), started: time.Now()}
+}
+
+// WithTrace returns a context carrying trace
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFromContext returns the context's trace, or nil
+func TraceFromContext(ctx context.Context) *Trace {
+	if ctx == nil {
+		return nil
+	}
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// TraceID returns the ID of the context's trace, or ""
+func TraceID(ctx context.Context) string {
+	if trace := TraceFromContext(ctx); trace != nil {
+		return trace.ID
+	}
+	return ""
+}
+
+// StartSpan times a step of the context's trace until the returned function is called.
+// Without a trace it does nothing.
+func StartSpan(ctx context.Context, name string) func() {
+	trace := TraceFromContext(ctx)
+	if trace == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { trace.Record(name, start, time.Since(start)) }
+}
+
+// Record adds a span that has already finished
+func (t *Trace) Record(name string, start time.Time, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, Span{Name: name, Start: start, Duration: duration})
+}
+
+// Spans returns the recorded spans in the order they finished
+func (t *Trace) Spans() []Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Span(nil), t.spans...)
+}
+
+// Metadata returns the spans for ToolResult or action metadata, each with its offset
+// from the start of the trace and its duration in milliseconds
+func (t *Trace) Metadata() []map[string]interface{} {
+	spans := t.Spans()
+	out := make([]map[string]interface{}, len(spans))
+	for i, span := range spans {
+		out[i] = map[string]interface{}{
+			"name":        span.Name,
+			"offset_ms":   span.Start.Sub(t.started).Milliseconds(),
+			"duration_ms": span.Duration.Milliseconds(),
+		}
+	}
+	return out
+}
+
+// SetTraceHeader puts the trace ID of req's context on req
+func SetTraceHeader(req *http.Request) {
+	if id := TraceID(req.Context()); id != "" {
+		req.Header.Set(TraceHeader, id)
+	}
+}
+
+// recordTrace puts the context's trace on a tool result
+func recordTrace(ctx context.Context, result *ToolResult) {
+	trace := TraceFromContext(ctx)
+	if trace == nil || result == nil {
+		return
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata[MetaTraceID] = trace.ID
+	result.Metadata[MetaTraceSpans] = trace.Metadata()
+}
+
+// OTLPExporter sends finished traces to an OpenTelemetry collector as OTLP/HTTP JSON.
+// It is optional: traces are recorded in metadata whether or not one is configured.
+type OTLPExporter struct {
+	endpoint    string // Collector base URL; traces go to <endpoint>/v1/traces
+	serviceName string
+	client      *http.Client
+}
+
+// NewOTLPExporter creates an exporter for the collector at endpoint
+func NewOTLPExporter(endpoint, serviceName string, timeout time.Duration) *OTLPExporter {
+	if serviceName == "" {
+		serviceName = "go-llama"
+	}
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	return &OTLPExporter{
+		endpoint:    strings.TrimRight(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      httpclient.New(timeout),
+	}
+}
+
+// Export sends the trace's spans as children of a root span named rootName covering
+// the whole trace
+func (x *OTLPExporter) Export(ctx context.Context, trace *Trace, rootName string, attributes map[string]string) error {
+	body, err := json.Marshal(x.payload(trace, rootName, attributes, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", x.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("OTLP export failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ExportAsync exports in the background, logging a failure; an action never waits for
+// the collector
+func (x *OTLPExporter) ExportAsync(trace *Trace, rootName string, attributes map[string]string) {
+	go func() {
+		if err := x.Export(context.Background(), trace, rootName, attributes); err != nil {
+			log.Printf("[Trace] WARNING: Trace %s not exported: %v", trace.ID, err)
+		}
+	}()
+}
+
+// payload builds the OTLP/HTTP JSON request for a trace that ended at end
+func (x *OTLPExporter) payload(trace *Trace, rootName string, attributes map[string]string, end time.Time) map[string]interface{} {
+	rootID := spanID()
+	rootAttributes := []map[string]interface{}{}
+	for key, value := range attributes {
+		rootAttributes = append(rootAttributes, otlpAttribute(key, value))
+	}
+	spans := []map[string]interface{}{{
+		"traceId":           trace.ID,
+		"spanId":            rootID,
+		"name":              rootName,
+		"kind":              1, // SPAN_KIND_INTERNAL
+		"startTimeUnixNano": strconv.FormatInt(trace.started.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(end.UnixNano(), 10),
+		"attributes":        rootAttributes,
+	}}
+	for _, span := range trace.Spans() {
+		spans = append(spans, map[string]interface{}{
+			"traceId":           trace.ID,
+			"spanId":            spanID(),
+			"parentSpanId":      rootID,
+			"name":              span.Name,
+			"kind":              1,
+			"startTimeUnixNano": strconv.FormatInt(span.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.Start.Add(span.Duration).UnixNano(), 10),
+		})
+	}
+	return map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": map[string]interface{}{
+				"attributes": []map[string]interface{}{otlpAttribute("service.name", x.serviceName)},
+			},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": "go-llama/tools"},
+				"spans": spans,
+			}},
+		}},
+	}
+}
+
+func otlpAttribute(key, value string) map[string]interface{} {
+	return map[string]interface{}{"key": key, "value": map[string]interface{}{"stringValue": value}}
+}
+
+// spanID returns a random 64-bit span ID, hex-encoded
+func spanID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraceHeaderReachesToolRequests(t *testing.T) {
+	var seen string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get(TraceHeader)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(clutteredPage))
+	}))
+	defer srv.Close()
+
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+	registry := NewRegistry()
+	if err := registry.Register(tool); err != nil {
+		t.Fatal(err)
+	}
+	trace := NewTrace()
+	ctx := WithTrace(context.Background(), trace)
+
+	result, err := NewContextualRegistry(registry, nil).ExecuteIdle(ctx, tool.Name(), map[string]interface{}{"url": srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.ID) != 32 || seen != trace.ID {
+		t.Fatalf("expected the page request to carry trace %s, got %q", trace.ID, seen)
+	}
+	if result.Metadata[MetaTraceID] != trace.ID {
+		t.Errorf("expected the trace ID on the result, got %v", result.Metadata[MetaTraceID])
+	}
+	names := map[string]bool{}
+	for _, span := range result.Metadata[MetaTraceSpans].([]map[string]interface{}) {
+		names[span["name"].(string)] = true
+	}
+	if !names[SpanFetch] || !names[SpanExtraction] || !names[SpanTotal] {
+		t.Errorf("expected fetch, extraction and total spans, got %v", names)
+	}
+
+	// Without a trace no header is sent and nothing is recorded
+	seen = "unset"
+	untraced, err := NewContextualRegistry(registry, nil).ExecuteIdle(context.Background(), tool.Name(), map[string]interface{}{"url": srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := untraced.Metadata[MetaTraceID]; seen != "" || ok {
+		t.Errorf("expected no trace without one in the context, got header %q", seen)
+	}
+}
+
+func TestOTLPPayload(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer srv.Close()
+
+	trace := NewTrace()
+	StartSpan(WithTrace(context.Background(), trace), SpanFetch)()
+	if err := NewOTLPExporter(srv.URL+"/", "", 0).Export(context.Background(), trace, "action web_parse_unified", nil); err != nil {
+		t.Fatal(err)
+	}
+	scope := body["resourceSpans"].([]interface{})[0].(map[string]interface{})["scopeSpans"].([]interface{})[0].(map[string]interface{})
+	spans := scope["spans"].([]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("expected the root and fetch spans, got %d", len(spans))
+	}
+	fetch := spans[1].(map[string]interface{})
+	if fetch["traceId"] != trace.ID || fetch["parentSpanId"] != spans[0].(map[string]interface{})["spanId"] || fetch["name"] != SpanFetch {
+		t.Errorf("expected the fetch span under the root, got %v", fetch)
+	}
+}
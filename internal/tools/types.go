@@ -3,6 +3,8 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -21,6 +23,58 @@ type Tool interface {
 	RequiresAuth() bool
 }
 
+// Describer is implemented by tools that report more about themselves than a name and
+// description. Tools without it are listed with just those and allowed in idle mode.
+type Describer interface {
+	Info() ToolInfo
+}
+
+// ToolInfo describes a registered tool for listings and the LLM-facing tool list
+type ToolInfo struct {
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	Parameters       []ToolParameter `json:"parameters,omitempty"`
+	IdleAllowed      bool            `json:"idle_allowed"`                // May run during idle exploration
+	ExpectedDuration time.Duration   `json:"expected_duration,omitempty"` // Typical run time
+	CostHint         string          `json:"cost_hint,omitempty"`         // One of the Cost* constants
+	Order            int             `json:"order"`                       // Listing position; ties sort by name
+}
+
+// ToolParameter describes one parameter a tool reads from its params
+type ToolParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // Go-style type name, e.g. "string", "int", "[]int"
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// Cost hints in ToolInfo.CostHint
+const (
+	CostLow    = "low"    // Local work or a cached lookup
+	CostMedium = "medium" // Network requests
+	CostHigh   = "high"   // Network requests plus LLM calls
+)
+
+// ParameterSummary renders the parameters as "name (type, required), ..."
+func (i ToolInfo) ParameterSummary() string {
+	parts := make([]string, len(i.Parameters))
+	for n, p := range i.Parameters {
+		if p.Required {
+			parts[n] = fmt.Sprintf("%s (%s, required)", p.Name, p.Type)
+		} else {
+			parts[n] = fmt.Sprintf("%s (%s)", p.Name, p.Type)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ExecuteFunc runs a tool by name; Middleware wraps it
+type ExecuteFunc func(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error)
+
+// Middleware wraps every tool execution in a registry, for concerns such as metrics,
+// circuit breaking or rate limiting that should not need per-tool code
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
 // ToolResult contains the outcome of a tool execution
 type ToolResult struct {
 	Success    bool                   `json:"success"`
@@ -29,8 +83,21 @@ type ToolResult struct {
 	Duration   time.Duration          `json:"duration"`
 	TokensUsed int                    `json:"tokens_used,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	FailureKind string                `json:"failure_kind,omitempty"` // Set on failures callers can act on (see FailureKind* constants)
 }
 
+// Failure kinds reported in ToolResult.FailureKind
+const (
+	FailureKindUnsupportedContent = "unsupported_content" // Resource type the tool cannot extract text from
+	FailureKindDomainBlocked      = "domain_blocked"      // Target refused by the domain policy
+	FailureKindRobotsBlocked      = "robots_blocked"      // Target disallowed by the site's robots.txt
+	FailureKindPageTooLarge       = "page_too_large"      // Body over the tool's size limit
+	FailureKindHTTPStatus         = "http_status"         // Server answered with an unexpected status
+	FailureKindTimeout            = "timeout"             // Request did not finish in time
+	FailureKindWrongLanguage      = "wrong_language"      // Page is not in the target language and the policy skips it
+	FailureKindOutputTooLarge     = "output_too_large"    // Output over the registry's hard ceiling
+)
+
 // ToolUsage tracks tool execution for learning
 type ToolUsage struct {
 	ToolName  string                 `json:"tool_name"`
@@ -0,0 +1,191 @@
+// internal/tools/webparser_chunks.go
+package tools
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-shiori/go-readability"
+)
+
+// Chunking defaults for selective parsing
+const (
+	DefaultChunkTokens        = 500
+	DefaultChunkOverlapTokens = 50
+)
+
+// PageChunk is one token-estimated slice of extracted page text, reported in selective
+// parse metadata. Consecutive chunks overlap, so a sentence cut at one boundary appears
+// whole in one of the two chunks.
+type PageChunk struct {
+	Index   int    `json:"index"`
+	Start   int    `json:"start"` // Byte offsets into the extracted text
+	End     int    `json:"end"`
+	Tokens  int    `json:"tokens"`
+	Heading string `json:"heading,omitempty"` // Nearest heading at or before Start
+}
+
+// pageHeading is a section heading and where it starts in the extracted text
+type pageHeading struct {
+	Text   string
+	Offset int
+}
+
+// textWord is a whitespace-delimited word with its byte span and estimated tokens
+type textWord struct {
+	start, end int
+	tokens     int
+}
+
+// wordTokens estimates a word's tokens: about four characters per token, at least one
+func wordTokens(word string) int {
+	if n := (utf8.RuneCountInString(word) + 3) / 4; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// splitWords returns the words of text with their spans and token estimates
+func splitWords(text string) []textWord {
+	words := []textWord{}
+	start := -1
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, textWord{start: start, end: i, tokens: wordTokens(text[start:i])})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, textWord{start: start, end: len(text), tokens: wordTokens(text[start:])})
+	}
+	return words
+}
+
+// chunkText splits text into chunks of about sizeTokens estimated tokens, each starting
+// overlapTokens before the previous one ended. Chunks end at a paragraph, line or
+// sentence break in their second half when there is one, otherwise between words.
+func chunkText(text string, sizeTokens, overlapTokens int) []PageChunk {
+	if sizeTokens <= 0 {
+		sizeTokens = DefaultChunkTokens
+	}
+	if overlapTokens < 0 || overlapTokens >= sizeTokens {
+		overlapTokens = 0
+	}
+	words := splitWords(text)
+	if len(words) == 0 {
+		return []PageChunk{}
+	}
+
+	chunks := []PageChunk{}
+	for first := 0; first < len(words); {
+		last, tokens := first, words[first].tokens
+		for last+1 < len(words) && tokens+words[last+1].tokens <= sizeTokens {
+			last++
+			tokens += words[last].tokens
+		}
+		if last+1 < len(words) {
+			last = chunkBreak(text, words, first, last)
+			tokens = 0
+			for i := first; i <= last; i++ {
+				tokens += words[i].tokens
+			}
+		}
+
+		start, end := words[first].start, len(text)
+		if first == 0 {
+			start = 0
+		}
+		if last+1 < len(words) {
+			end = words[last+1].start
+		}
+		chunks = append(chunks, PageChunk{Index: len(chunks), Start: start, End: end, Tokens: tokens})
+		if last+1 >= len(words) {
+			break
+		}
+
+		// Step back over the overlap, always moving forward by at least one word
+		next, overlap := last+1, 0
+		for next-1 > first && overlap+words[next-1].tokens <= overlapTokens {
+			next--
+			overlap += words[next].tokens
+		}
+		first = next
+	}
+	return chunks
+}
+
+// chunkBreak picks the last word of a chunk spanning words[first..last], preferring a
+// paragraph break, then a line break, then a sentence end in the second half
+func chunkBreak(text string, words []textWord, first, last int) int {
+	half := first + (last-first)/2
+	for _, isBreak := range []func(word, gap string) bool{
+		func(_, gap string) bool { return strings.Contains(gap, "\n\n") },
+		func(_, gap string) bool { return strings.Contains(gap, "\n") },
+		func(word, _ string) bool { return strings.ContainsAny(word[len(word)-1:], ".!?") },
+	} {
+		for i := last; i > half; i-- {
+			if isBreak(text[words[i].start:words[i].end], text[words[i].end:words[i+1].start]) {
+				return i
+			}
+		}
+	}
+	return last
+}
+
+// pageHeadings locates the article's section headings in its extracted text. HTML
+// headings come from the readability markup; plain text and markdown use "#" lines.
+func pageHeadings(article *readability.Article) []pageHeading {
+	text := article.TextContent
+	headings := []pageHeading{}
+
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(article.Content)); err == nil {
+		offset := 0
+		doc.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+			heading := strings.Join(strings.Fields(s.Text()), " ")
+			if heading == "" {
+				return
+			}
+			if idx := strings.Index(text[offset:], heading); idx >= 0 {
+				headings = append(headings, pageHeading{Text: heading, Offset: offset + idx})
+				offset += idx + len(heading)
+			}
+		})
+	}
+	if len(headings) > 0 {
+		return headings
+	}
+
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "#") {
+			if heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#")); heading != "" {
+				headings = append(headings, pageHeading{Text: heading, Offset: offset})
+			}
+		}
+		offset += len(line)
+	}
+	return headings
+}
+
+// labelChunks sets each chunk's heading to the last heading at or before its start,
+// or to the first heading inside it when none precedes it
+func labelChunks(chunks []PageChunk, headings []pageHeading) {
+	for i := range chunks {
+		for _, h := range headings {
+			if h.Offset <= chunks[i].Start {
+				chunks[i].Heading = h.Text
+			} else {
+				if chunks[i].Heading == "" && h.Offset < chunks[i].End {
+					chunks[i].Heading = h.Text
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/go-readability"
+)
+
+func numberedSentences(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "Sentence number %d describes the soil in some detail. ", i)
+		if i%5 == 4 {
+			b.WriteString("\n\n")
+		}
+	}
+	return b.String()
+}
+
+func TestChunkTextRespectsSizeAndOverlap(t *testing.T) {
+	text := numberedSentences(200)
+	chunks := chunkText(text, 100, 20)
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	if chunks[0].Start != 0 || chunks[len(chunks)-1].End != len(text) {
+		t.Errorf("expected the chunks to cover the whole text, got %+v ... %+v", chunks[0], chunks[len(chunks)-1])
+	}
+	for i, c := range chunks {
+		if c.Index != i || c.Tokens > 100 {
+			t.Errorf("chunk %d: unexpected index or size %+v", i, c)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := chunks[i-1]
+		if c.Start >= prev.End || c.Start <= prev.Start {
+			t.Errorf("chunk %d should start inside the previous chunk: %+v after %+v", i, c, prev)
+		}
+		if overlap := splitWords(text[c.Start:prev.End]); tokenSum(overlap) > 20 {
+			t.Errorf("chunk %d overlaps by %d tokens, want at most 20", i, tokenSum(overlap))
+		}
+	}
+	for _, c := range chunks[:len(chunks)-1] {
+		if end := strings.TrimSpace(text[c.Start:c.End]); !strings.HasSuffix(end, ".") {
+			t.Errorf("expected chunk %d to end at a sentence, ends %q", c.Index, end[len(end)-20:])
+		}
+	}
+
+	none := chunkText(text, 100, -1)
+	for i := 1; i < len(none); i++ {
+		if none[i].Start != none[i-1].End {
+			t.Errorf("expected a negative overlap to produce adjacent chunks, got %+v after %+v", none[i], none[i-1])
+		}
+	}
+}
+
+func tokenSum(words []textWord) int {
+	total := 0
+	for _, w := range words {
+		total += w.tokens
+	}
+	return total
+}
+
+func TestLabelChunksWithNearestHeading(t *testing.T) {
+	text := "# Intro\n" + numberedSentences(30) + "\n# Installation\n" + numberedSentences(30)
+	article := &readability.Article{TextContent: text}
+	chunks := chunkText(text, 120, 0)
+	labelChunks(chunks, pageHeadings(article))
+
+	install := strings.Index(text, "# Installation")
+	for _, c := range chunks {
+		want := "Intro"
+		if c.Start >= install {
+			want = "Installation"
+		}
+		if c.Heading != want {
+			t.Errorf("chunk %d at %d: heading %q, want %q", c.Index, c.Start, c.Heading, want)
+		}
+	}
+
+	selected := resolveChunkSelection([]interface{}{"installation", 0.0, 0.0, 99.0}, chunks)
+	if len(selected) < 2 || chunks[selected[0]].Heading != "Installation" || !containsInt(selected, 0) {
+		t.Errorf("expected section and index selections resolved once each, got %v", selected)
+	}
+}
+
+func TestSelectiveParseReportsChunkBoundaries(t *testing.T) {
+	page := "<html><head><title>Guide</title></head><body><article><h1>Guide</h1><h2>Overview</h2><p>" +
+		numberedSentences(40) + "</p><h2>Watering</h2><p>" + numberedSentences(40) + "</p></article></body></html>"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	llm := &fakeSummaryLLM{reply: `["Watering"]`}
+	tool := NewWebParserUnifiedTool("test", "http://llm", "small", 1, ToolConfig{}, llm, 200)
+	tool.SetChunking(150, 30)
+
+	result := parsePage(t, tool, srv.URL, map[string]interface{}{"goal": "how often to water"})
+	chunks, ok := result.Metadata["chunks"].([]PageChunk)
+	if !ok || len(chunks) < 2 {
+		t.Fatalf("expected chunk boundaries in metadata, got %v", result.Metadata["chunks"])
+	}
+	selected := result.Metadata["selected_chunks"].([]int)
+	if len(selected) == 0 {
+		t.Fatal("expected the Watering section selected")
+	}
+	for _, idx := range selected {
+		if chunks[idx].Heading != "Watering" {
+			t.Errorf("selected chunk %d is under %q", idx, chunks[idx].Heading)
+		}
+	}
+	if !strings.Contains(llm.userPrompt(), `"section":"Overview"`) {
+		t.Error("expected the selection prompt to name sections")
+	}
+	if !strings.Contains(result.Output, "(Section: Watering)") {
+		t.Error("expected selected chunks labelled with their section")
+	}
+
+	// Indexes stored with an older chunking still resolve when they exist
+	stored := parsePage(t, tool, srv.URL, map[string]interface{}{"chunks": []interface{}{1.0, 500.0}})
+	if got := stored.Metadata["selected_chunks"].([]int); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected only the stored index that still exists, got %v", got)
+	}
+}
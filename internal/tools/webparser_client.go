@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+
+	"go-llama/internal/httpclient"
+	"go-llama/internal/utils"
 )
 
 // WebParserClient handles HTTP fetching and HTML parsing
@@ -49,16 +52,16 @@ type PageMetadata struct {
 
 // NewWebParserClient creates a new web parser client
 func NewWebParserClient(timeout time.Duration, userAgent string, maxSizeMB int) *WebParserClient {
+	httpClient := httpclient.New(timeout)
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("too many redirects")
+		}
+		return nil
+	}
+
 	return &WebParserClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return fmt.Errorf("too many redirects")
-				}
-				return nil
-			},
-		},
+		httpClient: httpClient,
 		userAgent: userAgent,
 		maxSizeMB: maxSizeMB,
 	}
@@ -67,13 +70,17 @@ func NewWebParserClient(timeout time.Duration, userAgent string, maxSizeMB int)
 // FetchAndParse fetches a URL and parses it into clean content
 func (c *WebParserClient) FetchAndParse(ctx context.Context, url string) (*ParsedContent, error) {
 	// Fetch HTML
+	endFetch := StartSpan(ctx, SpanFetch)
 	html, err := c.fetchHTML(ctx, url)
+	endFetch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch URL: %w", err)
 	}
 
 	// Parse HTML into clean content
+	endExtraction := StartSpan(ctx, SpanExtraction)
 	content, err := c.parseHTML(url, html)
+	endExtraction()
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -96,6 +103,7 @@ func (c *WebParserClient) fetchHTML(ctx context.Context, url string) (string, er
     req.Header.Set("DNT", "1")
     req.Header.Set("Connection", "keep-alive")
     req.Header.Set("Upgrade-Insecure-Requests", "1")
+    SetTraceHeader(req)
     req.Header.Set("Sec-Fetch-Dest", "document")
     req.Header.Set("Sec-Fetch-Mode", "navigate")
     req.Header.Set("Sec-Fetch-Site", "none")
@@ -103,12 +111,12 @@ func (c *WebParserClient) fetchHTML(ctx context.Context, url string) (string, er
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch: %w", err)
+		return "", fmt.Errorf("failed to fetch: %w", wrapTimeout(err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return "", fmt.Errorf("%w: %s", &HTTPStatusError{Code: resp.StatusCode}, resp.Status)
 	}
 
 	// Check content type
@@ -226,10 +234,7 @@ func (c *WebParserClient) extractText(sel *goquery.Selection) string {
 func (c *WebParserClient) ExtractMetadata(content *ParsedContent) *PageMetadata {
 	totalChunks := (content.EstimatedTokens + 499) / 500 // Round up, 500 tokens per chunk
 
-	briefSummary := content.CleanText
-	if len(briefSummary) > 200 {
-		briefSummary = briefSummary[:200] + "..."
-	}
+	briefSummary := utils.Truncate(content.CleanText, 200)
 
 	return &PageMetadata{
 		URL:          content.URL,
@@ -349,7 +354,10 @@ func (c *WebParserClient) findBreakPoint(text string, targetSize int) int {
 		return searchStart + idx + 1
 	}
 
-	// Fallback: break at target size
+	// Fallback: break at target size, short of a character it would split
+	if n := len(utils.Prefix(text, targetSize)); n > 0 {
+		return n
+	}
 	return targetSize
 }
 
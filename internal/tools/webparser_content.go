@@ -0,0 +1,85 @@
+// internal/tools/webparser_content.go
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ErrUnsupportedContent marks resources the parser cannot turn into text
+var ErrUnsupportedContent = errors.New("unsupported content type")
+
+// UnsupportedContentError reports the detected type of a rejected resource
+type UnsupportedContentError struct {
+	ContentType string
+	Reason      string
+}
+
+func (e *UnsupportedContentError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("unsupported content type %s: %s", e.ContentType, e.Reason)
+	}
+	return fmt.Sprintf("unsupported content type %s", e.ContentType)
+}
+
+// Unwrap lets callers match with errors.Is(err, ErrUnsupportedContent)
+func (e *UnsupportedContentError) Unwrap() error {
+	return ErrUnsupportedContent
+}
+
+// Content categories the parser handles differently
+const (
+	contentKindHTML  = "html"
+	contentKindJSON  = "json"
+	contentKindPDF   = "pdf"
+	contentKindText  = "text"
+	contentKindOther = "other"
+)
+
+// detectContentType returns the media type of a response, using the Content-Type
+// header when it is specific and sniffing magic bytes otherwise
+func detectContentType(header string, data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err == nil && mediaType != "" && mediaType != "application/octet-stream" && mediaType != "binary/octet-stream" {
+		return strings.ToLower(mediaType)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return "application/json"
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return sniffed
+}
+
+// classifyContentType maps a media type onto how the parser should treat it
+func classifyContentType(mediaType string) string {
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return contentKindHTML
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return contentKindJSON
+	case mediaType == "application/pdf":
+		return contentKindPDF
+	case strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return contentKindText
+	}
+	return contentKindOther
+}
+
+// formatJSONContent pretty-prints a JSON body behind a type marker so the LLM knows
+// it is looking at structured data rather than prose
+func formatJSONContent(data []byte) (string, error) {
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, bytes.TrimSpace(data), "", "  "); err != nil {
+		return "", err
+	}
+	return "[JSON CONTENT]\n" + pretty.String(), nil
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	cases := []struct {
+		header string
+		body   []byte
+		want   string
+	}{
+		{"text/html; charset=utf-8", []byte("<p>x</p>"), "text/html"},
+		{"", []byte(`{"a": 1}`), "application/json"},
+		{"application/octet-stream", []byte("%PDF-1.7\n"), "application/pdf"},
+		{"", png, "image/png"},
+	}
+	for _, tc := range cases {
+		if got := detectContentType(tc.header, tc.body); got != tc.want {
+			t.Errorf("detectContentType(%q) = %q, want %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestWebParserContentTypes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"temperature":21,"unit":"C"}`))
+		case "/image":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write([]byte("\xff\xd8\xff\xe0 not really a jpeg"))
+		}
+	}))
+	defer srv.Close()
+
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"url": srv.URL + "/data"})
+	if err != nil {
+		t.Fatalf("expected JSON to parse, got %v", err)
+	}
+	if result.Metadata["content_type"] != "application/json" || !strings.Contains(result.Output, "[JSON CONTENT]") {
+		t.Errorf("expected marked JSON output, got type %v", result.Metadata["content_type"])
+	}
+	if !strings.Contains(result.Output, "\"temperature\": 21") {
+		t.Error("expected pretty-printed JSON")
+	}
+
+	result, err = tool.Execute(context.Background(), map[string]interface{}{"url": srv.URL + "/image"})
+	if !errors.Is(err, ErrUnsupportedContent) {
+		t.Fatalf("expected ErrUnsupportedContent, got %v", err)
+	}
+	if result.FailureKind != FailureKindUnsupportedContent || result.Metadata["content_type"] != "image/jpeg" {
+		t.Errorf("unexpected failure result: %+v", result)
+	}
+}
@@ -0,0 +1,57 @@
+// internal/tools/webparser_extraction.go
+package tools
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-shiori/go-readability"
+)
+
+// Content extraction modes for the unified web parser
+const (
+	ExtractionModeRaw         = "raw"         // All visible page text, boilerplate included
+	ExtractionModeReadability = "readability" // Main article text with boilerplate removed
+	ExtractionModeSelective   = "selective"   // Readability text, LLM-selected chunks for large pages
+)
+
+// NormalizeExtractionMode validates a mode name, returning "" for unknown values
+func NormalizeExtractionMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case ExtractionModeRaw:
+		return ExtractionModeRaw
+	case ExtractionModeReadability:
+		return ExtractionModeReadability
+	case ExtractionModeSelective:
+		return ExtractionModeSelective
+	}
+	return ""
+}
+
+// fetchedPage is a downloaded page with both raw and boilerplate-free text
+type fetchedPage struct {
+	Article       *readability.Article
+	Provenance    PageProvenance
+	ContentType   string // Detected media type of the download
+	CacheStatus   string // HTTPCacheMiss, HTTPCacheHit or HTTPCacheRevalidated
+	RawText       string // Visible text before boilerplate removal
+	DownloadBytes int    // Size of the original response body
+}
+
+// rawPageText returns the visible text of a whole HTML document, dropping only
+// non-content elements (scripts, styles) and collapsing whitespace
+func rawPageText(html []byte) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(html)))
+	if err != nil {
+		return ""
+	}
+	doc.Find("script, style, noscript, template, svg").Remove()
+
+	lines := []string{}
+	for _, line := range strings.Split(doc.Find("body").Text(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const clutteredPage = `<html><head><title>Article</title></head><body>
+<nav><a href="/">Home</a> <a href="/news">News</a> <a href="/about">About us and our many sponsors</a></nav>
+<div class="cookie-banner">We use cookies to improve your experience. Accept all cookies to continue browsing.</div>
+<article><h1>Soil moisture and tomato yield</h1>
+<p>Consistent soil moisture is the single biggest factor in preventing blossom end rot in tomatoes grown indoors.</p>
+<p>Growers who watered on a fixed schedule saw fewer split fruits than those who watered only when the surface felt dry.</p>
+<p>Mulching the surface reduced evaporation and kept the root zone within a narrower moisture band throughout the trial.</p>
+</article>
+<section class="comments"><p>First!</p><p>Great post, check out my channel for more gardening tips every week.</p></section>
+<footer>Copyright Example Media. All rights reserved. Terms. Privacy. Contact.</footer>
+</body></html>`
+
+func parsePage(t *testing.T, tool *WebParserUnifiedTool, pageURL string, params map[string]interface{}) *ToolResult {
+	t.Helper()
+	params["url"] = pageURL
+	result, err := tool.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	return result
+}
+
+func TestWebParserExtractionModes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(clutteredPage))
+	}))
+	defer srv.Close()
+
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+	tool.SetExtractionMode(ExtractionModeRaw)
+
+	raw := parsePage(t, tool, srv.URL, map[string]interface{}{})
+	if raw.Metadata["extraction_mode"] != ExtractionModeRaw || !strings.Contains(raw.Output, "cookies") {
+		t.Errorf("expected raw mode to keep boilerplate, got mode %v", raw.Metadata["extraction_mode"])
+	}
+
+	clean := parsePage(t, tool, srv.URL, map[string]interface{}{"extraction_mode": "readability"})
+	if clean.Metadata["extraction_mode"] != ExtractionModeReadability {
+		t.Fatalf("expected param to override default mode, got %v", clean.Metadata["extraction_mode"])
+	}
+	if !strings.Contains(clean.Output, "blossom end rot") {
+		t.Error("expected article text in readability output")
+	}
+	if clean.Metadata["extracted_chars"].(int) >= clean.Metadata["raw_chars"].(int) {
+		t.Errorf("expected readability to shrink the text: %v -> %v", clean.Metadata["raw_chars"], clean.Metadata["extracted_chars"])
+	}
+	if clean.Metadata["download_bytes"] != len(clutteredPage) {
+		t.Errorf("expected download size %d, got %v", len(clutteredPage), clean.Metadata["download_bytes"])
+	}
+}
+
+func TestWebParserSizeLimitUsesDownloadSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		// Mostly markup that extraction would discard, so only the download is over the limit
+		w.Write([]byte("<html><body><p>tiny</p>" + strings.Repeat("<!-- padding -->", 70000) + "</body></html>"))
+	}))
+	defer srv.Close()
+
+	tool := NewWebParserUnifiedTool("test", "", "", 1, ToolConfig{}, nil, 6000)
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"url": srv.URL}); err == nil {
+		t.Fatal("expected oversized download to be rejected")
+	}
+}
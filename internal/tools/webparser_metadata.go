@@ -0,0 +1,227 @@
+// internal/tools/webparser_metadata.go
+package tools
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-shiori/go-readability"
+)
+
+// Metadata keys for page provenance in ToolResult.Metadata
+const (
+	MetaPageTitle        = "title"
+	MetaPageAuthor       = "author"
+	MetaPagePublished    = "published_date"
+	MetaPageModified     = "modified_date"
+	MetaPageCanonicalURL = "canonical_url"
+	MetaPageSiteName     = "site_name"
+	MetaPageLastModified = "last_modified"
+)
+
+// PageProvenanceKeys lists the provenance keys a parse result may carry
+var PageProvenanceKeys = []string{
+	MetaPageTitle, MetaPageAuthor, MetaPagePublished, MetaPageModified,
+	MetaPageCanonicalURL, MetaPageSiteName, MetaPageLastModified,
+}
+
+// PageProvenance is structured metadata describing where parsed content came from.
+// Dates are RFC 3339 (or the page's own format when it cannot be parsed).
+type PageProvenance struct {
+	Title         string
+	Author        string
+	PublishedDate string
+	ModifiedDate  string
+	CanonicalURL  string
+	SiteName      string
+	LastModified  string // From the HTTP Last-Modified header
+}
+
+// ToMetadata returns the non-empty fields keyed for ToolResult.Metadata
+func (p PageProvenance) ToMetadata() map[string]interface{} {
+	meta := map[string]interface{}{}
+	for key, value := range map[string]string{
+		MetaPageTitle:        p.Title,
+		MetaPageAuthor:       p.Author,
+		MetaPagePublished:    p.PublishedDate,
+		MetaPageModified:     p.ModifiedDate,
+		MetaPageCanonicalURL: p.CanonicalURL,
+		MetaPageSiteName:     p.SiteName,
+		MetaPageLastModified: p.LastModified,
+	} {
+		if value != "" {
+			meta[key] = value
+		}
+	}
+	return meta
+}
+
+// extractPageProvenance gathers provenance from JSON-LD, OpenGraph/meta tags, <title>,
+// the readability article and response headers, preferring the most structured source
+func extractPageProvenance(html []byte, header http.Header, pageURL *url.URL, article *readability.Article) PageProvenance {
+	var p PageProvenance
+
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(html))); err == nil {
+		ld := extractJSONLD(doc)
+		meta := func(selectors ...string) string {
+			for _, sel := range selectors {
+				if v, ok := doc.Find(sel).First().Attr("content"); ok && strings.TrimSpace(v) != "" {
+					return strings.TrimSpace(v)
+				}
+			}
+			return ""
+		}
+
+		p.Title = firstNonEmpty(ld.headline,
+			meta(`meta[property="og:title"]`, `meta[name="twitter:title"]`),
+			strings.TrimSpace(doc.Find("title").First().Text()))
+		p.Author = firstNonEmpty(ld.author,
+			meta(`meta[name="author"]`, `meta[property="article:author"]`, `meta[name="dc.creator"]`))
+		p.PublishedDate = firstNonEmpty(ld.datePublished,
+			meta(`meta[property="article:published_time"]`, `meta[name="date"]`, `meta[name="pubdate"]`, `meta[name="dc.date"]`),
+			attr(doc, "time[datetime]", "datetime"))
+		p.ModifiedDate = firstNonEmpty(ld.dateModified,
+			meta(`meta[property="article:modified_time"]`, `meta[property="og:updated_time"]`))
+		p.SiteName = meta(`meta[property="og:site_name"]`)
+
+		canonical := firstNonEmpty(attr(doc, `link[rel="canonical"]`, "href"), meta(`meta[property="og:url"]`))
+		if canonical != "" && pageURL != nil {
+			if ref, err := url.Parse(canonical); err == nil {
+				canonical = pageURL.ResolveReference(ref).String()
+			}
+		}
+		p.CanonicalURL = canonical
+	}
+
+	// Readability's own heuristics fill remaining gaps
+	if article != nil {
+		p.Title = firstNonEmpty(p.Title, strings.TrimSpace(article.Title))
+		p.Author = firstNonEmpty(p.Author, strings.TrimSpace(article.Byline))
+		p.SiteName = firstNonEmpty(p.SiteName, strings.TrimSpace(article.SiteName))
+		if p.PublishedDate == "" && article.PublishedTime != nil {
+			p.PublishedDate = article.PublishedTime.Format(time.RFC3339)
+		}
+		if p.ModifiedDate == "" && article.ModifiedTime != nil {
+			p.ModifiedDate = article.ModifiedTime.Format(time.RFC3339)
+		}
+	}
+
+	p.PublishedDate = normalizeProvenanceDate(p.PublishedDate)
+	p.ModifiedDate = normalizeProvenanceDate(p.ModifiedDate)
+
+	if lm := header.Get("Last-Modified"); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil {
+			p.LastModified = t.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return p
+}
+
+// jsonLDFields holds the schema.org fields we care about
+type jsonLDFields struct {
+	headline      string
+	author        string
+	datePublished string
+	dateModified  string
+}
+
+// extractJSONLD reads the first schema.org object with useful fields, including
+// objects nested in arrays or @graph
+func extractJSONLD(doc *goquery.Document) jsonLDFields {
+	var fields jsonLDFields
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var raw interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &raw); err != nil {
+			return true
+		}
+		for _, obj := range flattenJSONLD(raw) {
+			candidate := jsonLDFields{
+				headline:      firstNonEmpty(jsonString(obj["headline"]), jsonString(obj["name"])),
+				author:        jsonLDAuthor(obj["author"]),
+				datePublished: jsonString(obj["datePublished"]),
+				dateModified:  jsonString(obj["dateModified"]),
+			}
+			if candidate.datePublished != "" || candidate.author != "" {
+				fields = candidate
+				return false
+			}
+		}
+		return true
+	})
+	return fields
+}
+
+func flattenJSONLD(raw interface{}) []map[string]interface{} {
+	objects := []map[string]interface{}{}
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, item := range v {
+			objects = append(objects, flattenJSONLD(item)...)
+		}
+	case map[string]interface{}:
+		objects = append(objects, v)
+		if graph, ok := v["@graph"]; ok {
+			objects = append(objects, flattenJSONLD(graph)...)
+		}
+	}
+	return objects
+}
+
+// jsonLDAuthor handles author as a string, an object with a name, or a list of either
+func jsonLDAuthor(raw interface{}) string {
+	switch v := raw.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]interface{}:
+		return jsonString(v["name"])
+	case []interface{}:
+		names := []string{}
+		for _, item := range v {
+			if name := jsonLDAuthor(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+func jsonString(raw interface{}) string {
+	if s, ok := raw.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return ""
+}
+
+func attr(doc *goquery.Document, selector, name string) string {
+	v, _ := doc.Find(selector).First().Attr(name)
+	return strings.TrimSpace(v)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// normalizeProvenanceDate converts common date layouts to RFC 3339, keeping the
+// original text when it does not match any
+func normalizeProvenanceDate(value string) string {
+	if value == "" {
+		return ""
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05Z0700", "2006-01-02T15:04:05", "2006-01-02", time.RFC1123, time.RFC1123Z} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return value
+}
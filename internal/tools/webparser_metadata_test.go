@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestExtractPageProvenance(t *testing.T) {
+	html := []byte(`<html><head>
+<title>Fallback Title</title>
+<meta property="og:title" content="OG Title">
+<meta property="og:site_name" content="Example News">
+<link rel="canonical" href="/articles/42">
+<script type="application/ld+json">
+{"@context":"https://schema.org","@graph":[{"@type":"WebSite","name":"Example"},
+ {"@type":"NewsArticle","headline":"LD Headline","datePublished":"2024-03-05T10:00:00Z",
+  "author":[{"@type":"Person","name":"Ada"},{"@type":"Person","name":"Grace"}]}]}
+</script>
+</head><body><p>Body</p></body></html>`)
+	header := http.Header{}
+	header.Set("Last-Modified", "Wed, 06 Mar 2024 08:00:00 GMT")
+	pageURL, _ := url.Parse("https://example.com/articles/42?utm_source=x")
+
+	p := extractPageProvenance(html, header, pageURL, nil)
+
+	if p.Title != "LD Headline" {
+		t.Errorf("expected JSON-LD headline, got %q", p.Title)
+	}
+	if p.Author != "Ada, Grace" {
+		t.Errorf("expected joined authors, got %q", p.Author)
+	}
+	if p.PublishedDate != "2024-03-05T10:00:00Z" {
+		t.Errorf("unexpected published date %q", p.PublishedDate)
+	}
+	if p.CanonicalURL != "https://example.com/articles/42" {
+		t.Errorf("expected resolved canonical URL, got %q", p.CanonicalURL)
+	}
+	if p.SiteName != "Example News" || p.LastModified != "2024-03-06T08:00:00Z" {
+		t.Errorf("unexpected site/last-modified: %+v", p)
+	}
+}
+
+func TestPageProvenanceOmitsMissingFields(t *testing.T) {
+	html := []byte(`<html><head><title> Only Title </title></head><body></body></html>`)
+	meta := extractPageProvenance(html, http.Header{}, nil, nil).ToMetadata()
+
+	if meta[MetaPageTitle] != "Only Title" {
+		t.Errorf("expected title, got %v", meta[MetaPageTitle])
+	}
+	for _, key := range []string{MetaPageAuthor, MetaPagePublished, MetaPageCanonicalURL, MetaPageLastModified} {
+		if _, ok := meta[key]; ok {
+			t.Errorf("expected %s to be absent, got %v", key, meta[key])
+		}
+	}
+}
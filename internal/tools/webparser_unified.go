@@ -4,17 +4,21 @@ import (
 	"bytes"
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "io"
     "log"
     "net/http"
     "net/url"
     "os/exec"
+    "strconv"
     "strings"
     "time"
 
     "github.com/go-shiori/go-readability"
     "go-llama/internal/config"
+    "go-llama/internal/httpclient"
+	"go-llama/internal/utils"
 )
 
 // WebParserUnifiedTool provides intelligent web parsing with strategy selection
@@ -26,6 +30,12 @@ type WebParserUnifiedTool struct {
     llmModel          string
     llmClient         interface{} // Queue client
     maxContentTokens  int         // Dynamic limit based on LLM context size (typically 2/3 of context)
+    extractionMode    string      // Default extraction mode (see ExtractionMode* constants)
+    httpCache         *HTTPCache  // Optional; nil disables conditional requests and body reuse
+    domainPolicy      *DomainPolicy // Optional; nil leaves fetch targets unchecked
+    chunkTokens       int         // Estimated tokens per chunk in selective parsing
+    chunkOverlap      int         // Estimated tokens each chunk repeats from the previous one
+    language          LanguageConfig // Target language and the policy for pages in others
 }
 
 // NewWebParserUnifiedTool creates a new unified parser
@@ -40,22 +50,83 @@ func NewWebParserUnifiedTool(userAgent string, llmURL string, llmModel string, m
         maxContentTokens = 6000
     }
 
+    httpClient := httpclient.New(timeout)
+    httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+        if len(via) >= 10 {
+            return fmt.Errorf("stopped after 10 redirects")
+        }
+        return nil
+    }
+
     return &WebParserUnifiedTool{
-        httpClient: &http.Client{
-            Timeout: timeout,
-            CheckRedirect: func(req *http.Request, via []*http.Request) error {
-                if len(via) >= 10 {
-                    return fmt.Errorf("stopped after 10 redirects")
-                }
-                return nil
-            },
-        },
+        httpClient:       httpClient,
         userAgent:        userAgent,
         maxSizeMB:        maxPageSizeMB,
         llmURL:           llmURL,
         llmModel:         llmModel,
         llmClient:        llmClient,
         maxContentTokens: maxContentTokens,
+        extractionMode:   ExtractionModeSelective,
+        chunkTokens:      DefaultChunkTokens,
+        chunkOverlap:     DefaultChunkOverlapTokens,
+    }
+}
+
+// SetChunking sets the chunk size and overlap, in estimated tokens, used by selective
+// parsing. A non-positive size keeps the default; a negative overlap disables overlap.
+func (t *WebParserUnifiedTool) SetChunking(sizeTokens, overlapTokens int) {
+    if sizeTokens > 0 {
+        t.chunkTokens = sizeTokens
+    }
+    if overlapTokens < 0 {
+        overlapTokens = 0
+    }
+    t.chunkOverlap = overlapTokens
+}
+
+// SetLanguage sets the language pages are expected in and what happens to pages in
+// other languages. Unknown policies keep the current one.
+func (t *WebParserUnifiedTool) SetLanguage(cfg LanguageConfig) {
+    policy := NormalizeLanguagePolicy(cfg.Policy)
+    if policy == "" {
+        policy = NormalizeLanguagePolicy(t.language.Policy)
+    }
+    cfg.Policy = policy
+    cfg.Target = strings.ToLower(strings.TrimSpace(cfg.Target))
+    t.language = cfg
+}
+
+// SetHTTPCache enables reuse of downloaded bodies across parses of the same URL
+func (t *WebParserUnifiedTool) SetHTTPCache(cache *HTTPCache) {
+    t.httpCache = cache
+}
+
+// SetDomainPolicy restricts fetch targets. Redirect hops and the resolved address of
+// every connection are checked as well, so private hosts cannot be reached indirectly.
+// Fetches stay on the shared httpclient transport and its connection pool.
+func (t *WebParserUnifiedTool) SetDomainPolicy(policy *DomainPolicy) {
+    t.domainPolicy = policy
+    t.httpClient.Transport = policy.Transport()
+    t.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+        if len(via) >= 10 {
+            return fmt.Errorf("stopped after 10 redirects")
+        }
+        return policy.CheckURL(req.URL.String())
+    }
+}
+
+// HTTPCacheStats reports page cache outcomes (zero when caching is disabled)
+func (t *WebParserUnifiedTool) HTTPCacheStats() HTTPCacheStats {
+    if t.httpCache == nil {
+        return HTTPCacheStats{}
+    }
+    return t.httpCache.Stats()
+}
+
+// SetExtractionMode sets the default content extraction mode; unknown modes are ignored
+func (t *WebParserUnifiedTool) SetExtractionMode(mode string) {
+    if normalized := NormalizeExtractionMode(mode); normalized != "" {
+        t.extractionMode = normalized
     }
 }
 
@@ -69,6 +140,25 @@ func (t *WebParserUnifiedTool) Description() string {
     return "Intelligently parse web pages: uses full parse for short pages, or LLM-driven selective chunking for large pages to maximize relevance."
 }
 
+// Info describes the parser for the tool list. Large pages also cost an LLM call to
+// select chunks.
+func (t *WebParserUnifiedTool) Info() ToolInfo {
+    return ToolInfo{
+        Description: t.Description(),
+        Parameters: []ToolParameter{
+            {Name: "url", Type: "string", Description: "http(s) URL of the page", Required: true},
+            {Name: "goal", Type: "string", Description: "What to look for; guides chunk selection on large pages"},
+            {Name: "chunks", Type: "[]int", Description: "Chunk indexes to read, skipping selection"},
+            {Name: "extraction_mode", Type: "string", Description: "Content extraction mode"},
+            {Name: "bypass_cache", Type: "bool", Description: "Fetch the page even if cached"},
+        },
+        IdleAllowed:      true,
+        ExpectedDuration: 15 * time.Second,
+        CostHint:         CostHigh,
+        Order:            20,
+    }
+}
+
 // RequiresAuth returns false
 func (t *WebParserUnifiedTool) RequiresAuth() bool {
     return false
@@ -85,108 +175,287 @@ func (t *WebParserUnifiedTool) Execute(ctx context.Context, params map[string]in
     }
 
     goal, _ := params["goal"].(string) // Optional, but critical for large pages
+    requestedChunks := chunkIndexesParam(params["chunks"]) // Optional; skips LLM selection
+
+    mode := t.extractionMode
+    if requested, ok := params["extraction_mode"].(string); ok {
+        if normalized := NormalizeExtractionMode(requested); normalized != "" {
+            mode = normalized
+        }
+    }
 
     if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
         return &ToolResult{Success: false, Error: "invalid URL scheme"}, fmt.Errorf("invalid url")
     }
 
+    if t.domainPolicy != nil {
+        if err := t.domainPolicy.CheckURL(urlStr); err != nil {
+            return domainBlockedResult(urlStr, err), err
+        }
+    }
+
     // 2. Fetch & Extract
-    article, err := t.fetchAndExtract(ctx, urlStr)
+    page, err := t.fetchAndExtract(ctx, urlStr)
     if err != nil {
-        return &ToolResult{Success: false, Error: fmt.Sprintf("Fetch failed: %v", err)}, err
+        result := &ToolResult{
+            Success:  false,
+            Error:    fmt.Sprintf("Fetch failed: %v", err),
+            Metadata: map[string]interface{}{"url": urlStr},
+        }
+        result.FailureKind = FailureKindOf(err)
+        var unsupported *UnsupportedContentError
+        if errors.As(err, &unsupported) {
+            result.Metadata["content_type"] = unsupported.ContentType
+        }
+        var status *HTTPStatusError
+        if errors.As(err, &status) {
+            result.Metadata["status_code"] = status.Code
+        }
+        if errors.Is(err, ErrDomainBlocked) {
+            // A redirect or DNS answer led to a refused target
+            return domainBlockedResult(urlStr, err), err
+        }
+        return result, err
     }
+    article := page.Article
 
-    // 3. Token Estimation
-    tokens := t.estimateTokens(article.TextContent)
+    // 3. Pre-extraction: raw mode keeps everything, the others use readability output
+    text := article.TextContent
+    if mode == ExtractionModeRaw {
+        text = page.RawText
+    }
+    log.Printf("[WebParser] Extraction mode %s: %d raw chars -> %d chars", mode, len(page.RawText), len(text))
+
+    // Pages in another language are skipped here or translated once content is chosen
+    language := DetectLanguage(text)
+    foreign := t.language.Target != "" && language.Reliable() && language.Code != t.language.Target
+    if foreign && t.language.Policy == LanguagePolicySkip {
+        err := &WrongLanguageError{Detected: language.Code, Target: t.language.Target}
+        log.Printf("[WebParser] Skipping %s: %v (confidence %.2f)", urlStr, err, language.Confidence)
+        return wrongLanguageResult(urlStr, language, err), err
+    }
+
+    // Token Estimation
+    tokens := t.estimateTokens(text)
     
     var content string
     var strategy string
     var reasoning string
+    var selection *chunkSelection
 
     // 4. Strategy Selection
     if tokens <= t.maxContentTokens {
         // STRATEGY: FULL
         strategy = "FULL_PARSE"
         reasoning = fmt.Sprintf("Page size (%d tokens) is within threshold (%d). Returning full content.", tokens, t.maxContentTokens)
-        content = article.TextContent
+        content = text
         log.Printf("[WebParser] Strategy: FULL (Size: %d tokens)", tokens)
     } else {
         // STRATEGY: SELECTIVE
         strategy = "SELECTIVE_CHUNKING"
         log.Printf("[WebParser] Strategy: SELECTIVE (Size: %d tokens)", tokens)
         
-        if goal == "" {
+        if mode != ExtractionModeSelective {
+            // Non-selective modes never call the LLM; return the head of the extracted text
+            strategy = "TRUNCATED"
+            reasoning = fmt.Sprintf("Page size (%d tokens) exceeds threshold in %s mode. Returning first %d tokens.", tokens, mode, t.maxContentTokens)
+            content = t.truncateText(text, t.maxContentTokens)
+        } else if goal == "" && len(requestedChunks) == 0 {
             // Fallback if no goal provided but page is huge
             // Use the dynamic limit instead of hardcoded 4000
             reasoning = fmt.Sprintf("Page size (%d tokens) exceeds threshold, but NO GOAL provided. Returning first %d tokens.", tokens, t.maxContentTokens)
-            content = t.truncateText(article.TextContent, t.maxContentTokens)
+            content = t.truncateText(text, t.maxContentTokens)
         } else {
             // LLM Assisted Selection
-            selectedContent, selReasoning, err := t.performSelectiveParsing(ctx, article, goal)
+            sel, err := t.performSelectiveParsing(ctx, article, goal, requestedChunks)
             if err != nil {
                 // Use dynamic limit for fallback as well (split between metadata and content)
                 fallbackLimit := t.maxContentTokens
                 reasoning = fmt.Sprintf("Selective parsing failed: %v. Falling back to metadata + first %d tokens.", err, fallbackLimit)
                 content = fmt.Sprintf("METADATA:\n%s\n\nTOP CONTENT:\n%s", t.formatMetadata(article), t.truncateText(article.TextContent, fallbackLimit))
             } else {
-                content = selectedContent
-                reasoning = selReasoning
+                selection = sel
+                content = sel.Content
+                reasoning = sel.Reasoning
             }
         }
     }
 
+    // Translate the chosen content rather than the whole page, so the cost is bounded
+    var translationTokens int
+    if foreign && t.language.Policy == LanguagePolicyTranslate {
+        translated, tokens, err := translateText(ctx, t.llmClient, t.language, content, language.Code)
+        if err != nil {
+            // Untranslated text is what garbles summaries, so a fallback page is better
+            err = fmt.Errorf("%w (translation failed: %v)", &WrongLanguageError{Detected: language.Code, Target: t.language.Target}, err)
+            log.Printf("[WebParser] Skipping %s: %v", urlStr, err)
+            return wrongLanguageResult(urlStr, language, err), err
+        }
+        log.Printf("[WebParser] Translated %s from %s (%d tokens)", urlStr, LanguageName(language.Code), tokens)
+        content = translated
+        translationTokens = tokens
+        reasoning += fmt.Sprintf(" Translated from %s.", LanguageName(language.Code))
+    }
+
     // 5. Format Output
-    output := fmt.Sprintf("=== WEB PARSER RESULTS ===\nStrategy: %s\nReasoning: %s\n\nSource: %s\n%s\n\nContent:\n%s",
-        strategy, reasoning, article.Title, urlStr, content)
+    output := fmt.Sprintf("=== WEB PARSER RESULTS ===\nStrategy: %s\nReasoning: %s\n\nSource: %s\n%s\n%s\nContent:\n%s",
+        strategy, reasoning, article.Title, urlStr, formatProvenance(page.Provenance), content)
+
+    // Provenance fields are only present when the page supplied them
+    metadata := page.Provenance.ToMetadata()
+    metadata["url"] = urlStr
+    metadata["strategy"] = strategy
+    metadata["final_tokens"] = t.estimateTokens(content)
+    metadata["original_size"] = tokens
+    metadata["content_type"] = page.ContentType
+    metadata["http_cache"] = page.CacheStatus
+    metadata["from_cache"] = page.CacheStatus != HTTPCacheMiss
+    metadata["extraction_mode"] = mode
+    metadata["download_bytes"] = page.DownloadBytes
+    metadata["raw_chars"] = len(page.RawText)
+    metadata["extracted_chars"] = len(text)
+    metadata["language"] = language.Code
+    metadata["language_confidence"] = language.Confidence
+    if translationTokens > 0 {
+        metadata["translated_from"] = language.Code
+        metadata["translation_tokens"] = translationTokens
+    }
+    if selection != nil {
+        // Boundaries let callers name sections or re-read specific chunks later
+        metadata["chunks"] = selection.Chunks
+        metadata["selected_chunks"] = selection.Selected
+        metadata["chunk_tokens"] = t.chunkTokens
+        metadata["chunk_overlap_tokens"] = t.chunkOverlap
+    }
+
+    return &ToolResult{
+        Success:    true,
+        Output:     output,
+        Duration:   time.Since(startTime),
+        TokensUsed: translationTokens,
+        Metadata:   metadata,
+    }, nil
+}
 
+// wrongLanguageResult is the failed result for a page skipped for its language
+func wrongLanguageResult(urlStr string, language LanguageDetection, err error) *ToolResult {
     return &ToolResult{
-        Success:  true,
-        Output:   output,
-        Duration: time.Since(startTime),
+        Success:     false,
+        Error:       fmt.Sprintf("Skipped: %v", err),
+        FailureKind: FailureKindWrongLanguage,
         Metadata: map[string]interface{}{
-            "url":           urlStr,
-            "title":         article.Title,
-            "strategy":      strategy,
-            "final_tokens":  t.estimateTokens(content),
-            "original_size": tokens,
+            "url":                 urlStr,
+            "language":            language.Code,
+            "language_confidence": language.Confidence,
         },
-    }, nil
+    }
 }
 
 // fetchAndExtract handles HTTP, Readability, and PDF parsing
-func (t *WebParserUnifiedTool) fetchAndExtract(ctx context.Context, urlString string) (*readability.Article, error) {
+func (t *WebParserUnifiedTool) fetchAndExtract(ctx context.Context, urlString string) (*fetchedPage, error) {
     parsedURL, err := url.Parse(urlString)
     if err != nil {
         return nil, err
     }
 
-    req, err := http.NewRequestWithContext(ctx, "GET", urlString, nil)
+    endFetch := StartSpan(ctx, SpanFetch)
+    data, header, cacheStatus, err := t.download(ctx, urlString)
+    endFetch()
     if err != nil {
         return nil, err
     }
+
+    endExtraction := StartSpan(ctx, SpanExtraction)
+    page, err := t.extract(parsedURL, data, header)
+    endExtraction()
+    if page != nil {
+        page.CacheStatus = cacheStatus
+    }
+    return page, err
+}
+
+// download fetches a URL body, reusing or revalidating a cached copy when the HTTP
+// cache is enabled. It returns the body, its headers and the cache outcome.
+func (t *WebParserUnifiedTool) download(ctx context.Context, urlString string) ([]byte, http.Header, string, error) {
+    var cached *CachedPage
+    if t.httpCache != nil {
+        cached = t.httpCache.Lookup(ctx, urlString)
+        // Repeated parses of one URL (full, then selective, then a retry) share the body
+        if cached != nil && t.httpCache.Fresh(cached) {
+            t.httpCache.Record(HTTPCacheHit)
+            log.Printf("[WebParser] HTTP cache hit: %s", urlString)
+            return cached.Body, cached.Header(), HTTPCacheHit, nil
+        }
+    }
+
+    req, err := http.NewRequestWithContext(ctx, "GET", urlString, nil)
+    if err != nil {
+        return nil, nil, "", err
+    }
     req.Header.Set("User-Agent", t.userAgent)
+    SetTraceHeader(req)
+    if cached != nil {
+        if cached.ETag != "" {
+            req.Header.Set("If-None-Match", cached.ETag)
+        }
+        if cached.LastModified != "" {
+            req.Header.Set("If-Modified-Since", cached.LastModified)
+        }
+    }
 
     resp, err := t.httpClient.Do(req)
     if err != nil {
-        return nil, err
+        return nil, nil, "", wrapTimeout(err)
     }
     defer resp.Body.Close()
 
+    if resp.StatusCode == http.StatusNotModified && cached != nil {
+        t.httpCache.Refresh(ctx, cached)
+        t.httpCache.Record(HTTPCacheRevalidated)
+        log.Printf("[WebParser] HTTP 304, reusing cached body: %s", urlString)
+        return cached.Body, cached.Header(), HTTPCacheRevalidated, nil
+    }
+
     if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+        return nil, nil, "", &HTTPStatusError{Code: resp.StatusCode}
     }
 
+    // Size limits apply to the original download, before any extraction shrinks it
     maxBytes := int64(t.maxSizeMB * 1024 * 1024)
-    limitedReader := io.LimitReader(resp.Body, maxBytes)
+    if resp.ContentLength > maxBytes {
+        return nil, nil, "", fmt.Errorf("%w: content length %d exceeds size limit of %dMB", ErrPageTooLarge, resp.ContentLength, t.maxSizeMB)
+    }
+    limitedReader := io.LimitReader(resp.Body, maxBytes+1)
     data, err := io.ReadAll(limitedReader)
     if err != nil {
-        return nil, err
+        return nil, nil, "", wrapTimeout(err)
     }
+    if int64(len(data)) > maxBytes {
+        return nil, nil, "", fmt.Errorf("%w: content exceeds size limit of %dMB", ErrPageTooLarge, t.maxSizeMB)
+    }
+
+    if t.httpCache != nil {
+        t.httpCache.Record(HTTPCacheMiss)
+        t.httpCache.Store(ctx, &CachedPage{
+            URL:          urlString,
+            ETag:         resp.Header.Get("ETag"),
+            LastModified: resp.Header.Get("Last-Modified"),
+            ContentType:  resp.Header.Get("Content-Type"),
+            Body:         data,
+            StoredAt:     time.Now(),
+        })
+    }
+
+    return data, resp.Header, HTTPCacheMiss, nil
+}
 
-    // Check Content-Type to determine parsing strategy
-    contentType := resp.Header.Get("Content-Type")
+// extract turns a downloaded body into text according to its content type
+func (t *WebParserUnifiedTool) extract(parsedURL *url.URL, data []byte, header http.Header) (*fetchedPage, error) {
+    // Determine parsing strategy from Content-Type, sniffing magic bytes when absent
+    contentType := detectContentType(header.Get("Content-Type"), data)
 
-    if strings.Contains(contentType, "application/pdf") {
+    switch classifyContentType(contentType) {
+    case contentKindPDF:
         // --- PDF PARSING LOGIC (CLI Tool) ---
         log.Printf("[WebParser] Detected PDF, extracting text via pdftotext...")
 
@@ -200,66 +469,167 @@ func (t *WebParserUnifiedTool) fetchAndExtract(ctx context.Context, urlString st
         // Capture the output (stdout + stderr)
         output, err := cmd.CombinedOutput()
         if err != nil {
-            return nil, fmt.Errorf("pdftotext failed: %w, output: %s", err, string(output))
+            // No usable extractor: report it as unsupported so callers move on quickly
+            return nil, &UnsupportedContentError{
+                ContentType: contentType,
+                Reason:      fmt.Sprintf("pdftotext failed: %v", err),
+            }
         }
 
         pdfText := string(output)
 
-        // Map PDF content to the Article struct
-        return &readability.Article{
-            Title:       "PDF Document: " + parsedURL.Path,
-            Content:     pdfText,
-            TextContent: pdfText,
-            Length:      len(pdfText),
+        // Map PDF content to the Article struct (PDFs carry no HTML metadata, only headers)
+        return &fetchedPage{
+            Article: &readability.Article{
+                Title:       "PDF Document: " + parsedURL.Path,
+                Content:     pdfText,
+                TextContent: pdfText,
+                Length:      len(pdfText),
+            },
+            Provenance:    extractPageProvenance(nil, header, parsedURL, nil),
+            ContentType:   contentType,
+            RawText:       pdfText,
+            DownloadBytes: len(data),
         }, nil
 
-    } else {
+    case contentKindJSON:
+        // --- JSON: pretty-print and pass through ---
+        text, err := formatJSONContent(data)
+        if err != nil {
+            return nil, &UnsupportedContentError{ContentType: contentType, Reason: "invalid JSON body"}
+        }
+        return t.plainTextPage(parsedURL, header, contentType, text, len(data)), nil
+
+    case contentKindText:
+        // --- Plain text, markdown, XML: pass through unchanged ---
+        return t.plainTextPage(parsedURL, header, contentType, string(data), len(data)), nil
+
+    case contentKindHTML:
         // --- HTML PARSING LOGIC (Existing) ---
         article, err := readability.FromReader(strings.NewReader(string(data)), parsedURL)
         if err != nil {
             return nil, err
         }
-        return &article, nil
+        return &fetchedPage{
+            Article:       &article,
+            Provenance:    extractPageProvenance(data, header, parsedURL, &article),
+            ContentType:   contentType,
+            RawText:       rawPageText(data),
+            DownloadBytes: len(data),
+        }, nil
+
+    default:
+        // Images, archives, media and other binaries: fail fast
+        return nil, &UnsupportedContentError{ContentType: contentType}
     }
 }
 
-// performSelectiveParsing asks the LLM which chunks to read
-func (t *WebParserUnifiedTool) performSelectiveParsing(ctx context.Context, article *readability.Article, goal string) (string, string, error) {
-    // 1. Create Chunks (approx 500 tokens each)
-    chunks := t.createChunks(article.TextContent, 2000) // 2000 chars ~ 500 tokens
+// plainTextPage wraps non-HTML text so it flows through the same strategy selection
+func (t *WebParserUnifiedTool) plainTextPage(pageURL *url.URL, header http.Header, contentType, text string, size int) *fetchedPage {
+    return &fetchedPage{
+        Article: &readability.Article{
+            Title:       pageURL.Path,
+            TextContent: text,
+            Length:      len(text),
+        },
+        Provenance:    extractPageProvenance(nil, header, pageURL, nil),
+        ContentType:   contentType,
+        RawText:       text,
+        DownloadBytes: size,
+    }
+}
 
-    // 2. Generate Chunk Map (Metadata for LLM)
+// chunkSelection is the outcome of selective parsing
+type chunkSelection struct {
+    Content   string
+    Reasoning string
+    Chunks    []PageChunk
+    Selected  []int
+}
+
+// performSelectiveParsing reads the requested chunks, or asks the LLM which chunks to
+// read when none of the requested indexes exist in the current chunking
+func (t *WebParserUnifiedTool) performSelectiveParsing(ctx context.Context, article *readability.Article, goal string, requested []int) (*chunkSelection, error) {
+    // 1. Create token-estimated, overlapping chunks labelled with their section
+    text := article.TextContent
+    chunks := chunkText(text, t.chunkTokens, t.chunkOverlap)
+    labelChunks(chunks, pageHeadings(article))
+    sel := &chunkSelection{Chunks: chunks}
+
+    // Indexes stored by earlier actions may come from different chunk settings; they
+    // are used as-is when they still exist
+    for _, idx := range requested {
+        if idx >= 0 && idx < len(chunks) && !containsInt(sel.Selected, idx) {
+            sel.Selected = append(sel.Selected, idx)
+        }
+    }
+    if len(sel.Selected) > 0 {
+        sel.Reasoning = fmt.Sprintf("Page size (%d tokens) required selection. Read %d requested chunks.", t.estimateTokens(text), len(sel.Selected))
+    } else {
+        if goal == "" {
+            return nil, fmt.Errorf("no goal to select chunks for")
+        }
+        endSelect := StartSpan(ctx, SpanLLMSelect)
+        selected, err := t.selectChunks(ctx, text, chunks, goal)
+        endSelect()
+        if err != nil {
+            return nil, err
+        }
+        sel.Selected = selected
+        sel.Reasoning = fmt.Sprintf("Page size (%d tokens) required selection. LLM selected %d chunks based on goal '%s'.", t.estimateTokens(text), len(selected), goal)
+    }
+
+    // 2. Stitch Content
+    var builder strings.Builder
+    builder.WriteString(fmt.Sprintf("SELECTIVE EXTRACTION: Goal='%s'. Selected %d/%d chunks.\n\n", goal, len(sel.Selected), len(chunks)))
+    for _, idx := range sel.Selected {
+        c := chunks[idx]
+        if c.Heading != "" {
+            builder.WriteString(fmt.Sprintf("--- CHUNK %d (Section: %s) ---\n%s\n\n", idx, c.Heading, text[c.Start:c.End]))
+        } else {
+            builder.WriteString(fmt.Sprintf("--- CHUNK %d ---\n%s\n\n", idx, text[c.Start:c.End]))
+        }
+    }
+    sel.Content = builder.String()
+    return sel, nil
+}
+
+// selectChunks asks the LLM which chunks are relevant to the goal. The chunk map names
+// each chunk's section, so the model may answer with section headings as well as indexes.
+func (t *WebParserUnifiedTool) selectChunks(ctx context.Context, text string, chunks []PageChunk, goal string) ([]int, error) {
+    // 1. Generate Chunk Map (Metadata for LLM)
     type ChunkInfo struct {
         Index   int    `json:"index"`
+        Section string `json:"section,omitempty"`
+        Start   int    `json:"start"`
+        End     int    `json:"end"`
         Preview string `json:"preview"`
     }
-    
+
     chunkInfos := []ChunkInfo{}
-    for i, c := range chunks {
-        preview := strings.ReplaceAll(c, "\n", " ")
-        if len(preview) > 100 {
-            preview = preview[:100] + "..."
-        }
-        chunkInfos = append(chunkInfos, ChunkInfo{Index: i, Preview: preview})
+    for _, c := range chunks {
+        preview := strings.ReplaceAll(text[c.Start:c.End], "\n", " ")
+        preview = utils.Truncate(preview, 100)
+        chunkInfos = append(chunkInfos, ChunkInfo{Index: c.Index, Section: c.Heading, Start: c.Start, End: c.End, Preview: preview})
     }
 
-    // 3. Prompt LLM
-    mapJSON, _ := json.Marshal(chunkInfos) // Typo fix here
-    
+    // 2. Prompt LLM
+    mapJSON, _ := json.Marshal(chunkInfos)
+
     prompt := fmt.Sprintf(`You are a research assistant. Your goal is: "%s".
 
-I have a large web page divided into %d chunks. I cannot read them all. 
-Here is a map of the chunks (Index and Preview):
+I have a large web page divided into %d overlapping chunks. I cannot read them all.
+Here is a map of the chunks (Index, the Section heading each chunk falls under, character offsets Start-End, and a Preview):
 
 %s
 
-TASK: Identify the chunk indexes (0 to %d) that are MOST LIKELY to contain information relevant to the goal.
-- Return ONLY a JSON array of integers. 
-- Example: [1, 3, 5]
+TASK: Identify the chunks (0 to %d) that are MOST LIKELY to contain information relevant to the goal.
+- Return ONLY a JSON array. Each entry is a chunk index, or a section heading to read every chunk of that section.
+- Example: [1, 3, "Installation"]
 - If none seem relevant, return [].
 - Be precise. Do not guess.
 
-Relevant Chunk Indexes:`,
+Relevant Chunks:`,
         goal, len(chunks), string(mapJSON), len(chunks)-1)
 
     reqBody := map[string]interface{}{
@@ -273,7 +643,7 @@ Relevant Chunk Indexes:`,
     }
 
     if t.llmClient == nil {
-        return "", "", fmt.Errorf("LLM client unavailable")
+        return nil, fmt.Errorf("LLM client unavailable")
     }
 
     type LLMCaller interface {
@@ -282,13 +652,13 @@ Relevant Chunk Indexes:`,
 
     client, ok := t.llmClient.(LLMCaller)
     if !ok {
-        return "", "", fmt.Errorf("LLM client type assertion failed")
+        return nil, fmt.Errorf("LLM client type assertion failed")
     }
 
     llmURL := config.GetChatURL(t.llmURL)
     body, err := client.Call(ctx, llmURL, reqBody)
     if err != nil {
-        return "", "", fmt.Errorf("LLM Call failed: %w", err)
+        return nil, fmt.Errorf("LLM Call failed: %w", err)
     }
 
     var llmResp struct {
@@ -298,58 +668,78 @@ Relevant Chunk Indexes:`,
             } `json:"message"`
         } `json:"choices"`
     }
-    
+
     if err := json.Unmarshal(body, &llmResp); err != nil {
-        return "", "", fmt.Errorf("LLM Decode failed: %w", err)
+        return nil, fmt.Errorf("LLM Decode failed: %w", err)
+    }
+    if len(llmResp.Choices) == 0 {
+        return nil, fmt.Errorf("no choices returned from LLM")
     }
 
-    // 4. Parse Selection
-    var selectedIndexes []int
-    if err := json.Unmarshal([]byte(llmResp.Choices[0].Message.Content), &selectedIndexes); err != nil {
-        return "", "", fmt.Errorf("LLM JSON Parse failed: %w (Content: %s)", err, llmResp.Choices[0].Message.Content)
+    // 3. Parse Selection
+    var entries []interface{}
+    if err := json.Unmarshal([]byte(llmResp.Choices[0].Message.Content), &entries); err != nil {
+        return nil, fmt.Errorf("LLM JSON Parse failed: %w (Content: %s)", err, llmResp.Choices[0].Message.Content)
     }
+    return resolveChunkSelection(entries, chunks), nil
+}
 
-    // 5. Stitch Content
-    var builder strings.Builder
-    builder.WriteString(fmt.Sprintf("SELECTIVE EXTRACTION: Goal='%s'. Selected %d/%d chunks.\n\n", goal, len(selectedIndexes), len(chunks)))
-    
-    for _, idx := range selectedIndexes {
-        if idx >= 0 && idx < len(chunks) {
-            builder.WriteString(fmt.Sprintf("--- CHUNK %d ---\n%s\n\n", idx, chunks[idx]))
+// resolveChunkSelection maps selected indexes and section headings to distinct chunk
+// indexes in selection order, dropping entries that match no chunk
+func resolveChunkSelection(entries []interface{}, chunks []PageChunk) []int {
+    selected := []int{}
+    add := func(idx int) {
+        if idx >= 0 && idx < len(chunks) && !containsInt(selected, idx) {
+            selected = append(selected, idx)
         }
     }
-
-    reasoning := fmt.Sprintf("Page size (%d tokens) required selection. LLM selected %d chunks based on goal '%s'.", t.estimateTokens(article.TextContent), len(selectedIndexes), goal)
-    return builder.String(), reasoning, nil
+    for _, entry := range entries {
+        switch v := entry.(type) {
+        case float64:
+            add(int(v))
+        case string:
+            section := strings.TrimSpace(v)
+            for _, c := range chunks {
+                if c.Heading != "" && strings.EqualFold(c.Heading, section) {
+                    add(c.Index)
+                }
+            }
+        }
+    }
+    return selected
 }
 
-// createChunks splits text into roughly equal sized chunks
-func (t *WebParserUnifiedTool) createChunks(text string, size int) []string {
-    if len(text) == 0 {
-        return []string{}
-    }
-    
-    var chunks []string
-    for len(text) > 0 {
-        if len(text) <= size {
-            chunks = append(chunks, text)
-            break
+// chunkIndexesParam reads chunk indexes from a tool parameter given as a number, a
+// numeric string or a list of either
+func chunkIndexesParam(value interface{}) []int {
+    switch v := value.(type) {
+    case int:
+        return []int{v}
+    case float64:
+        return []int{int(v)}
+    case string:
+        if idx, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+            return []int{idx}
         }
-        
-        // Try to break at a newline or space
-        cutPoint := size
-        if idx := strings.LastIndex(text[:cutPoint], "\n\n"); idx > size/2 {
-            cutPoint = idx + 2
-        } else if idx := strings.LastIndex(text[:cutPoint], "\n"); idx > size/2 {
-            cutPoint = idx + 1
-        } else if idx := strings.LastIndex(text[:cutPoint], " "); idx > size/2 {
-            cutPoint = idx + 1
+    case []int:
+        return v
+    case []interface{}:
+        indexes := []int{}
+        for _, item := range v {
+            indexes = append(indexes, chunkIndexesParam(item)...)
         }
-        
-        chunks = append(chunks, text[:cutPoint])
-        text = text[cutPoint:]
+        return indexes
     }
-    return chunks
+    return nil
+}
+
+func containsInt(values []int, value int) bool {
+    for _, v := range values {
+        if v == value {
+            return true
+        }
+    }
+    return false
 }
 
 // estimateTokens
@@ -358,10 +748,23 @@ func (t *WebParserUnifiedTool) estimateTokens(text string) int {
 }
 
 func (t *WebParserUnifiedTool) truncateText(text string, maxChars int) string {
-    if len(text) <= maxChars {
-        return text
+    return utils.TruncateWith(text, maxChars, "...[truncated]")
+}
+
+// formatProvenance renders the known provenance fields as header lines
+func formatProvenance(p PageProvenance) string {
+    var builder strings.Builder
+    for _, line := range []struct{ label, value string }{
+        {"Author", p.Author},
+        {"Published", p.PublishedDate},
+        {"Modified", firstNonEmpty(p.ModifiedDate, p.LastModified)},
+        {"Canonical URL", p.CanonicalURL},
+    } {
+        if line.value != "" {
+            builder.WriteString(fmt.Sprintf("%s: %s\n", line.label, line.value))
+        }
     }
-    return text[:maxChars] + "...[truncated]"
+    return builder.String()
 }
 
 func (t *WebParserUnifiedTool) formatMetadata(article *readability.Article) string {
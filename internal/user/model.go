@@ -16,6 +16,10 @@ type User struct {
 	Username     string    `gorm:"uniqueIndex;size:32;not null" json:"username"`
 	PasswordHash string    `gorm:"size:128;not null"`
 	Role         Role      `gorm:"type:varchar(10);not null;default:'user'" json:"role"`
+	// Consent for the autonomous dialogue engine: may it read this user's personal
+	// memories, and may content derived from them be shared collectively?
+	AllowDialogueAccess    bool `gorm:"not null;default:false" json:"allowDialogueAccess"`
+	AllowCollectiveSharing bool `gorm:"not null;default:false" json:"allowCollectiveSharing"`
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 }
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// zeroWidthJoiner glues emoji into one symbol, as in a family or profession emoji
+const zeroWidthJoiner = '\u200d'
+
+// extendsPrevious reports whether r renders as part of the symbol before it: a
+// combining mark, a joiner, a variation selector, a skin tone modifier or an emoji tag
+func extendsPrevious(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner:
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // Variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // Skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // Tags, as in subdivision flags
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+// boundaryAtOrBefore returns the last position at or before i where s can be cut
+// without splitting a rune or separating a symbol from the marks and joiners that
+// extend it
+func boundaryAtOrBefore(s string, i int) int {
+	if i >= len(s) {
+		return len(s)
+	}
+	if i <= 0 {
+		return 0
+	}
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	for i > 0 {
+		next, _ := utf8.DecodeRuneInString(s[i:])
+		prev, size := utf8.DecodeLastRuneInString(s[:i])
+		if !extendsPrevious(next) && prev != zeroWidthJoiner {
+			break
+		}
+		i -= size
+	}
+	return i
+}
+
+// boundaryAtOrAfter is boundaryAtOrBefore searching forward
+func boundaryAtOrAfter(s string, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	if i >= len(s) {
+		return len(s)
+	}
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	for i < len(s) {
+		next, size := utf8.DecodeRuneInString(s[i:])
+		prev, _ := utf8.DecodeLastRuneInString(s[:i])
+		if !extendsPrevious(next) && prev != zeroWidthJoiner {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// Prefix returns the longest start of s that is at most maxBytes long and ends on a
+// symbol boundary, so it is valid UTF-8 whenever s is
+func Prefix(s string, maxBytes int) string {
+	return s[:boundaryAtOrBefore(s, maxBytes)]
+}
+
+// Suffix returns the longest end of s that is at most maxBytes long and starts on a
+// symbol boundary
+func Suffix(s string, maxBytes int) string {
+	if maxBytes <= 0 {
+		return ""
+	}
+	return s[boundaryAtOrAfter(s, len(s)-maxBytes):]
+}
+
+// Truncate cuts s to at most maxBytes on a symbol boundary and appends "..." when
+// anything was cut
+func Truncate(s string, maxBytes int) string {
+	return TruncateWith(s, maxBytes, "...")
+}
+
+// TruncateWith cuts s to at most maxBytes on a symbol boundary and appends marker when
+// anything was cut
+func TruncateWith(s string, maxBytes int, marker string) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	return Prefix(s, maxBytes) + marker
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsCharactersWhole(t *testing.T) {
+	cases := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{"ascii", "hello world", 5, "hello..."},
+		{"fits", "hello", 5, "hello"},
+		{"cjk", "蜜蜂如何导航", 7, "蜜蜂..."},                        // 3 bytes a character
+		{"emoji", "bees 🐝🐝", 7, "bees ..."},                  // 4 bytes an emoji
+		{"skin tone", "hi 👋🏽 there", 7, "hi ..."},            // Modifier stays with its hand
+		{"zwj family", "a 👨\u200d👩\u200d👧 b", 13, "a ..."},   // Joined emoji are one symbol
+		{"combining accent", "cafe\u0301 noir", 5, "caf..."}, // e and its accent stay together
+	}
+	for _, c := range cases {
+		got := Truncate(c.s, c.max)
+		if got != c.want || !utf8.ValidString(got) {
+			t.Errorf("%s: Truncate(%q, %d) = %q, want %q", c.name, c.s, c.max, got, c.want)
+		}
+	}
+}
+
+func TestPrefixAndSuffixStayValid(t *testing.T) {
+	s := strings.Repeat("研究🐝é", 20)
+	for n := 0; n <= len(s); n++ {
+		prefix, suffix := Prefix(s, n), Suffix(s, n)
+		if len(prefix) > n || !utf8.ValidString(prefix) || !strings.HasPrefix(s, prefix) {
+			t.Fatalf("Prefix(%d) = %q is not a valid prefix within the limit", n, prefix)
+		}
+		if len(suffix) > n || !utf8.ValidString(suffix) || !strings.HasSuffix(s, suffix) {
+			t.Fatalf("Suffix(%d) = %q is not a valid suffix within the limit", n, suffix)
+		}
+	}
+	if Prefix(s, -1) != "" || Suffix(s, 0) != "" {
+		t.Error("expected nothing kept for a limit of zero or less")
+	}
+}